@@ -0,0 +1,160 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	manifestPath   string
+	recursive      bool
+	prune          bool
+	dryRun         bool
+	organizationID string
+)
+
+// ApplyCmd represents the mesheryctl apply command
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile Meshery Server with declarative manifests",
+	Long: `Reconcile Meshery Server with the manifests of a file or directory, to keep the configuration of a
+Meshery deployment as code, e.g. in a Git repository.
+
+Manifests have the apiVersion meshery.io/v1alpha1, a kind, a metadata.name and a spec. The kinds are Design,
+Environment, Connection (metadata of registered connections), PerformanceProfile and AlertRule (alert rules and
+their notification channels). Resources are matched by kind and name: missing resources are created and resources
+differing from their manifest updated. With --prune, the resources of the kinds of the manifests which no manifest
+declares are deleted; connections are never created nor deleted.
+
+  apiVersion: meshery.io/v1alpha1
+  kind: Design
+  metadata:
+    name: bookinfo
+  spec:
+    file: ./designs/bookinfo.yaml
+  ---
+  apiVersion: meshery.io/v1alpha1
+  kind: Environment
+  metadata:
+    name: staging
+  spec:
+    description: Staging clusters
+    connections: [staging-cluster]`,
+	Example: `
+// Reconcile Meshery Server with the manifests of a directory and its subdirectories
+mesheryctl apply -R -f ./meshery/
+
+// Show the changes without making them
+mesheryctl apply -R -f ./meshery/ --dry-run
+
+// Delete the resources no manifest declares as well
+mesheryctl apply -R -f ./meshery/ --prune
+
+// Reconcile the environments of an organization
+mesheryctl apply -f environments.yaml --org-id [organization-id]
+	`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if manifestPath == "" {
+			if err := cmd.Usage(); err != nil {
+				return nil
+			}
+			return utils.ErrInvalidArgument(errors.New("Please provide the file or directory of the manifests with -f"))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifests, err := loadManifests(manifestPath, recursive)
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		if len(manifests) == 0 {
+			utils.Log.Info(fmt.Sprintf("No manifest of apiVersion %s found in %s", ManifestAPIVersion, manifestPath))
+			return nil
+		}
+
+		mctlCfg, err := config.GetMesheryCtl(viper.GetViper())
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		baseURL := mctlCfg.GetBaseMesheryURL()
+
+		state, err := fetchServerState(baseURL, manifests, organizationID)
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		changes, err := planChanges(manifests, state, planOptions{prune: prune, organizationID: organizationID})
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+
+		deletes := 0
+		for _, c := range changes {
+			utils.Log.Info(c.String())
+			if c.op == opDelete {
+				deletes++
+			}
+		}
+		if dryRun {
+			utils.Log.Info("Dry run, no change made")
+			return nil
+		}
+		if deletes > 0 && !utils.SilentFlag {
+			if !utils.AskForConfirmation(fmt.Sprintf("%d resources will be deleted. Are you sure you want to continue", deletes)) {
+				return nil
+			}
+		}
+
+		count := map[operation]int{}
+		failed := 0
+		for _, c := range changes {
+			if c.op != opUnchanged {
+				if err := c.apply(baseURL); err != nil {
+					utils.Log.Error(ErrReconcile(err, c.kind, c.name))
+					failed++
+					continue
+				}
+			}
+			count[c.op]++
+		}
+		utils.Log.Info(fmt.Sprintf("%d created, %d updated, %d deleted, %d unchanged, %d failed", count[opCreate], count[opUpdate], count[opDelete], count[opUnchanged], failed))
+		return nil
+	},
+}
+
+// String describes the change as a line of the plan.
+func (c change) String() string {
+	symbol := map[operation]string{opCreate: "+", opUpdate: "~", opDelete: "-", opUnchanged: "="}[c.op]
+	return fmt.Sprintf("%s %s %s (%s)", symbol, c.kind, c.name, c.op)
+}
+
+func init() {
+	ApplyCmd.Flags().StringVarP(&manifestPath, "file", "f", "", "file or directory of the manifests")
+	ApplyCmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "read the manifests of the subdirectories as well")
+	ApplyCmd.Flags().BoolVar(&prune, "prune", false, "delete the resources of the kinds of the manifests which no manifest declares")
+	ApplyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the changes without making them")
+	ApplyCmd.Flags().StringVar(&organizationID, "org-id", "", "organization of the environments which do not declare one")
+	ApplyCmd.Flags().BoolVarP(&utils.SilentFlag, "yes", "y", false, "(optional) assume yes for user interactive prompts.")
+}
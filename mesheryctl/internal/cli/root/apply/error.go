@@ -0,0 +1,20 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+var (
+	ErrInvalidManifestCode = "mesheryctl-1141"
+	ErrReconcileCode       = "mesheryctl-1142"
+)
+
+func ErrInvalidManifest(err error, source string) error {
+	return errors.New(ErrInvalidManifestCode, errors.Alert, []string{fmt.Sprintf("invalid manifest %s", source)}, []string{err.Error()}, []string{"The file is neither YAML nor JSON", "The kind of the manifest is not supported", "The manifest has no name or the same name as another manifest of its kind", "A file referenced by the manifest does not exist"}, []string{"Check the syntax of the file", "Use one of the kinds Design, Environment, Connection, PerformanceProfile and AlertRule", "Give every manifest a unique metadata.name within its kind", "Reference files relative to the manifest"})
+}
+
+func ErrReconcile(err error, kind, name string) error {
+	return errors.New(ErrReconcileCode, errors.Alert, []string{fmt.Sprintf("unable to reconcile %s %s", kind, name)}, []string{err.Error()}, []string{"Meshery Server is not reachable", "The server rejected the manifest", "The connection referenced by the manifest is not registered"}, []string{"Ensure Meshery Server is running with `mesheryctl system status`", "Check the spec of the manifest against the error returned by the server", "List the registered connections with `mesheryctl exp connections list`"})
+}
@@ -0,0 +1,186 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ManifestAPIVersion is the apiVersion of the manifests describing the state of Meshery. Documents of another
+// apiVersion, such as the design files referenced by the manifests, are not manifests and are skipped.
+const ManifestAPIVersion = "meshery.io/v1alpha1"
+
+// The kinds of the manifests, in the order they are reconciled.
+const (
+	KindConnection         = "Connection"
+	KindEnvironment        = "Environment"
+	KindDesign             = "Design"
+	KindPerformanceProfile = "PerformanceProfile"
+	KindAlertRule          = "AlertRule"
+)
+
+var kinds = []string{KindConnection, KindEnvironment, KindDesign, KindPerformanceProfile, KindAlertRule}
+
+// Manifest declares a resource of Meshery Server, identified by its kind and name.
+type Manifest struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Metadata   ManifestMetadata `json:"metadata"`
+	Spec       json.RawMessage  `json:"spec"`
+
+	// Source is the file the manifest is read from.
+	Source string `json:"-"`
+}
+
+// ManifestMetadata is the metadata of a manifest.
+type ManifestMetadata struct {
+	Name string `json:"name"`
+}
+
+// designSpec is the spec of a Design, either the design file File, relative to the manifest, or the inline Design.
+type designSpec struct {
+	File   string                 `json:"file,omitempty"`
+	Design map[string]interface{} `json:"design,omitempty"`
+}
+
+// environmentSpec is the spec of an Environment. Connections are the names or ids of the connections assigned
+// to the environment.
+type environmentSpec struct {
+	Description    string   `json:"description,omitempty"`
+	OrganizationID string   `json:"organization_id,omitempty"`
+	Connections    []string `json:"connections,omitempty"`
+}
+
+// connectionSpec is the spec of a Connection. Connections are registered by Meshery, their metadata is merged
+// with Metadata.
+type connectionSpec struct {
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// loadManifests reads the manifests of the file or directory at path, of its subdirectories too when recursive
+// is set. YAML files may hold several manifests separated by ---.
+func loadManifests(path string, recursive bool) ([]Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, ErrInvalidManifest(err, path)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = []string{}
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if file != path && (!recursive || strings.HasPrefix(entry.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, ErrInvalidManifest(err, path)
+		}
+	}
+	sort.Strings(files)
+
+	manifests := []Manifest{}
+	seen := map[string]string{}
+	for _, file := range files {
+		fileManifests, err := readManifests(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range fileManifests {
+			key := manifest.Kind + "/" + manifest.Metadata.Name
+			if source, ok := seen[key]; ok {
+				return nil, ErrInvalidManifest(fmt.Errorf("%s %s is also declared by %s", manifest.Kind, manifest.Metadata.Name, source), file)
+			}
+			seen[key] = file
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests, nil
+}
+
+// readManifests returns the manifests of the file.
+func readManifests(file string) ([]Manifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, ErrInvalidManifest(err, file)
+	}
+
+	manifests := []Manifest{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		manifest := Manifest{}
+		if err := decoder.Decode(&manifest); err != nil {
+			if err == io.EOF {
+				return manifests, nil
+			}
+			return nil, ErrInvalidManifest(err, file)
+		}
+		if manifest.APIVersion != ManifestAPIVersion {
+			continue
+		}
+		manifest.Source = file
+		if err := manifest.validate(); err != nil {
+			return nil, ErrInvalidManifest(err, file)
+		}
+		manifests = append(manifests, manifest)
+	}
+}
+
+func (m *Manifest) validate() error {
+	if m.Metadata.Name == "" {
+		return errors.New("metadata.name is required")
+	}
+	for _, kind := range kinds {
+		if m.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown kind %q, the kind is one of %s", m.Kind, strings.Join(kinds, ", "))
+}
+
+// decodeSpec decodes the spec of the manifest into spec.
+func (m *Manifest) decodeSpec(spec interface{}) error {
+	if len(m.Spec) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(m.Spec))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return ErrInvalidManifest(fmt.Errorf("spec of %s %s: %w", m.Kind, m.Metadata.Name, err), m.Source)
+	}
+	return nil
+}
@@ -0,0 +1,502 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+)
+
+// profilesPageSize is the size of the pages of performance profiles requested from the server.
+const profilesPageSize = 100
+
+// operation is what reconciling a resource does to it.
+type operation string
+
+const (
+	opCreate    operation = "create"
+	opUpdate    operation = "update"
+	opDelete    operation = "delete"
+	opUnchanged operation = "unchanged"
+)
+
+// change is the reconciliation of a resource, the request to send to the server unless it is unchanged.
+type change struct {
+	op   operation
+	kind string
+	name string
+
+	method string
+	path   string
+	body   []byte
+}
+
+// serverState is the state of the resources of the server, of the kinds declared by the manifests.
+type serverState struct {
+	designs      []models.ManagedDesign
+	environments []models.ManagedEnvironment
+	connections  []*connections.Connection
+	profiles     []models.PerformanceProfile
+	alertRules   []models.AlertRule
+}
+
+// planOptions are the options of the reconciliation.
+type planOptions struct {
+	// prune deletes the resources of the kinds declared by the manifests which no manifest declares.
+	prune bool
+	// organizationID is the organization of the environments which do not declare theirs.
+	organizationID string
+}
+
+// fetchServerState reads the resources of the kinds declared by the manifests from the server.
+func fetchServerState(baseURL string, manifests []Manifest, organizationID string) (*serverState, error) {
+	declared := declaredKinds(manifests)
+	state := &serverState{}
+
+	if declared[KindDesign] {
+		if err := getJSON(baseURL+models.ManagementAPIBasePath+"/designs", &state.designs); err != nil {
+			return nil, ErrReconcile(err, "designs", "")
+		}
+	}
+	if declared[KindEnvironment] {
+		path := models.ManagementAPIBasePath + "/environments?orgID=" + url.QueryEscape(organizationID)
+		if err := getJSON(baseURL+path, &state.environments); err != nil {
+			return nil, ErrReconcile(err, "environments", "")
+		}
+	}
+	if declared[KindConnection] || declared[KindEnvironment] {
+		page := connections.ConnectionPage{}
+		if err := getJSON(baseURL+"/api/integrations/connections?pagesize=all", &page); err != nil {
+			return nil, ErrReconcile(err, "connections", "")
+		}
+		state.connections = page.Connections
+	}
+	if declared[KindPerformanceProfile] {
+		for page := 0; ; page++ {
+			resp := models.PerformanceProfilesAPIResponse{}
+			path := fmt.Sprintf("/api/user/performance/profiles?pagesize=%d&page=%d", profilesPageSize, page)
+			if err := getJSON(baseURL+path, &resp); err != nil {
+				return nil, ErrReconcile(err, "performance profiles", "")
+			}
+			state.profiles = append(state.profiles, resp.Profiles...)
+			if len(resp.Profiles) < profilesPageSize || uint(len(state.profiles)) >= resp.TotalCount {
+				break
+			}
+		}
+	}
+	if declared[KindAlertRule] {
+		if err := getJSON(baseURL+"/api/alerts/rules", &state.alertRules); err != nil {
+			return nil, ErrReconcile(err, "alert rules", "")
+		}
+	}
+	return state, nil
+}
+
+// planChanges returns the changes reconciling the server with the manifests: resources missing from the server
+// are created, resources differing from their manifest updated and, when pruning, resources of the kinds of
+// the manifests which no manifest declares deleted. Resources are matched by kind and name. Connections are
+// registered by Meshery, they are updated but never created nor pruned.
+func planChanges(manifests []Manifest, state *serverState, opts planOptions) ([]change, error) {
+	changes := []change{}
+	declared := map[string]bool{}
+	for _, kind := range kinds {
+		for i := range manifests {
+			manifest := &manifests[i]
+			if manifest.Kind != kind {
+				continue
+			}
+			declared[kind+"/"+manifest.Metadata.Name] = true
+
+			var c change
+			var err error
+			switch kind {
+			case KindConnection:
+				c, err = planConnection(manifest, state)
+			case KindEnvironment:
+				c, err = planEnvironment(manifest, state, opts.organizationID)
+			case KindDesign:
+				c, err = planDesign(manifest, state)
+			case KindPerformanceProfile:
+				c, err = planPerformanceProfile(manifest, state)
+			case KindAlertRule:
+				c, err = planAlertRule(manifest, state)
+			}
+			if err != nil {
+				return nil, err
+			}
+			c.kind, c.name = kind, manifest.Metadata.Name
+			changes = append(changes, c)
+		}
+	}
+	if !opts.prune {
+		return changes, nil
+	}
+
+	kindsOfManifests := declaredKinds(manifests)
+	prune := func(kind, name, path string) {
+		if kindsOfManifests[kind] && !declared[kind+"/"+name] {
+			changes = append(changes, change{op: opDelete, kind: kind, name: name, method: http.MethodDelete, path: path})
+		}
+	}
+	for _, design := range state.designs {
+		prune(KindDesign, design.Name, fmt.Sprintf("%s/designs/%s", models.ManagementAPIBasePath, design.ID))
+	}
+	for _, env := range state.environments {
+		prune(KindEnvironment, env.Name, fmt.Sprintf("%s/environments/%s", models.ManagementAPIBasePath, env.ID))
+	}
+	for _, profile := range state.profiles {
+		if profile.ID != nil {
+			prune(KindPerformanceProfile, profile.Name, fmt.Sprintf("/api/user/performance/profiles/%s", profile.ID))
+		}
+	}
+	for _, rule := range state.alertRules {
+		prune(KindAlertRule, rule.Name, fmt.Sprintf("/api/alerts/rules/%s", rule.ID))
+	}
+	return changes, nil
+}
+
+func planDesign(manifest *Manifest, state *serverState) (change, error) {
+	spec := designSpec{}
+	if err := manifest.decodeSpec(&spec); err != nil {
+		return change{}, err
+	}
+
+	var patternFile []byte
+	switch {
+	case spec.File != "" && spec.Design != nil:
+		return change{}, ErrInvalidManifest(fmt.Errorf("design %s declares both a file and an inline design", manifest.Metadata.Name), manifest.Source)
+	case spec.File != "":
+		path := spec.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(manifest.Source), path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return change{}, ErrInvalidManifest(err, manifest.Source)
+		}
+		patternFile = data
+	case spec.Design != nil:
+		data, err := yaml.Marshal(spec.Design)
+		if err != nil {
+			return change{}, ErrInvalidManifest(err, manifest.Source)
+		}
+		patternFile = data
+	default:
+		return change{}, ErrInvalidManifest(fmt.Errorf("design %s declares neither a file nor an inline design", manifest.Metadata.Name), manifest.Source)
+	}
+
+	body, err := json.Marshal(models.ManagedDesignRequest{Name: manifest.Metadata.Name, PatternFile: string(patternFile)})
+	if err != nil {
+		return change{}, ErrInvalidManifest(err, manifest.Source)
+	}
+	var existing *models.ManagedDesign
+	for i := range state.designs {
+		if state.designs[i].Name != manifest.Metadata.Name {
+			continue
+		}
+		if existing != nil {
+			return change{}, ErrReconcile(fmt.Errorf("several designs are named %s, rename them to reconcile them", manifest.Metadata.Name), KindDesign, manifest.Metadata.Name)
+		}
+		existing = &state.designs[i]
+	}
+
+	switch {
+	case existing == nil:
+		return change{op: opCreate, method: http.MethodPost, path: models.ManagementAPIBasePath + "/designs", body: body}, nil
+	case existing.ContentHash == models.DesignContentHash(string(patternFile)):
+		return change{op: opUnchanged}, nil
+	}
+	return change{op: opUpdate, method: http.MethodPut, path: fmt.Sprintf("%s/designs/%s", models.ManagementAPIBasePath, existing.ID), body: body}, nil
+}
+
+func planEnvironment(manifest *Manifest, state *serverState, organizationID string) (change, error) {
+	spec := environmentSpec{}
+	if err := manifest.decodeSpec(&spec); err != nil {
+		return change{}, err
+	}
+	if spec.OrganizationID != "" {
+		organizationID = spec.OrganizationID
+	}
+
+	req := models.ManagedEnvironmentRequest{Name: manifest.Metadata.Name, Description: spec.Description, ConnectionIDs: []uuid.UUID{}}
+	for _, ref := range spec.Connections {
+		conn, err := findConnection(state, ref)
+		if err != nil {
+			return change{}, ErrReconcile(err, KindEnvironment, manifest.Metadata.Name)
+		}
+		req.ConnectionIDs = append(req.ConnectionIDs, conn.ID)
+	}
+	sort.Slice(req.ConnectionIDs, func(i, j int) bool { return req.ConnectionIDs[i].String() < req.ConnectionIDs[j].String() })
+
+	var existing *models.ManagedEnvironment
+	for i := range state.environments {
+		if state.environments[i].Name != manifest.Metadata.Name {
+			continue
+		}
+		if existing != nil {
+			return change{}, ErrReconcile(fmt.Errorf("several environments are named %s, rename them to reconcile them", manifest.Metadata.Name), KindEnvironment, manifest.Metadata.Name)
+		}
+		existing = &state.environments[i]
+	}
+
+	if organizationID != "" {
+		id, err := uuid.FromString(organizationID)
+		if err != nil {
+			return change{}, ErrInvalidManifest(fmt.Errorf("invalid organization id %q: %w", organizationID, err), manifest.Source)
+		}
+		req.OrganizationID = id
+	} else if existing != nil {
+		req.OrganizationID = existing.OrganizationID
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return change{}, ErrInvalidManifest(err, manifest.Source)
+	}
+
+	switch {
+	case existing == nil:
+		return change{op: opCreate, method: http.MethodPost, path: models.ManagementAPIBasePath + "/environments", body: body}, nil
+	case existing.Description == req.Description && existing.OrganizationID == req.OrganizationID && sameIDs(existing.ConnectionIDs, req.ConnectionIDs):
+		return change{op: opUnchanged}, nil
+	}
+	return change{op: opUpdate, method: http.MethodPut, path: fmt.Sprintf("%s/environments/%s", models.ManagementAPIBasePath, existing.ID), body: body}, nil
+}
+
+func planConnection(manifest *Manifest, state *serverState) (change, error) {
+	spec := connectionSpec{}
+	if err := manifest.decodeSpec(&spec); err != nil {
+		return change{}, err
+	}
+	conn, err := findConnection(state, manifest.Metadata.Name)
+	if err != nil {
+		return change{}, ErrReconcile(err, KindConnection, manifest.Metadata.Name)
+	}
+
+	metadata := map[string]interface{}{}
+	for key, value := range conn.Metadata {
+		metadata[key] = value
+	}
+	changed := false
+	for key, value := range spec.Metadata {
+		changed = changed || !sameJSON(metadata[key], value)
+		metadata[key] = value
+	}
+	if !changed {
+		return change{op: opUnchanged}, nil
+	}
+
+	payload := connections.ConnectionPayload{
+		ID:       conn.ID,
+		Kind:     conn.Kind,
+		SubType:  conn.SubType,
+		Type:     conn.Type,
+		MetaData: metadata,
+		Status:   conn.Status,
+		Name:     conn.Name,
+	}
+	if conn.CredentialID != uuid.Nil {
+		payload.CredentialID = &conn.CredentialID
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return change{}, ErrInvalidManifest(err, manifest.Source)
+	}
+	return change{op: opUpdate, method: http.MethodPut, path: fmt.Sprintf("/api/integrations/connections/%s", conn.ID), body: body}, nil
+}
+
+func planPerformanceProfile(manifest *Manifest, state *serverState) (change, error) {
+	profile := models.PerformanceProfile{}
+	if err := manifest.decodeSpec(&profile); err != nil {
+		return change{}, err
+	}
+	profile.Name = manifest.Metadata.Name
+	profile.ID, profile.LastRun, profile.Schedule, profile.TotalResults, profile.CreatedAt, profile.UpdatedAt = nil, nil, nil, 0, nil, nil
+
+	var existing *models.PerformanceProfile
+	for i := range state.profiles {
+		if state.profiles[i].Name != manifest.Metadata.Name {
+			continue
+		}
+		if existing != nil {
+			return change{}, ErrReconcile(fmt.Errorf("several performance profiles are named %s, rename them to reconcile them", manifest.Metadata.Name), KindPerformanceProfile, manifest.Metadata.Name)
+		}
+		existing = &state.profiles[i]
+	}
+
+	op := opCreate
+	if existing != nil {
+		// compare the settings of the profiles, not the bookkeeping of the server
+		current := *existing
+		current.ID, current.LastRun, current.Schedule, current.TotalResults, current.CreatedAt, current.UpdatedAt = nil, nil, nil, 0, nil, nil
+		if sameJSON(current, profile) {
+			return change{op: opUnchanged}, nil
+		}
+		op = opUpdate
+		profile.ID = existing.ID
+	}
+	body, err := json.Marshal(profile)
+	if err != nil {
+		return change{}, ErrInvalidManifest(err, manifest.Source)
+	}
+	// the server creates profiles without an id and updates the others
+	return change{op: op, method: http.MethodPost, path: "/api/user/performance/profiles", body: body}, nil
+}
+
+func planAlertRule(manifest *Manifest, state *serverState) (change, error) {
+	def := models.AlertRuleDefinition{}
+	if err := manifest.decodeSpec(&def); err != nil {
+		return change{}, err
+	}
+	def.Name = manifest.Metadata.Name
+	if err := def.Validate(); err != nil {
+		return change{}, ErrInvalidManifest(fmt.Errorf("alert rule %s: %w", def.Name, err), manifest.Source)
+	}
+	body, err := json.Marshal(def)
+	if err != nil {
+		return change{}, ErrInvalidManifest(err, manifest.Source)
+	}
+
+	var existing *models.AlertRule
+	for i := range state.alertRules {
+		if state.alertRules[i].Name != manifest.Metadata.Name {
+			continue
+		}
+		if existing != nil {
+			return change{}, ErrReconcile(fmt.Errorf("several alert rules are named %s, rename them to reconcile them", manifest.Metadata.Name), KindAlertRule, manifest.Metadata.Name)
+		}
+		existing = &state.alertRules[i]
+	}
+
+	if existing == nil {
+		return change{op: opCreate, method: http.MethodPost, path: "/api/alerts/rules", body: body}, nil
+	}
+	if current, err := models.ParseAlertRuleDefinition([]byte(existing.Definition)); err == nil && sameJSON(*current, def) {
+		return change{op: opUnchanged}, nil
+	}
+	return change{op: opUpdate, method: http.MethodPut, path: fmt.Sprintf("/api/alerts/rules/%s", existing.ID), body: body}, nil
+}
+
+// findConnection returns the connection with the id or name ref.
+func findConnection(state *serverState, ref string) (*connections.Connection, error) {
+	var found *connections.Connection
+	for _, conn := range state.connections {
+		if conn == nil {
+			continue
+		}
+		if conn.ID.String() == ref {
+			return conn, nil
+		}
+		if conn.Name != ref {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several connections are named %s, reference the connection by id", ref)
+		}
+		found = conn
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no connection is named %s, connections are registered by Meshery and cannot be created by apply", ref)
+	}
+	return found, nil
+}
+
+// apply sends the request of the change to the server.
+func (c *change) apply(baseURL string) error {
+	req, err := utils.NewRequest(c.method, baseURL+c.path, bytes.NewReader(c.body))
+	if err != nil {
+		return err
+	}
+	resp, err := utils.MakeRequest(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// getJSON decodes the response of the server to the GET request of url into v.
+func getJSON(url string, v interface{}) error {
+	req, err := utils.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := utils.MakeRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return utils.ErrReadResponseBody(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return utils.ErrUnmarshal(err)
+	}
+	return nil
+}
+
+func declaredKinds(manifests []Manifest) map[string]bool {
+	declared := map[string]bool{}
+	for _, manifest := range manifests {
+		declared[manifest.Kind] = true
+	}
+	return declared
+}
+
+// sameIDs reports whether the ids are the same, whatever their order.
+func sameIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[uuid.UUID]int{}
+	for _, id := range a {
+		count[id]++
+	}
+	for _, id := range b {
+		if count[id] == 0 {
+			return false
+		}
+		count[id]--
+	}
+	return true
+}
+
+// sameJSON reports whether a and b have the same JSON representation, so that values decoded from manifests
+// compare equal to the values read from the server.
+func sameJSON(a, b interface{}) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	if errX != nil || errY != nil {
+		return false
+	}
+	var u, v interface{}
+	if json.Unmarshal(x, &u) != nil || json.Unmarshal(y, &v) != nil {
+		return false
+	}
+	return reflect.DeepEqual(u, v)
+}
@@ -0,0 +1,162 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+)
+
+const testDesign = "name: bookinfo\nservices: {}\n"
+
+const testManifests = `apiVersion: meshery.io/v1alpha1
+kind: Design
+metadata:
+  name: bookinfo
+spec:
+  file: designs/bookinfo.yaml
+---
+apiVersion: meshery.io/v1alpha1
+kind: Environment
+metadata:
+  name: staging
+spec:
+  description: Staging clusters
+  connections: [staging-cluster]
+---
+apiVersion: meshery.io/v1alpha1
+kind: Connection
+metadata:
+  name: staging-cluster
+spec:
+  metadata:
+    team: platform
+---
+apiVersion: meshery.io/v1alpha1
+kind: AlertRule
+metadata:
+  name: deployments failing
+spec:
+  metric: deployment_failure_streak
+  condition: ">= 3"
+  channels:
+    - type: events
+`
+
+func writeTestManifests(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"meshery.yaml":          testManifests,
+		"designs/bookinfo.yaml": testDesign,
+		"profiles/load.yaml":    "apiVersion: meshery.io/v1alpha1\nkind: PerformanceProfile\nmetadata:\n  name: load\nspec:\n  endpoints: [http://bookinfo]\n  qps: 10\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadManifests(t *testing.T) {
+	dir := writeTestManifests(t)
+
+	manifests, err := loadManifests(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 4 {
+		t.Errorf("expected the 4 manifests of the directory, got %d", len(manifests))
+	}
+
+	manifests, err = loadManifests(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 5 {
+		t.Errorf("expected the 5 manifests of the directory and its subdirectories, got %d", len(manifests))
+	}
+
+	duplicate := filepath.Join(dir, "profiles", "copy.yaml")
+	if err := os.WriteFile(duplicate, []byte(testManifests), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadManifests(dir, true); err == nil {
+		t.Error("expected resources declared twice to be rejected")
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	dir := writeTestManifests(t)
+	manifests, err := loadManifests(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connID := uuid.Must(uuid.NewV4())
+	profileID := uuid.Must(uuid.NewV4())
+	state := &serverState{
+		designs: []models.ManagedDesign{
+			{ID: uuid.Must(uuid.NewV4()), Name: "bookinfo", ContentHash: models.DesignContentHash(testDesign)},
+			{ID: uuid.Must(uuid.NewV4()), Name: "scratch"},
+		},
+		environments: []models.ManagedEnvironment{
+			{ID: uuid.Must(uuid.NewV4()), Name: "staging", Description: "Staging clusters"},
+		},
+		connections: []*connections.Connection{
+			{ID: connID, Name: "staging-cluster", Kind: "kubernetes", Metadata: map[string]interface{}{"team": "platform"}},
+		},
+		profiles: []models.PerformanceProfile{
+			{ID: &profileID, Name: "load", Endpoints: []string{"http://bookinfo"}, QPS: 10, TotalResults: 4},
+		},
+	}
+
+	type planned struct {
+		op   operation
+		kind string
+		name string
+	}
+	plan := func(prune bool) []planned {
+		changes, err := planChanges(manifests, state, planOptions{prune: prune})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []planned{}
+		for _, c := range changes {
+			got = append(got, planned{c.op, c.kind, c.name})
+		}
+		return got
+	}
+
+	want := []planned{
+		{opUnchanged, KindConnection, "staging-cluster"},
+		{opUpdate, KindEnvironment, "staging"},
+		{opUnchanged, KindDesign, "bookinfo"},
+		{opUnchanged, KindPerformanceProfile, "load"},
+		{opCreate, KindAlertRule, "deployments failing"},
+	}
+	if got := plan(false); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the changes %v, got %v", want, got)
+	}
+
+	state.environments[0].ConnectionIDs = []uuid.UUID{connID}
+	state.profiles[0].QPS = 20
+	want[1].op, want[3].op = opUnchanged, opUpdate
+	want = append(want, planned{opDelete, KindDesign, "scratch"})
+	if got := plan(true); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the changes %v when pruning, got %v", want, got)
+	}
+
+	state.connections = nil
+	if _, err := planChanges(manifests, state, planOptions{}); err == nil {
+		t.Error("expected a connection which is not registered to be rejected")
+	}
+}
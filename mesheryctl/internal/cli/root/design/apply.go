@@ -36,8 +36,11 @@ import (
 )
 
 var (
-	skipSave    bool // skip saving a design
-	patternFile string
+	skipSave          bool // skip saving a design
+	patternFile       string
+	designVars        []string
+	designOverlay     string
+	designOverlayFile string
 )
 
 var linkDocPatternApply = map[string]string{
@@ -55,6 +58,18 @@ mesheryctl design apply -f [file | URL]
 
 // deploy a saved design
 mesheryctl design apply [design-name]
+
+// apply a design file, overriding the variables declared in its vars section
+mesheryctl design apply -f [file | URL] --var namespace=staging --var replicas=3
+
+// apply a design file patched by its staging overlay, declared in the companion file design.overlays.yaml
+mesheryctl design apply -f design.yaml --overlay staging
+
+// apply a design patched by an overlay declared in a given overlay file
+mesheryctl design apply [design-name] --overlay production --overlay-file overlays.yaml
+
+// apply a design file importing reusable designs, eg. imports: [{source: ./fragments/monitoring.yaml, namespace: monitoring}]
+mesheryctl design apply -f design.yaml
 	`,
 	Annotations: linkDocPatternApply,
 	Args:        cobra.MinimumNArgs(0),
@@ -68,6 +83,8 @@ mesheryctl design apply [design-name]
 
 		deployURL := mctlCfg.GetBaseMesheryURL() + "/api/pattern/deploy"
 		patternURL := mctlCfg.GetBaseMesheryURL() + "/api/pattern"
+		// local design files can import designs relative to their path
+		importer := &core.PatternImporter{}
 
 		// pattern name has been passed
 		if len(args) > 0 {
@@ -121,6 +138,7 @@ mesheryctl design apply [design-name]
 					utils.Log.Error(utils.ErrFileRead(errors.Errorf("file path %s is invalid. Enter a valid path ", file)))
 					return nil
 				}
+				importer.Location = file
 
 				// if --skip-save is not passed we save the pattern first
 				if !skipSave {
@@ -233,23 +251,78 @@ mesheryctl design apply [design-name]
 
 		}
 
-		payload := models.MesheryPatternFileDeployPayload{
-			PatternFile: patternFile,
+		overrides, err := parseDesignVars(designVars)
+		if err != nil {
+			return err
 		}
 
-		payloadBytes, err := json.Marshal(payload)
+		pf, err := core.NewPatternFileWithImports([]byte(patternFile), overrides, importer)
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		imports, err := core.GetPatternImports([]byte(patternFile), core.PatternFileFormatAuto)
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		vars, err := core.GetPatternVars([]byte(patternFile), core.PatternFileFormatAuto, overrides)
 		if err != nil {
 			utils.Log.Error(err)
 			return nil
 		}
 
-		req, err = utils.NewRequest("POST", deployURL, bytes.NewBuffer(payloadBytes))
+		if designOverlay != "" {
+			overlayFile := designOverlayFile
+			if overlayFile == "" {
+				overlayFile = companionOverlayFile(file)
+			}
+			if overlayFile == "" {
+				utils.Log.Error(utils.ErrInvalidArgument(errors.New("--overlay-file is required to apply an overlay to a saved or remote design")))
+				return nil
+			}
+			overlays, err := os.ReadFile(overlayFile)
+			if err != nil {
+				utils.Log.Error(utils.ErrFileRead(errors.Errorf("overlay file %s is invalid. Enter a valid path ", overlayFile)))
+				return nil
+			}
+			p, err := core.NewPatternWithOverlays(pf, overlays)
+			if err != nil {
+				utils.Log.Error(err)
+				return nil
+			}
+			resolved, err := p.WithOverlay(designOverlay)
+			if err != nil {
+				utils.Log.Error(err)
+				return nil
+			}
+			pf = *resolved
+		}
+
+		// Variables overridden by flags or by MESHERY_VAR_ environment variables, overlays and local imports are only
+		// known here, so deploy the resolved design. Meshery Server would otherwise resolve the variables from its own
+		// environment. It is sent as JSON, as the metadata of components keeps its additional properties, eg. dependsOn,
+		// only in JSON.
+		if len(vars) > 0 || designOverlay != "" || len(imports) > 0 {
+			resolvedPatternFile, err := json.Marshal(pf)
+			if err != nil {
+				utils.Log.Error(err)
+				return nil
+			}
+			patternFile = string(resolvedPatternFile)
+		}
+
+		payload := models.MesheryPatternFileDeployPayload{
+			PatternFile: patternFile,
+		}
+
+		payloadBytes, err := json.Marshal(payload)
 		if err != nil {
 			utils.Log.Error(err)
 			return nil
 		}
 
-		pf, err := core.NewPatternFile([]byte(patternFile))
+		req, err = utils.NewRequest("POST", deployURL, bytes.NewBuffer(payloadBytes))
 		if err != nil {
 			utils.Log.Error(err)
 			return nil
@@ -279,6 +352,29 @@ mesheryctl design apply [design-name]
 	},
 }
 
+// parseDesignVars parses the name=value pairs passed with --var
+func parseDesignVars(vars []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(vars))
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, utils.ErrInvalidArgument(errors.Errorf("invalid variable %q, expected name=value", v))
+		}
+		overrides[name] = value
+	}
+	return overrides, nil
+}
+
+// companionOverlayFile returns the path of the overlay document accompanying a local design file,
+// eg. design.overlays.yaml for design.yaml, or an empty string for remote design files.
+func companionOverlayFile(designFile string) string {
+	if designFile == "" || strings.HasPrefix(designFile, "https://") {
+		return ""
+	}
+	ext := path.Ext(designFile)
+	return strings.TrimSuffix(designFile, ext) + ".overlays" + ext
+}
+
 func multiplePatternsConfirmation(profiles []models.MesheryPattern) int {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -313,4 +409,7 @@ func multiplePatternsConfirmation(profiles []models.MesheryPattern) int {
 func init() {
 	applyCmd.Flags().StringVarP(&file, "file", "f", "", "Path to design file")
 	applyCmd.Flags().BoolVarP(&skipSave, "skip-save", "", false, "Skip saving a design")
+	applyCmd.Flags().StringArrayVar(&designVars, "var", []string{}, "Override a variable declared in the vars section of the design, as name=value")
+	applyCmd.Flags().StringVar(&designOverlay, "overlay", "", "Name of the overlay patching the design for an environment, eg. staging")
+	applyCmd.Flags().StringVar(&designOverlayFile, "overlay-file", "", "Path to the document declaring the overlays of the design (default: the design file with the .overlays extension, eg. design.overlays.yaml)")
 }
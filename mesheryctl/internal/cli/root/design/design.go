@@ -47,6 +47,9 @@ mesheryctl design view [design name | ID]
 
 // List all designs:
 mesheryctl design list
+
+// Fork a design:
+mesheryctl design fork [design name | ID]
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -71,6 +74,6 @@ mesheryctl design list
 func init() {
 	DesignCmd.PersistentFlags().StringVarP(&utils.TokenFlag, "token", "t", "", "Path to token file default from current context")
 
-	availableSubcommands = []*cobra.Command{applyCmd, deleteCmd, viewCmd, listCmd, importCmd, onboardCmd, offboardCmd}
+	availableSubcommands = []*cobra.Command{applyCmd, deleteCmd, viewCmd, listCmd, importCmd, onboardCmd, offboardCmd, forkCmd}
 	DesignCmd.AddCommand(availableSubcommands...)
 }
@@ -37,6 +37,7 @@ const (
 	ErrRetrieveHomeDirCode       = "mesheryctl-1124"
 	ErrReadFromBodyCode          = "mesheryctl-1125"
 	ErrMarkFlagRequireCode       = "mesheryctl-1126"
+	ErrForkDesignCode            = "mesheryctl-1140"
 )
 
 const (
@@ -156,3 +157,10 @@ func ErrReadFromBody(err error) error {
 		[]string{"The data for the pattern (design) file might be corrupted."},
 		[]string{"Please ensure that your network connection is stable. If the issue continues, check the server response or data format for potential problems."})
 }
+func ErrForkDesign(err error) error {
+	return errors.New(ErrForkDesignCode, errors.Alert,
+		[]string{"Unable to fork design"},
+		[]string{err.Error()},
+		[]string{"The design does not exist or is not shared with you", "The design is in a format which cannot be forked"},
+		[]string{"Run `mesheryctl design list` to view the designs available, and import designs of older formats again before forking them."})
+}
@@ -52,7 +52,9 @@ var exportCmd = &cobra.Command{
 You can specify the design by its name or ID and optionally define the type of design.
 The command also supports specifying an output directory where the exported design will be saved.
 By default, the exported design will be saved in the current directory. The different types of design
-type allowed are oci, original, and current. The default design type is current.`,
+type allowed are oci, original, manifest and current. The default design type is current.
+The manifest type renders the design into the Kubernetes manifests which deploying it would apply,
+without touching a cluster, so they can be committed to a GitOps repository.`,
 	Example: `
 	# Export a design with a specific ID
 	mesheryctl design export [pattern-name | ID]
@@ -63,6 +65,9 @@ type allowed are oci, original, and current. The default design type is current.
 	# Export a design and save it to a specific directory
 	mesheryctl design export [pattern-name | ID] --output ./designs
 	
+	# Render a design into Kubernetes manifests
+	mesheryctl design export [pattern-name | ID] --type manifest
+
 	# Export a design with a specific type and save it to a directory
 	mesheryctl design export [pattern-name | ID] --type [design-type] --output ./exports
 	`,
@@ -154,6 +159,8 @@ func exportDesign(baseUrl, design, designType string) error {
 		url += "?oci=true"
 	case "original":
 		url += fmt.Sprintf("/%s", pattern.Type.String)
+	case "manifest":
+		url += "?export=Kubernetes%20Manifest"
 	}
 
 	resp, err := makeRequest(http.MethodGet, url)
@@ -0,0 +1,113 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package design
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var forkName string
+
+var forkCmd = &cobra.Command{
+	Use:   "fork [design-name | ID]",
+	Short: "Fork a design into your workspace",
+	Long: `Fork a design shared with you or published to the catalog into your workspace.
+The fork keeps track of the design it was forked from, so that its changes can be proposed back
+to the original design as a merge request which the owner of the design reviews.`,
+	Example: `
+// Fork a design
+mesheryctl design fork [design-name | ID]
+
+// Fork a design under another name
+mesheryctl design fork [design-name | ID] --name [name of the fork]
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mctlCfg, err := config.GetMesheryCtl(viper.GetViper())
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		baseURL := mctlCfg.GetBaseMesheryURL()
+
+		design, isID, err := utils.ValidId(baseURL, args[0], "pattern")
+		if err != nil {
+			utils.Log.Error(ErrPatternInvalidNameOrID(err))
+			return nil
+		}
+		if !isID {
+			// designs shared with the user or published to the catalog are only forked by id
+			_, id, isName, err := utils.ValidName(baseURL, args[0], "pattern")
+			if err != nil {
+				utils.Log.Error(ErrPatternInvalidNameOrID(err))
+				return nil
+			}
+			if !isName {
+				utils.Log.Error(ErrDesignNotFound())
+				return nil
+			}
+			design = id
+		}
+
+		body, err := json.Marshal(map[string]string{"name": forkName})
+		if err != nil {
+			utils.Log.Error(utils.ErrMarshal(err))
+			return nil
+		}
+		req, err := utils.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/pattern/%s/fork", baseURL, design), bytes.NewBuffer(body))
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		resp, err := utils.MakeRequest(req)
+		if err != nil {
+			utils.Log.Error(err)
+			return nil
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			utils.Log.Error(utils.ErrReadResponseBody(err))
+			return nil
+		}
+		if resp.StatusCode != http.StatusCreated {
+			utils.Log.Error(ErrForkDesign(fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))))
+			return nil
+		}
+
+		fork := models.MesheryPattern{}
+		if err := json.Unmarshal(data, &fork); err != nil {
+			utils.Log.Error(utils.ErrUnmarshal(err))
+			return nil
+		}
+		utils.Log.Info(fmt.Sprintf("Design forked as %s (%s)", fork.Name, fork.ID))
+		return nil
+	},
+}
+
+func init() {
+	forkCmd.Flags().StringVarP(&forkName, "name", "n", "", "(optional) name of the fork, the name of the design followed by (fork) by default")
+}
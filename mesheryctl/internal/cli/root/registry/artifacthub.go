@@ -0,0 +1,180 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	artifactHubFormat      = "artifacthub"
+	artifactHubPackageFile = "artifacthub-pkg.yml"
+	artifactHubLogoFile    = "logo.svg"
+)
+
+var (
+	// artifactHubCategories maps the categories of the models to the categories of Artifact Hub packages.
+	// Models of the other categories are published without category.
+	artifactHubCategories = map[string]string{
+		"App Definition and Development": "integration-delivery",
+		"Cloud Native Network":           "networking",
+		"Cloud Native Storage":           "storage",
+		"Database":                       "database",
+		"Machine Learning":               "ai-machine-learning",
+		"Observability and Analysis":     "monitoring-logging",
+		"Security & Compliance":          "security",
+		"Streaming & Messaging":          "streaming-messaging",
+	}
+
+	invalidArtifactHubNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type artifactHubPackage struct {
+	Version     string              `yaml:"version"`
+	Name        string              `yaml:"name"`
+	DisplayName string              `yaml:"displayName"`
+	CreatedAt   string              `yaml:"createdAt"`
+	Description string              `yaml:"description"`
+	LogoPath    string              `yaml:"logoPath,omitempty"`
+	HomeURL     string              `yaml:"homeURL,omitempty"`
+	Category    string              `yaml:"category,omitempty"`
+	Keywords    []string            `yaml:"keywords,omitempty"`
+	Links       []artifactHubLink   `yaml:"links,omitempty"`
+	Provider    artifactHubProvider `yaml:"provider"`
+	Annotations map[string]string   `yaml:"annotations,omitempty"`
+}
+
+type artifactHubLink struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+type artifactHubProvider struct {
+	Name string `yaml:"name"`
+}
+
+// writeArtifactHubPackages writes the Artifact Hub package of each model to the directory dir/<model>/<version>.
+// Models whose version is not a semantic version, as Artifact Hub requires, are skipped.
+func writeArtifactHubPackages(modelDefs []model.ModelDefinition, dir string) error {
+	for _, modelDef := range modelDefs {
+		pkg := artifactHubPackageOf(modelDef)
+		if !semver.IsValid(canonicalVersion(pkg.Version)) {
+			utils.Log.Warn(errors.Errorf("skipping model %s, its version %q is not a semantic version", modelDef.Name, pkg.Version))
+			continue
+		}
+
+		pkgDir := filepath.Join(dir, pkg.Name, pkg.Version)
+		if err := os.MkdirAll(pkgDir, 0755); err != nil {
+			return err
+		}
+		pkg.CreatedAt = artifactHubCreatedAt(filepath.Join(pkgDir, artifactHubPackageFile))
+
+		if modelDef.Metadata != nil && modelDef.Metadata.SvgColor != "" {
+			if err := os.WriteFile(filepath.Join(pkgDir, artifactHubLogoFile), []byte(modelDef.Metadata.SvgColor), 0644); err != nil {
+				return err
+			}
+			pkg.LogoPath = artifactHubLogoFile
+		}
+
+		data, err := yaml.Marshal(pkg)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, artifactHubPackageFile), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// artifactHubPackageOf returns the Artifact Hub package metadata of the model, but for its creation date and logo.
+func artifactHubPackageOf(modelDef model.ModelDefinition) artifactHubPackage {
+	version := modelDef.Model.Version
+	if version == "" {
+		version = modelDef.Version
+	}
+	displayName := modelDef.DisplayName
+	if displayName == "" {
+		displayName = modelDef.Name
+	}
+	description := modelDef.Description
+	if description == "" {
+		description = fmt.Sprintf("Meshery model of %s.", displayName)
+	}
+
+	pkg := artifactHubPackage{
+		Version:     version,
+		Name:        artifactHubName(modelDef.Name),
+		DisplayName: displayName,
+		Description: description,
+		Category:    artifactHubCategories[modelDef.Category.Name],
+		Provider:    artifactHubProvider{Name: "Meshery"},
+		Annotations: map[string]string{
+			"meshery.io/model": modelDef.Name,
+		},
+	}
+
+	keywords := []string{"meshery"}
+	for _, value := range []string{modelDef.Category.Name, modelDef.SubCategory, modelDef.Registrant.Kind} {
+		if keyword := strings.ToLower(strings.TrimSpace(value)); keyword != "" && utils.Contains(keyword, keywords) == -1 {
+			keywords = append(keywords, keyword)
+		}
+	}
+	pkg.Keywords = keywords
+	if modelDef.Registrant.Kind != "" {
+		pkg.Annotations["meshery.io/registrant"] = modelDef.Registrant.Kind
+	}
+	if modelDef.Category.Name != "" {
+		pkg.Annotations["meshery.io/category"] = modelDef.Category.Name
+	}
+
+	if modelDef.Metadata != nil {
+		if source, ok := modelDef.Metadata.AdditionalProperties["source_uri"].(string); ok && strings.HasPrefix(source, "http") {
+			pkg.HomeURL = source
+			pkg.Links = append(pkg.Links, artifactHubLink{Name: "Source", URL: source})
+		}
+	}
+	return pkg
+}
+
+// artifactHubCreatedAt returns the creation date of the package of the file path, now for a new package. The date of
+// a package already written is kept, Artifact Hub ordering the versions of a package by it.
+func artifactHubCreatedAt(path string) string {
+	existing := struct {
+		CreatedAt string `yaml:"createdAt"`
+	}{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(data, &existing); err == nil && existing.CreatedAt != "" {
+			return existing.CreatedAt
+		}
+	}
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// artifactHubName returns the name as a valid Artifact Hub package name, lowercase letters and digits separated by -.
+func artifactHubName(name string) string {
+	return strings.Trim(invalidArtifactHubNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,192 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	backstageFormat     = "backstage"
+	backstageAPIVersion = "backstage.io/v1alpha1"
+)
+
+var (
+	// backstageResourceCategories are the categories of the models exported as Backstage Resources, the
+	// infrastructure the other models, exported as Components, run on.
+	backstageResourceCategories = map[string]bool{
+		"Cloud Native Storage": true,
+		"Database":             true,
+		"Provisioning":         true,
+	}
+
+	// backstageLifecycles maps the status of a model to the lifecycle of its entity.
+	backstageLifecycles = map[model.ModelDefinitionStatus]string{
+		model.ModelDefinitionStatusEnabled: "production",
+		model.ModelDefinitionStatusIgnored: "deprecated",
+	}
+
+	invalidBackstageNameChars = regexp.MustCompile(`[^a-zA-Z0-9\-_.]+`)
+	invalidBackstageTagChars  = regexp.MustCompile(`[^a-z0-9:+#]+`)
+)
+
+type backstageEntity struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   backstageMetadata `yaml:"metadata"`
+	Spec       backstageSpec     `yaml:"spec"`
+}
+
+type backstageMetadata struct {
+	Name        string            `yaml:"name"`
+	Title       string            `yaml:"title,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+	Links       []backstageLink   `yaml:"links,omitempty"`
+}
+
+type backstageLink struct {
+	URL   string `yaml:"url"`
+	Title string `yaml:"title,omitempty"`
+	Icon  string `yaml:"icon,omitempty"`
+}
+
+type backstageSpec struct {
+	Type      string `yaml:"type"`
+	Lifecycle string `yaml:"lifecycle,omitempty"`
+	Owner     string `yaml:"owner"`
+	System    string `yaml:"system,omitempty"`
+}
+
+// writeBackstageCatalog writes the Backstage entities of the models to the catalog-info file path.
+func writeBackstageCatalog(modelDefs []model.ModelDefinition, path, mesheryURL string) error {
+	var out bytes.Buffer
+	for i, modelDef := range modelDefs {
+		data, err := yaml.Marshal(backstageEntityOf(modelDef, mesheryURL))
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
+
+// backstageEntityOf returns the Backstage entity of the model, linking back to the Meshery Server at mesheryURL.
+func backstageEntityOf(modelDef model.ModelDefinition, mesheryURL string) backstageEntity {
+	mesheryURL = strings.TrimSuffix(mesheryURL, "/")
+	kind := "Component"
+	if backstageResourceCategories[modelDef.Category.Name] {
+		kind = "Resource"
+	}
+
+	annotations := map[string]string{
+		"meshery.io/model":         modelDef.Name,
+		"meshery.io/model-version": modelDef.Model.Version,
+		"meshery.io/url":           mesheryURL,
+	}
+	if modelDef.Registrant.Kind != "" {
+		annotations["meshery.io/registrant"] = modelDef.Registrant.Kind
+	}
+	if modelDef.Category.Name != "" {
+		annotations["meshery.io/category"] = modelDef.Category.Name
+	}
+
+	links := []backstageLink{
+		{
+			URL:   fmt.Sprintf("%s/settings?settingsCategory=Registry&tab=Models&searchText=%s", mesheryURL, url.QueryEscape(modelDef.Name)),
+			Title: "Meshery Registry",
+			Icon:  "dashboard",
+		},
+		{
+			URL:   fmt.Sprintf("%s/api/meshmodels/models/%s", mesheryURL, url.PathEscape(modelDef.Name)),
+			Title: "Model Definition",
+			Icon:  "code",
+		},
+	}
+	if modelDef.Metadata != nil {
+		if source, ok := modelDef.Metadata.AdditionalProperties["source_uri"].(string); ok && strings.HasPrefix(source, "http") {
+			annotations["backstage.io/source-location"] = "url:" + source
+			links = append(links, backstageLink{URL: source, Title: "Source", Icon: "github"})
+		}
+	}
+
+	tags := []string{"meshery"}
+	for _, value := range []string{modelDef.Category.Name, modelDef.SubCategory} {
+		if tag := backstageTag(value); tag != "" && tag != tags[len(tags)-1] {
+			tags = append(tags, tag)
+		}
+	}
+
+	title := modelDef.DisplayName
+	if title == "" {
+		title = modelDef.Name
+	}
+	spec := backstageSpec{
+		Type:   "meshery-model",
+		Owner:  exportOwner,
+		System: exportSystem,
+	}
+	if kind == "Component" {
+		spec.Lifecycle = backstageLifecycles[modelDef.Status]
+		if spec.Lifecycle == "" {
+			spec.Lifecycle = "experimental"
+		}
+	}
+
+	return backstageEntity{
+		APIVersion: backstageAPIVersion,
+		Kind:       kind,
+		Metadata: backstageMetadata{
+			Name:        backstageName(modelDef.Name),
+			Title:       title,
+			Description: modelDef.Description,
+			Annotations: annotations,
+			Tags:        tags,
+			Links:       links,
+		},
+		Spec: spec,
+	}
+}
+
+// backstageName returns the name as a valid Backstage entity name: at most 63 characters of letters, digits
+// and the separators -, _ and ., starting and ending with a letter or a digit.
+func backstageName(name string) string {
+	name = invalidBackstageNameChars.ReplaceAllString(name, "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.Trim(name, "-_.")
+}
+
+// backstageTag returns the value as a valid Backstage tag, e.g. "Cloud Native Storage" is cloud-native-storage.
+func backstageTag(value string) string {
+	tag := invalidBackstageTagChars.ReplaceAllString(strings.ToLower(value), "-")
+	if len(tag) > 63 {
+		tag = tag[:63]
+	}
+	return strings.Trim(tag, "-")
+}
@@ -0,0 +1,132 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	mutils "github.com/layer5io/meshkit/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// knownRegistrants are the registrants models are generated from, whatever the spreadsheet lists.
+	knownRegistrants = []string{"artifacthub", "github", "meshery"}
+	// spreadsheetCacheDir is where the sheets of the spreadsheet are downloaded to by generate and update.
+	spreadsheetCacheDir = filepath.Join(mutils.GetHome(), ".meshery", "content")
+)
+
+// completeModelNames completes --model with the models of the models directory given by the flag dirFlag,
+// along with the models of the spreadsheet last downloaded or of the CSV files of --directory.
+func completeModelNames(dirFlag string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names := []string{}
+		if flag := cmd.Flag(dirFlag); flag != nil {
+			entries, _ := os.ReadDir(flag.Value.String())
+			for _, entry := range entries {
+				if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+					names = append(names, entry.Name())
+				}
+			}
+		}
+		for _, path := range spreadsheetCSVFiles(cmd) {
+			names = append(names, csvColumnValues(path, "model")...)
+		}
+		return completions(names, toComplete, false), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeRegistrants completes --registrant with the known registrants, along with the registrants of the
+// spreadsheet last downloaded or of the CSV files of --directory.
+func completeRegistrants(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	registrants := append([]string{}, knownRegistrants...)
+	for _, path := range spreadsheetCSVFiles(cmd) {
+		registrants = append(registrants, csvColumnValues(path, "registrant")...)
+	}
+	return completions(registrants, toComplete, true), cobra.ShellCompDirectiveNoFileComp
+}
+
+// spreadsheetCSVFiles returns the CSV files of the models and of the components, of --directory when it is
+// set, downloaded from the spreadsheet otherwise.
+func spreadsheetCSVFiles(cmd *cobra.Command) []string {
+	if flag := cmd.Flag("directory"); flag != nil && flag.Value.String() != "" {
+		models, components, _, err := utils.GetCsv(flag.Value.String())
+		if err == nil {
+			return []string{models, components}
+		}
+		return nil
+	}
+	return []string{filepath.Join(spreadsheetCacheDir, "models.csv"), filepath.Join(spreadsheetCacheDir, "components.csv")}
+}
+
+// csvColumnValues returns the values of the column of the CSV file, the file missing or not. The header is
+// the first of the leading rows naming the column, the sheets of the spreadsheet starting with a title row.
+func csvColumnValues(path, column string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil
+	}
+
+	values := []string{}
+	index := -1
+	for _, row := range rows {
+		if index == -1 {
+			for i, cell := range row {
+				if strings.TrimSpace(cell) == column {
+					index = i
+					break
+				}
+			}
+			continue
+		}
+		if index < len(row) && strings.TrimSpace(row[index]) != "" {
+			values = append(values, strings.TrimSpace(row[index]))
+		}
+	}
+	return values
+}
+
+// completions returns the values starting with toComplete, case insensitively, once each and sorted.
+// Registrants are normalized as the generators match them, e.g. "Artifact Hub" is artifacthub.
+func completions(values []string, toComplete string, normalize bool) []string {
+	seen := map[string]bool{}
+	matches := []string{}
+	prefix := strings.ToLower(toComplete)
+	for _, value := range values {
+		if normalize {
+			value = mutils.ReplaceSpacesAndConvertToLowercase(value)
+		}
+		if seen[value] || !strings.HasPrefix(strings.ToLower(value), prefix) {
+			continue
+		}
+		seen[value] = true
+		matches = append(matches, value)
+	}
+	sort.Strings(matches)
+	return matches
+}
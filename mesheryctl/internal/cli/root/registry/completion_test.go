@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRegistryFlagCompletion(t *testing.T) {
+	modelsDir := t.TempDir()
+	for _, name := range []string{"aws-ec2-controller", "aws-s3-controller", "istio-base", ".git"} {
+		if err := os.Mkdir(filepath.Join(modelsDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cacheDir := t.TempDir()
+	models := "Models,,\nregistrant,model,modelDisplayName\nArtifact Hub,aws-rds-controller,AWS RDS\nGitHub,istio-base,Istio\nkubernetes,kubernetes,Kubernetes\n"
+	if err := os.WriteFile(filepath.Join(cacheDir, "models.csv"), []byte(models), 0644); err != nil {
+		t.Fatal(err)
+	}
+	previous := spreadsheetCacheDir
+	spreadsheetCacheDir = cacheDir
+	t.Cleanup(func() {
+		spreadsheetCacheDir = previous
+	})
+
+	cmd := &cobra.Command{Use: "update"}
+	cmd.Flags().String("input", modelsDir, "")
+
+	tests := []struct {
+		name       string
+		complete   func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)
+		toComplete string
+		want       []string
+	}{
+		{name: "models", complete: completeModelNames("input"), toComplete: "aws", want: []string{"aws-ec2-controller", "aws-rds-controller", "aws-s3-controller"}},
+		{name: "models listed once", complete: completeModelNames("input"), toComplete: "ISTIO", want: []string{"istio-base"}},
+		{name: "registrants", complete: completeRegistrants, toComplete: "", want: []string{"artifacthub", "github", "kubernetes", "meshery"}},
+		{name: "registrants by prefix", complete: completeRegistrants, toComplete: "k", want: []string{"kubernetes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := tt.complete(cmd, nil, tt.toComplete)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the completions %v, got %v", tt.want, got)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("expected file completion to be disabled, got %v", directive)
+			}
+		})
+	}
+}
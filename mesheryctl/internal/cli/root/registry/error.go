@@ -13,6 +13,10 @@ var (
 	ErrUpdateComponentCode   = "mesheryctl-1058"
 	ErrUpdateRegistryCode    = "mesheryctl-1059"
 	ErrParsingSheetCode      = "mesheryctl-1128"
+	ErrPublishSnapshotCode   = "mesheryctl-1137"
+	ErrUpgradeSnapshotCode   = "mesheryctl-1138"
+	ErrExportRegistryCode    = "mesheryctl-1144"
+	ErrRegistryMetricsCode   = "mesheryctl-1146"
 )
 
 func ErrUpdateRegistry(err error, path string) error {
@@ -37,3 +41,19 @@ func ErrUpdateComponent(err error, modelName, compName string) error {
 func ErrParsingSheet(err error, obj string) error {
 	return errors.New(ErrParsingSheetCode, errors.Alert, []string{fmt.Sprintf("error parsing %s sheet", obj)}, []string{fmt.Sprintf("while parsing the %s sheet encountered an error: %s", obj, err)}, []string{"provied sheet id for %s might be incorrect"}, []string{"ensure the sheet id is correct"})
 }
+
+func ErrPublishSnapshot(err error, version string) error {
+	return errors.New(ErrPublishSnapshotCode, errors.Alert, []string{fmt.Sprintf("error publishing registry snapshot %s", version)}, []string{err.Error()}, []string{"Models directory does not exist", "Provided credential is incorrect", "OCI registry is not reachable"}, []string{"Ensure the path to the models directory is correct", "Ensure correct OCI registry credentials are used"})
+}
+
+func ErrUpgradeSnapshot(err error, version string) error {
+	return errors.New(ErrUpgradeSnapshotCode, errors.Alert, []string{fmt.Sprintf("error pinning Meshery Server to registry snapshot %s", version)}, []string{err.Error()}, []string{"Registry snapshot version does not exist", "Meshery Server cannot reach the OCI registry"}, []string{"Ensure the snapshot version has been published", "Ensure Meshery Server can reach the OCI registry"})
+}
+
+func ErrExportRegistry(err error, format string) error {
+	return errors.New(ErrExportRegistryCode, errors.Alert, []string{fmt.Sprintf("error exporting the registry as %s", format)}, []string{err.Error()}, []string{"Models directory does not exist", "Model definition is corrupted", "Output file cannot be written"}, []string{"Ensure the path to the models directory is correct", "Regenerate corrupted model", "Ensure sufficient permissions to write the output file"})
+}
+
+func ErrRegistryMetrics(err error, target string) error {
+	return errors.New(ErrRegistryMetricsCode, errors.Alert, []string{fmt.Sprintf("error exposing the metrics of the registry pipeline to %s", target)}, []string{err.Error()}, []string{"The metrics listen address is in use or invalid", "The Pushgateway is not reachable"}, []string{"Use a free address of the form host:port for --metrics-listen-address", "Ensure the URL of --pushgateway is correct and reachable"})
+}
@@ -0,0 +1,188 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/semver"
+)
+
+const defaultMesheryURL = "http://localhost:9081"
+
+var (
+	exportFormat     string
+	exportInput      string
+	exportOutput     string
+	exportModel      string
+	exportOwner      string
+	exportSystem     string
+	exportMesheryURL string
+
+	// defaultExportOutputs are the files or directories the formats are written to without --output.
+	defaultExportOutputs = map[string]string{
+		backstageFormat:   "catalog-info.yaml",
+		artifactHubFormat: "artifacthub",
+	}
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the registry to other catalogs",
+	Long: `Export the models of the models directory to the catalog of another system, each model at its latest version.
+
+With --format backstage, a Backstage catalog-info YAML file is written with an entity per model: a Resource for the models of infrastructure categories (Cloud Native Storage, Database, Provisioning) and a Component for the others. Entities are annotated with the model, its version, registrant and category, and link back to the model in Meshery.
+
+With --format artifacthub, an Artifact Hub package is written per model to the directory <output>/<model>/<version>: its artifacthub-pkg.yml metadata and the logo of the model. Packages already in the directory are kept, so that the directory can be the Artifact Hub repository the registry pipeline publishes to.`,
+	Example: `
+// Export the models of the meshery/meshery repo to a Backstage catalog
+mesheryctl registry export --format backstage -o catalog-info.yaml
+
+// Export a model, owned by a Backstage group and linking back to a Meshery deployment
+mesheryctl registry export --format backstage --model istio-base --owner group:platform --meshery-url https://meshery.example.com
+
+// Export the models of the meshery/meshery repo as the packages of an Artifact Hub repository
+mesheryctl registry export --format artifacthub -o ../artifacthub/integrations
+	`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := defaultExportOutputs[exportFormat]; !ok {
+			return utils.ErrInvalidArgument(errors.Errorf("unsupported export format %q, supported formats: %s, %s", exportFormat, backstageFormat, artifactHubFormat))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := exportOutput
+		if output == "" {
+			output = defaultExportOutputs[exportFormat]
+		}
+
+		modelDefs, err := latestModelDefinitions(exportInput, exportModel)
+		if err != nil {
+			return ErrExportRegistry(err, exportFormat)
+		}
+		if len(modelDefs) == 0 {
+			utils.Log.Info("No model found in ", exportInput)
+			return nil
+		}
+
+		switch exportFormat {
+		case backstageFormat:
+			mesheryURL := exportMesheryURL
+			if mesheryURL == "" {
+				mesheryURL = defaultMesheryURL
+				if mctlCfg, err := config.GetMesheryCtl(viper.GetViper()); err == nil {
+					mesheryURL = mctlCfg.GetBaseMesheryURL()
+				}
+			}
+			err = writeBackstageCatalog(modelDefs, output, mesheryURL)
+		case artifactHubFormat:
+			err = writeArtifactHubPackages(modelDefs, output)
+		}
+		if err != nil {
+			return ErrExportRegistry(err, exportFormat)
+		}
+		utils.Log.Info(fmt.Sprintf("Exported %d models to %s", len(modelDefs), output))
+		return nil
+	},
+}
+
+// latestModelDefinitions returns the definitions of the models of the models directory at their latest
+// version, sorted by name. With modelFilter, only the definition of this model is returned.
+func latestModelDefinitions(modelsDir, modelFilter string) ([]model.ModelDefinition, error) {
+	paths, err := filepath.Glob(filepath.Join(modelsDir, "*", "*", "*", "model.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]string{}
+	for _, path := range paths {
+		versionDir := filepath.Dir(filepath.Dir(path))
+		name := filepath.Base(filepath.Dir(versionDir))
+		if modelFilter != "" && name != modelFilter {
+			continue
+		}
+		if current, ok := latest[name]; !ok || compareModelVersions(path, current) > 0 {
+			latest[name] = path
+		}
+	}
+
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	modelDefs := []model.ModelDefinition{}
+	for _, name := range names {
+		data, err := os.ReadFile(latest[name])
+		if err != nil {
+			return nil, err
+		}
+		modelDef := model.ModelDefinition{}
+		if err := json.Unmarshal(data, &modelDef); err != nil {
+			return nil, errors.Wrapf(err, "invalid model definition %s", latest[name])
+		}
+		if modelDef.Name == "" {
+			modelDef.Name = name
+		}
+		modelDefs = append(modelDefs, modelDef)
+	}
+	return modelDefs, nil
+}
+
+// compareModelVersions compares the model.json files of two versions of a model by the version of the model,
+// then by the version of the definition. Versions are compared as semantic versions, with or without v.
+func compareModelVersions(a, b string) int {
+	versions := func(path string) (string, string) {
+		defDir := filepath.Dir(path)
+		return canonicalVersion(filepath.Base(filepath.Dir(defDir))), canonicalVersion(filepath.Base(defDir))
+	}
+	aVersion, aDefVersion := versions(a)
+	bVersion, bDefVersion := versions(b)
+	if c := semver.Compare(aVersion, bVersion); c != 0 {
+		return c
+	}
+	return semver.Compare(aDefVersion, bDefVersion)
+}
+
+func canonicalVersion(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", backstageFormat, "format of the export, one of: backstage, artifacthub")
+	exportCmd.Flags().StringVarP(&exportInput, "input", "i", "../server/meshmodel", "relative or absolute input path to the models directory")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file (backstage) or directory (artifacthub) the export is written to, catalog-info.yaml or artifacthub by default")
+	exportCmd.Flags().StringVarP(&exportModel, "model", "m", "", "export only this model")
+	exportCmd.Flags().StringVar(&exportOwner, "owner", "meshery", "Backstage owner of the entities, e.g. group:platform")
+	exportCmd.Flags().StringVar(&exportSystem, "system", "", "Backstage system the entities are part of")
+	exportCmd.Flags().StringVar(&exportMesheryURL, "meshery-url", "", "URL of the Meshery Server the Backstage entities link back to, of the current context by default")
+	_ = exportCmd.RegisterFlagCompletionFunc("model", completeModelNames("input"))
+	_ = exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{backstageFormat, artifactHubFormat}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/meshery/schemas/models/v1beta1/category"
+	"github.com/meshery/schemas/models/v1beta1/connection"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"gopkg.in/yaml.v2"
+)
+
+func TestBackstageExport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"istio-base/1.19.0/v1.0.0/model.json": `{"name": "istio-base", "displayName": "Istio Base", "status": "enabled", "category": {"name": "Cloud Native Network"}, "model": {"version": "1.19.0"}}`,
+		"istio-base/1.20.1/v1.0.0/model.json": `{"name": "istio-base", "displayName": "Istio Base", "status": "enabled", "category": {"name": "Cloud Native Network"}, "model": {"version": "1.20.1"}, "registrant": {"kind": "artifacthub"}, "metadata": {"source_uri": "https://github.com/istio/istio"}}`,
+		"istio-base/1.9.0/v1.0.0/model.json":  `{"name": "istio-base", "model": {"version": "1.9.0"}}`,
+		"rook/v1.12.0/v1.0.0/model.json":      `{"name": "rook", "category": {"name": "Cloud Native Storage"}, "model": {"version": "v1.12.0"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modelDefs, err := latestModelDefinitions(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modelDefs) != 2 {
+		t.Fatalf("expected the models istio-base and rook, got %d models", len(modelDefs))
+	}
+	if modelDefs[0].Model.Version != "1.20.1" {
+		t.Errorf("expected the latest version 1.20.1 of istio-base, got %s", modelDefs[0].Model.Version)
+	}
+
+	istio := backstageEntityOf(modelDefs[0], "https://meshery.example.com/")
+	if istio.Kind != "Component" || istio.Spec.Lifecycle != "production" {
+		t.Errorf("expected a Component in production, got a %s in %s", istio.Kind, istio.Spec.Lifecycle)
+	}
+	if got := istio.Metadata.Annotations["backstage.io/source-location"]; got != "url:https://github.com/istio/istio" {
+		t.Errorf("expected the source location of the model, got %q", got)
+	}
+	if got := istio.Metadata.Annotations["meshery.io/registrant"]; got != "artifacthub" {
+		t.Errorf("expected the registrant of the model, got %q", got)
+	}
+	if got := istio.Metadata.Links[1].URL; got != "https://meshery.example.com/api/meshmodels/models/istio-base" {
+		t.Errorf("expected a link back to the model in Meshery, got %s", got)
+	}
+	if len(istio.Metadata.Tags) != 2 || istio.Metadata.Tags[1] != "cloud-native-network" {
+		t.Errorf("expected the tags meshery and cloud-native-network, got %v", istio.Metadata.Tags)
+	}
+
+	rook := backstageEntityOf(modelDefs[1], "https://meshery.example.com")
+	if rook.Kind != "Resource" || rook.Spec.Lifecycle != "" {
+		t.Errorf("expected a Resource without lifecycle, got a %s in %s", rook.Kind, rook.Spec.Lifecycle)
+	}
+
+	modelDefs, err = latestModelDefinitions(dir, "rook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modelDefs) != 1 || modelDefs[0].Name != "rook" {
+		t.Errorf("expected only the model rook, got %d models", len(modelDefs))
+	}
+
+	if got := backstageName("aws/ec2 controller"); got != "aws-ec2-controller" {
+		t.Errorf("expected a valid entity name, got %s", got)
+	}
+}
+
+func TestArtifactHubExport(t *testing.T) {
+	_ = utils.SetupMeshkitLoggerTesting(t, false)
+	dir := t.TempDir()
+	modelDefs := []model.ModelDefinition{
+		{
+			Name:        "istio-base",
+			DisplayName: "Istio Base",
+			Category:    category.CategoryDefinition{Name: "Cloud Native Network"},
+			Model:       model.Model{Version: "1.20.1"},
+			Registrant:  connection.Connection{Kind: "artifacthub"},
+			Metadata:    &model.ModelDefinition_Metadata{SvgColor: "<svg></svg>"},
+		},
+		{Name: "latest", Model: model.Model{Version: "latest"}},
+	}
+	if err := writeArtifactHubPackages(modelDefs, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgDir := filepath.Join(dir, "istio-base", "1.20.1")
+	data, err := os.ReadFile(filepath.Join(pkgDir, artifactHubPackageFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := artifactHubPackage{}
+	if err := yaml.Unmarshal(data, &pkg); err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Category != "networking" || pkg.LogoPath != artifactHubLogoFile || pkg.CreatedAt == "" {
+		t.Errorf("expected a networking package with a logo and a creation date, got %+v", pkg)
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, artifactHubLogoFile)); err != nil {
+		t.Errorf("expected the logo of the model to be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "latest")); err == nil {
+		t.Error("expected a model without semantic version to be skipped")
+	}
+
+	createdAt := "2024-01-02T03:04:05Z"
+	pkg.CreatedAt = createdAt
+	data, _ = yaml.Marshal(pkg)
+	if err := os.WriteFile(filepath.Join(pkgDir, artifactHubPackageFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeArtifactHubPackages(modelDefs, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := artifactHubCreatedAt(filepath.Join(pkgDir, artifactHubPackageFile)); got != createdAt {
+		t.Errorf("expected the creation date of the package to be kept, got %s", got)
+	}
+}
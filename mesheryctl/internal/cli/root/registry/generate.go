@@ -48,7 +48,7 @@ var (
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate Models",
-	Long:  "Prerequisite: Excecute this command from the root of a meshery/meshery repo fork.\n\nGiven a Google Sheet with a list of model names and source locations, generate models and components any Registrant (e.g. GitHub, Artifact Hub) repositories.\n\nGenerated Model files are written to local filesystem under `/server/models/<model-name>`.",
+	Long:  "Prerequisite: Excecute this command from the root of a meshery/meshery repo fork.\n\nGiven a Google Sheet with a list of model names and source locations, generate models and components any Registrant (e.g. GitHub, Artifact Hub) repositories.\n\nModels of the registrant `cue` are generated from the CUE packages of a local directory (e.g. file://./apis/payments): every exported definition is a component whose schema is the OpenAPI schema of the definition.\n\nGenerated Model files are written to local filesystem under `/server/models/<model-name>`. With --sbom, the provenance of each model is recorded in an SPDX manifest, sbom.spdx.json, next to its model.json.\n\nWith --metrics-listen-address or --pushgateway, the Prometheus metrics of the run (components processed, updated and errored, duration per model, Google Sheets API latency and retries) are served while it runs or pushed once it completes.",
 	Example: `
 // Generate Meshery Models from a Google Spreadsheet (i.e. "Meshery Integrations" spreadsheet).
 mesheryctl registry generate --spreadsheet-id "1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw" --spreadsheet-cred $CRED
@@ -58,6 +58,10 @@ mesheryctl registry generate --registrant-def [path to connection definition] --
 mesheryctl registry generate --spreadsheet-id "1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw" --spreadsheet-cred --model "[model-name]"
 // Generate Meshery Models and Component from csv files in a local directory.
 mesheryctl registry generate --directory <DIRECTORY_PATH>
+// Generate Meshery Models from csv files in a local directory, recording the provenance of each model.
+mesheryctl registry generate --directory <DIRECTORY_PATH> --sbom
+// Generate Meshery Models from a Google Spreadsheet, serving the metrics of the generation to Prometheus while it runs.
+mesheryctl registry generate --spreadsheet-id "1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw" --spreadsheet-cred $CRED --metrics-listen-address :9464
     `,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		// Prerequisite check is needed - https://github.com/meshery/meshery/issues/10369
@@ -75,7 +79,11 @@ mesheryctl registry generate --directory <DIRECTORY_PATH>
 			utils.Log.Info("Model generation from Registrant definitions not yet supported.")
 			return nil
 		}
-		var err error
+		finishMetrics, err := startRegistryMetrics(utils.RegistryOperationGenerate)
+		if err != nil {
+			return err
+		}
+		defer finishMetrics()
 
 		if csvDirectory == "" {
 			srv, err = mutils.NewSheetSRV(spreadsheeetCred)
@@ -84,7 +92,11 @@ mesheryctl registry generate --directory <DIRECTORY_PATH>
 				return nil
 			}
 
-			resp, err := srv.Spreadsheets.Get(spreadsheeetID).Fields().Do()
+			var resp *sheets.Spreadsheet
+			err = utils.CallSheetsAPI("spreadsheets.get", func() (err error) {
+				resp, err = srv.Spreadsheets.Get(spreadsheeetID).Fields().Do()
+				return err
+			})
 			if err != nil || resp.HTTPStatusCode != 200 {
 				utils.LogError.Error(ErrUpdateRegistry(err, outputLocation))
 				return nil
@@ -125,6 +137,7 @@ mesheryctl registry generate --directory <DIRECTORY_PATH>
 
 		utils.Log.UpdateLogOutput(multiWriter)
 		utils.LogError.UpdateLogOutput(multiErrorWriter)
+		defer utils.SetRegistryProgressHandler(utils.PrintRegistryProgress(os.Stdout))()
 		err = utils.InvokeGenerationFromSheet(&wg, registryLocation, sheetGID, componentSpredsheetGID, spreadsheeetID, modelName, modelCSVFilePath, componentCSVFilePath, spreadsheeetCred, relationshipCSVFilePath, relationshipSpredsheetGID, srv)
 		if err != nil {
 			// meshkit
@@ -143,6 +156,7 @@ mesheryctl registry generate --directory <DIRECTORY_PATH>
 func init() {
 	generateCmd.PersistentFlags().StringVar(&spreadsheeetID, "spreadsheet-id", "", "spreadsheet ID for the integration spreadsheet")
 	generateCmd.PersistentFlags().StringVar(&spreadsheeetCred, "spreadsheet-cred", "", "base64 encoded credential to download the spreadsheet")
+	generateCmd.PersistentFlags().BoolVar(&utils.GenerateSBOM, "sbom", false, "record the provenance of each model (sheet row, CSV file hash, upstream source and version, generation time and mesheryctl version) in an SPDX manifest, sbom.spdx.json, next to its model.json")
 
 	generateCmd.MarkFlagsRequiredTogether("spreadsheet-id", "spreadsheet-cred")
 
@@ -155,7 +169,9 @@ func init() {
 	generateCmd.MarkFlagsMutuallyExclusive("spreadsheet-cred", "registrant-cred")
 	generateCmd.PersistentFlags().StringVarP(&modelName, "model", "m", "", "specific model name to be generated")
 	generateCmd.PersistentFlags().StringVarP(&outputLocation, "output", "o", "../server/meshmodel", "location to output generated models, defaults to ../server/meshmodels")
+	_ = generateCmd.RegisterFlagCompletionFunc("model", completeModelNames("output"))
 
 	generateCmd.PersistentFlags().StringVarP(&csvDirectory, "directory", "d", "", "Directory containing the Model and Component CSV files")
+	addRegistryMetricsFlags(generateCmd.PersistentFlags())
 
 }
@@ -0,0 +1,168 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/manifoldco/promptui"
+)
+
+// diffContextLines is the number of unchanged lines shown around the changes of a component.
+const diffContextLines = 3
+
+// pendingComponentUpdate is an update of a component held back until the maintainer confirms it.
+type pendingComponentUpdate struct {
+	version  string
+	kind     string
+	path     string
+	existing []byte
+	updated  []byte
+}
+
+// selectModelsPrompt lets the maintainer pick the models of the spreadsheet to update, all of them selected at
+// first. Enter toggles a model, / searches them. It returns the selected models, or false if the update is aborted.
+func selectModelsPrompt(models []string) (map[string]bool, bool) {
+	sort.Strings(models)
+	selected := map[string]bool{}
+	for _, model := range models {
+		selected[model] = true
+	}
+
+	cursor, scroll := 0, 0
+	for {
+		count := 0
+		items := make([]string, 0, len(models)+3)
+		for _, model := range models {
+			mark := "[ ]"
+			if selected[model] {
+				mark = "[x]"
+				count++
+			}
+			items = append(items, fmt.Sprintf("%s %s", mark, model))
+		}
+		items = append([]string{fmt.Sprintf("Continue with %d of %d models", count, len(models)), "Select all", "Select none"}, items...)
+
+		prompt := promptui.Select{
+			Label: "Select the models to update",
+			Items: items,
+			Size:  15,
+			Searcher: func(input string, index int) bool {
+				return strings.Contains(strings.ToLower(items[index]), strings.ToLower(input))
+			},
+		}
+		i, _, err := prompt.RunCursorAt(cursor, scroll)
+		if err != nil {
+			return nil, false
+		}
+		cursor, scroll = i, prompt.Size*(i/prompt.Size)
+
+		switch i {
+		case 0:
+			return selected, true
+		case 1, 2:
+			for _, model := range models {
+				selected[model] = i == 1
+			}
+		default:
+			model := models[i-3]
+			selected[model] = !selected[model]
+		}
+	}
+}
+
+// confirmComponentUpdates shows the changes to the components of the model and asks whether to write them.
+func confirmComponentUpdates(w io.Writer, model string, updates []pendingComponentUpdate) bool {
+	for _, update := range updates {
+		fmt.Fprintf(w, "\n%s %s (%s)\n", color.New(color.Bold).Sprint(model), update.kind, update.version)
+		for _, line := range diffLines(string(update.existing), string(update.updated)) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				line = color.GreenString(line)
+			case strings.HasPrefix(line, "-"):
+				line = color.RedString(line)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	fmt.Fprintln(w)
+	return utils.AskForConfirmation(fmt.Sprintf("Write the changes to %d components of model %s", len(updates), model))
+}
+
+// diffLines returns the lines removed from a, prefixed with -, and added to b, prefixed with +, along with
+// diffContextLines unchanged lines around them. Skipped unchanged lines are marked with @@.
+func diffLines(a, b string) []string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := []string{}
+	for i, j := 0, 0; i < len(x) || j < len(y); {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+
+	// keep the unchanged lines close enough to a change
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, i-diffContextLines); k <= min(len(lines)-1, i+diffContextLines); k++ {
+			keep[k] = true
+		}
+	}
+	diff := []string{}
+	if !slices.Contains(keep, true) {
+		return diff
+	}
+	for i, line := range lines {
+		if keep[i] {
+			diff = append(diff, line)
+		} else if i == 0 || keep[i-1] {
+			diff = append(diff, "@@")
+		}
+	}
+	return diff
+}
@@ -0,0 +1,29 @@
+package registry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{name: "unchanged", a: "a\nb", b: "a\nb", want: []string{}},
+		{name: "changed line", a: "{\n \"kind\": \"Pod\",\n \"shape\": \"circle\"\n}", b: "{\n \"kind\": \"Pod\",\n \"shape\": \"round-rectangle\"\n}",
+			want: []string{" {", "  \"kind\": \"Pod\",", "- \"shape\": \"circle\"", "+ \"shape\": \"round-rectangle\"", " }"}},
+		{name: "added line", a: "a\nc", b: "a\nb\nc", want: []string{" a", "+b", " c"}},
+		{name: "far from the changes", a: strings.Repeat("x\n", 10) + "a", b: strings.Repeat("x\n", 10) + "b",
+			want: []string{"@@", " x", " x", " x", "-a", "+b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the diff %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/spf13/pflag"
+)
+
+var (
+	metricsListenAddress string
+	pushgatewayURL       string
+)
+
+// addRegistryMetricsFlags adds the flags exposing the metrics of the registry pipeline of a command.
+func addRegistryMetricsFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&metricsListenAddress, "metrics-listen-address", "", "serve the Prometheus metrics of the pipeline at http://<address>/metrics while the command runs, e.g. :9464")
+	flags.StringVar(&pushgatewayURL, "pushgateway", "", "push the Prometheus metrics of the pipeline to this Pushgateway once the command completes, e.g. http://pushgateway:9091")
+}
+
+// startRegistryMetrics measures the registry pipeline of the operation when its metrics are served or pushed, and
+// returns the function to call once the operation completes, which pushes the metrics and stops serving them.
+func startRegistryMetrics(operation string) (func(), error) {
+	if metricsListenAddress == "" && pushgatewayURL == "" {
+		return func() {}, nil
+	}
+	registry := utils.EnableRegistryMetrics()
+
+	var server *http.Server
+	if metricsListenAddress != "" {
+		listener, err := net.Listen("tcp", metricsListenAddress)
+		if err != nil {
+			return nil, ErrRegistryMetrics(err, metricsListenAddress)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				utils.Log.Error(ErrRegistryMetrics(err, metricsListenAddress))
+			}
+		}()
+		utils.Log.Info("Serving the metrics of the registry ", operation, " at http://", listener.Addr().String(), "/metrics")
+	}
+
+	return func() {
+		if pushgatewayURL != "" {
+			if err := push.New(pushgatewayURL, "mesheryctl_registry_"+operation).Gatherer(registry).Push(); err != nil {
+				utils.Log.Error(ErrRegistryMetrics(err, pushgatewayURL))
+			}
+		}
+		if server != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(ctx)
+		}
+	}, nil
+}
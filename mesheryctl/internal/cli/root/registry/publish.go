@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"google.golang.org/api/sheets/v4"
 
 	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
 	meshkitUtils "github.com/layer5io/meshkit/utils"
@@ -97,7 +98,11 @@ mesheryctl registry publish website $CRED 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwiz
 			utils.Log.Error(err)
 			return nil
 		}
-		resp, err := srv.Spreadsheets.Get(sheetID).Fields().Do()
+		var resp *sheets.Spreadsheet
+		err = utils.CallSheetsAPI("spreadsheets.get", func() (err error) {
+			resp, err = srv.Spreadsheets.Get(sheetID).Fields().Do()
+			return err
+		})
 		if err != nil || resp.HTTPStatusCode != 200 {
 			utils.Log.Error(err)
 			return nil
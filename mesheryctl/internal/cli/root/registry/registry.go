@@ -24,7 +24,7 @@ import (
 )
 
 var (
-	availableSubcommands = []*cobra.Command{generateCmd, publishCmd, updateCmd}
+	availableSubcommands = []*cobra.Command{generateCmd, publishCmd, updateCmd, snapshotCmd, exportCmd}
 
 	spreadsheeetID          string
 	spreadsheeetCred        string
@@ -0,0 +1,218 @@
+// # Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	servermodels "github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/oci"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	snapshotVersion    string
+	snapshotRegistry   string
+	snapshotRepository string
+	snapshotUsername   string
+	snapshotPassword   string
+	snapshotInput      string
+
+	availableSnapshotSubcommands = []*cobra.Command{publishSnapshotCmd, viewSnapshotCmd, upgradeSnapshotCmd}
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage versioned snapshots of the registry",
+	Long: `Publish versioned snapshots of the full registry as OCI artifacts and pin Meshery Server to a snapshot.
+
+A Meshery Server pinned to a snapshot seeds its registry from that snapshot instead of the models it ships with, and only moves to another snapshot when explicitly upgraded.`,
+	Example: `
+// Publish the models directory as snapshot v0.8.0
+mesheryctl registry snapshot publish --version v0.8.0
+
+// View the snapshot Meshery Server is pinned to
+mesheryctl registry snapshot view
+
+// Pin Meshery Server to snapshot v0.8.0
+mesheryctl registry snapshot upgrade v0.8.0
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		if ok := utils.IsValidSubcommand(availableSnapshotSubcommands, args[0]); !ok {
+			return errors.New(utils.RegistryError(fmt.Sprintf("'%s' is an invalid subcommand. Use 'mesheryctl registry snapshot --help' to display usage guide.\n", args[0]), "registry"))
+		}
+		return nil
+	},
+}
+
+var publishSnapshotCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish a snapshot of the registry",
+	Long:  "Publish the contents of the models directory as a versioned OCI artifact. Run it from a scheduled pipeline to publish periodic snapshots.",
+	Example: `
+// Publish the models directory of the meshery/meshery repo as snapshot v0.8.0
+mesheryctl registry snapshot publish --version v0.8.0 --username [username] --password [token]
+
+// Publish a models directory to a custom repository
+mesheryctl registry snapshot publish -i [path to the directory containing models] --version v0.8.0 --registry docker.io --repository [org]/[repository]
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modelsDir, err := filepath.Abs(snapshotInput)
+		if err != nil {
+			return ErrPublishSnapshot(err, snapshotVersion)
+		}
+		if _, err := os.Stat(modelsDir); err != nil {
+			return ErrPublishSnapshot(err, snapshotVersion)
+		}
+
+		// The artifact stores the models directory under its base name,
+		// so push it relative to its parent directory.
+		pwd, _ := os.Getwd()
+		if err := os.Chdir(filepath.Dir(modelsDir)); err != nil {
+			return ErrPublishSnapshot(err, snapshotVersion)
+		}
+		defer func() {
+			_ = os.Chdir(pwd)
+		}()
+
+		err = oci.PushToOCIRegistry(filepath.Base(modelsDir), snapshotRegistry, snapshotRepository, snapshotVersion, snapshotUsername, snapshotPassword)
+		if err != nil {
+			return ErrPublishSnapshot(err, snapshotVersion)
+		}
+
+		utils.Log.Info(fmt.Sprintf("Published registry snapshot %s to %s/%s", snapshotVersion, snapshotRegistry, snapshotRepository))
+		return nil
+	},
+}
+
+var viewSnapshotCmd = &cobra.Command{
+	Use:   "view",
+	Short: "View the snapshot Meshery Server is pinned to",
+	Long:  "View the registry snapshot Meshery Server seeds its registry from",
+	Example: `
+// View the snapshot Meshery Server is pinned to
+mesheryctl registry snapshot view
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mctlCfg, err := config.GetMesheryCtl(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		url := fmt.Sprintf("%s/api/meshmodels/snapshot", mctlCfg.GetBaseMesheryURL())
+		snapshot, err := doSnapshotRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		if snapshot == nil {
+			utils.Log.Info("Meshery Server is not pinned to a registry snapshot")
+			return nil
+		}
+		utils.Log.Info(fmt.Sprintf("Pinned to registry snapshot %s (%s/%s) since %s", snapshot.Version, snapshot.Registry, snapshot.Repository, snapshot.PinnedAt.Format("2006-01-02 15:04:05")))
+		return nil
+	},
+}
+
+var upgradeSnapshotCmd = &cobra.Command{
+	Use:   "upgrade [version]",
+	Short: "Pin Meshery Server to a registry snapshot",
+	Long:  "Pin Meshery Server to the given registry snapshot version and register its models",
+	Example: `
+// Pin Meshery Server to snapshot v0.8.0
+mesheryctl registry snapshot upgrade v0.8.0
+
+// Snapshots are pulled from the registry and repository Meshery Server is configured with
+	`,
+	Args: func(_ *cobra.Command, args []string) error {
+		const errMsg = "Usage: mesheryctl registry snapshot upgrade [version]\nRun 'mesheryctl registry snapshot upgrade --help' to see detailed help message"
+		if len(args) != 1 {
+			return utils.ErrInvalidArgument(errors.New("Please provide a snapshot version. " + errMsg))
+		}
+		return nil
+	},
+	RunE: func(_ *cobra.Command, args []string) error {
+		mctlCfg, err := config.GetMesheryCtl(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		upgradeRequest := servermodels.RegistrySnapshotUpgradeRequest{Version: args[0]}
+
+		payload, err := json.Marshal(upgradeRequest)
+		if err != nil {
+			return ErrUpgradeSnapshot(err, args[0])
+		}
+
+		url := fmt.Sprintf("%s/api/meshmodels/snapshot", mctlCfg.GetBaseMesheryURL())
+		snapshot, err := doSnapshotRequest(http.MethodPost, url, bytes.NewBuffer(payload))
+		if err != nil {
+			return ErrUpgradeSnapshot(err, args[0])
+		}
+
+		utils.Log.Info(fmt.Sprintf("Meshery Server pinned to registry snapshot %s", snapshot.Version))
+		return nil
+	},
+}
+
+func doSnapshotRequest(method, url string, body io.Reader) (*servermodels.RegistrySnapshot, error) {
+	req, err := utils.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := utils.MakeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, utils.ErrReadResponseBody(err)
+	}
+
+	var snapshot *servermodels.RegistrySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, utils.ErrUnmarshal(err)
+	}
+	return snapshot, nil
+}
+
+func init() {
+	publishSnapshotCmd.Flags().StringVarP(&snapshotInput, "input", "i", "../server/meshmodel", "relative or absolute input path to the models directory")
+	publishSnapshotCmd.Flags().StringVar(&snapshotVersion, "version", "", "version (OCI tag) of the snapshot")
+	publishSnapshotCmd.Flags().StringVar(&snapshotUsername, "username", "", "username for the OCI registry")
+	publishSnapshotCmd.Flags().StringVar(&snapshotPassword, "password", "", "password or token for the OCI registry")
+	_ = publishSnapshotCmd.MarkFlagRequired("version")
+
+	publishSnapshotCmd.Flags().StringVar(&snapshotRegistry, "registry", "ghcr.io", "OCI registry hosting the registry snapshots")
+	publishSnapshotCmd.Flags().StringVar(&snapshotRepository, "repository", "meshery/registry", "repository holding the registry snapshots")
+
+	snapshotCmd.AddCommand(availableSnapshotSubcommands...)
+}
@@ -20,18 +20,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	serverutils "github.com/layer5io/meshery/server/helpers/utils"
 	mutils "github.com/layer5io/meshkit/utils"
 	"github.com/layer5io/meshkit/utils/store"
 	comp "github.com/meshery/schemas/models/v1beta1/component"
 	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/api/sheets/v4"
 )
 
 var (
 	modelLocation            string
+	registrantName           string
+	interactive              bool
+	outputDir                string
+	changedOnly              bool
 	logFile                  *os.File
 	errorLogFile             *os.File
 	sheetGID                 int64
@@ -44,7 +51,7 @@ var (
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update the registry with latest data.",
-	Long:  "Updates the component metadata (SVGs, shapes, styles and other) by referring from a Google Spreadsheet.",
+	Long:  "Updates the component metadata (SVGs, shapes, styles and other) by referring from a Google Spreadsheet.\n\nWith --interactive, the models of the spreadsheet to update are selected from a list, and the changes to their components are shown and confirmed before they are written.\n\nWith --output-dir, the models directory is left untouched: the updated model tree is written to a fresh directory, along with the files which did not change unless --changed-only is set.\n\nWith --sbom, the sheet and rows the components of a model are updated from are recorded in the provenance manifest of the model, sbom.spdx.json, next to its model.json.\n\nWith --metrics-listen-address or --pushgateway, the Prometheus metrics of the run (components processed, updated and errored, duration per model, Google Sheets API latency and retries) are served while it runs or pushed once it completes.\n\nWith shell completion set up (see mesheryctl completion), --model completes the models of the models directory and of the spreadsheet last downloaded, and --registrant the known registrants.",
 	Example: `
 // Update models from Meshery Integration Spreadsheet
 mesheryctl registry update --spreadsheet-id [id] --spreadsheet-cred [base64 encoded spreadsheet credential] -i [path to the directory containing models].
@@ -53,8 +60,26 @@ mesheryctl registry update --spreadsheet-id [id] --spreadsheet-cred [base64 enco
 mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED
 // Updating models in the meshery/meshery repo based on flag
 mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --model "[model-name]"
+// Updating the models of a registrant in the meshery/meshery repo
+mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --registrant artifacthub
+// Selecting the models to update and reviewing the changes to their components before writing them
+mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --interactive
+// Writing the updated models to a fresh directory, e.g. to review or publish them from CI
+mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --output-dir ./updated-models
+// Writing only the updated components to a fresh directory
+mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --output-dir ./updated-models --changed-only
+// Pushing the metrics of a scheduled update to a Prometheus Pushgateway
+mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdwizOJmeMw --spreadsheet-cred $CRED --pushgateway http://pushgateway:9091
 	`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if changedOnly && outputDir == "" {
+			return utils.ErrInvalidArgument(fmt.Errorf("--changed-only requires --output-dir"))
+		}
+		if outputDir != "" {
+			if err := prepareOutputDir(modelLocation, outputDir, changedOnly); err != nil {
+				return ErrUpdateRegistry(err, outputDir)
+			}
+		}
 
 		err := os.MkdirAll(logDirPath, 0755)
 		if err != nil {
@@ -69,13 +94,22 @@ mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdw
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		finishMetrics, err := startRegistryMetrics(utils.RegistryOperationUpdate)
+		if err != nil {
+			return err
+		}
+		defer finishMetrics()
 
 		srv, err := mutils.NewSheetSRV(spreadsheeetCred)
 		if err != nil {
 			utils.Log.Error(ErrUpdateRegistry(err, modelLocation))
 			return err
 		}
-		resp, err := srv.Spreadsheets.Get(spreadsheeetID).Fields().Do()
+		var resp *sheets.Spreadsheet
+		err = utils.CallSheetsAPI("spreadsheets.get", func() (err error) {
+			resp, err = srv.Spreadsheets.Get(spreadsheeetID).Fields().Do()
+			return err
+		})
 		if err != nil || resp.HTTPStatusCode != 200 {
 			utils.Log.Error(ErrUpdateRegistry(err, outputLocation))
 			return err
@@ -83,6 +117,8 @@ mesheryctl registry update --spreadsheet-id 1DZHnzxYWOlJ69Oguz4LkRVTFM79kC2tuvdw
 
 		sheetGID = GetSheetIDFromTitle(resp, "Components")
 
+		// the detailed logs go to the log file, the progress of the models to the terminal
+		defer utils.SetRegistryProgressHandler(utils.PrintRegistryProgress(os.Stdout))()
 		err = InvokeCompUpdate()
 		if err != nil {
 			utils.Log.Error(err)
@@ -112,6 +148,9 @@ func InvokeCompUpdate() error {
 		// Additionally log the summary to the terminal
 		utils.Log.Info(fmt.Sprintf("Updated %d models and %d components", totalAggregateModel, totalAggregateComponents))
 		utils.Log.Info("refer ", logDirPath, " for detailed registry update logs")
+		if outputDir != "" {
+			utils.Log.Info("updated models written to ", outputDir)
+		}
 
 		totalAggregateModel = 0
 		totalAggregateComponents = 0
@@ -130,6 +169,17 @@ func InvokeCompUpdate() error {
 		utils.Log.Error(err)
 		return nil
 	}
+	var componentSheet *utils.SBOMSheet
+	if utils.GenerateSBOM {
+		sheetURL := url
+		if componentCSVFilePath != "" {
+			sheetURL = ""
+		}
+		componentSheet, err = utils.NewSBOMSheet(componentCSVHelper.Title, sheetURL, componentCSVHelper.CSVPath)
+		if err != nil {
+			return err
+		}
+	}
 
 	utils.Log.Info("Total Registrants: ", len(componentCSVHelper.Components))
 
@@ -137,28 +187,53 @@ func InvokeCompUpdate() error {
 	// weightedSem := semaphore.NewWeighted(20)
 	pwd, _ := os.Getwd()
 
+	var selectedModels map[string]bool
+	if interactive {
+		models := []string{}
+		for registrant, model := range componentCSVHelper.Components {
+			if registrant == "" || !isSelectedRegistrant(registrant) {
+				continue
+			}
+			for modelName := range model {
+				models = append(models, modelName)
+			}
+		}
+		var ok bool
+		if selectedModels, ok = selectModelsPrompt(models); !ok {
+			utils.Log.Info("Registry update aborted, no model is updated")
+			return nil
+		}
+	}
+
 	// var wg sync.WaitGroup
 	for registrant, model := range componentCSVHelper.Components {
+		if !isSelectedRegistrant(registrant) {
+			continue
+		}
 		if registrant == "" {
 			continue
 		}
 
 		// Iterate all models
 		for modelName, components := range model {
+			if interactive && !selectedModels[modelName] {
+				continue
+			}
 			availableComponentsPerModelPerVersion := 0
 			modelPath := filepath.Join(pwd, modelLocation, modelName)
 			utils.Log.Info("Starting to update components of model ", modelName)
+			utils.ReportRegistryProgress(utils.RegistryProgress{Operation: utils.RegistryOperationUpdate, Kind: utils.RegistryModelStarted, Model: modelName})
 
 			modelContents, err := os.ReadDir(modelPath)
 			if err != nil {
-				err = ErrUpdateModel(err, modelName)
-				utils.Log.Error(err)
+				reportUpdateError(ErrUpdateModel(err, modelName), modelName, "")
 				continue
 			}
 
 			// Iterate over all content inside model
 			// Comps, relationships, policies
 			compUpdateArray := []compUpdateTracker{}
+			pendingUpdates := []pendingComponentUpdate{}
 			for _, content := range modelContents {
 				totalCompsUpdatedPerModelPerVersion := 0
 
@@ -175,31 +250,25 @@ func InvokeCompUpdate() error {
 					utils.Log.Info("Updating component of model ", modelName, " with version: ", content.Name())
 
 					for _, component := range components {
+						utils.CountRegistryComponentProcessed(utils.RegistryOperationUpdate, modelName)
 						compPath := filepath.Join(versionPath, "components", fmt.Sprintf("%s.json", component.Component))
 						componentByte, err := os.ReadFile(compPath)
 						if err != nil {
-							utils.Log.Error(ErrUpdateComponent(err, modelName, component.Component))
+							reportUpdateError(ErrUpdateComponent(err, modelName, component.Component), modelName, content.Name())
 							continue
 						}
 						componentDef := comp.ComponentDefinition{}
 						err = json.Unmarshal(componentByte, &componentDef)
 						if err != nil {
-							utils.Log.Error(ErrUpdateComponent(err, modelName, component.Component))
+							reportUpdateError(ErrUpdateComponent(err, modelName, component.Component), modelName, content.Name())
 							continue
 						}
 
 						err = component.UpdateCompDefinition(&componentDef)
 						if err != nil {
-							utils.Log.Error(ErrUpdateComponent(err, modelName, component.Component))
+							reportUpdateError(ErrUpdateComponent(err, modelName, component.Component), modelName, content.Name())
 							continue
 						}
-						tmpFilePath := filepath.Join(versionPath, "components", "tmp_model.json")
-
-						// Ensure the temporary file is removed regardless of what happens
-						defer func() {
-							_ = os.Remove(tmpFilePath)
-						}()
-
 						if _, err := os.Stat(compPath); err == nil {
 							existingData, err := os.ReadFile(compPath)
 							if err != nil {
@@ -207,13 +276,8 @@ func InvokeCompUpdate() error {
 								continue
 							}
 
-							err = mutils.WriteJSONToFile[comp.ComponentDefinition](tmpFilePath, componentDef)
-							if err != nil {
-								utils.Log.Error(err)
-								continue
-							}
-
-							newData, err := os.ReadFile(tmpFilePath)
+							// formatted as written by mutils.WriteJSONToFile
+							newData, err := json.MarshalIndent(componentDef, " ", " ")
 							if err != nil {
 								utils.Log.Error(err)
 								continue
@@ -222,18 +286,35 @@ func InvokeCompUpdate() error {
 							if bytes.Equal(existingData, newData) {
 								utils.Log.Info("No changes detected for ", componentDef.Component.Kind)
 								continue
+							} else if interactive {
+								pendingUpdates = append(pendingUpdates, pendingComponentUpdate{
+									version:  content.Name(),
+									kind:     componentDef.Component.Kind,
+									path:     compPath,
+									existing: existingData,
+									updated:  newData,
+								})
 							} else {
-								err = mutils.WriteJSONToFile[comp.ComponentDefinition](compPath, componentDef)
+								outputPath, err := componentOutputPath(compPath)
 								if err != nil {
-									utils.Log.Error(err)
+									reportUpdateError(ErrUpdateComponent(err, modelName, component.Component), modelName, content.Name())
+									continue
+								}
+								err = mutils.WriteJSONToFile[comp.ComponentDefinition](outputPath, componentDef)
+								if err != nil {
+									reportUpdateError(ErrUpdateComponent(err, modelName, component.Component), modelName, content.Name())
 									continue
 								}
 								totalCompsUpdatedPerModelPerVersion++
+								utils.ReportRegistryProgress(utils.RegistryProgress{Operation: utils.RegistryOperationUpdate, Kind: utils.RegistryComponentUpdated, Model: modelName, Version: content.Name(), Component: componentDef.Component.Kind})
 
 							}
 						}
 					}
 
+					if componentSheet != nil && totalCompsUpdatedPerModelPerVersion > 0 {
+						recordUpdateSBOM(modelName, content.Name(), versionPath, componentSheet)
+					}
 					compUpdateArray = append(compUpdateArray, compUpdateTracker{
 						totalComps:        availableComponentsPerModelPerVersion,
 						totalCompsUpdated: totalCompsUpdatedPerModelPerVersion,
@@ -241,7 +322,15 @@ func InvokeCompUpdate() error {
 					})
 				}
 			}
+			if len(pendingUpdates) > 0 {
+				writePendingUpdates(modelName, pendingUpdates, compUpdateArray, componentSheet)
+			}
 			modelToCompUpdateTracker.Set(modelName, compUpdateArray)
+			totalCompsUpdated := 0
+			for _, tracker := range compUpdateArray {
+				totalCompsUpdated += tracker.totalCompsUpdated
+			}
+			utils.ReportRegistryProgress(utils.RegistryProgress{Operation: utils.RegistryOperationUpdate, Kind: utils.RegistryModelCompleted, Model: modelName, Message: fmt.Sprintf("%d components updated", totalCompsUpdated)})
 			utils.Log.Info("\n")
 		}
 
@@ -250,6 +339,116 @@ func InvokeCompUpdate() error {
 	return nil
 }
 
+// reportUpdateError logs the error of the update of the model and reports it.
+func reportUpdateError(err error, modelName, version string) {
+	utils.Log.Error(err)
+	utils.ReportRegistryProgress(utils.RegistryProgress{Operation: utils.RegistryOperationUpdate, Kind: utils.RegistryProgressError, Model: modelName, Version: version, Err: err})
+}
+
+// isSelectedRegistrant reports whether the models of the registrant are updated, all of them without --registrant.
+func isSelectedRegistrant(registrant string) bool {
+	return registrantName == "" || mutils.ReplaceSpacesAndConvertToLowercase(registrant) == mutils.ReplaceSpacesAndConvertToLowercase(registrantName)
+}
+
+// writePendingUpdates writes the updates of the components of the model once the maintainer confirms them,
+// counting them in the trackers of their versions. With a components sheet, the provenance manifests of the
+// versions updated are recorded as well.
+func writePendingUpdates(modelName string, updates []pendingComponentUpdate, trackers []compUpdateTracker, componentSheet *utils.SBOMSheet) {
+	if !confirmComponentUpdates(os.Stdout, modelName, updates) {
+		utils.Log.Info("Changes to the components of model ", modelName, " discarded")
+		return
+	}
+	recorded := map[string]bool{}
+	for _, update := range updates {
+		path, err := componentOutputPath(update.path)
+		if err == nil {
+			err = os.WriteFile(path, update.updated, 0644)
+		}
+		if err != nil {
+			reportUpdateError(ErrUpdateComponent(err, modelName, update.kind), modelName, update.version)
+			continue
+		}
+		for i := range trackers {
+			if trackers[i].version == update.version {
+				trackers[i].totalCompsUpdated++
+			}
+		}
+		utils.ReportRegistryProgress(utils.RegistryProgress{Operation: utils.RegistryOperationUpdate, Kind: utils.RegistryComponentUpdated, Model: modelName, Version: update.version, Component: update.kind})
+		versionPath := filepath.Dir(filepath.Dir(update.path))
+		if componentSheet != nil && !recorded[versionPath] {
+			recordUpdateSBOM(modelName, update.version, versionPath, componentSheet)
+			recorded[versionPath] = true
+		}
+	}
+}
+
+// recordUpdateSBOM records the components sheet as a source of the version of the model at versionPath, in the
+// provenance manifest of the directory the version is written to.
+func recordUpdateSBOM(modelName, version, versionPath string, componentSheet *utils.SBOMSheet) {
+	sbomPath := filepath.Join(versionPath, utils.ModelSBOMFileName)
+	outputPath, err := componentOutputPath(sbomPath)
+	if err == nil && outputPath != sbomPath {
+		// with --changed-only the manifest recorded at generation is not in the output directory yet
+		if _, statErr := os.Stat(outputPath); os.IsNotExist(statErr) {
+			if data, readErr := os.ReadFile(sbomPath); readErr == nil {
+				err = os.WriteFile(outputPath, data, 0644)
+			}
+		}
+	}
+	if err == nil {
+		err = utils.WriteModelSBOM(filepath.Dir(outputPath), utils.ModelSBOMSource{
+			Model:   modelName,
+			Version: version,
+			Sheet:   componentSheet,
+			Rows:    componentSheet.Rows("model", modelName),
+		})
+	}
+	if err != nil {
+		utils.Log.Error(err)
+	}
+}
+
+// prepareOutputDir creates the fresh directory the updated models are written to, along with a copy of the
+// models directory unless only the changed files are written.
+func prepareOutputDir(modelsDir, outputDir string, changedOnly bool) error {
+	absModels, err := filepath.Abs(modelsDir)
+	if err != nil {
+		return err
+	}
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(absModels, absOutput); err == nil && (rel == "." || !strings.HasPrefix(rel, "..")) {
+		return fmt.Errorf("the output directory %s is within the models directory %s", outputDir, modelsDir)
+	}
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("the output directory %s is not empty", outputDir)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	if changedOnly {
+		return nil
+	}
+	return serverutils.CopyDirectory(modelsDir, outputDir)
+}
+
+// componentOutputPath returns the path the component of the models directory at compPath is written to,
+// compPath itself unless --output-dir is set.
+func componentOutputPath(compPath string) (string, error) {
+	if outputDir == "" {
+		return compPath, nil
+	}
+	pwd, _ := os.Getwd()
+	rel, err := filepath.Rel(filepath.Join(pwd, modelLocation), compPath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(outputDir, rel)
+	return path, os.MkdirAll(filepath.Dir(path), 0755)
+}
+
 func logModelUpdateSummary(modelToCompUpdateTracker *store.GenerticThreadSafeStore[[]compUpdateTracker]) {
 	values := modelToCompUpdateTracker.GetAllPairs()
 	for key, val := range values {
@@ -269,7 +468,15 @@ func init() {
 	updateCmd.PersistentFlags().StringVar(&spreadsheeetID, "spreadsheet-id", "", "spreadsheet it for the integration spreadsheet")
 	updateCmd.PersistentFlags().StringVar(&spreadsheeetCred, "spreadsheet-cred", "", "base64 encoded credential to download the spreadsheet")
 	updateCmd.PersistentFlags().StringVarP(&modelName, "model", "m", "", "specific model name to be generated")
-
+	updateCmd.PersistentFlags().StringVarP(&registrantName, "registrant", "r", "", "update only the models of the registrant, e.g. artifacthub or github")
+	updateCmd.PersistentFlags().BoolVar(&interactive, "interactive", false, "select the models to update and confirm the changes to their components before they are written")
+	updateCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "write the updated models to this fresh directory instead of updating the models directory in place")
+	updateCmd.PersistentFlags().BoolVar(&changedOnly, "changed-only", false, "with --output-dir, write only the updated components, not a copy of the whole models directory")
+	updateCmd.PersistentFlags().BoolVar(&utils.GenerateSBOM, "sbom", false, "record the sheet and rows the components are updated from in the provenance manifest (sbom.spdx.json) of the models")
+	_ = updateCmd.RegisterFlagCompletionFunc("model", completeModelNames("input"))
+	_ = updateCmd.RegisterFlagCompletionFunc("registrant", completeRegistrants)
+
+	addRegistryMetricsFlags(updateCmd.PersistentFlags())
 	updateCmd.MarkFlagsRequiredTogether("spreadsheet-id", "spreadsheet-cred")
 
 }
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	pwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	previous := []string{modelLocation, outputDir}
+	t.Cleanup(func() {
+		_ = os.Chdir(pwd)
+		modelLocation, outputDir = previous[0], previous[1]
+	})
+
+	component := filepath.Join("models", "istio-base", "v1.0.0", "v1.0.0", "components", "Gateway.json")
+	if err := os.MkdirAll(filepath.Dir(component), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(component, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modelLocation = "models"
+
+	if err := prepareOutputDir("models", filepath.Join("models", "out"), false); err == nil {
+		t.Error("expected an output directory within the models directory to be rejected")
+	}
+
+	outputDir = "out"
+	if err := prepareOutputDir("models", outputDir, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join("out", "istio-base", "v1.0.0", "v1.0.0", "components", "Gateway.json")); err != nil {
+		t.Errorf("expected the models directory to be copied to the output directory: %v", err)
+	}
+	if err := prepareOutputDir("models", outputDir, false); err == nil {
+		t.Error("expected an output directory which is not empty to be rejected")
+	}
+
+	outputDir = "changed"
+	if err := prepareOutputDir("models", outputDir, true); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("expected only the changed files to be written to the output directory, got %d entries", len(entries))
+	}
+	resolvedDir, _ := os.Getwd()
+	path, err := componentOutputPath(filepath.Join(resolvedDir, component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("changed", "istio-base", "v1.0.0", "v1.0.0", "components", "Gateway.json"); path != want {
+		t.Errorf("expected the component to be written to %s, got %s", want, path)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		t.Errorf("expected the directory of the component to be created: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	costMonth      string
+	costCPURate    float64
+	costMemoryRate float64
+	costOutput     string
+)
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Generate the chargeback report of a month",
+	Long: `Generate the chargeback (or showback) report of a month for every cost center and team.
+
+Resources discovered by MeshSync are correlated with the designs they were deployed from. Usage is measured as
+CPU and memory requests multiplied by the hours the resources existed during the month. Designs are charged to
+the cost center of the design, or else of the workspace they belong to; other designs are reported as unallocated.
+Without rates, the report is a showback of usage only.`,
+	Example: `
+// Showback of the current month
+mesheryctl report cost
+
+// Chargeback of June 2024 at 0.03 per core-hour and 0.004 per GiB-hour
+mesheryctl report cost --month 2024-06 --cpu-rate 0.03 --memory-rate 0.004
+
+// Output the report as JSON
+mesheryctl report cost --month 2024-06 -o json
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if costMonth == "" {
+			costMonth = time.Now().UTC().Format("2006-01")
+		}
+		if _, err := time.Parse("2006-01", costMonth); err != nil {
+			return utils.ErrInvalidArgument(errors.New("Please provide the month as YYYY-MM"))
+		}
+		if costCPURate < 0 || costMemoryRate < 0 {
+			return utils.ErrInvalidArgument(errors.New("Rates must be positive numbers"))
+		}
+		if costOutput != "table" && costOutput != "json" && costOutput != "yaml" {
+			return utils.ErrInvalidArgument(errors.New("Output format must be one of table, json or yaml"))
+		}
+
+		mctlCfg, err := config.GetMesheryCtl(viper.GetViper())
+		if err != nil {
+			return utils.ErrLoadConfig(err)
+		}
+
+		query := url.Values{}
+		query.Set("month", costMonth)
+		query.Set("cpu_rate", strconv.FormatFloat(costCPURate, 'f', -1, 64))
+		query.Set("memory_rate", strconv.FormatFloat(costMemoryRate, 'f', -1, 64))
+		req, err := utils.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/cost-centers/report?%s", mctlCfg.GetBaseMesheryURL(), query.Encode()), nil)
+		if err != nil {
+			return ErrGenerateCostReport(err, costMonth)
+		}
+
+		resp, err := utils.MakeRequest(req)
+		if err != nil {
+			return ErrGenerateCostReport(err, costMonth)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return utils.ErrReadResponseBody(err)
+		}
+		var report models.CostReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return utils.ErrUnmarshal(err)
+		}
+
+		switch costOutput {
+		case "json":
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return utils.ErrMarshalIndent(err)
+			}
+			fmt.Println(string(out))
+			return nil
+		case "yaml":
+			out, err := yaml.Marshal(report)
+			if err != nil {
+				return utils.ErrMarshal(err)
+			}
+			fmt.Print(string(out))
+			return nil
+		}
+
+		if len(report.Entries) == 0 {
+			utils.Log.Info(fmt.Sprintf("No usage of designs found in %s", report.Month))
+			return nil
+		}
+
+		chargeback := report.Rates.CPUCoreHour > 0 || report.Rates.MemoryGiBHour > 0
+		header := []string{"Cost Center", "Team", "Designs", "CPU Core-Hours", "Memory GiB-Hours"}
+		if chargeback {
+			header = append(header, "Cost")
+		}
+		rows := [][]string{}
+		var total float64
+		for _, entry := range report.Entries {
+			row := []string{entry.CostCenter, entry.Team, strings.Join(entry.Designs, ", "), fmt.Sprintf("%.2f", entry.CPUCoreHours), fmt.Sprintf("%.2f", entry.MemoryGiBHours)}
+			if chargeback {
+				row = append(row, fmt.Sprintf("%.2f", entry.Cost))
+			}
+			total += entry.Cost
+			rows = append(rows, row)
+		}
+
+		utils.Log.Info(fmt.Sprintf("Usage from %s to %s", report.From.Format("2006-01-02"), report.To.Format("2006-01-02 15:04")))
+		utils.PrintToTable(header, rows)
+		if chargeback {
+			utils.Log.Info(fmt.Sprintf("Total: %.2f", total))
+		}
+		return nil
+	},
+}
+
+func init() {
+	costCmd.Flags().StringVar(&costMonth, "month", "", "month of the report as YYYY-MM (default: current month)")
+	costCmd.Flags().Float64Var(&costCPURate, "cpu-rate", 0, "price of a CPU core-hour")
+	costCmd.Flags().Float64Var(&costMemoryRate, "memory-rate", 0, "price of a GiB-hour of memory")
+	costCmd.Flags().StringVarP(&costOutput, "output", "o", "table", "output format: table, json or yaml")
+}
@@ -0,0 +1,15 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+var (
+	ErrGenerateCostReportCode = "mesheryctl-1139"
+)
+
+func ErrGenerateCostReport(err error, month string) error {
+	return errors.New(ErrGenerateCostReportCode, errors.Alert, []string{fmt.Sprintf("error generating the cost report of %s", month)}, []string{err.Error()}, []string{"Month is not in the YYYY-MM format", "Rates are not positive numbers", "Meshery Server is not reachable"}, []string{"Pass the month as YYYY-MM, e.g. 2024-06", "Pass rates as positive numbers", "Ensure Meshery Server is running with `mesheryctl system status`"})
+}
@@ -0,0 +1,55 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var availableSubcommands = []*cobra.Command{costCmd}
+
+// ReportCmd represents the mesheryctl report command
+var ReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports",
+	Long:  "Generate reports on the usage of designs deployed by Meshery",
+	Example: `
+// Generate the chargeback report of the current month
+mesheryctl report cost
+	`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if err := cmd.Usage(); err != nil {
+				return nil
+			}
+			return utils.ErrInvalidArgument(errors.New("Please provide a subcommand"))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ok := utils.IsValidSubcommand(availableSubcommands, args[0]); !ok {
+			return utils.ErrInvalidArgument(errors.New(fmt.Sprintf("'%s' is an invalid subcommand. Use 'mesheryctl report --help' to display usage guide.\n", args[0])))
+		}
+		return nil
+	},
+}
+
+func init() {
+	ReportCmd.AddCommand(availableSubcommands...)
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/adapter"
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/apply"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/components"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/config"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/design"
@@ -28,6 +29,7 @@ import (
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/model"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/perf"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/registry"
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/report"
 	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/system"
 	"github.com/layer5io/meshery/mesheryctl/pkg/utils"
 	log "github.com/sirupsen/logrus"
@@ -116,6 +118,8 @@ func init() {
 		registry.RegistryCmd,
 		components.ComponentsCmd,
 		model.ModelCmd,
+		report.ReportCmd,
+		apply.ApplyCmd,
 	}
 
 	RootCmd.AddCommand(availableSubcommands...)
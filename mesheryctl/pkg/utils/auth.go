@@ -86,8 +86,8 @@ func MakeRequest(req *http.Request) (*http.Response, error) {
 		return nil, ErrUnauthenticated()
 	}
 
-	// failsafe for bad api call
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+	// failsafe for bad api call, e.g. deletes succeed with 204
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return nil, ErrReadResponseBody(err)
@@ -240,7 +240,9 @@ func (mch *ComponentCSVHelper) GetColumns() ([]string, error) {
 }
 
 func (mch *ComponentCSVHelper) ParseComponentsSheet(modelName string) error {
-	ch := make(chan ComponentCSV, 1)
+	// Unbuffered, so that every row is received before the parser is done: with a buffered channel,
+	// the last rows could still be buffered when the done case is selected, and be lost.
+	ch := make(chan ComponentCSV)
 	errorChan := make(chan error, 1)
 	csvReader, err := csv.NewCSVParser[ComponentCSV](mch.CSVPath, rowIndex, nil, func(_ []string, _ []string) bool {
 		return true
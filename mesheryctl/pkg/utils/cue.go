@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/encoding/openapi"
+	"github.com/layer5io/meshkit/generators"
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils/manifests"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	_model "github.com/meshery/schemas/models/v1beta1/model"
+)
+
+// cueRegistrant is the registrant of models whose components are defined in CUE. The source of these models
+// is a local directory of CUE packages, e.g. file://./apis/payments.
+const cueRegistrant = "cue"
+
+// CUEPackageManager loads the CUE packages of the directory SourceURL.
+type CUEPackageManager struct {
+	PackageName string
+	SourceURL   string
+}
+
+// CUEPackage is a model whose components are the exported definitions of CUE packages. The kind of a component
+// is the name of its definition, e.g. #Payment is the component Payment, and its schema is the OpenAPI schema
+// of the definition. The version of a component is the apiVersion field of the definition, of the package
+// otherwise. Hidden definitions, e.g. #_Money, are helpers of the other definitions and not components.
+// The version of the model is the version field of the packages, v1.0.0 without it.
+type CUEPackage struct {
+	Name      string
+	SourceURL string
+
+	version string
+	values  []cue.Value
+}
+
+// newGenerator returns the generator of the models of the registrant, from the sources of meshkit or from
+// the CUE packages of a directory.
+func newGenerator(registrant, sourceURL, modelName string) (models.PackageManager, error) {
+	if strings.ToLower(strings.TrimSpace(registrant)) == cueRegistrant {
+		return CUEPackageManager{PackageName: modelName, SourceURL: sourceURL}, nil
+	}
+	return generators.NewGenerator(registrant, sourceURL, modelName)
+}
+
+func (m CUEPackageManager) GetPackage() (models.Package, error) {
+	dir := strings.TrimPrefix(m.SourceURL, "file://")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		if err == nil {
+			err = fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil, ErrGenerateCUEPackage(err, m.PackageName)
+	}
+
+	ctx := cuecontext.New()
+	pkg := &CUEPackage{Name: m.PackageName, SourceURL: m.SourceURL, version: defVersion}
+	instances := load.Instances([]string{"./..."}, &load.Config{Dir: dir})
+	for _, inst := range instances {
+		if inst.Err != nil {
+			return nil, ErrGenerateCUEPackage(inst.Err, m.PackageName)
+		}
+		value := ctx.BuildInstance(inst)
+		if err := value.Err(); err != nil {
+			return nil, ErrGenerateCUEPackage(err, m.PackageName)
+		}
+		if version, err := value.LookupPath(cue.ParsePath("version")).String(); err == nil && version != "" {
+			pkg.version = version
+		}
+		pkg.values = append(pkg.values, value)
+	}
+	if len(pkg.values) == 0 {
+		return nil, ErrGenerateCUEPackage(fmt.Errorf("no CUE package found in %s", dir), m.PackageName)
+	}
+	return pkg, nil
+}
+
+func (p *CUEPackage) GetVersion() string {
+	return p.version
+}
+
+func (p *CUEPackage) GetSourceURL() string {
+	return p.SourceURL
+}
+
+func (p *CUEPackage) GetName() string {
+	return p.Name
+}
+
+func (p *CUEPackage) GenerateComponents() ([]component.ComponentDefinition, error) {
+	components := []component.ComponentDefinition{}
+	seen := map[string]string{}
+	for _, value := range p.values {
+		schemas, err := cueDefinitionSchemas(value)
+		if err != nil {
+			return nil, ErrGenerateCUEPackage(err, p.Name)
+		}
+		apiVersion, _ := value.LookupPath(cue.ParsePath("apiVersion")).String()
+
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pkgPath, ok := seen[name]; ok {
+				return nil, ErrGenerateCUEPackage(fmt.Errorf("the definition #%s of %s is also defined by %s", name, value.BuildInstance().ImportPath, pkgPath), p.Name)
+			}
+			seen[name] = value.BuildInstance().ImportPath
+
+			version := apiVersion
+			if defVersion, err := value.LookupPath(cue.MakePath(cue.Def(name), cue.Str("apiVersion"))).String(); err == nil {
+				version = defVersion
+			}
+			if version == "" {
+				version = "v1"
+			}
+
+			comp := component.ComponentDefinition{
+				SchemaVersion: v1beta1.ComponentSchemaVersion,
+				Format:        component.JSON,
+				DisplayName:   manifests.FormatToReadableString(name),
+				Component: component.Component{
+					Kind:    name,
+					Version: version,
+					Schema:  string(schemas[name]),
+				},
+				Metadata: component.ComponentDefinition_Metadata{
+					AdditionalProperties: map[string]interface{}{},
+				},
+				Model: _model.ModelDefinition{
+					Name:    p.Name,
+					Version: p.version,
+					Metadata: &_model.ModelDefinition_Metadata{
+						AdditionalProperties: map[string]interface{}{"source_uri": p.SourceURL},
+					},
+				},
+			}
+			components = append(components, comp)
+		}
+	}
+	return components, nil
+}
+
+// cueDefinitionSchemas returns the OpenAPI schemas of the exported definitions of the CUE package, by name.
+// References to other definitions are expanded so that every schema stands on its own.
+func cueDefinitionSchemas(value cue.Value) (map[string]json.RawMessage, error) {
+	// OpenAPI schemas are generated for definitions only, the regular fields of the package are left out
+	exported := map[string]bool{}
+	definitions := value.Context().CompileString("{}")
+	iter, err := value.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		name := strings.TrimPrefix(sel.String(), "#")
+		if sel.IsDefinition() && !strings.HasPrefix(name, "_") {
+			exported[name] = true
+			definitions = definitions.FillPath(cue.MakePath(sel), iter.Value())
+		}
+	}
+	if len(exported) == 0 {
+		return map[string]json.RawMessage{}, nil
+	}
+
+	doc, err := openapi.Gen(definitions, &openapi.Config{
+		ExpandReferences: true,
+		Info:             map[string]string{"title": filepath.Base(value.BuildInstance().Dir), "version": "v1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	spec := struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}{}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, err
+	}
+
+	schemas := map[string]json.RawMessage{}
+	for name, schema := range spec.Components.Schemas {
+		if exported[name] {
+			schemas[name] = schema
+		}
+	}
+	return schemas, nil
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCUEModule = `module: "example.com/payments"
+`
+
+const testCUEPackage = `package payments
+
+version:    "v0.3.0"
+apiVersion: "payments.example.com/v1"
+
+#_Money: {
+	amount:   number & >=0
+	currency: "USD" | "EUR"
+}
+
+// Payment is a payment between two accounts.
+#Payment: {
+	from:  string
+	to:    string
+	price: #_Money
+	memo?: string
+}
+
+#Refund: {
+	apiVersion: "payments.example.com/v2"
+	payment:    string
+	reason?:    string
+}
+`
+
+func TestCUEPackage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cue.mod/module.cue":    testCUEModule,
+		"payments/payments.cue": testCUEPackage,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generator, err := newGenerator("CUE", "file://"+dir, "payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := generator.GetPackage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.GetVersion() != "v0.3.0" {
+		t.Errorf("expected the version of the model to be v0.3.0, got %s", pkg.GetVersion())
+	}
+
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 2 {
+		t.Fatalf("expected the components Payment and Refund, got %d components", len(comps))
+	}
+	payment, refund := comps[0], comps[1]
+	if payment.Component.Kind != "Payment" || payment.Component.Version != "payments.example.com/v1" {
+		t.Errorf("expected the component Payment of payments.example.com/v1, got %s of %s", payment.Component.Kind, payment.Component.Version)
+	}
+	if refund.Component.Kind != "Refund" || refund.Component.Version != "payments.example.com/v2" {
+		t.Errorf("expected the component Refund of payments.example.com/v2, got %s of %s", refund.Component.Kind, refund.Component.Version)
+	}
+
+	schema := struct {
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}{}
+	if err := json.Unmarshal([]byte(payment.Component.Schema), &schema); err != nil {
+		t.Fatalf("invalid schema %s: %v", payment.Component.Schema, err)
+	}
+	if len(schema.Required) != 3 {
+		t.Errorf("expected from, to and price to be required, got %v", schema.Required)
+	}
+	if _, ok := schema.Properties["price"].Properties["currency"]; !ok {
+		t.Errorf("expected the hidden definition #_Money to be expanded in the schema, got %s", payment.Component.Schema)
+	}
+
+	if _, err := newGenerator("cue", filepath.Join(dir, "missing"), "payments"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (CUEPackageManager{PackageName: "payments", SourceURL: filepath.Join(dir, "missing")}).GetPackage(); err == nil {
+		t.Error("expected a source which is not a directory to be rejected")
+	}
+}
@@ -49,6 +49,8 @@ var (
 	ErrUpdateComponentsCode       = "mesheryctl-1134"
 	ErrCSVFileNotFoundCode        = "mesheryctl-1135"
 	ErrReadCSVRowCode             = "mesheryctl-1136"
+	ErrGenerateCUEPackageCode     = "mesheryctl-1143"
+	ErrWriteModelSBOMCode         = "mesheryctl-1145"
 )
 
 // RootError returns a formatted error message with a link to 'root' command usage page at
@@ -496,6 +498,13 @@ func ErrFailReqStatus(statusCode int, obj string) error {
 func ErrGenerateModel(err error, modelName string) error {
 	return errors.New(ErrGeneratesModelCode, errors.Alert, []string{fmt.Sprintf("error generating model: %s", modelName)}, []string{fmt.Sprintf("Error generating model: %s\n %s", modelName, err.Error())}, []string{"Registrant used for the model is not supported", "Verify the model's source URL.", "Failed to create a local directory in the filesystem for this model."}, []string{"Ensure that each kind of registrant used is a supported kind.", "Ensure correct model source URL is provided and properly formatted.", "Ensure sufficient permissions to allow creation of model directory."})
 }
+func ErrGenerateCUEPackage(err error, modelName string) error {
+	return errors.New(ErrGenerateCUEPackageCode, errors.Alert, []string{fmt.Sprintf("error generating the components of model %s from CUE", modelName)}, []string{err.Error()}, []string{"The source of the model is not a directory of CUE packages", "The CUE packages do not evaluate", "Several packages define the same definition"}, []string{"Set the source of the model to the directory of its CUE packages, e.g. file://./apis/payments", "Check the packages with `cue vet ./...`", "Rename one of the definitions, or hide helper definitions by naming them #_Name"})
+}
+
+func ErrWriteModelSBOM(err error, modelName string) error {
+	return errors.New(ErrWriteModelSBOMCode, errors.Alert, []string{fmt.Sprintf("error recording the provenance manifest of model %s", modelName)}, []string{err.Error()}, []string{"The provenance manifest of the model is corrupted", "The directory of the model is not writable"}, []string{"Delete the sbom.spdx.json file of the model and generate it again", "Ensure sufficient permissions to write to the directory of the model"})
+}
 func ErrMarshalIndent(err error) error {
 	return errors.New(ErrMarshalIndentCode, errors.Alert,
 		[]string{"Error indenting JSON body"},
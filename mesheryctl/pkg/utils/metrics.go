@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/googleapi"
+)
+
+const registryMetricsNamespace = "meshery_registry"
+
+var (
+	// SheetsAPIMaxAttempts is the number of times a call to the Google Sheets API is tried before giving up.
+	SheetsAPIMaxAttempts = 3
+	// SheetsAPIRetryBackoff is the wait before the first retry of a call to the Google Sheets API, doubled on each retry.
+	SheetsAPIRetryBackoff = time.Second
+
+	registryMetricsMx sync.Mutex
+	// registryMetrics are the metrics of the registry pipelines, nil until EnableRegistryMetrics is called.
+	registryMetrics *registryPipelineMetrics
+)
+
+type registryPipelineMetrics struct {
+	registry            *prometheus.Registry
+	componentsProcessed *prometheus.CounterVec
+	componentsUpdated   *prometheus.CounterVec
+	pipelineErrors      *prometheus.CounterVec
+	modelDuration       *prometheus.GaugeVec
+	sheetsAPIDuration   *prometheus.HistogramVec
+	sheetsAPIRetries    *prometheus.CounterVec
+
+	startedMx sync.Mutex
+	started   map[[2]string]time.Time
+}
+
+// EnableRegistryMetrics starts measuring the registry pipelines and returns the registry of their metrics, to be
+// served or pushed to a Pushgateway. Until it is called, nothing is measured.
+func EnableRegistryMetrics() *prometheus.Registry {
+	registryMetricsMx.Lock()
+	defer registryMetricsMx.Unlock()
+	if registryMetrics != nil {
+		return registryMetrics.registry
+	}
+
+	labels := []string{"operation", "model"}
+	m := &registryPipelineMetrics{
+		registry: prometheus.NewRegistry(),
+		componentsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "components_processed_total",
+			Help:      "Components of the sheet processed by the registry pipeline, changed or not.",
+		}, labels),
+		componentsUpdated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "components_updated_total",
+			Help:      "Components generated or updated by the registry pipeline.",
+		}, labels),
+		pipelineErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "errors_total",
+			Help:      "Errors of the registry pipeline, of a component or of a whole model.",
+		}, labels),
+		modelDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "model_duration_seconds",
+			Help:      "Time taken by the registry pipeline to generate or update the model.",
+		}, labels),
+		sheetsAPIDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "sheets_api_request_duration_seconds",
+			Help:      "Latency of the calls to the Google Sheets API, retries included.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 8),
+		}, []string{"method"}),
+		sheetsAPIRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: registryMetricsNamespace,
+			Name:      "sheets_api_retries_total",
+			Help:      "Calls to the Google Sheets API retried after a rate limit or server error.",
+		}, []string{"method"}),
+		started: map[[2]string]time.Time{},
+	}
+	m.registry.MustRegister(m.componentsProcessed, m.componentsUpdated, m.pipelineErrors, m.modelDuration, m.sheetsAPIDuration, m.sheetsAPIRetries)
+	registryMetrics = m
+	return m.registry
+}
+
+func currentRegistryMetrics() *registryPipelineMetrics {
+	registryMetricsMx.Lock()
+	defer registryMetricsMx.Unlock()
+	return registryMetrics
+}
+
+// CountRegistryComponentProcessed counts a component of the model processed by the operation.
+func CountRegistryComponentProcessed(operation, model string) {
+	if m := currentRegistryMetrics(); m != nil {
+		m.componentsProcessed.WithLabelValues(operation, model).Inc()
+	}
+}
+
+// observeRegistryProgress measures the step of a registry operation.
+func observeRegistryProgress(progress RegistryProgress) {
+	m := currentRegistryMetrics()
+	if m == nil {
+		return
+	}
+	key := [2]string{progress.Operation, progress.Model}
+	switch progress.Kind {
+	case RegistryModelStarted:
+		m.startedMx.Lock()
+		m.started[key] = time.Now()
+		m.startedMx.Unlock()
+	case RegistryComponentGenerated, RegistryComponentUpdated:
+		m.componentsUpdated.WithLabelValues(progress.Operation, progress.Model).Inc()
+	case RegistryProgressError:
+		m.pipelineErrors.WithLabelValues(progress.Operation, progress.Model).Inc()
+	case RegistryModelCompleted:
+		m.startedMx.Lock()
+		started, ok := m.started[key]
+		delete(m.started, key)
+		m.startedMx.Unlock()
+		if ok {
+			m.modelDuration.WithLabelValues(progress.Operation, progress.Model).Set(time.Since(started).Seconds())
+		}
+	}
+}
+
+// CallSheetsAPI calls the method of the Google Sheets API, retrying it on rate limits and server errors, and
+// measures its latency and retries.
+func CallSheetsAPI(method string, call func() error) error {
+	m := currentRegistryMetrics()
+	start := time.Now()
+	backoff := SheetsAPIRetryBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call()
+		if err == nil || attempt >= SheetsAPIMaxAttempts || !isRetryableSheetsAPIError(err) {
+			break
+		}
+		if m != nil {
+			m.sheetsAPIRetries.WithLabelValues(method).Inc()
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if m != nil {
+		m.sheetsAPIDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+	return err
+}
+
+func isRetryableSheetsAPIError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+}
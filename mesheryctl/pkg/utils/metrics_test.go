@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/api/googleapi"
+)
+
+func TestRegistryMetrics(t *testing.T) {
+	registry := EnableRegistryMetrics()
+
+	CountRegistryComponentProcessed(RegistryOperationUpdate, "istio-base")
+	CountRegistryComponentProcessed(RegistryOperationUpdate, "istio-base")
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryModelStarted, Model: "istio-base"})
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryComponentUpdated, Model: "istio-base", Component: "Gateway"})
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryProgressError, Model: "istio-base", Err: errors.New("invalid component")})
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryModelCompleted, Model: "istio-base"})
+
+	defer func(backoff time.Duration) { SheetsAPIRetryBackoff = backoff }(SheetsAPIRetryBackoff)
+	SheetsAPIRetryBackoff = 0
+	calls := 0
+	err := CallSheetsAPI("spreadsheets.get", func() error {
+		calls++
+		if calls == 1 {
+			return &googleapi.Error{Code: http.StatusTooManyRequests}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("expected the rate limited call to be retried once, got %d calls and error %v", calls, err)
+	}
+	calls = 0
+	_ = CallSheetsAPI("spreadsheets.get", func() error {
+		calls++
+		return &googleapi.Error{Code: http.StatusForbidden}
+	})
+	if calls != 1 {
+		t.Errorf("expected a forbidden call not to be retried, got %d calls", calls)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]*dto.Metric{}
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()[0]
+	}
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"meshery_registry_components_processed_total", 2},
+		{"meshery_registry_components_updated_total", 1},
+		{"meshery_registry_errors_total", 1},
+		{"meshery_registry_sheets_api_retries_total", 1},
+	}
+	for _, tt := range tests {
+		metric, ok := metrics[tt.name]
+		if !ok {
+			t.Errorf("expected the metric %s", tt.name)
+			continue
+		}
+		if got := metric.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("expected %s to be %v, got %v", tt.name, tt.want, got)
+		}
+	}
+	if _, ok := metrics["meshery_registry_model_duration_seconds"]; !ok {
+		t.Error("expected the duration of the model to be measured")
+	}
+	if got := metrics["meshery_registry_sheets_api_request_duration_seconds"].GetHistogram().GetSampleCount(); got != 2 {
+		t.Errorf("expected the latency of both Sheets API calls to be measured, got %d", got)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/layer5io/meshkit/encoding"
-	"github.com/layer5io/meshkit/generators"
 	"github.com/layer5io/meshkit/generators/models"
 	meshkitutils "github.com/layer5io/meshkit/utils"
 	"github.com/sirupsen/logrus"
@@ -591,7 +590,7 @@ func GenerateComponentsFromPkg(pkg models.Package, compDirPath string, defVersio
 	return len(comps), lengthOfComps, nil
 }
 func GenerateModels(registrant string, sourceURl string, modelName string) (models.Package, string, error) {
-	generator, err := generators.NewGenerator(registrant, sourceURl, modelName)
+	generator, err := newGenerator(registrant, sourceURl, modelName)
 	if err != nil {
 		return nil, "", err
 	}
@@ -753,6 +752,17 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 	if err != nil {
 		return err
 	}
+	var modelSheet *SBOMSheet
+	if GenerateSBOM {
+		sheetURL := url
+		if modelCSVFilePath != "" {
+			sheetURL = ""
+		}
+		modelSheet, err = NewSBOMSheet(modelCSVHelper.Title, sheetURL, modelCSVHelper.CSVPath)
+		if err != nil {
+			return err
+		}
+	}
 
 	componentCSVHelper, err := parseComponentSheet(url, modelName, componentSheetID, componentCSVFilePath)
 	if err != nil {
@@ -798,19 +808,24 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 				weightedSem.Release(1)
 			}()
 			var err error
+			fail := func(err error) {
+				LogError.Error(err)
+				ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryProgressError, Model: model.Model, Err: err})
+			}
+			ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryModelStarted, Model: model.Model})
 
 			if utils.ReplaceSpacesAndConvertToLowercase(model.Registrant) == "meshery" {
-				err = GenerateDefsForCoreRegistrant(model, componentCSVHelper, path, modelName)
+				err = GenerateDefsForCoreRegistrant(model, componentCSVHelper, path, modelName, modelSheet)
 				if err != nil {
-					LogError.Error(err)
+					fail(err)
 				}
 				return
 			}
 
-			generator, err := generators.NewGenerator(model.Registrant, model.SourceURL, model.Model)
+			generator, err := newGenerator(model.Registrant, model.SourceURL, model.Model)
 			if err != nil {
 				err = ErrGenerateModel(err, model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 
@@ -821,7 +836,7 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 			pkg, err := generator.GetPackage()
 			if err != nil {
 				err = ErrGenerateModel(err, model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 
@@ -830,31 +845,32 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 			comps, err := pkg.GenerateComponents()
 			if err != nil {
 				err = ErrGenerateModel(err, model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 			lengthOfComps := len(comps)
 			if lengthOfComps == 0 {
 				err = ErrGenerateModel(fmt.Errorf("no components found for model"), model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 			modelDirPath, compDirPath, err := createVersionedDirectoryForModelAndComp(version, model.Model, path)
 			if err != nil {
 				err = ErrGenerateModel(err, model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 			modelDef, alreadyExist, err := writeModelDefToFileSystem(&model, version, modelDirPath)
 			if err != nil {
 				err = ErrGenerateModel(err, model.Model)
-				LogError.Error(err)
+				fail(err)
 				return
 			}
 			if alreadyExist {
 				totalAvailableModels--
 			}
 			for _, comp := range comps {
+				CountRegistryComponentProcessed(RegistryOperationGenerate, model.Model)
 				comp.Version = defVersion
 				// Assign the component status corresponding to model status.
 				// i.e., If model is enabled, comps are also "enabled". Ultimately, all individual comps will have the ability to control their status.
@@ -878,14 +894,22 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 				}
 				if err != nil {
 					err = ErrGenerateModel(err, model.Model)
-					LogError.Error(err)
+					fail(err)
 					return
 				}
+				if !compAlreadyExist {
+					ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryComponentGenerated, Model: model.Model, Version: version, Component: comp.Component.Kind})
+				}
+			}
+			if modelSheet != nil {
+				if err := recordModelSBOM(modelDirPath, model, version, modelSheet); err != nil {
+					fail(err)
+				}
 			}
 			if !alreadyExist {
 				if len(comps) == 0 {
 					err = ErrGenerateModel(fmt.Errorf("no components found for model"), model.Model)
-					LogError.Error(err)
+					fail(err)
 
 				} else {
 					log.Info("Current model: ", model.Model)
@@ -896,7 +920,7 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 					log.Info("Model already exists: ", model.Model)
 				} else {
 					err = ErrGenerateModel(fmt.Errorf("no components found for model"), model.Model)
-					LogError.Error(err)
+					fail(err)
 				}
 			}
 			spreadsheeetChan <- SpreadsheetData{
@@ -908,6 +932,7 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 				totalComps: lengthOfComps,
 				version:    version,
 			})
+			ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryModelCompleted, Model: model.Model, Version: version, Message: fmt.Sprintf("%d components generated", lengthOfComps)})
 		}(model)
 	}
 
@@ -926,7 +951,7 @@ func InvokeGenerationFromSheet(wg *sync.WaitGroup, path string, modelsheetID, co
 
 // For registrants eg: meshery, whose components needs to be directly created by referencing meshery/schemas repo.
 // the sourceURL contains the path of models component definitions
-func GenerateDefsForCoreRegistrant(model ModelCSV, ComponentCSVHelper *ComponentCSVHelper, path string, modelName string) error {
+func GenerateDefsForCoreRegistrant(model ModelCSV, ComponentCSVHelper *ComponentCSVHelper, path string, modelName string, modelSheet *SBOMSheet) error {
 	var version string
 	parts := strings.Split(model.SourceURL, "/")
 	// Assuming the URL is always of the format "protocol://github.com/owner/repo/tree/definitions/{model-name}/version/components"
@@ -972,14 +997,11 @@ func GenerateDefsForCoreRegistrant(model ModelCSV, ComponentCSVHelper *Component
 				if comp.Model != model.Model {
 					continue
 				}
+				CountRegistryComponentProcessed(RegistryOperationGenerate, model.Model)
 				var componentDef component.ComponentDefinition
 				componentDef, err = comp.CreateComponentDefinition(isModelPublishToSite, "v1.0.0")
 				if err != nil {
-					if LogError != nil {
-						LogError.Error(ErrUpdateComponent(err, modelName, comp.Component))
-						continue
-					}
-					Log.Error(ErrUpdateComponent(err, modelName, comp.Component))
+					reportComponentError(ErrUpdateComponent(err, modelName, comp.Component), model.Model, version)
 					continue
 				}
 				componentDef.Status = &_status
@@ -987,15 +1009,13 @@ func GenerateDefsForCoreRegistrant(model ModelCSV, ComponentCSVHelper *Component
 				alreadyExists, err = componentDef.WriteComponentDefinition(compDirPath, "json")
 				if err != nil {
 					err = ErrGenerateComponent(err, comp.Model, componentDef.DisplayName)
-					if LogError != nil {
-						LogError.Error(ErrUpdateComponent(err, modelName, comp.Component))
-						continue
-					}
-					Log.Error(ErrUpdateComponent(err, modelName, comp.Component))
+					reportComponentError(ErrUpdateComponent(err, modelName, comp.Component), model.Model, version)
 					continue
 				}
 				if alreadyExists {
 					actualCompCount++
+				} else {
+					ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryComponentGenerated, Model: model.Model, Version: version, Component: comp.Component})
 				}
 				compDefComps = append(compDefComps, componentDef)
 			}
@@ -1029,8 +1049,24 @@ func GenerateDefsForCoreRegistrant(model ModelCSV, ComponentCSVHelper *Component
 	if err != nil {
 		return ErrGenerateModel(err, model.Model)
 	}
+	if modelSheet != nil {
+		if err := recordModelSBOM(modelDirPath, model, version, modelSheet); err != nil {
+			return err
+		}
+	}
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryModelCompleted, Model: model.Model, Version: version, Message: fmt.Sprintf("%d components generated", len(compDefComps)-actualCompCount)})
 	return nil
 }
+
+// reportComponentError logs the error of a component of the model, to the error log when it is set up, and reports it.
+func reportComponentError(err error, model, version string) {
+	if LogError != nil {
+		LogError.Error(err)
+	} else {
+		Log.Error(err)
+	}
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryProgressError, Model: model, Version: version, Err: err})
+}
 func SetLogger(ismultiWriter bool) error {
 	logDirPath := filepath.Join(meshkitutils.GetHome(), ".meshery", "logs", "registry")
 	err := os.MkdirAll(logDirPath, 0755)
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// RegistryProgressKind is the kind of a step of a registry operation.
+type RegistryProgressKind string
+
+const (
+	RegistryModelStarted       RegistryProgressKind = "model_started"
+	RegistryComponentGenerated RegistryProgressKind = "component_generated"
+	RegistryComponentUpdated   RegistryProgressKind = "component_updated"
+	RegistryModelCompleted     RegistryProgressKind = "model_completed"
+	RegistryProgressError      RegistryProgressKind = "error"
+)
+
+const (
+	RegistryOperationGenerate = "generate"
+	RegistryOperationUpdate   = "update"
+)
+
+// RegistryProgress is a step of the generation or the update of the models of the registry.
+type RegistryProgress struct {
+	// Operation is generate or update.
+	Operation string
+	Kind      RegistryProgressKind
+	Model     string
+	Version   string
+	Component string
+	Message   string
+	Err       error
+}
+
+var (
+	registryProgressMx sync.RWMutex
+	// registryProgressHandler receives the progress of the registry operations, nil when nobody follows it.
+	registryProgressHandler func(RegistryProgress)
+)
+
+// SetRegistryProgressHandler sets the function the steps of the registry operations are sent to, e.g. to print
+// them or to publish them as events, and returns the function to unset it. The handler is called from the
+// goroutines of the models generated concurrently, so it must be safe for concurrent use.
+func SetRegistryProgressHandler(handler func(RegistryProgress)) func() {
+	registryProgressMx.Lock()
+	defer registryProgressMx.Unlock()
+	registryProgressHandler = handler
+	return func() {
+		registryProgressMx.Lock()
+		defer registryProgressMx.Unlock()
+		registryProgressHandler = nil
+	}
+}
+
+// ReportRegistryProgress sends a step of a registry operation to the handler of the progress, if any, and measures
+// it when the metrics of the registry are enabled.
+func ReportRegistryProgress(progress RegistryProgress) {
+	observeRegistryProgress(progress)
+	registryProgressMx.RLock()
+	handler := registryProgressHandler
+	registryProgressMx.RUnlock()
+	if handler != nil {
+		handler(progress)
+	}
+}
+
+// PrintRegistryProgress returns a handler of the progress printing each step as a line to w.
+func PrintRegistryProgress(w io.Writer) func(RegistryProgress) {
+	var mx sync.Mutex
+	return func(progress RegistryProgress) {
+		mx.Lock()
+		defer mx.Unlock()
+		fmt.Fprintln(w, progress.String())
+	}
+}
+
+// String returns the step as a line of the output of mesheryctl, e.g. [update] istio-base 1.20.1: component Gateway updated.
+func (p RegistryProgress) String() string {
+	subject := strings.TrimSpace(p.Model + " " + p.Version)
+	message := p.Message
+	if message == "" {
+		switch p.Kind {
+		case RegistryModelStarted:
+			message = "started"
+		case RegistryComponentGenerated:
+			message = fmt.Sprintf("component %s generated", p.Component)
+		case RegistryComponentUpdated:
+			message = fmt.Sprintf("component %s updated", p.Component)
+		case RegistryModelCompleted:
+			message = "completed"
+		}
+	}
+	if p.Err != nil {
+		message = strings.TrimSpace(message + " " + p.Err.Error())
+	}
+	if subject == "" {
+		return fmt.Sprintf("[%s] %s", p.Operation, message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", p.Operation, subject, message)
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRegistryProgress(t *testing.T) {
+	reported := []RegistryProgress{}
+	unset := SetRegistryProgressHandler(func(progress RegistryProgress) {
+		reported = append(reported, progress)
+	})
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryModelStarted, Model: "istio-base"})
+	unset()
+	ReportRegistryProgress(RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryModelCompleted, Model: "istio-base"})
+	if len(reported) != 1 || reported[0].Kind != RegistryModelStarted {
+		t.Fatalf("expected only the step reported while the handler is set, got %v", reported)
+	}
+
+	tests := []struct {
+		progress RegistryProgress
+		want     string
+	}{
+		{RegistryProgress{Operation: RegistryOperationUpdate, Kind: RegistryComponentUpdated, Model: "istio-base", Version: "1.20.1", Component: "Gateway"}, "[update] istio-base 1.20.1: component Gateway updated\n"},
+		{RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryModelCompleted, Model: "rook", Message: "3 components generated"}, "[generate] rook: 3 components generated\n"},
+		{RegistryProgress{Operation: RegistryOperationGenerate, Kind: RegistryProgressError, Model: "rook", Err: errors.New("no components found for model")}, "[generate] rook: no components found for model\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		PrintRegistryProgress(out)(tt.progress)
+		if out.String() != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, out.String())
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/mesheryctl/internal/cli/root/constants"
+)
+
+// ModelSBOMFileName is the provenance manifest of a model, stored with its model.json.
+const ModelSBOMFileName = "sbom.spdx.json"
+
+const (
+	spdxVersion         = "SPDX-2.3"
+	spdxNoAssertion     = "NOASSERTION"
+	spdxModelID         = "SPDXRef-Model"
+	spdxUpstreamID      = "SPDXRef-Upstream"
+	spdxSheetIDPrefix   = "SPDXRef-Sheet-"
+	spdxGeneratedFrom   = "GENERATED_FROM"
+	spdxDocumentID      = "SPDXRef-DOCUMENT"
+	spdxDocumentLicense = "CC0-1.0"
+)
+
+// GenerateSBOM records the provenance manifest of the models generated or updated from the spreadsheet.
+var GenerateSBOM bool
+
+// SBOMSheet is a sheet of the spreadsheet, or the CSV file standing for it, models are generated or updated from.
+type SBOMSheet struct {
+	// Name is the title of the sheet, e.g. Models or Components.
+	Name string
+	// URL is the URL of the spreadsheet, empty for local CSV files.
+	URL string
+	// Path is the CSV file the sheet is read from.
+	Path   string
+	SHA256 string
+}
+
+// ModelSBOMSource is the provenance of a version of a model: its upstream source, along with the sheet and the
+// rows of the sheet its definitions come from.
+type ModelSBOMSource struct {
+	Model      string
+	Version    string
+	Registrant string
+	// SourceURL is the upstream chart, CRDs or package the components are generated from, empty when the
+	// components are only updated.
+	SourceURL string
+	Sheet     *SBOMSheet
+	Rows      []int
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files,omitempty"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo,omitempty"`
+	DownloadLocation string `json:"downloadLocation"`
+	FilesAnalyzed    bool   `json:"filesAnalyzed"`
+	Supplier         string `json:"supplier,omitempty"`
+	SourceInfo       string `json:"sourceInfo,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID    string         `json:"SPDXID"`
+	FileName  string         `json:"fileName"`
+	Checksums []spdxChecksum `json:"checksums"`
+	Comment   string         `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// NewSBOMSheet returns the sheet of the CSV file at path, hashing its content.
+func NewSBOMSheet(name, url, path string) (*SBOMSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ErrFileRead(err)
+	}
+	sum := sha256.Sum256(data)
+	return &SBOMSheet{Name: name, URL: url, Path: path, SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// Rows returns the rows of the sheet, numbered as in the spreadsheet, whose column has the value.
+func (s *SBOMSheet) Rows(column, value string) []int {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil
+	}
+
+	rows := []int{}
+	index := -1
+	for i, record := range records {
+		if index == -1 {
+			for j, cell := range record {
+				if strings.TrimSpace(cell) == column {
+					index = j
+					break
+				}
+			}
+			continue
+		}
+		if index < len(record) && strings.TrimSpace(record[index]) == value {
+			rows = append(rows, i+1)
+		}
+	}
+	return rows
+}
+
+// WriteModelSBOM records the provenance of the model in the manifest of the directory of its model.json. The
+// manifest already there is updated: the sheets it records are kept, as is the upstream source unless the
+// source has one.
+func WriteModelSBOM(modelDefPath string, source ModelSBOMSource) error {
+	path := filepath.Join(modelDefPath, ModelSBOMFileName)
+	doc := spdxDocument{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return ErrWriteModelSBOM(err, source.Model)
+		}
+	}
+
+	namespace, err := uuid.NewV4()
+	if err != nil {
+		return ErrWriteModelSBOM(err, source.Model)
+	}
+	doc.SPDXVersion = spdxVersion
+	doc.DataLicense = spdxDocumentLicense
+	doc.SPDXID = spdxDocumentID
+	doc.Name = fmt.Sprintf("%s-%s", source.Model, source.Version)
+	doc.DocumentNamespace = fmt.Sprintf("https://meshery.io/spdxdocs/%s-%s-%s", source.Model, source.Version, namespace)
+	doc.CreationInfo = spdxCreationInfo{
+		Created:  time.Now().UTC().Format(time.RFC3339),
+		Creators: []string{"Tool: mesheryctl-" + constants.GetMesheryctlVersion(), "Organization: Meshery"},
+	}
+
+	doc.setPackage(spdxPackage{
+		SPDXID:           spdxModelID,
+		Name:             source.Model,
+		VersionInfo:      source.Version,
+		DownloadLocation: spdxNoAssertion,
+		Supplier:         "Organization: Meshery",
+	})
+	doc.setRelationship(spdxRelationship{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxModelID})
+	if source.SourceURL != "" {
+		upstream := spdxPackage{
+			SPDXID:           spdxUpstreamID,
+			Name:             source.Model,
+			VersionInfo:      source.Version,
+			DownloadLocation: source.SourceURL,
+		}
+		if source.Registrant != "" {
+			upstream.SourceInfo = fmt.Sprintf("components generated by the %s registrant", source.Registrant)
+		}
+		doc.setPackage(upstream)
+		doc.setRelationship(spdxRelationship{SPDXElementID: spdxModelID, RelationshipType: spdxGeneratedFrom, RelatedSPDXElement: spdxUpstreamID})
+	}
+
+	if source.Sheet != nil {
+		fileID := spdxSheetIDPrefix + source.Sheet.Name
+		comment := fmt.Sprintf("rows %s of the %s sheet", joinInts(source.Rows), source.Sheet.Name)
+		if source.Sheet.URL != "" {
+			comment += " of the spreadsheet " + source.Sheet.URL
+		}
+		doc.setFile(spdxFile{
+			SPDXID:    fileID,
+			FileName:  filepath.Base(source.Sheet.Path),
+			Checksums: []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: source.Sheet.SHA256}},
+			Comment:   comment,
+		})
+		doc.setRelationship(spdxRelationship{SPDXElementID: spdxModelID, RelationshipType: spdxGeneratedFrom, RelatedSPDXElement: fileID})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return ErrWriteModelSBOM(err, source.Model)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return ErrWriteModelSBOM(err, source.Model)
+	}
+	return nil
+}
+
+// recordModelSBOM records the provenance of the version of the model generated from its row of the models sheet.
+func recordModelSBOM(modelDefPath string, model ModelCSV, version string, sheet *SBOMSheet) error {
+	return WriteModelSBOM(modelDefPath, ModelSBOMSource{
+		Model:      model.Model,
+		Version:    version,
+		Registrant: model.Registrant,
+		SourceURL:  model.SourceURL,
+		Sheet:      sheet,
+		Rows:       sheet.Rows("model", model.Model),
+	})
+}
+
+func (d *spdxDocument) setPackage(pkg spdxPackage) {
+	for i := range d.Packages {
+		if d.Packages[i].SPDXID == pkg.SPDXID {
+			d.Packages[i] = pkg
+			return
+		}
+	}
+	d.Packages = append(d.Packages, pkg)
+}
+
+func (d *spdxDocument) setFile(file spdxFile) {
+	for i := range d.Files {
+		if d.Files[i].SPDXID == file.SPDXID {
+			d.Files[i] = file
+			return
+		}
+	}
+	d.Files = append(d.Files, file)
+}
+
+func (d *spdxDocument) setRelationship(rel spdxRelationship) {
+	for _, existing := range d.Relationships {
+		if existing == rel {
+			return
+		}
+	}
+	d.Relationships = append(d.Relationships, rel)
+}
+
+func joinInts(values []int) string {
+	if len(values) == 0 {
+		return spdxNoAssertion
+	}
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, ", ")
+}
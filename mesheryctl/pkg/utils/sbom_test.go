@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testModelsCSV = `Models,,
+registrant,model,sourceURL
+artifacthub,istio-base,https://artifacthub.io/packages/helm/istio/base
+github,cert-manager,https://github.com/cert-manager/cert-manager
+artifacthub,istio-base,https://artifacthub.io/packages/helm/istio/base
+`
+
+func TestWriteModelSBOM(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "models.csv")
+	if err := os.WriteFile(csvPath, []byte(testModelsCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := NewSBOMSheet("Models", "", csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := sheet.Rows("model", "istio-base"); len(rows) != 2 || rows[0] != 3 || rows[1] != 5 {
+		t.Errorf("expected the rows 3 and 5 of istio-base, got %v", rows)
+	}
+
+	model := ModelCSV{Model: "istio-base", Registrant: "artifacthub", SourceURL: "https://artifacthub.io/packages/helm/istio/base"}
+	if err := recordModelSBOM(dir, model, "1.20.1", sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	components := &SBOMSheet{Name: "Components", Path: csvPath, SHA256: "abc"}
+	if err := WriteModelSBOM(dir, ModelSBOMSource{Model: "istio-base", Version: "1.20.1", Sheet: components, Rows: []int{7}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ModelSBOMFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := spdxDocument{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Packages) != 2 || doc.Packages[1].DownloadLocation != model.SourceURL || doc.Packages[1].VersionInfo != "1.20.1" {
+		t.Errorf("expected the model and its upstream source to be recorded, got %+v", doc.Packages)
+	}
+	if len(doc.Files) != 2 || doc.Files[0].Checksums[0].ChecksumValue != sheet.SHA256 || doc.Files[0].Comment != "rows 3, 5 of the Models sheet" {
+		t.Errorf("expected the models and components sheets to be recorded, got %+v", doc.Files)
+	}
+	if len(doc.Relationships) != 4 {
+		t.Errorf("expected the model to be described and generated from its 3 sources, got %+v", doc.Relationships)
+	}
+	if doc.CreationInfo.Created == "" || len(doc.CreationInfo.Creators) == 0 {
+		t.Errorf("expected the generation time and tool to be recorded, got %+v", doc.CreationInfo)
+	}
+}
@@ -183,7 +183,10 @@ func (mrh *RelationshipCSVHelper) UpdateRelationshipSheet(srv *sheets.Service, c
 		Data:             dataToUpdate,
 	}
 
-	_, err := srv.Spreadsheets.Values.BatchUpdate(sheetId, batchUpdateValuesRequest).Context(context.Background()).Do()
+	err := CallSheetsAPI("spreadsheets.values.batchUpdate", func() error {
+		_, err := srv.Spreadsheets.Values.BatchUpdate(sheetId, batchUpdateValuesRequest).Context(context.Background()).Do()
+		return err
+	})
 	if err != nil {
 		return ErrUpdateToSheet(err, sheetId)
 	}
@@ -250,11 +253,14 @@ func appendSheet(srv *sheets.Service, cred, sheetId, appendRange string, values
 		if len(values) == 0 {
 			return nil
 		}
-		_, err := srv.Spreadsheets.Values.Append(sheetId, appendRange, &sheets.ValueRange{
-			MajorDimension: "ROWS",
-			Range:          appendRange,
-			Values:         values,
-		}).InsertDataOption("INSERT_ROWS").ValueInputOption("USER_ENTERED").Context(context.Background()).Do()
+		err := CallSheetsAPI("spreadsheets.values.append", func() error {
+			_, err := srv.Spreadsheets.Values.Append(sheetId, appendRange, &sheets.ValueRange{
+				MajorDimension: "ROWS",
+				Range:          appendRange,
+				Values:         values,
+			}).InsertDataOption("INSERT_ROWS").ValueInputOption("USER_ENTERED").Context(context.Background()).Do()
+			return err
+		})
 
 		if err != nil {
 			return ErrAppendToSheet(err, sheetId)
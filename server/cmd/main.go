@@ -17,12 +17,16 @@ import (
 	"github.com/layer5io/meshery/server/helpers"
 	"github.com/layer5io/meshery/server/helpers/utils"
 	"github.com/layer5io/meshery/server/internal/graphql"
+	"github.com/layer5io/meshery/server/internal/spiffe"
 	"github.com/layer5io/meshery/server/internal/store"
 	"github.com/layer5io/meshery/server/machines"
 	mhelpers "github.com/layer5io/meshery/server/machines/helpers"
+	"github.com/layer5io/meshery/server/management"
+	"github.com/layer5io/meshery/server/meshes"
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshery/server/models/connections"
 	mesherymeshmodel "github.com/layer5io/meshery/server/models/meshmodel"
+	pCore "github.com/layer5io/meshery/server/models/pattern/core"
 	"github.com/layer5io/meshery/server/router"
 	"github.com/layer5io/meshkit/broker/nats"
 	"github.com/layer5io/meshkit/logger"
@@ -33,6 +37,7 @@ import (
 	"github.com/layer5io/meshkit/utils/events"
 	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/meshery/schemas/models/v1beta1"
 	"github.com/sirupsen/logrus"
@@ -80,6 +85,13 @@ func main() {
 		logrus.Error(err)
 		os.Exit(1)
 	}
+	pCore.SetLogger(log)
+	nameCollisions, err := pCore.ParseNameCollisionStrategy(viper.GetString("DESIGN_NAME_COLLISION_STRATEGY"))
+	if err != nil {
+		log.Warn(err)
+	} else {
+		pCore.SetNameCollisionStrategy(nameCollisions)
+	}
 
 	viper.OnConfigChange(func(event fsnotify.Event) {
 		log.Info("received change for", event.Name)
@@ -113,6 +125,16 @@ func main() {
 	viper.SetDefault("SKIP_DOWNLOAD_CONTENT", false)
 	viper.SetDefault("SKIP_COMP_GEN", false)
 	viper.SetDefault("PLAYGROUND", false)
+	viper.SetDefault(models.RegistrySnapshotVersionENV, "")
+	viper.SetDefault(models.RegistrySnapshotRegistryENV, "ghcr.io")
+	viper.SetDefault(models.RegistrySnapshotRepositoryENV, "meshery/registry")
+	viper.SetDefault(models.RegistryUpdateSpreadsheetIDENV, "")
+	viper.SetDefault(models.RegistryUpdateCSVEnabledENV, false)
+	viper.SetDefault(models.AdmissionWebhookEnabledENV, false)
+	viper.SetDefault(models.AdmissionWebhookNamespaceLabelENV, models.DefaultAdmissionWebhookNamespaceLabel)
+	viper.SetDefault(models.AdmissionWebhookPolicyQueryENV, models.DefaultAdmissionWebhookPolicyQuery)
+	viper.SetDefault(management.PortENV, 0)
+	viper.SetDefault(spiffe.EnabledENV, false)
 	store.Initialize()
 
 	log.Info("Local Provider capabilities are: ", version)
@@ -160,6 +182,31 @@ func main() {
 	log.Info("Using kubeconfig at: ", viper.GetString("KUBECONFIG_FOLDER"))
 	log.Info("Log level: ", log.GetLevel())
 
+	// managementCreds secure the connections from agents to the management service, when set
+	var managementCreds credentials.TransportCredentials
+	if viper.GetBool(spiffe.EnabledENV) {
+		spiffeConfig := spiffe.Config{
+			CertFile:      viper.GetString(spiffe.SVIDCertFileENV),
+			KeyFile:       viper.GetString(spiffe.SVIDKeyFileENV),
+			BundleFile:    viper.GetString(spiffe.BundleFileENV),
+			TrustDomain:   viper.GetString(spiffe.TrustDomainENV),
+			AuthorizedIDs: viper.GetStringSlice(spiffe.AuthorizedIDsENV),
+		}
+		clientTLSConfig, err := spiffe.NewClientTLSConfig(spiffeConfig)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		serverTLSConfig, err := spiffe.NewServerTLSConfig(spiffeConfig)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		meshes.UseTransportCredentials(credentials.NewTLS(clientTLSConfig))
+		managementCreds = credentials.NewTLS(serverTLSConfig)
+		log.Info("Using mutual TLS with SPIFFE identities of the trust domain ", spiffeConfig.TrustDomain)
+	}
+
 	adapterURLs := viper.GetStringSlice("ADAPTER_URLS")
 
 	adapterTracker := helpers.NewAdaptersTracker(adapterURLs)
@@ -197,6 +244,22 @@ func main() {
 		&models.PerformanceProfile{},
 		&models.MesheryResult{},
 		&models.MesheryPattern{},
+		&models.ComponentUsage{},
+		&models.CostCenterTag{},
+		&models.EphemeralEnvironmentPolicy{},
+		&models.EphemeralDeployment{},
+		&models.BackstageWebhook{},
+		&models.ManagedDeployment{},
+		&models.ResourceOwnership{},
+		&models.OwnershipOverride{},
+		&models.Workflow{},
+		&models.WorkflowRun{},
+		&models.AlertRule{},
+		&models.DesignComment{},
+		&models.DesignCommentReaction{},
+		&models.DesignFork{},
+		&models.DesignMergeRequest{},
+		&models.Dashboard{},
 		&models.MesheryFilter{},
 		&models.PatternResource{},
 		&models.MesheryApplication{},
@@ -271,6 +334,13 @@ func main() {
 		K8scontextChannel: models.NewContextHelper(),
 		OperatorTracker:   models.NewOperatorTracker(viper.GetBool("DISABLE_OPERATOR")),
 	}
+	reportingDB, err := models.NewReportingDB(dbHandler, log)
+	if err != nil {
+		log.Warn(err)
+	}
+	hc.ReportingDB = reportingDB
+	go reportingDB.Run()
+
 	krh, err := models.NewKeysRegistrationHelper(dbHandler, log)
 	if err != nil {
 		log.Error(ErrInitializingKeysRegistration(err))
@@ -375,6 +445,15 @@ func main() {
 			os.Exit(1)
 		}
 	}()
+
+	if managementPort := viper.GetInt(management.PortENV); managementPort > 0 {
+		go func() {
+			log.Info("Meshery Server management service listening on: ", managementPort)
+			if err := management.NewServer(r.S, hc.EventBroadcaster, log).Run(managementPort, managementCreds); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
 	<-c
 	regManager.Cleanup()
 	log.Info("Doing seeded content cleanup...")
@@ -397,6 +476,9 @@ func main() {
 	}
 	utils.DeleteSVGsFromFileSystem()
 	log.Info("Closing database instance...")
+	if err := reportingDB.Close(); err != nil {
+		log.Error(ErrClosingDatabaseInstance(err))
+	}
 	err = dbHandler.DBClose()
 	if err != nil {
 		log.Error(ErrClosingDatabaseInstance(err))
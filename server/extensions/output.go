@@ -11,4 +11,7 @@ type Router struct {
 // ExtensionOutput - output for a plugin
 type ExtensionOutput struct {
 	Router *Router
+	// Extension, when set, is registered with the server's extension registry:
+	// its routes are served under /api/extensions/{name} and its hooks receive lifecycle events.
+	Extension *Extension
 }
@@ -0,0 +1,287 @@
+package extensions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Hook identifies a lifecycle event of the server extensions can subscribe to.
+type Hook string
+
+const (
+	HookDesignSaved      Hook = "design.saved"
+	HookDesignDeleted    Hook = "design.deleted"
+	HookDesignDeployed   Hook = "design.deployed"
+	HookDesignUndeployed Hook = "design.undeployed"
+)
+
+// HookEvent is passed to the hooks subscribed to a lifecycle event.
+type HookEvent struct {
+	Hook       Hook
+	UserID     uuid.UUID
+	DesignID   uuid.UUID
+	DesignName string
+	Metadata   map[string]interface{}
+}
+
+// HookFunc handles a lifecycle event. Hooks run asynchronously and cannot
+// alter the outcome of the operation which triggered them.
+type HookFunc func(ctx context.Context, event HookEvent) error
+
+// Route is an HTTP endpoint served by an extension under /api/extensions/{name}.
+// Path is relative to the extension's base path.
+type Route struct {
+	Path    string
+	Methods []string
+	Handler http.Handler
+	// Scopes are the scopes a token must be granted to call the route.
+	Scopes []string
+}
+
+// Extension is the server side of a UI extension or remote provider plugin.
+type Extension struct {
+	// Name scopes the routes of the extension under /api/extensions/{name}.
+	Name   string
+	Routes []Route
+	Hooks  map[Hook][]HookFunc
+	// Scopes are the permissions granted to the tokens issued for the extension to any user.
+	Scopes []string
+	// RoleScopes are the permissions granted in addition to the tokens issued to the users
+	// with a role, by role name. Privileged scopes belong here rather than in Scopes.
+	RoleScopes map[string][]string
+}
+
+// Token is a credential scoped to a single extension.
+type Token struct {
+	Value     string    `json:"token"`
+	Extension string    `json:"extension"`
+	Scopes    []string  `json:"scopes"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HasScopes reports whether the token is granted all the scopes.
+func (t *Token) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		granted := false
+		for _, s := range t.Scopes {
+			if s == scope {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+// TokenTTL is the validity of tokens issued to extensions.
+const TokenTTL = 12 * time.Hour
+
+type tokenCtxKey struct{}
+
+// TokenFromContext returns the extension token the request to an extension route was authorized with.
+func TokenFromContext(ctx context.Context) (*Token, bool) {
+	t, ok := ctx.Value(tokenCtxKey{}).(*Token)
+	return t, ok
+}
+
+// Registry keeps the extensions registered with the server, the tokens issued
+// to them and dispatches lifecycle events to their hooks.
+type Registry struct {
+	mx         sync.RWMutex
+	extensions map[string]*Extension
+	tokens     map[string]*Token
+}
+
+// NewRegistry returns an empty extension registry
+func NewRegistry() *Registry {
+	return &Registry{
+		extensions: make(map[string]*Extension),
+		tokens:     make(map[string]*Token),
+	}
+}
+
+// Register adds the extension to the registry, replacing an extension already
+// registered with the same name.
+func (r *Registry) Register(ext *Extension) error {
+	if ext == nil || ext.Name == "" {
+		return fmt.Errorf("extension name is required")
+	}
+	if strings.Contains(ext.Name, "/") {
+		return fmt.Errorf("extension name %q must not contain \"/\"", ext.Name)
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.extensions[ext.Name] = ext
+	r.revokeTokens(ext.Name)
+	return nil
+}
+
+// Unregister removes the extension and revokes the tokens issued to it.
+func (r *Registry) Unregister(name string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	delete(r.extensions, name)
+	r.revokeTokens(name)
+}
+
+// Get returns the extension registered with the given name.
+func (r *Registry) Get(name string) (*Extension, bool) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	ext, ok := r.extensions[name]
+	return ext, ok
+}
+
+// IssueToken issues a token for the given user which is only valid for the
+// routes of the named extension. The token is granted the scopes the extension
+// grants to any user, along with the scopes it grants to the roles of the user.
+// Expired tokens are swept on issue.
+func (r *Registry) IssueToken(name string, userID uuid.UUID, roles []string) (*Token, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.sweepExpiredTokens(time.Now())
+	ext, ok := r.extensions[name]
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not registered", name)
+	}
+
+	byt := make([]byte, 32)
+	if _, err := rand.Read(byt); err != nil {
+		return nil, err
+	}
+	token := &Token{
+		Value:     hex.EncodeToString(byt),
+		Extension: name,
+		Scopes:    ext.grantedScopes(roles),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(TokenTTL),
+	}
+	r.tokens[token.Value] = token
+	return token, nil
+}
+
+// ValidateToken returns the token if it is valid for the named extension and was issued to the user.
+func (r *Registry) ValidateToken(name, value string, userID uuid.UUID) (*Token, bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	token, ok := r.tokens[value]
+	if !ok || token.Extension != name || token.UserID != userID {
+		return nil, false
+	}
+	if time.Now().After(token.ExpiresAt) {
+		delete(r.tokens, value)
+		return nil, false
+	}
+	return token, true
+}
+
+// grantedScopes returns the scopes of the extension granted to a user with the roles, without duplicates.
+func (ext *Extension) grantedScopes(roles []string) []string {
+	scopes := []string{}
+	granted := map[string]bool{}
+	grant := func(scope string) {
+		if !granted[scope] {
+			granted[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	for _, scope := range ext.Scopes {
+		grant(scope)
+	}
+	for _, role := range roles {
+		for _, scope := range ext.RoleScopes[role] {
+			grant(scope)
+		}
+	}
+	return scopes
+}
+
+// sweepExpiredTokens must be called with the lock held.
+func (r *Registry) sweepExpiredTokens(now time.Time) {
+	for value, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(r.tokens, value)
+		}
+	}
+}
+
+// revokeTokens must be called with the lock held.
+func (r *Registry) revokeTokens(name string) {
+	for value, token := range r.tokens {
+		if token.Extension == name {
+			delete(r.tokens, value)
+		}
+	}
+}
+
+// Route returns the extension route matching the path (relative to
+// /api/extensions/{name}) and method.
+func (r *Registry) Route(name, path, method string) (*Route, bool) {
+	ext, ok := r.Get(name)
+	if !ok {
+		return nil, false
+	}
+	path = "/" + strings.Trim(path, "/")
+	for i := range ext.Routes {
+		route := &ext.Routes[i]
+		if "/"+strings.Trim(route.Path, "/") != path {
+			continue
+		}
+		if len(route.Methods) == 0 {
+			return route, true
+		}
+		for _, m := range route.Methods {
+			if strings.EqualFold(m, method) {
+				return route, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Handler returns the handler of the extension route matching the path
+// (relative to /api/extensions/{name}) and method.
+func (r *Registry) Handler(name, path, method string) (http.Handler, bool) {
+	route, ok := r.Route(name, path, method)
+	if !ok {
+		return nil, false
+	}
+	return route.Handler, true
+}
+
+// WithToken returns a copy of the request carrying the token, for extension route handlers.
+func WithToken(req *http.Request, token *Token) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), tokenCtxKey{}, token))
+}
+
+// Dispatch runs the hooks subscribed to the event in the background.
+// Errors returned by hooks are passed to onError, which may be nil.
+func (r *Registry) Dispatch(ctx context.Context, event HookEvent, onError func(name string, err error)) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	for name, ext := range r.extensions {
+		for _, hook := range ext.Hooks[event.Hook] {
+			go func(name string, hook HookFunc) {
+				if err := hook(ctx, event); err != nil && onError != nil {
+					onError(name, err)
+				}
+			}(name, hook)
+		}
+	}
+}
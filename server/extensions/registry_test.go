@@ -0,0 +1,172 @@
+package extensions
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func newTestRegistry(t *testing.T, scopes ...string) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	err := r.Register(&Extension{
+		Name:   "test",
+		Scopes: scopes,
+		Routes: []Route{
+			{Path: "/designs", Methods: []string{http.MethodGet}, Handler: http.NotFoundHandler()},
+			{Path: "/designs", Methods: []string{http.MethodPost}, Handler: http.NotFoundHandler(), Scopes: []string{"designs:write"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestValidateToken(t *testing.T) {
+	r := newTestRegistry(t, "designs:read")
+	owner := uuid.Must(uuid.NewV4())
+	token, err := r.IssueToken("test", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		extension string
+		value     string
+		userID    uuid.UUID
+		valid     bool
+	}{
+		{name: "owner", extension: "test", value: token.Value, userID: owner, valid: true},
+		{name: "other user", extension: "test", value: token.Value, userID: uuid.Must(uuid.NewV4())},
+		{name: "no user", extension: "test", value: token.Value, userID: uuid.Nil},
+		{name: "other extension", extension: "other", value: token.Value, userID: owner},
+		{name: "unknown token", extension: "test", value: "unknown", userID: owner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := r.ValidateToken(tt.extension, tt.value, tt.userID)
+			if ok != tt.valid {
+				t.Errorf("ValidateToken() = %v, want %v", ok, tt.valid)
+			}
+		})
+	}
+
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+	if _, ok := r.ValidateToken("test", token.Value, owner); ok {
+		t.Error("expired token is valid")
+	}
+}
+
+func TestValidateToken_RevokedOnRegister(t *testing.T) {
+	r := newTestRegistry(t)
+	owner := uuid.Must(uuid.NewV4())
+	token, err := r.IssueToken("test", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(&Extension{Name: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.ValidateToken("test", token.Value, owner); ok {
+		t.Error("token of a replaced extension is valid")
+	}
+}
+
+func TestRouteScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		method  string
+		allowed bool
+	}{
+		{name: "route without scopes", method: http.MethodGet, allowed: true},
+		{name: "missing scope", scopes: []string{"designs:read"}, method: http.MethodPost},
+		{name: "granted scope", scopes: []string{"designs:read", "designs:write"}, method: http.MethodPost, allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, tt.scopes...)
+			token, err := r.IssueToken("test", uuid.Nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			route, ok := r.Route("test", "designs/", tt.method)
+			if !ok {
+				t.Fatal("route not found")
+			}
+			if got := token.HasScopes(route.Scopes...); got != tt.allowed {
+				t.Errorf("HasScopes(%v) = %v, want %v", route.Scopes, got, tt.allowed)
+			}
+		})
+	}
+
+	if _, ok := newTestRegistry(t).Route("test", "designs", http.MethodDelete); ok {
+		t.Error("route found for a method it does not serve")
+	}
+}
+
+func TestIssueToken_RoleScopes(t *testing.T) {
+	r := NewRegistry()
+	err := r.Register(&Extension{
+		Name:   "test",
+		Scopes: []string{"designs:read"},
+		RoleScopes: map[string][]string{
+			"admin":    {"designs:read", "designs:write"},
+			"operator": {"designs:deploy"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "no role", want: []string{"designs:read"}},
+		{name: "role without scopes", roles: []string{"viewer"}, want: []string{"designs:read"}},
+		{name: "admin", roles: []string{"admin"}, want: []string{"designs:read", "designs:write"}},
+		{name: "several roles", roles: []string{"operator", "admin"}, want: []string{"designs:read", "designs:deploy", "designs:write"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := r.IssueToken("test", uuid.Must(uuid.NewV4()), tt.roles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(token.Scopes, tt.want) {
+				t.Errorf("scopes = %v, want %v", token.Scopes, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueToken_SweepsExpiredTokens(t *testing.T) {
+	r := newTestRegistry(t)
+	owner := uuid.Must(uuid.NewV4())
+	expired, err := r.IssueToken("test", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := r.IssueToken("test", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := r.IssueToken("test", owner, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.tokens[expired.Value]; ok {
+		t.Error("expired token is still kept")
+	}
+	if _, ok := r.tokens[valid.Value]; !ok {
+		t.Error("valid token was swept")
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/spf13/viper"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// swagger:route POST /api/system/admission/validate SystemAPI idValidateAdmissionReview
+// Handle POST request from the Kubernetes API server to validate a resource against the design policies
+//
+// The request body is an AdmissionReview (admission.k8s.io/v1). The admitted resource is converted to a design
+// and evaluated with the same policies used at design time; the resource is denied when any policy is violated.
+// Only resources of the namespaces labeled for policy enforcement are sent by the API server,
+// see /api/system/admission/configuration.
+//
+// The route does not authenticate its callers, as the API server has no Meshery session, and Meshery Server does
+// not verify client certificates. Expose it to the API server only through a TLS terminating proxy verifying the
+// client certificate of the API server, or restrict access to it with network policies.
+// responses:
+// 	200: admissionReviewResponseWrapper
+
+func (h *Handler) ValidateAdmissionReviewHandler(rw http.ResponseWriter, r *http.Request) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	review := admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		h.log.Error(ErrDecoding(err, "admission review"))
+		http.Error(rw, ErrDecoding(err, "admission review").Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		err := ErrAdmissionReview(fmt.Errorf("admission review does not contain a request"))
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+
+	// Deleted resources have no object to validate
+	if len(review.Request.Object.Raw) > 0 {
+		violations, err := h.evaluateAdmissionRequest(r, review.Request)
+		if err != nil {
+			// Let the API server apply the failure policy of the webhook
+			h.log.Error(err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(violations) > 0 {
+			h.log.Info(fmt.Sprintf("denied %s %s/%s: %s", review.Request.Kind.Kind, review.Request.Namespace, review.Request.Name, strings.Join(violations, "; ")))
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonForbidden,
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf("Denied by Meshery design policies: %s", strings.Join(violations, "; ")),
+			}
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		h.log.Error(models.ErrEncoding(err, "admission review"))
+		http.Error(rw, models.ErrEncoding(err, "admission review").Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) evaluateAdmissionRequest(r *http.Request, req *admissionv1.AdmissionRequest) ([]string, error) {
+	name := fmt.Sprintf("%s-%s", strings.ToLower(req.Kind.Kind), req.Name)
+	design, err := core.NewPatternFileFromK8sManifest(string(req.Object.Raw), name, false, h.registryManager)
+	if err != nil {
+		// Resources that are not registered are still checked, with a component derived from their GVK
+		design, err = core.NewPatternFileFromK8sManifest(string(req.Object.Raw), name, false, nil)
+	}
+	if err != nil {
+		return nil, ErrAdmissionReview(err)
+	}
+	for _, comp := range design.Components {
+		comp.Configuration = core.Format.DePrettify(comp.Configuration, false)
+	}
+
+	evaluator, err := h.admissionPolicyEvaluator(r.Context())
+	if err != nil {
+		return nil, ErrAdmissionReview(err)
+	}
+	violations, err := evaluator.Evaluate(r.Context(), design)
+	if err != nil {
+		return nil, ErrAdmissionReview(err)
+	}
+	return violations, nil
+}
+
+// admissionPolicyEvaluator returns the evaluator of the policy query of the webhook, preparing it on first use.
+// Preparation is retried by the next admission request when it fails.
+func (h *Handler) admissionPolicyEvaluator(ctx context.Context) (*models.DesignPolicyEvaluator, error) {
+	h.admissionPoliciesMx.Lock()
+	defer h.admissionPoliciesMx.Unlock()
+	if h.admissionPolicies != nil {
+		return h.admissionPolicies, nil
+	}
+	evaluator, err := models.NewDesignPolicyEvaluator(ctx, models.PoliciesPath, viper.GetString(models.AdmissionWebhookPolicyQueryENV))
+	if err != nil {
+		return nil, err
+	}
+	h.admissionPolicies = evaluator
+	return evaluator, nil
+}
+
+// swagger:route GET /api/system/admission/configuration SystemAPI idGetAdmissionWebhookConfiguration
+// Handle GET request for the ValidatingWebhookConfiguration registering the Meshery admission webhook
+//
+// ?url={url} - URL at which the API server reaches the webhook, defaults to the host of the request. The webhook must be served over TLS.
+// ?caBundle={caBundle} - base64 encoded PEM bundle used by the API server to verify the certificate of the webhook
+//
+// Apply the returned manifest and label namespaces with <ADMISSION_WEBHOOK_NAMESPACE_LABEL>=enabled to enforce the design policies on their resources.
+// responses:
+// 	200: admissionWebhookConfigurationResponseWrapper
+
+func (h *Handler) GetAdmissionWebhookConfigurationHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	q := r.URL.Query()
+
+	url := q.Get("url")
+	if url == "" {
+		url = fmt.Sprintf("https://%s%s", r.Host, models.AdmissionWebhookPath)
+	}
+
+	var caBundle []byte
+	if q.Get("caBundle") != "" {
+		var err error
+		caBundle, err = base64.StdEncoding.DecodeString(q.Get("caBundle"))
+		if err != nil {
+			h.log.Error(ErrQueryGet("caBundle"))
+			http.Error(rw, ErrQueryGet("caBundle").Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	config := models.NewAdmissionWebhookConfiguration(url, caBundle, viper.GetString(models.AdmissionWebhookNamespaceLabelENV))
+	manifest, err := yaml.Marshal(config)
+	if err != nil {
+		h.log.Error(models.ErrMarshal(err, "admission webhook configuration"))
+		http.Error(rw, models.ErrMarshal(err, "admission webhook configuration").Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/yaml")
+	_, _ = rw.Write(manifest)
+}
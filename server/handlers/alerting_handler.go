@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+const (
+	// alertEvaluationInterval is the interval between the evaluations of the alert rules.
+	alertEvaluationInterval = time.Minute
+	// alertNotificationTimeout bounds the delivery of a notification to a webhook.
+	alertNotificationTimeout = 10 * time.Second
+)
+
+// swagger:route GET /api/alerts/rules AlertsAPI idGetAlertRules
+// Handle GET request for the alert rules of the user, along with their state
+//
+// responses:
+// 	200: alertRulesResponseWrapper
+
+func (h *Handler) GetAlertRulesHandler(rw http.ResponseWriter, _ *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	ap := &models.AlertRulePersister{DB: provider.GetGenericPersister()}
+	rules, err := ap.GetRules(uuid.FromStringOrNil(user.ID))
+	if err != nil {
+		h.log.Error(ErrAlertRule(err, ""))
+		http.Error(rw, ErrAlertRule(err, "").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, rules, "alert rules")
+}
+
+// swagger:route POST /api/alerts/rules AlertsAPI idSaveAlertRule
+// Handle POST request to store an alert rule
+//
+// The body is the alert rule in YAML: a name, the metric observed among event_rate, deployment_failure_streak,
+// drift_count and adapter_down, a condition on it such as "> 10", how long the condition holds before the rule
+// fires and the channels notified, events or webhook. The rules are evaluated every minute.
+// responses:
+// 	201: alertRuleResponseWrapper
+
+func (h *Handler) SaveAlertRuleHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.saveAlertRule(rw, r, user, provider, nil)
+}
+
+// swagger:route GET /api/alerts/rules/{id} AlertsAPI idGetAlertRule
+// Handle GET request for an alert rule, along with its state
+//
+// responses:
+// 	200: alertRuleResponseWrapper
+
+func (h *Handler) GetAlertRuleHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	rule, ok := h.getAlertRule(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, rule, "alert rule")
+}
+
+// swagger:route PUT /api/alerts/rules/{id} AlertsAPI idUpdateAlertRule
+// Handle PUT request to replace the definition of an alert rule
+//
+// The state of the rule is kept, a firing rule is notified as resolved once the new condition no longer holds.
+// responses:
+// 	200: alertRuleResponseWrapper
+
+func (h *Handler) UpdateAlertRuleHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	rule, ok := h.getAlertRule(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.saveAlertRule(rw, r, user, provider, rule)
+}
+
+// swagger:route DELETE /api/alerts/rules/{id} AlertsAPI idDeleteAlertRule
+// Handle DELETE request to delete an alert rule
+//
+// responses:
+// 	200:
+
+func (h *Handler) DeleteAlertRuleHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	rule, ok := h.getAlertRule(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	ap := &models.AlertRulePersister{DB: provider.GetGenericPersister()}
+	if err := ap.DeleteRule(rule.ID); err != nil {
+		h.log.Error(ErrAlertRule(err, rule.Name))
+		http.Error(rw, ErrAlertRule(err, rule.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// saveAlertRule stores the alert rule of the body of the request, as a new rule unless rule is set.
+func (h *Handler) saveAlertRule(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, rule *models.AlertRule) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	def, err := models.ParseAlertRuleDefinition(body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	if rule == nil {
+		rule = &models.AlertRule{UserID: uuid.FromStringOrNil(user.ID)}
+		status = http.StatusCreated
+	}
+	rule.Name = def.Name
+	rule.Description = def.Description
+	rule.Definition = string(body)
+
+	ap := &models.AlertRulePersister{DB: provider.GetGenericPersister()}
+	if err := ap.SaveRule(rule); err != nil {
+		h.log.Error(ErrAlertRule(err, rule.Name))
+		http.Error(rw, ErrAlertRule(err, rule.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, status, rule, "alert rule")
+}
+
+// getAlertRule returns the alert rule of the request, writing the error response if it is not a rule of the user.
+func (h *Handler) getAlertRule(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.AlertRule, bool) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	ap := &models.AlertRulePersister{DB: provider.GetGenericPersister()}
+	rule, err := ap.GetRule(id)
+	if err != nil {
+		h.log.Error(ErrAlertRule(err, id.String()))
+		http.Error(rw, ErrAlertRule(err, id.String()).Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if rule == nil || rule.UserID != uuid.FromStringOrNil(user.ID) {
+		http.Error(rw, fmt.Sprintf("alert rule %s does not exist", id), http.StatusNotFound)
+		return nil, false
+	}
+	return rule, true
+}
+
+// runAlertRuleEvaluator evaluates the alert rules of every user periodically.
+func (h *Handler) runAlertRuleEvaluator() {
+	ticker := time.NewTicker(alertEvaluationInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.evaluateAlertRules()
+	}
+}
+
+// evaluateAlertRules measures the metric of every enabled alert rule, updates the state of the rules and
+// notifies the channels of the rules which fired or resolved. The metrics are measured once per evaluation,
+// the rules observing the same metric with the same filter share the measure.
+func (h *Handler) evaluateAlertRules() {
+	ap := &models.AlertRulePersister{DB: h.dbHandler}
+	rules, err := ap.GetRules(uuid.Nil)
+	if err != nil {
+		h.log.Error(ErrAlertRule(err, ""))
+		return
+	}
+
+	measurer := &models.AlertMeasurer{DB: h.dbHandler, Adapters: h.config.AdapterTracker}
+	measures := map[string]float64{}
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		def, err := models.ParseAlertRuleDefinition([]byte(rule.Definition))
+		if err != nil {
+			rule.Error = err.Error()
+			_ = ap.SaveRule(rule)
+			continue
+		}
+		if def.Disabled {
+			continue
+		}
+
+		key := fmt.Sprintf("%s %s %+v", def.Metric, def.Window, def.Filter)
+		value, measured := measures[key]
+		if !measured {
+			value, err = measurer.Measure(context.Background(), def, now)
+			if err != nil {
+				h.log.Warn(ErrAlertRule(err, rule.Name))
+				rule.Error = err.Error()
+				_ = ap.SaveRule(rule)
+				continue
+			}
+			measures[key] = value
+		}
+
+		transition := rule.Observe(def, value, now)
+		if err := ap.SaveRule(rule); err != nil {
+			h.log.Error(ErrAlertRule(err, rule.Name))
+			continue
+		}
+		if transition != "" {
+			h.notifyAlertRule(rule, def, transition)
+		}
+	}
+}
+
+// notifyAlertRule routes the notification of the transition of the rule to its channels.
+func (h *Handler) notifyAlertRule(rule *models.AlertRule, def *models.AlertRuleDefinition, transition models.AlertTransition) {
+	severity := def.Severity
+	if severity == "" {
+		severity = events.Warning
+	}
+	description := fmt.Sprintf("Alert %s fired: %s is %g, %s", rule.Name, def.Metric, rule.Value, def.Condition)
+	if transition == models.AlertTransitionResolved {
+		severity = events.Success
+		description = fmt.Sprintf("Alert %s resolved: %s is %g", rule.Name, def.Metric, rule.Value)
+	}
+
+	for _, channel := range def.Channels {
+		switch channel.Type {
+		case models.AlertChannelEvents:
+			event := events.NewEvent().ActedUpon(rule.ID).FromUser(rule.UserID).FromSystem(*h.SystemID).
+				WithCategory("alert").WithAction(string(transition)).WithSeverity(severity).
+				WithDescription(description).WithMetadata(map[string]interface{}{
+				"rule":      rule.Name,
+				"metric":    def.Metric,
+				"value":     rule.Value,
+				"condition": def.Condition,
+			}).Build()
+			ep := &models.EventsPersister{DB: h.dbHandler}
+			if err := ep.PersistEvent(event); err != nil {
+				h.log.Warn(err)
+			}
+			go h.config.EventBroadcaster.Publish(rule.UserID, event)
+		case models.AlertChannelWebhook:
+			go func(url string) {
+				ctx, cancel := context.WithTimeout(context.Background(), alertNotificationTimeout)
+				defer cancel()
+				if err := models.NotifyWorkflowWebhook(ctx, url, description); err != nil {
+					h.log.Warn(ErrAlertRule(err, rule.Name))
+				}
+			}(channel.URL)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+func newAlertingTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.AlertRule{}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// newTestAlertWebhook returns the URL of a webhook responding with the status, and the texts it receives.
+func newTestAlertWebhook(t *testing.T, status int) (string, <-chan string) {
+	t.Helper()
+	texts := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts <- body["text"]
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, texts
+}
+
+func receiveAlertNotification(t *testing.T, texts <-chan string) string {
+	t.Helper()
+	select {
+	case text := <-texts:
+		return text
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the webhook to be notified")
+		return ""
+	}
+}
+
+// persistDeployments records deployments of the design, as the deploy handler does.
+func persistDeployments(t *testing.T, h *Handler, designID uuid.UUID, severity events.EventSeverity, count int) {
+	t.Helper()
+	ep := &models.EventsPersister{DB: h.dbHandler}
+	for i := 0; i < count; i++ {
+		event := events.NewEvent().ActedUpon(designID).FromUser(uuid.Must(uuid.NewV4())).FromSystem(*h.SystemID).
+			WithCategory("pattern").WithAction("deploy").WithSeverity(severity).Build()
+		if err := ep.PersistEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// alertEvents returns the actions of the events notified for the rule.
+func alertEvents(t *testing.T, h *Handler, rule *models.AlertRule) []string {
+	t.Helper()
+	recorded := []events.Event{}
+	if err := h.dbHandler.Where("acted_upon = ?", rule.ID).Order("created_at").Find(&recorded).Error; err != nil {
+		t.Fatal(err)
+	}
+	actions := []string{}
+	for _, event := range recorded {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+func saveAlertRule(t *testing.T, h *Handler, definition string) *models.AlertRule {
+	t.Helper()
+	ap := &models.AlertRulePersister{DB: h.dbHandler}
+	rule := &models.AlertRule{Name: "failing", Definition: definition, UserID: uuid.Must(uuid.NewV4())}
+	if err := ap.SaveRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+func getAlertRule(t *testing.T, h *Handler, rule *models.AlertRule) *models.AlertRule {
+	t.Helper()
+	ap := &models.AlertRulePersister{DB: h.dbHandler}
+	saved, err := ap.GetRule(rule.ID)
+	if err != nil || saved == nil {
+		t.Fatalf("expected the rule to be saved: %v", err)
+	}
+	return saved
+}
+
+func TestEvaluateAlertRules_FiresAndResolves(t *testing.T) {
+	h := newAlertingTestHandler(t)
+	url, texts := newTestAlertWebhook(t, http.StatusOK)
+	rule := saveAlertRule(t, h, `{name: failing, metric: deployment_failure_streak, condition: ">= 2", severity: error, channels: [{type: events}, {type: webhook, url: "`+url+`"}]}`)
+
+	designID := uuid.Must(uuid.NewV4())
+	persistDeployments(t, h, designID, events.Error, 1)
+	h.evaluateAlertRules()
+	if got := getAlertRule(t, h, rule); got.State != models.AlertInactive || got.Value != 1 {
+		t.Fatalf("expected the rule to be inactive, got %s with %g", got.State, got.Value)
+	}
+
+	persistDeployments(t, h, designID, events.Error, 1)
+	h.evaluateAlertRules()
+	if got := getAlertRule(t, h, rule); got.State != models.AlertFiring || got.FiredAt == nil {
+		t.Fatalf("expected the rule to fire, got %s", got.State)
+	}
+	if text := receiveAlertNotification(t, texts); text != "Alert failing fired: deployment_failure_streak is 2, >= 2" {
+		t.Errorf("webhook text = %q", text)
+	}
+
+	// firing still, not notified again
+	h.evaluateAlertRules()
+	persistDeployments(t, h, designID, events.Success, 1)
+	h.evaluateAlertRules()
+	if got := getAlertRule(t, h, rule); got.State != models.AlertInactive || got.ResolvedAt == nil {
+		t.Fatalf("expected the rule to resolve, got %s", got.State)
+	}
+	if text := receiveAlertNotification(t, texts); text != "Alert failing resolved: deployment_failure_streak is 0" {
+		t.Errorf("webhook text = %q", text)
+	}
+
+	if got := alertEvents(t, h, rule); len(got) != 2 || got[0] != string(models.AlertTransitionFired) || got[1] != string(models.AlertTransitionResolved) {
+		t.Errorf("expected the rule to be notified once fired and once resolved, got %v", got)
+	}
+}
+
+func TestEvaluateAlertRules_NotifierFailure(t *testing.T) {
+	h := newAlertingTestHandler(t)
+	url, texts := newTestAlertWebhook(t, http.StatusInternalServerError)
+	rule := saveAlertRule(t, h, `{name: failing, metric: event_rate, filter: {category: pattern}, condition: "> 0", channels: [{type: webhook, url: "`+url+`"}, {type: events}]}`)
+
+	persistDeployments(t, h, uuid.Must(uuid.NewV4()), events.Error, 1)
+	h.evaluateAlertRules()
+	receiveAlertNotification(t, texts)
+
+	got := getAlertRule(t, h, rule)
+	if got.State != models.AlertFiring || got.Error != "" {
+		t.Errorf("expected the rule to fire whatever the delivery of its notifications, got %s: %s", got.State, got.Error)
+	}
+	if actions := alertEvents(t, h, rule); len(actions) != 1 {
+		t.Errorf("expected the other channels to be notified, got %v", actions)
+	}
+}
+
+func TestEvaluateAlertRules_InvalidRules(t *testing.T) {
+	h := newAlertingTestHandler(t)
+	invalid := saveAlertRule(t, h, `{name: failing, metric: cpu, condition: "> 0", channels: [{type: events}]}`)
+	disabled := saveAlertRule(t, h, `{name: failing, metric: event_rate, condition: ">= 0", disabled: true, channels: [{type: events}]}`)
+
+	h.evaluateAlertRules()
+
+	if got := getAlertRule(t, h, invalid); got.Error == "" || got.EvaluatedAt != nil {
+		t.Errorf("expected the error of the invalid rule to be recorded, got %+v", got)
+	}
+	if got := getAlertRule(t, h, disabled); got.State != models.AlertInactive || got.EvaluatedAt != nil {
+		t.Errorf("expected the disabled rule not to be evaluated, got %+v", got)
+	}
+}
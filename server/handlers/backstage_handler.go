@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// backstageExtensionName is the name of the built-in extension notifying Backstage of design changes.
+const backstageExtensionName = "backstage"
+
+// swagger:route GET /api/integrations/backstage/entities BackstageAPI idGetBackstageEntities
+// Handle GET request for the designs and their owners as Backstage catalog entities
+//
+// Designs are returned as Components annotated with their id and health, linking back to Meshery.
+// The owners of the designs are returned as Users.
+// responses:
+// 	200: backstageEntitiesResponseWrapper
+
+func (h *Handler) GetBackstageEntitiesHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	bp := &models.BackstagePersister{DB: provider.GetGenericPersister()}
+	designs, err := bp.GetDesigns()
+	if err != nil {
+		h.log.Error(ErrBackstageEntities(err))
+		http.Error(rw, ErrBackstageEntities(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	health, err := bp.GetDesignsHealth(designs)
+	if err != nil {
+		h.log.Error(ErrBackstageEntities(err))
+		http.Error(rw, ErrBackstageEntities(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mesheryURL := requestBaseURL(r)
+	entities := models.BackstageEntities{Items: []models.BackstageEntity{}}
+	owners := map[string]bool{}
+	for i := range designs {
+		design := &designs[i]
+		if design.ID == nil {
+			continue
+		}
+		owner := ""
+		if design.UserID != nil {
+			owner = *design.UserID
+			owners[owner] = true
+		}
+		entities.Items = append(entities.Items, models.NewBackstageDesignEntity(design, health[*design.ID], owner, mesheryURL))
+	}
+
+	ownerIDs := make([]string, 0, len(owners))
+	for id := range owners {
+		ownerIDs = append(ownerIDs, id)
+	}
+	sort.Strings(ownerIDs)
+	for _, id := range ownerIDs {
+		user := &models.User{ID: id}
+		if resp, err := provider.GetUserByID(r, id); err == nil {
+			_ = json.Unmarshal(resp, user)
+		}
+		entities.Items = append(entities.Items, models.NewBackstageUserEntity(user))
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(entities); err != nil {
+		h.log.Error(models.ErrMarshal(err, "backstage entities"))
+		http.Error(rw, models.ErrMarshal(err, "backstage entities").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route GET /api/integrations/backstage/entities/{id} BackstageAPI idGetBackstageDesignEntity
+// Handle GET request for a design as a Backstage catalog entity
+//
+// responses:
+// 	200: backstageEntityResponseWrapper
+
+func (h *Handler) GetBackstageDesignEntityHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	designID, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	bp := &models.BackstagePersister{DB: provider.GetGenericPersister()}
+	entity, err := h.backstageDesignEntity(bp, designID, requestBaseURL(r))
+	if err != nil {
+		h.log.Error(ErrBackstageEntities(err))
+		http.Error(rw, ErrBackstageEntities(err).Error(), http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(entity); err != nil {
+		h.log.Error(models.ErrMarshal(err, "backstage entity"))
+		http.Error(rw, models.ErrMarshal(err, "backstage entity").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route GET /api/integrations/backstage/webhooks BackstageAPI idGetBackstageWebhooks
+// Handle GET request for the webhooks notified of design changes
+//
+// responses:
+// 	200: backstageWebhooksResponseWrapper
+
+func (h *Handler) GetBackstageWebhooksHandler(rw http.ResponseWriter, _ *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	bp := &models.BackstagePersister{DB: provider.GetGenericPersister()}
+	webhooks, err := bp.GetWebhooks()
+	if err != nil {
+		h.log.Error(ErrBackstageWebhook(err))
+		http.Error(rw, ErrBackstageWebhook(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(webhooks); err != nil {
+		h.log.Error(models.ErrMarshal(err, "backstage webhooks"))
+		http.Error(rw, models.ErrMarshal(err, "backstage webhooks").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/integrations/backstage/webhooks BackstageAPI idSaveBackstageWebhook
+// Handle POST request to register a webhook notified when a design is saved, deleted, deployed or undeployed
+//
+// Notifications carry the updated entity of the design and, when a secret is provided,
+// the HMAC-SHA256 of the body in the X-Meshery-Signature header.
+// responses:
+// 	200: backstageWebhookResponseWrapper
+
+func (h *Handler) SaveBackstageWebhookHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	var webhook models.BackstageWebhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("url must be an absolute http(s) URL")).Error(), http.StatusBadRequest)
+		return
+	}
+	if webhook.MesheryURL == "" {
+		webhook.MesheryURL = requestBaseURL(r)
+	}
+	webhook.UserID = uuid.FromStringOrNil(user.ID)
+
+	bp := &models.BackstagePersister{DB: provider.GetGenericPersister()}
+	if err := bp.SaveWebhook(&webhook); err != nil {
+		h.log.Error(ErrBackstageWebhook(err))
+		http.Error(rw, ErrBackstageWebhook(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	webhook.Secret = ""
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(webhook); err != nil {
+		h.log.Error(models.ErrMarshal(err, "backstage webhook"))
+		http.Error(rw, models.ErrMarshal(err, "backstage webhook").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route DELETE /api/integrations/backstage/webhooks/{id} BackstageAPI idDeleteBackstageWebhook
+// Handle DELETE request to unregister a webhook
+//
+// responses:
+// 	200: noContentWrapper
+
+func (h *Handler) DeleteBackstageWebhookHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	bp := &models.BackstagePersister{DB: provider.GetGenericPersister()}
+	if err := bp.DeleteWebhook(id); err != nil {
+		h.log.Error(ErrBackstageWebhook(err))
+		http.Error(rw, ErrBackstageWebhook(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) backstageDesignEntity(bp *models.BackstagePersister, designID uuid.UUID, mesheryURL string) (*models.BackstageEntity, error) {
+	design, err := bp.GetDesign(designID)
+	if err != nil {
+		return nil, err
+	}
+	health, err := bp.GetDesignsHealth([]models.MesheryPattern{*design})
+	if err != nil {
+		return nil, err
+	}
+	owner := ""
+	if design.UserID != nil {
+		owner = *design.UserID
+	}
+	entity := models.NewBackstageDesignEntity(design, health[designID], owner, mesheryURL)
+	return &entity, nil
+}
+
+// backstageExtension returns the built-in extension notifying the Backstage webhooks of design changes.
+func (h *Handler) backstageExtension() *extensions.Extension {
+	hooks := map[extensions.Hook][]extensions.HookFunc{}
+	for _, hook := range []extensions.Hook{extensions.HookDesignSaved, extensions.HookDesignDeleted, extensions.HookDesignDeployed, extensions.HookDesignUndeployed} {
+		hooks[hook] = []extensions.HookFunc{h.notifyBackstageWebhooks}
+	}
+	return &extensions.Extension{
+		Name:  backstageExtensionName,
+		Hooks: hooks,
+	}
+}
+
+func (h *Handler) notifyBackstageWebhooks(ctx context.Context, event extensions.HookEvent) error {
+	bp := &models.BackstagePersister{DB: h.dbHandler}
+	webhooks, err := bp.GetWebhooks()
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	notification := models.BackstageNotification{
+		Event:      string(event.Hook),
+		DesignID:   event.DesignID,
+		DesignName: event.DesignName,
+		Timestamp:  time.Now(),
+	}
+	for _, webhook := range webhooks {
+		if event.Hook != extensions.HookDesignDeleted {
+			// Entities link back to the Meshery address the webhook was registered with
+			notification.Entity, _ = h.backstageDesignEntity(bp, event.DesignID, webhook.MesheryURL)
+		}
+		if err := models.NotifyBackstageWebhook(ctx, webhook, notification); err != nil {
+			h.log.Warn(ErrBackstageWebhook(fmt.Errorf("failed to notify %s: %w", webhook.URL, err)))
+		}
+	}
+	return nil
+}
+
+// requestBaseURL returns the address at which the client reached Meshery.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
+)
+
+func TestNotifyBackstageWebhooks(t *testing.T) {
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.BackstageWebhook{}, &models.MesheryPattern{},
+		&meshsyncmodel.KubernetesKeyValue{}, &meshsyncmodel.KubernetesResource{}, &meshsyncmodel.KubernetesResourceSpec{},
+		&meshsyncmodel.KubernetesResourceStatus{}, &meshsyncmodel.KubernetesResourceObjectMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	designID := uuid.Must(uuid.NewV4())
+	owner := uuid.Must(uuid.NewV4()).String()
+	if err := h.dbHandler.Create(&models.MesheryPattern{ID: &designID, Name: "web", PatternFile: "name: web", UserID: &owner}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan models.BackstageNotification, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notification := models.BackstageNotification{}
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Error(err)
+		}
+		if r.Header.Get(models.BackstageSignatureHeader) == "" {
+			t.Error("expected the notification to be signed")
+		}
+		received <- notification
+	}))
+	defer srv.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	bp := &models.BackstagePersister{DB: h.dbHandler}
+	for _, webhook := range []*models.BackstageWebhook{
+		{URL: failing.URL, MesheryURL: "https://meshery.example.com"},
+		{URL: srv.URL, Secret: "s3cr3t", MesheryURL: "https://meshery.example.com"},
+	} {
+		if err := bp.SaveWebhook(webhook); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		hook       extensions.Hook
+		wantEntity bool
+	}{
+		{hook: extensions.HookDesignSaved, wantEntity: true},
+		{hook: extensions.HookDesignDeployed, wantEntity: true},
+		{hook: extensions.HookDesignDeleted},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.hook), func(t *testing.T) {
+			// a failing webhook does not prevent the others from being notified
+			if err := h.notifyBackstageWebhooks(context.Background(), extensions.HookEvent{Hook: tt.hook, DesignID: designID, DesignName: "web"}); err != nil {
+				t.Fatal(err)
+			}
+			notification := <-received
+			if notification.Event != string(tt.hook) || notification.DesignID != designID || notification.DesignName != "web" {
+				t.Errorf("unexpected notification %+v", notification)
+			}
+			if !tt.wantEntity {
+				if notification.Entity != nil {
+					t.Errorf("expected no entity for a deleted design, got %+v", notification.Entity)
+				}
+				return
+			}
+			if notification.Entity == nil {
+				t.Fatal("expected the entity of the design")
+			}
+			entity := notification.Entity
+			if entity.Metadata.Annotations[models.BackstageDesignIDAnnotation] != designID.String() ||
+				entity.Metadata.Annotations[models.BackstageHealthAnnotation] != string(models.DesignHealthNotDeployed) ||
+				entity.Spec["owner"] != "user:"+owner {
+				t.Errorf("unexpected entity %+v", entity)
+			}
+			if len(entity.Metadata.Links) == 0 || entity.Metadata.Links[0].URL != "https://meshery.example.com/configuration/designs/configurator?design_id="+designID.String() {
+				t.Errorf("expected the entity to link to the Meshery address of the webhook, got %+v", entity.Metadata.Links)
+			}
+		})
+	}
+}
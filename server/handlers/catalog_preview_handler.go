@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/spf13/viper"
+)
+
+const catalogPreviewsDir = "catalog-previews"
+
+// catalogPreviewHashFile records the hash of the design the cached previews were rendered from.
+const catalogPreviewHashFile = "design.sha256"
+
+func catalogPreviewPath(designID uuid.UUID) string {
+	return filepath.Join(viper.GetString("USER_DATA_FOLDER"), catalogPreviewsDir, designID.String())
+}
+
+// generateCatalogPreviews renders the preview images of the design at every
+// catalog resolution. Previews are cached and only rendered again when the design changes.
+// Previews of the same design may be rendered concurrently, eg. on publish and on a request
+// for a missing preview, so every file of the cache is replaced atomically.
+func generateCatalogPreviews(designID uuid.UUID, patternFileContent string) error {
+	dir := catalogPreviewPath(designID)
+	sum := sha256.Sum256([]byte(patternFileContent))
+	hash := hex.EncodeToString(sum[:])
+
+	if cached, err := os.ReadFile(filepath.Join(dir, catalogPreviewHashFile)); err == nil && string(cached) == hash {
+		return nil
+	}
+
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		return ErrGenerateCatalogPreview(err, designID.String())
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ErrGenerateCatalogPreview(utils.ErrCreateDir(err, dir), designID.String())
+	}
+	for _, size := range core.CatalogPreviewSizes {
+		img, err := core.RenderPreview(&patternFile, size)
+		if err != nil {
+			return ErrGenerateCatalogPreview(err, designID.String())
+		}
+		if err := writeFileAtomic(filepath.Join(dir, size.String()+".png"), img); err != nil {
+			return ErrGenerateCatalogPreview(err, designID.String())
+		}
+	}
+	if err := writeFileAtomic(filepath.Join(dir, catalogPreviewHashFile), []byte(hash)); err != nil {
+		return ErrGenerateCatalogPreview(err, designID.String())
+	}
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file renamed to the path, so that readers
+// never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fetchDesignContent returns the design file of the stored design.
+func fetchDesignContent(r *http.Request, provider models.Provider, designID uuid.UUID) (string, error) {
+	resp, err := provider.GetMesheryPattern(r, designID.String(), "false")
+	if err != nil {
+		return "", err
+	}
+	var design models.MesheryPattern
+	if err := json.Unmarshal(resp, &design); err != nil {
+		return "", models.ErrUnmarshal(err, "design")
+	}
+	return design.PatternFile, nil
+}
+
+// swagger:route GET /api/pattern/catalog/preview/{id} PatternsAPI idGetCatalogPatternPreview
+// Handle GET request for the preview image of a catalog design
+//
+// Returns a PNG preview of the design's topology. Previews are generated when a design is published
+// and rendered on demand when missing or outdated.
+//
+// ```?size={width}x{height}``` One of 1200x630, 600x315 or 300x158. Defaults to 600x315.
+// responses:
+// 	200: []byte
+
+func (h *Handler) GetCatalogPatternPreviewHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	designID, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = core.CatalogPreviewSizes[1].String()
+	}
+	supported := false
+	for _, s := range core.CatalogPreviewSizes {
+		if s.String() == size {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		http.Error(rw, ErrUnsupportedPreviewSize(size).Error(), http.StatusBadRequest)
+		return
+	}
+
+	content, err := fetchDesignContent(r, provider, designID)
+	if err != nil {
+		h.log.Error(ErrGetPattern(err))
+		http.Error(rw, ErrGetPattern(err).Error(), http.StatusNotFound)
+		return
+	}
+	if err := generateCatalogPreviews(designID, content); err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "image/png")
+	http.ServeFile(rw, r, filepath.Join(catalogPreviewPath(designID), size+".png"))
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"github.com/spf13/viper"
+)
+
+func TestGenerateCatalogPreviews_Concurrent(t *testing.T) {
+	viper.Set("USER_DATA_FOLDER", t.TempDir())
+	defer viper.Set("USER_DATA_FOLDER", "")
+
+	designs := make([]string, 2)
+	for i := range designs {
+		pf := pattern.PatternFile{Name: "preview"}
+		for j := 0; j <= i; j++ {
+			name := uuid.Must(uuid.NewV4()).String()
+			pf.Components = append(pf.Components, &component.ComponentDefinition{
+				Id:          uuid.NewV5(uuid.Nil, name),
+				DisplayName: name,
+				Component:   component.Component{Kind: "Pod", Version: "v1"},
+			})
+		}
+		byt, err := json.Marshal(pf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		designs[i] = string(byt)
+	}
+
+	designID := uuid.Must(uuid.NewV4())
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(content string) {
+			defer wg.Done()
+			errs <- generateCatalogPreviews(designID, content)
+		}(designs[i%len(designs)])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := catalogPreviewPath(designID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(core.CatalogPreviewSizes)+1 {
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("cache holds %v, want the previews and the hash of the design only", names)
+	}
+	for _, size := range core.CatalogPreviewSizes {
+		byt, err := os.ReadFile(filepath.Join(dir, size.String()+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := png.Decode(bytes.NewReader(byt)); err != nil {
+			t.Errorf("preview %s is not a valid PNG: %v", size, err)
+		}
+	}
+}
@@ -1332,7 +1332,9 @@ func (h *Handler) RegisterMeshmodels(rw http.ResponseWriter, r *http.Request, _
 		}
 		defer os.RemoveAll(tempDir)
 
+		stopProgress := h.forwardRegistryProgress(userID, provider, nil)
 		err = mesheryctlUtils.InvokeGenerationFromSheet(&wg, tempDir, 0, 0, "", "", modelCsvFile.Name(), componentCsvFile.Name(), "", relationshipCsvFile.Name(), 0, nil)
+		stopProgress()
 		if err != nil {
 			h.handleError(rw, err, "Error invoking generation from sheet")
 			h.sendErrorEvent(userID, provider, "Error invoking generation from sheet", err)
@@ -1505,6 +1507,7 @@ func (h *Handler) RegisterMeshmodels(rw http.ResponseWriter, r *http.Request, _
 	}
 
 	h.handleRegistrationAndError(registrationHelper, &mu, &response, regErrorStore)
+	go h.notifyDesignImpact(userID, provider, registrationHelper.PkgUnits)
 	var errMsg string
 	message = writeMessageString(&response)
 	if response.EntityCount.TotalErrCount > 0 {
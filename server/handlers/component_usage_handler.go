@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/layer5io/meshery/server/models"
+)
+
+// swagger:route GET /api/meshmodels/components/usage MeshmodelsAPI idGetComponentUsage
+// Handle GET request for component usage
+//
+// Returns how many stored designs use each component and how many times it was deployed, most used first.
+//
+// ```?model={model}``` If model is provided, only components of the given model are returned
+//
+// ```?kind={kind}``` If kind is provided, only components of the given kind are returned
+// responses:
+// 	200: componentUsageResponseWrapper
+
+func (h *Handler) GetComponentUsageHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	model := r.URL.Query().Get("model")
+	kind := r.URL.Query().Get("kind")
+
+	cup := &models.ComponentUsagePersister{DB: h.reportingDB(provider)}
+	usage, err := cup.GetComponentUsage(model, kind)
+	if err != nil {
+		h.log.Error(ErrFetchComponentUsage(err))
+		http.Error(rw, ErrFetchComponentUsage(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(usage); err != nil {
+		h.log.Error(models.ErrMarshal(err, "component usage"))
+		http.Error(rw, models.ErrMarshal(err, "component usage").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// swagger:route GET /api/cost-centers/tags CostCentersAPI idGetCostCenterTags
+// Handle GET request for cost center tags
+//
+// Returns the cost center tags assigned to designs and workspaces.
+//
+// ```?type={design|workspace}``` If type is provided, only tags of the given resource type are returned
+// responses:
+// 	200: costCenterTagsResponseWrapper
+
+func (h *Handler) GetCostCenterTagsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	ccp := &models.CostCenterPersister{DB: provider.GetGenericPersister()}
+	tags, err := ccp.GetTags(models.CostCenterResourceType(r.URL.Query().Get("type")))
+	if err != nil {
+		h.log.Error(ErrCostCenterTags(err))
+		http.Error(rw, ErrCostCenterTags(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(tags); err != nil {
+		h.log.Error(models.ErrMarshal(err, "cost center tags"))
+		http.Error(rw, models.ErrMarshal(err, "cost center tags").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/cost-centers/tags CostCentersAPI idSaveCostCenterTag
+// Handle POST request to assign a design or workspace to a cost center
+//
+// Replaces the cost center tag already assigned to the design or workspace.
+// responses:
+// 	200: costCenterTagResponseWrapper
+
+func (h *Handler) SaveCostCenterTagHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	var tag models.CostCenterTag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if tag.ResourceID == uuid.Nil || tag.CostCenter == "" {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("resource_id and cost_center are required")).Error(), http.StatusBadRequest)
+		return
+	}
+	if tag.ResourceType != models.CostCenterResourceDesign && tag.ResourceType != models.CostCenterResourceWorkspace {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("resource_type must be one of %s or %s", models.CostCenterResourceDesign, models.CostCenterResourceWorkspace)).Error(), http.StatusBadRequest)
+		return
+	}
+
+	ccp := &models.CostCenterPersister{DB: provider.GetGenericPersister()}
+	if err := ccp.SaveTag(&tag); err != nil {
+		h.log.Error(ErrCostCenterTags(err))
+		http.Error(rw, ErrCostCenterTags(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(tag); err != nil {
+		h.log.Error(models.ErrMarshal(err, "cost center tag"))
+		http.Error(rw, models.ErrMarshal(err, "cost center tag").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route DELETE /api/cost-centers/tags/{type}/{id} CostCentersAPI idDeleteCostCenterTag
+// Handle DELETE request for the cost center tag of a design or workspace
+//
+// responses:
+// 	200:
+
+func (h *Handler) DeleteCostCenterTagHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	vars := mux.Vars(r)
+	resourceID, err := uuid.FromString(vars["id"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	ccp := &models.CostCenterPersister{DB: provider.GetGenericPersister()}
+	if err := ccp.DeleteTag(models.CostCenterResourceType(vars["type"]), resourceID); err != nil {
+		h.log.Error(ErrCostCenterTags(err))
+		http.Error(rw, ErrCostCenterTags(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// swagger:route GET /api/cost-centers/report CostCentersAPI idGetCostReport
+// Handle GET request for the chargeback report of a month
+//
+// Correlates the resources discovered by MeshSync with the designs they were deployed from and
+// returns the CPU and memory requested by the designs of every cost center and team, in core-hours and GiB-hours.
+//
+// ```?month={YYYY-MM}``` Month of the report. Defaults to the current month
+//
+// ```?cpu_rate={rate}``` Price of a CPU core-hour. Without rates the report is a showback of usage only
+//
+// ```?memory_rate={rate}``` Price of a GiB-hour of memory
+// responses:
+// 	200: costReportResponseWrapper
+
+func (h *Handler) GetCostReportHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	q := r.URL.Query()
+
+	month := time.Now().UTC()
+	if m := q.Get("month"); m != "" {
+		parsed, err := time.Parse("2006-01", m)
+		if err != nil {
+			http.Error(rw, ErrGenerateCostReport(fmt.Errorf("month %q is not in the YYYY-MM format", m)).Error(), http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	var rates models.CostRates
+	for param, rate := range map[string]*float64{"cpu_rate": &rates.CPUCoreHour, "memory_rate": &rates.MemoryGiBHour} {
+		if v := q.Get(param); v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed < 0 {
+				http.Error(rw, ErrGenerateCostReport(fmt.Errorf("%s must be a positive number", param)).Error(), http.StatusBadRequest)
+				return
+			}
+			*rate = parsed
+		}
+	}
+
+	ccp := &models.CostCenterPersister{DB: h.reportingDB(provider)}
+	report, err := ccp.GenerateCostReport(month, rates)
+	if err != nil {
+		h.log.Error(ErrGenerateCostReport(err))
+		http.Error(rw, ErrGenerateCostReport(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(report); err != nil {
+		h.log.Error(models.ErrMarshal(err, "cost report"))
+		http.Error(rw, models.ErrMarshal(err, "cost report").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// maxDashboardWorkspaces bounds the workspaces of the user whose shared dashboards are listed.
+const maxDashboardWorkspaces = "1000"
+
+// DashboardRequest is a dashboard as composed by its owner. The dashboard is shared with the members of the
+// workspace WorkspaceID when it is set, and shown on the home screen of its owner when Home is set.
+type DashboardRequest struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	WorkspaceID *uuid.UUID                   `json:"workspace_id,omitempty"`
+	Home        bool                         `json:"home"`
+	Widgets     []models.DashboardWidget     `json:"widgets"`
+	Layout      []models.DashboardLayoutItem `json:"layout"`
+}
+
+// swagger:route GET /api/dashboards DashboardsAPI idGetDashboards
+// Handle GET request for the dashboards of the user along with the dashboards shared with their workspaces
+//
+// ?orgID={orgID} - the organization of the workspaces
+// The home dashboard of the user comes first.
+// responses:
+// 	200: dashboardsResponseWrapper
+
+func (h *Handler) GetDashboardsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	dp := &models.DashboardPersister{DB: provider.GetGenericPersister()}
+	dashboards, err := dp.GetDashboards(uuid.FromStringOrNil(user.ID), h.userWorkspaceIDs(r, provider))
+	if err != nil {
+		h.log.Error(ErrDashboard(err, ""))
+		http.Error(rw, ErrDashboard(err, "").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, dashboards, "dashboards")
+}
+
+// swagger:route POST /api/dashboards DashboardsAPI idSaveDashboard
+// Handle POST request to compose a dashboard
+//
+// The widgets are resource counts, design health, perf trends or Prometheus panels, laid out on a grid of 12
+// columns. A dashboard with a workspace_id is shared with the members of the workspace.
+// responses:
+// 	201: dashboardResponseWrapper
+
+func (h *Handler) SaveDashboardHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.saveDashboard(rw, r, provider, &models.Dashboard{UserID: uuid.FromStringOrNil(user.ID)}, http.StatusCreated)
+}
+
+// swagger:route GET /api/dashboards/{id} DashboardsAPI idGetDashboard
+// Handle GET request for a dashboard of the user or shared with one of their workspaces
+//
+// responses:
+// 	200: dashboardResponseWrapper
+
+func (h *Handler) GetDashboardHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	dashboard, ok := h.getDashboard(rw, r, user, provider, false)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, dashboard, "dashboard")
+}
+
+// swagger:route PUT /api/dashboards/{id} DashboardsAPI idUpdateDashboard
+// Handle PUT request to replace the widgets, the layout or the sharing of a dashboard
+//
+// Only the owner of a dashboard updates it.
+// responses:
+// 	200: dashboardResponseWrapper
+
+func (h *Handler) UpdateDashboardHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	dashboard, ok := h.getDashboard(rw, r, user, provider, true)
+	if !ok {
+		return
+	}
+	h.saveDashboard(rw, r, provider, dashboard, http.StatusOK)
+}
+
+// swagger:route DELETE /api/dashboards/{id} DashboardsAPI idDeleteDashboard
+// Handle DELETE request to delete a dashboard
+//
+// Only the owner of a dashboard deletes it.
+// responses:
+// 	200:
+
+func (h *Handler) DeleteDashboardHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	dashboard, ok := h.getDashboard(rw, r, user, provider, true)
+	if !ok {
+		return
+	}
+	dp := &models.DashboardPersister{DB: provider.GetGenericPersister()}
+	if err := dp.DeleteDashboard(dashboard.ID); err != nil {
+		h.log.Error(ErrDashboard(err, dashboard.Name))
+		http.Error(rw, ErrDashboard(err, dashboard.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// saveDashboard stores the dashboard of the body of the request into dashboard.
+func (h *Handler) saveDashboard(rw http.ResponseWriter, r *http.Request, provider models.Provider, dashboard *models.Dashboard, status int) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	req := DashboardRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	dashboard.Name = req.Name
+	dashboard.Description = req.Description
+	dashboard.WorkspaceID = req.WorkspaceID
+	dashboard.Home = req.Home
+	dashboard.Widgets = req.Widgets
+	dashboard.Layout = req.Layout
+	if err := dashboard.Validate(); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dashboard.WorkspaceID != nil {
+		if _, err := provider.GetWorkspaceByID(r, dashboard.WorkspaceID.String(), r.URL.Query().Get("orgID")); err != nil {
+			h.log.Error(ErrGetResult(err))
+			http.Error(rw, fmt.Sprintf("workspace %s does not exist", dashboard.WorkspaceID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	dp := &models.DashboardPersister{DB: provider.GetGenericPersister()}
+	if err := dp.SaveDashboard(dashboard); err != nil {
+		h.log.Error(ErrDashboard(err, dashboard.Name))
+		http.Error(rw, ErrDashboard(err, dashboard.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, status, dashboard, "dashboard")
+}
+
+// getDashboard returns the dashboard of the request, writing the error response if it is neither a dashboard of
+// the user nor, unless owned is set, a dashboard shared with one of their workspaces.
+func (h *Handler) getDashboard(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, owned bool) (*models.Dashboard, bool) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	dp := &models.DashboardPersister{DB: provider.GetGenericPersister()}
+	dashboard, err := dp.GetDashboard(id)
+	if err != nil {
+		h.log.Error(ErrDashboard(err, id.String()))
+		http.Error(rw, ErrDashboard(err, id.String()).Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if dashboard == nil {
+		http.Error(rw, fmt.Sprintf("dashboard %s does not exist", id), http.StatusNotFound)
+		return nil, false
+	}
+	if dashboard.UserID == uuid.FromStringOrNil(user.ID) {
+		return dashboard, true
+	}
+
+	shared := false
+	if dashboard.WorkspaceID != nil {
+		for _, workspaceID := range h.userWorkspaceIDs(r, provider) {
+			shared = shared || workspaceID == *dashboard.WorkspaceID
+		}
+	}
+	switch {
+	case !shared:
+		http.Error(rw, fmt.Sprintf("dashboard %s does not exist", id), http.StatusNotFound)
+		return nil, false
+	case owned:
+		http.Error(rw, "only the owner of a dashboard can change it", http.StatusForbidden)
+		return nil, false
+	}
+	return dashboard, true
+}
+
+// userWorkspaceIDs returns the ids of the workspaces of the user, none when the provider does not support
+// workspaces.
+func (h *Handler) userWorkspaceIDs(r *http.Request, provider models.Provider) []uuid.UUID {
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+	resp, err := provider.GetWorkspaces(token, "0", maxDashboardWorkspaces, "", "", "", r.URL.Query().Get("orgID"))
+	if err != nil {
+		h.log.Debug(err)
+		return nil
+	}
+	page := models.WorkspacePage{}
+	if err := json.Unmarshal(resp, &page); err != nil {
+		h.log.Debug(models.ErrUnmarshal(err, "workspaces"))
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(page.Workspaces))
+	for _, workspace := range page.Workspaces {
+		ids = append(ids, workspace.ID)
+	}
+	return ids
+}
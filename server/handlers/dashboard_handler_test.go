@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// dashboardTestProvider is the local provider, with the workspaces of the user.
+type dashboardTestProvider struct {
+	*models.DefaultLocalProvider
+	workspaces []uuid.UUID
+}
+
+func (p *dashboardTestProvider) GetWorkspaces(_, _, _, _, _, _, _ string) ([]byte, error) {
+	page := models.WorkspacePage{}
+	for _, id := range p.workspaces {
+		page.Workspaces = append(page.Workspaces, models.Workspace{ID: id})
+	}
+	return json.Marshal(page)
+}
+
+func (p *dashboardTestProvider) GetWorkspaceByID(_ *http.Request, workspaceID, _ string) ([]byte, error) {
+	for _, id := range p.workspaces {
+		if id.String() == workspaceID {
+			return json.Marshal(models.Workspace{ID: id})
+		}
+	}
+	return nil, fmt.Errorf("workspace %s not found", workspaceID)
+}
+
+// serveDashboard calls the handler as the user, with the dashboard id as var of the route, and returns the response.
+func serveDashboard(handler func(http.ResponseWriter, *http.Request, *models.Preference, *models.User, models.Provider), provider models.Provider, user uuid.UUID, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	rec := httptest.NewRecorder()
+	handler(rec, req, nil, &models.User{ID: user.String()}, provider)
+	return rec
+}
+
+const testDashboard = `{"name": "ops", "widgets": [{"id": "pods", "type": "resource_count", "options": {"kinds": ["Pod"]}}], "layout": [{"widget_id": "pods", "x": 0, "y": 0, "w": 6, "h": 2}]%s}`
+
+func TestDashboardHandlers(t *testing.T) {
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.Dashboard{}); err != nil {
+		t.Fatal(err)
+	}
+	workspace := uuid.Must(uuid.NewV4())
+	owner, member, stranger := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	local := &models.DefaultLocalProvider{GenericPersister: h.dbHandler}
+	// the owner and the member belong to the workspace, the stranger does not
+	providers := map[uuid.UUID]models.Provider{
+		owner:    &dashboardTestProvider{DefaultLocalProvider: local, workspaces: []uuid.UUID{workspace}},
+		member:   &dashboardTestProvider{DefaultLocalProvider: local, workspaces: []uuid.UUID{workspace}},
+		stranger: &dashboardTestProvider{DefaultLocalProvider: local},
+	}
+
+	if rec := serveDashboard(h.SaveDashboardHandler, providers[owner], owner, "", `{"name": "ops", "widgets": [{"id": "pods", "type": "resource_count"}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid dashboard to be rejected, got %d", rec.Code)
+	}
+	if rec := serveDashboard(h.SaveDashboardHandler, providers[stranger], stranger, "", fmt.Sprintf(testDashboard, `, "workspace_id": "`+workspace.String()+`"`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected sharing with a workspace the user does not belong to to be rejected, got %d", rec.Code)
+	}
+
+	rec := serveDashboard(h.SaveDashboardHandler, providers[owner], owner, "", fmt.Sprintf(testDashboard, `, "workspace_id": "`+workspace.String()+`", "home": true`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	dashboard := models.Dashboard{}
+	if err := json.NewDecoder(rec.Body).Decode(&dashboard); err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.ID == uuid.Nil || dashboard.UserID != owner || !dashboard.Home || len(dashboard.Widgets) != 1 {
+		t.Errorf("unexpected dashboard %+v", dashboard)
+	}
+	id := dashboard.ID.String()
+
+	tests := []struct {
+		name       string
+		user       uuid.UUID
+		wantGet    int
+		wantUpdate int
+	}{
+		{name: "owner", user: owner, wantGet: http.StatusOK, wantUpdate: http.StatusOK},
+		{name: "member of the workspace", user: member, wantGet: http.StatusOK, wantUpdate: http.StatusForbidden},
+		{name: "stranger", user: stranger, wantGet: http.StatusNotFound, wantUpdate: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := providers[tt.user]
+			if rec := serveDashboard(h.GetDashboardHandler, provider, tt.user, id, ""); rec.Code != tt.wantGet {
+				t.Errorf("get: status = %d, want %d", rec.Code, tt.wantGet)
+			}
+			if rec := serveDashboard(h.UpdateDashboardHandler, provider, tt.user, id, fmt.Sprintf(testDashboard, `, "workspace_id": "`+workspace.String()+`"`)); rec.Code != tt.wantUpdate {
+				t.Errorf("update: status = %d, want %d", rec.Code, tt.wantUpdate)
+			}
+			if tt.user != owner {
+				if rec := serveDashboard(h.DeleteDashboardHandler, provider, tt.user, id, ""); rec.Code != tt.wantUpdate {
+					t.Errorf("delete: status = %d, want %d", rec.Code, tt.wantUpdate)
+				}
+			}
+
+			rec := serveDashboard(h.GetDashboardsHandler, provider, tt.user, "", "")
+			dashboards := []models.Dashboard{}
+			if err := json.NewDecoder(rec.Body).Decode(&dashboards); err != nil {
+				t.Fatal(err)
+			}
+			if wantListed := tt.wantGet == http.StatusOK; (len(dashboards) == 1) != wantListed {
+				t.Errorf("expected the dashboard to be listed %t, got %+v", wantListed, dashboards)
+			}
+		})
+	}
+
+	dp := &models.DashboardPersister{DB: h.dbHandler}
+	if saved, err := dp.GetDashboard(dashboard.ID); err != nil || saved == nil || saved.Home {
+		t.Errorf("expected the update of the owner to be saved, got %+v: %v", saved, err)
+	}
+	if rec := serveDashboard(h.DeleteDashboardHandler, providers[owner], owner, id, ""); rec.Code != http.StatusOK {
+		t.Errorf("delete: status = %d", rec.Code)
+	}
+	if rec := serveDashboard(h.GetDashboardHandler, providers[owner], owner, id, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected the dashboard to be deleted, got %d", rec.Code)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/models/meshmodel/registry"
 	"github.com/layer5io/meshkit/utils"
 	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
@@ -199,3 +200,32 @@ func (h *Handler) ResetSystemDatabase(w http.ResponseWriter, r *http.Request, _
 		fmt.Fprint(w, "Database reset successful")
 	}
 }
+
+// swagger:route GET /api/system/database/reporting GetReportingDatabase idGetReportingDatabase
+// Handle GET request for the database of the reporting queries
+//
+// The reporting endpoints, component usage, cost reports and the ownership of resources along with its
+// overrides, read from a read-only replica of the database (REPORTING_DB_MODE=replica) or from a snapshot
+// taken every REPORTING_DB_SNAPSHOT_INTERVAL (REPORTING_DB_MODE=snapshot) instead of the primary database,
+// so that they do not slow down the other endpoints on busy deployments. Returns the database they read
+// from, along with the time of the latest snapshot. Reports fall back to the primary database whenever the
+// replica or the snapshot is not available.
+// responses:
+//
+//	200: reportingDatabaseResponseWrapper
+func (h *Handler) GetReportingDatabaseHandler(w http.ResponseWriter, _ *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.config.ReportingDB.Status()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "reporting database status"))
+		http.Error(w, models.ErrMarshal(err, "reporting database status").Error(), http.StatusInternalServerError)
+	}
+}
+
+// reportingDB returns the database to run the reporting queries of the provider on, the database of the
+// provider when no reporting database is configured.
+func (h *Handler) reportingDB(provider models.Provider) *database.Handler {
+	if h.config.ReportingDB == nil {
+		return provider.GetGenericPersister()
+	}
+	return h.config.ReportingDB.Handler()
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// DeploymentDiagnosisRequest is a deployed design, either a saved design or a design file, to diagnose,
+// along with the error its deployment failed with, if any.
+type DeploymentDiagnosisRequest struct {
+	DesignID    *uuid.UUID `json:"design_id,omitempty"`
+	PatternFile string     `json:"pattern_file,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// swagger:route POST /api/pattern/diagnosis PatternsAPI idDiagnosePatternDeployment
+// Handle POST request to diagnose the deployment of a design
+//
+// Gathers the warning events of the resources of a deployed design, saved (design_id) or not (pattern_file),
+// and the state and the tail of the logs of their failing containers from the selected Kubernetes clusters.
+// The evidence, along with the error the deployment failed with, is matched against common failure
+// signatures such as image pull errors, crash loops, containers killed for lack of memory, missing
+// configuration and unschedulable pods. Every finding is returned with its cause and the fixes to try.
+// Failed deployments are diagnosed when they fail, the diagnosis is attached to the deployment.
+// responses:
+// 	200: deploymentDiagnosisResponseWrapper
+
+func (h *Handler) DiagnosePatternDeploymentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := DeploymentDiagnosisRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	var deployErr error
+	if req.Error != "" {
+		deployErr = errors.New(req.Error)
+	}
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	diagnosis := h.diagnoseDesignDeployment(r.Context(), &patternFile, k8sContexts, deployErr)
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(diagnosis); err != nil {
+		h.log.Error(models.ErrMarshal(err, "deployment diagnosis"))
+		http.Error(rw, models.ErrMarshal(err, "deployment diagnosis").Error(), http.StatusInternalServerError)
+	}
+}
+
+// diagnoseDesignDeployment diagnoses the deployment of the design to the clusters, the clusters whose
+// client cannot be created are reported in the errors of the diagnosis.
+func (h *Handler) diagnoseDesignDeployment(ctx context.Context, patternFile *pattern.PatternFile, k8sContexts []models.K8sContext, deployErr error) *models.DeploymentDiagnosis {
+	clusters := []models.DiagnosisCluster{}
+	failed := []string{}
+	for i := range k8sContexts {
+		kubeClient, err := k8sContexts[i].GenerateKubeHandler()
+		if err != nil {
+			h.log.Warn(ErrDiagnoseDeployment(err, patternFile.Name))
+			failed = append(failed, k8sContexts[i].Name+": "+err.Error())
+			continue
+		}
+		clusters = append(clusters, models.DiagnosisCluster{Name: k8sContexts[i].Name, Client: kubeClient.KubeClient})
+	}
+	diagnosis := models.DiagnoseDeployment(ctx, patternFile, clusters, deployErr)
+	diagnosis.Errors = append(failed, diagnosis.Errors...)
+	return diagnosis
+}
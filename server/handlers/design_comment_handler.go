@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+const (
+	defaultDesignActivityLimit = 50
+	maxDesignActivityLimit     = 500
+)
+
+// DesignCommentRequest is the body of a comment, a reply to the comment ParentID when it is set.
+type DesignCommentRequest struct {
+	Body     string     `json:"body"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// DesignReactionRequest is a reaction to a comment, eg. an emoji or a :shortcode:.
+type DesignReactionRequest struct {
+	Reaction string `json:"reaction"`
+}
+
+// swagger:route GET /api/pattern/{id}/comments PatternsAPI idGetDesignComments
+// Handle GET request for the comments of a design
+//
+// Returns the comments and their replies oldest first, along with the count of their reactions.
+// responses:
+// 	200: designCommentsResponseWrapper
+
+func (h *Handler) GetDesignCommentsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	comments, err := dp.GetComments(*design.ID)
+	if err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, comments, "design comments")
+}
+
+// swagger:route POST /api/pattern/{id}/comments PatternsAPI idAddDesignComment
+// Handle POST request to comment on a design
+//
+// Replies to the comment parent_id when it is set. The users mentioned by the comment, eg. @jane or
+// @jane@example.com, are notified, as is the author of the comment replied to.
+// responses:
+// 	201: designCommentResponseWrapper
+
+func (h *Handler) AddDesignCommentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	req := DesignCommentRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateDesignComment(req.Body); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	var parent *models.DesignComment
+	if req.ParentID != nil {
+		var err error
+		parent, err = dp.GetComment(*design.ID, *req.ParentID)
+		if err != nil {
+			h.log.Error(ErrDesignComment(err, design.Name))
+			http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+			return
+		}
+		if parent == nil {
+			http.Error(rw, fmt.Sprintf("comment %s does not exist", req.ParentID), http.StatusNotFound)
+			return
+		}
+		// threads are one level deep, replies to replies are replies to the comment
+		if parent.ParentID != nil {
+			req.ParentID = parent.ParentID
+		}
+	}
+
+	comment := &models.DesignComment{
+		DesignID: *design.ID,
+		ParentID: req.ParentID,
+		UserID:   uuid.FromStringOrNil(user.ID),
+		UserName: designCommentUserName(user),
+		Body:     req.Body,
+		Mentions: h.resolveDesignMentions(r, provider, models.ParseDesignMentions(req.Body)),
+	}
+	if err := dp.SaveComment(comment); err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	notified := h.notifyDesignMentions(provider, design, comment, comment.Mentions)
+	if parent != nil && parent.UserID != comment.UserID && !notified[parent.UserID] {
+		h.notifyDesignCommenter(provider, design, comment, parent.UserID, "reply",
+			fmt.Sprintf("%s replied to your comment on the design %s", comment.UserName, design.Name))
+	}
+	h.writeJSONResource(rw, http.StatusCreated, comment, "design comment")
+}
+
+// swagger:route PUT /api/pattern/{id}/comments/{commentID} PatternsAPI idUpdateDesignComment
+// Handle PUT request to edit a comment on a design
+//
+// Only the author of a comment edits it. The users newly mentioned are notified.
+// responses:
+// 	200: designCommentResponseWrapper
+
+func (h *Handler) UpdateDesignCommentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	design, comment, ok := h.getDesignComment(rw, r, provider)
+	if !ok {
+		return
+	}
+	if comment.UserID != uuid.FromStringOrNil(user.ID) {
+		http.Error(rw, "only the author of a comment can edit it", http.StatusForbidden)
+		return
+	}
+	req := DesignCommentRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateDesignComment(req.Body); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	mentioned := map[string]bool{}
+	for _, mention := range comment.Mentions {
+		mentioned[strings.ToLower(mention.Handle)] = true
+	}
+	comment.Body = req.Body
+	comment.Edited = true
+	comment.Mentions = h.resolveDesignMentions(r, provider, models.ParseDesignMentions(req.Body))
+	newMentions := []models.DesignMention{}
+	for _, mention := range comment.Mentions {
+		if !mentioned[strings.ToLower(mention.Handle)] {
+			newMentions = append(newMentions, mention)
+		}
+	}
+
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	if err := dp.SaveComment(comment); err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.notifyDesignMentions(provider, design, comment, newMentions)
+	h.writeJSONResource(rw, http.StatusOK, comment, "design comment")
+}
+
+// swagger:route DELETE /api/pattern/{id}/comments/{commentID} PatternsAPI idDeleteDesignComment
+// Handle DELETE request to delete a comment on a design along with its replies
+//
+// Comments are deleted by their author or by the owner of the design.
+// responses:
+// 	200:
+
+func (h *Handler) DeleteDesignCommentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	design, comment, ok := h.getDesignComment(rw, r, provider)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	isDesignOwner := design.UserID != nil && uuid.FromStringOrNil(*design.UserID) == userID
+	if comment.UserID != userID && !isDesignOwner {
+		http.Error(rw, "only the author of a comment or the owner of the design can delete it", http.StatusForbidden)
+		return
+	}
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	if err := dp.DeleteComment(comment.ID); err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// swagger:route POST /api/pattern/{id}/comments/{commentID}/reactions PatternsAPI idAddDesignCommentReaction
+// Handle POST request to react to a comment on a design
+//
+// A user reacts once with a reaction, reacting again is a no-op.
+// responses:
+// 	200: designCommentResponseWrapper
+
+func (h *Handler) AddDesignCommentReactionHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	req := DesignReactionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	h.reactToDesignComment(rw, r, user, provider, req.Reaction, true)
+}
+
+// swagger:route DELETE /api/pattern/{id}/comments/{commentID}/reactions/{reaction} PatternsAPI idDeleteDesignCommentReaction
+// Handle DELETE request to withdraw a reaction to a comment on a design
+//
+// responses:
+// 	200: designCommentResponseWrapper
+
+func (h *Handler) DeleteDesignCommentReactionHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.reactToDesignComment(rw, r, user, provider, mux.Vars(r)["reaction"], false)
+}
+
+// swagger:route GET /api/pattern/{id}/activity PatternsAPI idGetDesignActivity
+// Handle GET request for the activity feed of a design
+//
+// Returns the comments, the revisions and the deployments of the design, most recent first.
+// ?limit={limit} - the count of entries returned, 50 by default and 500 at most
+// responses:
+// 	200: designActivityResponseWrapper
+
+func (h *Handler) GetDesignActivityHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	limit := defaultDesignActivityLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(rw, ErrQueryGet("limit").Error(), http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxDesignActivityLimit)
+	}
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	activity, err := dp.GetActivity(*design.ID, limit)
+	if err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, activity, "design activity")
+}
+
+func (h *Handler) reactToDesignComment(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, reaction string, add bool) {
+	if err := models.ValidateDesignReaction(reaction); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	design, comment, ok := h.getDesignComment(rw, r, provider)
+	if !ok {
+		return
+	}
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	userID := uuid.FromStringOrNil(user.ID)
+	var err error
+	if add {
+		err = dp.AddReaction(comment.ID, userID, reaction)
+	} else {
+		err = dp.RemoveReaction(comment.ID, userID, reaction)
+	}
+	if err == nil {
+		comment, err = dp.GetComment(*design.ID, comment.ID)
+	}
+	if err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, comment, "design comment")
+}
+
+// getCommentedDesign returns the design of the request, writing the error response if the user cannot access it.
+func (h *Handler) getCommentedDesign(rw http.ResponseWriter, r *http.Request, provider models.Provider) (*models.MesheryPattern, bool) {
+	designID, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	design, err := h.getManagedDesign(r, provider, designID)
+	if err != nil {
+		h.log.Error(ErrFetchPattern(err))
+		http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return design, true
+}
+
+// getDesignComment returns the design and the comment of the request, writing the error response if the
+// comment is not a comment of a design the user can access.
+func (h *Handler) getDesignComment(rw http.ResponseWriter, r *http.Request, provider models.Provider) (*models.MesheryPattern, *models.DesignComment, bool) {
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return nil, nil, false
+	}
+	commentID, err := uuid.FromString(mux.Vars(r)["commentID"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	comment, err := dp.GetComment(*design.ID, commentID)
+	if err != nil {
+		h.log.Error(ErrDesignComment(err, design.Name))
+		http.Error(rw, ErrDesignComment(err, design.Name).Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if comment == nil {
+		http.Error(rw, fmt.Sprintf("comment %s does not exist", commentID), http.StatusNotFound)
+		return nil, nil, false
+	}
+	return design, comment, true
+}
+
+// resolveDesignMentions resolves the handles mentioned to the users of the provider, by user name or by
+// email. Handles are left unresolved when the provider does not list its users.
+func (h *Handler) resolveDesignMentions(r *http.Request, provider models.Provider, handles []string) []models.DesignMention {
+	mentions := make([]models.DesignMention, 0, len(handles))
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+	for _, handle := range handles {
+		mention := models.DesignMention{Handle: handle}
+		resp, err := provider.GetUsers(token, "0", "10", handle, "", "")
+		if err != nil {
+			h.log.Debug(err)
+			mentions = append(mentions, mention)
+			continue
+		}
+		users := models.AllUsers{}
+		if err := json.Unmarshal(resp, &users); err != nil {
+			h.log.Debug(models.ErrUnmarshal(err, "users"))
+			mentions = append(mentions, mention)
+			continue
+		}
+		for _, u := range users.Data {
+			if u != nil && (strings.EqualFold(u.UserID, handle) || strings.EqualFold(u.Email, handle)) {
+				if id := uuid.FromStringOrNil(u.ID); id != uuid.Nil {
+					mention.UserID = &id
+				}
+				break
+			}
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// notifyDesignMentions notifies the users mentioned by the comment, but its author, and returns the users notified.
+func (h *Handler) notifyDesignMentions(provider models.Provider, design *models.MesheryPattern, comment *models.DesignComment, mentions []models.DesignMention) map[uuid.UUID]bool {
+	notified := map[uuid.UUID]bool{}
+	for _, mention := range mentions {
+		if mention.UserID == nil || *mention.UserID == comment.UserID || notified[*mention.UserID] {
+			continue
+		}
+		notified[*mention.UserID] = true
+		h.notifyDesignCommenter(provider, design, comment, *mention.UserID, "mention",
+			fmt.Sprintf("%s mentioned you on the design %s", comment.UserName, design.Name))
+	}
+	return notified
+}
+
+// notifyDesignCommenter notifies the user of the comment with an event acted upon the design.
+func (h *Handler) notifyDesignCommenter(provider models.Provider, design *models.MesheryPattern, comment *models.DesignComment, userID uuid.UUID, action, description string) {
+	event := events.NewEvent().ActedUpon(*design.ID).FromUser(userID).FromSystem(*h.SystemID).
+		WithCategory("pattern").WithAction(action).WithSeverity(events.Informational).
+		WithDescription(description).WithMetadata(map[string]interface{}{
+		"design_id":   design.ID,
+		"design_name": design.Name,
+		"comment_id":  comment.ID,
+		"comment":     comment.Body,
+		"author":      comment.UserName,
+	}).Build()
+	if err := provider.PersistEvent(event); err != nil {
+		h.log.Warn(err)
+	}
+	go h.config.EventBroadcaster.Publish(userID, event)
+}
+
+// designCommentUserName returns the name comments of the user are signed with.
+func designCommentUserName(user *models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.UserID
+}
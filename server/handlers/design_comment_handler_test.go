@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// commentTestProvider is the local provider, listing the users mentioned by comments.
+type commentTestProvider struct {
+	*models.DefaultLocalProvider
+	users []*models.User
+}
+
+func (p *commentTestProvider) GetUsers(_, _, _, _, _, _ string) ([]byte, error) {
+	return json.Marshal(models.AllUsers{Data: p.users})
+}
+
+// newDesignCommentTestHandler returns a handler and a provider serving a design owned by the given user.
+func newDesignCommentTestHandler(t *testing.T, owner uuid.UUID, users ...*models.User) (*Handler, *commentTestProvider, *models.MesheryPattern) {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.MesheryPattern{}, &models.DesignComment{}, &models.DesignCommentReaction{}); err != nil {
+		t.Fatal(err)
+	}
+	id := uuid.Must(uuid.NewV4())
+	ownerID := owner.String()
+	design := &models.MesheryPattern{ID: &id, Name: "web", PatternFile: "name: web", UserID: &ownerID}
+	if err := h.dbHandler.Create(design).Error; err != nil {
+		t.Fatal(err)
+	}
+	provider := &commentTestProvider{
+		DefaultLocalProvider: &models.DefaultLocalProvider{
+			GenericPersister:        h.dbHandler,
+			MesheryPatternPersister: &models.MesheryPatternPersister{DB: h.dbHandler},
+			EventsPersister:         &models.EventsPersister{DB: h.dbHandler},
+		},
+		users: users,
+	}
+	return h, provider, design
+}
+
+// serveDesignComment calls the handler as the user, with the vars of the route, and returns the response.
+func serveDesignComment(handler func(http.ResponseWriter, *http.Request, *models.Preference, *models.User, models.Provider), provider models.Provider, user uuid.UUID, vars map[string]string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req = mux.SetURLVars(req, vars)
+	rec := httptest.NewRecorder()
+	handler(rec, req, nil, &models.User{ID: user.String(), UserID: user.String()}, provider)
+	return rec
+}
+
+func decodeDesignComment(t *testing.T, rec *httptest.ResponseRecorder) models.DesignComment {
+	t.Helper()
+	comment := models.DesignComment{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &comment); err != nil {
+		t.Fatal(err)
+	}
+	return comment
+}
+
+// designCommentEvents returns the notifications of the comments of the design, by action.
+func designCommentEvents(t *testing.T, h *Handler, design *models.MesheryPattern) map[string][]uuid.UUID {
+	t.Helper()
+	recorded := []events.Event{}
+	if err := h.dbHandler.Where("acted_upon = ?", design.ID).Find(&recorded).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]uuid.UUID{}
+	for _, event := range recorded {
+		if event.UserID != nil {
+			got[event.Action] = append(got[event.Action], *event.UserID)
+		}
+	}
+	return got
+}
+
+func TestAddDesignCommentHandler_Replies(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	replier := uuid.Must(uuid.NewV4())
+	h, provider, design := newDesignCommentTestHandler(t, owner)
+	vars := map[string]string{"id": design.ID.String()}
+
+	rec := serveDesignComment(h.AddDesignCommentHandler, provider, owner, vars, `{"body": "Should the replicas be 3?"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	comment := decodeDesignComment(t, rec)
+
+	rec = serveDesignComment(h.AddDesignCommentHandler, provider, replier, vars, `{"body": "Yes", "parent_id": "`+comment.ID.String()+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	reply := decodeDesignComment(t, rec)
+	if reply.ParentID == nil || *reply.ParentID != comment.ID {
+		t.Errorf("parent = %v, want %s", reply.ParentID, comment.ID)
+	}
+
+	rec = serveDesignComment(h.AddDesignCommentHandler, provider, owner, vars, `{"body": "Done", "parent_id": "`+reply.ID.String()+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if replyToReply := decodeDesignComment(t, rec); replyToReply.ParentID == nil || *replyToReply.ParentID != comment.ID {
+		t.Errorf("expected the reply to the reply to be a reply to the comment, got parent %v", replyToReply.ParentID)
+	}
+
+	notified := map[uuid.UUID]bool{}
+	for _, user := range designCommentEvents(t, h, design)["reply"] {
+		notified[user] = true
+	}
+	if len(notified) != 2 || !notified[owner] || !notified[replier] {
+		t.Errorf("expected the authors of the comments replied to to be notified, got %v", notified)
+	}
+
+	for name, body := range map[string]string{
+		"missing parent": `{"body": "Yes", "parent_id": "` + uuid.Must(uuid.NewV4()).String() + `"}`,
+		"empty":          `{"body": "  "}`,
+	} {
+		rec := serveDesignComment(h.AddDesignCommentHandler, provider, owner, vars, body)
+		if want := map[string]int{"missing parent": http.StatusNotFound, "empty": http.StatusBadRequest}[name]; rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, want)
+		}
+	}
+}
+
+func TestAddDesignCommentHandler_Mentions(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	jane := &models.User{ID: uuid.Must(uuid.NewV4()).String(), UserID: "jane", Email: "jane@example.com"}
+	h, provider, design := newDesignCommentTestHandler(t, owner, jane)
+
+	rec := serveDesignComment(h.AddDesignCommentHandler, provider, owner, map[string]string{"id": design.ID.String()}, `{"body": "@jane and @jane@example.com, @bob: thoughts?"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	comment := decodeDesignComment(t, rec)
+	if len(comment.Mentions) != 3 || comment.Mentions[0].UserID == nil || comment.Mentions[1].UserID == nil || comment.Mentions[2].UserID != nil {
+		t.Fatalf("expected jane to be resolved by user name and email and bob to be unresolved, got %+v", comment.Mentions)
+	}
+	if got := designCommentEvents(t, h, design)["mention"]; len(got) != 1 || got[0].String() != jane.ID {
+		t.Errorf("expected jane to be notified once, got %v", got)
+	}
+}
+
+func TestUpdateDesignCommentHandler(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	author := uuid.Must(uuid.NewV4())
+	jane := &models.User{ID: uuid.Must(uuid.NewV4()).String(), UserID: "jane"}
+	h, provider, design := newDesignCommentTestHandler(t, owner, jane)
+	dp := &models.DesignCommentPersister{DB: h.dbHandler}
+	comment := &models.DesignComment{DesignID: *design.ID, UserID: author, Body: "LGTM"}
+	if err := dp.SaveComment(comment); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{"id": design.ID.String(), "commentID": comment.ID.String()}
+
+	for name, user := range map[string]uuid.UUID{"design owner": owner, "another user": uuid.Must(uuid.NewV4())} {
+		if rec := serveDesignComment(h.UpdateDesignCommentHandler, provider, user, vars, `{"body": "edited"}`); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusForbidden)
+		}
+	}
+
+	rec := serveDesignComment(h.UpdateDesignCommentHandler, provider, author, vars, `{"body": "LGTM, cc @jane"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := decodeDesignComment(t, rec); !got.Edited || got.Body != "LGTM, cc @jane" {
+		t.Errorf("expected the comment to be edited, got %+v", got)
+	}
+	// mentioned already, jane is not notified again
+	if rec := serveDesignComment(h.UpdateDesignCommentHandler, provider, author, vars, `{"body": "LGTM, cc @jane!"}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := designCommentEvents(t, h, design)["mention"]; len(got) != 1 {
+		t.Errorf("expected jane to be notified once, got %v", got)
+	}
+}
+
+func TestDeleteDesignCommentHandler(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	author := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name string
+		user uuid.UUID
+		want int
+	}{
+		{name: "author", user: author, want: http.StatusOK},
+		{name: "design owner", user: owner, want: http.StatusOK},
+		{name: "another user", user: uuid.Must(uuid.NewV4()), want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, provider, design := newDesignCommentTestHandler(t, owner)
+			dp := &models.DesignCommentPersister{DB: h.dbHandler}
+			comment := &models.DesignComment{DesignID: *design.ID, UserID: author, Body: "LGTM"}
+			if err := dp.SaveComment(comment); err != nil {
+				t.Fatal(err)
+			}
+			reply := &models.DesignComment{DesignID: *design.ID, ParentID: &comment.ID, UserID: owner, Body: "Thanks"}
+			if err := dp.SaveComment(reply); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := serveDesignComment(h.DeleteDesignCommentHandler, provider, tt.user, map[string]string{"id": design.ID.String(), "commentID": comment.ID.String()}, "")
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			comments, err := dp.GetComments(*design.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted := len(comments) == 0; deleted != (tt.want == http.StatusOK) {
+				t.Errorf("expected the comment and its reply to be deleted only when allowed, got %d comments", len(comments))
+			}
+		})
+	}
+}
+
+func TestDesignCommentReactionHandlers(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	h, provider, design := newDesignCommentTestHandler(t, owner)
+	dp := &models.DesignCommentPersister{DB: h.dbHandler}
+	comment := &models.DesignComment{DesignID: *design.ID, UserID: owner, Body: "LGTM"}
+	if err := dp.SaveComment(comment); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{"id": design.ID.String(), "commentID": comment.ID.String()}
+
+	react := func(user uuid.UUID) models.DesignComment {
+		t.Helper()
+		rec := serveDesignComment(h.AddDesignCommentReactionHandler, provider, user, vars, `{"reaction": ":+1:"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		return decodeDesignComment(t, rec)
+	}
+	react(owner)
+	if got := react(owner).Reactions[":+1:"]; got != 1 {
+		t.Errorf("expected reacting twice to count once, got %d", got)
+	}
+	if got := react(other).Reactions[":+1:"]; got != 2 {
+		t.Errorf("expected the reactions of both users, got %d", got)
+	}
+
+	rec := serveDesignComment(h.DeleteDesignCommentReactionHandler, provider, owner, map[string]string{"id": design.ID.String(), "commentID": comment.ID.String(), "reaction": ":+1:"}, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := decodeDesignComment(t, rec).Reactions[":+1:"]; got != 1 {
+		t.Errorf("expected the reaction of the other user to be left, got %d", got)
+	}
+
+	if rec := serveDesignComment(h.AddDesignCommentReactionHandler, provider, owner, vars, `{"reaction": "thumbs up"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
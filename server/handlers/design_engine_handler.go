@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
 	"github.com/layer5io/meshery/server/meshes"
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshery/server/models/pattern/core"
@@ -36,6 +37,8 @@ import (
 // Handle POST request for Pattern Deploy
 //
 // Deploy an attached pattern with the request
+// Responds with 409 and the conflicts when the design would modify resources owned by other designs,
+// unless override_ownership is set along with an override_justification, which is recorded.
 // responses:
 // 	200:
 
@@ -43,6 +46,8 @@ import (
 // Handle DELETE request for Pattern Deploy
 //
 // Delete a deployed pattern with the request
+// Responds with 409 and the conflicts when the design would remove resources owned by other designs,
+// unless override_ownership is set along with an override_justification, which is recorded.
 // responses:
 // 	200:
 
@@ -80,6 +85,11 @@ func (h *Handler) PatternFileHandler(
 	}
 
 	patternFileByte = []byte(payload.PatternFile)
+	if err := payload.Validate(); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
 
 	queryParams, _ := extractBoolQueryParams(r, "dryRun", "skipCRD", "verify", "upgrade")
 	isDryRun := queryParams["dryRun"]
@@ -160,15 +170,46 @@ func (h *Handler) PatternFileHandler(
 		EventBroadcaster:       h.config.EventBroadcaster,
 		Log:                    h.log,
 	}
-	response, err := _processPattern(opts)
-
 	eventBuilder := events.NewEvent().ActedUpon(patternID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("pattern").WithAction(action)
 
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	if !isDryRun {
+		if err := h.checkDesignOwnership(provider, &patternFile, k8sContexts, userID, payload.OwnershipOverrideRequest); err != nil {
+			status := http.StatusInternalServerError
+			metadata := map[string]interface{}{
+				"error": err,
+			}
+			var conflictErr *models.OwnershipConflictError
+			if errors.As(err, &conflictErr) {
+				status = http.StatusConflict
+				metadata["conflicts"] = conflictErr.Conflicts
+			}
+			event := eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Failed to %s design '%s', it conflicts with other designs.", action, patternFile.Name)).WithMetadata(metadata).Build()
+			_ = provider.PersistEvent(event)
+			go h.config.EventBroadcaster.Publish(userID, event)
+
+			h.log.Error(err)
+			if status == http.StatusConflict {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.WriteHeader(status)
+				_ = json.NewEncoder(rw).Encode(conflictErr)
+				return
+			}
+			http.Error(rw, err.Error(), status)
+			return
+		}
+	}
+
+	response, err := _processPattern(opts)
+
 	if err != nil {
 		err := ErrPatternDeploy(err, patternFile.Name)
 		metadata := map[string]interface{}{
 			"error": err,
 		}
+		if action == "deploy" {
+			metadata["diagnosis"] = h.diagnoseDesignDeployment(r.Context(), &patternFile, k8sContexts, err)
+		}
 
 		event := eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Failed to %s design '%s'.", action, patternFile.Name)).WithMetadata(metadata).Build()
 		_ = provider.PersistEvent(event)
@@ -185,6 +226,28 @@ func (h *Handler) PatternFileHandler(
 
 	serverURL, _ := r.Context().Value(models.MesheryServerURL).(string)
 
+	if action == "deploy" && !isDryRun {
+		cup := &models.ComponentUsagePersister{DB: provider.GetGenericPersister()}
+		if err := cup.RecordDeployment(&patternFile); err != nil {
+			h.log.Warn(err)
+		}
+	}
+
+	if !isDryRun {
+		hook := extensions.HookDesignDeployed
+		if isDelete {
+			hook = extensions.HookDesignUndeployed
+		}
+		h.dispatchDesignHook(hook, userID, &patternID, patternFile.Name, metadata)
+		h.recordDesignOwnership(provider, &patternFile, k8sContexts, userID, isDelete)
+
+		if isDelete {
+			h.untrackEphemeralDeployment(provider, patternID)
+		} else {
+			h.trackEphemeralDeployment(r, provider, userID, patternID, string(patternFileByte), patternFile.Name)
+		}
+	}
+
 	if action == "deploy" {
 		viewLink := fmt.Sprintf("%s/extension/meshmap?mode=visualize&design=%s", serverURL, patternID)
 		description = fmt.Sprintf("%s.", description)
@@ -229,6 +292,24 @@ func _processPattern(opts *core.ProcessPatternOptions) (map[string]interface{},
 		ctxToconfig[ctx.ID] = string(cfg)
 	}
 
+	// secret references are resolved from the clusters the design is deployed to, the design keeps them
+	patternFile := opts.Pattern
+	if core.HasSecretRefs(&patternFile) {
+		secrets := &core.KubernetesSecretResolver{}
+		for i := range k8scontexts {
+			kubeClient, err := k8scontexts[i].GenerateKubeHandler()
+			if err != nil {
+				return nil, ErrInvalidKubeHandler(err, opts.Pattern.Name)
+			}
+			secrets.Clients = append(secrets.Clients, kubeClient.KubeClient)
+		}
+		resolved, err := core.ResolvePatternSecrets(opts.Context, &patternFile, map[string]core.SecretResolver{core.SecretSourceKubernetes: secrets})
+		if err != nil {
+			return nil, err
+		}
+		patternFile = *resolved
+	}
+
 	internal := func(mk8scontext []models.K8sContext) (map[string]interface{}, error) {
 		sip := &serviceInfoProvider{
 			token:      token,
@@ -291,7 +372,7 @@ func _processPattern(opts *core.ProcessPatternOptions) (map[string]interface{},
 				sap.err = err
 			}).
 			Process(&stages.Data{
-				Pattern:                       &opts.Pattern,
+				Pattern:                       &patternFile,
 				Other:                         map[string]interface{}{},
 				DeclartionToDefinitionMapping: make(map[uuid.UUID]component.ComponentDefinition),
 			})
@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+// DesignForkRequest is the name of the fork of a design, the name of the design followed by "(fork)" when empty.
+type DesignForkRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// DesignMergeRequestRequest is the title and the description of a merge request.
+type DesignMergeRequestRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// DesignMergeRequestReviewRequest is the review of a merge request. Strategy resolves the conflicts of the
+// changes accepted, error (the default), ours, theirs or deep-merge.
+type DesignMergeRequestReviewRequest struct {
+	Strategy core.MergeStrategy `json:"strategy,omitempty"`
+	Comment  string             `json:"comment,omitempty"`
+}
+
+// DesignMergeRequestDetails is a merge request along with the changes it proposes and, while it is open,
+// the conflicts of the changes with the changes of the design since it was forked.
+type DesignMergeRequestDetails struct {
+	models.DesignMergeRequest
+	Changes   *core.PatternDiff    `json:"changes"`
+	Conflicts []core.MergeConflict `json:"conflicts"`
+}
+
+// swagger:route POST /api/pattern/{id}/fork PatternsAPI idForkDesign
+// Handle POST request to fork a design into the workspace of the user
+//
+// The design is typically a design shared with the user or published to the catalog. The fork is a private copy
+// of the design whose changes can be proposed back to the design with POST /api/pattern/{id}/merge-requests.
+// responses:
+// 	201: mesheryPatternResponseWrapper
+
+func (h *Handler) ForkDesignHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	origin, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	req := DesignForkRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	// changes are matched by the ids of the components, which only designs in the current format have
+	if _, err := core.NewPatternFile([]byte(origin.PatternFile)); err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s (fork)", origin.Name)
+	}
+
+	userID := uuid.FromStringOrNil(user.ID)
+	fork, err := h.saveDesignFile(r, provider, &models.MesheryPattern{
+		Name:        name,
+		PatternFile: origin.PatternFile,
+		Visibility:  models.Private,
+	})
+	if err != nil {
+		h.log.Error(ErrSavePattern(err))
+		http.Error(rw, ErrSavePattern(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	lineage := &models.DesignFork{
+		ForkID:     *fork.ID,
+		OriginID:   *origin.ID,
+		OriginName: origin.Name,
+		UserID:     userID,
+		Base:       origin.PatternFile,
+	}
+	if err := dp.SaveFork(lineage); err != nil {
+		h.log.Error(ErrDesignFork(err, origin.Name))
+		http.Error(rw, ErrDesignFork(err, origin.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	event := events.NewEvent().ActedUpon(*fork.ID).FromUser(userID).FromSystem(*h.SystemID).
+		WithCategory("pattern").WithAction("fork").WithSeverity(events.Informational).
+		WithDescription(fmt.Sprintf("Design %s forked from %s", fork.Name, origin.Name)).
+		WithMetadata(map[string]interface{}{
+			"origin_id":   origin.ID,
+			"origin_name": origin.Name,
+		}).Build()
+	_ = provider.PersistEvent(event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	go h.config.PatternChannel.Publish(userID, struct{}{})
+	h.dispatchDesignHook(extensions.HookDesignSaved, userID, fork.ID, fork.Name, nil)
+	h.writeJSONResource(rw, http.StatusCreated, fork, "design")
+}
+
+// swagger:route GET /api/pattern/{id}/lineage PatternsAPI idGetDesignLineage
+// Handle GET request for the lineage of a design
+//
+// Returns the designs the design was forked from, its origin first, and the designs forked from it.
+// responses:
+// 	200: designLineageResponseWrapper
+
+func (h *Handler) GetDesignLineageHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	lineage, err := dp.GetLineage(*design.ID)
+	if err != nil {
+		h.log.Error(ErrDesignFork(err, design.Name))
+		http.Error(rw, ErrDesignFork(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, lineage, "design lineage")
+}
+
+// swagger:route GET /api/pattern/{id}/merge-requests PatternsAPI idGetDesignMergeRequests
+// Handle GET request for the merge requests proposed to a design or from a fork of a design
+//
+// ?status={status} - open, accepted, rejected or closed, every merge request when empty
+// responses:
+// 	200: designMergeRequestsResponseWrapper
+
+func (h *Handler) GetDesignMergeRequestsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	design, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	status := models.DesignMergeRequestStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.DesignMergeRequestOpen, models.DesignMergeRequestAccepted, models.DesignMergeRequestRejected, models.DesignMergeRequestClosed:
+	default:
+		http.Error(rw, ErrQueryGet("status").Error(), http.StatusBadRequest)
+		return
+	}
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	mrs, err := dp.GetMergeRequests(*design.ID, status)
+	if err != nil {
+		h.log.Error(ErrDesignFork(err, design.Name))
+		http.Error(rw, ErrDesignFork(err, design.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, mrs, "design merge requests")
+}
+
+// swagger:route POST /api/pattern/{id}/merge-requests PatternsAPI idProposeDesignChanges
+// Handle POST request to propose the changes of a fork back to the design it was forked from
+//
+// Opens a merge request with the changes of the fork since it was forked, or since its last changes were
+// accepted. The owner of the design is notified, and reviews the changes with
+// POST /api/pattern/merge-requests/{mrID}/accept or /reject.
+// responses:
+// 	201: designMergeRequestResponseWrapper
+
+func (h *Handler) ProposeDesignChangesHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	fork, ok := h.getCommentedDesign(rw, r, provider)
+	if !ok {
+		return
+	}
+	req := DesignMergeRequestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("the title of the merge request is empty")).Error(), http.StatusBadRequest)
+		return
+	}
+
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	lineage, err := dp.GetFork(*fork.ID)
+	if err != nil {
+		h.log.Error(ErrDesignFork(err, fork.Name))
+		http.Error(rw, ErrDesignFork(err, fork.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	if lineage == nil {
+		http.Error(rw, fmt.Sprintf("design %s is not a fork", fork.Name), http.StatusBadRequest)
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	if lineage.UserID != userID {
+		http.Error(rw, "only the owner of a fork can propose its changes", http.StatusForbidden)
+		return
+	}
+	changes, ok := h.designChanges(rw, fork.Name, lineage.Base, fork.PatternFile)
+	if !ok {
+		return
+	}
+	if changes.IsEmpty() {
+		http.Error(rw, fmt.Sprintf("design %s has no changes to propose", fork.Name), http.StatusBadRequest)
+		return
+	}
+
+	mr := &models.DesignMergeRequest{
+		ForkID:      *fork.ID,
+		OriginID:    lineage.OriginID,
+		Title:       req.Title,
+		Description: req.Description,
+		UserID:      userID,
+		UserName:    designCommentUserName(user),
+		Status:      models.DesignMergeRequestOpen,
+		Base:        lineage.Base,
+		Proposed:    fork.PatternFile,
+	}
+	if err := dp.SaveMergeRequest(mr); err != nil {
+		h.log.Error(ErrDesignFork(err, fork.Name))
+		http.Error(rw, ErrDesignFork(err, fork.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if origin, err := h.getManagedDesign(r, provider, lineage.OriginID); err == nil && origin.UserID != nil {
+		h.notifyDesignMergeRequest(provider, mr, uuid.FromStringOrNil(*origin.UserID), "merge_request",
+			fmt.Sprintf("%s proposed changes to the design %s: %s", mr.UserName, origin.Name, mr.Title))
+	}
+	h.writeJSONResource(rw, http.StatusCreated, DesignMergeRequestDetails{DesignMergeRequest: *mr, Changes: changes}, "design merge request")
+}
+
+// swagger:route GET /api/pattern/merge-requests/{mrID} PatternsAPI idGetDesignMergeRequest
+// Handle GET request for a merge request along with the changes it proposes
+//
+// While the merge request is open, the conflicts of its changes with the changes of the design since it was
+// forked are previewed.
+// responses:
+// 	200: designMergeRequestResponseWrapper
+
+func (h *Handler) GetDesignMergeRequestHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	mr, origin, ok := h.getDesignMergeRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	changes, ok := h.designChanges(rw, mr.Title, mr.Base, mr.Proposed)
+	if !ok {
+		return
+	}
+	details := DesignMergeRequestDetails{DesignMergeRequest: *mr, Changes: changes, Conflicts: []core.MergeConflict{}}
+	if mr.Status == models.DesignMergeRequestOpen && origin != nil {
+		_, conflicts, err := h.applyDesignMergeRequest(mr, origin, core.MergeStrategyOurs)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		details.Conflicts = conflicts
+	}
+	h.writeJSONResource(rw, http.StatusOK, details, "design merge request")
+}
+
+// swagger:route POST /api/pattern/merge-requests/{mrID}/accept PatternsAPI idAcceptDesignMergeRequest
+// Handle POST request to accept the changes proposed by a merge request
+//
+// Only the owner of the design accepts its merge requests. The changes are applied to the current version of
+// the design as a new revision; the conflicts with the changes of the design since it was forked are resolved
+// by the strategy of the review, merge requests with conflicts are rejected with 409 by default.
+// responses:
+// 	200: designMergeRequestResponseWrapper
+
+func (h *Handler) AcceptDesignMergeRequestHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	req := DesignMergeRequestReviewRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Strategy == "" {
+		req.Strategy = core.MergeStrategyError
+	}
+	mr, origin, ok := h.getReviewedDesignMergeRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	if origin.UserID == nil || uuid.FromStringOrNil(*origin.UserID) != userID {
+		http.Error(rw, "only the owner of the design can accept its merge requests", http.StatusForbidden)
+		return
+	}
+
+	merged, conflicts, err := h.applyDesignMergeRequest(mr, origin, req.Strategy)
+	if err != nil {
+		h.log.Error(err)
+		status := http.StatusBadRequest
+		if len(conflicts) > 0 {
+			status = http.StatusConflict
+		}
+		http.Error(rw, err.Error(), status)
+		return
+	}
+	merged.Version = models.NextPatchVersion(merged.Version)
+	byt, err := yaml.Marshal(merged)
+	if err != nil {
+		h.log.Error(models.ErrMarshalYAML(err, "design"))
+		http.Error(rw, models.ErrMarshalYAML(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	revision, err := h.saveDesignFile(r, provider, &models.MesheryPattern{
+		ID:          origin.ID,
+		Name:        origin.Name,
+		PatternFile: string(byt),
+		Visibility:  origin.Visibility,
+		CatalogData: origin.CatalogData,
+	})
+	if err != nil {
+		h.log.Error(ErrSavePattern(err))
+		http.Error(rw, ErrSavePattern(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	mr.Status = models.DesignMergeRequestAccepted
+	mr.ReviewerID = &userID
+	mr.ReviewComment = req.Comment
+	mr.ReviewedAt = &now
+	mr.MergedVersion = merged.Version
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	if err := dp.SaveMergeRequest(mr); err != nil {
+		h.log.Error(ErrDesignFork(err, origin.Name))
+		http.Error(rw, ErrDesignFork(err, origin.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	// the next changes of the fork are proposed from the changes accepted
+	if lineage, err := dp.GetFork(mr.ForkID); err == nil && lineage != nil {
+		lineage.Base = mr.Proposed
+		if err := dp.SaveFork(lineage); err != nil {
+			h.log.Warn(ErrDesignFork(err, origin.Name))
+		}
+	}
+
+	event := events.NewEvent().ActedUpon(*origin.ID).FromUser(userID).FromSystem(*h.SystemID).
+		WithCategory("pattern").WithAction("update").WithSeverity(events.Informational).
+		WithDescription(fmt.Sprintf("Changes of %s accepted into the design %s", mr.Title, origin.Name)).
+		WithMetadata(map[string]interface{}{
+			"merge_request_id": mr.ID,
+			"fork_id":          mr.ForkID,
+			"version":          merged.Version,
+			"conflicts":        len(conflicts),
+		}).Build()
+	_ = provider.PersistEvent(event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	go h.config.PatternChannel.Publish(userID, struct{}{})
+	h.dispatchDesignHook(extensions.HookDesignSaved, userID, revision.ID, revision.Name, nil)
+	h.notifyDesignMergeRequest(provider, mr, mr.UserID, "accept",
+		fmt.Sprintf("Your changes %s to the design %s were accepted", mr.Title, origin.Name))
+
+	changes, ok := h.designChanges(rw, mr.Title, mr.Base, mr.Proposed)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, DesignMergeRequestDetails{DesignMergeRequest: *mr, Changes: changes, Conflicts: conflicts}, "design merge request")
+}
+
+// swagger:route POST /api/pattern/merge-requests/{mrID}/reject PatternsAPI idRejectDesignMergeRequest
+// Handle POST request to reject the changes proposed by a merge request
+//
+// The owner of the design rejects the merge request, its author closes it.
+// responses:
+// 	200: designMergeRequestResponseWrapper
+
+func (h *Handler) RejectDesignMergeRequestHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	req := DesignMergeRequestReviewRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	mr, origin, ok := h.getReviewedDesignMergeRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	switch {
+	case origin.UserID != nil && uuid.FromStringOrNil(*origin.UserID) == userID:
+		mr.Status = models.DesignMergeRequestRejected
+	case mr.UserID == userID:
+		mr.Status = models.DesignMergeRequestClosed
+	default:
+		http.Error(rw, "only the owner of the design or the author of a merge request can reject it", http.StatusForbidden)
+		return
+	}
+
+	now := time.Now()
+	mr.ReviewerID = &userID
+	mr.ReviewComment = req.Comment
+	mr.ReviewedAt = &now
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	if err := dp.SaveMergeRequest(mr); err != nil {
+		h.log.Error(ErrDesignFork(err, origin.Name))
+		http.Error(rw, ErrDesignFork(err, origin.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	if mr.Status == models.DesignMergeRequestRejected {
+		h.notifyDesignMergeRequest(provider, mr, mr.UserID, "reject",
+			fmt.Sprintf("Your changes %s to the design %s were rejected", mr.Title, origin.Name))
+	}
+	h.writeJSONResource(rw, http.StatusOK, mr, "design merge request")
+}
+
+// getDesignMergeRequest returns the merge request of the request along with the design it proposes changes to,
+// nil if the design was deleted, writing the error response if the user can neither access the design nor is
+// the author of the merge request.
+func (h *Handler) getDesignMergeRequest(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.DesignMergeRequest, *models.MesheryPattern, bool) {
+	id, err := uuid.FromString(mux.Vars(r)["mrID"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	dp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	mr, err := dp.GetMergeRequest(id)
+	if err != nil {
+		h.log.Error(ErrDesignFork(err, id.String()))
+		http.Error(rw, ErrDesignFork(err, id.String()).Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if mr == nil {
+		http.Error(rw, fmt.Sprintf("merge request %s does not exist", id), http.StatusNotFound)
+		return nil, nil, false
+	}
+	origin, err := h.getManagedDesign(r, provider, mr.OriginID)
+	if err != nil {
+		if mr.UserID != uuid.FromStringOrNil(user.ID) {
+			http.Error(rw, fmt.Sprintf("merge request %s does not exist", id), http.StatusNotFound)
+			return nil, nil, false
+		}
+		origin = nil
+	}
+	return mr, origin, true
+}
+
+// getReviewedDesignMergeRequest returns the open merge request of the request along with the design it
+// proposes changes to, writing the error response if it cannot be reviewed.
+func (h *Handler) getReviewedDesignMergeRequest(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.DesignMergeRequest, *models.MesheryPattern, bool) {
+	mr, origin, ok := h.getDesignMergeRequest(rw, r, user, provider)
+	if !ok {
+		return nil, nil, false
+	}
+	if mr.Status != models.DesignMergeRequestOpen {
+		http.Error(rw, fmt.Sprintf("merge request %s is %s", mr.ID, mr.Status), http.StatusConflict)
+		return nil, nil, false
+	}
+	if origin == nil {
+		http.Error(rw, fmt.Sprintf("the design of merge request %s does not exist", mr.ID), http.StatusNotFound)
+		return nil, nil, false
+	}
+	return mr, origin, true
+}
+
+// applyDesignMergeRequest applies the changes of the merge request to the current version of the design.
+func (h *Handler) applyDesignMergeRequest(mr *models.DesignMergeRequest, origin *models.MesheryPattern, strategy core.MergeStrategy) (*pattern.PatternFile, []core.MergeConflict, error) {
+	files := make([]pattern.PatternFile, 3)
+	for i, content := range []string{mr.Base, origin.PatternFile, mr.Proposed} {
+		patternFile, err := core.NewPatternFile([]byte(content))
+		if err != nil {
+			return nil, nil, ErrPatternFile(err)
+		}
+		files[i] = patternFile
+	}
+	return core.ApplyDesignChanges(&files[0], &files[1], &files[2], strategy)
+}
+
+// designChanges returns the changes between both versions of the design, writing the error response if
+// either cannot be parsed.
+func (h *Handler) designChanges(rw http.ResponseWriter, name, before, after string) (*core.PatternDiff, bool) {
+	beforeFile, err := core.NewPatternFile([]byte(before))
+	if err == nil {
+		var afterFile pattern.PatternFile
+		afterFile, err = core.NewPatternFile([]byte(after))
+		if err == nil {
+			return core.DiffPatternFiles(&beforeFile, &afterFile), true
+		}
+	}
+	h.log.Error(ErrDesignFork(err, name))
+	http.Error(rw, ErrDesignFork(err, name).Error(), http.StatusInternalServerError)
+	return nil, false
+}
+
+// saveDesignFile saves the design, as a new design unless its id is set, and returns the design saved.
+func (h *Handler) saveDesignFile(r *http.Request, provider models.Provider, design *models.MesheryPattern) (*models.MesheryPattern, error) {
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+	resp, err := provider.SaveMesheryPattern(token, design)
+	if err != nil {
+		return nil, err
+	}
+	saved := []models.MesheryPattern{}
+	if err := json.Unmarshal(resp, &saved); err != nil {
+		return nil, models.ErrUnmarshal(err, "design")
+	}
+	if len(saved) == 0 || saved[0].ID == nil {
+		return nil, fmt.Errorf("the provider did not return the saved design")
+	}
+	return &saved[0], nil
+}
+
+// notifyDesignMergeRequest notifies the user of the merge request with an event acted upon the design it
+// proposes changes to.
+func (h *Handler) notifyDesignMergeRequest(provider models.Provider, mr *models.DesignMergeRequest, userID uuid.UUID, action, description string) {
+	if userID == uuid.Nil {
+		return
+	}
+	event := events.NewEvent().ActedUpon(mr.OriginID).FromUser(userID).FromSystem(*h.SystemID).
+		WithCategory("pattern").WithAction(action).WithSeverity(events.Informational).
+		WithDescription(description).WithMetadata(map[string]interface{}{
+		"merge_request_id": mr.ID,
+		"title":            mr.Title,
+		"fork_id":          mr.ForkID,
+		"author":           mr.UserName,
+		"status":           mr.Status,
+	}).Build()
+	if err := provider.PersistEvent(event); err != nil {
+		h.log.Warn(err)
+	}
+	go h.config.EventBroadcaster.Publish(userID, event)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/registration"
+	"github.com/meshery/schemas/models/v1beta1/component"
+)
+
+// swagger:route GET /api/meshmodels/components/{name}/designs MeshmodelsAPI idGetDesignsUsingComponent
+// Handle GET request for the designs using a component
+//
+// Returns the designs of the user, and the published designs, which use components of the given kind, with the
+// matching components of each design.
+//
+// ```?model={model}``` If model is provided, only components of the given model are matched
+//
+// ```?version={version}``` If version is provided, only components of the given version, eg. apps/v1, are matched
+// responses:
+// 	200: designImpactResponseWrapper
+
+func (h *Handler) GetDesignsUsingComponentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	kind := mux.Vars(r)["name"]
+	model := r.URL.Query().Get("model")
+	version := r.URL.Query().Get("version")
+
+	mpp := &models.MesheryPatternPersister{DB: provider.GetGenericPersister()}
+	impacts, err := mpp.GetDesignsUsingComponent(uuid.FromStringOrNil(user.ID), kind, model, version)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(impacts); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design impact"))
+		http.Error(rw, models.ErrMarshal(err, "design impact").Error(), http.StatusInternalServerError)
+	}
+}
+
+// notifyDesignImpact emits an event to the owner of every stored design, whatever its visibility,
+// which uses a component whose schema changed in a breaking way in the
+// packaging units just registered. Designs without an owner are reported to the
+// user who updated the registry.
+func (h *Handler) notifyDesignImpact(userID uuid.UUID, provider models.Provider, pkgUnits []registration.PackagingUnit) {
+	var updated []component.ComponentDefinition
+	for _, pkgUnit := range pkgUnits {
+		updated = append(updated, pkgUnit.Components...)
+	}
+
+	mpp := &models.MesheryPatternPersister{DB: provider.GetGenericPersister()}
+	impacts, err := mpp.GetRegistryChangeImpact(updated)
+	if err != nil {
+		h.log.Error(err)
+		return
+	}
+
+	for _, impact := range impacts {
+		ownerID := userID
+		if impact.UserID != nil {
+			if id, err := uuid.FromString(*impact.UserID); err == nil {
+				ownerID = id
+			}
+		}
+
+		var fields []string
+		for _, comp := range impact.Components {
+			for _, change := range comp.BreakingFields {
+				fields = append(fields, fmt.Sprintf("%s: %s (%s)", comp.DisplayName, change.Field, change.Change))
+			}
+		}
+
+		event := events.NewEvent().ActedUpon(impact.DesignID).FromUser(ownerID).FromSystem(*h.SystemID).WithCategory("pattern").WithAction("registry_change").WithSeverity(events.Warning).WithDescription(fmt.Sprintf("A registry update introduced breaking changes to components used by design %s", impact.DesignName)).WithMetadata(map[string]interface{}{
+			"design_id":       impact.DesignID,
+			"components":      impact.Components,
+			"breaking_fields": strings.Join(fields, "\n"),
+		}).Build()
+		_ = provider.PersistEvent(event)
+		go h.config.EventBroadcaster.Publish(ownerID, event)
+	}
+}
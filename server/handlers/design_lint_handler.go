@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/spf13/viper"
+)
+
+// PatternLintDisabledRulesENV lists the lint rules disabled on every design linted by Meshery Server.
+const PatternLintDisabledRulesENV = "PATTERN_LINT_DISABLED_RULES"
+
+// DesignLintRequest is a design, either a saved design or a design file, to lint with the rules
+// selected by the config.
+type DesignLintRequest struct {
+	DesignID    *uuid.UUID      `json:"design_id,omitempty"`
+	PatternFile string          `json:"pattern_file,omitempty"`
+	Config      core.LintConfig `json:"config,omitempty"`
+}
+
+// LintRuleInfo describes a lint rule and whether it was run.
+type LintRuleInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Severity    core.LintSeverity `json:"severity"`
+	Enabled     bool              `json:"enabled"`
+}
+
+// DesignLint is the findings of the lint rules on a design.
+type DesignLint struct {
+	Design   string             `json:"design"`
+	Findings []core.LintFinding `json:"findings"`
+	Rules    []LintRuleInfo     `json:"rules"`
+}
+
+// swagger:route POST /api/pattern/lint PatternsAPI idLintPattern
+// Handle POST request to lint a design
+//
+// Runs the lint rules on a design, saved (design_id) or not (pattern_file): missing namespaces, component
+// types unknown to the registry, dependencies on components which are not in the design, duplicated ports
+// and missing resource limits. The config enables, disables and sets the severity of the rules, the rules
+// listed in PATTERN_LINT_DISABLED_RULES are disabled on every design. Findings are returned with their
+// severity, the component and the field causing them, the most severe first.
+// responses:
+// 	200: designLintResponseWrapper
+
+func (h *Handler) LintPatternHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := DesignLintRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := req.Config
+	config.Disabled = append(config.Disabled, viper.GetStringSlice(PatternLintDisabledRulesENV)...)
+	findings, err := core.LintPatternFile(&patternFile, &core.RegistryResolver{Registry: h.registryManager}, config)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lint := DesignLint{Design: patternFile.Name, Findings: findings, Rules: []LintRuleInfo{}}
+	for _, rule := range core.LintRules() {
+		info := LintRuleInfo{Name: rule.Name(), Description: rule.Description(), Severity: rule.Severity()}
+		if severity, ok := config.Severities[rule.Name()]; ok {
+			info.Severity = severity
+		}
+		info.Enabled = (len(config.Enabled) == 0 || slices.Contains(config.Enabled, rule.Name())) && !slices.Contains(config.Disabled, rule.Name())
+		lint.Rules = append(lint.Rules, info)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(lint); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design lint findings"))
+		http.Error(rw, models.ErrMarshal(err, "design lint findings").Error(), http.StatusInternalServerError)
+	}
+}
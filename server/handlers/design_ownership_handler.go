@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// swagger:route GET /api/pattern/ownership PatternsAPI idGetPatternOwnership
+// Handle GET request for the resources owned by designs
+//
+// A design owns the resources it deployed last, and a Namespace it deployed along with every resource
+// deployed to it. Deploying, or undeploying, a design which would modify resources owned by another
+// design fails with 409 unless the ownership is overridden with a justification.
+// The ownership can be filtered by design (design_id) and cluster (cluster_id).
+// responses:
+// 	200: resourceOwnershipResponseWrapper
+
+func (h *Handler) GetPatternOwnershipHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	designID := uuid.FromStringOrNil(r.URL.Query().Get("design_id"))
+	dop := &models.DesignOwnershipPersister{DB: h.reportingDB(provider)}
+	ownerships, err := dop.List(designID, r.URL.Query().Get("cluster_id"))
+	if err != nil {
+		h.log.Error(ErrDesignOwnership(err, designID.String()))
+		http.Error(rw, ErrDesignOwnership(err, designID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(ownerships); err != nil {
+		h.log.Error(models.ErrMarshal(err, "resource ownership"))
+		http.Error(rw, models.ErrMarshal(err, "resource ownership").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route GET /api/pattern/ownership/overrides PatternsAPI idGetPatternOwnershipOverrides
+// Handle GET request for the overrides of the ownership of resources
+//
+// Returns the resources modified, or removed, by designs which did not own them, along with the
+// justification given, the latest first. The overrides can be filtered by design (design_id),
+// which returns the overrides made by the design and those of its resources.
+// responses:
+// 	200: ownershipOverridesResponseWrapper
+
+func (h *Handler) GetPatternOwnershipOverridesHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	designID := uuid.FromStringOrNil(r.URL.Query().Get("design_id"))
+	dop := &models.DesignOwnershipPersister{DB: h.reportingDB(provider)}
+	overrides, err := dop.ListOverrides(designID)
+	if err != nil {
+		h.log.Error(ErrDesignOwnership(err, designID.String()))
+		http.Error(rw, ErrDesignOwnership(err, designID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(overrides); err != nil {
+		h.log.Error(models.ErrMarshal(err, "ownership overrides"))
+		http.Error(rw, models.ErrMarshal(err, "ownership overrides").Error(), http.StatusInternalServerError)
+	}
+}
+
+// ownershipClusterIDs identifies the clusters of the contexts by their Kubernetes server id when known,
+// so that the contexts of a cluster share the ownership of its resources.
+func ownershipClusterIDs(k8sContexts []models.K8sContext) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, k8sContext := range k8sContexts {
+		id := k8sContext.ID
+		if k8sContext.KubernetesServerID != nil && *k8sContext.KubernetesServerID != uuid.Nil {
+			id = k8sContext.KubernetesServerID.String()
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkDesignOwnership returns an *models.OwnershipConflictError when deploying, or undeploying, the design
+// to the clusters would modify resources owned by other designs. When the ownership is overridden, the
+// conflicts are recorded as overrides along with their justification instead.
+func (h *Handler) checkDesignOwnership(provider models.Provider, patternFile *pattern.PatternFile, k8sContexts []models.K8sContext, userID uuid.UUID, override models.OwnershipOverrideRequest) error {
+	if err := override.Validate(); err != nil {
+		return err
+	}
+	dop := &models.DesignOwnershipPersister{DB: provider.GetGenericPersister()}
+	conflicts, err := dop.Conflicts(patternFile.Id, ownershipClusterIDs(k8sContexts), models.DesignOwnedResources(patternFile))
+	if err != nil {
+		return ErrDesignOwnership(err, patternFile.Name)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if !override.OverrideOwnership {
+		return &models.OwnershipConflictError{Design: patternFile.Name, Conflicts: conflicts}
+	}
+
+	overrides := make([]models.OwnershipOverride, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		overrides = append(overrides, models.OwnershipOverride{
+			DesignID:           patternFile.Id,
+			DesignName:         patternFile.Name,
+			ClusterID:          conflict.ClusterID,
+			Kind:               conflict.Resource.Kind,
+			Namespace:          conflict.Resource.Namespace,
+			Name:               conflict.Resource.Name,
+			PreviousDesignID:   conflict.Owner.DesignID,
+			PreviousDesignName: conflict.Owner.DesignName,
+			Justification:      override.OverrideJustification,
+			UserID:             userID,
+		})
+	}
+	if err := dop.RecordOverrides(overrides); err != nil {
+		return ErrDesignOwnership(err, patternFile.Name)
+	}
+	h.log.Infof("design %s overrode the ownership of %d resources: %s", patternFile.Name, len(overrides), override.OverrideJustification)
+	return nil
+}
+
+// recordDesignOwnership records the design as the owner of its resources once deployed to the clusters,
+// and forgets its ownership once undeployed.
+func (h *Handler) recordDesignOwnership(provider models.Provider, patternFile *pattern.PatternFile, k8sContexts []models.K8sContext, userID uuid.UUID, isDelete bool) {
+	dop := &models.DesignOwnershipPersister{DB: provider.GetGenericPersister()}
+	var err error
+	if isDelete {
+		err = dop.Release(patternFile.Id, ownershipClusterIDs(k8sContexts))
+	} else {
+		err = dop.Claim(patternFile.Id, patternFile.Name, userID, ownershipClusterIDs(k8sContexts), models.DesignOwnedResources(patternFile))
+	}
+	if err != nil {
+		h.log.Warn(ErrDesignOwnership(err, patternFile.Name))
+	}
+}
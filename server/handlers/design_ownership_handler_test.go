@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+)
+
+const ownershipTestDesign = `
+id: 2a6e4c1d-5b3f-4e8a-9c7d-1f0e2b3a4c55
+schemaVersion: designs.meshery.io/v1beta1
+name: web
+components:
+  - id: 7b9d2c44-1e3a-4f0b-8c6d-5a4e3b2f1d22
+    displayName: web
+    component:
+      kind: Deployment
+      version: apps/v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        name: web
+        namespace: shop
+`
+
+// newOwnershipTestHandler returns a handler and a provider where the shop namespace of the cluster c1
+// is owned by another design.
+func newOwnershipTestHandler(t *testing.T) (*Handler, *models.DefaultLocalProvider, uuid.UUID) {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.ResourceOwnership{}, &models.OwnershipOverride{}); err != nil {
+		t.Fatal(err)
+	}
+	provider := &models.DefaultLocalProvider{GenericPersister: h.dbHandler, EventsPersister: &models.EventsPersister{DB: h.dbHandler}}
+	other := uuid.Must(uuid.NewV4())
+	dop := &models.DesignOwnershipPersister{DB: h.dbHandler}
+	if err := dop.Claim(other, "other", uuid.Must(uuid.NewV4()), []string{"c1"}, []models.OwnedResource{{Kind: "Namespace", Name: "shop"}}); err != nil {
+		t.Fatal(err)
+	}
+	return h, provider, other
+}
+
+func TestPatternFileHandler_OwnershipConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		override   models.OwnershipOverrideRequest
+		wantStatus int
+	}{
+		{name: "conflict", wantStatus: http.StatusConflict},
+		{name: "unjustified override", override: models.OwnershipOverrideRequest{OverrideOwnership: true}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, provider, other := newOwnershipTestHandler(t)
+			body, err := json.Marshal(models.MesheryPatternFileDeployPayload{PatternFile: ownershipTestDesign, OwnershipOverrideRequest: tt.override})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/pattern/deploy", bytes.NewReader(body))
+			req = req.WithContext(context.WithValue(req.Context(), models.KubeClustersKey, []models.K8sContext{{ID: "c1", Name: "kind"}}))
+			rw := httptest.NewRecorder()
+
+			h.PatternFileHandler(rw, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+
+			if rw.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rw.Code, tt.wantStatus, rw.Body.String())
+			}
+			if tt.wantStatus == http.StatusConflict {
+				conflictErr := models.OwnershipConflictError{}
+				if err := json.NewDecoder(rw.Body).Decode(&conflictErr); err != nil {
+					t.Fatal(err)
+				}
+				if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Reason != models.OwnershipConflictNamespace || conflictErr.Conflicts[0].Owner.DesignID != other {
+					t.Errorf("expected the namespace of web to conflict with the other design, got %+v", conflictErr)
+				}
+			}
+
+			dop := &models.DesignOwnershipPersister{DB: h.dbHandler}
+			if owned, err := dop.List(uuid.Nil, "c1"); err != nil || len(owned) != 1 || owned[0].DesignID != other {
+				t.Errorf("expected the ownership to be left alone, got %+v: %v", owned, err)
+			}
+			if overrides, err := dop.ListOverrides(uuid.Nil); err != nil || len(overrides) != 0 {
+				t.Errorf("expected no override to be recorded, got %+v: %v", overrides, err)
+			}
+		})
+	}
+}
+
+func TestCheckDesignOwnership_Override(t *testing.T) {
+	h, provider, other := newOwnershipTestHandler(t)
+	patternFile, err := core.NewPatternFile([]byte(ownershipTestDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverID := uuid.Must(uuid.NewV4())
+	// the contexts of c1 share the ownership of its resources
+	k8sContexts := []models.K8sContext{{ID: "c1"}, {ID: "c1-admin", KubernetesServerID: &serverID}}
+	if got := ownershipClusterIDs(k8sContexts); len(got) != 2 || got[1] != serverID.String() {
+		t.Fatalf("expected the clusters to be identified by their server id, got %v", got)
+	}
+
+	user := uuid.Must(uuid.NewV4())
+	override := models.OwnershipOverrideRequest{OverrideOwnership: true, OverrideJustification: "shop moves to web"}
+	if err := h.checkDesignOwnership(provider, &patternFile, k8sContexts, user, override); err != nil {
+		t.Fatal(err)
+	}
+
+	dop := &models.DesignOwnershipPersister{DB: h.dbHandler}
+	overrides, err := dop.ListOverrides(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].DesignID != patternFile.Id || overrides[0].Name != "web" ||
+		overrides[0].Justification != override.OverrideJustification || overrides[0].UserID != user {
+		t.Errorf("expected the override to be recorded with its justification, got %+v", overrides)
+	}
+
+	// once deployed, the design owns web while the other design keeps owning its namespace
+	h.recordDesignOwnership(provider, &patternFile, k8sContexts, user, false)
+	if err := h.checkDesignOwnership(provider, &patternFile, k8sContexts, user, models.OwnershipOverrideRequest{}); err == nil || !strings.Contains(err.Error(), "Deployment shop/web is owned by design other") {
+		t.Errorf("expected the namespace of the other design to still conflict, got %v", err)
+	}
+	if owned, err := dop.List(patternFile.Id, ""); err != nil || len(owned) != 2 {
+		t.Errorf("expected the design to own web in both clusters, got %+v: %v", owned, err)
+	}
+}
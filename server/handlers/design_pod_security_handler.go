@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DesignPodSecurityRequest is a design, either a saved design or a design file, to check against the
+// Pod Security Standards enforced on the namespaces of the clusters.
+type DesignPodSecurityRequest struct {
+	DesignID    *uuid.UUID `json:"design_id,omitempty"`
+	PatternFile string     `json:"pattern_file,omitempty"`
+	// DefaultLevel is the level enforced on the namespaces which are not labelled, privileged if empty.
+	DefaultLevel core.PodSecurityLevel `json:"default_level,omitempty"`
+	// Remediate previews the design with the remediable violations fixed.
+	Remediate bool `json:"remediate,omitempty"`
+}
+
+// PodSecurityRemediation is the design with the remediable violations fixed, the changes made to it
+// and the violations left.
+type PodSecurityRemediation struct {
+	PatternFile string                      `json:"pattern_file"`
+	Diff        *core.PatternDiff           `json:"diff"`
+	Violations  []core.PodSecurityViolation `json:"violations"`
+}
+
+// ClusterPodSecurity is whether the workloads of a design comply with the levels enforced on the
+// namespaces of a cluster.
+type ClusterPodSecurity struct {
+	ContextID   string                      `json:"context_id"`
+	Name        string                      `json:"name"`
+	Namespaces  core.PodSecurityNamespaces  `json:"namespaces"`
+	Violations  []core.PodSecurityViolation `json:"violations"`
+	Remediation *PodSecurityRemediation     `json:"remediation,omitempty"`
+	Error       string                      `json:"error,omitempty"`
+}
+
+// DesignPodSecurity is whether the workloads of a design comply with the Pod Security Standards on
+// the selected clusters.
+type DesignPodSecurity struct {
+	Design   string               `json:"design"`
+	Clusters []ClusterPodSecurity `json:"clusters"`
+}
+
+// swagger:route POST /api/pattern/pod-security PatternsAPI idCheckPatternPodSecurity
+// Handle POST request to check the workloads of a design against the Pod Security Standards
+//
+// Evaluates the workloads of a design, saved (design_id) or not (pattern_file), against the Pod Security
+// admission level (privileged, baseline or restricted) enforced on their namespaces in the selected
+// Kubernetes clusters, read from the pod-security.kubernetes.io/enforce label of the namespaces and of the
+// namespaces declared by the design. Every violation names the field of the component causing it.
+// With remediate, the design with the remediable violations fixed (capabilities dropped, privilege
+// escalation disallowed, runAsNonRoot and the RuntimeDefault seccomp profile set) is previewed along with
+// the changes made to it. Nothing is saved nor deployed.
+// responses:
+// 	200: designPodSecurityResponseWrapper
+
+func (h *Handler) CheckPatternPodSecurityHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := DesignPodSecurityRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	defaultLevel := core.PodSecurityPrivileged
+	if req.DefaultLevel != "" {
+		defaultLevel = core.ParsePodSecurityLevel(string(req.DefaultLevel))
+		if defaultLevel != req.DefaultLevel {
+			err := fmt.Errorf("invalid default level %q, expected privileged, baseline or restricted", req.DefaultLevel)
+			h.log.Error(ErrRequestBody(err))
+			http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	podSecurity := DesignPodSecurity{Design: patternFile.Name, Clusters: []ClusterPodSecurity{}}
+
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	for i := range k8sContexts {
+		k8sContext := &k8sContexts[i]
+		cluster := ClusterPodSecurity{ContextID: k8sContext.ID, Name: k8sContext.Name}
+
+		kubeClient, err := k8sContext.GenerateKubeHandler()
+		if err != nil {
+			h.log.Warn(ErrCheckDesignPodSecurity(err, patternFile.Name))
+			cluster.Error = err.Error()
+			podSecurity.Clusters = append(podSecurity.Clusters, cluster)
+			continue
+		}
+		namespaces, err := kubeClient.KubeClient.CoreV1().Namespaces().List(r.Context(), metav1.ListOptions{})
+		if err != nil {
+			h.log.Warn(ErrCheckDesignPodSecurity(err, patternFile.Name))
+			cluster.Error = err.Error()
+			podSecurity.Clusters = append(podSecurity.Clusters, cluster)
+			continue
+		}
+		cluster.Namespaces = core.NewPodSecurityNamespaces(namespaces.Items, defaultLevel)
+
+		cluster.Violations, err = core.CheckPodSecurity(&patternFile, cluster.Namespaces)
+		if err != nil {
+			h.log.Error(ErrCheckDesignPodSecurity(err, patternFile.Name))
+			http.Error(rw, ErrCheckDesignPodSecurity(err, patternFile.Name).Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Remediate {
+			remediated, diff, violations, err := core.RemediatePodSecurity(&patternFile, cluster.Namespaces)
+			if err != nil {
+				h.log.Error(ErrCheckDesignPodSecurity(err, patternFile.Name))
+				http.Error(rw, ErrCheckDesignPodSecurity(err, patternFile.Name).Error(), http.StatusBadRequest)
+				return
+			}
+			byt, err := yaml.Marshal(remediated)
+			if err != nil {
+				h.log.Error(models.ErrMarshalYAML(err, "design"))
+				http.Error(rw, models.ErrMarshalYAML(err, "design").Error(), http.StatusInternalServerError)
+				return
+			}
+			cluster.Remediation = &PodSecurityRemediation{PatternFile: string(byt), Diff: diff, Violations: violations}
+		}
+		podSecurity.Clusters = append(podSecurity.Clusters, cluster)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(podSecurity); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design pod security"))
+		http.Error(rw, models.ErrMarshal(err, "design pod security").Error(), http.StatusInternalServerError)
+	}
+}
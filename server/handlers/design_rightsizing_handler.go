@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/encoding"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DesignRightSizingRevision is a design saved with the recommended resources applied.
+type DesignRightSizingRevision struct {
+	Design     *models.MesheryPattern        `json:"design"`
+	Version    string                        `json:"version"`
+	Components []models.ComponentRightSizing `json:"components"`
+	Diff       *core.PatternDiff             `json:"diff"`
+}
+
+// swagger:route POST /api/pattern/rightsizing PatternsAPI idRightSizePattern
+// Handle POST request to recommend the resources of the workloads of a design
+//
+// Computes the recommended requests and limits of the containers of the deployed workloads of a design,
+// saved (design_id) or not (pattern_file), from their usage: the history over the window (7 days by default)
+// queried from the given Prometheus connection, or the current usage read from the metrics-server of the
+// selected Kubernetes clusters. Requests cover a percentile of the usage and limits its peak, with a margin.
+// The requests of every workload before and after the recommendations are reported, nothing is changed.
+// responses:
+// 	200: designRightSizingResponseWrapper
+
+func (h *Handler) RightSizePatternHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := models.DesignRightSizingRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	window := models.DefaultRightSizingWindow
+	if req.Window != "" {
+		var err error
+		if window, err = time.ParseDuration(req.Window); err != nil || window <= 0 {
+			http.Error(rw, ErrRightSizeDesign(fmt.Errorf("invalid window %q, expected a duration such as 72h", req.Window), "").Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	var collector models.ResourceUsageCollector
+	if req.ConnectionID != nil {
+		token, _ := r.Context().Value(models.TokenCtxKey).(string)
+		connection, statusCode, err := provider.GetConnectionByIDAndKind(token, *req.ConnectionID, "prometheus")
+		if err != nil {
+			http.Error(rw, err.Error(), statusCode)
+			return
+		}
+		url, _ := connection.Metadata["url"].(string)
+		collector = &models.PrometheusUsageCollector{Client: h.config.PrometheusClientForQuery, URL: url, Window: window}
+	} else {
+		metricsServer := &models.MetricsServerUsageCollector{Clients: []kubernetes.Interface{}}
+		k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+		for i := range k8sContexts {
+			kubeClient, err := k8sContexts[i].GenerateKubeHandler()
+			if err != nil {
+				h.log.Warn(ErrRightSizeDesign(err, patternFile.Name))
+				continue
+			}
+			metricsServer.Clients = append(metricsServer.Clients, kubeClient.KubeClient)
+		}
+		collector = metricsServer
+	}
+
+	rightSizing, err := models.RecommendDesignResources(r.Context(), &patternFile, collector, req.Policy)
+	if err != nil {
+		h.log.Error(ErrRightSizeDesign(err, patternFile.Name))
+		http.Error(rw, ErrRightSizeDesign(err, patternFile.Name).Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(rightSizing); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design right-sizing"))
+		http.Error(rw, models.ErrMarshal(err, "design right-sizing").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/pattern/{id}/rightsizing PatternsAPI idApplyPatternRightSizing
+// Handle POST request to apply resource recommendations to a design
+//
+// Sets the recommended requests and limits, as returned by POST /api/pattern/rightsizing, on the containers of
+// the workloads of the saved design and saves it as a new revision, with the patch of its version incremented.
+// The requests of every workload before and after, and the changes made to the design, are returned.
+// responses:
+// 	200: designRightSizingRevisionResponseWrapper
+
+func (h *Handler) ApplyPatternRightSizingHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := models.ApplyRightSizingRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	designID := uuid.FromStringOrNil(mux.Vars(r)["id"])
+	design, err := h.getManagedDesign(r, provider, designID)
+	if err != nil {
+		h.log.Error(ErrFetchPattern(err))
+		http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+		return
+	}
+	patternFile, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+	before, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := models.ApplyDesignRightSizing(&patternFile, req.Components); err != nil {
+		h.log.Error(ErrRightSizeDesign(err, patternFile.Name))
+		http.Error(rw, ErrRightSizeDesign(err, patternFile.Name).Error(), http.StatusBadRequest)
+		return
+	}
+	// the dependencies of the components are kept in the additional properties of their metadata, only
+	// encoded as JSON
+	byt, err := encoding.Marshal(patternFile)
+	if err != nil {
+		h.log.Error(ErrEncodePattern(err))
+		http.Error(rw, ErrEncodePattern(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	revision := &models.MesheryPattern{
+		ID:          design.ID,
+		Name:        design.Name,
+		PatternFile: string(byt),
+		Visibility:  design.Visibility,
+		CatalogData: design.CatalogData,
+	}
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+	resp, err := provider.SaveMesheryPattern(token, revision)
+	if err != nil {
+		h.log.Error(ErrSavePattern(err))
+		http.Error(rw, ErrSavePattern(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	saved := []models.MesheryPattern{}
+	if err := json.Unmarshal(resp, &saved); err != nil || len(saved) == 0 {
+		if err == nil {
+			err = fmt.Errorf("the provider did not return the saved design")
+		}
+		h.log.Error(models.ErrUnmarshal(err, "design"))
+		http.Error(rw, models.ErrUnmarshal(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
+	h.dispatchDesignHook(extensions.HookDesignSaved, uuid.FromStringOrNil(user.ID), saved[0].ID, saved[0].Name, nil)
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(DesignRightSizingRevision{
+		Design:     &saved[0],
+		Version:    patternFile.Version,
+		Components: req.Components,
+		Diff:       core.DiffPatternFiles(&before, &patternFile),
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design revision"))
+		http.Error(rw, models.ErrMarshal(err, "design revision").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// PatternSanitizeRegistriesENV lists the private registries masked in the images of every design sanitized by Meshery Server.
+	PatternSanitizeRegistriesENV = "PATTERN_SANITIZE_REGISTRIES"
+	// PatternSanitizeHostnamesENV lists the private hostnames masked in every design sanitized by Meshery Server.
+	PatternSanitizeHostnamesENV = "PATTERN_SANITIZE_HOSTNAMES"
+)
+
+// PatternRedactionsHeader is the header of sanitized exports counting the values redacted.
+const PatternRedactionsHeader = "X-Meshery-Redactions"
+
+// DesignSanitizeRequest is a design, either a saved design or a design file, to sanitize with the policy.
+// Saved designs are saved sanitized, as a new revision, when Save is set.
+type DesignSanitizeRequest struct {
+	DesignID    *uuid.UUID          `json:"design_id,omitempty"`
+	PatternFile string              `json:"pattern_file,omitempty"`
+	Policy      core.SanitizePolicy `json:"policy,omitempty"`
+	Save        bool                `json:"save,omitempty"`
+}
+
+// DesignSanitization is a sanitized design along with the report of the values redacted.
+type DesignSanitization struct {
+	PatternFile string                 `json:"pattern_file"`
+	Design      *models.MesheryPattern `json:"design,omitempty"`
+	Report      *core.SanitizeReport   `json:"report"`
+}
+
+// swagger:route POST /api/pattern/sanitize PatternsAPI idSanitizePattern
+// Handle POST request to sanitize a design
+//
+// Masks, or strips, the sensitive settings of a design, saved (design_id) or not (pattern_file), before it is
+// shared: the values of the settings whose key names a credential (passwords, tokens, API keys...), the values
+// which are credentials (private keys, cloud access keys, JWTs...), the data of Secrets, the credentials of URLs
+// and the private registries and hostnames. The policy selects the action, the mask and the patterns of the
+// sensitive keys and values, the registries and hostnames listed in PATTERN_SANITIZE_REGISTRIES and
+// PATTERN_SANITIZE_HOSTNAMES are masked in every design. Secret references are kept.
+// The sanitized design is returned along with the report of what was redacted. Saved designs are saved
+// sanitized, as a new revision with the patch of their version incremented, when save is set.
+// responses:
+// 	200: designSanitizationResponseWrapper
+
+func (h *Handler) SanitizePatternHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := DesignSanitizeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Save && req.DesignID == nil {
+		err := ErrRequestBody(fmt.Errorf("only saved designs can be saved sanitized"))
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFileContent := req.PatternFile
+	var design *models.MesheryPattern
+	if req.DesignID != nil {
+		var err error
+		design, err = h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	sanitized, report, err := core.SanitizePatternFile(&patternFile, patternSanitizePolicy(req.Policy))
+	if err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Save && report.Redacted() {
+		sanitized.Version = models.NextPatchVersion(sanitized.Version)
+	}
+	byt, err := yaml.Marshal(sanitized)
+	if err != nil {
+		h.log.Error(models.ErrMarshalYAML(err, "design"))
+		http.Error(rw, models.ErrMarshalYAML(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	sanitization := DesignSanitization{PatternFile: string(byt), Report: report}
+
+	if req.Save && report.Redacted() {
+		revision := &models.MesheryPattern{
+			ID:          design.ID,
+			Name:        design.Name,
+			PatternFile: string(byt),
+			Visibility:  design.Visibility,
+			CatalogData: design.CatalogData,
+		}
+		token, _ := r.Context().Value(models.TokenCtxKey).(string)
+		resp, err := provider.SaveMesheryPattern(token, revision)
+		if err != nil {
+			h.log.Error(ErrSavePattern(err))
+			http.Error(rw, ErrSavePattern(err).Error(), http.StatusInternalServerError)
+			return
+		}
+		saved := []models.MesheryPattern{}
+		if err := json.Unmarshal(resp, &saved); err != nil || len(saved) == 0 {
+			if err == nil {
+				err = fmt.Errorf("the provider did not return the saved design")
+			}
+			h.log.Error(models.ErrUnmarshal(err, "design"))
+			http.Error(rw, models.ErrUnmarshal(err, "design").Error(), http.StatusInternalServerError)
+			return
+		}
+		go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
+		h.dispatchDesignHook(extensions.HookDesignSaved, uuid.FromStringOrNil(user.ID), saved[0].ID, saved[0].Name, nil)
+		sanitization.Design = &saved[0]
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(sanitization); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design sanitization"))
+		http.Error(rw, models.ErrMarshal(err, "design sanitization").Error(), http.StatusInternalServerError)
+	}
+}
+
+// patternSanitizePolicy adds the private registries and hostnames configured for Meshery Server to the policy.
+func patternSanitizePolicy(policy core.SanitizePolicy) core.SanitizePolicy {
+	policy.Registries = append(policy.Registries, viper.GetStringSlice(PatternSanitizeRegistriesENV)...)
+	policy.Hostnames = append(policy.Hostnames, viper.GetStringSlice(PatternSanitizeHostnamesENV)...)
+	return policy
+}
+
+// sanitizeExportedDesign sanitizes the design of an export with the policy of Meshery Server, in place, and
+// returns the report of what was redacted.
+func sanitizeExportedDesign(design *models.MesheryPattern) (*core.SanitizeReport, error) {
+	patternFile, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		return nil, ErrPatternFile(err)
+	}
+	sanitized, report, err := core.SanitizePatternFile(&patternFile, patternSanitizePolicy(core.SanitizePolicy{}))
+	if err != nil {
+		return nil, err
+	}
+	byt, err := yaml.Marshal(sanitized)
+	if err != nil {
+		return nil, models.ErrMarshalYAML(err, "design")
+	}
+	design.PatternFile = string(byt)
+	return report, nil
+}
+
+// checkPublishedDesign rejects publishing a design to the catalog while it holds sensitive settings, the
+// report of what would be redacted is returned along with the error.
+func (h *Handler) checkPublishedDesign(r *http.Request, provider models.Provider, designID uuid.UUID) (*core.SanitizeReport, error) {
+	design, err := h.getManagedDesign(r, provider, designID)
+	if err != nil {
+		return nil, ErrFetchPattern(err)
+	}
+	patternFile, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		return nil, ErrPatternFile(err)
+	}
+	_, report, err := core.SanitizePatternFile(&patternFile, patternSanitizePolicy(core.SanitizePolicy{}))
+	if err != nil {
+		return nil, err
+	}
+	if report.Redacted() {
+		return report, ErrSensitiveDesign(fmt.Errorf("%d sensitive values would be published", len(report.Redactions)), design.Name)
+	}
+	return report, nil
+}
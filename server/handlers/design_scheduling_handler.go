@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DesignSchedulingRequest is a design, either a saved design or a design file, to check against the clusters.
+type DesignSchedulingRequest struct {
+	DesignID    *uuid.UUID `json:"design_id,omitempty"`
+	PatternFile string     `json:"pattern_file,omitempty"`
+}
+
+// ClusterScheduling is whether the workloads of a design can be scheduled on a cluster.
+type ClusterScheduling struct {
+	ContextID string                   `json:"context_id"`
+	Name      string                   `json:"name"`
+	Nodes     []core.ClusterNode       `json:"nodes"`
+	Warnings  []core.SchedulingWarning `json:"warnings"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// DesignScheduling is whether the workloads of a design can be scheduled on the selected clusters.
+type DesignScheduling struct {
+	Design   string              `json:"design"`
+	Clusters []ClusterScheduling `json:"clusters"`
+}
+
+// swagger:route POST /api/pattern/scheduling PatternsAPI idCheckPatternScheduling
+// Handle POST request to check that the workloads of a design can be scheduled
+//
+// Checks the workloads of a design, saved (design_id) or not (pattern_file), against the nodes of the
+// selected Kubernetes clusters: their scheduling traits and node selectors must select a node whose taints
+// they tolerate, and the manifests of their images must provide the architecture of such a node.
+// Workloads which would not be scheduled are reported as warnings, nothing is deployed.
+// responses:
+// 	200: designSchedulingResponseWrapper
+
+func (h *Handler) CheckPatternSchedulingHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := DesignSchedulingRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	scheduling := DesignScheduling{Design: patternFile.Name, Clusters: []ClusterScheduling{}}
+	resolver := &core.CachedPlatformResolver{Resolver: &core.RegistryPlatformResolver{}}
+
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	for i := range k8sContexts {
+		k8sContext := &k8sContexts[i]
+		cluster := ClusterScheduling{ContextID: k8sContext.ID, Name: k8sContext.Name}
+
+		kubeClient, err := k8sContext.GenerateKubeHandler()
+		if err != nil {
+			h.log.Warn(ErrCheckDesignScheduling(err, patternFile.Name))
+			cluster.Error = err.Error()
+			scheduling.Clusters = append(scheduling.Clusters, cluster)
+			continue
+		}
+		nodes, err := kubeClient.KubeClient.CoreV1().Nodes().List(r.Context(), metav1.ListOptions{})
+		if err != nil {
+			h.log.Warn(ErrCheckDesignScheduling(err, patternFile.Name))
+			cluster.Error = err.Error()
+			scheduling.Clusters = append(scheduling.Clusters, cluster)
+			continue
+		}
+		cluster.Nodes = core.NewClusterNodes(nodes.Items)
+
+		cluster.Warnings, err = core.CheckScheduling(r.Context(), &patternFile, cluster.Nodes, resolver)
+		if err != nil {
+			h.log.Error(ErrCheckDesignScheduling(err, patternFile.Name))
+			http.Error(rw, ErrCheckDesignScheduling(err, patternFile.Name).Error(), http.StatusBadRequest)
+			return
+		}
+		scheduling.Clusters = append(scheduling.Clusters, cluster)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(scheduling); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design scheduling"))
+		http.Error(rw, models.ErrMarshal(err, "design scheduling").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+)
+
+// swagger:route POST /api/pattern/simulate PatternsAPI idSimulatePattern
+// Handle POST request to simulate scaling changes to a design
+//
+// Applies hypothetical changes to the replica counts and the resource requests of the workloads of a
+// design, saved (design_id) or not (pattern_file), and reports whether the design still fits the
+// capacity of the selected Kubernetes clusters, the projected consumption of the resource quotas of its
+// namespaces and the projected monthly cost of its requests at the given rates. Nothing is deployed.
+// responses:
+// 	200: designSimulationResponseWrapper
+
+func (h *Handler) SimulatePatternHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	req := models.DesignSimulationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Rates.CPUCoreHour < 0 || req.Rates.MemoryGiBHour < 0 {
+		http.Error(rw, ErrSimulateDesign(fmt.Errorf("rates must be positive numbers"), "").Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFileContent := req.PatternFile
+	if req.DesignID != nil {
+		design, err := h.getManagedDesign(r, provider, *req.DesignID)
+		if err != nil {
+			h.log.Error(ErrFetchPattern(err))
+			http.Error(rw, ErrFetchPattern(err).Error(), http.StatusNotFound)
+			return
+		}
+		patternFileContent = design.PatternFile
+	}
+	patternFile, err := core.NewPatternFile([]byte(patternFileContent))
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	simulation, err := models.SimulateDesignChanges(&patternFile, req.Changes)
+	if err != nil {
+		http.Error(rw, ErrSimulateDesign(err, patternFile.Name).Error(), http.StatusBadRequest)
+		return
+	}
+	simulation.EstimateCost(req.Rates)
+
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	for i := range k8sContexts {
+		k8sContext := &k8sContexts[i]
+		kubeClient, err := k8sContext.GenerateKubeHandler()
+		if err == nil {
+			err = simulation.FitCluster(r.Context(), k8sContext.ID, k8sContext.Name, kubeClient.KubeClient, req.Deployed)
+		}
+		if err != nil {
+			h.log.Warn(ErrSimulateDesign(err, patternFile.Name))
+			simulation.Clusters = append(simulation.Clusters, models.ClusterFit{ContextID: k8sContext.ID, Name: k8sContext.Name, Error: err.Error()})
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(simulation); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design simulation"))
+		http.Error(rw, models.ErrMarshal(err, "design simulation").Error(), http.StatusInternalServerError)
+	}
+}
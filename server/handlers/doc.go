@@ -34,11 +34,13 @@ import (
 	"bytes"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/layer5io/meshery/server/extensions"
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshery/server/models/connections"
 	"github.com/layer5io/meshery/server/models/environments"
 	"github.com/layer5io/meshkit/models/events"
 	SMP "github.com/layer5io/service-mesh-performance/spec"
+	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -481,6 +483,13 @@ type systemDatabaseResponseWrapper struct {
 	Body *models.DatabaseSummary
 }
 
+// Returns the database of the reporting queries
+// swagger:response reportingDatabaseResponseWrapper
+type reportingDatabaseResponseWrapper struct {
+	// in: body
+	Body models.ReportingDBStatus
+}
+
 // Returns K8s contexts
 // swagger:response systemK8sContextsResponseWrapper
 type systemK8sContextsResponseWrapper struct {
@@ -611,3 +620,346 @@ type mesheryPatternFileDeployPayloadWrapper struct {
 	// in: body
 	Body *models.MesheryPatternFileDeployPayload
 }
+
+// Returns the pinned registry snapshot
+// swagger:response registrySnapshotResponseWrapper
+type registrySnapshotResponseWrapper struct {
+	// in: body
+	Body *models.RegistrySnapshot
+}
+
+// Returns a registry update and its progress
+// swagger:response registryUpdateResponseWrapper
+type registryUpdateResponseWrapper struct {
+	// in: body
+	Body *models.RegistryUpdate
+}
+
+// Returns the stored designs using a component
+// swagger:response designImpactResponseWrapper
+type designImpactResponseWrapper struct {
+	// in: body
+	Body []models.DesignImpact
+}
+
+// Returns the result of validating a design
+// swagger:response patternValidationResponseWrapper
+type patternValidationResponseWrapper struct {
+	// in: body
+	Body PatternValidationResponse
+}
+
+// Returns the usage of components in designs and deployments
+// swagger:response componentUsageResponseWrapper
+type componentUsageResponseWrapper struct {
+	// in: body
+	Body []models.ComponentUsageSummary
+}
+
+// Returns a token scoped to an extension
+// swagger:response extensionTokenResponseWrapper
+type extensionTokenResponseWrapper struct {
+	// in: body
+	Body *extensions.Token
+}
+
+// Returns the cost center tags of designs and workspaces
+// swagger:response costCenterTagsResponseWrapper
+type costCenterTagsResponseWrapper struct {
+	// in: body
+	Body []models.CostCenterTag
+}
+
+// Returns a cost center tag
+// swagger:response costCenterTagResponseWrapper
+type costCenterTagResponseWrapper struct {
+	// in: body
+	Body models.CostCenterTag
+}
+
+// Returns the chargeback report of a month
+// swagger:response costReportResponseWrapper
+type costReportResponseWrapper struct {
+	// in: body
+	Body *models.CostReport
+}
+
+// Returns the ephemeral policy of an environment
+// swagger:response ephemeralEnvironmentPolicyResponseWrapper
+type ephemeralEnvironmentPolicyResponseWrapper struct {
+	// in: body
+	Body models.EphemeralEnvironmentPolicy
+}
+
+// Returns the designs deployed to an ephemeral environment
+// swagger:response ephemeralDeploymentsResponseWrapper
+type ephemeralDeploymentsResponseWrapper struct {
+	// in: body
+	Body []models.EphemeralDeployment
+}
+
+// Returns the AdmissionReview with the decision of the design policies
+// swagger:response admissionReviewResponseWrapper
+type admissionReviewResponseWrapper struct {
+	// in: body
+	Body admissionv1.AdmissionReview
+}
+
+// Returns the ValidatingWebhookConfiguration registering the Meshery admission webhook
+// swagger:response admissionWebhookConfigurationResponseWrapper
+type admissionWebhookConfigurationResponseWrapper struct {
+	// in: body
+	Body string
+}
+
+// Returns the designs and their owners as Backstage catalog entities
+// swagger:response backstageEntitiesResponseWrapper
+type backstageEntitiesResponseWrapper struct {
+	// in: body
+	Body models.BackstageEntities
+}
+
+// Returns a design as a Backstage catalog entity
+// swagger:response backstageEntityResponseWrapper
+type backstageEntityResponseWrapper struct {
+	// in: body
+	Body models.BackstageEntity
+}
+
+// Returns the Backstage webhooks
+// swagger:response backstageWebhooksResponseWrapper
+type backstageWebhooksResponseWrapper struct {
+	// in: body
+	Body []models.BackstageWebhook
+}
+
+// Returns the registered Backstage webhook
+// swagger:response backstageWebhookResponseWrapper
+type backstageWebhookResponseWrapper struct {
+	// in: body
+	Body models.BackstageWebhook
+}
+
+// Returns the designs of the management API
+// swagger:response managedDesignsResponseWrapper
+type managedDesignsResponseWrapper struct {
+	// in: body
+	Body []models.ManagedDesign
+}
+
+// Returns a design of the management API
+// swagger:response managedDesignResponseWrapper
+type managedDesignResponseWrapper struct {
+	// in: body
+	Body models.ManagedDesign
+}
+
+// Returns the environments of the management API
+// swagger:response managedEnvironmentsResponseWrapper
+type managedEnvironmentsResponseWrapper struct {
+	// in: body
+	Body []models.ManagedEnvironment
+}
+
+// Returns an environment of the management API
+// swagger:response managedEnvironmentResponseWrapper
+type managedEnvironmentResponseWrapper struct {
+	// in: body
+	Body models.ManagedEnvironment
+}
+
+// Returns the connections of the management API
+// swagger:response managedConnectionsResponseWrapper
+type managedConnectionsResponseWrapper struct {
+	// in: body
+	Body []models.ManagedConnection
+}
+
+// Returns a connection of the management API
+// swagger:response managedConnectionResponseWrapper
+type managedConnectionResponseWrapper struct {
+	// in: body
+	Body models.ManagedConnection
+}
+
+// Returns the deployments of the management API
+// swagger:response managedDeploymentsResponseWrapper
+type managedDeploymentsResponseWrapper struct {
+	// in: body
+	Body []models.ManagedDeployment
+}
+
+// Returns a deployment of the management API
+// swagger:response managedDeploymentResponseWrapper
+type managedDeploymentResponseWrapper struct {
+	// in: body
+	Body models.ManagedDeployment
+}
+
+// Returns the workflows of the user
+// swagger:response workflowsResponseWrapper
+type workflowsResponseWrapper struct {
+	// in: body
+	Body []models.Workflow
+}
+
+// Returns a workflow
+// swagger:response workflowResponseWrapper
+type workflowResponseWrapper struct {
+	// in: body
+	Body models.Workflow
+}
+
+// Returns the runs of a workflow
+// swagger:response workflowRunsResponseWrapper
+type workflowRunsResponseWrapper struct {
+	// in: body
+	Body []models.WorkflowRun
+}
+
+// Returns a run of a workflow
+// swagger:response workflowRunResponseWrapper
+type workflowRunResponseWrapper struct {
+	// in: body
+	Body models.WorkflowRun
+}
+
+// Returns the comments of a design
+// swagger:response designCommentsResponseWrapper
+type designCommentsResponseWrapper struct {
+	// in: body
+	Body []models.DesignComment
+}
+
+// Returns a comment of a design
+// swagger:response designCommentResponseWrapper
+type designCommentResponseWrapper struct {
+	// in: body
+	Body models.DesignComment
+}
+
+// Returns the activity feed of a design
+// swagger:response designActivityResponseWrapper
+type designActivityResponseWrapper struct {
+	// in: body
+	Body []models.DesignActivity
+}
+
+// Returns the lineage of a design
+// swagger:response designLineageResponseWrapper
+type designLineageResponseWrapper struct {
+	// in: body
+	Body models.DesignLineage
+}
+
+// Returns the merge requests of a design
+// swagger:response designMergeRequestsResponseWrapper
+type designMergeRequestsResponseWrapper struct {
+	// in: body
+	Body []models.DesignMergeRequest
+}
+
+// Returns a merge request along with the changes it proposes
+// swagger:response designMergeRequestResponseWrapper
+type designMergeRequestResponseWrapper struct {
+	// in: body
+	Body DesignMergeRequestDetails
+}
+
+// Returns the dashboards of the user and of their workspaces
+// swagger:response dashboardsResponseWrapper
+type dashboardsResponseWrapper struct {
+	// in: body
+	Body []models.Dashboard
+}
+
+// Returns a dashboard
+// swagger:response dashboardResponseWrapper
+type dashboardResponseWrapper struct {
+	// in: body
+	Body models.Dashboard
+}
+
+// Returns the alert rules of the user
+// swagger:response alertRulesResponseWrapper
+type alertRulesResponseWrapper struct {
+	// in: body
+	Body []models.AlertRule
+}
+
+// Returns an alert rule
+// swagger:response alertRuleResponseWrapper
+type alertRuleResponseWrapper struct {
+	// in: body
+	Body models.AlertRule
+}
+
+// Returns the projected effect of scaling changes to a design
+// swagger:response designSimulationResponseWrapper
+type designSimulationResponseWrapper struct {
+	// in: body
+	Body models.DesignSimulation
+}
+
+// Returns whether the workloads of a design can be scheduled on the selected clusters
+// swagger:response designSchedulingResponseWrapper
+type designSchedulingResponseWrapper struct {
+	// in: body
+	Body DesignScheduling
+}
+
+// Returns whether the workloads of a design comply with the Pod Security Standards on the selected clusters
+// swagger:response designPodSecurityResponseWrapper
+type designPodSecurityResponseWrapper struct {
+	// in: body
+	Body DesignPodSecurity
+}
+
+// Returns the findings of the lint rules on a design
+// swagger:response designLintResponseWrapper
+type designLintResponseWrapper struct {
+	// in: body
+	Body DesignLint
+}
+
+// Returns the recommended resources of the workloads of a design
+// swagger:response designRightSizingResponseWrapper
+type designRightSizingResponseWrapper struct {
+	// in: body
+	Body models.DesignRightSizing
+}
+
+// Returns the revision of a design with the recommended resources applied
+// swagger:response designRightSizingRevisionResponseWrapper
+type designRightSizingRevisionResponseWrapper struct {
+	// in: body
+	Body DesignRightSizingRevision
+}
+
+// Returns the diagnosis of the deployment of a design
+// swagger:response deploymentDiagnosisResponseWrapper
+type deploymentDiagnosisResponseWrapper struct {
+	// in: body
+	Body models.DeploymentDiagnosis
+}
+
+// Returns the resources owned by designs
+// swagger:response resourceOwnershipResponseWrapper
+type resourceOwnershipResponseWrapper struct {
+	// in: body
+	Body []models.ResourceOwnership
+}
+
+// Returns the overrides of the ownership of resources
+// swagger:response ownershipOverridesResponseWrapper
+type ownershipOverridesResponseWrapper struct {
+	// in: body
+	Body []models.OwnershipOverride
+}
+
+// Returns the sanitized design and the report of the values redacted
+// swagger:response designSanitizationResponseWrapper
+type designSanitizationResponseWrapper struct {
+	// in: body
+	Body DesignSanitization
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/spf13/viper"
+)
+
+// ephemeralReaperInterval is how often deployments to ephemeral environments are checked for inactivity.
+const ephemeralReaperInterval = 10 * time.Minute
+
+// swagger:route GET /api/environments/{environmentID}/ephemeral EnvironmentsAPI idGetEphemeralEnvironmentPolicy
+// Handle GET request for the ephemeral policy of an environment
+//
+// responses:
+// 	200: ephemeralEnvironmentPolicyResponseWrapper
+
+func (h *Handler) GetEphemeralEnvironmentPolicyHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	environmentID, err := uuid.FromString(mux.Vars(r)["environmentID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	policy, err := eep.GetPolicy(environmentID)
+	if err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+	if policy == nil {
+		http.Error(rw, fmt.Sprintf("environment %s is not ephemeral", environmentID), http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(policy); err != nil {
+		h.log.Error(models.ErrMarshal(err, "ephemeral environment policy"))
+		http.Error(rw, models.ErrMarshal(err, "ephemeral environment policy").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/environments/{environmentID}/ephemeral EnvironmentsAPI idSaveEphemeralEnvironmentPolicy
+// Handle POST request to mark an environment as ephemeral
+//
+// Designs deployed to the connections of an ephemeral environment are undeployed after idle_hours without
+// activity. Their owners are warned warning_hours (1 by default) before.
+// responses:
+// 	200: ephemeralEnvironmentPolicyResponseWrapper
+
+func (h *Handler) SaveEphemeralEnvironmentPolicyHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	environmentID, err := uuid.FromString(mux.Vars(r)["environmentID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	var policy models.EphemeralEnvironmentPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if policy.WarningHours == 0 {
+		policy.WarningHours = models.DefaultEphemeralWarningHours
+	}
+	if policy.IdleHours <= 0 || policy.WarningHours < 0 || policy.WarningHours >= policy.IdleHours {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("idle_hours must be positive and greater than warning_hours")).Error(), http.StatusBadRequest)
+		return
+	}
+	policy.EnvironmentID = environmentID
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	if err := eep.SavePolicy(&policy); err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(policy); err != nil {
+		h.log.Error(models.ErrMarshal(err, "ephemeral environment policy"))
+		http.Error(rw, models.ErrMarshal(err, "ephemeral environment policy").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route DELETE /api/environments/{environmentID}/ephemeral EnvironmentsAPI idDeleteEphemeralEnvironmentPolicy
+// Handle DELETE request to unmark an environment as ephemeral
+//
+// Designs deployed to the environment are no longer undeployed automatically.
+// responses:
+// 	200:
+
+func (h *Handler) DeleteEphemeralEnvironmentPolicyHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	environmentID, err := uuid.FromString(mux.Vars(r)["environmentID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	if err := eep.DeletePolicy(environmentID); err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// swagger:route GET /api/environments/{environmentID}/ephemeral/deployments EnvironmentsAPI idGetEphemeralDeployments
+// Handle GET request for the designs deployed to an ephemeral environment
+//
+// Returns the deployments least recently active first.
+// responses:
+// 	200: ephemeralDeploymentsResponseWrapper
+
+func (h *Handler) GetEphemeralDeploymentsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	environmentID, err := uuid.FromString(mux.Vars(r)["environmentID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	deployments, err := eep.GetDeployments(environmentID)
+	if err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(deployments); err != nil {
+		h.log.Error(models.ErrMarshal(err, "ephemeral deployments"))
+		http.Error(rw, models.ErrMarshal(err, "ephemeral deployments").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/environments/{environmentID}/ephemeral/deployments/{designID}/extend EnvironmentsAPI idExtendEphemeralDeployment
+// Handle POST request to keep a design deployed to an ephemeral environment
+//
+// Records activity of the design, postponing its undeployment by the idle hours of the environment.
+// Systems observing the traffic of the design can call it on behalf of its owner to report activity.
+// responses:
+// 	200:
+
+func (h *Handler) ExtendEphemeralDeploymentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	vars := mux.Vars(r)
+	environmentID, err := uuid.FromString(vars["environmentID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	designID, err := uuid.FromString(vars["designID"])
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	deployment, err := eep.GetDeployment(environmentID, designID)
+	if err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+	if deployment == nil {
+		http.Error(rw, fmt.Sprintf("design %s is not deployed to ephemeral environment %s", designID, environmentID), http.StatusNotFound)
+		return
+	}
+	if deployment.UserID.String() != user.ID {
+		http.Error(rw, "only the owner of a deployment can extend it", http.StatusForbidden)
+		return
+	}
+	if err := eep.RecordActivity(environmentID, designID, time.Now()); err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, environmentID.String()))
+		http.Error(rw, ErrEphemeralEnvironment(err, environmentID.String()).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// trackEphemeralDeployment starts tracking the design if it was deployed to the connections of an ephemeral environment.
+// The session of the request is recorded along with the deployment, to undeploy the design on behalf of the user.
+func (h *Handler) trackEphemeralDeployment(r *http.Request, provider models.Provider, userID uuid.UUID, designID uuid.UUID, patternFile string, name string) {
+	k8sContexts, _ := r.Context().Value(models.KubeClustersKey).([]models.K8sContext)
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+
+	connectionIDs := make([]string, 0, len(k8sContexts))
+	for _, k8sContext := range k8sContexts {
+		connectionIDs = append(connectionIDs, k8sContext.ConnectionID)
+	}
+
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	environmentIDs, err := eep.GetEphemeralEnvironmentsOfConnections(connectionIDs)
+	if err != nil {
+		h.log.Warn(ErrEphemeralEnvironment(err, ""))
+		return
+	}
+	if len(environmentIDs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, environmentID := range environmentIDs {
+		err := eep.RecordDeployment(&models.EphemeralDeployment{
+			EnvironmentID:  environmentID,
+			DesignID:       designID,
+			DesignName:     name,
+			UserID:         userID,
+			PatternFile:    patternFile,
+			DeployedAt:     now,
+			LastActivityAt: now,
+			Provider:       provider.Name(),
+			Token:          token,
+			Contexts:       k8sContexts,
+		})
+		if err != nil {
+			h.log.Warn(ErrEphemeralEnvironment(err, environmentID.String()))
+		}
+	}
+}
+
+// untrackEphemeralDeployment stops tracking the design once it was undeployed.
+func (h *Handler) untrackEphemeralDeployment(provider models.Provider, designID uuid.UUID) {
+	eep := &models.EphemeralEnvironmentPersister{DB: provider.GetGenericPersister()}
+	if err := eep.RemoveDeployment(designID); err != nil {
+		h.log.Warn(ErrEphemeralEnvironment(err, ""))
+	}
+}
+
+func (h *Handler) runEphemeralEnvironmentReaper() {
+	ticker := time.NewTicker(ephemeralReaperInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.reapEphemeralDeployments()
+	}
+}
+
+// reapEphemeralDeployments warns the owners of deployments to ephemeral environments which
+// are about to be undeployed and undeploys the deployments which have been idle for too long.
+// Events acted upon a design count as activity.
+func (h *Handler) reapEphemeralDeployments() {
+	eep := &models.EphemeralEnvironmentPersister{DB: h.dbHandler}
+	deployments, err := eep.GetDeployments(uuid.Nil)
+	if err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, ""))
+		return
+	}
+
+	now := time.Now()
+	for i := range deployments {
+		deployment := &deployments[i]
+		policy, err := eep.GetPolicy(deployment.EnvironmentID)
+		if err != nil || policy == nil {
+			continue
+		}
+
+		last, err := eep.GetLastDesignEventTime(deployment.DesignID)
+		if err == nil && last != nil && last.After(deployment.LastActivityAt) {
+			if err := eep.RecordActivity(deployment.EnvironmentID, deployment.DesignID, *last); err != nil {
+				h.log.Warn(ErrEphemeralEnvironment(err, deployment.EnvironmentID.String()))
+			}
+			deployment.LastActivityAt = *last
+			deployment.WarnedAt = nil
+		}
+
+		undeployAt := deployment.UndeployAt(policy)
+		warnAt := undeployAt.Add(-time.Duration(policy.WarningHours) * time.Hour)
+		switch {
+		case !now.Before(undeployAt):
+			h.undeployEphemeralDeployment(deployment)
+		case deployment.WarnedAt == nil && !now.Before(warnAt):
+			h.publishEphemeralEvent(deployment, events.Warning, fmt.Sprintf("Design '%s' will be undeployed from an ephemeral environment at %s due to inactivity. Extend it to keep it deployed.", deployment.DesignName, undeployAt.Format(time.RFC1123)), map[string]interface{}{
+				"undeploy_at": undeployAt,
+				"extend_link": fmt.Sprintf("/api/environments/%s/ephemeral/deployments/%s/extend", deployment.EnvironmentID, deployment.DesignID),
+			})
+			if err := eep.MarkWarned(deployment.ID, now); err != nil {
+				h.log.Warn(ErrEphemeralEnvironment(err, deployment.EnvironmentID.String()))
+			}
+		}
+	}
+}
+
+func (h *Handler) undeployEphemeralDeployment(deployment *models.EphemeralDeployment) {
+	provider, ok := h.config.Providers[deployment.Provider]
+	if !ok || deployment.Token == "" {
+		if deployment.WarnedAt == nil {
+			h.publishEphemeralEvent(deployment, events.Warning, fmt.Sprintf("Design '%s' has been idle in an ephemeral environment but could not be undeployed automatically. Undeploy it manually.", deployment.DesignName), nil)
+			eep := &models.EphemeralEnvironmentPersister{DB: h.dbHandler}
+			_ = eep.MarkWarned(deployment.ID, time.Now())
+		}
+		return
+	}
+	prefObj, err := provider.ReadFromPersister(deployment.UserID.String())
+	if err != nil {
+		prefObj = &models.Preference{}
+	}
+
+	patternFile, err := core.NewPatternFile([]byte(deployment.PatternFile))
+	if err != nil {
+		h.log.Error(ErrEphemeralEnvironment(err, deployment.EnvironmentID.String()))
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), models.TokenCtxKey, deployment.Token)
+	ctx = context.WithValue(ctx, models.KubeClustersKey, deployment.Contexts)
+	_, err = _processPattern(&core.ProcessPatternOptions{
+		Context:          ctx,
+		Provider:         provider,
+		Pattern:          patternFile,
+		PrefObj:          prefObj,
+		UserID:           deployment.UserID.String(),
+		IsDelete:         true,
+		SkipPrintLogs:    viper.GetBool("DEBUG"),
+		Registry:         h.registryManager,
+		EventBroadcaster: h.config.EventBroadcaster,
+		Log:              h.log,
+	})
+	if err != nil {
+		err = ErrPatternDeploy(err, deployment.DesignName)
+		h.log.Error(err)
+		h.publishEphemeralEvent(deployment, events.Error, fmt.Sprintf("Failed to undeploy idle design '%s' from an ephemeral environment.", deployment.DesignName), map[string]interface{}{
+			"error": err,
+		})
+		return
+	}
+
+	h.untrackEphemeralDeployment(provider, deployment.DesignID)
+	h.dispatchDesignHook(extensions.HookDesignUndeployed, deployment.UserID, &deployment.DesignID, deployment.DesignName, nil)
+	h.publishEphemeralEvent(deployment, events.Informational, fmt.Sprintf("Undeployed design '%s' from an ephemeral environment after %s of inactivity.", deployment.DesignName, time.Since(deployment.LastActivityAt).Round(time.Minute)), nil)
+}
+
+// publishEphemeralEvent notifies the owner of the deployment. Events are acted upon
+// the environment so that they do not count as activity of the design.
+func (h *Handler) publishEphemeralEvent(deployment *models.EphemeralDeployment, severity events.EventSeverity, description string, metadata map[string]interface{}) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["design_id"] = deployment.DesignID
+	metadata["design_name"] = deployment.DesignName
+
+	event := events.NewEvent().ActedUpon(deployment.EnvironmentID).FromUser(deployment.UserID).FromSystem(*h.SystemID).
+		WithCategory("environment").WithAction("ephemeral_undeploy").WithSeverity(severity).
+		WithDescription(description).WithMetadata(metadata).Build()
+
+	ep := &models.EventsPersister{DB: h.dbHandler}
+	if err := ep.PersistEvent(event); err != nil {
+		h.log.Warn(err)
+	}
+	go h.config.EventBroadcaster.Publish(deployment.UserID, event)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+func newEphemeralTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(database.Options{
+		Filename: fmt.Sprintf("file:%s?mode=rwc", filepath.Join(t.TempDir(), "test.db")),
+		Engine:   database.SQLITE,
+		Logger:   log,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.EphemeralEnvironmentPolicy{}, &models.EphemeralDeployment{}, &events.Event{}); err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	return &Handler{
+		log:       log,
+		dbHandler: &db,
+		SystemID:  &systemID,
+		config: &models.HandlerConfig{
+			EventBroadcaster: models.NewBroadcaster("test"),
+			Providers:        map[string]models.Provider{},
+		},
+	}
+}
+
+func TestReapEphemeralDeployments(t *testing.T) {
+	environmentID := uuid.Must(uuid.NewV4())
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		// idle is how long the deployment has been inactive
+		idle time.Duration
+		// activity is how long ago an event was last acted upon the design, none if zero
+		activity time.Duration
+		// provider is the provider the design was deployed with
+		provider   string
+		wantWarned bool
+		wantEvent  string
+	}{
+		{
+			name: "active",
+			idle: time.Hour,
+		},
+		{
+			name:       "about to be undeployed",
+			idle:       4*time.Hour + 30*time.Minute,
+			wantWarned: true,
+			wantEvent:  "will be undeployed",
+		},
+		{
+			name:     "recent design events",
+			idle:     6 * time.Hour,
+			activity: time.Hour,
+		},
+		{
+			name:       "idle with a provider no longer available",
+			idle:       6 * time.Hour,
+			provider:   "Meshery",
+			wantWarned: true,
+			wantEvent:  "could not be undeployed automatically",
+		},
+		{
+			name:       "idle without session",
+			idle:       6 * time.Hour,
+			wantWarned: true,
+			wantEvent:  "could not be undeployed automatically",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newEphemeralTestHandler(t)
+			eep := &models.EphemeralEnvironmentPersister{DB: h.dbHandler}
+			if err := eep.SavePolicy(&models.EphemeralEnvironmentPolicy{EnvironmentID: environmentID, IdleHours: 5, WarningHours: 1}); err != nil {
+				t.Fatal(err)
+			}
+			designID := uuid.Must(uuid.NewV4())
+			userID := uuid.Must(uuid.NewV4())
+			deployment := &models.EphemeralDeployment{
+				EnvironmentID:  environmentID,
+				DesignID:       designID,
+				DesignName:     "web",
+				UserID:         userID,
+				DeployedAt:     now.Add(-tt.idle),
+				LastActivityAt: now.Add(-tt.idle),
+				Provider:       tt.provider,
+			}
+			if tt.provider != "" {
+				deployment.Token = "token"
+			}
+			if err := eep.RecordDeployment(deployment); err != nil {
+				t.Fatal(err)
+			}
+			if tt.activity != 0 {
+				event := events.NewEvent().ActedUpon(designID).FromUser(userID).FromSystem(*h.SystemID).
+					WithCategory("pattern").WithAction("update").WithSeverity(events.Informational).Build()
+				event.CreatedAt = now.Add(-tt.activity)
+				if err := h.dbHandler.Create(event).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			h.reapEphemeralDeployments()
+
+			got, err := eep.GetDeployment(environmentID, designID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil {
+				t.Fatal("the deployment is no longer tracked")
+			}
+			if warned := got.WarnedAt != nil; warned != tt.wantWarned {
+				t.Errorf("warned = %v, want %v", warned, tt.wantWarned)
+			}
+			if tt.activity != 0 && !got.LastActivityAt.After(deployment.LastActivityAt) {
+				t.Errorf("last activity = %v, want the time of the design event", got.LastActivityAt)
+			}
+
+			var published []events.Event
+			if err := h.dbHandler.Where("acted_upon = ?", environmentID).Find(&published).Error; err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantEvent == "" {
+				if len(published) != 0 {
+					t.Errorf("expected no event, got %+v", published)
+				}
+				return
+			}
+			if len(published) != 1 || !strings.Contains(published[0].Description, tt.wantEvent) {
+				t.Errorf("events = %+v, want one event containing %q", published, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestExtendEphemeralDeploymentHandler(t *testing.T) {
+	h := newEphemeralTestHandler(t)
+	provider := &models.DefaultLocalProvider{GenericPersister: h.dbHandler}
+	eep := &models.EphemeralEnvironmentPersister{DB: h.dbHandler}
+
+	environmentID := uuid.Must(uuid.NewV4())
+	designID := uuid.Must(uuid.NewV4())
+	owner := uuid.Must(uuid.NewV4())
+	idleSince := time.Now().Add(-2 * time.Hour)
+	err := eep.RecordDeployment(&models.EphemeralDeployment{
+		EnvironmentID:  environmentID,
+		DesignID:       designID,
+		UserID:         owner,
+		DeployedAt:     idleSince,
+		LastActivityAt: idleSince,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		user     uuid.UUID
+		designID uuid.UUID
+		want     int
+	}{
+		{name: "another user", user: uuid.Must(uuid.NewV4()), designID: designID, want: http.StatusForbidden},
+		{name: "untracked design", user: owner, designID: uuid.Must(uuid.NewV4()), want: http.StatusNotFound},
+		{name: "owner", user: owner, designID: designID, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = mux.SetURLVars(req, map[string]string{"environmentID": environmentID.String(), "designID": tt.designID.String()})
+			rec := httptest.NewRecorder()
+			h.ExtendEphemeralDeploymentHandler(rec, req, nil, &models.User{ID: tt.user.String()}, provider)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+
+			deployment, err := eep.GetDeployment(environmentID, designID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if extended := deployment.LastActivityAt.After(idleSince); extended != (tt.want == http.StatusOK) {
+				t.Errorf("extended = %v", extended)
+			}
+		})
+	}
+}
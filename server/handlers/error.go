@@ -150,6 +150,35 @@ const (
 	ErrExportPatternInFormatCode           = "meshery-server-1364"
 	ErrFileTypeCode                        = "meshery-server-1366"
 	ErrCreatingOPAInstanceCode             = "meshery-server-1367"
+	ErrFetchComponentUsageCode             = "meshery-server-1373"
+	ErrRegisterExtensionCode               = "meshery-server-1374"
+	ErrInvalidExtensionTokenCode           = "meshery-server-1375"
+	ErrIssueExtensionTokenCode             = "meshery-server-1376"
+	ErrExtensionHookCode                   = "meshery-server-1377"
+	ErrGenerateCatalogPreviewCode          = "meshery-server-1379"
+	ErrUnsupportedPreviewSizeCode          = "meshery-server-1380"
+	ErrCostCenterTagsCode                  = "meshery-server-1382"
+	ErrGenerateCostReportCode              = "meshery-server-1383"
+	ErrEphemeralEnvironmentCode            = "meshery-server-1384"
+	ErrAdmissionReviewCode                 = "meshery-server-1387"
+	ErrBackstageEntitiesCode               = "meshery-server-1388"
+	ErrBackstageWebhookCode                = "meshery-server-1389"
+	ErrManagementAPICode                   = "meshery-server-1392"
+	ErrManagedDeploymentCode               = "meshery-server-1393"
+	ErrWorkflowCode                        = "meshery-server-1400"
+	ErrSimulateDesignCode                  = "meshery-server-1402"
+	ErrCheckDesignSchedulingCode           = "meshery-server-1404"
+	ErrCheckDesignPodSecurityCode          = "meshery-server-1408"
+	ErrRightSizeDesignCode                 = "meshery-server-1410"
+	ErrDiagnoseDeploymentCode              = "meshery-server-1411"
+	ErrDesignOwnershipCode                 = "meshery-server-1412"
+	ErrSensitiveDesignCode                 = "meshery-server-1416"
+	ErrAlertRuleCode                       = "meshery-server-1419"
+	ErrDesignCommentCode                   = "meshery-server-1421"
+	ErrDesignForkCode                      = "meshery-server-1422"
+	ErrDashboardCode                       = "meshery-server-1424"
+	ErrRegistryUpdateCode                  = "meshery-server-1425"
+	ErrExtensionScopeCode                  = "meshery-server-1428"
 )
 
 var (
@@ -653,3 +682,119 @@ func ErrExportPatternInFormat(err error, format, designName string) error {
 func ErrCreatingOPAInstance(err error) error {
 	return errors.New(ErrCreatingOPAInstanceCode, errors.Alert, []string{"Error creating OPA Instance."}, []string{err.Error()}, []string{"Unable to create OPA instance, policies will not be evaluated."}, []string{"Ensure relationships are registered"})
 }
+
+func ErrFetchComponentUsage(err error) error {
+	return errors.New(ErrFetchComponentUsageCode, errors.Alert, []string{"Failed to fetch component usage"}, []string{err.Error()}, []string{"Component usage or designs could not be read from the database"}, []string{"Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrRegisterExtension(err error) error {
+	return errors.New(ErrRegisterExtensionCode, errors.Alert, []string{"Failed to register the extension"}, []string{err.Error()}, []string{"The extension returned by the plugin is invalid"}, []string{"Ensure the extension has a name which does not contain \"/\""})
+}
+
+func ErrInvalidExtensionToken(name string) error {
+	return errors.New(ErrInvalidExtensionTokenCode, errors.Alert, []string{fmt.Sprintf("Missing or invalid token for extension %s", name)}, []string{"The request to the extension does not carry a valid token scoped to the extension"}, []string{"The token is missing, expired, or was issued for another extension or to another user"}, []string{"Request a token from /api/extension/{name}/token and send it in the X-Meshery-Extension-Token header"})
+}
+
+func ErrExtensionScope(name string, scopes []string) error {
+	return errors.New(ErrExtensionScopeCode, errors.Alert, []string{fmt.Sprintf("Token for extension %s is not granted the scopes of the route", name)}, []string{fmt.Sprintf("The route requires the scopes: %s", strings.Join(scopes, ", "))}, []string{"The extension does not declare the scopes its route requires"}, []string{"Declare the scopes required by the routes in the Scopes of the extension and request a new token"})
+}
+
+func ErrIssueExtensionToken(err error, name string) error {
+	return errors.New(ErrIssueExtensionTokenCode, errors.Alert, []string{fmt.Sprintf("Failed to issue a token for extension %s", name)}, []string{err.Error()}, []string{"The extension is not registered with Meshery Server"}, []string{"Ensure the extension package of the provider is loaded"})
+}
+
+func ErrExtensionHook(err error, name, hook string) error {
+	return errors.New(ErrExtensionHookCode, errors.Alert, []string{fmt.Sprintf("Extension %s failed to handle %s", name, hook)}, []string{err.Error()}, []string{"The lifecycle hook of the extension returned an error"}, []string{"Check the logs of the extension"})
+}
+
+func ErrGenerateCatalogPreview(err error, designID string) error {
+	return errors.New(ErrGenerateCatalogPreviewCode, errors.Alert, []string{fmt.Sprintf("Failed to generate the catalog preview of design %s", designID)}, []string{err.Error()}, []string{"The design file is invalid", "The preview images could not be written to the Meshery data folder"}, []string{"Ensure the design file is valid", "Ensure the Meshery data folder is writable"})
+}
+
+func ErrUnsupportedPreviewSize(size string) error {
+	return errors.New(ErrUnsupportedPreviewSizeCode, errors.Alert, []string{fmt.Sprintf("Unsupported preview size %s", size)}, []string{"Catalog previews are only generated at fixed resolutions"}, []string{"The requested size is not one of the catalog preview resolutions"}, []string{"Request one of 1200x630, 600x315 or 300x158"})
+}
+
+func ErrCostCenterTags(err error) error {
+	return errors.New(ErrCostCenterTagsCode, errors.Alert, []string{"Failed to read or update cost center tags"}, []string{err.Error()}, []string{"Cost center tags could not be read from or written to the database"}, []string{"Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrGenerateCostReport(err error) error {
+	return errors.New(ErrGenerateCostReportCode, errors.Alert, []string{"Failed to generate the cost report"}, []string{err.Error()}, []string{"The month or rates of the report are invalid", "Designs, tags or MeshSync data could not be read from the database"}, []string{"Pass the month as YYYY-MM and rates as positive numbers", "Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrEphemeralEnvironment(err error, environmentID string) error {
+	return errors.New(ErrEphemeralEnvironmentCode, errors.Alert, []string{fmt.Sprintf("Failed to manage the deployments of ephemeral environment %s", environmentID)}, []string{err.Error()}, []string{"The policy or deployments of the environment could not be read from or written to the database", "The idle deployment could not be undeployed"}, []string{"Ensure the Meshery database is reachable and try again", "Check the connections of the environment are reachable"})
+}
+
+func ErrAdmissionReview(err error) error {
+	return errors.New(ErrAdmissionReviewCode, errors.Alert, []string{"Failed to review the admission of the resource"}, []string{err.Error()}, []string{"The resource could not be converted to a design", "The design policies could not be evaluated"}, []string{"Ensure the resource is a valid Kubernetes resource", "Ensure the design policies are valid and ADMISSION_WEBHOOK_POLICY_QUERY refers to a rule defined by them"})
+}
+
+func ErrBackstageEntities(err error) error {
+	return errors.New(ErrBackstageEntitiesCode, errors.Alert, []string{"Failed to generate the Backstage catalog entities"}, []string{err.Error()}, []string{"The design does not exist", "Designs or MeshSync data could not be read from the database"}, []string{"Ensure the design id is valid", "Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrBackstageWebhook(err error) error {
+	return errors.New(ErrBackstageWebhookCode, errors.Alert, []string{"Failed to manage or notify the Backstage webhooks"}, []string{err.Error()}, []string{"The webhooks could not be read from or written to the database", "The webhook is not reachable or rejected the notification"}, []string{"Ensure the Meshery database is reachable and try again", "Ensure the URL of the webhook is reachable from Meshery Server and the secret matches the one configured in Backstage"})
+}
+
+func ErrManagementAPI(err error, resource string) error {
+	return errors.New(ErrManagementAPICode, errors.Alert, []string{fmt.Sprintf("Failed to manage the %s through the management API", resource)}, []string{err.Error()}, []string{"The request body is not valid", "The provider failed to read or write the resource"}, []string{"Ensure the request matches the schema of the resource", "Ensure the provider is reachable and try again"})
+}
+
+func ErrManagedDeployment(err error, designName string) error {
+	return errors.New(ErrManagedDeploymentCode, errors.Alert, []string{fmt.Sprintf("Failed to deploy design %s to the environment", designName)}, []string{err.Error()}, []string{"The environment has no connected Kubernetes connection", "The design is not valid or the cluster rejected its resources"}, []string{"Assign a connected Kubernetes connection to the environment", "Validate the design and review the events of the deployment"})
+}
+
+func ErrWorkflow(err error, workflow string) error {
+	return errors.New(ErrWorkflowCode, errors.Alert, []string{fmt.Sprintf("Failed to process workflow %s", workflow)}, []string{err.Error()}, []string{"The workflow or its runs could not be read or written", "A step of the workflow failed"}, []string{"Verify that the database of Meshery Server is reachable", "Review the status of the steps of the run and the events of the workflow"})
+}
+
+func ErrSimulateDesign(err error, designName string) error {
+	return errors.New(ErrSimulateDesignCode, errors.Alert, []string{fmt.Sprintf("Failed to simulate changes to design %s", designName)}, []string{err.Error()}, []string{"A change refers to a component which is not a workload of the design", "A requested quantity is not a valid Kubernetes quantity", "The nodes, pods or resource quotas of a cluster could not be listed"}, []string{"Refer to workloads by their display name", "Use quantities like 500m for CPU and 512Mi for memory", "Verify that the Kubernetes connection is connected and can list nodes, pods and resource quotas"})
+}
+
+func ErrCheckDesignScheduling(err error, designName string) error {
+	return errors.New(ErrCheckDesignSchedulingCode, errors.Alert, []string{fmt.Sprintf("Failed to check whether design %s can be scheduled", designName)}, []string{err.Error()}, []string{"The nodes of a cluster could not be listed", "The scheduling traits of a component are invalid"}, []string{"Verify that the Kubernetes connection is connected and can list nodes", "Declare the nodeSelector and tolerations traits like the nodeSelector and tolerations of a pod"})
+}
+
+func ErrCheckDesignPodSecurity(err error, designName string) error {
+	return errors.New(ErrCheckDesignPodSecurityCode, errors.Alert, []string{fmt.Sprintf("Failed to check design %s against the Pod Security Standards", designName)}, []string{err.Error()}, []string{"The namespaces of a cluster could not be listed", "The pod template of a component is malformed"}, []string{"Verify that the Kubernetes connection is connected and can list namespaces", "Ensure the pod templates of the components are valid Kubernetes pod templates"})
+}
+
+func ErrRightSizeDesign(err error, designName string) error {
+	return errors.New(ErrRightSizeDesignCode, errors.Alert, []string{fmt.Sprintf("Failed to right-size the resources of design %s", designName)}, []string{err.Error()}, []string{"The usage of the workloads could not be queried from Prometheus or the metrics-server", "A recommendation refers to a component or a container which is not in the design", "A recommended quantity is not a valid Kubernetes quantity"}, []string{"Verify that the Prometheus connection is reachable, or that the metrics-server is installed on the clusters", "Apply the recommendations computed for the current revision of the design", "Use quantities like 250m for CPU and 256Mi for memory"})
+}
+
+func ErrDiagnoseDeployment(err error, designName string) error {
+	return errors.New(ErrDiagnoseDeploymentCode, errors.Alert, []string{fmt.Sprintf("Failed to gather the evidence to diagnose the deployment of design %s", designName)}, []string{err.Error()}, []string{"The Kubernetes connection is not connected", "The events, pods or logs of the namespaces of the design could not be read"}, []string{"Verify that the Kubernetes connection is connected", "Ensure the credentials of the connection can list events and pods and read the logs of pods"})
+}
+
+func ErrDesignOwnership(err error, designName string) error {
+	return errors.New(ErrDesignOwnershipCode, errors.Alert, []string{fmt.Sprintf("Failed to read or record the ownership of the resources of design %s", designName)}, []string{err.Error()}, []string{"The ownership of resources or its overrides could not be read from or written to the database"}, []string{"Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrSensitiveDesign(err error, designName string) error {
+	return errors.New(ErrSensitiveDesignCode, errors.Alert, []string{fmt.Sprintf("Design %s holds sensitive settings and cannot be published", designName)}, []string{err.Error()}, []string{"The design holds credentials, the data of Secrets, or private registries or hostnames"}, []string{"Sanitize the design with POST /api/pattern/sanitize and save it sanitized, or refer to its credentials with secretRef, then publish it"})
+}
+
+func ErrAlertRule(err error, rule string) error {
+	return errors.New(ErrAlertRuleCode, errors.Alert, []string{fmt.Sprintf("Failed to process alert rule %s", rule)}, []string{err.Error()}, []string{"The alert rule could not be read or written", "The metric of the alert rule could not be measured", "The webhook of the alert rule is not reachable"}, []string{"Verify that the database of Meshery Server is reachable", "Review the error of the alert rule and the URL of its webhooks"})
+}
+
+func ErrDesignComment(err error, design string) error {
+	return errors.New(ErrDesignCommentCode, errors.Alert, []string{fmt.Sprintf("Failed to process the comments of the design %s", design)}, []string{err.Error()}, []string{"The comments of the design or their reactions could not be read or written", "The events of the design could not be read"}, []string{"Verify that the database of Meshery Server is reachable"})
+}
+
+func ErrDesignFork(err error, design string) error {
+	return errors.New(ErrDesignForkCode, errors.Alert, []string{fmt.Sprintf("Failed to process the forks or the merge requests of the design %s", design)}, []string{err.Error()}, []string{"The lineage of the design or its merge requests could not be read or written", "The design files of a merge request could not be parsed"}, []string{"Verify that the database of Meshery Server is reachable", "Verify that the design and its fork are in the current design format"})
+}
+
+func ErrDashboard(err error, dashboard string) error {
+	return errors.New(ErrDashboardCode, errors.Alert, []string{fmt.Sprintf("Failed to process the dashboard %s", dashboard)}, []string{err.Error()}, []string{"The dashboards could not be read or written"}, []string{"Verify that the database of Meshery Server is reachable"})
+}
+
+func ErrRegistryUpdate(err error, source string) error {
+	return errors.New(ErrRegistryUpdateCode, errors.Alert, []string{fmt.Sprintf("Failed to update the registry from the %s source", source)}, []string{err.Error()}, []string{"The spreadsheet or the CSV could not be downloaded or parsed", "The OCI artifact could not be pulled", "The components of the registry could not be updated"}, []string{"Verify that the spreadsheet is published and the CSV has the columns of the Components sheet", "Verify that the OCI reference exists and is reachable from Meshery Server", "Verify that the database of Meshery Server is reachable"})
+}
@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
 	"plugin"
+	"strings"
 	"sync"
 
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+
 	"github.com/layer5io/meshery/server/extensions"
 	"github.com/layer5io/meshery/server/models"
 )
@@ -18,6 +23,12 @@ var (
 	mx                sync.Mutex
 )
 
+const (
+	extensionsBasePath = "/api/extensions/"
+	// ExtensionTokenHeader carries the token scoped to the extension being called.
+	ExtensionTokenHeader = "X-Meshery-Extension-Token"
+)
+
 // Defines the version metadata for the extension
 type ExtensionVersion struct {
 	Version string `json:"version,omitempty"`
@@ -71,6 +82,12 @@ func (h *Handler) LoadExtensionFromPackage(_ http.ResponseWriter, _ *http.Reques
 		extendedEndpoints[output.Router.Path] = output.Router
 	}
 
+	if output.Extension != nil {
+		if err := h.ExtensionRegistry.Register(output.Extension); err != nil {
+			return ErrRegisterExtension(err)
+		}
+	}
+
 	return nil
 }
 
@@ -100,10 +117,17 @@ func (h *Handler) ExtensionsVersionHandler(w http.ResponseWriter, _ *http.Reques
 }
 
 /*
-* ExtensionsHandler is a handler function which works as a proxy to resolve the
-* request of any extension point to its remote provider
+* ExtensionsHandler serves the routes of extensions registered with the server under
+* /api/extensions/{name}. Requests to those routes must carry a token issued for the extension.
+* Requests to any other extension point are proxied to the remote provider.
  */
-func (h *Handler) ExtensionsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+func (h *Handler) ExtensionsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	name, routePath, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, extensionsBasePath), "/")
+	if _, ok := h.ExtensionRegistry.Get(name); ok {
+		h.serveRegisteredExtension(w, req, user, name, routePath)
+		return
+	}
+
 	resp, err := provider.ExtensionProxy(req)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error: %v", err.Error()), http.StatusInternalServerError)
@@ -114,3 +138,68 @@ func (h *Handler) ExtensionsHandler(w http.ResponseWriter, req *http.Request, _
 	w.WriteHeader(resp.StatusCode)
 	fmt.Fprint(w, string(resp.Body))
 }
+
+// serveRegisteredExtension calls the route of the extension when the request carries a token issued to the
+// user for the extension, which is granted the scopes of the route.
+func (h *Handler) serveRegisteredExtension(w http.ResponseWriter, req *http.Request, user *models.User, name, routePath string) {
+	token, ok := h.ExtensionRegistry.ValidateToken(name, req.Header.Get(ExtensionTokenHeader), uuid.FromStringOrNil(user.ID))
+	if !ok {
+		http.Error(w, ErrInvalidExtensionToken(name).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	route, ok := h.ExtensionRegistry.Route(name, routePath, req.Method)
+	if !ok {
+		http.Error(w, "Invalid endpoint", http.StatusNotFound)
+		return
+	}
+	if !token.HasScopes(route.Scopes...) {
+		err := ErrExtensionScope(name, route.Scopes)
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	route.Handler.ServeHTTP(w, extensions.WithToken(req, token))
+}
+
+// swagger:route GET /api/extension/{name}/token ExtensionsAPI idGetExtensionToken
+// Handle GET request for an extension token
+//
+// Issues a token for the current user scoped to the routes of the named extension, granted
+// the scopes the extension grants to any user and to the roles of the user.
+// The token is sent in the X-Meshery-Extension-Token header when calling /api/extensions/{name}.
+// responses:
+// 	200: extensionTokenResponseWrapper
+
+func (h *Handler) ExtensionTokenHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, _ models.Provider) {
+	name := mux.Vars(req)["name"]
+
+	token, err := h.ExtensionRegistry.IssueToken(name, uuid.FromStringOrNil(user.ID), user.RoleNames)
+	if err != nil {
+		h.log.Error(ErrIssueExtensionToken(err, name))
+		http.Error(w, ErrIssueExtensionToken(err, name).Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		h.log.Error(models.ErrEncoding(err, "extension token"))
+		http.Error(w, models.ErrEncoding(err, "extension token").Error(), http.StatusInternalServerError)
+	}
+}
+
+// dispatchDesignHook notifies the extensions subscribed to a design lifecycle event.
+func (h *Handler) dispatchDesignHook(hook extensions.Hook, userID uuid.UUID, designID *uuid.UUID, designName string, metadata map[string]interface{}) {
+	event := extensions.HookEvent{
+		Hook:       hook,
+		UserID:     userID,
+		DesignName: designName,
+		Metadata:   metadata,
+	}
+	if designID != nil {
+		event.DesignID = *designID
+	}
+	h.ExtensionRegistry.Dispatch(context.Background(), event, func(name string, err error) {
+		h.log.Warn(ErrExtensionHook(err, name, string(hook)))
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+)
+
+func TestServeRegisteredExtension(t *testing.T) {
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{log: log, ExtensionRegistry: extensions.NewRegistry()}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	err = h.ExtensionRegistry.Register(&extensions.Extension{
+		Name:   "test",
+		Scopes: []string{"designs:read"},
+		Routes: []extensions.Route{
+			{Path: "/designs", Methods: []string{http.MethodGet}, Handler: ok, Scopes: []string{"designs:read"}},
+			{Path: "/designs", Methods: []string{http.MethodPost}, Handler: ok, Scopes: []string{"designs:write"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := uuid.Must(uuid.NewV4())
+	token, err := h.ExtensionRegistry.IssueToken("test", owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		user   uuid.UUID
+		token  string
+		want   int
+	}{
+		{name: "granted", method: http.MethodGet, user: owner, token: token.Value, want: http.StatusOK},
+		{name: "scope not granted", method: http.MethodPost, user: owner, token: token.Value, want: http.StatusForbidden},
+		{name: "token of another user", method: http.MethodGet, user: uuid.Must(uuid.NewV4()), token: token.Value, want: http.StatusUnauthorized},
+		{name: "no token", method: http.MethodGet, user: owner, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, extensionsBasePath+"test/designs", nil)
+			if tt.token != "" {
+				req.Header.Set(ExtensionTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.serveRegisteredExtension(rec, req, &models.User{ID: tt.user.String()}, "test", "designs")
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@
 package handlers
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
 	"github.com/layer5io/meshery/server/machines"
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshkit/broker"
@@ -32,6 +36,18 @@ type Handler struct {
 	EventsBuffer                            *events.EventStreamer
 	Rego                                    *policies.Rego
 	ConnectionToStateMachineInstanceTracker *machines.ConnectionToStateMachineInstanceTracker
+	ExtensionRegistry                       *extensions.Registry
+	// workflowExecutions maps the ids of workflow runs in progress to their executions.
+	workflowExecutions sync.Map
+	// registryUpdates maps the ids of registry updates to the updates, running or done.
+	registryUpdates sync.Map
+	// registryUpdateRunning is set while a registry update runs, updates running one at a time.
+	registryUpdateRunning atomic.Bool
+	// registryProgressMx is held by the registry operation the progress of the mesheryctl pipeline is forwarded for.
+	registryProgressMx sync.Mutex
+	// admissionPolicies is the prepared policy query of the admission webhook, guarded by admissionPoliciesMx.
+	admissionPolicies   *models.DesignPolicyEvaluator
+	admissionPoliciesMx sync.Mutex
 }
 
 // NewHandlerInstance returns a Handler instance
@@ -64,6 +80,16 @@ func NewHandlerInstance(
 		Rego:                                    rego,
 		SystemID:                                viper.Get("INSTANCE_ID").(*uuid.UUID),
 		ConnectionToStateMachineInstanceTracker: connToInstanceTracker,
+		ExtensionRegistry:                       extensions.NewRegistry(),
+	}
+
+	if dbHandler != nil {
+		go h.runEphemeralEnvironmentReaper()
+		go h.failInterruptedWorkflowRuns()
+		go h.runAlertRuleEvaluator()
+		if err := h.ExtensionRegistry.Register(h.backstageExtension()); err != nil {
+			logger.Warn(err)
+		}
 	}
 
 	h.task = taskq.RegisterTask(&taskq.TaskOptions{
@@ -0,0 +1,787 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/spf13/viper"
+)
+
+// managementAPIPageSize is the size of the pages requested from the provider when listing every resource.
+const managementAPIPageSize = 100
+
+// swagger:route GET /api/management/v1/designs ManagementAPI idGetManagedDesigns
+// Handle GET request for every design, ordered by id
+//
+// responses:
+// 	200: managedDesignsResponseWrapper
+
+func (h *Handler) GetManagedDesignsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+
+	designs := []models.ManagedDesign{}
+	for page := 0; ; page++ {
+		resp, err := provider.GetMesheryPatterns(token, fmt.Sprint(page), fmt.Sprint(managementAPIPageSize), "", "", "", nil, "false", "false")
+		if err != nil {
+			h.log.Error(ErrManagementAPI(err, "designs"))
+			http.Error(rw, ErrManagementAPI(err, "designs").Error(), http.StatusInternalServerError)
+			return
+		}
+		var patternPage models.MesheryPatternPage
+		if err := json.Unmarshal(resp, &patternPage); err != nil {
+			h.log.Error(models.ErrUnmarshal(err, "designs"))
+			http.Error(rw, models.ErrUnmarshal(err, "designs").Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, design := range patternPage.Patterns {
+			if design != nil && design.ID != nil {
+				designs = append(designs, models.NewManagedDesign(design))
+			}
+		}
+		if len(patternPage.Patterns) < managementAPIPageSize || len(designs) >= patternPage.TotalCount {
+			break
+		}
+	}
+	sort.Slice(designs, func(i, j int) bool { return designs[i].ID.String() < designs[j].ID.String() })
+
+	h.writeJSONResource(rw, http.StatusOK, designs, "designs")
+}
+
+// swagger:route GET /api/management/v1/designs/{id} ManagementAPI idGetManagedDesign
+// Handle GET request for a design
+//
+// Responds with 404 when the design does not exist.
+// responses:
+// 	200: managedDesignResponseWrapper
+
+func (h *Handler) GetManagedDesignHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	design, err := h.getManagedDesign(r, provider, id)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "design").Error(), http.StatusNotFound)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, models.NewManagedDesign(design), "design")
+}
+
+// swagger:route POST /api/management/v1/designs ManagementAPI idCreateManagedDesign
+// Handle POST request to create a design
+//
+// responses:
+// 	201: managedDesignResponseWrapper
+
+func (h *Handler) CreateManagedDesignHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.saveManagedDesign(rw, r, user, provider, nil)
+}
+
+// swagger:route PUT /api/management/v1/designs/{id} ManagementAPI idUpdateManagedDesign
+// Handle PUT request to replace the name and pattern file of a design
+//
+// Responds with 404 when the design does not exist.
+// responses:
+// 	200: managedDesignResponseWrapper
+
+func (h *Handler) UpdateManagedDesignHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	existing, err := h.getManagedDesign(r, provider, id)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "design").Error(), http.StatusNotFound)
+		return
+	}
+	h.saveManagedDesign(rw, r, user, provider, existing)
+}
+
+// swagger:route DELETE /api/management/v1/designs/{id} ManagementAPI idDeleteManagedDesign
+// Handle DELETE request to delete a design
+//
+// Responds with 404 when the design does not exist.
+// responses:
+// 	204: noContentWrapper
+
+func (h *Handler) DeleteManagedDesignHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	design, err := h.getManagedDesign(r, provider, id)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "design").Error(), http.StatusNotFound)
+		return
+	}
+	if _, err := provider.DeleteMesheryPattern(r, id.String()); err != nil {
+		h.log.Error(ErrManagementAPI(err, "design"))
+		http.Error(rw, ErrManagementAPI(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.dispatchDesignHook(extensions.HookDesignDeleted, uuid.FromStringOrNil(user.ID), &id, design.Name, nil)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// swagger:route GET /api/management/v1/environments ManagementAPI idGetManagedEnvironments
+// Handle GET request for every environment and the connections assigned to it, ordered by id
+//
+// ```?orgID={orgID}``` orgID is the organization of the environments, required by remote providers
+// responses:
+// 	200: managedEnvironmentsResponseWrapper
+
+func (h *Handler) GetManagedEnvironmentsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+
+	resp, err := provider.GetEnvironments(token, "0", "all", "", "", "", r.URL.Query().Get("orgID"))
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "environments"))
+		http.Error(rw, ErrManagementAPI(err, "environments").Error(), http.StatusInternalServerError)
+		return
+	}
+	var envPage v1beta1.EnvironmentPage
+	if err := json.Unmarshal(resp, &envPage); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "environments"))
+		http.Error(rw, models.ErrUnmarshal(err, "environments").Error(), http.StatusInternalServerError)
+		return
+	}
+
+	envs := make([]models.ManagedEnvironment, 0, len(envPage.Environments))
+	for i := range envPage.Environments {
+		env := &envPage.Environments[i]
+		conns, err := h.getManagedEnvironmentConnections(r, provider, env.ID)
+		if err != nil {
+			h.log.Error(ErrManagementAPI(err, "environments"))
+			http.Error(rw, ErrManagementAPI(err, "environments").Error(), http.StatusInternalServerError)
+			return
+		}
+		envs = append(envs, models.NewManagedEnvironment(env, conns))
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].ID.String() < envs[j].ID.String() })
+
+	h.writeJSONResource(rw, http.StatusOK, envs, "environments")
+}
+
+// swagger:route GET /api/management/v1/environments/{id} ManagementAPI idGetManagedEnvironment
+// Handle GET request for an environment and the connections assigned to it
+//
+// Responds with 404 when the environment does not exist.
+// responses:
+// 	200: managedEnvironmentResponseWrapper
+
+func (h *Handler) GetManagedEnvironmentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	env, err := h.getManagedEnvironment(r, provider, id)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusNotFound)
+		return
+	}
+	h.writeManagedEnvironment(rw, r, provider, env, http.StatusOK)
+}
+
+// swagger:route POST /api/management/v1/environments ManagementAPI idCreateManagedEnvironment
+// Handle POST request to create an environment and assign connections to it
+//
+// responses:
+// 	201: managedEnvironmentResponseWrapper
+
+func (h *Handler) CreateManagedEnvironmentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	var req models.ManagedEnvironmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("name is required")).Error(), http.StatusBadRequest)
+		return
+	}
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+
+	resp, err := provider.SaveEnvironment(r, &v1beta1.EnvironmentPayload{
+		OrgId:       req.OrganizationID.String(),
+		Name:        req.Name,
+		Description: req.Description,
+	}, token, false)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+	env := &v1beta1.Environment{}
+	if err := json.Unmarshal(resp, env); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "environment"))
+		http.Error(rw, models.ErrUnmarshal(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.reconcileManagedEnvironmentConnections(r, provider, env.ID, req.ConnectionIDs); err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeManagedEnvironment(rw, r, provider, env, http.StatusCreated)
+}
+
+// swagger:route PUT /api/management/v1/environments/{id} ManagementAPI idUpdateManagedEnvironment
+// Handle PUT request to replace an environment and the connections assigned to it
+//
+// Connections missing from connection_ids are removed from the environment.
+// Responds with 404 when the environment does not exist.
+// responses:
+// 	200: managedEnvironmentResponseWrapper
+
+func (h *Handler) UpdateManagedEnvironmentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := h.getManagedEnvironment(r, provider, id); err != nil {
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusNotFound)
+		return
+	}
+
+	var req models.ManagedEnvironmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		http.Error(rw, ErrRequestBody(fmt.Errorf("name is required")).Error(), http.StatusBadRequest)
+		return
+	}
+
+	env, err := provider.UpdateEnvironment(r, &v1beta1.EnvironmentPayload{
+		OrgId:       req.OrganizationID.String(),
+		Name:        req.Name,
+		Description: req.Description,
+	}, id.String())
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.reconcileManagedEnvironmentConnections(r, provider, id, req.ConnectionIDs); err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeManagedEnvironment(rw, r, provider, env, http.StatusOK)
+}
+
+// swagger:route DELETE /api/management/v1/environments/{id} ManagementAPI idDeleteManagedEnvironment
+// Handle DELETE request to delete an environment
+//
+// Responds with 404 when the environment does not exist.
+// responses:
+// 	204: noContentWrapper
+
+func (h *Handler) DeleteManagedEnvironmentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := h.getManagedEnvironment(r, provider, id); err != nil {
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusNotFound)
+		return
+	}
+	if _, err := provider.DeleteEnvironment(r, id.String()); err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// swagger:route GET /api/management/v1/connections ManagementAPI idGetManagedConnections
+// Handle GET request for every connection, ordered by id
+//
+// Connections are read only, they are referenced by environments.
+// responses:
+// 	200: managedConnectionsResponseWrapper
+
+func (h *Handler) GetManagedConnectionsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	conns, err := h.getManagedConnections(r, user, provider)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "connections"))
+		http.Error(rw, ErrManagementAPI(err, "connections").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, conns, "connections")
+}
+
+// swagger:route GET /api/management/v1/connections/{id} ManagementAPI idGetManagedConnection
+// Handle GET request for a connection
+//
+// Responds with 404 when the connection does not exist.
+// responses:
+// 	200: managedConnectionResponseWrapper
+
+func (h *Handler) GetManagedConnectionHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+	conns, err := h.getManagedConnections(r, user, provider)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "connection"))
+		http.Error(rw, ErrManagementAPI(err, "connection").Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, conn := range conns {
+		if conn.ID == id {
+			h.writeJSONResource(rw, http.StatusOK, conn, "connection")
+			return
+		}
+	}
+	http.Error(rw, ErrManagementAPI(fmt.Errorf("connection %s does not exist", id), "connection").Error(), http.StatusNotFound)
+}
+
+// swagger:route GET /api/management/v1/deployments ManagementAPI idGetManagedDeployments
+// Handle GET request for the designs deployed by the user through the management API, ordered by id
+//
+// responses:
+// 	200: managedDeploymentsResponseWrapper
+
+func (h *Handler) GetManagedDeploymentsHandler(rw http.ResponseWriter, _ *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	mdp := &models.ManagedDeploymentPersister{DB: provider.GetGenericPersister()}
+	deployments, err := mdp.List(uuid.FromStringOrNil(user.ID))
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "deployments"))
+		http.Error(rw, ErrManagementAPI(err, "deployments").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, deployments, "deployments")
+}
+
+// swagger:route GET /api/management/v1/deployments/{id} ManagementAPI idGetManagedDeployment
+// Handle GET request for a deployment
+//
+// The content_hash of the deployment differs from the one of its design when the design
+// changed since it was deployed. Responds with 404 when the deployment does not exist or belongs to another user.
+// responses:
+// 	200: managedDeploymentResponseWrapper
+
+func (h *Handler) GetManagedDeploymentHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	deployment, ok := h.managedDeploymentFromRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, deployment, "deployment")
+}
+
+// swagger:route POST /api/management/v1/deployments ManagementAPI idCreateManagedDeployment
+// Handle POST request to deploy a design to the Kubernetes connections of an environment
+//
+// When the deployment fails, it is still created, as failed along with a diagnosis of the failure,
+// so that it can be tracked and redeployed. The response is then 400 with the created deployment.
+// responses:
+// 	201: managedDeploymentResponseWrapper
+
+func (h *Handler) CreateManagedDeploymentHandler(rw http.ResponseWriter, r *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	var req models.ManagedDeploymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	design, err := h.getManagedDesign(r, provider, req.DesignID)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusBadRequest)
+		return
+	}
+
+	deployment := &models.ManagedDeployment{
+		DesignID:      req.DesignID,
+		EnvironmentID: req.EnvironmentID,
+		ContentHash:   models.DesignContentHash(design.PatternFile),
+		Status:        models.ManagedDeploymentDeployed,
+		UserID:        uuid.FromStringOrNil(user.ID),
+	}
+	deployErr := h.deployManagedDesign(r, provider, prefObj, user, design, req.EnvironmentID, false, req.OwnershipOverrideRequest)
+	if deployErr != nil {
+		h.log.Error(deployErr)
+		deployment.Status = models.ManagedDeploymentFailed
+		deployment.Message = deployErr.Error()
+		deployment.Diagnosis = h.diagnoseManagedDeployment(r, provider, design, req.EnvironmentID, deployErr)
+	}
+
+	mdp := &models.ManagedDeploymentPersister{DB: provider.GetGenericPersister()}
+	if err := mdp.Save(deployment); err != nil {
+		h.log.Error(ErrManagementAPI(err, "deployment"))
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusInternalServerError)
+		return
+	}
+	if deployErr != nil {
+		h.writeJSONResource(rw, http.StatusBadRequest, deployment, "deployment")
+		return
+	}
+	h.writeJSONResource(rw, http.StatusCreated, deployment, "deployment")
+}
+
+// swagger:route PUT /api/management/v1/deployments/{id} ManagementAPI idUpdateManagedDeployment
+// Handle PUT request to redeploy a design
+//
+// The current version of the design is deployed. When the design or the environment changed,
+// the previous design is undeployed from the previous environment first. When the deployment fails,
+// it is recorded as failed along with a diagnosis of the failure.
+// Responds with 404 when the deployment does not exist or belongs to another user.
+// responses:
+// 	200: managedDeploymentResponseWrapper
+
+func (h *Handler) UpdateManagedDeploymentHandler(rw http.ResponseWriter, r *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	deployment, ok := h.managedDeploymentFromRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	var req models.ManagedDeploymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	design, err := h.getManagedDesign(r, provider, req.DesignID)
+	if err != nil {
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusBadRequest)
+		return
+	}
+
+	if deployment.DesignID != req.DesignID || deployment.EnvironmentID != req.EnvironmentID {
+		if previous, err := h.getManagedDesign(r, provider, deployment.DesignID); err == nil {
+			if err := h.deployManagedDesign(r, provider, prefObj, user, previous, deployment.EnvironmentID, true, models.OwnershipOverrideRequest{}); err != nil {
+				h.log.Warn(err)
+			}
+		}
+	}
+
+	deployment.DesignID = req.DesignID
+	deployment.EnvironmentID = req.EnvironmentID
+	deployment.ContentHash = models.DesignContentHash(design.PatternFile)
+	deployment.Status = models.ManagedDeploymentDeployed
+	deployment.Message = ""
+	deployment.Diagnosis = nil
+	deployErr := h.deployManagedDesign(r, provider, prefObj, user, design, req.EnvironmentID, false, req.OwnershipOverrideRequest)
+	if deployErr != nil {
+		h.log.Error(deployErr)
+		deployment.Status = models.ManagedDeploymentFailed
+		deployment.Message = deployErr.Error()
+		deployment.Diagnosis = h.diagnoseManagedDeployment(r, provider, design, req.EnvironmentID, deployErr)
+	}
+
+	mdp := &models.ManagedDeploymentPersister{DB: provider.GetGenericPersister()}
+	if err := mdp.Save(deployment); err != nil {
+		h.log.Error(ErrManagementAPI(err, "deployment"))
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusInternalServerError)
+		return
+	}
+	if deployErr != nil {
+		http.Error(rw, deployErr.Error(), http.StatusBadRequest)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, deployment, "deployment")
+}
+
+// swagger:route DELETE /api/management/v1/deployments/{id} ManagementAPI idDeleteManagedDeployment
+// Handle DELETE request to undeploy a design
+//
+// When the design no longer exists, the deployment is only forgotten.
+// Responds with 404 when the deployment does not exist or belongs to another user.
+// responses:
+// 	204: noContentWrapper
+
+func (h *Handler) DeleteManagedDeploymentHandler(rw http.ResponseWriter, r *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	deployment, ok := h.managedDeploymentFromRequest(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	if design, err := h.getManagedDesign(r, provider, deployment.DesignID); err == nil {
+		if err := h.deployManagedDesign(r, provider, prefObj, user, design, deployment.EnvironmentID, true, models.OwnershipOverrideRequest{}); err != nil {
+			h.log.Error(err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	mdp := &models.ManagedDeploymentPersister{DB: provider.GetGenericPersister()}
+	if err := mdp.Delete(deployment.ID); err != nil {
+		h.log.Error(ErrManagementAPI(err, "deployment"))
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) saveManagedDesign(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, existing *models.MesheryPattern) {
+	var req models.ManagedDesignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	patternFile, err := core.NewPatternFile([]byte(req.PatternFile))
+	if err != nil {
+		http.Error(rw, ErrParsePattern(err).Error(), http.StatusBadRequest)
+		return
+	}
+	name := req.Name
+	if name == "" {
+		name = patternFile.Name
+	}
+
+	design := &models.MesheryPattern{
+		Name:        name,
+		PatternFile: req.PatternFile,
+		Visibility:  models.Private,
+	}
+	status := http.StatusCreated
+	if existing != nil {
+		design.ID = existing.ID
+		design.Visibility = existing.Visibility
+		design.CatalogData = existing.CatalogData
+		status = http.StatusOK
+	}
+
+	token, _ := r.Context().Value(models.TokenCtxKey).(string)
+	resp, err := provider.SaveMesheryPattern(token, design)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "design"))
+		http.Error(rw, ErrManagementAPI(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	saved := []models.MesheryPattern{}
+	if err := json.Unmarshal(resp, &saved); err != nil || len(saved) == 0 {
+		if err == nil {
+			err = fmt.Errorf("the provider did not return the saved design")
+		}
+		h.log.Error(models.ErrUnmarshal(err, "design"))
+		http.Error(rw, models.ErrUnmarshal(err, "design").Error(), http.StatusInternalServerError)
+		return
+	}
+	go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
+	h.dispatchDesignHook(extensions.HookDesignSaved, uuid.FromStringOrNil(user.ID), saved[0].ID, saved[0].Name, nil)
+
+	h.writeJSONResource(rw, status, models.NewManagedDesign(&saved[0]), "design")
+}
+
+func (h *Handler) getManagedDesign(r *http.Request, provider models.Provider, id uuid.UUID) (*models.MesheryPattern, error) {
+	resp, err := provider.GetMesheryPattern(r, id.String(), "false")
+	if err != nil {
+		return nil, err
+	}
+	design := &models.MesheryPattern{}
+	if err := json.Unmarshal(resp, design); err != nil {
+		return nil, models.ErrUnmarshal(err, "design")
+	}
+	if design.ID == nil || *design.ID != id {
+		return nil, fmt.Errorf("design %s does not exist", id)
+	}
+	return design, nil
+}
+
+func (h *Handler) getManagedEnvironment(r *http.Request, provider models.Provider, id uuid.UUID) (*v1beta1.Environment, error) {
+	resp, err := provider.GetEnvironmentByID(r, id.String(), r.URL.Query().Get("orgID"))
+	if err != nil {
+		return nil, err
+	}
+	env := &v1beta1.Environment{}
+	if err := json.Unmarshal(resp, env); err != nil {
+		return nil, models.ErrUnmarshal(err, "environment")
+	}
+	if env.ID != id {
+		return nil, fmt.Errorf("environment %s does not exist", id)
+	}
+	return env, nil
+}
+
+func (h *Handler) getManagedEnvironmentConnections(r *http.Request, provider models.Provider, envID uuid.UUID) ([]*connections.Connection, error) {
+	resp, err := provider.GetConnectionsOfEnvironment(r, envID.String(), "0", "all", "", "", `{"assigned":true}`)
+	if err != nil {
+		return nil, err
+	}
+	connectionPage := connections.ConnectionPage{}
+	if err := json.Unmarshal(resp, &connectionPage); err != nil {
+		return nil, models.ErrUnmarshal(err, "connections")
+	}
+	return connectionPage.Connections, nil
+}
+
+// reconcileManagedEnvironmentConnections assigns exactly the given connections to the environment.
+func (h *Handler) reconcileManagedEnvironmentConnections(r *http.Request, provider models.Provider, envID uuid.UUID, connectionIDs []uuid.UUID) error {
+	current, err := h.getManagedEnvironmentConnections(r, provider, envID)
+	if err != nil {
+		return err
+	}
+	assigned := map[uuid.UUID]bool{}
+	for _, conn := range current {
+		if conn != nil {
+			assigned[conn.ID] = true
+		}
+	}
+	desired := map[uuid.UUID]bool{}
+	for _, id := range connectionIDs {
+		desired[id] = true
+		if !assigned[id] {
+			if _, err := provider.AddConnectionToEnvironment(r, envID.String(), id.String()); err != nil {
+				return err
+			}
+		}
+	}
+	for id := range assigned {
+		if !desired[id] {
+			if _, err := provider.RemoveConnectionFromEnvironment(r, envID.String(), id.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) writeManagedEnvironment(rw http.ResponseWriter, r *http.Request, provider models.Provider, env *v1beta1.Environment, status int) {
+	conns, err := h.getManagedEnvironmentConnections(r, provider, env.ID)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "environment"))
+		http.Error(rw, ErrManagementAPI(err, "environment").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, status, models.NewManagedEnvironment(env, conns), "environment")
+}
+
+func (h *Handler) getManagedConnections(r *http.Request, user *models.User, provider models.Provider) ([]models.ManagedConnection, error) {
+	conns := []models.ManagedConnection{}
+	for page := 0; ; page++ {
+		connectionPage, err := provider.GetConnections(r, user.ID, page, managementAPIPageSize, "", "", "", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, conn := range connectionPage.Connections {
+			if conn != nil {
+				conns = append(conns, models.NewManagedConnection(conn))
+			}
+		}
+		if len(connectionPage.Connections) < managementAPIPageSize || len(conns) >= connectionPage.TotalCount {
+			break
+		}
+	}
+	sort.Slice(conns, func(i, j int) bool { return conns[i].ID.String() < conns[j].ID.String() })
+	return conns, nil
+}
+
+// managedDeploymentFromRequest returns the deployment of the request, writing the error response if it is not a deployment of the user.
+func (h *Handler) managedDeploymentFromRequest(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.ManagedDeployment, bool) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	mdp := &models.ManagedDeploymentPersister{DB: provider.GetGenericPersister()}
+	deployment, err := mdp.Get(uuid.FromStringOrNil(user.ID), id)
+	if err != nil {
+		h.log.Error(ErrManagementAPI(err, "deployment"))
+		http.Error(rw, ErrManagementAPI(err, "deployment").Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if deployment == nil {
+		http.Error(rw, ErrManagementAPI(fmt.Errorf("deployment %s does not exist", id), "deployment").Error(), http.StatusNotFound)
+		return nil, false
+	}
+	return deployment, true
+}
+
+// deployManagedDesign deploys, or undeploys, the design to the connected Kubernetes clusters of the connections of the environment.
+// Resources owned by other designs are only modified, or removed, when their ownership is overridden.
+func (h *Handler) deployManagedDesign(r *http.Request, provider models.Provider, prefObj *models.Preference, user *models.User, design *models.MesheryPattern, envID uuid.UUID, isDelete bool, override models.OwnershipOverrideRequest) error {
+	k8sContexts, err := h.getManagedEnvironmentKubeContexts(r, provider, envID)
+	if err != nil {
+		return ErrManagedDeployment(err, design.Name)
+	}
+
+	patternFile, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		return ErrManagedDeployment(err, design.Name)
+	}
+	if design.ID != nil {
+		patternFile.Id = *design.ID
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	if err := h.checkDesignOwnership(provider, &patternFile, k8sContexts, userID, override); err != nil {
+		return ErrManagedDeployment(err, design.Name)
+	}
+
+	ctx := context.WithValue(r.Context(), models.KubeClustersKey, k8sContexts)
+	_, err = _processPattern(&core.ProcessPatternOptions{
+		Context:          ctx,
+		Provider:         provider,
+		Pattern:          patternFile,
+		PrefObj:          prefObj,
+		UserID:           user.ID,
+		IsDelete:         isDelete,
+		SkipPrintLogs:    viper.GetBool("DEBUG"),
+		Registry:         h.registryManager,
+		EventBroadcaster: h.config.EventBroadcaster,
+		Log:              h.log,
+	})
+	if err != nil {
+		return ErrManagedDeployment(err, design.Name)
+	}
+
+	hook := extensions.HookDesignDeployed
+	if isDelete {
+		hook = extensions.HookDesignUndeployed
+	}
+	h.dispatchDesignHook(hook, userID, design.ID, design.Name, map[string]interface{}{
+		"environment_id": envID.String(),
+	})
+	h.recordDesignOwnership(provider, &patternFile, k8sContexts, userID, isDelete)
+	return nil
+}
+
+// getManagedEnvironmentKubeContexts returns the connected Kubernetes clusters of the connections of the environment.
+func (h *Handler) getManagedEnvironmentKubeContexts(r *http.Request, provider models.Provider, envID uuid.UUID) ([]models.K8sContext, error) {
+	conns, err := h.getManagedEnvironmentConnections(r, provider, envID)
+	if err != nil {
+		return nil, err
+	}
+	connectionIDs := map[string]bool{}
+	for _, conn := range conns {
+		if conn != nil {
+			connectionIDs[conn.ID.String()] = true
+		}
+	}
+
+	connected, _ := r.Context().Value(models.AllKubeClusterKey).([]*models.K8sContext)
+	k8sContexts := []models.K8sContext{}
+	for _, k8sContext := range connected {
+		if k8sContext != nil && connectionIDs[k8sContext.ConnectionID] {
+			k8sContexts = append(k8sContexts, *k8sContext)
+		}
+	}
+	if len(k8sContexts) == 0 {
+		return nil, fmt.Errorf("environment %s has no connected Kubernetes connection", envID)
+	}
+	return k8sContexts, nil
+}
+
+// diagnoseManagedDeployment diagnoses the failed deployment of the design to the environment. When the
+// environment cannot be reached, or the design cannot be parsed, only the deployment error is diagnosed.
+func (h *Handler) diagnoseManagedDeployment(r *http.Request, provider models.Provider, design *models.MesheryPattern, envID uuid.UUID, deployErr error) *models.DeploymentDiagnosis {
+	k8sContexts, _ := h.getManagedEnvironmentKubeContexts(r, provider, envID)
+	patternFile, _ := core.NewPatternFile([]byte(design.PatternFile))
+	return h.diagnoseDesignDeployment(r.Context(), &patternFile, k8sContexts, deployErr)
+}
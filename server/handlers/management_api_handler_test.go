@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+func newManagementAPITestHandler(t *testing.T) (*Handler, *models.DefaultLocalProvider) {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.ManagedDeployment{}); err != nil {
+		t.Fatal(err)
+	}
+	return h, &models.DefaultLocalProvider{GenericPersister: h.dbHandler}
+}
+
+func TestGetManagedDeploymentHandler(t *testing.T) {
+	h, provider := newManagementAPITestHandler(t)
+	mdp := &models.ManagedDeploymentPersister{DB: h.dbHandler}
+
+	owner := uuid.Must(uuid.NewV4())
+	deployment := &models.ManagedDeployment{
+		DesignID:      uuid.Must(uuid.NewV4()),
+		EnvironmentID: uuid.Must(uuid.NewV4()),
+		Status:        models.ManagedDeploymentDeployed,
+		UserID:        owner,
+	}
+	if err := mdp.Save(deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		user uuid.UUID
+		id   string
+		want int
+	}{
+		{name: "owner", user: owner, id: deployment.ID.String(), want: http.StatusOK},
+		{name: "missing", user: owner, id: uuid.Must(uuid.NewV4()).String(), want: http.StatusNotFound},
+		{name: "another user", user: uuid.Must(uuid.NewV4()), id: deployment.ID.String(), want: http.StatusNotFound},
+		{name: "invalid id", user: owner, id: "invalid", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			rec := httptest.NewRecorder()
+			h.GetManagedDeploymentHandler(rec, req, nil, &models.User{ID: tt.user.String()}, provider)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			got := models.ManagedDeployment{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != deployment.ID || got.DesignID != deployment.DesignID {
+				t.Errorf("deployment = %+v, want %+v", got, deployment)
+			}
+		})
+	}
+}
+
+func TestGetManagedDeploymentsHandler(t *testing.T) {
+	h, provider := newManagementAPITestHandler(t)
+	mdp := &models.ManagedDeploymentPersister{DB: h.dbHandler}
+
+	owner := uuid.Must(uuid.NewV4())
+	others := uuid.Must(uuid.NewV4())
+	ids := map[uuid.UUID]bool{}
+	for i := 0; i < 5; i++ {
+		deployment := &models.ManagedDeployment{
+			DesignID:      uuid.Must(uuid.NewV4()),
+			EnvironmentID: uuid.Must(uuid.NewV4()),
+			Status:        models.ManagedDeploymentDeployed,
+			UserID:        owner,
+		}
+		if err := mdp.Save(deployment); err != nil {
+			t.Fatal(err)
+		}
+		ids[deployment.ID] = true
+	}
+	if err := mdp.Save(&models.ManagedDeployment{DesignID: uuid.Must(uuid.NewV4()), UserID: others}); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() []models.ManagedDeployment {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.GetManagedDeploymentsHandler(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil, &models.User{ID: owner.String()}, provider)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		deployments := []models.ManagedDeployment{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &deployments); err != nil {
+			t.Fatal(err)
+		}
+		return deployments
+	}
+
+	first := list()
+	if len(first) != len(ids) {
+		t.Fatalf("got %d deployments, want the %d of the user", len(first), len(ids))
+	}
+	for i, deployment := range first {
+		if !ids[deployment.ID] {
+			t.Errorf("deployment %s does not belong to the user", deployment.ID)
+		}
+		if i > 0 && first[i-1].ID.String() >= deployment.ID.String() {
+			t.Errorf("deployments are not ordered by id: %s before %s", first[i-1].ID, deployment.ID)
+		}
+	}
+	second := list()
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Fatalf("deployments are not listed in a deterministic order: %v, then %v", first, second)
+		}
+	}
+}
@@ -21,7 +21,7 @@ import (
 	"github.com/gofrs/uuid"
 	guid "github.com/google/uuid"
 	"github.com/gorilla/mux"
-	helpers "github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/extensions"
 	isql "github.com/layer5io/meshery/server/internal/sql"
 	"github.com/layer5io/meshery/server/meshes"
 	"github.com/layer5io/meshery/server/models"
@@ -41,11 +41,9 @@ import (
 	"github.com/layer5io/meshkit/utils"
 	"github.com/layer5io/meshkit/utils/catalog"
 	"github.com/layer5io/meshkit/utils/kubernetes"
-	"github.com/layer5io/meshkit/utils/kubernetes/kompose"
 	"github.com/layer5io/meshkit/utils/walker"
 
 	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
-	"github.com/meshery/schemas/models/v1alpha2"
 	"github.com/meshery/schemas/models/v1beta1/component"
 	"github.com/meshery/schemas/models/v1beta1/connection"
 	"github.com/meshery/schemas/models/v1beta1/pattern"
@@ -274,9 +272,9 @@ func (h *Handler) handlePatternPOST(
 				},
 			}
 		} else if sourcetype == string(models.DockerCompose) || sourcetype == string(models.K8sManifest) {
-			var k8sres string
+			var patternFile pattern.PatternFile
 			if sourcetype == string(models.DockerCompose) {
-				k8sres, err = kompose.Convert(bytPattern) // convert the docker compose file into kubernetes manifest
+				patternFile, err = pCore.NewPatternFileFromDockerCompose(bytPattern, fileName, h.registryManager)
 				if err != nil {
 					h.log.Error(ErrConvertingDockerComposeToDesign(err))
 					event := eventBuilder.WithSeverity(events.Error).WithMetadata(map[string]interface{}{
@@ -295,13 +293,12 @@ func (h *Handler) handlePatternPOST(
 					Valid:  true,
 				}
 			} else if sourcetype == string(models.K8sManifest) {
-				k8sres = string(bytPattern)
 				mesheryPattern.Type = sql.NullString{
 					String: string(models.K8sManifest),
 					Valid:  true,
 				}
+				patternFile, err = pCore.NewPatternFileFromK8sManifest(string(bytPattern), fileName, false, h.registryManager)
 			}
-			patternFile, err := pCore.NewPatternFileFromK8sManifest(k8sres, fileName, false, h.registryManager)
 			if err != nil {
 				h.log.Error(ErrConvertingK8sManifestToDesign(err))
 				event := eventBuilder.WithSeverity(events.Error).WithMetadata(map[string]interface{}{
@@ -405,6 +402,7 @@ func (h *Handler) handlePatternPOST(
 				// Create the event but do not notify the client immediately, as the evaluations are frequent and takes up the view area.
 				// go h.config.EventBroadcaster.Publish(userID, event)
 				go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
+				h.dispatchDesignHook(extensions.HookDesignSaved, userID, mesheryPattern.ID, mesheryPattern.Name, nil)
 				return
 			}
 
@@ -424,6 +422,82 @@ func (h *Handler) handlePatternPOST(
 		}
 	}
 
+	// Designs edited as a graph are converted back into design files
+	if parsedBody.CytoscapeJSON != "" {
+		patternFile, err := pCore.NewPatternFileFromCytoscapeJSJSON(parsedBody.Name, []byte(parsedBody.CytoscapeJSON))
+		if err != nil {
+			h.log.Error(ErrSavePattern(err))
+			http.Error(rw, ErrSavePattern(err).Error(), http.StatusBadRequest)
+
+			event := eventBuilder.WithSeverity(events.Error).WithMetadata(map[string]interface{}{
+				"error": ErrSavePattern(err),
+			}).WithDescription("Unable to create design from the graph.").Build()
+
+			_ = provider.PersistEvent(event)
+			go h.config.EventBroadcaster.Publish(userID, event)
+			return
+		}
+		// Marshalled as JSON, as the metadata of components keeps its additional properties, eg. dependsOn,
+		// only when encoded as JSON.
+		bytPattern, err := encoding.Marshal(patternFile)
+		if err != nil {
+			h.log.Error(ErrEncodePattern(err))
+			http.Error(rw, ErrEncodePattern(err).Error(), http.StatusInternalServerError)
+			return
+		}
+
+		mesheryPattern = &models.MesheryPattern{
+			Name:        patternFile.Name,
+			PatternFile: string(bytPattern),
+			Type: sql.NullString{
+				String: string(models.Design),
+				Valid:  true,
+			},
+			Location: map[string]interface{}{
+				"host":   "",
+				"path":   "",
+				"type":   "local",
+				"branch": "",
+			},
+		}
+
+		if parsedBody.Save {
+			resp, err := provider.SaveMesheryPattern(token, mesheryPattern)
+			if err != nil {
+				h.log.Error(ErrSavePattern(err))
+				http.Error(rw, ErrSavePattern(err).Error(), http.StatusInternalServerError)
+
+				event := eventBuilder.WithSeverity(events.Error).WithMetadata(map[string]interface{}{
+					"error": ErrSavePattern(err),
+				}).WithDescription(ErrSavePattern(err).Error()).Build()
+
+				_ = provider.PersistEvent(event)
+				go h.config.EventBroadcaster.Publish(userID, event)
+				return
+			}
+
+			h.formatPatternOutput(rw, resp, format, sourcetype, eventBuilder, parsedBody.URL, action)
+			event := eventBuilder.Build()
+			_ = provider.PersistEvent(event)
+			go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
+			return
+		}
+
+		byt, err := json.Marshal([]models.MesheryPattern{*mesheryPattern})
+		if err != nil {
+			h.log.Error(ErrEncodePattern(err))
+			http.Error(rw, ErrEncodePattern(err).Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		h.formatPatternOutput(rw, byt, format, sourcetype, eventBuilder, parsedBody.URL, action)
+		event := eventBuilder.Build()
+		_ = provider.PersistEvent(event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+		return
+	}
+
 	if parsedBody.URL != "" {
 		latestKuberVersion := getLatestKubeVersionFromRegistry(h.registryManager)
 		if sourcetype == string(models.HelmChart) {
@@ -730,18 +804,16 @@ func (h *Handler) VerifyAndConvertToDesign(
 		sourcetype := mesheryPattern.Type.String
 
 		if sourcetype == string(models.DockerCompose) || sourcetype == string(models.K8sManifest) {
-			var k8sres string
+			var pattern pattern.PatternFile
 			if sourcetype == string(models.DockerCompose) {
-				k8sres, err = kompose.Convert(sourceContent) // convert the docker compose file into kubernetes manifest
+				pattern, err = pCore.NewPatternFileFromDockerCompose(sourceContent, "", h.registryManager)
 				if err != nil {
 					err = ErrConvertingDockerComposeToDesign(err)
 					return err
 				}
-
 			} else if sourcetype == string(models.K8sManifest) {
-				k8sres = string(sourceContent)
+				pattern, err = pCore.NewPatternFileFromK8sManifest(string(sourceContent), "", false, h.registryManager)
 			}
-			pattern, err := pCore.NewPatternFileFromK8sManifest(k8sres, "", false, h.registryManager)
 			if err != nil {
 				err = ErrConvertingK8sManifestToDesign(err)
 				return err
@@ -872,17 +944,14 @@ func githubRepoDesignScan(
 		Root(path).
 		RegisterFileInterceptor(func(f walker.File) error {
 			ext := filepath.Ext(f.Name)
-			var k8sres string
-			var err error
-			k8sres = f.Content
 			if ext == ".yml" || ext == ".yaml" {
+				var pattern pattern.PatternFile
+				var err error
 				if sourceType == string(models.DockerCompose) {
-					k8sres, err = kompose.Convert([]byte(f.Content))
-					if err != nil {
-						return ErrRemoteApplication(err)
-					}
+					pattern, err = pCore.NewPatternFileFromDockerCompose([]byte(f.Content), "", reg)
+				} else {
+					pattern, err = pCore.NewPatternFileFromK8sManifest(f.Content, "", false, reg)
 				}
-				pattern, err := pCore.NewPatternFileFromK8sManifest(k8sres, "", false, reg)
 				if err != nil {
 					return err //always a meshkit error
 				}
@@ -936,15 +1005,13 @@ func genericHTTPDesignFile(fileURL, patternName, sourceType string, reg *meshmod
 
 	res := string(body)
 
+	var pattern pattern.PatternFile
 	if sourceType == string(models.DockerCompose) {
-		res, err = kompose.Convert(body)
+		pattern, err = pCore.NewPatternFileFromDockerCompose(body, "", reg)
 		if err != nil {
-			return nil, ErrRemoteApplication(err)
+			return nil, err //This error is already a meshkit error
 		}
-	}
-
-	var pattern pattern.PatternFile
-	if sourceType == string(models.DockerCompose) || sourceType == string(models.K8sManifest) {
+	} else if sourceType == string(models.K8sManifest) {
 		var err error
 		pattern, err = pCore.NewPatternFileFromK8sManifest(res, "", false, reg)
 		if err != nil {
@@ -1147,6 +1214,16 @@ func (h *Handler) DeleteMesheryPatternHandler(
 	event := eventBuilder.WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Pattern %s deleted.", mesheryPattern.Name)).Build()
 	_ = provider.PersistEvent(event)
 	go h.config.EventBroadcaster.Publish(userID, event)
+	deletedID := uuid.FromStringOrNil(patternID)
+	dp := &models.DesignCommentPersister{DB: provider.GetGenericPersister()}
+	if err := dp.DeleteDesignComments(deletedID); err != nil {
+		h.log.Warn(ErrDesignComment(err, mesheryPattern.Name))
+	}
+	fp := &models.DesignForkPersister{DB: provider.GetGenericPersister()}
+	if err := fp.DeleteDesign(deletedID); err != nil {
+		h.log.Warn(ErrDesignFork(err, mesheryPattern.Name))
+	}
+	h.dispatchDesignHook(extensions.HookDesignDeleted, userID, &deletedID, mesheryPattern.Name, nil)
 	go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -1157,8 +1234,11 @@ func (h *Handler) DeleteMesheryPatternHandler(
 // Handle GET request for Meshery Pattern with the given id
 //
 // ?oci={true|false} - If true, returns the pattern in OCI Artifact format
-// ?export={Kubernetes Manifest} - exports the pattern file in the specified design format
+// ?export={Kubernetes Manifest|Helm Chart} - exports the pattern file in the specified design format
 // ?pkg={true|false} - If true, returns the artifact hub pkg and pattern file in zip file. If "oci" is true, "pkg" is ignored and the export always contains the artifact hub pkg.
+// ?schemaVersion=v1alpha2 - returns the pattern file in the legacy v1alpha2 format, for clients which do not support the current design schema
+// ?sanitize={true|false} - If true, masks the sensitive settings of the pattern file before it is exported, as POST /api/pattern/sanitize does with the policy of Meshery Server. The count of values redacted is returned in the X-Meshery-Redactions header
+// ?defaults={true|false} - If true, fills in the configuration of the components from the defaults of their registered schemas when exporting Kubernetes manifests
 //
 // Get the pattern with the given id
 // responses:
@@ -1178,7 +1258,9 @@ func (h *Handler) DownloadMesheryPatternHandler(
 	eventBuilder := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("pattern").WithAction("download").ActedUpon(userID).WithSeverity(events.Informational)
 
 	exportFormat := r.URL.Query().Get("export")
-	if exportFormat != "" {
+	exportHelmChart := converter.DesignFormat(exportFormat) == converter.HelmChart
+	exportManifests := converter.DesignFormat(exportFormat) == converter.K8sManifest
+	if exportFormat != "" && !exportHelmChart && !exportManifests {
 		var errConvert error
 		formatConverter, errConvert = converter.NewFormatConverter(converter.DesignFormat(exportFormat))
 		if errConvert != nil {
@@ -1259,6 +1341,32 @@ func (h *Handler) DownloadMesheryPatternHandler(
 		pattern.PatternFile = patternFileStr
 	}
 
+	if sanitize, _ := strconv.ParseBool(r.URL.Query().Get("sanitize")); sanitize {
+		report, err := sanitizeExportedDesign(pattern)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set(PatternRedactionsHeader, strconv.Itoa(len(report.Redactions)))
+	}
+
+	if r.URL.Query().Get("schemaVersion") == string(pCore.ConversionFormatV1alpha2) {
+		h.exportPatternAsV1alpha2(rw, pattern)
+		return
+	}
+
+	if exportHelmChart {
+		h.exportPatternAsHelmChart(rw, pattern)
+		return
+	}
+
+	if exportManifests {
+		applyDefaults, _ := strconv.ParseBool(r.URL.Query().Get("defaults"))
+		h.exportPatternAsManifests(rw, pattern, applyDefaults)
+		return
+	}
+
 	if formatConverter != nil {
 		patternFile, err := formatConverter.Convert(pattern.PatternFile)
 		if err != nil {
@@ -1541,6 +1649,121 @@ func (h *Handler) DownloadMesheryPatternHandler(
 	}
 }
 
+// exportPatternAsHelmChart writes the design as a packaged Helm chart to the response
+func (h *Handler) exportPatternAsHelmChart(rw http.ResponseWriter, pattern *models.MesheryPattern) {
+	patternFile, err := pCore.NewPatternFile([]byte(pattern.PatternFile))
+	if err != nil {
+		err = ErrParsePattern(err)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tmpDir, err := os.MkdirTemp("", "helm-chart")
+	if err != nil {
+		h.log.Error(ErrCreateDir(err, "Helm chart"))
+		http.Error(rw, ErrCreateDir(err, "Helm chart").Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pkg, err := pCore.ToHelmChart(&patternFile, tmpDir)
+	if err != nil {
+		err = ErrExportPatternInFormat(err, string(converter.HelmChart), pattern.Name)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	file, err := os.Open(pkg)
+	if err != nil {
+		h.log.Error(ErrOpenFile(pkg))
+		http.Error(rw, ErrOpenFile(pkg).Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	rw.Header().Add("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filepath.Base(pkg)))
+	rw.Header().Set("Content-Type", "application/gzip")
+	if _, err := io.Copy(rw, file); err != nil {
+		err = ErrWriteResponse(err)
+		h.log.Error(err)
+		http.Error(rw, _errors.Wrapf(err, "failed to export design \"%s\" as a Helm chart", pattern.Name).Error(), http.StatusInternalServerError)
+	}
+}
+
+// exportPatternAsV1alpha2 writes the design in the legacy v1alpha2 format to the response
+func (h *Handler) exportPatternAsV1alpha2(rw http.ResponseWriter, pattern *models.MesheryPattern) {
+	patternFile, err := pCore.NewPatternFile([]byte(pattern.PatternFile))
+	if err != nil {
+		err = ErrParsePattern(err)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	legacy, err := pCore.ConvertDesignToV1alpha2(&patternFile)
+	if err != nil {
+		err = ErrExportPatternInFormat(err, string(pCore.ConversionFormatV1alpha2), pattern.Name)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	byt, err := yaml.Marshal(legacy)
+	if err != nil {
+		err = models.ErrMarshalYAML(err, "v1alpha2 design")
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Add("Content-Disposition", fmt.Sprintf("attachment;filename=%s.yml", pattern.Name))
+	rw.Header().Set("Content-Type", "application/yaml")
+	if _, err := rw.Write(byt); err != nil {
+		err = ErrWriteResponse(err)
+		h.log.Error(err)
+		http.Error(rw, _errors.Wrapf(err, "failed to export design \"%s\" in the v1alpha2 format", pattern.Name).Error(), http.StatusInternalServerError)
+	}
+}
+
+// exportPatternAsManifests writes the Kubernetes manifests which deploying the design would apply to the response,
+// with the schema defaults of the components filled in when applyDefaults is set
+func (h *Handler) exportPatternAsManifests(rw http.ResponseWriter, pattern *models.MesheryPattern, applyDefaults bool) {
+	patternFile, err := pCore.NewPatternFile([]byte(pattern.PatternFile))
+	if err != nil {
+		err = ErrParsePattern(err)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resolver := &pCore.RegistryResolver{Registry: h.registryManager}
+	if applyDefaults {
+		if err := pCore.ApplyComponentDefaults(&patternFile, resolver); err != nil {
+			err = ErrExportPatternInFormat(err, string(converter.K8sManifest), pattern.Name)
+			h.log.Error(err)
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	manifests, err := pCore.RenderManifests(&patternFile, resolver)
+	if err != nil {
+		err = ErrExportPatternInFormat(err, string(converter.K8sManifest), pattern.Name)
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Add("Content-Disposition", fmt.Sprintf("attachment;filename=%s.yml", pattern.Name))
+	rw.Header().Set("Content-Type", "application/yaml")
+	if _, err := rw.Write(manifests); err != nil {
+		err = ErrWriteResponse(err)
+		h.log.Error(err)
+		http.Error(rw, _errors.Wrapf(err, "failed to export design \"%s\" as Kubernetes manifests", pattern.Name).Error(), http.StatusInternalServerError)
+	}
+}
+
 // swagger:route POST /api/pattern/clone/{id} PatternsAPI idCloneMesheryPattern
 // Handle Clone for a Meshery Pattern
 //
@@ -1657,6 +1880,8 @@ func (h *Handler) CloneMesheryPatternHandler(
 // Handle Publish for a Meshery Pattern
 //
 // Publishes pattern to Meshery Catalog by setting visibility to published and setting catalog data
+// Designs holding sensitive settings are not published, the report of what would be redacted is returned
+// with 422 instead. Sanitize the design, and save it sanitized, with POST /api/pattern/sanitize first.
 // responses:
 //
 //	202: noContentWrapper
@@ -1694,6 +1919,26 @@ func (h *Handler) PublishCatalogPatternHandler(
 		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
 		return
 	}
+	if report, err := h.checkPublishedDesign(r, provider, parsedBody.ID); err != nil {
+		h.log.Error(err)
+		metadata := map[string]interface{}{"error": err}
+		if report != nil {
+			metadata["redactions"] = report.Redactions
+		}
+		e := eventBuilder.WithSeverity(events.Error).WithMetadata(metadata).
+			WithDescription("Design holds sensitive settings, sanitize it before publishing it.").Build()
+		_ = provider.PersistEvent(e)
+		go h.config.EventBroadcaster.Publish(userID, e)
+		if report == nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(rw).Encode(report)
+		return
+	}
+
 	resp, err := provider.PublishCatalogPattern(r, parsedBody)
 	if err != nil {
 		h.log.Error(ErrPublishCatalogPattern(err))
@@ -1724,6 +1969,16 @@ func (h *Handler) PublishCatalogPatternHandler(
 	_ = provider.PersistEvent(e)
 	go h.config.EventBroadcaster.Publish(userID, e)
 
+	if content, err := fetchDesignContent(r, provider, parsedBody.ID); err != nil {
+		h.log.Error(ErrGenerateCatalogPreview(err, parsedBody.ID.String()))
+	} else {
+		go func() {
+			if err := generateCatalogPreviews(parsedBody.ID, content); err != nil {
+				h.log.Error(err)
+			}
+		}()
+	}
+
 	go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(http.StatusAccepted)
@@ -1835,6 +2090,10 @@ func (h *Handler) DeleteMultiMesheryPatternsHandler(
 		http.Error(rw, fmt.Sprintf("failed to delete the pattern: %s", err), http.StatusInternalServerError)
 		return
 	}
+	for _, p := range patterns.Patterns {
+		deletedID := uuid.FromStringOrNil(p.ID)
+		h.dispatchDesignHook(extensions.HookDesignDeleted, uuid.FromStringOrNil(user.ID), &deletedID, p.Name, nil)
+	}
 	go h.config.PatternChannel.Publish(uuid.FromStringOrNil(user.ID), struct{}{})
 	rw.Header().Set("Content-Type", "application/json")
 	fmt.Fprint(rw, string(resp))
@@ -2081,6 +2340,7 @@ func (h *Handler) handlePatternUpdate(
 		return
 	}
 	go h.config.PatternChannel.Publish(userID, struct{}{})
+	h.dispatchDesignHook(extensions.HookDesignSaved, userID, mesheryPattern.ID, mesheryPattern.Name, nil)
 
 	eventBuilder.WithSeverity(events.Informational)
 	h.formatPatternOutput(rw, resp, format, sourcetype, eventBuilder, parsedBody.URL, models.Update)
@@ -2205,27 +2465,12 @@ func createArtifactHubPkg(pattern *models.MesheryPattern, user string) ([]byte,
 
 func (h *Handler) convertV1alpha2ToV1beta1(mesheryPattern *models.MesheryPattern, eventBuilder *events.EventBuilder) (*pattern.PatternFile, string, error) {
 
-	v1alpha1PatternFile := v1alpha2.PatternFile{}
-
-	v1beta1PatternFile := pattern.PatternFile{}
-
-	err := encoding.Unmarshal([]byte(mesheryPattern.PatternFile), &v1alpha1PatternFile)
+	v1beta1PatternFile, err := pCore.NewPatternFileFromV1alpha2([]byte(mesheryPattern.PatternFile))
 	if err != nil {
 		return nil, "", ErrParsePattern(err)
 	}
 
-	for _, svc := range v1alpha1PatternFile.Services {
-		svc.Settings = helpers.RecursiveCastMapStringInterfaceToMapStringInterface(svc.Settings)
-		svc.Traits = helpers.RecursiveCastMapStringInterfaceToMapStringInterface(svc.Traits)
-	}
-
-	err = v1beta1PatternFile.ConvertFrom(&v1alpha1PatternFile)
-	if err != nil {
-		return nil, "", err
-	}
-
 	v1beta1PatternFile.Id = *mesheryPattern.ID
-	v1beta1PatternFile.Version = v1alpha1PatternFile.Version
 
 	h.log.Infof("Converted design file with id \"%s\" to v1beta1 format", *mesheryPattern.ID)
 
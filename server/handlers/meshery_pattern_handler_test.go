@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	pCore "github.com/layer5io/meshery/server/models/pattern/core"
+)
+
+const dependentTestDesign = `
+name: web
+components:
+  - id: 3f1c5a2e-8f4b-4d6a-9a57-0b2d0e6c1a11
+    displayName: config
+    component:
+      kind: ConfigMap
+      version: v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        name: config
+  - id: 7b9d2c44-1e3a-4f0b-8c6d-5a4e3b2f1d22
+    displayName: web
+    component:
+      kind: Deployment
+      version: apps/v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        name: web
+    metadata:
+      dependsOn:
+        - 3f1c5a2e-8f4b-4d6a-9a57-0b2d0e6c1a11
+`
+
+func TestHandlePatternPOST_CytoscapeJSON(t *testing.T) {
+	h := newEphemeralTestHandler(t)
+	h.config.PatternChannel = models.NewBroadcaster("test")
+	if err := h.dbHandler.AutoMigrate(&models.MesheryPattern{}); err != nil {
+		t.Fatal(err)
+	}
+	provider := &models.DefaultLocalProvider{
+		EventsPersister:         &models.EventsPersister{DB: h.dbHandler},
+		MesheryPatternPersister: &models.MesheryPatternPersister{DB: h.dbHandler},
+	}
+
+	patternFile, err := pCore.NewPatternFile([]byte(dependentTestDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cy, err := pCore.ToCytoscapeJS(&patternFile, h.log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cyJSON, err := json.Marshal(cy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(MesheryPatternPOSTRequestBody{Name: "web", Save: true, CytoscapeJSON: string(cyJSON)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/pattern", bytes.NewReader(body)), map[string]string{"sourcetype": string(models.Design)})
+	rec := httptest.NewRecorder()
+	h.handlePatternPOST(rec, req, &models.Preference{}, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	saved := []models.MesheryPattern{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].ID == nil {
+		t.Fatalf("saved designs = %+v, want one", saved)
+	}
+
+	// the design read back from the database keeps the dependencies of its components
+	byt, err := provider.GetMesheryPattern(req, saved[0].ID.String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := models.MesheryPattern{}
+	if err := json.Unmarshal(byt, &reloaded); err != nil {
+		t.Fatal(err)
+	}
+	reloadedFile, err := pCore.NewPatternFile([]byte(reloaded.PatternFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dependsOn := map[string]interface{}{}
+	for _, comp := range reloadedFile.Components {
+		dependsOn[comp.DisplayName] = comp.Metadata.AdditionalProperties["dependsOn"]
+	}
+	want := map[string]interface{}{
+		"config": nil,
+		"web":    []interface{}{"3f1c5a2e-8f4b-4d6a-9a57-0b2d0e6c1a11"},
+	}
+	if !reflect.DeepEqual(dependsOn, want) {
+		t.Errorf("dependencies = %v, want %v", dependsOn, want)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+)
+
+// PatternValidationResponse is the result of validating a design against the registry.
+type PatternValidationResponse struct {
+	Valid      bool                          `json:"valid"`
+	Violations []core.ConfigurationViolation `json:"violations"`
+}
+
+// swagger:route POST /api/pattern/validate PatternsAPI idValidatePattern
+// Handle POST request to validate a design
+//
+// Resolves every component of the design (YAML or JSON request body) to its registered definition
+// and validates the component configuration against the definition's schema.
+// All violations are reported, each with the component name and a JSON pointer to the invalid field.
+// responses:
+// 	200: patternValidationResponseWrapper
+
+func (h *Handler) ValidatePatternHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	patternFile, err := core.NewPatternFile(body)
+	if err != nil {
+		h.log.Error(ErrPatternFile(err))
+		http.Error(rw, ErrPatternFile(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := PatternValidationResponse{Valid: true, Violations: []core.ConfigurationViolation{}}
+	err = core.ValidatePatternFile(&patternFile, h.registryManager)
+	if err != nil {
+		var validationErr *core.PatternValidationError
+		if !errors.As(err, &validationErr) {
+			h.log.Error(ErrValidate(err))
+			http.Error(rw, ErrValidate(err).Error(), http.StatusInternalServerError)
+			return
+		}
+		response.Valid = false
+		response.Violations = validationErr.Violations
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(response); err != nil {
+		h.log.Error(models.ErrMarshal(err, "design validation result"))
+		http.Error(rw, models.ErrMarshal(err, "design validation result").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/registration"
+)
+
+// swagger:route GET /api/meshmodels/snapshot MeshmodelsAPI idGetRegistrySnapshot
+// Handle GET request for the registry snapshot the server is pinned to
+//
+// Returns the pinned registry snapshot. The body is null when the server seeds the models it ships with.
+// responses:
+// 	200: registrySnapshotResponseWrapper
+
+func (h *Handler) GetRegistrySnapshotHandler(rw http.ResponseWriter, _ *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	snapshot, err := models.GetPinnedRegistrySnapshot()
+	if err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(snapshot); err != nil {
+		h.log.Error(models.ErrMarshal(err, "registry snapshot"))
+		http.Error(rw, models.ErrMarshal(err, "registry snapshot").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/meshmodels/snapshot MeshmodelsAPI idUpgradeRegistrySnapshot
+// Handle POST request to pin the registry to a snapshot version
+//
+// Pulls the requested version of the registry snapshots from the registry and repository configured
+// on the server, pins the server to it and registers the models it contains.
+// The upgrade is explicit: a pinned server never moves to another snapshot on its own.
+// responses:
+// 	200: registrySnapshotResponseWrapper
+
+func (h *Handler) UpgradeRegistrySnapshotHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	var req models.RegistrySnapshotUpgradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Version == "" {
+		err := ErrRequestBody(fmt.Errorf("snapshot version is required"))
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateRegistrySnapshotVersion(req.Version); err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := uuid.FromStringOrNil(user.ID)
+	eventBuilder := events.NewEvent().ActedUpon(userID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("registry").WithAction("upgrade")
+
+	snapshot := models.NewRegistrySnapshot(req.Version, "", "")
+	if err := snapshot.Pull(); err != nil {
+		h.log.Error(err)
+		event := eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Failed to upgrade registry to snapshot %s", req.Version)).WithMetadata(map[string]interface{}{
+			"error": err,
+		}).Build()
+		_ = provider.PersistEvent(event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := snapshot.Pin(); err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	regErrorStore := models.NewRegistrationFailureLogHandler()
+	regHelper := registration.NewRegistrationHelper(utils.UI, h.registryManager, regErrorStore)
+	modelDirPaths, err := models.GetModelDirectoryPaths(snapshot.Path)
+	if err != nil {
+		h.log.Error(models.ErrSeedingComponents(err))
+	}
+	for _, dirPath := range modelDirPaths {
+		regHelper.Register(registration.NewDir(dirPath))
+	}
+	models.RegistryLog(h.log, h.config, h.registryManager, regErrorStore)
+	go h.config.MeshModelSummaryChannel.Publish()
+	go h.notifyDesignImpact(userID, provider, regHelper.PkgUnits)
+
+	event := eventBuilder.WithSeverity(events.Success).WithDescription(fmt.Sprintf("Registry upgraded to snapshot %s", snapshot.Version)).WithMetadata(map[string]interface{}{
+		"version":    snapshot.Version,
+		"registry":   snapshot.Registry,
+		"repository": snapshot.Repository,
+	}).Build()
+	_ = provider.PersistEvent(event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(snapshot); err != nil {
+		h.log.Error(models.ErrMarshal(err, "registry snapshot"))
+		http.Error(rw, models.ErrMarshal(err, "registry snapshot").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	mesheryctlUtils "github.com/layer5io/meshery/mesheryctl/pkg/utils"
+	"github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+	"github.com/layer5io/meshkit/models/registration"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"gorm.io/gorm/clause"
+)
+
+// swagger:route POST /api/meshmodels/updates MeshmodelsAPI idStartRegistryUpdate
+// Handle POST request to update the registry
+//
+// Starts updating the registry from one source: the Components sheet of a published spreadsheet, an uploaded
+// components CSV or an OCI artifact of models. The update runs in the background, only one at a time; follow
+// it with GET /api/meshmodels/updates/{id} or its event stream.
+//
+// Only the sources configured on the server are allowed, otherwise the response is 403: the tags of the
+// repository of the registry snapshots, the spreadsheet of REGISTRY_UPDATE_SPREADSHEET_ID, and uploaded
+// CSVs when REGISTRY_UPDATE_CSV_ENABLED is set.
+// responses:
+// 	202: registryUpdateResponseWrapper
+
+func (h *Handler) StartRegistryUpdateHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	var req models.RegistryUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(ErrRequestBody(err))
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	source, err := req.Source()
+	if err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := req.CheckAllowed(source); err != nil {
+		h.log.Error(err)
+		http.Error(rw, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if !h.registryUpdateRunning.CompareAndSwap(false, true) {
+		http.Error(rw, "a registry update is already running", http.StatusConflict)
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+	update, err := models.NewRegistryUpdate(source, userID)
+	if err != nil {
+		h.registryUpdateRunning.Store(false)
+		h.log.Error(ErrRegistryUpdate(err, source))
+		http.Error(rw, ErrRegistryUpdate(err, source).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.registryUpdates.Store(update.ID, update)
+	go h.runRegistryUpdate(update, req, userID, provider)
+
+	h.writeJSONResource(rw, http.StatusAccepted, update.Snapshot(), "registry update")
+}
+
+// swagger:route GET /api/meshmodels/updates/{id} MeshmodelsAPI idGetRegistryUpdate
+// Handle GET request for a registry update
+//
+// Returns the status and the progress of the update. Updates are kept until the server restarts.
+// Responds with 404 when the update does not exist or was started by another user.
+// responses:
+// 	200: registryUpdateResponseWrapper
+
+func (h *Handler) GetRegistryUpdateHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, _ models.Provider) {
+	update, ok := h.loadRegistryUpdate(mux.Vars(r)["id"], user)
+	if !ok {
+		http.Error(rw, "registry update not found", http.StatusNotFound)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, update.Snapshot(), "registry update")
+}
+
+// swagger:route GET /api/meshmodels/updates/{id}/stream MeshmodelsAPI idStreamRegistryUpdate
+// Handle GET request to stream the progress of a registry update
+//
+// Streams server-sent events: a progress event per step of the update, the steps already done first, then a
+// result event with the update once it is done.
+// responses:
+// 	200:
+
+func (h *Handler) StreamRegistryUpdateHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, _ models.Provider) {
+	update, ok := h.loadRegistryUpdate(mux.Vars(r)["id"], user)
+	if !ok {
+		http.Error(rw, "registry update not found", http.StatusNotFound)
+		return
+	}
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		h.log.Error(ErrEventStreamingNotSupported)
+		http.Error(rw, "Event streaming is not supported at the moment.", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+
+	send := func(event string, data interface{}) {
+		bd, err := json.Marshal(data)
+		if err != nil {
+			h.log.Error(models.ErrMarshal(err, "registry update"))
+			return
+		}
+		fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event, bd)
+		flusher.Flush()
+	}
+
+	done, next, unsubscribe := update.Subscribe()
+	defer unsubscribe()
+	for _, progress := range done {
+		send("progress", progress)
+	}
+	for next != nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case progress, ok := <-next:
+			if !ok {
+				next = nil
+				continue
+			}
+			send("progress", progress)
+		}
+	}
+	send("result", update.Snapshot())
+}
+
+// loadRegistryUpdate returns the update with the given id, if it was started by the user.
+func (h *Handler) loadRegistryUpdate(id string, user *models.User) (*models.RegistryUpdate, bool) {
+	value, ok := h.registryUpdates.Load(uuid.FromStringOrNil(id))
+	if !ok {
+		return nil, false
+	}
+	update := value.(*models.RegistryUpdate)
+	if update.UserID != uuid.FromStringOrNil(user.ID) {
+		return nil, false
+	}
+	return update, true
+}
+
+// runRegistryUpdate updates the registry from the source of the request, reporting its progress to the update,
+// then records the outcome as an event.
+func (h *Handler) runRegistryUpdate(update *models.RegistryUpdate, req models.RegistryUpdateRequest, userID uuid.UUID, provider models.Provider) {
+	defer h.registryUpdateRunning.Store(false)
+
+	var err error
+	switch update.Source {
+	case models.RegistryUpdateSourceSpreadsheet, models.RegistryUpdateSourceCSV:
+		stop := h.forwardRegistryProgress(userID, provider, update)
+		err = h.updateRegistryComponents(update, req)
+		stop()
+	case models.RegistryUpdateSourceOCI:
+		err = h.updateRegistryFromOCI(update, req, userID, provider)
+	}
+	if err != nil {
+		err = ErrRegistryUpdate(err, update.Source)
+		h.log.Error(err)
+		update.Report("", err.Error(), true)
+	}
+	update.Finish(err)
+	go h.config.MeshModelSummaryChannel.Publish()
+
+	result := update.Snapshot()
+	eventBuilder := events.NewEvent().ActedUpon(result.ID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("registry").WithAction("update")
+	if err != nil {
+		eventBuilder = eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Failed to update the registry from the %s source", result.Source)).WithMetadata(map[string]interface{}{
+			"error": err,
+		})
+	} else {
+		eventBuilder = eventBuilder.WithSeverity(events.Success).WithDescription(fmt.Sprintf("Updated %d models and %d components of the registry from the %s source", result.ModelsUpdated, result.ComponentsUpdated, result.Source)).WithMetadata(map[string]interface{}{
+			"models_updated":     result.ModelsUpdated,
+			"components_updated": result.ComponentsUpdated,
+		})
+	}
+	event := eventBuilder.Build()
+	_ = provider.PersistEvent(event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+}
+
+// updateRegistryComponents updates the registered components with their rows of the components sheet of a
+// spreadsheet or of an uploaded CSV, as mesheryctl registry update does for the models of the repository.
+func (h *Handler) updateRegistryComponents(update *models.RegistryUpdate, req models.RegistryUpdateRequest) error {
+	if err := mesheryctlUtils.SetLogger(false); err != nil {
+		return err
+	}
+
+	csvPath := ""
+	sheetURL := ""
+	if update.Source == models.RegistryUpdateSourceCSV {
+		if !strings.HasPrefix(req.CSV, "data:text/csv;base64,") {
+			return ErrFileType("file is not of type csv")
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(req.CSV, "data:text/csv;base64,"))
+		if err != nil {
+			return err
+		}
+		file, err := os.CreateTemp("", "components-*.csv")
+		if err != nil {
+			return ErrCreateFile(err, "Error creating temp file for Component CSV")
+		}
+		defer os.Remove(file.Name())
+		defer file.Close()
+		if _, err := file.Write(data); err != nil {
+			return ErrWritingIntoFile(err, "Error writing Component CSV to temp file")
+		}
+		csvPath = file.Name()
+		update.Report("", "Reading the uploaded components CSV", false)
+	} else {
+		sheetURL = mesheryctlUtils.GoogleSpreadSheetURL + req.SpreadsheetID
+		update.Report("", "Downloading the Components sheet of the spreadsheet "+req.SpreadsheetID, false)
+	}
+
+	csvHelper, err := mesheryctlUtils.NewComponentCSVHelper(sheetURL, "Components", req.SheetGID, csvPath)
+	if err != nil {
+		return err
+	}
+	if err := csvHelper.ParseComponentsSheet(req.Model); err != nil {
+		return err
+	}
+
+	for registrant, componentsByModel := range csvHelper.Components {
+		for modelName, comps := range componentsByModel {
+			mesheryctlUtils.ReportRegistryProgress(mesheryctlUtils.RegistryProgress{Operation: mesheryctlUtils.RegistryOperationUpdate, Kind: mesheryctlUtils.RegistryModelStarted, Model: modelName})
+			fail := func(err error, comp string) {
+				mesheryctlUtils.ReportRegistryProgress(mesheryctlUtils.RegistryProgress{Operation: mesheryctlUtils.RegistryOperationUpdate, Kind: mesheryctlUtils.RegistryProgressError, Model: modelName, Component: comp, Err: err})
+			}
+			updated := 0
+			for _, comp := range comps {
+				entities, _, _, err := h.registryManager.GetEntities(&regv1beta1.ComponentFilter{Name: comp.Component, ModelName: modelName})
+				if err != nil {
+					fail(fmt.Errorf("failed to get the component %s: %w", comp.Component, err), comp.Component)
+					continue
+				}
+				for _, entity := range entities {
+					compDef, ok := entity.(*component.ComponentDefinition)
+					if !ok {
+						continue
+					}
+					if err := comp.UpdateCompDefinition(compDef); err != nil {
+						fail(fmt.Errorf("failed to update the component %s: %w", comp.Component, err), comp.Component)
+						continue
+					}
+					if err := h.saveRegistryComponent(compDef); err != nil {
+						fail(fmt.Errorf("failed to save the component %s: %w", comp.Component, err), comp.Component)
+						continue
+					}
+					updated++
+					mesheryctlUtils.ReportRegistryProgress(mesheryctlUtils.RegistryProgress{Operation: mesheryctlUtils.RegistryOperationUpdate, Kind: mesheryctlUtils.RegistryComponentUpdated, Model: modelName, Version: compDef.Model.Model.Version, Component: comp.Component})
+				}
+			}
+			message := fmt.Sprintf("%d %s updated", updated, determinePluralWord(updated, "component"))
+			if updated == 0 {
+				message = fmt.Sprintf("no component of the model (registrant %s) is registered", registrant)
+			} else {
+				update.Count(1, updated)
+			}
+			mesheryctlUtils.ReportRegistryProgress(mesheryctlUtils.RegistryProgress{Operation: mesheryctlUtils.RegistryOperationUpdate, Kind: mesheryctlUtils.RegistryModelCompleted, Model: modelName, Message: message})
+		}
+	}
+	return nil
+}
+
+// forwardRegistryProgress sends the progress of the registry pipeline of mesheryctl to the event stream of the user,
+// and to the update if not nil, until the returned function is called. The pipeline reports to one handler at a
+// time, so registry operations forwarding their progress run one at a time.
+func (h *Handler) forwardRegistryProgress(userID uuid.UUID, provider models.Provider, update *models.RegistryUpdate) func() {
+	h.registryProgressMx.Lock()
+	unset := mesheryctlUtils.SetRegistryProgressHandler(func(progress mesheryctlUtils.RegistryProgress) {
+		if update != nil {
+			update.Report(progress.Model, progress.String(), progress.Err != nil)
+		}
+
+		eventBuilder := events.NewEvent().ActedUpon(userID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("registry").WithAction(string(progress.Kind)).WithDescription(progress.String())
+		metadata := map[string]interface{}{
+			"operation": progress.Operation,
+			"model":     progress.Model,
+			"version":   progress.Version,
+			"component": progress.Component,
+		}
+		if update != nil {
+			metadata["update_id"] = update.ID
+		}
+		if progress.Err != nil {
+			metadata["error"] = progress.Err
+			eventBuilder = eventBuilder.WithSeverity(events.Error)
+		} else {
+			eventBuilder = eventBuilder.WithSeverity(events.Informational)
+		}
+		event := eventBuilder.WithMetadata(metadata).Build()
+		// the steps are streamed live, only the errors and the completed models are kept
+		if progress.Err != nil || progress.Kind == mesheryctlUtils.RegistryModelCompleted {
+			_ = provider.PersistEvent(event)
+		}
+		go h.config.EventBroadcaster.Publish(userID, event)
+	})
+	return func() {
+		unset()
+		h.registryProgressMx.Unlock()
+	}
+}
+
+func (h *Handler) saveRegistryComponent(compDef *component.ComponentDefinition) error {
+	h.dbHandler.Lock()
+	defer h.dbHandler.Unlock()
+	return h.dbHandler.DB.Omit(clause.Associations).Save(compDef).Error
+}
+
+// updateRegistryFromOCI registers the models of an OCI artifact, such as a registry snapshot. Unlike an upgrade to
+// a snapshot, the server is not pinned to the artifact.
+func (h *Handler) updateRegistryFromOCI(update *models.RegistryUpdate, req models.RegistryUpdateRequest, userID uuid.UUID, provider models.Provider) error {
+	registry, repository, tag, err := models.ParseOCIRef(req.OCIRef)
+	if err != nil {
+		return err
+	}
+	update.Report("", "Pulling "+req.OCIRef, false)
+	snapshot := models.NewRegistrySnapshot(tag, registry, repository)
+	if err := snapshot.Pull(); err != nil {
+		return err
+	}
+
+	modelDirPaths, err := models.GetModelDirectoryPaths(snapshot.Path)
+	if err != nil {
+		return models.ErrSeedingComponents(err)
+	}
+	regErrorStore := models.NewRegistrationFailureLogHandler()
+	regHelper := registration.NewRegistrationHelper(utils.UI, h.registryManager, regErrorStore)
+	for _, dirPath := range modelDirPaths {
+		regHelper.Register(registration.NewDir(dirPath))
+	}
+	models.RegistryLog(h.log, h.config, h.registryManager, regErrorStore)
+
+	components := 0
+	for _, pkgUnit := range regHelper.PkgUnits {
+		components += len(pkgUnit.Components)
+		update.Report(pkgUnit.Model.Name, fmt.Sprintf("Registered %d %s", len(pkgUnit.Components), determinePluralWord(len(pkgUnit.Components), "component")), false)
+	}
+	update.Count(len(regHelper.PkgUnits), components)
+	go h.notifyDesignImpact(userID, provider, regHelper.PkgUnits)
+	return nil
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/meshmodel"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+	"github.com/meshery/schemas/models/v1alpha3/relationship"
+	"github.com/meshery/schemas/models/v1beta1/category"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/connection"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/spf13/viper"
+)
+
+func newRegistryUpdateTestHandler(t *testing.T) (*Handler, *models.DefaultLocalProvider) {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	h.config.MeshModelSummaryChannel = meshmodel.NewSummaryHelper()
+	return h, &models.DefaultLocalProvider{GenericPersister: h.dbHandler, EventsPersister: &models.EventsPersister{DB: h.dbHandler}}
+}
+
+func TestStartRegistryUpdateHandler(t *testing.T) {
+	viper.Set(models.RegistrySnapshotRegistryENV, "ghcr.io")
+	viper.Set(models.RegistrySnapshotRepositoryENV, "meshery/registry")
+	t.Cleanup(func() {
+		viper.Set(models.RegistrySnapshotRegistryENV, nil)
+		viper.Set(models.RegistrySnapshotRepositoryENV, nil)
+	})
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "invalid body", body: "{", want: http.StatusBadRequest},
+		{name: "no source", body: `{}`, want: http.StatusBadRequest},
+		{name: "other registry", body: `{"oci_ref": "docker.io/meshery/registry:v0.8.0"}`, want: http.StatusForbidden},
+		{name: "other repository", body: `{"oci_ref": "ghcr.io/fork/registry:v0.8.0"}`, want: http.StatusForbidden},
+		{name: "unconfigured spreadsheet", body: `{"spreadsheet_id": "sheet"}`, want: http.StatusForbidden},
+		{name: "csv disabled", body: `{"csv": "data:text/csv;base64,"}`, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, provider := newRegistryUpdateTestHandler(t)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.StartRegistryUpdateHandler(rec, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if h.registryUpdateRunning.Load() {
+				t.Error("expected no update to be started")
+			}
+		})
+	}
+}
+
+func TestGetRegistryUpdateHandler(t *testing.T) {
+	h, provider := newRegistryUpdateTestHandler(t)
+	owner := uuid.Must(uuid.NewV4())
+	update, err := models.NewRegistryUpdate(models.RegistryUpdateSourceOCI, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.registryUpdates.Store(update.ID, update)
+
+	tests := []struct {
+		name string
+		user uuid.UUID
+		id   string
+		want int
+	}{
+		{name: "owner", user: owner, id: update.ID.String(), want: http.StatusOK},
+		{name: "another user", user: uuid.Must(uuid.NewV4()), id: update.ID.String(), want: http.StatusNotFound},
+		{name: "missing", user: owner, id: uuid.Must(uuid.NewV4()).String(), want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, handler := range map[string]func(http.ResponseWriter, *http.Request, *models.Preference, *models.User, models.Provider){
+				"get":    h.GetRegistryUpdateHandler,
+				"stream": h.StreamRegistryUpdateHandler,
+			} {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+				rec := httptest.NewRecorder()
+				if name == "stream" && tt.want == http.StatusOK {
+					// the stream of a running update only ends with the request
+					continue
+				}
+				handler(rec, req, nil, &models.User{ID: tt.user.String()}, provider)
+				if rec.Code != tt.want {
+					t.Errorf("%s: status = %d, want %d: %s", name, rec.Code, tt.want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunRegistryUpdate(t *testing.T) {
+	deployment := component.ComponentDefinition{
+		DisplayName: "Deployment",
+		Component:   component.Component{Kind: "Deployment", Version: "apps/v1", Schema: `{"type": "object"}`},
+		Model: model.ModelDefinition{
+			Name:        "kubernetes",
+			DisplayName: "Kubernetes",
+			Version:     "v1.0.0",
+			Category:    category.CategoryDefinition{Name: "Orchestration"},
+			Model:       model.Model{Version: "v1.30.0"},
+			Status:      model.ModelDefinitionStatusEnabled,
+		},
+	}
+	csv := "registrant,model,component,description\nkubernetes,kubernetes,Deployment,Manages replicated pods\n"
+
+	tests := []struct {
+		name            string
+		csv             string
+		wantStatus      models.RegistryUpdateStatus
+		wantComponents  int
+		wantSeverity    events.EventSeverity
+		wantDescription string
+	}{
+		{
+			name:            "updated",
+			csv:             "data:text/csv;base64," + base64.StdEncoding.EncodeToString([]byte("components,,,\n"+csv)),
+			wantStatus:      models.RegistryUpdateCompleted,
+			wantComponents:  1,
+			wantSeverity:    events.Success,
+			wantDescription: "Manages replicated pods",
+		},
+		{
+			name:         "not a CSV",
+			csv:          "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte(csv)),
+			wantStatus:   models.RegistryUpdateFailed,
+			wantSeverity: events.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, provider := newRegistryUpdateTestHandler(t)
+			if err := h.dbHandler.AutoMigrate(&relationship.RelationshipDefinition{}); err != nil {
+				t.Fatal(err)
+			}
+			regManager, err := registry.NewRegistryManager(h.dbHandler)
+			if err != nil {
+				t.Fatal(err)
+			}
+			comp := deployment
+			if _, _, err := regManager.RegisterEntity(connection.Connection{Kind: "kubernetes", Type: "registry"}, &comp); err != nil {
+				t.Fatal(err)
+			}
+			h.registryManager = regManager
+
+			userID := uuid.Must(uuid.NewV4())
+			update, err := models.NewRegistryUpdate(models.RegistryUpdateSourceCSV, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.registryUpdateRunning.Store(true)
+			h.runRegistryUpdate(update, models.RegistryUpdateRequest{CSV: tt.csv}, userID, provider)
+
+			if h.registryUpdateRunning.Load() {
+				t.Error("expected the update to no longer be running")
+			}
+			got := update.Snapshot()
+			if got.Status != tt.wantStatus || got.ComponentsUpdated != tt.wantComponents {
+				t.Fatalf("update = %+v, want status %s with %d components updated", got, tt.wantStatus, tt.wantComponents)
+			}
+
+			var recorded []events.Event
+			if err := h.dbHandler.Where("acted_upon = ?", update.ID).Find(&recorded).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(recorded) != 1 || recorded[0].Severity != tt.wantSeverity {
+				t.Errorf("events = %+v, want one of severity %s", recorded, tt.wantSeverity)
+			}
+
+			if tt.wantDescription == "" {
+				return
+			}
+			entities, _, _, err := regManager.GetEntities(&regv1beta1.ComponentFilter{Name: "Deployment", ModelName: "kubernetes"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entities) != 1 || entities[0].(*component.ComponentDefinition).Description != tt.wantDescription {
+				t.Errorf("expected the description of the component to be updated, got %+v", entities)
+			}
+		})
+	}
+}
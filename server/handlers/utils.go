@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshkit/models/meshmodel/registry"
 	"github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
 	"github.com/layer5io/meshkit/utils"
@@ -77,3 +79,17 @@ func getLatestKubeVersionFromRegistry(reg *registry.RegistryManager) string {
 
 	return versions[0]
 }
+
+// writeJSONResource writes the resource as the JSON body of the response with the given status,
+// or an internal server error naming the resource when it cannot be marshalled.
+func (h *Handler) writeJSONResource(rw http.ResponseWriter, status int, resource interface{}, name string) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		h.log.Error(models.ErrMarshal(err, name))
+		http.Error(rw, models.ErrMarshal(err, name).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
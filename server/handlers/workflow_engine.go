@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/extensions"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/models/events"
+	promModel "github.com/prometheus/common/model"
+	"github.com/spf13/viper"
+)
+
+const (
+	// workflowSLOInterval is how often slo steps evaluate their query.
+	workflowSLOInterval = 30 * time.Second
+	// workflowSLOWindow is the window evaluated by slo steps, the last value of every series is checked.
+	workflowSLOWindow = time.Minute
+	workflowSLOStep   = 15 * time.Second
+)
+
+// workflowExecution is a run in progress, along with the session it runs on behalf of.
+type workflowExecution struct {
+	req      *http.Request
+	provider models.Provider
+	prefObj  *models.Preference
+	cancel   context.CancelFunc
+	// decisions receives the approval or rejection of the approval step the run is waiting on.
+	decisions chan workflowDecision
+}
+
+type workflowDecision struct {
+	approved bool
+	user     string
+}
+
+// executeWorkflowRun runs the steps of the run one after the other, stopping at the first failure.
+// The run is persisted after every change so that its progress can be followed.
+func (h *Handler) executeWorkflowRun(execution *workflowExecution, name string, def *models.WorkflowDefinition, run *models.WorkflowRun) {
+	defer h.workflowExecutions.Delete(run.ID)
+	defer execution.cancel()
+
+	ctx := execution.req.Context()
+	wp := &models.WorkflowPersister{DB: h.dbHandler}
+	save := func() {
+		if err := wp.SaveRun(run); err != nil {
+			h.log.Warn(ErrWorkflow(err, name))
+		}
+	}
+
+	run.Status = models.WorkflowRunning
+	save()
+	h.publishWorkflowEvent(run, "run", events.Informational, fmt.Sprintf("Workflow '%s' started.", name), nil)
+
+	for ; run.CurrentStep < len(def.Steps); run.CurrentStep++ {
+		step := &def.Steps[run.CurrentStep]
+		status := &run.Steps[run.CurrentStep]
+		startedAt := time.Now()
+		status.Status = models.WorkflowRunning
+		status.StartedAt = &startedAt
+		save()
+
+		err := h.runWorkflowStepWithRetries(ctx, execution, run, step, status, save)
+		finishedAt := time.Now()
+		status.FinishedAt = &finishedAt
+		if err == nil {
+			status.Status = models.WorkflowSucceeded
+			save()
+			continue
+		}
+
+		status.Message = err.Error()
+		if ctx.Err() != nil {
+			status.Status = models.WorkflowCancelled
+			h.finishWorkflowRun(run, models.WorkflowCancelled, save)
+			h.publishWorkflowEvent(run, "cancel", events.Warning, fmt.Sprintf("Workflow '%s' was cancelled at step '%s'.", name, step.Name), nil)
+			return
+		}
+		status.Status = models.WorkflowFailed
+		h.finishWorkflowRun(run, models.WorkflowFailed, save)
+		err = ErrWorkflow(err, name)
+		h.log.Error(err)
+		h.publishWorkflowEvent(run, "run", events.Error, fmt.Sprintf("Workflow '%s' failed at step '%s'.", name, step.Name), map[string]interface{}{
+			"error": err,
+		})
+		return
+	}
+
+	h.finishWorkflowRun(run, models.WorkflowSucceeded, save)
+	h.publishWorkflowEvent(run, "run", events.Informational, fmt.Sprintf("Workflow '%s' succeeded.", name), nil)
+}
+
+func (h *Handler) finishWorkflowRun(run *models.WorkflowRun, status models.WorkflowRunStatus, save func()) {
+	now := time.Now()
+	run.Status = status
+	run.FinishedAt = &now
+	save()
+}
+
+// runWorkflowStepWithRetries runs the step, retrying it as configured. Approval steps are never retried.
+func (h *Handler) runWorkflowStepWithRetries(ctx context.Context, execution *workflowExecution, run *models.WorkflowRun, step *models.WorkflowStep, status *models.WorkflowStepStatus, save func()) error {
+	retryDelay, _ := time.ParseDuration(step.RetryDelay)
+	for {
+		status.Attempts++
+		err := h.runWorkflowStep(ctx, execution, run, step, status, save)
+		if err == nil || ctx.Err() != nil || step.Type == models.WorkflowStepApproval || status.Attempts > step.Retries {
+			return err
+		}
+
+		status.Message = fmt.Sprintf("attempt %d failed, retrying: %s", status.Attempts, err)
+		save()
+		if err := sleepContext(ctx, retryDelay); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *Handler) runWorkflowStep(ctx context.Context, execution *workflowExecution, run *models.WorkflowRun, step *models.WorkflowStep, status *models.WorkflowStepStatus, save func()) error {
+	switch step.Type {
+	case models.WorkflowStepDeploy, models.WorkflowStepUndeploy:
+		return h.runWorkflowDesignStep(ctx, execution, run, step)
+	case models.WorkflowStepWait:
+		duration, _ := time.ParseDuration(step.Duration)
+		return sleepContext(ctx, duration)
+	case models.WorkflowStepSLO:
+		return h.runWorkflowSLOStep(ctx, execution.prefObj, step)
+	case models.WorkflowStepPerfTest:
+		return h.runWorkflowPerfTestStep(ctx, execution, step)
+	case models.WorkflowStepApproval:
+		return h.runWorkflowApprovalStep(ctx, execution, run, step, status, save)
+	case models.WorkflowStepNotify:
+		return models.NotifyWorkflowWebhook(ctx, step.URL, step.Message)
+	}
+	return fmt.Errorf("step %q has an unknown type %q", step.Name, step.Type)
+}
+
+func (h *Handler) runWorkflowDesignStep(ctx context.Context, execution *workflowExecution, run *models.WorkflowRun, step *models.WorkflowStep) error {
+	design, err := h.getManagedDesign(execution.req, execution.provider, uuid.FromStringOrNil(step.Design))
+	if err != nil {
+		return ErrFetchPattern(err)
+	}
+	patternFile, err := core.NewPatternFile([]byte(design.PatternFile))
+	if err != nil {
+		return ErrParsePattern(err)
+	}
+
+	isDelete := step.Type == models.WorkflowStepUndeploy
+	_, err = _processPattern(&core.ProcessPatternOptions{
+		Context:          ctx,
+		Provider:         execution.provider,
+		Pattern:          patternFile,
+		PrefObj:          execution.prefObj,
+		UserID:           run.UserID.String(),
+		IsDelete:         isDelete,
+		SkipPrintLogs:    viper.GetBool("DEBUG"),
+		Registry:         h.registryManager,
+		EventBroadcaster: h.config.EventBroadcaster,
+		Log:              h.log,
+	})
+	if err != nil {
+		return ErrPatternDeploy(err, design.Name)
+	}
+
+	hook := extensions.HookDesignDeployed
+	if isDelete {
+		hook = extensions.HookDesignUndeployed
+	}
+	h.dispatchDesignHook(hook, run.UserID, design.ID, design.Name, map[string]interface{}{
+		"workflow_run_id": run.ID,
+	})
+	return nil
+}
+
+// runWorkflowSLOStep evaluates the query until every series is within the bounds of the step, up to its timeout.
+func (h *Handler) runWorkflowSLOStep(ctx context.Context, prefObj *models.Preference, step *models.WorkflowStep) error {
+	if prefObj == nil || prefObj.Prometheus == nil || prefObj.Prometheus.PrometheusURL == "" {
+		return fmt.Errorf("step %q needs Prometheus to be connected", step.Name)
+	}
+	promURL := prefObj.Prometheus.PrometheusURL
+	timeout, _ := time.ParseDuration(step.Timeout)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(workflowSLOInterval)
+	defer ticker.Stop()
+
+	for {
+		err := h.evaluateWorkflowSLO(ctx, promURL, step)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("the SLO was not met within %s: %w", timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Handler) evaluateWorkflowSLO(ctx context.Context, promURL string, step *models.WorkflowStep) error {
+	end := time.Now()
+	value, err := h.config.PrometheusClient.QueryRangeUsingClient(ctx, promURL, step.Query, end.Add(-workflowSLOWindow), end, workflowSLOStep)
+	if err != nil {
+		return err
+	}
+	matrix, ok := value.(promModel.Matrix)
+	if !ok || len(matrix) == 0 {
+		return fmt.Errorf("the query returned no series")
+	}
+	for _, series := range matrix {
+		if len(series.Values) == 0 {
+			return fmt.Errorf("the series %s has no samples", series.Metric)
+		}
+		last := float64(series.Values[len(series.Values)-1].Value)
+		if step.Min != nil && last < *step.Min {
+			return fmt.Errorf("the series %s is %g, below %g", series.Metric, last, *step.Min)
+		}
+		if step.Max != nil && last > *step.Max {
+			return fmt.Errorf("the series %s is %g, above %g", series.Metric, last, *step.Max)
+		}
+	}
+	return nil
+}
+
+// runWorkflowPerfTestStep runs the performance profile, the results are persisted like those of any other test.
+func (h *Handler) runWorkflowPerfTestStep(ctx context.Context, execution *workflowExecution, step *models.WorkflowStep) error {
+	resp, err := execution.provider.GetPerformanceProfile(execution.req, step.Profile)
+	if err != nil {
+		return ErrFetchProfile(err)
+	}
+	profile := models.PerformanceProfile{}
+	if err := json.Unmarshal(resp, &profile); err != nil {
+		return models.ErrUnmarshal(err, "performance profile")
+	}
+	if len(profile.Endpoints) == 0 {
+		return fmt.Errorf("the performance profile %q has no endpoint", profile.Name)
+	}
+
+	loadTestOptions := &models.LoadTestOptions{
+		Name:               profile.Name,
+		URL:                profile.Endpoints[0],
+		HTTPNumThreads:     profile.ConcurrentRequest,
+		HTTPQPS:            float64(profile.QPS),
+		Headers:            h.jsonToMap(profile.RequestHeaders),
+		Cookies:            h.jsonToMap(profile.RequestCookies),
+		Body:               []byte(profile.RequestBody),
+		ContentType:        profile.ContentType,
+		AllowInitialErrors: true,
+	}
+	loadTestOptions.Duration, err = time.ParseDuration(profile.Duration)
+	if err != nil || loadTestOptions.Duration <= 0 {
+		loadTestOptions.Duration = time.Second
+	}
+	if loadTestOptions.HTTPNumThreads < 1 {
+		loadTestOptions.HTTPNumThreads = 1
+	}
+	if loadTestOptions.HTTPQPS < 0 {
+		loadTestOptions.HTTPQPS = 0
+	}
+	if options, ok := profile.Metadata["additional_options"].(string); ok {
+		loadTestOptions.Options = options
+	}
+	loadTestOptions.LoadGenerator = models.FortioLG
+	if len(profile.LoadGenerators) > 0 {
+		switch profile.LoadGenerators[0] {
+		case models.Wrk2LG.Name():
+			loadTestOptions.LoadGenerator = models.Wrk2LG
+		case models.NighthawkLG.Name():
+			loadTestOptions.LoadGenerator = models.NighthawkLG
+		}
+	}
+
+	respChan := make(chan *models.LoadTestResponse, 100)
+	go func() {
+		h.executeLoadTest(ctx, execution.req, step.Profile, profile.Name, profile.ServiceMesh, "", execution.prefObj, execution.provider, loadTestOptions, respChan)
+		close(respChan)
+	}()
+
+	var testErr error
+	for resp := range respChan {
+		if resp.Status == models.LoadTestError && testErr == nil {
+			testErr = fmt.Errorf("the performance test failed: %s", resp.Message)
+		}
+	}
+	return testErr
+}
+
+// runWorkflowApprovalStep waits for a user to approve or reject the run, up to the timeout of the step if any.
+func (h *Handler) runWorkflowApprovalStep(ctx context.Context, execution *workflowExecution, run *models.WorkflowRun, step *models.WorkflowStep, status *models.WorkflowStepStatus, save func()) error {
+	run.Status = models.WorkflowWaitingApproval
+	status.Status = models.WorkflowWaitingApproval
+	save()
+	h.publishWorkflowEvent(run, "approval", events.Informational, fmt.Sprintf("Step '%s' is waiting for an approval.", step.Name), map[string]interface{}{
+		"approve_link": fmt.Sprintf("/api/workflows/%s/runs/%s/approve", run.WorkflowID, run.ID),
+		"reject_link":  fmt.Sprintf("/api/workflows/%s/runs/%s/reject", run.WorkflowID, run.ID),
+	})
+	defer func() {
+		run.Status = models.WorkflowRunning
+	}()
+
+	var timeout <-chan time.Time
+	if step.Timeout != "" {
+		d, _ := time.ParseDuration(step.Timeout)
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case decision := <-execution.decisions:
+		status.ApprovedBy = decision.user
+		if !decision.approved {
+			return fmt.Errorf("rejected by %s", decision.user)
+		}
+		return nil
+	case <-timeout:
+		return fmt.Errorf("no approval within %s", step.Timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) cancelWorkflowRun(runID uuid.UUID) {
+	if value, ok := h.workflowExecutions.Load(runID); ok {
+		value.(*workflowExecution).cancel()
+	}
+}
+
+// failInterruptedWorkflowRuns fails the runs left unfinished by a previous instance of Meshery Server,
+// they cannot be resumed without the sessions they ran on behalf of.
+func (h *Handler) failInterruptedWorkflowRuns() {
+	wp := &models.WorkflowPersister{DB: h.dbHandler}
+	runs, err := wp.GetUnfinishedRuns()
+	if err != nil {
+		h.log.Warn(ErrWorkflow(err, ""))
+		return
+	}
+	for i := range runs {
+		run := &runs[i]
+		if run.CurrentStep < len(run.Steps) {
+			run.Steps[run.CurrentStep].Status = models.WorkflowFailed
+			run.Steps[run.CurrentStep].Message = "interrupted by a restart of Meshery Server"
+		}
+		h.finishWorkflowRun(run, models.WorkflowFailed, func() {
+			if err := wp.SaveRun(run); err != nil {
+				h.log.Warn(ErrWorkflow(err, ""))
+			}
+		})
+	}
+}
+
+// publishWorkflowEvent notifies the user who started the run. Events are acted upon the run.
+func (h *Handler) publishWorkflowEvent(run *models.WorkflowRun, action string, severity events.EventSeverity, description string, metadata map[string]interface{}) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["workflow_id"] = run.WorkflowID
+
+	event := events.NewEvent().ActedUpon(run.ID).FromUser(run.UserID).FromSystem(*h.SystemID).
+		WithCategory("workflow").WithAction(action).WithSeverity(severity).
+		WithDescription(description).WithMetadata(metadata).Build()
+
+	ep := &models.EventsPersister{DB: h.dbHandler}
+	if err := ep.PersistEvent(event); err != nil {
+		h.log.Warn(err)
+	}
+	go h.config.EventBroadcaster.Publish(run.UserID, event)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
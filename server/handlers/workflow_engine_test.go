@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+)
+
+func newWorkflowTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newEphemeralTestHandler(t)
+	if err := h.dbHandler.AutoMigrate(&models.Workflow{}, &models.WorkflowRun{}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// runTestWorkflow runs the workflow until it is over, after decide is called with the execution of the run.
+func runTestWorkflow(t *testing.T, h *Handler, definition string, decide func(*workflowExecution)) *models.WorkflowRun {
+	t.Helper()
+	def, err := models.ParseWorkflowDefinition([]byte(definition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := models.NewWorkflowRun(&models.Workflow{ID: uuid.Must(uuid.NewV4()), Definition: definition}, def, uuid.Must(uuid.NewV4()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	execution := &workflowExecution{
+		req:       httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx),
+		cancel:    cancel,
+		decisions: make(chan workflowDecision, 1),
+	}
+	h.workflowExecutions.Store(run.ID, execution)
+	if decide != nil {
+		decide(execution)
+	}
+	h.executeWorkflowRun(execution, def.Name, def, run)
+
+	if _, ok := h.workflowExecutions.Load(run.ID); ok {
+		t.Error("expected the execution to be removed once the run is over")
+	}
+	wp := &models.WorkflowPersister{DB: h.dbHandler}
+	saved, err := wp.GetRun(run.ID)
+	if err != nil || saved == nil {
+		t.Fatalf("expected the run to be saved: %v", err)
+	}
+	if saved.FinishedAt == nil {
+		t.Error("expected the run to be finished")
+	}
+	return saved
+}
+
+// newTestWebhook returns the URL of a webhook failing the first requests, and the count of its requests.
+func newTestWebhook(t *testing.T, failures int32) (string, *atomic.Int32) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, calls
+}
+
+func TestExecuteWorkflowRun_Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int32
+		retries    int
+		wantStatus models.WorkflowRunStatus
+		wantCalls  int32
+	}{
+		{name: "succeeds first", failures: 0, retries: 2, wantStatus: models.WorkflowSucceeded, wantCalls: 1},
+		{name: "succeeds after retries", failures: 2, retries: 2, wantStatus: models.WorkflowSucceeded, wantCalls: 3},
+		{name: "retries exhausted", failures: 3, retries: 2, wantStatus: models.WorkflowFailed, wantCalls: 3},
+		{name: "no retries", failures: 1, retries: 0, wantStatus: models.WorkflowFailed, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkflowTestHandler(t)
+			url, calls := newTestWebhook(t, tt.failures)
+			run := runTestWorkflow(t, h, fmt.Sprintf(`
+name: notify
+steps:
+  - {name: notify, type: notify, url: %q, message: deployed, retries: %d, retry_delay: 1ms}
+`, url, tt.retries), nil)
+			if run.Status != tt.wantStatus || run.Steps[0].Status != tt.wantStatus {
+				t.Errorf("status = %s, step %s, want %s", run.Status, run.Steps[0].Status, tt.wantStatus)
+			}
+			if calls.Load() != tt.wantCalls || run.Steps[0].Attempts != int(tt.wantCalls) {
+				t.Errorf("%d calls in %d attempts, want %d", calls.Load(), run.Steps[0].Attempts, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestExecuteWorkflowRun_Approval(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     string
+		decision    *workflowDecision
+		wantStatus  models.WorkflowRunStatus
+		wantMessage string
+		wantNotify  int32
+	}{
+		{name: "approved", decision: &workflowDecision{approved: true, user: "jane"}, wantStatus: models.WorkflowSucceeded, wantNotify: 1},
+		{name: "rejected", decision: &workflowDecision{approved: false, user: "jane"}, wantStatus: models.WorkflowFailed, wantMessage: "rejected by jane"},
+		{name: "timed out", timeout: "10ms", wantStatus: models.WorkflowFailed, wantMessage: "no approval within 10ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkflowTestHandler(t)
+			url, calls := newTestWebhook(t, 0)
+			timeout := ""
+			if tt.timeout != "" {
+				timeout = ", timeout: " + tt.timeout
+			}
+			run := runTestWorkflow(t, h, fmt.Sprintf(`
+name: promote
+steps:
+  - {name: approve, type: approval, retries: 2%s}
+  - {name: notify, type: notify, url: %q, message: promoted}
+`, timeout, url), func(execution *workflowExecution) {
+				if tt.decision != nil {
+					execution.decisions <- *tt.decision
+				}
+			})
+
+			approval := run.Steps[0]
+			if run.Status != tt.wantStatus || approval.Status != tt.wantStatus {
+				t.Errorf("status = %s, step %s, want %s", run.Status, approval.Status, tt.wantStatus)
+			}
+			if approval.Attempts != 1 {
+				t.Errorf("expected approval steps not to be retried, got %d attempts", approval.Attempts)
+			}
+			if !strings.Contains(approval.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want %q", approval.Message, tt.wantMessage)
+			}
+			if tt.decision != nil && approval.ApprovedBy != tt.decision.user {
+				t.Errorf("approved by %q, want %q", approval.ApprovedBy, tt.decision.user)
+			}
+			if calls.Load() != tt.wantNotify {
+				t.Errorf("expected the steps after the approval to run only once approved, got %d notifications", calls.Load())
+			}
+		})
+	}
+}
+
+func TestExecuteWorkflowRun_Cancelled(t *testing.T) {
+	h := newWorkflowTestHandler(t)
+	run := runTestWorkflow(t, h, `
+name: wait
+steps:
+  - {name: wait, type: wait, duration: 1h, retries: 3}
+  - {name: wait again, type: wait, duration: 1h}
+`, func(execution *workflowExecution) {
+		time.AfterFunc(10*time.Millisecond, execution.cancel)
+	})
+
+	if run.Status != models.WorkflowCancelled || run.Steps[0].Status != models.WorkflowCancelled {
+		t.Errorf("status = %s, step %s, want %s", run.Status, run.Steps[0].Status, models.WorkflowCancelled)
+	}
+	if run.Steps[0].Attempts != 1 {
+		t.Errorf("expected a cancelled step not to be retried, got %d attempts", run.Steps[0].Attempts)
+	}
+	if run.Steps[1].Status != models.WorkflowPending {
+		t.Errorf("expected the next step not to run, got %s", run.Steps[1].Status)
+	}
+}
+
+func TestFailInterruptedWorkflowRuns(t *testing.T) {
+	h := newWorkflowTestHandler(t)
+	wp := &models.WorkflowPersister{DB: h.dbHandler}
+	def := &models.WorkflowDefinition{Name: "promote", Steps: []models.WorkflowStep{{Name: "deploy"}, {Name: "approve"}}}
+
+	runs := map[models.WorkflowRunStatus]*models.WorkflowRun{}
+	for _, status := range []models.WorkflowRunStatus{models.WorkflowRunning, models.WorkflowWaitingApproval, models.WorkflowSucceeded} {
+		run, err := models.NewWorkflowRun(&models.Workflow{ID: uuid.Must(uuid.NewV4())}, def, uuid.Must(uuid.NewV4()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		run.Status = status
+		run.CurrentStep = 1
+		if err := wp.SaveRun(run); err != nil {
+			t.Fatal(err)
+		}
+		runs[status] = run
+	}
+
+	h.failInterruptedWorkflowRuns()
+
+	for status, run := range runs {
+		saved, err := wp.GetRun(run.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status == models.WorkflowSucceeded {
+			if saved.Status != models.WorkflowSucceeded {
+				t.Errorf("expected a finished run to be left alone, got %s", saved.Status)
+			}
+			continue
+		}
+		if saved.Status != models.WorkflowFailed || saved.FinishedAt == nil {
+			t.Errorf("%s: expected the run to be failed, got %s", status, saved.Status)
+		}
+		if step := saved.Steps[1]; step.Status != models.WorkflowFailed || !strings.Contains(step.Message, "interrupted") {
+			t.Errorf("%s: expected the current step to be failed, got %+v", status, step)
+		}
+		if saved.Steps[0].Status != models.WorkflowPending {
+			t.Errorf("%s: expected the other steps to be left alone, got %s", status, saved.Steps[0].Status)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// swagger:route GET /api/workflows WorkflowsAPI idGetWorkflows
+// Handle GET request for the workflows of the user
+//
+// responses:
+// 	200: workflowsResponseWrapper
+
+func (h *Handler) GetWorkflowsHandler(rw http.ResponseWriter, _ *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	workflows, err := wp.GetWorkflows(uuid.FromStringOrNil(user.ID))
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, ""))
+		http.Error(rw, ErrWorkflow(err, "").Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, workflows, "workflows")
+}
+
+// swagger:route POST /api/workflows WorkflowsAPI idSaveWorkflow
+// Handle POST request to store a workflow
+//
+// The body is the workflow in YAML: a name and ordered steps of type deploy, undeploy, wait, slo,
+// perf_test, approval or notify, each optionally retried.
+// responses:
+// 	201: workflowResponseWrapper
+
+func (h *Handler) SaveWorkflowHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.saveWorkflow(rw, r, user, provider, nil)
+}
+
+// swagger:route GET /api/workflows/{id} WorkflowsAPI idGetWorkflow
+// Handle GET request for a workflow
+//
+// responses:
+// 	200: workflowResponseWrapper
+
+func (h *Handler) GetWorkflowHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, workflow, "workflow")
+}
+
+// swagger:route PUT /api/workflows/{id} WorkflowsAPI idUpdateWorkflow
+// Handle PUT request to replace the definition of a workflow
+//
+// Runs in progress keep running the definition they started with.
+// responses:
+// 	200: workflowResponseWrapper
+
+func (h *Handler) UpdateWorkflowHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.saveWorkflow(rw, r, user, provider, workflow)
+}
+
+// swagger:route DELETE /api/workflows/{id} WorkflowsAPI idDeleteWorkflow
+// Handle DELETE request to delete a workflow along with its runs
+//
+// Runs in progress are cancelled.
+// responses:
+// 	200:
+
+func (h *Handler) DeleteWorkflowHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return
+	}
+
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	runs, err := wp.GetRuns(workflow.ID)
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, run := range runs {
+		h.cancelWorkflowRun(run.ID)
+	}
+	if err := wp.DeleteWorkflow(workflow.ID); err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// swagger:route POST /api/workflows/{id}/runs WorkflowsAPI idRunWorkflow
+// Handle POST request to run a workflow
+//
+// The steps run in the background on behalf of the user, against the Kubernetes contexts of the request.
+// The run is returned as soon as it starts, its progress is reported by its steps and by events.
+// responses:
+// 	202: workflowRunResponseWrapper
+
+func (h *Handler) RunWorkflowHandler(rw http.ResponseWriter, r *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	def, err := models.ParseWorkflowDefinition([]byte(workflow.Definition))
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusBadRequest)
+		return
+	}
+
+	run, err := models.NewWorkflowRun(workflow, def, uuid.FromStringOrNil(user.ID))
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	if err := wp.SaveRun(run); err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The run outlives the request: keep its values, eg. the token and the Kubernetes contexts, but not its cancellation.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	req := r.Clone(ctx)
+	req.Body = http.NoBody
+	execution := &workflowExecution{
+		req:       req,
+		provider:  provider,
+		prefObj:   prefObj,
+		cancel:    cancel,
+		decisions: make(chan workflowDecision, 1),
+	}
+	h.workflowExecutions.Store(run.ID, execution)
+	go h.executeWorkflowRun(execution, workflow.Name, def, run)
+
+	h.writeJSONResource(rw, http.StatusAccepted, run, "workflow run")
+}
+
+// swagger:route GET /api/workflows/{id}/runs WorkflowsAPI idGetWorkflowRuns
+// Handle GET request for the runs of a workflow
+//
+// Returns the runs most recent first.
+// responses:
+// 	200: workflowRunsResponseWrapper
+
+func (h *Handler) GetWorkflowRunsHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	runs, err := wp.GetRuns(workflow.ID)
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, runs, "workflow runs")
+}
+
+// swagger:route GET /api/workflows/{id}/runs/{runID} WorkflowsAPI idGetWorkflowRun
+// Handle GET request for a run of a workflow, along with the status of its steps
+//
+// responses:
+// 	200: workflowRunResponseWrapper
+
+func (h *Handler) GetWorkflowRunHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	run, ok := h.getWorkflowRun(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	h.writeJSONResource(rw, http.StatusOK, run, "workflow run")
+}
+
+// swagger:route POST /api/workflows/{id}/runs/{runID}/approve WorkflowsAPI idApproveWorkflowRun
+// Handle POST request to approve the approval step a run is waiting on
+//
+// The run resumes with the next step.
+// responses:
+// 	200:
+
+func (h *Handler) ApproveWorkflowRunHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.decideWorkflowRun(rw, r, user, provider, true)
+}
+
+// swagger:route POST /api/workflows/{id}/runs/{runID}/reject WorkflowsAPI idRejectWorkflowRun
+// Handle POST request to reject the approval step a run is waiting on
+//
+// The run fails.
+// responses:
+// 	200:
+
+func (h *Handler) RejectWorkflowRunHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	h.decideWorkflowRun(rw, r, user, provider, false)
+}
+
+// swagger:route POST /api/workflows/{id}/runs/{runID}/cancel WorkflowsAPI idCancelWorkflowRun
+// Handle POST request to cancel a run
+//
+// The step in progress is interrupted and the remaining steps are skipped.
+// responses:
+// 	200:
+
+func (h *Handler) CancelWorkflowRunHandler(rw http.ResponseWriter, r *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	run, ok := h.getWorkflowRun(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	if run.Status.Done() {
+		http.Error(rw, fmt.Sprintf("workflow run %s is already %s", run.ID, run.Status), http.StatusConflict)
+		return
+	}
+	h.cancelWorkflowRun(run.ID)
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) decideWorkflowRun(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, approved bool) {
+	run, ok := h.getWorkflowRun(rw, r, user, provider)
+	if !ok {
+		return
+	}
+	if run.Status != models.WorkflowWaitingApproval {
+		http.Error(rw, fmt.Sprintf("workflow run %s is not waiting for an approval", run.ID), http.StatusConflict)
+		return
+	}
+	value, ok := h.workflowExecutions.Load(run.ID)
+	if !ok {
+		http.Error(rw, fmt.Sprintf("workflow run %s is no longer running", run.ID), http.StatusConflict)
+		return
+	}
+
+	select {
+	case value.(*workflowExecution).decisions <- workflowDecision{approved: approved, user: user.UserID}:
+		rw.WriteHeader(http.StatusOK)
+	default:
+		http.Error(rw, fmt.Sprintf("workflow run %s was already approved or rejected", run.ID), http.StatusConflict)
+	}
+}
+
+func (h *Handler) saveWorkflow(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider, workflow *models.Workflow) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, ErrRequestBody(err).Error(), http.StatusBadRequest)
+		return
+	}
+	def, err := models.ParseWorkflowDefinition(body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	if workflow == nil {
+		workflow = &models.Workflow{UserID: uuid.FromStringOrNil(user.ID)}
+		status = http.StatusCreated
+	}
+	workflow.Name = def.Name
+	workflow.Description = def.Description
+	workflow.Definition = string(body)
+
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	if err := wp.SaveWorkflow(workflow); err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResource(rw, status, workflow, "workflow")
+}
+
+// getWorkflow returns the workflow of the request, writing the error response if it is not a workflow of the user.
+func (h *Handler) getWorkflow(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.Workflow, bool) {
+	id, err := uuid.FromString(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	workflow, err := wp.GetWorkflow(id)
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, id.String()))
+		http.Error(rw, ErrWorkflow(err, id.String()).Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if workflow == nil || workflow.UserID != uuid.FromStringOrNil(user.ID) {
+		http.Error(rw, fmt.Sprintf("workflow %s does not exist", id), http.StatusNotFound)
+		return nil, false
+	}
+	return workflow, true
+}
+
+// getWorkflowRun returns the run of the request, writing the error response if it is not a run of a workflow of the user.
+func (h *Handler) getWorkflowRun(rw http.ResponseWriter, r *http.Request, user *models.User, provider models.Provider) (*models.WorkflowRun, bool) {
+	workflow, ok := h.getWorkflow(rw, r, user, provider)
+	if !ok {
+		return nil, false
+	}
+	runID, err := uuid.FromString(mux.Vars(r)["runID"])
+	if err != nil {
+		http.Error(rw, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	wp := &models.WorkflowPersister{DB: provider.GetGenericPersister()}
+	run, err := wp.GetRun(runID)
+	if err != nil {
+		h.log.Error(ErrWorkflow(err, workflow.Name))
+		http.Error(rw, ErrWorkflow(err, workflow.Name).Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if run == nil || run.WorkflowID != workflow.ID {
+		http.Error(rw, fmt.Sprintf("workflow run %s does not exist", runID), http.StatusNotFound)
+		return nil, false
+	}
+	return run, true
+}
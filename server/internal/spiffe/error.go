@@ -0,0 +1,21 @@
+package spiffe
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+// Please reference the following before contributing an error code:
+// https://docs.meshery.io/project/contributing/contributing-error
+// https://github.com/meshery/meshkit/blob/master/errors/errors.go
+const (
+	ErrSPIFFEConfigCode   = "meshery-server-1397"
+	ErrVerifyPeerSVIDCode = "meshery-server-1398"
+)
+
+func ErrSPIFFEConfig(err error) error {
+	return errors.New(ErrSPIFFEConfigCode, errors.Fatal, []string{"Unable to set up mutual TLS with SPIFFE identities"}, []string{err.Error()}, []string{"The X.509 SVID, its key or the trust bundle cannot be read", "The X.509 SVID has no SPIFFE ID", "The trust domain or an authorized ID is not valid"}, []string{"Ensure the SPIRE agent writes the SVID and the trust bundle to the configured files", "Ensure SPIFFE_TRUST_DOMAIN is set and SPIFFE_AUTHORIZED_IDS only lists SPIFFE IDs"})
+}
+
+func ErrVerifyPeerSVID(err error) error {
+	return errors.New(ErrVerifyPeerSVIDCode, errors.Alert, []string{"Unable to verify the identity of the peer"}, []string{err.Error()}, []string{"The peer presented no X.509 SVID or an expired one", "The SVID of the peer is not issued by the trust domain", "The SPIFFE ID of the peer is not authorized"}, []string{"Ensure the peer is registered with SPIRE in the trust domain", "Add the SPIFFE ID of the peer to SPIFFE_AUTHORIZED_IDS"})
+}
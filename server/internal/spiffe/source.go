@@ -0,0 +1,150 @@
+package spiffe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// source reads the SVID and the trust bundle from their files, reloading them when they change.
+type source struct {
+	certFile, keyFile, bundleFile string
+
+	mu            sync.Mutex
+	cert          *tls.Certificate
+	certModTime   time.Time
+	keyModTime    time.Time
+	pool          *x509.CertPool
+	bundleModTime time.Time
+}
+
+func (s *source) svid() (*tls.Certificate, error) {
+	certModTime, err := modTime(s.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyModTime, err := modTime(s.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cert != nil && certModTime.Equal(s.certModTime) && keyModTime.Equal(s.keyModTime) {
+		return s.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := idFromCertificate(leaf); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.certFile, err)
+	}
+	cert.Leaf = leaf
+
+	s.cert, s.certModTime, s.keyModTime = &cert, certModTime, keyModTime
+	return s.cert, nil
+}
+
+func (s *source) bundle() (*x509.CertPool, error) {
+	bundleModTime, err := modTime(s.bundleFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pool != nil && bundleModTime.Equal(s.bundleModTime) {
+		return s.pool, nil
+	}
+
+	data, err := os.ReadFile(s.bundleFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.bundleFile, err)
+		}
+		pool.AddCert(cert)
+	}
+	if pool.Equal(x509.NewCertPool()) {
+		return nil, fmt.Errorf("%s holds no certificate", s.bundleFile)
+	}
+
+	s.pool, s.bundleModTime = pool, bundleModTime
+	return s.pool, nil
+}
+
+func (s *source) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.svid()
+}
+
+func (s *source) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return s.svid()
+}
+
+// verifyPeer returns a verification of the SVID presented by the peer: it must chain up to the
+// trust bundle and carry a SPIFFE ID allowed by the authorizer.
+func (s *source) verifyPeer(authorize authorizer, usage x509.ExtKeyUsage) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return ErrVerifyPeerSVID(fmt.Errorf("the peer presented no X.509 SVID"))
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return ErrVerifyPeerSVID(err)
+			}
+			certs = append(certs, cert)
+		}
+
+		roots, err := s.bundle()
+		if err != nil {
+			return ErrVerifyPeerSVID(err)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{usage},
+		}); err != nil {
+			return ErrVerifyPeerSVID(err)
+		}
+
+		id, err := idFromCertificate(certs[0])
+		if err != nil {
+			return ErrVerifyPeerSVID(err)
+		}
+		if err := authorize(id); err != nil {
+			return ErrVerifyPeerSVID(err)
+		}
+		return nil
+	}
+}
+
+func modTime(name string) (time.Time, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
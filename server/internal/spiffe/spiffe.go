@@ -0,0 +1,166 @@
+// Package spiffe secures the management plane connections of Meshery Server with mutual TLS
+// based on SPIFFE identities, so that every connection verifies the workload identity of its
+// peer instead of relying on the network or on shared tokens.
+//
+// The X.509 SVID of Meshery Server and the trust bundle are read from files kept up to date
+// by the SPIRE agent, eg. through spiffe-helper. They are reloaded whenever they change, so
+// rotated SVIDs are picked up without restarting. Peers are authenticated by verifying their
+// SVID against the trust bundle and authorized by their SPIFFE ID: any ID of the trust domain,
+// or only the configured IDs.
+//
+// The connections to adapters and the connections of in-cluster agents to the management
+// service are secured when enabled. The connection to the MeshSync broker (NATS) is out of
+// scope: it is set up by the broker deployment and does not use SPIFFE identities.
+package spiffe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// EnabledENV enables mutual TLS with SPIFFE identities.
+	EnabledENV = "SPIFFE_ENABLED"
+	// SVIDCertFileENV is the PEM file holding the X.509 SVID of Meshery Server, leaf first.
+	SVIDCertFileENV = "SPIFFE_SVID_CERT_FILE"
+	// SVIDKeyFileENV is the PEM file holding the private key of the X.509 SVID.
+	SVIDKeyFileENV = "SPIFFE_SVID_KEY_FILE"
+	// BundleFileENV is the PEM file holding the X.509 trust bundle of the trust domain.
+	BundleFileENV = "SPIFFE_BUNDLE_FILE"
+	// TrustDomainENV is the trust domain of the peers, eg. meshery.example.org.
+	TrustDomainENV = "SPIFFE_TRUST_DOMAIN"
+	// AuthorizedIDsENV restricts the peers to a space separated list of SPIFFE IDs,
+	// eg. spiffe://meshery.example.org/ns/meshery/sa/meshery-istio.
+	AuthorizedIDsENV = "SPIFFE_AUTHORIZED_IDS"
+)
+
+// Config configures mutual TLS with SPIFFE identities.
+type Config struct {
+	CertFile    string
+	KeyFile     string
+	BundleFile  string
+	TrustDomain string
+	// AuthorizedIDs are the SPIFFE IDs of the peers allowed to connect,
+	// any ID of the trust domain is allowed when empty.
+	AuthorizedIDs []string
+}
+
+// ID is a SPIFFE ID, spiffe://{trust domain}/{path}.
+type ID struct {
+	TrustDomain string
+	Path        string
+}
+
+func (id ID) String() string {
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+// ParseID parses the SPIFFE ID, following the SPIFFE ID specification.
+func ParseID(raw string) (ID, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ID{}, err
+	}
+	return idFromURL(u)
+}
+
+func idFromURL(u *url.URL) (ID, error) {
+	switch {
+	case u.Scheme != "spiffe":
+		return ID{}, fmt.Errorf("%q is not a SPIFFE ID, the scheme must be spiffe", u.String())
+	case u.Host == "" || u.Port() != "" || u.User != nil:
+		return ID{}, fmt.Errorf("%q is not a SPIFFE ID, the authority must be a trust domain", u.String())
+	case u.RawQuery != "" || u.Fragment != "":
+		return ID{}, fmt.Errorf("%q is not a SPIFFE ID, it must not have a query or a fragment", u.String())
+	}
+	return ID{TrustDomain: strings.ToLower(u.Host), Path: u.Path}, nil
+}
+
+// idFromCertificate returns the SPIFFE ID of the X.509 SVID, its only URI SAN.
+func idFromCertificate(cert *x509.Certificate) (ID, error) {
+	if len(cert.URIs) != 1 {
+		return ID{}, fmt.Errorf("an X.509 SVID must have exactly one URI SAN, found %d", len(cert.URIs))
+	}
+	return idFromURL(cert.URIs[0])
+}
+
+// authorizer returns whether the SPIFFE ID of a peer is allowed to connect.
+type authorizer func(id ID) error
+
+func newAuthorizer(cfg Config) (authorizer, error) {
+	trustDomain := strings.ToLower(cfg.TrustDomain)
+	if trustDomain == "" {
+		return nil, fmt.Errorf("the trust domain is not set")
+	}
+	allowed := make(map[ID]bool, len(cfg.AuthorizedIDs))
+	for _, raw := range cfg.AuthorizedIDs {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := ParseID(raw)
+		if err != nil {
+			return nil, err
+		}
+		allowed[id] = true
+	}
+
+	return func(id ID) error {
+		if id.TrustDomain != trustDomain {
+			return fmt.Errorf("%s is not a member of the trust domain %s", id, trustDomain)
+		}
+		if len(allowed) > 0 && !allowed[id] {
+			return fmt.Errorf("%s is not authorized", id)
+		}
+		return nil
+	}, nil
+}
+
+// NewClientTLSConfig returns the TLS configuration of connections to peers, eg. adapters,
+// presenting the SVID of Meshery Server and verifying the SVID of the peer.
+func NewClientTLSConfig(cfg Config) (*tls.Config, error) {
+	src, authorize, err := newSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// SVIDs are not issued for host names, the peer is verified by its SPIFFE ID instead.
+		InsecureSkipVerify:    true, //nolint:gosec
+		GetClientCertificate:  src.getClientCertificate,
+		VerifyPeerCertificate: src.verifyPeer(authorize, x509.ExtKeyUsageServerAuth),
+	}, nil
+}
+
+// NewServerTLSConfig returns the TLS configuration of connections from peers, eg. in-cluster
+// agents, presenting the SVID of Meshery Server and requiring the SVID of the peer.
+func NewServerTLSConfig(cfg Config) (*tls.Config, error) {
+	src, authorize, err := newSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:            tls.VersionTLS12,
+		ClientAuth:            tls.RequireAnyClientCert,
+		GetCertificate:        src.getCertificate,
+		VerifyPeerCertificate: src.verifyPeer(authorize, x509.ExtKeyUsageClientAuth),
+	}, nil
+}
+
+func newSource(cfg Config) (*source, authorizer, error) {
+	authorize, err := newAuthorizer(cfg)
+	if err != nil {
+		return nil, nil, ErrSPIFFEConfig(err)
+	}
+	src := &source{certFile: cfg.CertFile, keyFile: cfg.KeyFile, bundleFile: cfg.BundleFile}
+	// fail fast on a misconfiguration rather than on the first connection
+	if _, err := src.svid(); err != nil {
+		return nil, nil, ErrSPIFFEConfig(err)
+	}
+	if _, err := src.bundle(); err != nil {
+		return nil, nil, ErrSPIFFEConfig(err)
+	}
+	return src, authorize, nil
+}
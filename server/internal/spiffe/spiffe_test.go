@@ -0,0 +1,376 @@
+package spiffe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues X.509 SVIDs for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+type svidOptions struct {
+	// uris are the URI SANs of the SVID
+	uris     []string
+	notAfter time.Time
+}
+
+// issue returns the DER certificate and the key of an SVID with the given URI SANs, usable by clients and servers.
+func (ca *testCA) issue(t *testing.T, opts svidOptions) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.notAfter.IsZero() {
+		opts.notAfter = time.Now().Add(time.Hour)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     opts.notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, raw := range opts.uris {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = append(template.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, key
+}
+
+// writeSVID writes the SVID, its key and the bundle of the CA to the files of cfg.
+func writeSVID(t *testing.T, cfg Config, ca *testCA, der []byte, key *ecdsa.PrivateKey) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		cfg.CertFile:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		cfg.KeyFile:    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cfg.BundleFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(name, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestConfig(t *testing.T, authorizedIDs ...string) Config {
+	dir := t.TempDir()
+	return Config{
+		CertFile:      filepath.Join(dir, "svid.pem"),
+		KeyFile:       filepath.Join(dir, "svid_key.pem"),
+		BundleFile:    filepath.Join(dir, "bundle.pem"),
+		TrustDomain:   "meshery.example.org",
+		AuthorizedIDs: authorizedIDs,
+	}
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    ID
+		wantErr bool
+	}{
+		{raw: "spiffe://meshery.example.org/ns/meshery/sa/meshery", want: ID{TrustDomain: "meshery.example.org", Path: "/ns/meshery/sa/meshery"}},
+		{raw: "spiffe://Meshery.Example.org/adapter", want: ID{TrustDomain: "meshery.example.org", Path: "/adapter"}},
+		{raw: "https://meshery.example.org/adapter", wantErr: true},
+		{raw: "spiffe:///adapter", wantErr: true},
+		{raw: "spiffe://meshery.example.org:8443/adapter", wantErr: true},
+		{raw: "spiffe://user@meshery.example.org/adapter", wantErr: true},
+		{raw: "spiffe://meshery.example.org/adapter?version=1", wantErr: true},
+		{raw: "spiffe://meshery.example.org/adapter#v1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseID(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPeer(t *testing.T) {
+	const adapter = "spiffe://meshery.example.org/ns/meshery/sa/meshery-istio"
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	cfg := newTestConfig(t, adapter)
+	der, key := ca.issue(t, svidOptions{uris: []string{"spiffe://meshery.example.org/ns/meshery/sa/meshery"}})
+	writeSVID(t, cfg, ca, der, key)
+	src, authorize, err := newSource(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := src.verifyPeer(authorize, x509.ExtKeyUsageServerAuth)
+
+	peer := func(ca *testCA, opts svidOptions) [][]byte {
+		der, _ := ca.issue(t, opts)
+		return [][]byte{der}
+	}
+	tests := []struct {
+		name    string
+		certs   [][]byte
+		wantErr bool
+	}{
+		{name: "authorized", certs: peer(ca, svidOptions{uris: []string{adapter}})},
+		{name: "no SVID", certs: nil, wantErr: true},
+		{name: "wrong trust domain", certs: peer(ca, svidOptions{uris: []string{"spiffe://other.example.org/ns/meshery/sa/meshery-istio"}}), wantErr: true},
+		{name: "not an authorized ID", certs: peer(ca, svidOptions{uris: []string{"spiffe://meshery.example.org/ns/meshery/sa/meshery-linkerd"}}), wantErr: true},
+		{name: "expired", certs: peer(ca, svidOptions{uris: []string{adapter}, notAfter: time.Now().Add(-time.Hour)}), wantErr: true},
+		{name: "no URI SAN", certs: peer(ca, svidOptions{}), wantErr: true},
+		{name: "several URI SANs", certs: peer(ca, svidOptions{uris: []string{adapter, "spiffe://meshery.example.org/other"}}), wantErr: true},
+		{name: "not rooted in the bundle", certs: peer(otherCA, svidOptions{uris: []string{adapter}}), wantErr: true},
+		{name: "not a certificate", certs: [][]byte{[]byte("invalid")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(tt.certs, nil); (err != nil) != tt.wantErr {
+				t.Errorf("verifyPeer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		id      string
+		wantErr bool
+	}{
+		{name: "any ID of the trust domain", cfg: Config{TrustDomain: "Meshery.example.org"}, id: "spiffe://meshery.example.org/adapter"},
+		{name: "another trust domain", cfg: Config{TrustDomain: "meshery.example.org"}, id: "spiffe://other.example.org/adapter", wantErr: true},
+		{name: "authorized ID", cfg: Config{TrustDomain: "meshery.example.org", AuthorizedIDs: []string{" spiffe://meshery.example.org/adapter", ""}}, id: "spiffe://meshery.example.org/adapter"},
+		{name: "unauthorized ID", cfg: Config{TrustDomain: "meshery.example.org", AuthorizedIDs: []string{"spiffe://meshery.example.org/adapter"}}, id: "spiffe://meshery.example.org/agent", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorize, err := newAuthorizer(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, err := ParseID(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := authorize(id); (err != nil) != tt.wantErr {
+				t.Errorf("authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for name, cfg := range map[string]Config{
+		"no trust domain":       {},
+		"invalid authorized ID": {TrustDomain: "meshery.example.org", AuthorizedIDs: []string{"https://meshery.example.org/adapter"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newAuthorizer(cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSourceReloadsRotatedSVID(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := newTestConfig(t)
+	der, key := ca.issue(t, svidOptions{uris: []string{"spiffe://meshery.example.org/server"}})
+	writeSVID(t, cfg, ca, der, key)
+	src, _, err := newSource(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := src.svid()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotatedCA := newTestCA(t)
+	rotated, rotatedKey := rotatedCA.issue(t, svidOptions{uris: []string{"spiffe://meshery.example.org/server"}})
+	writeSVID(t, cfg, rotatedCA, rotated, rotatedKey)
+	// the SPIRE agent writes the rotated SVID later, make sure the modification times differ
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{cfg.CertFile, cfg.KeyFile, cfg.BundleFile} {
+		if err := os.Chtimes(name, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := src.svid()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Leaf.Equal(second.Leaf) || !second.Leaf.Equal(mustParseCertificate(t, rotated)) {
+		t.Error("expected the rotated SVID to be reloaded")
+	}
+	roots, err := src.bundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Leaf.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Errorf("expected the rotated bundle to be reloaded: %v", err)
+	}
+}
+
+func TestNewSourceErrors(t *testing.T) {
+	ca := newTestCA(t)
+	tests := map[string]func(cfg Config){
+		"missing SVID": func(cfg Config) {
+			der, key := ca.issue(t, svidOptions{uris: []string{"spiffe://meshery.example.org/server"}})
+			writeSVID(t, cfg, ca, der, key)
+			_ = os.Remove(cfg.CertFile)
+		},
+		"SVID without SPIFFE ID": func(cfg Config) {
+			der, key := ca.issue(t, svidOptions{})
+			writeSVID(t, cfg, ca, der, key)
+		},
+		"empty bundle": func(cfg Config) {
+			der, key := ca.issue(t, svidOptions{uris: []string{"spiffe://meshery.example.org/server"}})
+			writeSVID(t, cfg, ca, der, key)
+			if err := os.WriteFile(cfg.BundleFile, nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			setup(cfg)
+			if _, _, err := newSource(cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	const (
+		serverID = "spiffe://meshery.example.org/server"
+		agentID  = "spiffe://meshery.example.org/agent"
+	)
+	ca := newTestCA(t)
+
+	tests := []struct {
+		name    string
+		peerID  string
+		peerCA  *testCA
+		wantErr bool
+	}{
+		{name: "authorized agent", peerID: agentID, peerCA: ca},
+		{name: "unauthorized agent", peerID: "spiffe://meshery.example.org/other", peerCA: ca, wantErr: true},
+		{name: "agent of another trust domain", peerID: "spiffe://other.example.org/agent", peerCA: ca, wantErr: true},
+		{name: "agent not rooted in the bundle", peerID: agentID, peerCA: newTestCA(t), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverCfg := newTestConfig(t, agentID)
+			der, key := ca.issue(t, svidOptions{uris: []string{serverID}})
+			writeSVID(t, serverCfg, ca, der, key)
+			serverTLS, err := NewServerTLSConfig(serverCfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the agent trusts the server, whatever the trust of the server in the agent
+			clientCfg := newTestConfig(t, serverID)
+			der, key = tt.peerCA.issue(t, svidOptions{uris: []string{tt.peerID}})
+			writeSVID(t, clientCfg, ca, der, key)
+			clientCfg.TrustDomain = "meshery.example.org"
+			clientTLS, err := NewClientTLSConfig(clientCfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			listener, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+			serverErr := make(chan error, 1)
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					serverErr <- err
+					return
+				}
+				defer conn.Close()
+				serverErr <- conn.(*tls.Conn).Handshake()
+			}()
+			clientConn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", listener.Addr().String(), clientTLS)
+			clientErr := err
+			if err == nil {
+				defer clientConn.Close()
+			}
+			if err := <-serverErr; (err != nil) != tt.wantErr {
+				t.Errorf("server handshake error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && clientErr != nil {
+				t.Errorf("client handshake error = %v", clientErr)
+			}
+		})
+	}
+}
+
+func mustParseCertificate(t *testing.T, der []byte) *x509.Certificate {
+	t.Helper()
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
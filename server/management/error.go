@@ -0,0 +1,18 @@
+package management
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// Please reference the following before contributing an error code:
+// https://docs.meshery.io/project/contributing/contributing-error
+// https://github.com/meshery/meshkit/blob/master/errors/errors.go
+const (
+	ErrManagementServerCode = "meshery-server-1395"
+)
+
+func ErrManagementServer(err error, port int) error {
+	return errors.New(ErrManagementServerCode, errors.Alert, []string{fmt.Sprintf("Unable to serve the management service on port %d", port)}, []string{err.Error()}, []string{"The port is already in use.", "The port is not allowed to be bound by Meshery Server."}, []string{fmt.Sprintf("Make sure that the port set in %s is free, or unset it to disable the management service.", PortENV)})
+}
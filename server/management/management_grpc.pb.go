@@ -0,0 +1,479 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.23.2
+// source: management.proto
+
+package management
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ManagementService_ListDesigns_FullMethodName    = "/management.ManagementService/ListDesigns"
+	ManagementService_GetDesign_FullMethodName      = "/management.ManagementService/GetDesign"
+	ManagementService_CreateDesign_FullMethodName   = "/management.ManagementService/CreateDesign"
+	ManagementService_UpdateDesign_FullMethodName   = "/management.ManagementService/UpdateDesign"
+	ManagementService_DeleteDesign_FullMethodName   = "/management.ManagementService/DeleteDesign"
+	ManagementService_DeployDesign_FullMethodName   = "/management.ManagementService/DeployDesign"
+	ManagementService_UndeployDesign_FullMethodName = "/management.ManagementService/UndeployDesign"
+	ManagementService_ListModels_FullMethodName     = "/management.ManagementService/ListModels"
+	ManagementService_ListComponents_FullMethodName = "/management.ManagementService/ListComponents"
+	ManagementService_StreamEvents_FullMethodName   = "/management.ManagementService/StreamEvents"
+)
+
+// ManagementServiceClient is the client API for ManagementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ManagementService mirrors the core operations of the REST API for programmatic clients.
+// Calls are authenticated like REST requests: the metadata of a call is forwarded as the
+// headers of the request, eg. the meshery-provider and cookie or authorization headers.
+type ManagementServiceClient interface {
+	ListDesigns(ctx context.Context, in *ListDesignsRequest, opts ...grpc.CallOption) (*ListDesignsResponse, error)
+	GetDesign(ctx context.Context, in *GetDesignRequest, opts ...grpc.CallOption) (*Design, error)
+	CreateDesign(ctx context.Context, in *CreateDesignRequest, opts ...grpc.CallOption) (*Design, error)
+	UpdateDesign(ctx context.Context, in *UpdateDesignRequest, opts ...grpc.CallOption) (*Design, error)
+	DeleteDesign(ctx context.Context, in *DeleteDesignRequest, opts ...grpc.CallOption) (*DeleteDesignResponse, error)
+	// DeployDesign deploys a design to the Kubernetes connections of an environment.
+	DeployDesign(ctx context.Context, in *DeployDesignRequest, opts ...grpc.CallOption) (*Deployment, error)
+	UndeployDesign(ctx context.Context, in *UndeployDesignRequest, opts ...grpc.CallOption) (*UndeployDesignResponse, error)
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
+	ListComponents(ctx context.Context, in *ListComponentsRequest, opts ...grpc.CallOption) (*ListComponentsResponse, error)
+	// StreamEvents streams the events published to the user until the call is cancelled.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type managementServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewManagementServiceClient(cc grpc.ClientConnInterface) ManagementServiceClient {
+	return &managementServiceClient{cc}
+}
+
+func (c *managementServiceClient) ListDesigns(ctx context.Context, in *ListDesignsRequest, opts ...grpc.CallOption) (*ListDesignsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDesignsResponse)
+	err := c.cc.Invoke(ctx, ManagementService_ListDesigns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetDesign(ctx context.Context, in *GetDesignRequest, opts ...grpc.CallOption) (*Design, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Design)
+	err := c.cc.Invoke(ctx, ManagementService_GetDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) CreateDesign(ctx context.Context, in *CreateDesignRequest, opts ...grpc.CallOption) (*Design, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Design)
+	err := c.cc.Invoke(ctx, ManagementService_CreateDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) UpdateDesign(ctx context.Context, in *UpdateDesignRequest, opts ...grpc.CallOption) (*Design, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Design)
+	err := c.cc.Invoke(ctx, ManagementService_UpdateDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) DeleteDesign(ctx context.Context, in *DeleteDesignRequest, opts ...grpc.CallOption) (*DeleteDesignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDesignResponse)
+	err := c.cc.Invoke(ctx, ManagementService_DeleteDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) DeployDesign(ctx context.Context, in *DeployDesignRequest, opts ...grpc.CallOption) (*Deployment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Deployment)
+	err := c.cc.Invoke(ctx, ManagementService_DeployDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) UndeployDesign(ctx context.Context, in *UndeployDesignRequest, opts ...grpc.CallOption) (*UndeployDesignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndeployDesignResponse)
+	err := c.cc.Invoke(ctx, ManagementService_UndeployDesign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, ManagementService_ListModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ListComponents(ctx context.Context, in *ListComponentsRequest, opts ...grpc.CallOption) (*ListComponentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListComponentsResponse)
+	err := c.cc.Invoke(ctx, ManagementService_ListComponents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ManagementService_ServiceDesc.Streams[0], ManagementService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ManagementService_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+// ManagementServiceServer is the server API for ManagementService service.
+// All implementations must embed UnimplementedManagementServiceServer
+// for forward compatibility.
+//
+// ManagementService mirrors the core operations of the REST API for programmatic clients.
+// Calls are authenticated like REST requests: the metadata of a call is forwarded as the
+// headers of the request, eg. the meshery-provider and cookie or authorization headers.
+type ManagementServiceServer interface {
+	ListDesigns(context.Context, *ListDesignsRequest) (*ListDesignsResponse, error)
+	GetDesign(context.Context, *GetDesignRequest) (*Design, error)
+	CreateDesign(context.Context, *CreateDesignRequest) (*Design, error)
+	UpdateDesign(context.Context, *UpdateDesignRequest) (*Design, error)
+	DeleteDesign(context.Context, *DeleteDesignRequest) (*DeleteDesignResponse, error)
+	// DeployDesign deploys a design to the Kubernetes connections of an environment.
+	DeployDesign(context.Context, *DeployDesignRequest) (*Deployment, error)
+	UndeployDesign(context.Context, *UndeployDesignRequest) (*UndeployDesignResponse, error)
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
+	ListComponents(context.Context, *ListComponentsRequest) (*ListComponentsResponse, error)
+	// StreamEvents streams the events published to the user until the call is cancelled.
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedManagementServiceServer()
+}
+
+// UnimplementedManagementServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedManagementServiceServer struct{}
+
+func (UnimplementedManagementServiceServer) ListDesigns(context.Context, *ListDesignsRequest) (*ListDesignsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDesigns not implemented")
+}
+func (UnimplementedManagementServiceServer) GetDesign(context.Context, *GetDesignRequest) (*Design, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) CreateDesign(context.Context, *CreateDesignRequest) (*Design, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) UpdateDesign(context.Context, *UpdateDesignRequest) (*Design, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) DeleteDesign(context.Context, *DeleteDesignRequest) (*DeleteDesignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) DeployDesign(context.Context, *DeployDesignRequest) (*Deployment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeployDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) UndeployDesign(context.Context, *UndeployDesignRequest) (*UndeployDesignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeployDesign not implemented")
+}
+func (UnimplementedManagementServiceServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
+}
+func (UnimplementedManagementServiceServer) ListComponents(context.Context, *ListComponentsRequest) (*ListComponentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListComponents not implemented")
+}
+func (UnimplementedManagementServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedManagementServiceServer) mustEmbedUnimplementedManagementServiceServer() {}
+func (UnimplementedManagementServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeManagementServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ManagementServiceServer will
+// result in compilation errors.
+type UnsafeManagementServiceServer interface {
+	mustEmbedUnimplementedManagementServiceServer()
+}
+
+func RegisterManagementServiceServer(s grpc.ServiceRegistrar, srv ManagementServiceServer) {
+	// If the following call pancis, it indicates UnimplementedManagementServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ManagementService_ServiceDesc, srv)
+}
+
+func _ManagementService_ListDesigns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDesignsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ListDesigns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_ListDesigns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ListDesigns(ctx, req.(*ListDesignsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_GetDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetDesign(ctx, req.(*GetDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_CreateDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).CreateDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_CreateDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).CreateDesign(ctx, req.(*CreateDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_UpdateDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).UpdateDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_UpdateDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).UpdateDesign(ctx, req.(*UpdateDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_DeleteDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).DeleteDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_DeleteDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).DeleteDesign(ctx, req.(*DeleteDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_DeployDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeployDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).DeployDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_DeployDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).DeployDesign(ctx, req.(*DeployDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_UndeployDesign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeployDesignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).UndeployDesign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_UndeployDesign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).UndeployDesign(ctx, req.(*UndeployDesignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_ListModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ListComponents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListComponentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ListComponents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ManagementService_ListComponents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ListComponents(ctx, req.(*ListComponentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ManagementService_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+// ManagementService_ServiceDesc is the grpc.ServiceDesc for ManagementService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ManagementService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "management.ManagementService",
+	HandlerType: (*ManagementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDesigns",
+			Handler:    _ManagementService_ListDesigns_Handler,
+		},
+		{
+			MethodName: "GetDesign",
+			Handler:    _ManagementService_GetDesign_Handler,
+		},
+		{
+			MethodName: "CreateDesign",
+			Handler:    _ManagementService_CreateDesign_Handler,
+		},
+		{
+			MethodName: "UpdateDesign",
+			Handler:    _ManagementService_UpdateDesign_Handler,
+		},
+		{
+			MethodName: "DeleteDesign",
+			Handler:    _ManagementService_DeleteDesign_Handler,
+		},
+		{
+			MethodName: "DeployDesign",
+			Handler:    _ManagementService_DeployDesign_Handler,
+		},
+		{
+			MethodName: "UndeployDesign",
+			Handler:    _ManagementService_UndeployDesign_Handler,
+		},
+		{
+			MethodName: "ListModels",
+			Handler:    _ManagementService_ListModels_Handler,
+		},
+		{
+			MethodName: "ListComponents",
+			Handler:    _ManagementService_ListComponents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _ManagementService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "management.proto",
+}
@@ -0,0 +1,410 @@
+// Package management serves ManagementService, a gRPC mirror of the core operations of the REST
+// API: managing designs, deploying them, browsing the registry and streaming events.
+//
+// Calls are served by the REST API itself, in process, so that both APIs share authentication,
+// authorization and behaviour. The metadata of a call carrying the credentials of the caller is
+// forwarded as the headers of the request, and the status of the response is translated to a
+// gRPC status.
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PortENV is the port the management service is served on, it is not served when unset.
+const PortENV = "MANAGEMENT_GRPC_PORT"
+
+// Server implements ManagementService on top of the REST API.
+type Server struct {
+	UnimplementedManagementServiceServer
+
+	handler     http.Handler
+	broadcaster *models.Broadcast
+	log         logger.Handler
+}
+
+// NewServer returns a Server serving calls with the handler of the REST API
+// and streaming the events published to the broadcaster.
+func NewServer(handler http.Handler, broadcaster *models.Broadcast, log logger.Handler) *Server {
+	return &Server{
+		handler:     handler,
+		broadcaster: broadcaster,
+		log:         log,
+	}
+}
+
+// Run serves the management service on the port, it returns when the server stops.
+// Connections are secured with the credentials when given, eg. mutual TLS.
+func (s *Server) Run(port int, creds credentials.TransportCredentials) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return ErrManagementServer(err, port)
+	}
+	opts := []grpc.ServerOption{}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(opts...)
+	RegisterManagementServiceServer(srv, s)
+	if err := srv.Serve(lis); err != nil {
+		return ErrManagementServer(err, port)
+	}
+	return nil
+}
+
+func (s *Server) ListDesigns(ctx context.Context, _ *ListDesignsRequest) (*ListDesignsResponse, error) {
+	designs := []models.ManagedDesign{}
+	if err := s.call(ctx, http.MethodGet, models.ManagementAPIBasePath+"/designs", nil, nil, &designs); err != nil {
+		return nil, err
+	}
+	resp := &ListDesignsResponse{Designs: make([]*Design, 0, len(designs))}
+	for i := range designs {
+		resp.Designs = append(resp.Designs, newDesign(&designs[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetDesign(ctx context.Context, req *GetDesignRequest) (*Design, error) {
+	design := models.ManagedDesign{}
+	if err := s.call(ctx, http.MethodGet, models.ManagementAPIBasePath+"/designs/"+url.PathEscape(req.GetId()), nil, nil, &design); err != nil {
+		return nil, err
+	}
+	return newDesign(&design), nil
+}
+
+func (s *Server) CreateDesign(ctx context.Context, req *CreateDesignRequest) (*Design, error) {
+	payload := models.ManagedDesignRequest{Name: req.GetName(), PatternFile: req.GetPatternFile()}
+	design := models.ManagedDesign{}
+	if err := s.call(ctx, http.MethodPost, models.ManagementAPIBasePath+"/designs", nil, payload, &design); err != nil {
+		return nil, err
+	}
+	return newDesign(&design), nil
+}
+
+func (s *Server) UpdateDesign(ctx context.Context, req *UpdateDesignRequest) (*Design, error) {
+	payload := models.ManagedDesignRequest{Name: req.GetName(), PatternFile: req.GetPatternFile()}
+	design := models.ManagedDesign{}
+	if err := s.call(ctx, http.MethodPut, models.ManagementAPIBasePath+"/designs/"+url.PathEscape(req.GetId()), nil, payload, &design); err != nil {
+		return nil, err
+	}
+	return newDesign(&design), nil
+}
+
+func (s *Server) DeleteDesign(ctx context.Context, req *DeleteDesignRequest) (*DeleteDesignResponse, error) {
+	if err := s.call(ctx, http.MethodDelete, models.ManagementAPIBasePath+"/designs/"+url.PathEscape(req.GetId()), nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &DeleteDesignResponse{}, nil
+}
+
+func (s *Server) DeployDesign(ctx context.Context, req *DeployDesignRequest) (*Deployment, error) {
+	designID, err := uuid.FromString(req.GetDesignId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid design id %q", req.GetDesignId())
+	}
+	environmentID, err := uuid.FromString(req.GetEnvironmentId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid environment id %q", req.GetEnvironmentId())
+	}
+
+	payload := models.ManagedDeploymentRequest{DesignID: designID, EnvironmentID: environmentID}
+	deployment := models.ManagedDeployment{}
+	if err := s.call(ctx, http.MethodPost, models.ManagementAPIBasePath+"/deployments", nil, payload, &deployment); err != nil {
+		return nil, err
+	}
+	return newDeployment(&deployment), nil
+}
+
+func (s *Server) UndeployDesign(ctx context.Context, req *UndeployDesignRequest) (*UndeployDesignResponse, error) {
+	if err := s.call(ctx, http.MethodDelete, models.ManagementAPIBasePath+"/deployments/"+url.PathEscape(req.GetDeploymentId()), nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &UndeployDesignResponse{}, nil
+}
+
+func (s *Server) ListModels(ctx context.Context, req *ListModelsRequest) (*ListModelsResponse, error) {
+	query := pagination(req.GetSearch(), req.GetPage(), req.GetPageSize())
+	page := models.MeshmodelsAPIResponse{}
+	if err := s.call(ctx, http.MethodGet, "/api/meshmodels/models", query, nil, &page); err != nil {
+		return nil, err
+	}
+	resp := &ListModelsResponse{Models: make([]*Model, 0, len(page.Models)), TotalCount: page.Count}
+	for i := range page.Models {
+		resp.Models = append(resp.Models, newModel(&page.Models[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListComponents(ctx context.Context, req *ListComponentsRequest) (*ListComponentsResponse, error) {
+	path := "/api/meshmodels/components"
+	if req.GetModel() != "" {
+		path = "/api/meshmodels/models/" + url.PathEscape(req.GetModel()) + "/components"
+	}
+	query := pagination(req.GetSearch(), req.GetPage(), req.GetPageSize())
+	page := models.MeshmodelComponentsAPIResponse{}
+	if err := s.call(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+		return nil, err
+	}
+	resp := &ListComponentsResponse{Components: make([]*Component, 0, len(page.Components)), TotalCount: page.Count}
+	for i := range page.Components {
+		resp.Components = append(resp.Components, newComponent(&page.Components[i]))
+	}
+	return resp, nil
+}
+
+// StreamEvents authenticates the call as a request for the current user,
+// then streams the events published to the user.
+func (s *Server) StreamEvents(_ *StreamEventsRequest, stream grpc.ServerStreamingServer[Event]) error {
+	ctx := stream.Context()
+	user := models.User{}
+	if err := s.call(ctx, http.MethodGet, "/api/user", nil, nil, &user); err != nil {
+		return err
+	}
+	userID, err := uuid.FromString(user.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid user id %q", user.ID)
+	}
+
+	ch, unsubscribe := s.broadcaster.Subscribe(userID)
+	defer unsubscribe()
+	s.log.Infof("Management events stream started for %s", userID)
+	for {
+		select {
+		case data := <-ch:
+			event, ok := data.(*events.Event)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(newEvent(event)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			s.log.Infof("Management events stream stopped for %s", userID)
+			return nil
+		}
+	}
+}
+
+// call serves the request with the handler of the REST API, on behalf of the caller,
+// and decodes the response into out unless it is nil.
+func (s *Server) call(ctx context.Context, method, path string, query url.Values, payload, out interface{}) error {
+	var body io.Reader = http.NoBody
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		body = bytes.NewReader(data)
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			if !forwardedHeader(key) {
+				continue
+			}
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp := newResponse()
+	s.handler.ServeHTTP(resp, req)
+
+	if resp.code < 200 || resp.code > 299 {
+		return statusFromHTTP(resp.code, resp.body.String())
+	}
+	if out == nil || resp.body.Len() == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.body.Bytes(), out); err != nil {
+		return status.Error(codes.Internal, models.ErrUnmarshal(err, path).Error())
+	}
+	return nil
+}
+
+// forwardedHeaders are the metadata keys forwarded as headers: the credentials of the caller,
+// the session token and the provider being sent as cookies or headers.
+var forwardedHeaders = map[string]bool{
+	"authorization":    true,
+	"cookie":           true,
+	"meshery-provider": true,
+}
+
+// forwardedHeader reports whether the metadata key is forwarded as a header. Any other metadata,
+// eg. headers identifying the client or set by proxies, is left out so that callers cannot
+// alter how the REST API handles the request.
+func forwardedHeader(key string) bool {
+	return forwardedHeaders[strings.ToLower(key)]
+}
+
+// response records the response of the REST API to a call.
+type response struct {
+	code        int
+	wroteHeader bool
+	header      http.Header
+	body        bytes.Buffer
+}
+
+func newResponse() *response {
+	return &response{code: http.StatusOK, header: http.Header{}}
+}
+
+func (r *response) Header() http.Header {
+	return r.header
+}
+
+func (r *response) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(data)
+}
+
+// WriteHeader records the status code, only the first one is kept as by net/http.
+func (r *response) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+	r.code, r.wroteHeader = code, true
+}
+
+// statusFromHTTP translates the status of a response of the REST API to a gRPC status.
+func statusFromHTTP(code int, body string) error {
+	msg := strings.TrimSpace(body)
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	switch {
+	case code == http.StatusBadRequest, code == http.StatusUnprocessableEntity:
+		return status.Error(codes.InvalidArgument, msg)
+	// unauthenticated requests are redirected to the login of the provider
+	case code == http.StatusUnauthorized, code >= 300 && code < 400:
+		return status.Error(codes.Unauthenticated, msg)
+	case code == http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, msg)
+	case code == http.StatusNotFound:
+		return status.Error(codes.NotFound, msg)
+	case code == http.StatusConflict:
+		return status.Error(codes.AlreadyExists, msg)
+	case code == http.StatusNotImplemented:
+		return status.Error(codes.Unimplemented, msg)
+	case code == http.StatusServiceUnavailable:
+		return status.Error(codes.Unavailable, msg)
+	case code >= 500:
+		return status.Error(codes.Internal, msg)
+	}
+	return status.Error(codes.Unknown, msg)
+}
+
+func pagination(search string, page, pageSize int32) url.Values {
+	query := url.Values{}
+	if search != "" {
+		query.Set("search", search)
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(int(page)))
+	}
+	if pageSize > 0 {
+		query.Set("pagesize", strconv.Itoa(int(pageSize)))
+	}
+	return query
+}
+
+func newDesign(design *models.ManagedDesign) *Design {
+	return &Design{
+		Id:          design.ID.String(),
+		Name:        design.Name,
+		PatternFile: design.PatternFile,
+		Visibility:  design.Visibility,
+		ContentHash: design.ContentHash,
+		CreatedAt:   timestamp(design.CreatedAt),
+		UpdatedAt:   timestamp(design.UpdatedAt),
+	}
+}
+
+func newDeployment(deployment *models.ManagedDeployment) *Deployment {
+	return &Deployment{
+		Id:            deployment.ID.String(),
+		DesignId:      deployment.DesignID.String(),
+		EnvironmentId: deployment.EnvironmentID.String(),
+		ContentHash:   deployment.ContentHash,
+		Status:        string(deployment.Status),
+		Message:       deployment.Message,
+		CreatedAt:     timestamppb.New(deployment.CreatedAt),
+		UpdatedAt:     timestamppb.New(deployment.UpdatedAt),
+	}
+}
+
+func newModel(m *model.ModelDefinition) *Model {
+	return &Model{
+		Id:          m.Id.String(),
+		Name:        m.Name,
+		DisplayName: m.DisplayName,
+		Version:     m.Model.Version,
+		Category:    m.Category.Name,
+		Registrant:  m.Registrant.Kind,
+	}
+}
+
+func newComponent(c *component.ComponentDefinition) *Component {
+	return &Component{
+		Id:           c.Id.String(),
+		Kind:         c.Component.Kind,
+		DisplayName:  c.DisplayName,
+		ApiVersion:   c.Component.Version,
+		Model:        c.Model.Name,
+		ModelVersion: c.Model.Model.Version,
+		Schema:       c.Component.Schema,
+	}
+}
+
+func newEvent(event *events.Event) *Event {
+	return &Event{
+		Id:          event.ID.String(),
+		Severity:    string(event.Severity),
+		Category:    event.Category,
+		Action:      event.Action,
+		Description: event.Description,
+		ActedUpon:   event.ActedUpon.String(),
+		CreatedAt:   timestamppb.New(event.CreatedAt),
+	}
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,158 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "token-of-the-owner"
+
+// newTestClient serves the management service with the handler over an in-memory connection.
+func newTestClient(t *testing.T, handler http.Handler) ManagementServiceClient {
+	t.Helper()
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterManagementServiceServer(srv, NewServer(handler, models.NewBroadcaster("test"), log))
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return NewManagementServiceClient(conn)
+}
+
+func TestServerForwardsCredentials(t *testing.T) {
+	designID := uuid.Must(uuid.NewV4())
+	var headers http.Header
+	router := mux.NewRouter()
+	router.HandleFunc(models.ManagementAPIBasePath+"/designs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		ck, err := r.Cookie("token")
+		if err != nil || ck.Value != testToken {
+			http.Redirect(w, r, "/provider", http.StatusFound)
+			return
+		}
+		if mux.Vars(r)["id"] != designID.String() {
+			http.Error(w, "design does not exist", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.ManagedDesign{ID: designID, Name: "web"})
+	}).Methods(http.MethodGet)
+	client := newTestClient(t, router)
+
+	tests := []struct {
+		name     string
+		metadata metadata.MD
+		id       string
+		want     codes.Code
+	}{
+		{name: "authenticated", metadata: metadata.Pairs("cookie", "token="+testToken, "meshery-provider", "Meshery"), id: designID.String(), want: codes.OK},
+		{name: "unauthenticated", id: designID.String(), want: codes.Unauthenticated},
+		{name: "wrong token", metadata: metadata.Pairs("cookie", "token=other"), id: designID.String(), want: codes.Unauthenticated},
+		{name: "missing design", metadata: metadata.Pairs("cookie", "token="+testToken), id: uuid.Must(uuid.NewV4()).String(), want: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), metadata.Join(tt.metadata, metadata.Pairs("x-forwarded-for", "10.0.0.1", "x-user-id", "admin")))
+			design, err := client.GetDesign(ctx, &GetDesignRequest{Id: tt.id})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s: %v", got, tt.want, err)
+			}
+			if tt.want == codes.OK && (design.GetId() != designID.String() || design.GetName() != "web") {
+				t.Errorf("design = %v, want %s", design, designID)
+			}
+			for _, header := range []string{"X-Forwarded-For", "X-User-Id", "User-Agent", "Te"} {
+				if value := headers.Get(header); value != "" {
+					t.Errorf("header %s = %q, want it left out", header, value)
+				}
+			}
+			if tt.metadata != nil && headers.Get("Cookie") != tt.metadata.Get("cookie")[0] {
+				t.Errorf("cookie = %q, want the cookie of the call", headers.Get("Cookie"))
+			}
+		})
+	}
+}
+
+func TestServerStatusCodes(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{status: http.StatusBadRequest, want: codes.InvalidArgument},
+		{status: http.StatusUnprocessableEntity, want: codes.InvalidArgument},
+		{status: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{status: http.StatusFound, want: codes.Unauthenticated},
+		{status: http.StatusForbidden, want: codes.PermissionDenied},
+		{status: http.StatusNotFound, want: codes.NotFound},
+		{status: http.StatusConflict, want: codes.AlreadyExists},
+		{status: http.StatusNotImplemented, want: codes.Unimplemented},
+		{status: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{status: http.StatusInternalServerError, want: codes.Internal},
+		{status: http.StatusTeapot, want: codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "failed", tt.status)
+				// only the first status is kept
+				w.WriteHeader(http.StatusOK)
+			}))
+			_, err := client.DeleteDesign(context.Background(), &DeleteDesignRequest{Id: uuid.Must(uuid.NewV4()).String()})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s: %v", got, tt.want, err)
+			}
+			if !strings.Contains(status.Convert(err).Message(), "failed") {
+				t.Errorf("message = %q, want the body of the response", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestForwardedHeader(t *testing.T) {
+	for key, want := range map[string]bool{
+		"authorization":    true,
+		"cookie":           true,
+		"meshery-provider": true,
+		":authority":       false,
+		"grpc-timeout":     false,
+		"content-type":     false,
+		"user-agent":       false,
+		"x-forwarded-for":  false,
+		"x-user-id":        false,
+	} {
+		if got := forwardedHeader(key); got != want {
+			t.Errorf("forwardedHeader(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -4,9 +4,19 @@ import (
 	context "context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// transportCredentials secure the connections to adapters, which are not secured by default.
+var transportCredentials = insecure.NewCredentials()
+
+// UseTransportCredentials secures the connections to adapters with the credentials, eg. mutual TLS.
+// It must be called before any client is created.
+func UseTransportCredentials(creds credentials.TransportCredentials) {
+	transportCredentials = creds
+}
+
 // MeshClient represents a gRPC adapter client
 type MeshClient struct {
 	MClient MeshServiceClient
@@ -16,14 +26,7 @@ type MeshClient struct {
 // CreateClient creates a MeshClient for the given params
 func CreateClient(_ context.Context, meshLocationURL string) (*MeshClient, error) {
 	var opts []grpc.DialOption
-	// creds, err := credentials.NewClientTLSFromFile(*caFile, *serverHostOverride)
-	// 	if err != nil {
-	// 		logrus.Errorf("Failed to create TLS credentials %v", err)
-	// 	}
-	// 	opts = append(opts, grpc.WithTransportCredentials(creds))
-	// } else {
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	// }
+	opts = append(opts, grpc.WithTransportCredentials(transportCredentials))
 	conn, err := grpc.NewClient(meshLocationURL, opts...)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,62 @@
+package design_policy
+
+import future.keywords.contains
+import future.keywords.if
+import future.keywords.in
+
+# deny is the set of violations of the design policies by the components of a design.
+# It is evaluated at design time and by the admission webhook of Meshery Server
+# against the resources admitted in the namespaces labeled for policy enforcement.
+
+workload_kinds := {"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job"}
+
+pod_spec(component) := spec if {
+	component.component.kind == "Pod"
+	spec := component.configuration.spec
+}
+
+pod_spec(component) := spec if {
+	component.component.kind in workload_kinds
+	spec := component.configuration.spec.template.spec
+}
+
+pod_spec(component) := spec if {
+	component.component.kind == "CronJob"
+	spec := component.configuration.spec.jobTemplate.spec.template.spec
+}
+
+containers(spec) := array.concat(object.get(spec, "containers", []), object.get(spec, "initContainers", []))
+
+deny contains msg if {
+	some component in input.components
+	spec := pod_spec(component)
+	some container in containers(spec)
+	container.securityContext.privileged == true
+	msg := sprintf("%s: container %s must not be privileged", [component.displayName, container.name])
+}
+
+deny contains msg if {
+	some component in input.components
+	spec := pod_spec(component)
+	some field in ["hostNetwork", "hostPID", "hostIPC"]
+	spec[field] == true
+	msg := sprintf("%s: %s must not be enabled", [component.displayName, field])
+}
+
+deny contains msg if {
+	some component in input.components
+	spec := pod_spec(component)
+	some container in containers(spec)
+	not pinned_image(container.image)
+	msg := sprintf("%s: image %s of container %s must be pinned to a tag other than latest or to a digest", [component.displayName, container.image, container.name])
+}
+
+pinned_image(image) if contains(image, "@")
+
+pinned_image(image) if {
+	not contains(image, "@")
+	name := split(image, "/")
+	tag := split(name[count(name) - 1], ":")
+	count(tag) == 2
+	tag[1] != "latest"
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"github.com/open-policy-agent/opa/rego"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AdmissionWebhookEnabledENV enables the validating admission webhook served by Meshery Server.
+	AdmissionWebhookEnabledENV = "ADMISSION_WEBHOOK_ENABLED"
+	// AdmissionWebhookNamespaceLabelENV is the label a namespace must carry for its resources to be checked by the webhook.
+	AdmissionWebhookNamespaceLabelENV = "ADMISSION_WEBHOOK_NAMESPACE_LABEL"
+	// AdmissionWebhookPolicyQueryENV is the rego query evaluated against the admitted resources.
+	AdmissionWebhookPolicyQueryENV = "ADMISSION_WEBHOOK_POLICY_QUERY"
+
+	DefaultAdmissionWebhookNamespaceLabel = "meshery.io/policy-enforcement"
+	DefaultAdmissionWebhookPolicyQuery    = "data.design_policy.deny"
+
+	AdmissionWebhookPath = "/api/system/admission/validate"
+	admissionWebhookName = "design-policies.meshery.io"
+)
+
+// DesignPolicyEvaluator evaluates a query of the design policies against designs. The policies are
+// loaded and the query is compiled once, when the evaluator is created, and reused for every design.
+type DesignPolicyEvaluator struct {
+	query rego.PreparedEvalQuery
+}
+
+// NewDesignPolicyEvaluator loads the policies found in policyDir and prepares the query for evaluation.
+// The default query, data.design_policy.deny, is defined by the design_policy package shipped in PoliciesPath.
+func NewDesignPolicyEvaluator(ctx context.Context, policyDir, query string) (*DesignPolicyEvaluator, error) {
+	prepared, err := rego.New(
+		rego.Query(query),
+		rego.Load([]string{policyDir}, nil),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, ErrEvaluateDesignPolicies(err)
+	}
+	return &DesignPolicyEvaluator{query: prepared}, nil
+}
+
+// Evaluate evaluates the query against the design and returns the messages of the violated policies.
+//
+// The query is expected to produce a set of violations, each of them either a message
+// or an object with a "msg" field, as is customary for OPA deny rules.
+// An empty result means no policy is violated.
+func (e *DesignPolicyEvaluator) Evaluate(ctx context.Context, design pattern.PatternFile) ([]string, error) {
+	results, err := e.query.Eval(ctx, rego.EvalInput(design))
+	if err != nil {
+		return nil, ErrEvaluateDesignPolicies(err)
+	}
+
+	violations := []string{}
+	for _, result := range results {
+		for _, expr := range result.Expressions {
+			violations = append(violations, policyViolationMessages(expr.Value)...)
+		}
+	}
+	sort.Strings(violations)
+	return violations, nil
+}
+
+func policyViolationMessages(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		if v {
+			return []string{"the resource violates a design policy"}
+		}
+		return nil
+	case string:
+		return []string{v}
+	case []interface{}:
+		msgs := []string{}
+		for _, val := range v {
+			msgs = append(msgs, policyViolationMessages(val)...)
+		}
+		return msgs
+	case map[string]interface{}:
+		if msg, ok := v["msg"]; ok {
+			return []string{fmt.Sprint(msg)}
+		}
+		return []string{fmt.Sprint(v)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// NewAdmissionWebhookConfiguration returns the ValidatingWebhookConfiguration registering the webhook
+// served at url for the resources of the namespaces labeled with namespaceLabel=enabled.
+//
+// The webhook fails open so that resources can still be admitted while Meshery Server is unreachable.
+func NewAdmissionWebhookConfiguration(url string, caBundle []byte, namespaceLabel string) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	scope := admissionregistrationv1.NamespacedScope
+	timeout := int32(10)
+
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "admissionregistration.k8s.io/v1",
+			Kind:       "ValidatingWebhookConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: admissionWebhookName,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "meshery",
+			},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: admissionWebhookName,
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					URL:      &url,
+					CABundle: caBundle,
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{"*"},
+							APIVersions: []string{"*"},
+							Resources:   []string{"*"},
+							Scope:       &scope,
+						},
+					},
+				},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{namespaceLabel: "enabled"},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+}
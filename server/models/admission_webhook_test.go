@@ -0,0 +1,87 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func newAdmissionTestComponent(name, kind string, configuration map[string]interface{}) *component.ComponentDefinition {
+	return &component.ComponentDefinition{
+		DisplayName:   name,
+		Component:     component.Component{Kind: kind},
+		Configuration: configuration,
+	}
+}
+
+func TestDesignPolicyEvaluator(t *testing.T) {
+	evaluator, err := NewDesignPolicyEvaluator(context.Background(), PoliciesPath, DefaultAdmissionWebhookPolicyQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compliant := newAdmissionTestComponent("web", "Deployment", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "nginx", "image": "docker.io/library/nginx:1.27"},
+						map[string]interface{}{"name": "proxy", "image": "envoyproxy/envoy@sha256:0123"},
+					},
+				},
+			},
+		},
+	})
+	violating := newAdmissionTestComponent("debug", "Pod", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"hostNetwork": true,
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":            "shell",
+					"image":           "localhost:5000/busybox",
+					"securityContext": map[string]interface{}{"privileged": true},
+				},
+			},
+			"initContainers": []interface{}{
+				map[string]interface{}{"name": "init", "image": "busybox:latest"},
+			},
+		},
+	})
+	service := newAdmissionTestComponent("svc", "Service", map[string]interface{}{"spec": map[string]interface{}{}})
+
+	tests := []struct {
+		name  string
+		comps []*component.ComponentDefinition
+		want  []string
+	}{
+		{
+			name:  "compliant design",
+			comps: []*component.ComponentDefinition{compliant, service},
+			want:  []string{},
+		},
+		{
+			name:  "violating design",
+			comps: []*component.ComponentDefinition{compliant, violating},
+			want: []string{
+				"debug: container shell must not be privileged",
+				"debug: hostNetwork must not be enabled",
+				"debug: image busybox:latest of container init must be pinned to a tag other than latest or to a digest",
+				"debug: image localhost:5000/busybox of container shell must be pinned to a tag other than latest or to a digest",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluator.Evaluate(context.Background(), pattern.PatternFile{Components: tt.comps})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
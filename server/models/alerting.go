@@ -0,0 +1,462 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/meshes"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/events"
+	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
+	"gopkg.in/yaml.v2"
+)
+
+// AlertMetric is the data of Meshery observed by an alert rule.
+type AlertMetric string
+
+const (
+	// AlertMetricEventRate is the number of events in the window, filtered by category, action and severity.
+	AlertMetricEventRate AlertMetric = "event_rate"
+	// AlertMetricDeploymentFailureStreak is the number of consecutive failed deployments of a design, of the
+	// filtered design or the longest streak among designs.
+	AlertMetricDeploymentFailureStreak AlertMetric = "deployment_failure_streak"
+	// AlertMetricDriftCount is the number of resources deployed by designs which are missing from their
+	// cluster, as discovered by MeshSync.
+	AlertMetricDriftCount AlertMetric = "drift_count"
+	// AlertMetricAdapterDown is the number of registered adapters which do not respond.
+	AlertMetricAdapterDown AlertMetric = "adapter_down"
+)
+
+// AlertChannelType is where the notifications of an alert rule are routed.
+type AlertChannelType string
+
+const (
+	// AlertChannelEvents notifies the owner of the rule with an event, in the notification center.
+	AlertChannelEvents AlertChannelType = "events"
+	// AlertChannelWebhook posts the notification to the URL, as expected by Slack incoming webhooks.
+	AlertChannelWebhook AlertChannelType = "webhook"
+)
+
+// AlertChannel is a channel the notifications of an alert rule are routed to.
+type AlertChannel struct {
+	Type AlertChannelType `json:"type" yaml:"type"`
+	URL  string           `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// AlertFilter selects the events counted by event_rate rules, and the design of deployment_failure_streak rules.
+type AlertFilter struct {
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	Action   string `json:"action,omitempty" yaml:"action,omitempty"`
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Design   string `json:"design,omitempty" yaml:"design,omitempty"`
+}
+
+// AlertRuleDefinition is an alert rule as written by users: the rule fires once the condition on the metric
+// has held for the duration, and resolves once it no longer holds. Both are routed to the channels.
+//
+//	name: deployments failing
+//	metric: deployment_failure_streak
+//	condition: ">= 3"
+//	for: 10m
+//	severity: error
+//	channels:
+//	  - type: events
+//	  - type: webhook
+//	    url: https://hooks.slack.com/services/T000/B000/XXXX
+type AlertRuleDefinition struct {
+	Name        string      `json:"name" yaml:"name"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Metric      AlertMetric `json:"metric" yaml:"metric"`
+	Filter      AlertFilter `json:"filter,omitempty" yaml:"filter,omitempty"`
+	// Window is the period event_rate rules count the events of, 5m by default.
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+	// Condition compares the metric with a threshold, eg. "> 10", with >, >=, <, <=, == or !=.
+	Condition string `json:"condition" yaml:"condition"`
+	// For is how long the condition must hold before the rule fires, eg. 10m. It fires right away without it.
+	For string `json:"for,omitempty" yaml:"for,omitempty"`
+	// Severity is the severity of the events of the rule, warning by default.
+	Severity events.EventSeverity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Channels []AlertChannel       `json:"channels" yaml:"channels"`
+	Disabled bool                 `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+var alertConditionPattern = regexp.MustCompile(`^\s*(>=|<=|==|!=|>|<)\s*(-?[0-9]+(?:\.[0-9]+)?)\s*$`)
+
+const defaultAlertWindow = 5 * time.Minute
+
+// ParseAlertRuleDefinition decodes and validates the alert rule, in YAML or JSON.
+func ParseAlertRuleDefinition(byt []byte) (*AlertRuleDefinition, error) {
+	def := &AlertRuleDefinition{}
+	if err := yaml.UnmarshalStrict(byt, def); err != nil {
+		return nil, ErrInvalidAlertRule(err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, ErrInvalidAlertRule(err)
+	}
+	return def, nil
+}
+
+// Validate checks that the rule is named, observes a known metric with a valid condition and is routed to
+// valid channels.
+func (def *AlertRuleDefinition) Validate() error {
+	if def.Name == "" {
+		return fmt.Errorf("the alert rule has no name")
+	}
+	switch def.Metric {
+	case AlertMetricEventRate, AlertMetricDeploymentFailureStreak, AlertMetricDriftCount, AlertMetricAdapterDown:
+	default:
+		return fmt.Errorf("unknown metric %q, the metric is %s, %s, %s or %s", def.Metric, AlertMetricEventRate, AlertMetricDeploymentFailureStreak, AlertMetricDriftCount, AlertMetricAdapterDown)
+	}
+	if _, _, err := def.condition(); err != nil {
+		return err
+	}
+	for field, d := range map[string]string{"window": def.Window, "for": def.For} {
+		if d == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d); err != nil || parsed < 0 {
+			return fmt.Errorf("invalid %s %q, eg. 5m", field, d)
+		}
+	}
+	if def.Filter.Design != "" {
+		if _, err := uuid.FromString(def.Filter.Design); err != nil {
+			return fmt.Errorf("invalid design id %q", def.Filter.Design)
+		}
+	}
+	if len(def.Channels) == 0 {
+		return fmt.Errorf("the alert rule has no channels")
+	}
+	for i, channel := range def.Channels {
+		switch channel.Type {
+		case AlertChannelEvents:
+		case AlertChannelWebhook:
+			if channel.URL == "" {
+				return fmt.Errorf("channel %d needs a url", i+1)
+			}
+		default:
+			return fmt.Errorf("channel %d has an unknown type %q", i+1, channel.Type)
+		}
+	}
+	return nil
+}
+
+func (def *AlertRuleDefinition) condition() (string, float64, error) {
+	match := alertConditionPattern.FindStringSubmatch(def.Condition)
+	if match == nil {
+		return "", 0, fmt.Errorf("invalid condition %q, eg. \"> 10\"", def.Condition)
+	}
+	threshold, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid condition %q: %w", def.Condition, err)
+	}
+	return match[1], threshold, nil
+}
+
+// Holds returns whether the value satisfies the condition of the rule.
+func (def *AlertRuleDefinition) Holds(value float64) bool {
+	operator, threshold, err := def.condition()
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+func (def *AlertRuleDefinition) window() time.Duration {
+	if window, err := time.ParseDuration(def.Window); err == nil && window > 0 {
+		return window
+	}
+	return defaultAlertWindow
+}
+
+func (def *AlertRuleDefinition) holdFor() time.Duration {
+	d, _ := time.ParseDuration(def.For)
+	return d
+}
+
+// AlertState is the state of an alert rule.
+type AlertState string
+
+const (
+	// AlertInactive rules do not satisfy their condition.
+	AlertInactive AlertState = "inactive"
+	// AlertPending rules satisfy their condition, not for long enough to fire.
+	AlertPending AlertState = "pending"
+	// AlertFiring rules have satisfied their condition for long enough, and were notified.
+	AlertFiring AlertState = "firing"
+)
+
+// AlertRule is an alert rule stored server-side, along with its definition as written by its owner and its
+// state as of its latest evaluation.
+type AlertRule struct {
+	ID          uuid.UUID `json:"id" gorm:"primarykey"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Definition  string    `json:"definition"`
+	UserID      uuid.UUID `json:"user_id" gorm:"index"`
+
+	State        AlertState `json:"state"`
+	Value        float64    `json:"value"`
+	PendingSince *time.Time `json:"pending_since,omitempty"`
+	FiredAt      *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	EvaluatedAt  *time.Time `json:"evaluated_at,omitempty"`
+	Error        string     `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AlertTransition is a change of the state of an alert rule which is notified.
+type AlertTransition string
+
+const (
+	AlertTransitionFired    AlertTransition = "fired"
+	AlertTransitionResolved AlertTransition = "resolved"
+)
+
+// Observe updates the state of the rule with the value of its metric at the given time and returns the
+// transition to notify, if any.
+func (rule *AlertRule) Observe(def *AlertRuleDefinition, value float64, now time.Time) AlertTransition {
+	rule.Value = value
+	rule.EvaluatedAt = &now
+	rule.Error = ""
+
+	if !def.Holds(value) {
+		rule.PendingSince = nil
+		if rule.State == AlertFiring {
+			rule.State = AlertInactive
+			rule.ResolvedAt = &now
+			return AlertTransitionResolved
+		}
+		rule.State = AlertInactive
+		return ""
+	}
+
+	if rule.State == AlertFiring {
+		return ""
+	}
+	if rule.PendingSince == nil {
+		rule.PendingSince = &now
+	}
+	if now.Sub(*rule.PendingSince) < def.holdFor() {
+		rule.State = AlertPending
+		return ""
+	}
+	rule.State = AlertFiring
+	rule.FiredAt = &now
+	return AlertTransitionFired
+}
+
+// AlertMeasurer measures the metrics of alert rules from the database and the adapters of Meshery.
+type AlertMeasurer struct {
+	DB       *database.Handler
+	Adapters AdaptersTrackerInterface
+}
+
+// Measure returns the value of the metric of the rule at the given time.
+func (m *AlertMeasurer) Measure(ctx context.Context, def *AlertRuleDefinition, now time.Time) (float64, error) {
+	switch def.Metric {
+	case AlertMetricEventRate:
+		return m.eventCount(def.Filter, now.Add(-def.window()))
+	case AlertMetricDeploymentFailureStreak:
+		return m.deploymentFailureStreak(def.Filter)
+	case AlertMetricDriftCount:
+		return m.driftCount()
+	case AlertMetricAdapterDown:
+		return m.downAdapters(ctx)
+	}
+	return 0, fmt.Errorf("unknown metric %q", def.Metric)
+}
+
+func (m *AlertMeasurer) eventCount(filter AlertFilter, since time.Time) (float64, error) {
+	var count int64
+	query := m.DB.Table("events").Where("created_at >= ?", since)
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.Design != "" {
+		query = query.Where("acted_upon = ?", filter.Design)
+	}
+	err := query.Count(&count).Error
+	return float64(count), err
+}
+
+// alertDeploymentEvents bounds the deployment events looked at for failure streaks.
+const alertDeploymentEvents = 500
+
+func (m *AlertMeasurer) deploymentFailureStreak(filter AlertFilter) (float64, error) {
+	type deploymentEvent struct {
+		ActedUpon string
+		Severity  string
+	}
+	deployments := []deploymentEvent{}
+	query := m.DB.Table("events").Select("acted_upon, severity").
+		Where("category = ? AND action = ?", "pattern", "deploy").
+		Order("created_at desc").Limit(alertDeploymentEvents)
+	if filter.Design != "" {
+		query = query.Where("acted_upon = ?", filter.Design)
+	}
+	if err := query.Find(&deployments).Error; err != nil {
+		return 0, err
+	}
+
+	// the latest deployments first, the streak of a design ends at its latest successful deployment
+	streaks := map[string]int{}
+	ended := map[string]bool{}
+	longest := 0
+	for _, deployment := range deployments {
+		if ended[deployment.ActedUpon] {
+			continue
+		}
+		if deployment.Severity != string(events.Error) {
+			ended[deployment.ActedUpon] = true
+			continue
+		}
+		streaks[deployment.ActedUpon]++
+		longest = max(longest, streaks[deployment.ActedUpon])
+	}
+	return float64(longest), nil
+}
+
+func (m *AlertMeasurer) driftCount() (float64, error) {
+	ownerships := []ResourceOwnership{}
+	if err := m.DB.Find(&ownerships).Error; err != nil {
+		return 0, err
+	}
+	if len(ownerships) == 0 {
+		return 0, nil
+	}
+	clusterIDs := []string{}
+	kinds := []string{}
+	for _, ownership := range ownerships {
+		clusterIDs = append(clusterIDs, ownership.ClusterID)
+		kinds = append(kinds, ownership.Kind)
+	}
+
+	resources := []meshsyncmodel.KubernetesResource{}
+	err := m.DB.Preload("KubernetesResourceMeta").Where("cluster_id IN ? AND kind IN ?", clusterIDs, kinds).Find(&resources).Error
+	if err != nil {
+		return 0, err
+	}
+	discovered := map[string]bool{}
+	synced := map[string]bool{}
+	for _, res := range resources {
+		synced[res.ClusterID] = true
+		if res.KubernetesResourceMeta == nil {
+			continue
+		}
+		resource := OwnedResource{Kind: res.Kind, Namespace: res.KubernetesResourceMeta.Namespace, Name: res.KubernetesResourceMeta.Name}
+		discovered[res.ClusterID+" "+resource.String()] = true
+	}
+
+	drifted := 0
+	for _, ownership := range ownerships {
+		// clusters without any resource discovered are not synchronized by MeshSync
+		if synced[ownership.ClusterID] && !discovered[ownership.ClusterID+" "+ownership.Resource().String()] {
+			drifted++
+		}
+	}
+	return float64(drifted), nil
+}
+
+// alertAdapterTimeout bounds the wait for the response of every adapter.
+const alertAdapterTimeout = 5 * time.Second
+
+func (m *AlertMeasurer) downAdapters(ctx context.Context) (float64, error) {
+	if m.Adapters == nil {
+		return 0, nil
+	}
+	down := 0
+	for _, adapter := range m.Adapters.GetAdapters(ctx) {
+		if err := pingAdapter(ctx, adapter); err != nil {
+			down++
+		}
+	}
+	return float64(down), nil
+}
+
+func pingAdapter(ctx context.Context, adapter Adapter) error {
+	ctx, cancel := context.WithTimeout(ctx, alertAdapterTimeout)
+	defer cancel()
+	client, err := meshes.CreateClient(ctx, adapter.Location)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+	_, err = client.MClient.MeshName(ctx, &meshes.MeshNameRequest{})
+	return err
+}
+
+// AlertRulePersister is the persister for alert rules
+type AlertRulePersister struct {
+	DB *database.Handler
+}
+
+// SaveRule creates the rule, or updates it if it already exists.
+func (ap *AlertRulePersister) SaveRule(rule *AlertRule) error {
+	if rule.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		rule.ID = id
+	}
+	if rule.State == "" {
+		rule.State = AlertInactive
+	}
+	return ap.DB.Save(rule).Error
+}
+
+// GetRule returns the rule with the given id, nil if it does not exist.
+func (ap *AlertRulePersister) GetRule(id uuid.UUID) (*AlertRule, error) {
+	rules := []AlertRule{}
+	if err := ap.DB.Where("id = ?", id).Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+// GetRules returns the rules of the user, by name, or every rule when the user is nil.
+func (ap *AlertRulePersister) GetRules(userID uuid.UUID) ([]AlertRule, error) {
+	rules := []AlertRule{}
+	query := ap.DB.Order("name")
+	if userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// DeleteRule removes the rule.
+func (ap *AlertRulePersister) DeleteRule(id uuid.UUID) error {
+	return ap.DB.Where("id = ?", id).Delete(&AlertRule{}).Error
+}
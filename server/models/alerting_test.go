@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertRuleObserve(t *testing.T) {
+	def := &AlertRuleDefinition{Name: "failing", Metric: AlertMetricDeploymentFailureStreak, Condition: ">= 3", For: "10m"}
+	start := time.Now()
+
+	steps := []struct {
+		after          time.Duration
+		value          float64
+		wantState      AlertState
+		wantTransition AlertTransition
+	}{
+		{after: 0, value: 1, wantState: AlertInactive},
+		{after: time.Minute, value: 3, wantState: AlertPending},
+		{after: 5 * time.Minute, value: 4, wantState: AlertPending},
+		{after: 11 * time.Minute, value: 4, wantState: AlertFiring, wantTransition: AlertTransitionFired},
+		{after: 12 * time.Minute, value: 5, wantState: AlertFiring},
+		{after: 13 * time.Minute, value: 0, wantState: AlertInactive, wantTransition: AlertTransitionResolved},
+		{after: 14 * time.Minute, value: 0, wantState: AlertInactive},
+		// pending again from the start, the condition held for 10m no longer
+		{after: 15 * time.Minute, value: 3, wantState: AlertPending},
+	}
+	rule := &AlertRule{State: AlertInactive}
+	for i, step := range steps {
+		transition := rule.Observe(def, step.value, start.Add(step.after))
+		if rule.State != step.wantState || transition != step.wantTransition {
+			t.Errorf("step %d: state = %s, transition %q, want %s, %q", i, rule.State, transition, step.wantState, step.wantTransition)
+		}
+	}
+	if rule.FiredAt == nil || rule.ResolvedAt == nil || !rule.PendingSince.Equal(start.Add(15*time.Minute)) {
+		t.Errorf("unexpected times of the rule %+v", rule)
+	}
+}
+
+func TestParseAlertRuleDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     string
+		wantErr bool
+	}{
+		{name: "valid", def: `{name: failing, metric: event_rate, condition: "> 10", window: 5m, for: 1m, channels: [{type: events}, {type: webhook, url: "https://hooks.example.com"}]}`},
+		{name: "no name", def: `{metric: event_rate, condition: "> 10", channels: [{type: events}]}`, wantErr: true},
+		{name: "unknown metric", def: `{name: failing, metric: cpu, condition: "> 10", channels: [{type: events}]}`, wantErr: true},
+		{name: "invalid condition", def: `{name: failing, metric: event_rate, condition: "more than 10", channels: [{type: events}]}`, wantErr: true},
+		{name: "invalid window", def: `{name: failing, metric: event_rate, condition: "> 10", window: -5m, channels: [{type: events}]}`, wantErr: true},
+		{name: "no channels", def: `{name: failing, metric: event_rate, condition: "> 10"}`, wantErr: true},
+		{name: "webhook without url", def: `{name: failing, metric: event_rate, condition: "> 10", channels: [{type: webhook}]}`, wantErr: true},
+		{name: "invalid design", def: `{name: failing, metric: deployment_failure_streak, condition: "> 1", filter: {design: web}, channels: [{type: events}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAlertRuleDefinition([]byte(tt.def)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseAlertRuleDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
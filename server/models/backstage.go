@@ -0,0 +1,342 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/encoding"
+	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// BackstageAPIVersion is the apiVersion of the entities ingested by the Backstage catalog.
+const BackstageAPIVersion = "backstage.io/v1alpha1"
+
+// Annotations set on the Backstage entities of designs.
+const (
+	BackstageDesignIDAnnotation = "meshery.io/design-id"
+	BackstageHealthAnnotation   = "meshery.io/health"
+)
+
+// BackstageSignatureHeader carries the HMAC-SHA256 of the notification body, keyed with the secret of the webhook.
+const BackstageSignatureHeader = "X-Meshery-Signature"
+
+// DesignHealth summarizes the state of the resources deployed from a design.
+type DesignHealth string
+
+const (
+	// DesignHealthHealthy means every component of the design has a running resource.
+	DesignHealthHealthy DesignHealth = "healthy"
+	// DesignHealthDegraded means some components have no resource, or a resource which failed.
+	DesignHealthDegraded DesignHealth = "degraded"
+	// DesignHealthNotDeployed means no resource was deployed from the design.
+	DesignHealthNotDeployed DesignHealth = "not-deployed"
+)
+
+var (
+	backstageNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	backstageNameSeparators   = regexp.MustCompile(`[._-]{2,}`)
+)
+
+// BackstageLink is an external link shown on the page of an entity.
+type BackstageLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// BackstageEntityMetadata is the metadata of a Backstage catalog entity.
+type BackstageEntityMetadata struct {
+	Name        string            `json:"name"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Links       []BackstageLink   `json:"links,omitempty"`
+}
+
+// BackstageEntity is an entity in the catalog-info format ingested by Backstage.
+type BackstageEntity struct {
+	APIVersion string                  `json:"apiVersion"`
+	Kind       string                  `json:"kind"`
+	Metadata   BackstageEntityMetadata `json:"metadata"`
+	Spec       map[string]interface{}  `json:"spec"`
+}
+
+// BackstageEntities are the designs and their owners, as catalog entities.
+type BackstageEntities struct {
+	Items []BackstageEntity `json:"items"`
+}
+
+// BackstageWebhook is notified when a design changes so Backstage can refresh the entity of the design.
+type BackstageWebhook struct {
+	ID  uuid.UUID `json:"id,omitempty" gorm:"primarykey"`
+	URL string    `json:"url"`
+	// Secret signs the notifications, it is never returned by the API.
+	Secret string `json:"secret,omitempty"`
+	// MesheryURL is the address of Meshery used in the links of the notified entities.
+	MesheryURL string    `json:"meshery_url"`
+	UserID     uuid.UUID `json:"user_id"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// BackstageNotification is the body of the requests sent to the webhooks.
+// Entity is nil when the design was deleted.
+type BackstageNotification struct {
+	Event      string           `json:"event"`
+	DesignID   uuid.UUID        `json:"design_id"`
+	DesignName string           `json:"design_name"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Entity     *BackstageEntity `json:"entity,omitempty"`
+}
+
+// BackstagePersister is the persister for Backstage webhooks and the health of designs
+type BackstagePersister struct {
+	DB *database.Handler
+}
+
+// SaveWebhook creates the webhook, or updates it if it already exists.
+func (bp *BackstagePersister) SaveWebhook(webhook *BackstageWebhook) error {
+	if webhook.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		webhook.ID = id
+	}
+	return bp.DB.Save(webhook).Error
+}
+
+// GetWebhooks returns the registered webhooks.
+func (bp *BackstagePersister) GetWebhooks() ([]BackstageWebhook, error) {
+	webhooks := []BackstageWebhook{}
+	err := bp.DB.Order("created_at").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// DeleteWebhook removes the webhook with the given id.
+func (bp *BackstagePersister) DeleteWebhook(id uuid.UUID) error {
+	return bp.DB.Where("id = ?", id).Delete(&BackstageWebhook{}).Error
+}
+
+// GetDesign returns the design with the given id.
+func (bp *BackstagePersister) GetDesign(id uuid.UUID) (*MesheryPattern, error) {
+	design := MesheryPattern{}
+	err := bp.DB.Table("meshery_patterns").Where("id = ?", id).First(&design).Error
+	return &design, err
+}
+
+// GetDesigns returns the stored designs.
+func (bp *BackstagePersister) GetDesigns() ([]MesheryPattern, error) {
+	designs := []MesheryPattern{}
+	err := bp.DB.Table("meshery_patterns").Order("name").Find(&designs).Error
+	return designs, err
+}
+
+// GetDesignsHealth returns the health of the designs, from the resources discovered by MeshSync.
+// Resources deployed from a design are labelled with the id of their component.
+func (bp *BackstagePersister) GetDesignsHealth(designs []MesheryPattern) (map[uuid.UUID]DesignHealth, error) {
+	resources := []meshsyncmodel.KubernetesResource{}
+	err := bp.DB.Preload("KubernetesResourceMeta.Labels", "kind = ?", meshsyncmodel.KindLabel).Preload("Status").
+		Find(&resources).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// failed is true for the components with at least one failed resource
+	failed := map[string]bool{}
+	for _, res := range resources {
+		if res.KubernetesResourceMeta == nil || res.KubernetesResourceMeta.DeletionTimestamp != "" {
+			continue
+		}
+		for _, label := range res.KubernetesResourceMeta.Labels {
+			if label != nil && label.Key == DesignComponentLabel {
+				failed[label.Value] = failed[label.Value] || resourceFailed(res.Status)
+				break
+			}
+		}
+	}
+
+	health := make(map[uuid.UUID]DesignHealth, len(designs))
+	for _, design := range designs {
+		if design.ID == nil {
+			continue
+		}
+		var patternFile pattern.PatternFile
+		if err := encoding.Unmarshal([]byte(design.PatternFile), &patternFile); err != nil {
+			health[*design.ID] = DesignHealthNotDeployed
+			continue
+		}
+		deployed, healthy := 0, 0
+		for _, comp := range patternFile.Components {
+			if comp == nil {
+				continue
+			}
+			compFailed, ok := failed[comp.Id.String()]
+			if !ok {
+				continue
+			}
+			deployed++
+			if !compFailed {
+				healthy++
+			}
+		}
+		switch {
+		case deployed == 0:
+			health[*design.ID] = DesignHealthNotDeployed
+		case healthy == len(patternFile.Components):
+			health[*design.ID] = DesignHealthHealthy
+		default:
+			health[*design.ID] = DesignHealthDegraded
+		}
+	}
+	return health, nil
+}
+
+// resourceFailed reports if the status of the resource is a failed phase or has a false Ready or Available condition.
+func resourceFailed(status *meshsyncmodel.KubernetesResourceStatus) bool {
+	if status == nil || status.Attribute == "" {
+		return false
+	}
+	var s struct {
+		Phase      string `json:"phase"`
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	}
+	if err := json.Unmarshal([]byte(status.Attribute), &s); err != nil {
+		return false
+	}
+	if s.Phase == "Failed" {
+		return true
+	}
+	for _, c := range s.Conditions {
+		if (c.Type == "Ready" || c.Type == "Available") && c.Status == "False" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBackstageDesignEntity returns the design as a Backstage Component owned by the user who created it.
+// mesheryURL is the address of Meshery used in the links of the entity.
+func NewBackstageDesignEntity(design *MesheryPattern, health DesignHealth, owner, mesheryURL string) BackstageEntity {
+	id := ""
+	if design.ID != nil {
+		id = design.ID.String()
+	}
+
+	lifecycle := "experimental"
+	if design.Visibility == Published {
+		lifecycle = "production"
+	}
+
+	entity := BackstageEntity{
+		APIVersion: BackstageAPIVersion,
+		Kind:       "Component",
+		Metadata: BackstageEntityMetadata{
+			Name:  BackstageEntityName(fmt.Sprintf("%s-%s", design.Name, strings.Split(id, "-")[0])),
+			Title: design.Name,
+			Annotations: map[string]string{
+				BackstageDesignIDAnnotation: id,
+				BackstageHealthAnnotation:   string(health),
+			},
+			Tags: []string{"meshery", "design"},
+		},
+		Spec: map[string]interface{}{
+			"type":      "meshery-design",
+			"lifecycle": lifecycle,
+			"owner":     "user:" + BackstageEntityName(owner),
+		},
+	}
+	if mesheryURL != "" && id != "" {
+		mesheryURL = strings.TrimSuffix(mesheryURL, "/")
+		entity.Metadata.Links = []BackstageLink{
+			{URL: fmt.Sprintf("%s/configuration/designs/configurator?design_id=%s", mesheryURL, id), Title: "Open in Meshery", Icon: "dashboard"},
+			{URL: fmt.Sprintf("%s/api/pattern/download/%s", mesheryURL, id), Title: "Download design", Icon: "download"},
+		}
+	}
+	return entity
+}
+
+// NewBackstageUserEntity returns the owner of designs as a Backstage User, named after the id of the user.
+func NewBackstageUserEntity(user *User) BackstageEntity {
+	profile := map[string]interface{}{
+		"displayName": strings.TrimSpace(user.FirstName + " " + user.LastName),
+	}
+	if user.Email != "" {
+		profile["email"] = user.Email
+	}
+	if user.AvatarURL != "" {
+		profile["picture"] = user.AvatarURL
+	}
+	return BackstageEntity{
+		APIVersion: BackstageAPIVersion,
+		Kind:       "User",
+		Metadata: BackstageEntityMetadata{
+			Name:  BackstageEntityName(user.ID),
+			Title: user.UserID,
+		},
+		Spec: map[string]interface{}{
+			"profile":  profile,
+			"memberOf": []string{},
+		},
+	}
+}
+
+// BackstageEntityName returns the name as a valid Backstage entity name:
+// at most 63 alphanumerics, separated by single dashes, underscores or dots.
+func BackstageEntityName(name string) string {
+	name = backstageNameInvalidChars.ReplaceAllString(name, "-")
+	name = strings.Trim(backstageNameSeparators.ReplaceAllString(name, "-"), "-_.")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-_.")
+	}
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
+
+// NotifyBackstageWebhook sends the notification to the webhook, signed with its secret.
+func NotifyBackstageWebhook(ctx context.Context, webhook BackstageWebhook, notification BackstageNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return ErrMarshal(err, "backstage notification")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(webhook.Secret))
+		_, _ = mac.Write(body)
+		req.Header.Set(BackstageSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrStatusCode(resp.StatusCode)
+	}
+	return nil
+}
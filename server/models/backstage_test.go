@@ -0,0 +1,156 @@
+package models
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the tests")
+
+// checkGolden compares the JSON of the value with the golden file in testdata, or updates the file with -update.
+func checkGolden(t *testing.T, name string, value interface{}) {
+	t.Helper()
+	got, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from the golden file:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestNewBackstageDesignEntity(t *testing.T) {
+	id := uuid.FromStringOrNil("3c8a8a5e-4a5c-4c8c-9c4c-2d7f4b1a6f10")
+	owner := "9c0b8f0e-3b0d-4f0a-9a43-9a1b3c0b6e21"
+
+	tests := []struct {
+		golden     string
+		design     MesheryPattern
+		health     DesignHealth
+		mesheryURL string
+	}{
+		{
+			golden:     "backstage_design_published.golden.json",
+			design:     MesheryPattern{ID: &id, Name: "Online Boutique", UserID: &owner, Visibility: Published},
+			health:     DesignHealthHealthy,
+			mesheryURL: "https://meshery.example.com/",
+		},
+		{
+			golden: "backstage_design_private.golden.json",
+			design: MesheryPattern{ID: &id, Name: "frontend (staging)", Visibility: Private},
+			health: DesignHealthNotDeployed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			owner := ""
+			if tt.design.UserID != nil {
+				owner = *tt.design.UserID
+			}
+			checkGolden(t, tt.golden, NewBackstageDesignEntity(&tt.design, tt.health, owner, tt.mesheryURL))
+		})
+	}
+}
+
+func TestNewBackstageUserEntity(t *testing.T) {
+	checkGolden(t, "backstage_user.golden.json", NewBackstageUserEntity(&User{
+		ID:        "9c0b8f0e-3b0d-4f0a-9a43-9a1b3c0b6e21",
+		UserID:    "jane",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Email:     "jane@example.com",
+	}))
+}
+
+func TestBackstageEntityName(t *testing.T) {
+	tests := map[string]string{
+		"web":              "web",
+		"Online Boutique":  "Online-Boutique",
+		"  frontend (v2) ": "frontend-v2",
+		"--.":              "unknown",
+		"web -- v2":        "web-v2",
+		"web.v2_beta":      "web.v2_beta",
+		"":                 "unknown",
+		"a-very-long-name-which-does-not-fit-in-the-sixty-three-characters-allowed": "a-very-long-name-which-does-not-fit-in-the-sixty-three-characte",
+	}
+	for name, want := range tests {
+		if got := BackstageEntityName(name); got != want {
+			t.Errorf("BackstageEntityName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNotifyBackstageWebhook(t *testing.T) {
+	notification := BackstageNotification{
+		Event:      "design_saved",
+		DesignID:   uuid.Must(uuid.NewV4()),
+		DesignName: "web",
+		Timestamp:  time.Now(),
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		wantErr bool
+	}{
+		{name: "signed", secret: "s3cr3t", status: http.StatusNoContent},
+		{name: "unsigned", status: http.StatusOK},
+		{name: "rejected", secret: "s3cr3t", status: http.StatusUnauthorized, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var signature string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				signature = r.Header.Get(BackstageSignatureHeader)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NotifyBackstageWebhook(context.Background(), BackstageWebhook{URL: srv.URL, Secret: tt.secret}, notification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NotifyBackstageWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := BackstageNotification{}
+			if err := json.Unmarshal(body, &got); err != nil || got.DesignID != notification.DesignID || got.Event != notification.Event {
+				t.Errorf("expected the notification to be sent, got %s", body)
+			}
+			wantSignature := ""
+			if tt.secret != "" {
+				mac := hmac.New(sha256.New, []byte(tt.secret))
+				_, _ = mac.Write(body)
+				wantSignature = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+			}
+			if signature != wantSignature {
+				t.Errorf("signature = %q, want %q", signature, wantSignature)
+			}
+		})
+	}
+}
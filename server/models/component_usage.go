@@ -0,0 +1,158 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/encoding"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ComponentUsage records how often the components of a model and kind were deployed.
+type ComponentUsage struct {
+	ID              uuid.UUID  `json:"id,omitempty" gorm:"primarykey"`
+	Model           string     `json:"model" gorm:"uniqueIndex:idx_component_usage_model_kind"`
+	Kind            string     `json:"kind" gorm:"uniqueIndex:idx_component_usage_model_kind"`
+	DeploymentCount int        `json:"deployment_count"`
+	LastDeployedAt  *time.Time `json:"last_deployed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ComponentUsageSummary is the usage of the components of a model and kind
+// across stored designs and deployments.
+type ComponentUsageSummary struct {
+	Model           string     `json:"model"`
+	Kind            string     `json:"kind"`
+	DesignCount     int        `json:"design_count"`
+	DeploymentCount int        `json:"deployment_count"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ComponentUsagePersister is the persister for component usage analytics
+type ComponentUsagePersister struct {
+	DB *database.Handler
+}
+
+// RecordDeployment increments the deployment count of every model and kind
+// used in the given design. A component used several times in the same design
+// is counted once.
+func (cup *ComponentUsagePersister) RecordDeployment(patternFile *pattern.PatternFile) error {
+	now := time.Now()
+	seen := map[string]bool{}
+
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Component.Kind == "" {
+			continue
+		}
+		key := comp.Model.Name + "/" + comp.Component.Kind
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		usage := ComponentUsage{
+			ID:              id,
+			Model:           comp.Model.Name,
+			Kind:            comp.Component.Kind,
+			DeploymentCount: 1,
+			LastDeployedAt:  &now,
+		}
+		err = cup.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "model"}, {Name: "kind"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"deployment_count": gorm.Expr("deployment_count + 1"),
+				"last_deployed_at": now,
+				"updated_at":       now,
+			}),
+		}).Create(&usage).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetComponentUsage returns the usage of components in stored designs and
+// deployments, most used first. Empty model and kind match all components.
+func (cup *ComponentUsagePersister) GetComponentUsage(model, kind string) ([]ComponentUsageSummary, error) {
+	summaries := map[string]*ComponentUsageSummary{}
+	summaryFor := func(model, kind string) *ComponentUsageSummary {
+		key := model + "/" + kind
+		if s, ok := summaries[key]; ok {
+			return s
+		}
+		s := &ComponentUsageSummary{Model: model, Kind: kind}
+		summaries[key] = s
+		return s
+	}
+	matches := func(m, k string) bool {
+		return (model == "" || m == model) && (kind == "" || k == kind)
+	}
+
+	deployments := []ComponentUsage{}
+	if err := cup.DB.Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	for _, usage := range deployments {
+		if !matches(usage.Model, usage.Kind) {
+			continue
+		}
+		s := summaryFor(usage.Model, usage.Kind)
+		s.DeploymentCount = usage.DeploymentCount
+		s.LastUsedAt = usage.LastDeployedAt
+	}
+
+	designs := []MesheryPattern{}
+	if err := cup.DB.Table("meshery_patterns").Select("pattern_file", "updated_at").Find(&designs).Error; err != nil {
+		return nil, err
+	}
+	for _, design := range designs {
+		var patternFile pattern.PatternFile
+		if err := encoding.Unmarshal([]byte(design.PatternFile), &patternFile); err != nil {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, comp := range patternFile.Components {
+			if comp == nil || !matches(comp.Model.Name, comp.Component.Kind) {
+				continue
+			}
+			key := comp.Model.Name + "/" + comp.Component.Kind
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			s := summaryFor(comp.Model.Name, comp.Component.Kind)
+			s.DesignCount++
+			if design.UpdatedAt != nil && (s.LastUsedAt == nil || design.UpdatedAt.After(*s.LastUsedAt)) {
+				s.LastUsedAt = design.UpdatedAt
+			}
+		}
+	}
+
+	result := make([]ComponentUsageSummary, 0, len(summaries))
+	for _, s := range summaries {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ui, uj := result[i].DesignCount+result[i].DeploymentCount, result[j].DesignCount+result[j].DeploymentCount
+		if ui != uj {
+			return ui > uj
+		}
+		if result[i].Model != result[j].Model {
+			return result[i].Model < result[j].Model
+		}
+		return result[i].Kind < result[j].Kind
+	})
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func newComponentUsageTestPatternFile(name string, kinds ...string) *pattern.PatternFile {
+	patternFile := &pattern.PatternFile{Name: name}
+	for i, kind := range kinds {
+		patternFile.Components = append(patternFile.Components, &component.ComponentDefinition{
+			Id:          uuid.NewV5(uuid.Nil, name+kind+string(rune('a'+i))),
+			DisplayName: kind,
+			Component:   component.Component{Kind: kind},
+			Model:       model.ModelDefinition{Name: "kubernetes"},
+		})
+	}
+	return patternFile
+}
+
+func TestComponentUsagePersister(t *testing.T) {
+	db := newTestDB(t, &MesheryPattern{}, &ComponentUsage{})
+	cup := &ComponentUsagePersister{DB: db}
+
+	// Components used several times in a design are counted once per design and deployment
+	designs := []*pattern.PatternFile{
+		newComponentUsageTestPatternFile("web", "Deployment", "Deployment", "Service"),
+		newComponentUsageTestPatternFile("api", "Deployment", "ConfigMap"),
+		newComponentUsageTestPatternFile("jobs", "CronJob"),
+	}
+	for _, patternFile := range designs {
+		byt, err := json.Marshal(patternFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := uuid.Must(uuid.NewV4())
+		if err := db.Create(&MesheryPattern{ID: &id, Name: patternFile.Name, PatternFile: string(byt)}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, patternFile := range []*pattern.PatternFile{designs[0], designs[0], designs[1]} {
+		if err := cup.RecordDeployment(patternFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Deployed designs need not be stored
+	if err := cup.RecordDeployment(newComponentUsageTestPatternFile("adhoc", "Secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	deployments := []ComponentUsage{}
+	if err := db.Find(&deployments).Error; err != nil {
+		t.Fatal(err)
+	}
+	gotDeployments := map[string]int{}
+	for _, usage := range deployments {
+		gotDeployments[usage.Model+"/"+usage.Kind] = usage.DeploymentCount
+		if usage.LastDeployedAt == nil {
+			t.Errorf("deployment of %s/%s has no time", usage.Model, usage.Kind)
+		}
+	}
+	wantDeployments := map[string]int{
+		"kubernetes/Deployment": 3,
+		"kubernetes/Service":    2,
+		"kubernetes/ConfigMap":  1,
+		"kubernetes/Secret":     1,
+	}
+	if !reflect.DeepEqual(gotDeployments, wantDeployments) {
+		t.Errorf("RecordDeployment() recorded %v, want %v", gotDeployments, wantDeployments)
+	}
+
+	tests := []struct {
+		name        string
+		model, kind string
+		want        []ComponentUsageSummary
+	}{
+		{
+			name: "all components",
+			want: []ComponentUsageSummary{
+				{Model: "kubernetes", Kind: "Deployment", DesignCount: 2, DeploymentCount: 3},
+				{Model: "kubernetes", Kind: "Service", DesignCount: 1, DeploymentCount: 2},
+				{Model: "kubernetes", Kind: "ConfigMap", DesignCount: 1, DeploymentCount: 1},
+				{Model: "kubernetes", Kind: "CronJob", DesignCount: 1},
+				{Model: "kubernetes", Kind: "Secret", DeploymentCount: 1},
+			},
+		},
+		{
+			name: "components of a kind",
+			kind: "Service",
+			want: []ComponentUsageSummary{
+				{Model: "kubernetes", Kind: "Service", DesignCount: 1, DeploymentCount: 2},
+			},
+		},
+		{
+			name:  "components of another model",
+			model: "istio",
+			want:  []ComponentUsageSummary{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cup.GetComponentUsage(tt.model, tt.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				if got[i].LastUsedAt == nil {
+					t.Errorf("usage of %s/%s has no last use", got[i].Model, got[i].Kind)
+				}
+				got[i].LastUsedAt = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetComponentUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/encoding"
+	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CostCenterResourceType is the kind of resource a cost center tag is assigned to.
+type CostCenterResourceType string
+
+const (
+	CostCenterResourceDesign    CostCenterResourceType = "design"
+	CostCenterResourceWorkspace CostCenterResourceType = "workspace"
+)
+
+// UnallocatedCostCenter groups the usage of designs without a cost center.
+const UnallocatedCostCenter = "unallocated"
+
+// DesignComponentLabel is set on every resource deployed from a design to the id of its component.
+const DesignComponentLabel = "resource.pattern.meshery.io/id"
+
+// CostCenterTag assigns a design or a workspace to the cost center and team its usage is charged to.
+// A tag on a design takes precedence over a tag on the workspaces the design belongs to.
+type CostCenterTag struct {
+	ID           uuid.UUID              `json:"id,omitempty" gorm:"primarykey"`
+	ResourceID   uuid.UUID              `json:"resource_id" gorm:"uniqueIndex:idx_cost_center_tag_resource"`
+	ResourceType CostCenterResourceType `json:"resource_type" gorm:"uniqueIndex:idx_cost_center_tag_resource"`
+	CostCenter   string                 `json:"cost_center"`
+	Team         string                 `json:"team"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// CostRates are the prices used to turn resource usage into a chargeback.
+// When both rates are zero the report is a showback of usage only.
+type CostRates struct {
+	CPUCoreHour   float64 `json:"cpu_core_hour"`
+	MemoryGiBHour float64 `json:"memory_gib_hour"`
+}
+
+// CostReportEntry is the usage of the designs of a cost center and team.
+type CostReportEntry struct {
+	CostCenter     string   `json:"cost_center"`
+	Team           string   `json:"team"`
+	Designs        []string `json:"designs"`
+	CPUCoreHours   float64  `json:"cpu_core_hours"`
+	MemoryGiBHours float64  `json:"memory_gib_hours"`
+	Cost           float64  `json:"cost"`
+}
+
+// CostReport is the chargeback (or showback, without rates) of a month.
+type CostReport struct {
+	Month   string            `json:"month"`
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Rates   CostRates         `json:"rates"`
+	Entries []CostReportEntry `json:"entries"`
+}
+
+// CostCenterPersister is the persister for cost center tags and reports
+type CostCenterPersister struct {
+	DB *database.Handler
+}
+
+// SaveTag creates the tag, or updates the tag already assigned to the same resource.
+func (ccp *CostCenterPersister) SaveTag(tag *CostCenterTag) error {
+	if tag.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		tag.ID = id
+	}
+	return ccp.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_id"}, {Name: "resource_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cost_center", "team", "updated_at"}),
+	}).Create(tag).Error
+}
+
+// GetTags returns the cost center tags, of the given resource type if not empty.
+func (ccp *CostCenterPersister) GetTags(resourceType CostCenterResourceType) ([]CostCenterTag, error) {
+	tags := []CostCenterTag{}
+	query := ccp.DB.Order("cost_center, team")
+	if resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+	err := query.Find(&tags).Error
+	return tags, err
+}
+
+// DeleteTag removes the cost center tag of the resource.
+func (ccp *CostCenterPersister) DeleteTag(resourceType CostCenterResourceType, resourceID uuid.UUID) error {
+	return ccp.DB.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).Delete(&CostCenterTag{}).Error
+}
+
+// GenerateCostReport correlates the resources discovered by MeshSync with the designs they
+// were deployed from and reports the usage of every cost center and team in the month
+// starting at the given time. Usage is measured as resource requests multiplied by the
+// hours the resource existed during the month.
+func (ccp *CostCenterPersister) GenerateCostReport(month time.Time, rates CostRates) (*CostReport, error) {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	if now := time.Now().UTC(); now.Before(to) {
+		to = now
+	}
+
+	tags, err := ccp.GetTags("")
+	if err != nil {
+		return nil, err
+	}
+	designTags := map[uuid.UUID]CostCenterTag{}
+	workspaceTags := map[uuid.UUID]CostCenterTag{}
+	for _, tag := range tags {
+		if tag.ResourceType == CostCenterResourceWorkspace {
+			workspaceTags[tag.ResourceID] = tag
+		} else {
+			designTags[tag.ResourceID] = tag
+		}
+	}
+
+	mappings := []v1beta1.WorkspacesDesignsMapping{}
+	if err := ccp.DB.Where("deleted_at IS NULL").Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range mappings {
+		if _, ok := designTags[m.DesignId]; ok {
+			continue
+		}
+		if tag, ok := workspaceTags[m.WorkspaceId]; ok {
+			designTags[m.DesignId] = tag
+		}
+	}
+
+	// Resources deployed from a design are labelled with the id of their component.
+	designs := []MesheryPattern{}
+	if err := ccp.DB.Table("meshery_patterns").Select("id", "name", "pattern_file").Find(&designs).Error; err != nil {
+		return nil, err
+	}
+	componentDesigns := map[string]costDesignComponent{}
+	for _, design := range designs {
+		var patternFile pattern.PatternFile
+		if err := encoding.Unmarshal([]byte(design.PatternFile), &patternFile); err != nil {
+			continue
+		}
+		for _, comp := range patternFile.Components {
+			if comp != nil {
+				componentDesigns[comp.Id.String()] = costDesignComponent{design: design, kind: comp.Component.Kind}
+			}
+		}
+	}
+
+	resources := []meshsyncmodel.KubernetesResource{}
+	err = ccp.DB.Preload("KubernetesResourceMeta.Labels", "kind = ?", meshsyncmodel.KindLabel).Preload("Spec").
+		Where("kind IN ?", []string{"Pod", "Deployment", "StatefulSet", "DaemonSet", "Job"}).
+		Find(&resources).Error
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]*CostReportEntry{}
+	for _, res := range resources {
+		if res.KubernetesResourceMeta == nil || res.Spec == nil {
+			continue
+		}
+		var componentID string
+		for _, label := range res.KubernetesResourceMeta.Labels {
+			if label != nil && label.Key == DesignComponentLabel {
+				componentID = label.Value
+				break
+			}
+		}
+		// Only the workload declared by the component is counted, not the pods it owns, which
+		// carry the label of the component as well when it is set on the pod template.
+		comp, ok := componentDesigns[componentID]
+		if !ok || comp.design.ID == nil || comp.kind != res.Kind {
+			continue
+		}
+		design := comp.design
+
+		hours := resourceHours(res.KubernetesResourceMeta, from, to)
+		if hours == 0 {
+			continue
+		}
+		cpu, memory := workloadRequests(res.Kind, res.Spec.Attribute)
+
+		tag, ok := designTags[*design.ID]
+		if !ok {
+			tag = CostCenterTag{CostCenter: UnallocatedCostCenter}
+		}
+		key := tag.CostCenter + "/" + tag.Team
+		entry, ok := entries[key]
+		if !ok {
+			entry = &CostReportEntry{CostCenter: tag.CostCenter, Team: tag.Team}
+			entries[key] = entry
+		}
+		if !slices.Contains(entry.Designs, design.Name) {
+			entry.Designs = append(entry.Designs, design.Name)
+		}
+		entry.CPUCoreHours += cpu * hours
+		entry.MemoryGiBHours += memory * hours
+	}
+
+	report := &CostReport{
+		Month:   from.Format("2006-01"),
+		From:    from,
+		To:      to,
+		Rates:   rates,
+		Entries: make([]CostReportEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		entry.Cost = entry.CPUCoreHours*rates.CPUCoreHour + entry.MemoryGiBHours*rates.MemoryGiBHour
+		sort.Strings(entry.Designs)
+		report.Entries = append(report.Entries, *entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].CostCenter != report.Entries[j].CostCenter {
+			return report.Entries[i].CostCenter < report.Entries[j].CostCenter
+		}
+		return report.Entries[i].Team < report.Entries[j].Team
+	})
+	return report, nil
+}
+
+// costDesignComponent is a component of a design resources are charged to.
+type costDesignComponent struct {
+	design MesheryPattern
+	kind   string
+}
+
+// resourceHours returns the hours the resource existed between from and to.
+func resourceHours(meta *meshsyncmodel.KubernetesResourceObjectMeta, from, to time.Time) float64 {
+	start, err := time.Parse(time.RFC3339, meta.CreationTimestamp)
+	if err != nil {
+		return 0
+	}
+	end := to
+	if deleted, err := time.Parse(time.RFC3339, meta.DeletionTimestamp); err == nil && deleted.Before(end) {
+		end = deleted
+	}
+	if start.Before(from) {
+		start = from
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// workloadRequests returns the CPU cores and GiB of memory requested by the workload
+// with the given spec, for all of its replicas.
+func workloadRequests(kind, spec string) (cpu, memory float64) {
+	cpu, memory, replicas := workloadPodRequests(kind, spec)
+	return cpu * replicas, memory * replicas
+}
+
+// workloadPodRequests returns the CPU cores and GiB of memory requested by every pod of the
+// workload with the given spec, along with its replicas. DaemonSets are counted once.
+func workloadPodRequests(kind, spec string) (cpu, memory, replicas float64) {
+	var workload struct {
+		Replicas   *int64         `json:"replicas"`
+		Containers []podContainer `json:"containers"`
+		Template   struct {
+			Spec struct {
+				Containers []podContainer `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	}
+	if err := json.Unmarshal([]byte(spec), &workload); err != nil {
+		return 0, 0, 0
+	}
+
+	containers := workload.Template.Spec.Containers
+	if kind == "Pod" {
+		containers = workload.Containers
+	}
+	replicas = 1.0
+	if workload.Replicas != nil && kind != "Pod" && kind != "DaemonSet" {
+		replicas = float64(*workload.Replicas)
+	}
+
+	for _, c := range containers {
+		if q, err := resource.ParseQuantity(c.Resources.Requests["cpu"]); err == nil {
+			cpu += q.AsApproximateFloat64()
+		}
+		if q, err := resource.ParseQuantity(c.Resources.Requests["memory"]); err == nil {
+			memory += q.AsApproximateFloat64() / (1 << 30)
+		}
+	}
+	return cpu, memory, replicas
+}
+
+type podContainer struct {
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+	} `json:"resources"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func TestGenerateCostReport(t *testing.T) {
+	db := newTestDB(t,
+		&MesheryPattern{}, &CostCenterTag{}, &v1beta1.WorkspacesDesignsMapping{},
+		&meshsyncmodel.KubernetesKeyValue{}, &meshsyncmodel.KubernetesResource{},
+		&meshsyncmodel.KubernetesResourceSpec{}, &meshsyncmodel.KubernetesResourceObjectMeta{},
+	)
+	ccp := &CostCenterPersister{DB: db}
+
+	deploymentID := uuid.NewV5(uuid.Nil, "web")
+	podID := uuid.NewV5(uuid.Nil, "debug")
+	tagged := createCostTestDesign(t, db, "tagged", component.ComponentDefinition{Id: deploymentID, Component: component.Component{Kind: "Deployment"}})
+	createCostTestDesign(t, db, "untagged", component.ComponentDefinition{Id: podID, Component: component.Component{Kind: "Pod"}})
+	if err := ccp.SaveTag(&CostCenterTag{ResourceID: tagged, ResourceType: CostCenterResourceDesign, CostCenter: "cc-1", Team: "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	month := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	created := month.Add(-24 * time.Hour).Format(time.RFC3339)
+	hours := month.AddDate(0, 1, 0).Sub(month).Hours()
+
+	podTemplate := `{"containers":[{"resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]}`
+	// the deployment, along with its two pods which carry the label of the component through the pod template
+	createCostTestResource(t, db, "Deployment", "web", created, deploymentID, `{"replicas":2,"template":{"spec":`+podTemplate+`}}`)
+	createCostTestResource(t, db, "Pod", "web-1", created, deploymentID, podTemplate)
+	createCostTestResource(t, db, "Pod", "web-2", created, deploymentID, podTemplate)
+	// a bare pod declared by the design
+	createCostTestResource(t, db, "Pod", "debug", created, podID, podTemplate)
+	// a pod which was not deployed from a design
+	createCostTestResource(t, db, "Pod", "other", created, uuid.Nil, podTemplate)
+
+	report, err := ccp.GenerateCostReport(month, CostRates{CPUCoreHour: 1, MemoryGiBHour: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CostReportEntry{
+		{CostCenter: "cc-1", Team: "web", Designs: []string{"tagged"}, CPUCoreHours: 1 * hours, MemoryGiBHours: 2 * hours, Cost: 5 * hours},
+		{CostCenter: UnallocatedCostCenter, Designs: []string{"untagged"}, CPUCoreHours: 0.5 * hours, MemoryGiBHours: 1 * hours, Cost: 2.5 * hours},
+	}
+	if len(report.Entries) != len(want) {
+		t.Fatalf("report has %d entries, want %d: %+v", len(report.Entries), len(want), report.Entries)
+	}
+	for i, entry := range report.Entries {
+		if entry.CostCenter != want[i].CostCenter || entry.Team != want[i].Team || !reflect.DeepEqual(entry.Designs, want[i].Designs) {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+		for name, v := range map[string][2]float64{
+			"cpu":    {entry.CPUCoreHours, want[i].CPUCoreHours},
+			"memory": {entry.MemoryGiBHours, want[i].MemoryGiBHours},
+			"cost":   {entry.Cost, want[i].Cost},
+		} {
+			if math.Abs(v[0]-v[1]) > 1e-6 {
+				t.Errorf("%s of entry %d = %v, want %v", name, i, v[0], v[1])
+			}
+		}
+	}
+}
+
+func createCostTestDesign(t *testing.T, db *database.Handler, name string, comp component.ComponentDefinition) uuid.UUID {
+	t.Helper()
+	byt, err := json.Marshal(pattern.PatternFile{Name: name, Components: []*component.ComponentDefinition{&comp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := uuid.Must(uuid.NewV4())
+	if err := db.Create(&MesheryPattern{ID: &id, Name: name, PatternFile: string(byt)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func createCostTestResource(t *testing.T, db *database.Handler, kind, name, created string, componentID uuid.UUID, spec string) {
+	t.Helper()
+	id := uuid.Must(uuid.NewV4()).String()
+	res := &meshsyncmodel.KubernetesResource{
+		ID:   id,
+		Kind: kind,
+		KubernetesResourceMeta: &meshsyncmodel.KubernetesResourceObjectMeta{
+			ID:                id,
+			Name:              name,
+			CreationTimestamp: created,
+		},
+		Spec: &meshsyncmodel.KubernetesResourceSpec{ID: id, Attribute: spec},
+	}
+	if componentID != uuid.Nil {
+		res.KubernetesResourceMeta.Labels = []*meshsyncmodel.KubernetesKeyValue{{
+			ID: id, Kind: meshsyncmodel.KindLabel, Key: DesignComponentLabel, Value: componentID.String(),
+		}}
+	}
+	if err := db.Create(res).Error; err != nil {
+		t.Fatal(err)
+	}
+}
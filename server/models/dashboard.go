@@ -0,0 +1,220 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+)
+
+// DashboardGridColumns is the width of the grid the widgets of dashboards are laid out on.
+const DashboardGridColumns = 12
+
+// DashboardWidgetType is the type of a widget of a dashboard, which decides the options it takes.
+type DashboardWidgetType string
+
+const (
+	// DashboardWidgetResourceCount counts the Kubernetes resources of Kinds, in Namespace when it is set.
+	DashboardWidgetResourceCount DashboardWidgetType = "resource_count"
+	// DashboardWidgetDesignHealth shows the status of the deployments of DesignIDs, every design when empty.
+	DashboardWidgetDesignHealth DashboardWidgetType = "design_health"
+	// DashboardWidgetPerfTrend plots Metric of the results of the performance profile ProfileID over Window.
+	DashboardWidgetPerfTrend DashboardWidgetType = "perf_trend"
+	// DashboardWidgetPrometheusPanel plots Query, or the panel Panel of the Grafana board Board, over Window.
+	DashboardWidgetPrometheusPanel DashboardWidgetType = "prometheus_panel"
+)
+
+// dashboardPerfMetrics are the metrics of the results of performance profiles perf trends plot.
+var dashboardPerfMetrics = map[string]bool{
+	"rps": true, "p50": true, "p75": true, "p90": true, "p99": true, "p99.9": true, "max": true, "min": true, "avg": true,
+}
+
+// DashboardWidgetOptions are the options of widgets, the options of their type.
+type DashboardWidgetOptions struct {
+	Kinds     []string    `json:"kinds,omitempty"`
+	Namespace string      `json:"namespace,omitempty"`
+	DesignIDs []uuid.UUID `json:"design_ids,omitempty"`
+	ProfileID *uuid.UUID  `json:"profile_id,omitempty"`
+	Metric    string      `json:"metric,omitempty"`
+	Query     string      `json:"query,omitempty"`
+	Board     string      `json:"board,omitempty"`
+	Panel     *int        `json:"panel,omitempty"`
+	// Window is the duration plotted, e.g. 24h.
+	Window string `json:"window,omitempty"`
+}
+
+// DashboardWidget is a widget of a dashboard, identified by an id unique within the dashboard.
+type DashboardWidget struct {
+	ID      string                 `json:"id"`
+	Type    DashboardWidgetType    `json:"type"`
+	Title   string                 `json:"title,omitempty"`
+	Options DashboardWidgetOptions `json:"options"`
+}
+
+// DashboardLayoutItem places a widget on the grid of the dashboard, in columns and rows.
+type DashboardLayoutItem struct {
+	WidgetID string `json:"widget_id"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	W        int    `json:"w"`
+	H        int    `json:"h"`
+}
+
+// Dashboard is a dashboard composed by a user, its widgets laid out on a grid of DashboardGridColumns
+// columns. Dashboards are private to their owner unless shared with a workspace, whose members view them.
+// The home dashboard of the user is shown on the home screen.
+type Dashboard struct {
+	ID          uuid.UUID             `json:"id" gorm:"primarykey"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	UserID      uuid.UUID             `json:"user_id" gorm:"index"`
+	WorkspaceID *uuid.UUID            `json:"workspace_id,omitempty" gorm:"index"`
+	Home        bool                  `json:"home"`
+	Widgets     []DashboardWidget     `json:"widgets" gorm:"type:bytes;serializer:json"`
+	Layout      []DashboardLayoutItem `json:"layout" gorm:"type:bytes;serializer:json"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the widgets of the dashboard and their layout: widgets have unique ids and the options of
+// their type, and every widget is laid out once within the grid without overlapping another one.
+// This function always returns meshkit error
+func (d *Dashboard) Validate() error {
+	if d.Name == "" {
+		return ErrInvalidDashboard(fmt.Errorf("the dashboard has no name"))
+	}
+	widgets := map[string]bool{}
+	for _, widget := range d.Widgets {
+		if widget.ID == "" {
+			return ErrInvalidDashboard(fmt.Errorf("a widget of the dashboard has no id"))
+		}
+		if widgets[widget.ID] {
+			return ErrInvalidDashboard(fmt.Errorf("the dashboard has several widgets with id %q", widget.ID))
+		}
+		widgets[widget.ID] = true
+		if err := widget.validate(); err != nil {
+			return ErrInvalidDashboard(fmt.Errorf("widget %q: %w", widget.ID, err))
+		}
+	}
+
+	placed := map[string]bool{}
+	for i, item := range d.Layout {
+		if !widgets[item.WidgetID] {
+			return ErrInvalidDashboard(fmt.Errorf("the layout places widget %q, which the dashboard does not have", item.WidgetID))
+		}
+		if placed[item.WidgetID] {
+			return ErrInvalidDashboard(fmt.Errorf("the layout places widget %q several times", item.WidgetID))
+		}
+		placed[item.WidgetID] = true
+		if item.X < 0 || item.Y < 0 || item.W <= 0 || item.H <= 0 || item.X+item.W > DashboardGridColumns {
+			return ErrInvalidDashboard(fmt.Errorf("widget %q is placed out of the grid of %d columns", item.WidgetID, DashboardGridColumns))
+		}
+		for _, other := range d.Layout[:i] {
+			if item.X < other.X+other.W && other.X < item.X+item.W && item.Y < other.Y+other.H && other.Y < item.Y+item.H {
+				return ErrInvalidDashboard(fmt.Errorf("widgets %q and %q overlap", other.WidgetID, item.WidgetID))
+			}
+		}
+	}
+	for id := range widgets {
+		if !placed[id] {
+			return ErrInvalidDashboard(fmt.Errorf("widget %q is not placed by the layout", id))
+		}
+	}
+	return nil
+}
+
+func (w *DashboardWidget) validate() error {
+	opts := w.Options
+	if opts.Window != "" {
+		if window, err := time.ParseDuration(opts.Window); err != nil || window <= 0 {
+			return fmt.Errorf("invalid window %q, the window is a duration such as 24h", opts.Window)
+		}
+	}
+	switch w.Type {
+	case DashboardWidgetResourceCount:
+		if len(opts.Kinds) == 0 {
+			return fmt.Errorf("a resource count counts the resources of kinds, none is given")
+		}
+	case DashboardWidgetDesignHealth:
+	case DashboardWidgetPerfTrend:
+		if opts.ProfileID == nil {
+			return fmt.Errorf("a perf trend plots the results of a performance profile, profile_id is missing")
+		}
+		if !dashboardPerfMetrics[opts.Metric] {
+			return fmt.Errorf("unknown metric %q, the metric is rps, min, avg, max or a percentile among p50, p75, p90, p99 and p99.9", opts.Metric)
+		}
+	case DashboardWidgetPrometheusPanel:
+		if (opts.Query == "") == (opts.Board == "") {
+			return fmt.Errorf("a Prometheus panel plots either a query or the panel of a Grafana board")
+		}
+		if opts.Board != "" && opts.Panel == nil {
+			return fmt.Errorf("the panel of board %q is missing", opts.Board)
+		}
+	default:
+		return fmt.Errorf("unknown type %q, the type is %s, %s, %s or %s", w.Type, DashboardWidgetResourceCount, DashboardWidgetDesignHealth, DashboardWidgetPerfTrend, DashboardWidgetPrometheusPanel)
+	}
+	return nil
+}
+
+// DashboardPersister is the persister for the dashboards of users
+type DashboardPersister struct {
+	DB *database.Handler
+}
+
+// SaveDashboard creates the dashboard, or updates it if it already exists. The home dashboard of the user
+// replaces the previous one.
+func (dp *DashboardPersister) SaveDashboard(dashboard *Dashboard) error {
+	if dashboard.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		dashboard.ID = id
+	}
+	if dashboard.Home {
+		err := dp.DB.Model(&Dashboard{}).Where("user_id = ? AND id <> ? AND home = ?", dashboard.UserID, dashboard.ID, true).
+			Update("home", false).Error
+		if err != nil {
+			return err
+		}
+	}
+	return dp.DB.Save(dashboard).Error
+}
+
+// GetDashboard returns the dashboard with the given id, nil if it does not exist.
+func (dp *DashboardPersister) GetDashboard(id uuid.UUID) (*Dashboard, error) {
+	dashboards := []Dashboard{}
+	if err := dp.DB.Where("id = ?", id).Limit(1).Find(&dashboards).Error; err != nil {
+		return nil, err
+	}
+	if len(dashboards) == 0 {
+		return nil, nil
+	}
+	return &dashboards[0], nil
+}
+
+// GetDashboards returns the dashboards of the user along with the dashboards shared with the workspaces,
+// the home dashboard of the user first, then by name.
+func (dp *DashboardPersister) GetDashboards(userID uuid.UUID, workspaceIDs []uuid.UUID) ([]Dashboard, error) {
+	dashboards := []Dashboard{}
+	query := dp.DB.Where("user_id = ?", userID)
+	if len(workspaceIDs) > 0 {
+		query = dp.DB.Where("user_id = ? OR workspace_id IN ?", userID, workspaceIDs)
+	}
+	if err := query.Order("name").Find(&dashboards).Error; err != nil {
+		return nil, err
+	}
+	// the home dashboards of the users sharing their dashboards are not the home dashboard of the user
+	sort.SliceStable(dashboards, func(i, j int) bool {
+		return dashboards[i].Home && dashboards[i].UserID == userID && !(dashboards[j].Home && dashboards[j].UserID == userID)
+	})
+	return dashboards, nil
+}
+
+// DeleteDashboard removes the dashboard.
+func (dp *DashboardPersister) DeleteDashboard(id uuid.UUID) error {
+	return dp.DB.Where("id = ?", id).Delete(&Dashboard{}).Error
+}
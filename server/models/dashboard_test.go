@@ -0,0 +1,129 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	meshkiterrors "github.com/layer5io/meshkit/errors"
+)
+
+func TestDashboardValidate(t *testing.T) {
+	profileID := uuid.Must(uuid.NewV4())
+	panel := 2
+	count := DashboardWidget{ID: "pods", Type: DashboardWidgetResourceCount, Options: DashboardWidgetOptions{Kinds: []string{"Pod"}}}
+	at := func(id string, x, y, w, h int) DashboardLayoutItem {
+		return DashboardLayoutItem{WidgetID: id, X: x, Y: y, W: w, H: h}
+	}
+
+	tests := []struct {
+		name      string
+		dashboard Dashboard
+		wantErr   string
+	}{
+		{
+			name: "valid",
+			dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{
+				count,
+				{ID: "health", Type: DashboardWidgetDesignHealth},
+				{ID: "latency", Type: DashboardWidgetPerfTrend, Options: DashboardWidgetOptions{ProfileID: &profileID, Metric: "p99", Window: "24h"}},
+				{ID: "cpu", Type: DashboardWidgetPrometheusPanel, Options: DashboardWidgetOptions{Board: "node-exporter", Panel: &panel}},
+			}, Layout: []DashboardLayoutItem{at("pods", 0, 0, 6, 2), at("health", 6, 0, 6, 2), at("latency", 0, 2, 12, 4), at("cpu", 0, 6, 4, 4)}},
+		},
+		{name: "no name", dashboard: Dashboard{}, wantErr: "no name"},
+		{name: "no widget id", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{Type: DashboardWidgetDesignHealth}}}, wantErr: "no id"},
+		{name: "duplicate widget", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{count, count}}, wantErr: "several widgets"},
+		{name: "unknown type", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{ID: "logs", Type: "logs"}}}, wantErr: "unknown type"},
+		{name: "no kinds", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{ID: "pods", Type: DashboardWidgetResourceCount}}}, wantErr: "kinds"},
+		{
+			name:      "unknown metric",
+			dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{ID: "latency", Type: DashboardWidgetPerfTrend, Options: DashboardWidgetOptions{ProfileID: &profileID, Metric: "p95"}}}},
+			wantErr:   "unknown metric",
+		},
+		{
+			name:      "query and board",
+			dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{ID: "cpu", Type: DashboardWidgetPrometheusPanel, Options: DashboardWidgetOptions{Query: "up", Board: "node-exporter", Panel: &panel}}}},
+			wantErr:   "either a query or the panel",
+		},
+		{
+			name:      "invalid window",
+			dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{{ID: "cpu", Type: DashboardWidgetPrometheusPanel, Options: DashboardWidgetOptions{Query: "up", Window: "-1h"}}}},
+			wantErr:   "invalid window",
+		},
+		{name: "unknown widget placed", dashboard: Dashboard{Name: "ops", Layout: []DashboardLayoutItem{at("pods", 0, 0, 1, 1)}}, wantErr: "does not have"},
+		{name: "placed twice", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{count}, Layout: []DashboardLayoutItem{at("pods", 0, 0, 1, 1), at("pods", 2, 0, 1, 1)}}, wantErr: "several times"},
+		{name: "out of the grid", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{count}, Layout: []DashboardLayoutItem{at("pods", 8, 0, 6, 1)}}, wantErr: "out of the grid"},
+		{
+			name: "overlapping",
+			dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{count, {ID: "health", Type: DashboardWidgetDesignHealth}},
+				Layout: []DashboardLayoutItem{at("pods", 0, 0, 6, 2), at("health", 5, 1, 6, 2)}},
+			wantErr: "overlap",
+		},
+		{name: "not placed", dashboard: Dashboard{Name: "ops", Widgets: []DashboardWidget{count}}, wantErr: "not placed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dashboard.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || meshkiterrors.GetCode(err) != ErrInvalidDashboardCode || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDashboardPersister(t *testing.T) {
+	dp := &DashboardPersister{DB: newTestDB(t, &Dashboard{})}
+	user := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	workspace := uuid.Must(uuid.NewV4())
+
+	save := func(name string, owner uuid.UUID, home bool, workspaceID *uuid.UUID) *Dashboard {
+		t.Helper()
+		dashboard := &Dashboard{Name: name, UserID: owner, Home: home, WorkspaceID: workspaceID}
+		if err := dp.SaveDashboard(dashboard); err != nil {
+			t.Fatal(err)
+		}
+		return dashboard
+	}
+	first := save("a first home", user, true, nil)
+	second := save("z second home", user, true, nil)
+	shared := save("b shared", other, true, &workspace)
+	save("c private", other, false, nil)
+
+	got, err := dp.GetDashboard(first.ID)
+	if err != nil || got == nil || got.Home {
+		t.Fatalf("expected the new home dashboard to replace the previous one, got %+v: %v", got, err)
+	}
+	if got, err := dp.GetDashboard(shared.ID); err != nil || got == nil || !got.Home {
+		t.Errorf("expected the home dashboard of the other user to be kept, got %+v: %v", got, err)
+	}
+
+	dashboards, err := dp.GetDashboards(user, []uuid.UUID{workspace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, dashboard := range dashboards {
+		names = append(names, dashboard.Name)
+	}
+	// the home dashboard of the user first, the shared home dashboard of the other user among the others
+	if strings.Join(names, ", ") != "z second home, a first home, b shared" {
+		t.Errorf("dashboards = %v", names)
+	}
+	if dashboards, err := dp.GetDashboards(user, nil); err != nil || len(dashboards) != 2 {
+		t.Errorf("expected only the dashboards of the user without workspaces, got %d: %v", len(dashboards), err)
+	}
+
+	if err := dp.DeleteDashboard(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dp.GetDashboard(second.ID); err != nil || got != nil {
+		t.Errorf("expected the dashboard to be deleted, got %+v: %v", got, err)
+	}
+}
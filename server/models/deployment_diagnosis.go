@@ -0,0 +1,567 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DiagnosisLogTailLines is the number of lines of the logs of a failing container gathered for a diagnosis.
+const DiagnosisLogTailLines = 50
+
+// DiagnosisSource is the evidence a failure signature is matched against.
+type DiagnosisSource string
+
+const (
+	// DiagnosisSourceError is the error returned when the design was deployed.
+	DiagnosisSourceError DiagnosisSource = "error"
+	// DiagnosisSourceEvent is the warning events of a resource of the design.
+	DiagnosisSourceEvent DiagnosisSource = "event"
+	// DiagnosisSourceContainer is the reason a container is waiting or was terminated.
+	DiagnosisSourceContainer DiagnosisSource = "container"
+	// DiagnosisSourceLogs is the tail of the logs of a container.
+	DiagnosisSourceLogs DiagnosisSource = "logs"
+)
+
+// FailureSignature is a common cause of deployment failures, recognized by the reason or the message
+// of the evidence gathered from the cluster.
+type FailureSignature struct {
+	Name  string
+	Cause string
+	Fixes []string
+	// Group, when set, reports only the first matching signature of the group for a resource or a container,
+	// so that a specific signature hides the generic one.
+	Group  string
+	Source DiagnosisSource
+	// Reasons match the reason of the event or of the state of the container, any reason when empty.
+	Reasons []string
+	// Pattern matches the message of the event or of the state of the container, a line of the logs or the
+	// deployment error. Any message when nil, except for logs and errors which require one.
+	Pattern *regexp.Regexp
+}
+
+// DiagnosisEvent is a warning event of a resource.
+type DiagnosisEvent struct {
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// DiagnosisContainer is the state of a container of a pod of a workload which is not running as expected.
+type DiagnosisContainer struct {
+	Pod          string `json:"pod"`
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Reason       string `json:"reason"`
+	Message      string `json:"message,omitempty"`
+	ExitCode     int32  `json:"exit_code,omitempty"`
+	RestartCount int32  `json:"restart_count"`
+	// Logs is the tail of the logs of the container, of its previous run when it restarted.
+	Logs string `json:"logs,omitempty"`
+}
+
+// DiagnosedResource is the evidence gathered for a component of a design from a cluster.
+type DiagnosedResource struct {
+	ComponentID uuid.UUID            `json:"component_id"`
+	Component   string               `json:"component"`
+	Cluster     string               `json:"cluster"`
+	Kind        string               `json:"kind"`
+	Namespace   string               `json:"namespace"`
+	Name        string               `json:"name"`
+	Events      []DiagnosisEvent     `json:"events"`
+	Containers  []DiagnosisContainer `json:"containers"`
+}
+
+// DiagnosisFinding is a failure signature recognized in the evidence, along with the fixes to try.
+type DiagnosisFinding struct {
+	Signature string          `json:"signature"`
+	Source    DiagnosisSource `json:"source"`
+	Component string          `json:"component,omitempty"`
+	Cluster   string          `json:"cluster,omitempty"`
+	Kind      string          `json:"kind,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Container string          `json:"container,omitempty"`
+	Cause     string          `json:"cause"`
+	Evidence  string          `json:"evidence"`
+	Fixes     []string        `json:"fixes"`
+}
+
+// DeploymentDiagnosis is the diagnosis of a failed deployment of a design.
+type DeploymentDiagnosis struct {
+	Summary   string              `json:"summary"`
+	Findings  []DiagnosisFinding  `json:"findings"`
+	Resources []DiagnosedResource `json:"resources"`
+	// Errors are the evidence which could not be gathered, eg. from a disconnected cluster.
+	Errors      []string  `json:"errors,omitempty"`
+	DiagnosedAt time.Time `json:"diagnosed_at"`
+}
+
+// DiagnosisCluster is a cluster the design was deployed to.
+type DiagnosisCluster struct {
+	Name   string
+	Client kubernetes.Interface
+}
+
+var failureSignatures = []FailureSignature{
+	{
+		Name:    "image-not-found",
+		Group:   "image-pull",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"Failed"},
+		Pattern: regexp.MustCompile(`(?i)failed to pull image.*(not found|manifest unknown|no such host)`),
+		Cause:   "The image, or its tag, does not exist in the registry.",
+		Fixes:   []string{"Correct the name and the tag of the image of the container", "Push the image to the registry before deploying the design"},
+	},
+	{
+		Name:    "image-pull-unauthorized",
+		Group:   "image-pull",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"Failed"},
+		Pattern: regexp.MustCompile(`(?i)failed to pull image.*(unauthorized|authentication required|pull access denied|denied)`),
+		Cause:   "The registry refused to serve the image without valid credentials.",
+		Fixes:   []string{"Create a docker-registry secret with credentials for the registry and reference it in imagePullSecrets", "Ensure the service account of the pod has access to the registry"},
+	},
+	{
+		Name:    "image-pull-failed",
+		Group:   "image-pull",
+		Source:  DiagnosisSourceContainer,
+		Reasons: []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName"},
+		Cause:   "The image of the container could not be pulled.",
+		Fixes:   []string{"Ensure the image reference is valid and the registry is reachable from the nodes", "Review the events of the pod for the error returned by the registry"},
+	},
+	{
+		Name:    "missing-configuration",
+		Source:  DiagnosisSourceContainer,
+		Reasons: []string{"CreateContainerConfigError"},
+		Cause:   "A ConfigMap or a Secret referenced by the container does not exist, or lacks a referenced key.",
+		Fixes:   []string{"Add the ConfigMap or the Secret to the design, in the namespace of the workload", "Correct the names and the keys referenced by env, envFrom and volumes"},
+	},
+	{
+		Name:    "out-of-memory",
+		Source:  DiagnosisSourceContainer,
+		Reasons: []string{"OOMKilled"},
+		Cause:   "The container used more memory than its limit and was killed.",
+		Fixes:   []string{"Raise the memory limit of the container", "Right-size the resources of the design from the usage of the workload"},
+	},
+	{
+		Name:    "port-in-use",
+		Group:   "crash",
+		Source:  DiagnosisSourceLogs,
+		Pattern: regexp.MustCompile(`(?i)address already in use`),
+		Cause:   "The application exits because the port it listens on is already bound.",
+		Fixes:   []string{"Ensure the containers of the pod listen on distinct ports", "Avoid hostNetwork or hostPort when another pod of the node binds the port"},
+	},
+	{
+		Name:    "permission-denied",
+		Group:   "crash",
+		Source:  DiagnosisSourceLogs,
+		Pattern: regexp.MustCompile(`(?i)(permission denied|operation not permitted|read-only file system)`),
+		Cause:   "The application exits because the security context does not allow it to access a file or perform an operation.",
+		Fixes:   []string{"Run the container as the user the image expects, or make its files writable by that user with fsGroup", "Mount a writable emptyDir volume where the application writes when the root filesystem is read only"},
+	},
+	{
+		Name:    "dependency-unreachable",
+		Group:   "crash",
+		Source:  DiagnosisSourceLogs,
+		Pattern: regexp.MustCompile(`(?i)(connection refused|no such host|i/o timeout|could not connect|connection timed out)`),
+		Cause:   "The application exits because a service it depends on is not reachable.",
+		Fixes:   []string{"Ensure the services the application connects to are part of the design, or deployed, and ready", "Declare the dependency of the component on the service so that it is deployed first"},
+	},
+	{
+		Name:    "crash-loop",
+		Group:   "crash",
+		Source:  DiagnosisSourceContainer,
+		Reasons: []string{"CrashLoopBackOff", "Error"},
+		Cause:   "The container exits repeatedly after it starts.",
+		Fixes:   []string{"Review the logs of the previous run of the container", "Verify the command, the arguments and the environment of the container"},
+	},
+	{
+		Name:    "insufficient-resources",
+		Group:   "scheduling",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedScheduling"},
+		Pattern: regexp.MustCompile(`(?i)insufficient (cpu|memory|ephemeral-storage|pods)`),
+		Cause:   "No node has enough allocatable resources left for the requests of the pod.",
+		Fixes:   []string{"Lower the requests of the containers, or the replicas of the workload", "Add nodes to the cluster, or enable its autoscaler"},
+	},
+	{
+		Name:    "untolerated-taint",
+		Group:   "scheduling",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedScheduling"},
+		Pattern: regexp.MustCompile(`(?i)untolerated taint|had taint`),
+		Cause:   "The nodes which could run the pod have taints the pod does not tolerate.",
+		Fixes:   []string{"Add tolerations for the taints of the nodes to the workload", "Check whether the design can be scheduled before deploying it"},
+	},
+	{
+		Name:    "unschedulable",
+		Group:   "scheduling",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedScheduling"},
+		Cause:   "No node of the cluster satisfies the scheduling constraints of the pod.",
+		Fixes:   []string{"Relax the nodeSelector and the affinity of the workload", "Check whether the design can be scheduled before deploying it"},
+	},
+	{
+		Name:    "volume-mount-failed",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedMount", "FailedAttachVolume", "ProvisioningFailed"},
+		Cause:   "A volume of the pod could not be provisioned, attached or mounted.",
+		Fixes:   []string{"Ensure the PersistentVolumeClaims, ConfigMaps and Secrets mounted by the pod exist", "Ensure the storage class of the claims exists and can provision volumes"},
+	},
+	{
+		Name:    "probe-failed",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"Unhealthy"},
+		Cause:   "The liveness or readiness probe of a container fails, so it is restarted or receives no traffic.",
+		Fixes:   []string{"Verify the path and the port of the probe match the application", "Raise the initialDelaySeconds of the probe when the application starts slowly"},
+	},
+	{
+		Name:    "quota-exceeded",
+		Group:   "admission",
+		Pattern: regexp.MustCompile(`(?i)exceeded quota`),
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedCreate"},
+		Cause:   "The pods would exceed a resource quota of the namespace.",
+		Fixes:   []string{"Lower the requests or the replicas of the workloads of the namespace", "Raise the resource quota of the namespace"},
+	},
+	{
+		Name:    "quota-exceeded",
+		Group:   "admission",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)exceeded quota`),
+		Cause:   "A resource of the design would exceed a resource quota of its namespace.",
+		Fixes:   []string{"Lower the requests or the replicas of the workloads of the namespace", "Raise the resource quota of the namespace"},
+	},
+	{
+		Name:    "pod-security-violation",
+		Group:   "admission",
+		Source:  DiagnosisSourceEvent,
+		Reasons: []string{"FailedCreate"},
+		Pattern: regexp.MustCompile(`(?i)violates podsecurity`),
+		Cause:   "The pods violate the Pod Security Standard enforced on the namespace.",
+		Fixes:   []string{"Check the design against the Pod Security Standards and apply the remediations"},
+	},
+	{
+		Name:    "pod-security-violation",
+		Group:   "admission",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)violates podsecurity`),
+		Cause:   "A workload of the design violates the Pod Security Standard enforced on its namespace.",
+		Fixes:   []string{"Check the design against the Pod Security Standards and apply the remediations"},
+	},
+	{
+		Name:    "admission-denied",
+		Group:   "admission",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)admission webhook .* denied`),
+		Cause:   "An admission webhook of the cluster rejected a resource of the design.",
+		Fixes:   []string{"Change the resource to satisfy the policy enforced by the webhook, as explained by its message"},
+	},
+	{
+		Name:    "forbidden",
+		Group:   "admission",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)forbidden`),
+		Cause:   "The credentials of the Kubernetes connection are not allowed to create a resource of the design.",
+		Fixes:   []string{"Grant the user or the service account of the connection the permissions to manage the resources of the design"},
+	},
+	{
+		Name:    "missing-namespace",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)namespaces? "[^"]+" not found`),
+		Cause:   "A resource of the design is in a namespace which does not exist.",
+		Fixes:   []string{"Add the Namespace to the design", "Correct the namespace of the component"},
+	},
+	{
+		Name:    "missing-crd",
+		Source:  DiagnosisSourceError,
+		Pattern: regexp.MustCompile(`(?i)no matches for kind|could not find the requested resource`),
+		Cause:   "The cluster does not serve the kind, or the version, of a resource of the design.",
+		Fixes:   []string{"Install the operator or the CRDs of the component before deploying the design", "Use an API version served by the version of the cluster"},
+	},
+}
+
+// RegisterFailureSignature adds a failure signature to the diagnosis engine, checked after the signatures
+// of its group registered before it.
+func RegisterFailureSignature(signature FailureSignature) {
+	failureSignatures = append(failureSignatures, signature)
+}
+
+// FailureSignatures returns the failure signatures of the diagnosis engine, in the order they are checked.
+func FailureSignatures() []FailureSignature {
+	return slices.Clone(failureSignatures)
+}
+
+// DiagnoseDeployment gathers the warning events, the state and the logs of the failing containers of the
+// workloads of the design from the clusters, and matches them, along with the deployment error, against
+// the failure signatures. Evidence which cannot be gathered is reported in the errors of the diagnosis.
+func DiagnoseDeployment(ctx context.Context, patternFile *pattern.PatternFile, clusters []DiagnosisCluster, deployErr error) *DeploymentDiagnosis {
+	diagnosis := &DeploymentDiagnosis{Findings: []DiagnosisFinding{}, Resources: []DiagnosedResource{}, DiagnosedAt: time.Now()}
+	for _, cluster := range clusters {
+		resources, err := gatherDiagnosisEvidence(ctx, patternFile, cluster)
+		if err != nil {
+			diagnosis.Errors = append(diagnosis.Errors, fmt.Sprintf("cluster %s: %v", cluster.Name, err))
+		}
+		diagnosis.Resources = append(diagnosis.Resources, resources...)
+	}
+	diagnosis.Findings = MatchFailureSignatures(diagnosis.Resources, deployErr)
+
+	switch len(diagnosis.Findings) {
+	case 0:
+		diagnosis.Summary = "No known failure signature was recognized, review the events and the logs of the resources."
+	case 1:
+		diagnosis.Summary = diagnosis.Findings[0].Cause
+	default:
+		diagnosis.Summary = fmt.Sprintf("%s (and %d more findings)", diagnosis.Findings[0].Cause, len(diagnosis.Findings)-1)
+	}
+	return diagnosis
+}
+
+// MatchFailureSignatures matches the evidence against the failure signatures. Findings from the deployment
+// error come first, followed by the findings of the resources in order.
+func MatchFailureSignatures(resources []DiagnosedResource, deployErr error) []DiagnosisFinding {
+	findings := []DiagnosisFinding{}
+	if deployErr != nil {
+		matched := map[string]bool{}
+		for _, sig := range failureSignatures {
+			if sig.Source != DiagnosisSourceError || matched[sig.Group] && sig.Group != "" || sig.Pattern == nil {
+				continue
+			}
+			if evidence := sig.Pattern.FindString(deployErr.Error()); evidence != "" {
+				matched[sig.Group] = true
+				findings = append(findings, sig.finding(DiagnosisFinding{Source: DiagnosisSourceError, Evidence: deployErr.Error()}))
+			}
+		}
+	}
+
+	for _, res := range resources {
+		base := DiagnosisFinding{Component: res.Component, Cluster: res.Cluster, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}
+		// a group is matched once per resource for events, and once per container otherwise
+		matched := map[string]bool{}
+		for _, sig := range failureSignatures {
+			if sig.Source != DiagnosisSourceEvent || sig.Group != "" && matched[sig.Group] {
+				continue
+			}
+			for _, event := range res.Events {
+				if sig.matches(event.Reason, event.Message) {
+					matched[sig.Group] = true
+					finding := base
+					finding.Source = DiagnosisSourceEvent
+					finding.Evidence = fmt.Sprintf("%s %s: %s: %s", event.Kind, event.Name, event.Reason, event.Message)
+					findings = append(findings, sig.finding(finding))
+					break
+				}
+			}
+		}
+
+		for _, container := range res.Containers {
+			containerMatched := map[string]bool{}
+			for group := range matched {
+				containerMatched[group] = true
+			}
+			for _, sig := range failureSignatures {
+				if sig.Group != "" && containerMatched[sig.Group] {
+					continue
+				}
+				finding := base
+				finding.Container = container.Name
+				finding.Source = sig.Source
+				switch sig.Source {
+				case DiagnosisSourceContainer:
+					if !sig.matches(container.Reason, container.Message) {
+						continue
+					}
+					finding.Evidence = fmt.Sprintf("pod %s: container %s: %s", container.Pod, container.Name, container.Reason)
+					if container.Message != "" {
+						finding.Evidence += ": " + container.Message
+					}
+				case DiagnosisSourceLogs:
+					line := sig.logLine(container.Logs)
+					if line == "" {
+						continue
+					}
+					finding.Evidence = fmt.Sprintf("pod %s: container %s: %s", container.Pod, container.Name, line)
+				default:
+					continue
+				}
+				containerMatched[sig.Group] = true
+				findings = append(findings, sig.finding(finding))
+			}
+		}
+	}
+	return findings
+}
+
+func (sig *FailureSignature) matches(reason, message string) bool {
+	if len(sig.Reasons) > 0 && !slices.Contains(sig.Reasons, reason) {
+		return false
+	}
+	return sig.Pattern == nil || sig.Pattern.MatchString(message)
+}
+
+// logLine returns the last line of the logs matching the pattern of the signature.
+func (sig *FailureSignature) logLine(logs string) string {
+	if sig.Pattern == nil {
+		return ""
+	}
+	lines := strings.Split(logs, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if sig.Pattern.MatchString(lines[i]) {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return ""
+}
+
+func (sig *FailureSignature) finding(finding DiagnosisFinding) DiagnosisFinding {
+	finding.Signature = sig.Name
+	finding.Cause = sig.Cause
+	finding.Fixes = slices.Clone(sig.Fixes)
+	return finding
+}
+
+// gatherDiagnosisEvidence collects the evidence of the components of the design deployed to the cluster.
+func gatherDiagnosisEvidence(ctx context.Context, patternFile *pattern.PatternFile, cluster DiagnosisCluster) ([]DiagnosedResource, error) {
+	resources := []DiagnosedResource{}
+	events := map[string][]corev1.Event{}
+	pods := map[string][]corev1.Pod{}
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		res := DiagnosedResource{
+			ComponentID: comp.Id,
+			Component:   comp.DisplayName,
+			Cluster:     cluster.Name,
+			Kind:        comp.Component.Kind,
+			Namespace:   "default",
+			Events:      []DiagnosisEvent{},
+			Containers:  []DiagnosisContainer{},
+		}
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		if ns, _ := metadata["namespace"].(string); ns != "" {
+			res.Namespace = ns
+		}
+		if res.Name, _ = metadata["name"].(string); res.Name == "" {
+			res.Name = comp.DisplayName
+		}
+		workload := isDiagnosedWorkload(res.Kind)
+
+		if _, ok := events[res.Namespace]; !ok {
+			list, err := cluster.Client.CoreV1().Events(res.Namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+			if err != nil {
+				return resources, err
+			}
+			sort.SliceStable(list.Items, func(i, j int) bool {
+				return eventTime(&list.Items[i]).Before(eventTime(&list.Items[j]))
+			})
+			events[res.Namespace] = list.Items
+		}
+		if _, ok := pods[res.Namespace]; !ok && workload {
+			list, err := cluster.Client.CoreV1().Pods(res.Namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return resources, err
+			}
+			pods[res.Namespace] = list.Items
+		}
+
+		podName := regexp.MustCompile("^" + workloadPodNamePattern(res.Kind, res.Name) + "$")
+		replicaSetName := regexp.MustCompile("^" + workloadPodNamePattern("ReplicaSet", res.Name) + "$")
+		for _, event := range events[res.Namespace] {
+			object := event.InvolvedObject
+			owned := object.Kind == res.Kind && object.Name == res.Name
+			if workload {
+				owned = owned || object.Kind == "Pod" && podName.MatchString(object.Name) ||
+					res.Kind == "Deployment" && object.Kind == "ReplicaSet" && replicaSetName.MatchString(object.Name)
+			}
+			if owned {
+				res.Events = append(res.Events, DiagnosisEvent{Kind: object.Kind, Name: object.Name, Reason: event.Reason, Message: event.Message, Count: event.Count, LastSeen: eventTime(&event)})
+			}
+		}
+
+		if workload {
+			for _, pod := range pods[res.Namespace] {
+				if !podName.MatchString(pod.Name) {
+					continue
+				}
+				for _, status := range pod.Status.ContainerStatuses {
+					container, failing := failingContainer(&pod, status)
+					if !failing {
+						continue
+					}
+					previous := status.RestartCount > 0
+					tail := int64(DiagnosisLogTailLines)
+					logs, err := cluster.Client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: status.Name, TailLines: &tail, Previous: previous}).DoRaw(ctx)
+					if err == nil {
+						container.Logs = string(logs)
+					}
+					res.Containers = append(res.Containers, container)
+				}
+			}
+		}
+
+		if len(res.Events) > 0 || len(res.Containers) > 0 {
+			resources = append(resources, res)
+		}
+	}
+	return resources, nil
+}
+
+// failingContainer returns the state of the container of the pod when it is waiting for any reason but
+// starting, or when it was terminated with an error, now or on its previous run.
+func failingContainer(pod *corev1.Pod, status corev1.ContainerStatus) (DiagnosisContainer, bool) {
+	container := DiagnosisContainer{Pod: pod.Name, Name: status.Name, Image: status.Image, RestartCount: status.RestartCount}
+	var terminated *corev1.ContainerStateTerminated
+	switch {
+	case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+		terminated = status.State.Terminated
+	case status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0:
+		terminated = status.LastTerminationState.Terminated
+	}
+
+	if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "ContainerCreating" && waiting.Reason != "PodInitializing" {
+		container.Reason = waiting.Reason
+		container.Message = waiting.Message
+		// OOMKilled explains a crash loop better than the back off does
+		if terminated != nil && terminated.Reason == "OOMKilled" {
+			container.Reason = terminated.Reason
+		}
+	} else if terminated != nil {
+		container.Reason = terminated.Reason
+		container.Message = terminated.Message
+	} else {
+		return container, false
+	}
+	if terminated != nil {
+		container.ExitCode = terminated.ExitCode
+	}
+	return container, true
+}
+
+func isDiagnosedWorkload(kind string) bool {
+	return isSimulatedWorkload(kind) || kind == "Job"
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func findingSignatures(findings []DiagnosisFinding) []string {
+	signatures := []string{}
+	for _, finding := range findings {
+		signatures = append(signatures, finding.Signature)
+	}
+	return signatures
+}
+
+func TestMatchFailureSignatures(t *testing.T) {
+	event := func(reason, message string) []DiagnosisEvent {
+		return []DiagnosisEvent{{Kind: "Pod", Name: "web-5d9c7b-x2k4p", Reason: reason, Message: message}}
+	}
+	container := func(reason, logs string) []DiagnosisContainer {
+		return []DiagnosisContainer{{Pod: "web-5d9c7b-x2k4p", Name: "main", Reason: reason, Logs: logs}}
+	}
+
+	tests := []struct {
+		name      string
+		resource  DiagnosedResource
+		deployErr error
+		want      []string
+	}{
+		{
+			name:      "deployment error",
+			deployErr: errors.New(`admission webhook "quota.example.com" denied the request: exceeded quota: compute`),
+			// a single finding of the admission group
+			want: []string{"quota-exceeded"},
+		},
+		{
+			name:      "missing namespace",
+			deployErr: errors.New(`namespaces "shop" not found`),
+			want:      []string{"missing-namespace"},
+		},
+		{
+			name:     "insufficient resources",
+			resource: DiagnosedResource{Events: event("FailedScheduling", "0/3 nodes are available: 3 Insufficient cpu.")},
+			want:     []string{"insufficient-resources"},
+		},
+		{
+			name:     "unschedulable",
+			resource: DiagnosedResource{Events: event("FailedScheduling", "0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector.")},
+			want:     []string{"unschedulable"},
+		},
+		{
+			name: "image not found",
+			resource: DiagnosedResource{
+				Events:     event("Failed", `Failed to pull image "web:v3": manifest unknown`),
+				Containers: container("ImagePullBackOff", ""),
+			},
+			// the event explains the image pull of the container
+			want: []string{"image-not-found"},
+		},
+		{
+			name:     "image pull failed",
+			resource: DiagnosedResource{Containers: container("ErrImagePull", "")},
+			want:     []string{"image-pull-failed"},
+		},
+		{
+			name:     "crash explained by the logs",
+			resource: DiagnosedResource{Containers: container("CrashLoopBackOff", "starting\nlisten tcp :8080: bind: address already in use\n")},
+			want:     []string{"port-in-use"},
+		},
+		{
+			name:     "crash loop",
+			resource: DiagnosedResource{Containers: container("CrashLoopBackOff", "panic: nil map\n")},
+			want:     []string{"crash-loop"},
+		},
+		{
+			name:     "out of memory",
+			resource: DiagnosedResource{Containers: container("OOMKilled", "")},
+			want:     []string{"out-of-memory"},
+		},
+		{
+			name:     "unknown",
+			resource: DiagnosedResource{Events: event("BackOff", "Back-off restarting failed container")},
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := []DiagnosedResource{}
+			if len(tt.resource.Events)+len(tt.resource.Containers) > 0 {
+				tt.resource.Component = "web"
+				resources = append(resources, tt.resource)
+			}
+			findings := MatchFailureSignatures(resources, tt.deployErr)
+			if got := findingSignatures(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("signatures = %v, want %v", got, tt.want)
+			}
+			for _, finding := range findings {
+				if finding.Cause == "" || len(finding.Fixes) == 0 || finding.Evidence == "" {
+					t.Errorf("expected the finding to explain the failure, got %+v", finding)
+				}
+				if tt.deployErr == nil && finding.Component != "web" {
+					t.Errorf("expected the finding to name the component, got %+v", finding)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterFailureSignature(t *testing.T) {
+	saved := failureSignatures
+	defer func() { failureSignatures = saved }()
+
+	RegisterFailureSignature(FailureSignature{Name: "license-expired", Group: "license", Source: DiagnosisSourceLogs, Pattern: regexp.MustCompile(`license expired`), Cause: "The license of the application expired."})
+	if signatures := FailureSignatures(); signatures[len(signatures)-1].Name != "license-expired" {
+		t.Fatalf("expected the signature to be registered last, got %s", signatures[len(signatures)-1].Name)
+	}
+
+	resources := []DiagnosedResource{{Containers: []DiagnosisContainer{{Name: "main", Reason: "CrashLoopBackOff", Logs: "error: license expired"}}}}
+	// signatures of other groups are matched along with it
+	if got := findingSignatures(MatchFailureSignatures(resources, nil)); !reflect.DeepEqual(got, []string{"crash-loop", "license-expired"}) {
+		t.Errorf("expected the registered signature to be matched, got %v", got)
+	}
+}
+
+func TestFailingContainer(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      corev1.ContainerStatus
+		wantFailing bool
+		wantReason  string
+		wantExit    int32
+	}{
+		{
+			name:   "starting",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+		{
+			name:   "completed",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+		},
+		{
+			name:        "missing configuration",
+			status:      corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}}},
+			wantFailing: true,
+			wantReason:  "CreateContainerConfigError",
+		},
+		{
+			name: "killed for lack of memory",
+			status: corev1.ContainerStatus{
+				RestartCount:         3,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			},
+			wantFailing: true,
+			wantReason:  "OOMKilled",
+			wantExit:    137,
+		},
+		{
+			name:        "exited with an error",
+			status:      corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}}},
+			wantFailing: true,
+			wantReason:  "Error",
+			wantExit:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, failing := failingContainer(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0"}}, tt.status)
+			if failing != tt.wantFailing || container.Reason != tt.wantReason || container.ExitCode != tt.wantExit {
+				t.Errorf("failingContainer() = %+v, %t, want %q exiting %d, %t", container, failing, tt.wantReason, tt.wantExit, tt.wantFailing)
+			}
+		})
+	}
+}
+
+func TestDiagnoseDeployment(t *testing.T) {
+	design := &pattern.PatternFile{Name: "shop", Components: []*component.ComponentDefinition{
+		{DisplayName: "web", Component: component.Component{Kind: "Deployment"}, Configuration: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "shop"},
+		}},
+		{DisplayName: "config", Component: component.Component{Kind: "ConfigMap"}, Configuration: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "shop"},
+		}},
+	}}
+	warning := func(name, kind, object, reason, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        message,
+		}
+	}
+	client := fake.NewSimpleClientset(
+		warning("scaled", "ReplicaSet", "web-5d9c7b", "FailedCreate", `pods "web-5d9c7b-x2k4p" is forbidden: exceeded quota: compute`),
+		// another workload of the namespace
+		warning("api", "Pod", "api-7f8d9c-q1w2e", "FailedScheduling", "0/3 nodes are available: 3 Insufficient cpu."),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5d9c7b-x2k4p", Namespace: "shop"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", Image: "web:v3", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: `configmap "web" not found`}}},
+				{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}},
+		},
+	)
+	disconnected := fake.NewSimpleClientset()
+	disconnected.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	diagnosis := DiagnoseDeployment(context.Background(), design, []DiagnosisCluster{{Name: "kind", Client: client}, {Name: "gke", Client: disconnected}}, nil)
+
+	if len(diagnosis.Resources) != 1 {
+		t.Fatalf("expected the evidence of web only, got %+v", diagnosis.Resources)
+	}
+	web := diagnosis.Resources[0]
+	if web.Component != "web" || web.Cluster != "kind" || len(web.Events) != 1 || web.Events[0].Name != "web-5d9c7b" {
+		t.Errorf("expected the events of the replica set of web, got %+v", web)
+	}
+	if len(web.Containers) != 1 || web.Containers[0].Name != "main" || web.Containers[0].Image != "web:v3" {
+		t.Errorf("expected the failing container of web, got %+v", web.Containers)
+	}
+	if got := findingSignatures(diagnosis.Findings); !reflect.DeepEqual(got, []string{"quota-exceeded", "missing-configuration"}) {
+		t.Errorf("signatures = %v", got)
+	}
+	if !strings.HasSuffix(diagnosis.Summary, "(and 1 more findings)") {
+		t.Errorf("summary = %q", diagnosis.Summary)
+	}
+	if len(diagnosis.Errors) != 1 || !strings.HasPrefix(diagnosis.Errors[0], "cluster gke: ") {
+		t.Errorf("expected the disconnected cluster to be reported, got %v", diagnosis.Errors)
+	}
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+const (
+	// DesignCommentMaxLength bounds the length of comments, in characters.
+	DesignCommentMaxLength = 10000
+	// designReactionMaxLength bounds the length of reactions, eg. an emoji or a :shortcode:.
+	designReactionMaxLength = 32
+)
+
+// DesignComment is a comment on a design, or a reply to a comment of the design when ParentID is set.
+// The users mentioned by the comment, eg. @jane, are notified.
+type DesignComment struct {
+	ID       uuid.UUID       `json:"id" gorm:"primarykey"`
+	DesignID uuid.UUID       `json:"design_id" gorm:"index"`
+	ParentID *uuid.UUID      `json:"parent_id,omitempty" gorm:"index"`
+	UserID   uuid.UUID       `json:"user_id"`
+	UserName string          `json:"user_name"`
+	Body     string          `json:"body"`
+	Mentions []DesignMention `json:"mentions,omitempty" gorm:"type:bytes;serializer:json"`
+	Edited   bool            `json:"edited"`
+	// Reactions counts the users having reacted to the comment, by reaction.
+	Reactions map[string]int `json:"reactions,omitempty" gorm:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DesignMention is a user mentioned by a comment, by handle. UserID is nil when the handle matches no user.
+type DesignMention struct {
+	Handle string     `json:"handle"`
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// DesignCommentReaction is the reaction of a user to a comment. A user reacts once with a reaction.
+type DesignCommentReaction struct {
+	ID        uuid.UUID `json:"id" gorm:"primarykey"`
+	CommentID uuid.UUID `json:"comment_id" gorm:"uniqueIndex:idx_design_comment_reaction"`
+	UserID    uuid.UUID `json:"user_id" gorm:"uniqueIndex:idx_design_comment_reaction"`
+	Reaction  string    `json:"reaction" gorm:"uniqueIndex:idx_design_comment_reaction"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// mentionPattern matches the handles mentioned, @ followed by a user name or an email address, but not
+// the @ of an email address itself.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([\w][\w.+-]*(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+// ParseDesignMentions returns the handles mentioned by the body of a comment, once each, in order.
+func ParseDesignMentions(body string) []string {
+	handles := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// trailing punctuation ends the sentence, not the handle, eg. "thanks @jane."
+		handle := strings.TrimRight(match[1], ".-+")
+		if handle == "" || seen[strings.ToLower(handle)] {
+			continue
+		}
+		seen[strings.ToLower(handle)] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// ValidateDesignComment checks that the body of a comment is neither empty nor too long.
+func ValidateDesignComment(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("the comment is empty")
+	}
+	if utf8.RuneCountInString(body) > DesignCommentMaxLength {
+		return fmt.Errorf("the comment is longer than %d characters", DesignCommentMaxLength)
+	}
+	return nil
+}
+
+// ValidateDesignReaction checks that a reaction is a short word, eg. an emoji or a :shortcode:.
+func ValidateDesignReaction(reaction string) error {
+	if reaction == "" || utf8.RuneCountInString(reaction) > designReactionMaxLength || strings.IndexFunc(reaction, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid reaction %q, a reaction is an emoji or a :shortcode:", reaction)
+	}
+	return nil
+}
+
+// DesignActivityType is the type of an entry of the activity feed of a design.
+type DesignActivityType string
+
+const (
+	DesignActivityComment    DesignActivityType = "comment"
+	DesignActivityRevision   DesignActivityType = "revision"
+	DesignActivityDeployment DesignActivityType = "deployment"
+)
+
+// designActivityActions are the actions of the events of designs in the activity feed, by type.
+var designActivityActions = map[string]DesignActivityType{
+	"create":   DesignActivityRevision,
+	"update":   DesignActivityRevision,
+	"clone":    DesignActivityRevision,
+	"deploy":   DesignActivityDeployment,
+	"undeploy": DesignActivityDeployment,
+}
+
+// DesignActivity is an entry of the activity feed of a design: a comment, a revision of the design or a
+// deployment, along with the event recording the revision or the deployment.
+type DesignActivity struct {
+	Type        DesignActivityType   `json:"type"`
+	Action      string               `json:"action"`
+	At          time.Time            `json:"at"`
+	UserID      *uuid.UUID           `json:"user_id,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Severity    events.EventSeverity `json:"severity,omitempty"`
+	Comment     *DesignComment       `json:"comment,omitempty"`
+	EventID     *uuid.UUID           `json:"event_id,omitempty"`
+}
+
+// DesignCommentPersister is the persister for the comments of designs and their reactions
+type DesignCommentPersister struct {
+	DB *database.Handler
+}
+
+// SaveComment creates the comment, or updates it if it already exists.
+func (dp *DesignCommentPersister) SaveComment(comment *DesignComment) error {
+	if comment.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		comment.ID = id
+	}
+	return dp.DB.Save(comment).Error
+}
+
+// GetComment returns the comment of the design with the given id, nil if it does not exist.
+func (dp *DesignCommentPersister) GetComment(designID, id uuid.UUID) (*DesignComment, error) {
+	comments := []DesignComment{}
+	if err := dp.DB.Where("id = ? AND design_id = ?", id, designID).Limit(1).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	if err := dp.countReactions(comments); err != nil {
+		return nil, err
+	}
+	return &comments[0], nil
+}
+
+// GetComments returns the comments of the design along with their replies, oldest first.
+func (dp *DesignCommentPersister) GetComments(designID uuid.UUID) ([]DesignComment, error) {
+	comments := []DesignComment{}
+	if err := dp.DB.Where("design_id = ?", designID).Order("created_at").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	if err := dp.countReactions(comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (dp *DesignCommentPersister) countReactions(comments []DesignComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	reactions := []DesignCommentReaction{}
+	if err := dp.DB.Where("comment_id IN ?", ids).Find(&reactions).Error; err != nil {
+		return err
+	}
+	byComment := map[uuid.UUID]map[string]int{}
+	for _, reaction := range reactions {
+		if byComment[reaction.CommentID] == nil {
+			byComment[reaction.CommentID] = map[string]int{}
+		}
+		byComment[reaction.CommentID][reaction.Reaction]++
+	}
+	for i := range comments {
+		comments[i].Reactions = byComment[comments[i].ID]
+	}
+	return nil
+}
+
+// DeleteComment removes the comment along with its replies and their reactions.
+func (dp *DesignCommentPersister) DeleteComment(id uuid.UUID) error {
+	ids := []uuid.UUID{id}
+	replies := []uuid.UUID{}
+	if err := dp.DB.Model(&DesignComment{}).Where("parent_id = ?", id).Pluck("id", &replies).Error; err != nil {
+		return err
+	}
+	ids = append(ids, replies...)
+	if err := dp.DB.Where("comment_id IN ?", ids).Delete(&DesignCommentReaction{}).Error; err != nil {
+		return err
+	}
+	return dp.DB.Where("id IN ?", ids).Delete(&DesignComment{}).Error
+}
+
+// DeleteDesignComments removes the comments of the design along with their reactions.
+func (dp *DesignCommentPersister) DeleteDesignComments(designID uuid.UUID) error {
+	ids := []uuid.UUID{}
+	if err := dp.DB.Model(&DesignComment{}).Where("design_id = ?", designID).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := dp.DB.Where("comment_id IN ?", ids).Delete(&DesignCommentReaction{}).Error; err != nil {
+		return err
+	}
+	return dp.DB.Where("design_id = ?", designID).Delete(&DesignComment{}).Error
+}
+
+// AddReaction records the reaction of the user to the comment, once.
+func (dp *DesignCommentPersister) AddReaction(commentID, userID uuid.UUID, reaction string) error {
+	existing := []DesignCommentReaction{}
+	err := dp.DB.Where("comment_id = ? AND user_id = ? AND reaction = ?", commentID, userID, reaction).Limit(1).Find(&existing).Error
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ErrGenerateUUID(err)
+	}
+	return dp.DB.Create(&DesignCommentReaction{ID: id, CommentID: commentID, UserID: userID, Reaction: reaction}).Error
+}
+
+// RemoveReaction removes the reaction of the user to the comment.
+func (dp *DesignCommentPersister) RemoveReaction(commentID, userID uuid.UUID, reaction string) error {
+	return dp.DB.Where("comment_id = ? AND user_id = ? AND reaction = ?", commentID, userID, reaction).Delete(&DesignCommentReaction{}).Error
+}
+
+// GetActivity returns the latest entries of the activity feed of the design, most recent first: its
+// comments, its revisions and its deployments, as recorded by the events of the design, up to limit.
+func (dp *DesignCommentPersister) GetActivity(designID uuid.UUID, limit int) ([]DesignActivity, error) {
+	comments := []DesignComment{}
+	if err := dp.DB.Where("design_id = ?", designID).Order("created_at desc").Limit(limit).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	if err := dp.countReactions(comments); err != nil {
+		return nil, err
+	}
+
+	actions := make([]string, 0, len(designActivityActions))
+	for action := range designActivityActions {
+		actions = append(actions, action)
+	}
+	designEvents := []events.Event{}
+	err := dp.DB.Where("acted_upon = ? AND category = ? AND action IN ?", designID, "pattern", actions).
+		Order("created_at desc").Limit(limit).Find(&designEvents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]DesignActivity, 0, len(comments)+len(designEvents))
+	for i := range comments {
+		comment := &comments[i]
+		userID := comment.UserID
+		action := "comment"
+		if comment.ParentID != nil {
+			action = "reply"
+		}
+		activity = append(activity, DesignActivity{Type: DesignActivityComment, Action: action, At: comment.CreatedAt, UserID: &userID, Comment: comment})
+	}
+	for _, event := range designEvents {
+		eventID := event.ID
+		activity = append(activity, DesignActivity{
+			Type:        designActivityActions[event.Action],
+			Action:      event.Action,
+			At:          event.CreatedAt,
+			UserID:      event.UserID,
+			Description: event.Description,
+			Severity:    event.Severity,
+			EventID:     &eventID,
+		})
+	}
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].At.After(activity[j].At)
+	})
+	if len(activity) > limit {
+		activity = activity[:limit]
+	}
+	return activity, nil
+}
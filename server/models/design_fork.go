@@ -0,0 +1,158 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+)
+
+// DesignFork records the lineage of a design forked from another design, typically a design shared with
+// the user or published to the catalog. Base is the design file of the origin the fork was made from, or
+// last synced with, against which the changes proposed by the fork are applied.
+type DesignFork struct {
+	ForkID     uuid.UUID `json:"fork_id" gorm:"primarykey"`
+	OriginID   uuid.UUID `json:"origin_id" gorm:"index"`
+	OriginName string    `json:"origin_name"`
+	UserID     uuid.UUID `json:"user_id"`
+	Base       string    `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DesignLineage is the lineage of a design: the designs it was forked from, its origin first, and the
+// designs forked from it.
+type DesignLineage struct {
+	Ancestors []DesignFork `json:"ancestors"`
+	Forks     []DesignFork `json:"forks"`
+}
+
+// DesignMergeRequestStatus is the status of a merge request.
+type DesignMergeRequestStatus string
+
+const (
+	DesignMergeRequestOpen     DesignMergeRequestStatus = "open"
+	DesignMergeRequestAccepted DesignMergeRequestStatus = "accepted"
+	DesignMergeRequestRejected DesignMergeRequestStatus = "rejected"
+	DesignMergeRequestClosed   DesignMergeRequestStatus = "closed"
+)
+
+// DesignMergeRequest proposes the changes of a fork back to the design it was forked from. Base and
+// Proposed are the design files of the origin the fork was made from and of the fork when the request
+// was opened; the changes are the difference between both.
+type DesignMergeRequest struct {
+	ID          uuid.UUID                `json:"id" gorm:"primarykey"`
+	ForkID      uuid.UUID                `json:"fork_id" gorm:"index"`
+	OriginID    uuid.UUID                `json:"origin_id" gorm:"index"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description,omitempty"`
+	UserID      uuid.UUID                `json:"user_id"`
+	UserName    string                   `json:"user_name"`
+	Status      DesignMergeRequestStatus `json:"status"`
+	Base        string                   `json:"-"`
+	Proposed    string                   `json:"-"`
+
+	ReviewerID    *uuid.UUID `json:"reviewer_id,omitempty"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	// MergedVersion is the version of the revision of the origin accepting the changes.
+	MergedVersion string `json:"merged_version,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DesignForkPersister is the persister for the lineage of the designs and their merge requests
+type DesignForkPersister struct {
+	DB *database.Handler
+}
+
+// SaveFork records the fork, or updates it if it is already recorded.
+func (dp *DesignForkPersister) SaveFork(fork *DesignFork) error {
+	return dp.DB.Save(fork).Error
+}
+
+// GetFork returns the lineage of the design forked, nil if the design is not a fork.
+func (dp *DesignForkPersister) GetFork(forkID uuid.UUID) (*DesignFork, error) {
+	forks := []DesignFork{}
+	if err := dp.DB.Where("fork_id = ?", forkID).Limit(1).Find(&forks).Error; err != nil {
+		return nil, err
+	}
+	if len(forks) == 0 {
+		return nil, nil
+	}
+	return &forks[0], nil
+}
+
+// GetLineage returns the designs the design was forked from and the designs forked from it.
+func (dp *DesignForkPersister) GetLineage(designID uuid.UUID) (*DesignLineage, error) {
+	lineage := &DesignLineage{Ancestors: []DesignFork{}, Forks: []DesignFork{}}
+	seen := map[uuid.UUID]bool{designID: true}
+	for id := designID; ; {
+		fork, err := dp.GetFork(id)
+		if err != nil {
+			return nil, err
+		}
+		// a fork of a fork of a deleted design ends the lineage, as would a cycle
+		if fork == nil || seen[fork.OriginID] {
+			break
+		}
+		seen[fork.OriginID] = true
+		lineage.Ancestors = append([]DesignFork{*fork}, lineage.Ancestors...)
+		id = fork.OriginID
+	}
+	if err := dp.DB.Where("origin_id = ?", designID).Order("created_at").Find(&lineage.Forks).Error; err != nil {
+		return nil, err
+	}
+	return lineage, nil
+}
+
+// SaveMergeRequest creates the merge request, or updates it if it already exists.
+func (dp *DesignForkPersister) SaveMergeRequest(mr *DesignMergeRequest) error {
+	if mr.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		mr.ID = id
+	}
+	return dp.DB.Save(mr).Error
+}
+
+// GetMergeRequest returns the merge request with the given id, nil if it does not exist.
+func (dp *DesignForkPersister) GetMergeRequest(id uuid.UUID) (*DesignMergeRequest, error) {
+	mrs := []DesignMergeRequest{}
+	if err := dp.DB.Where("id = ?", id).Limit(1).Find(&mrs).Error; err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return &mrs[0], nil
+}
+
+// GetMergeRequests returns the merge requests proposed to the design or from it, most recent first,
+// with the given status unless status is empty.
+func (dp *DesignForkPersister) GetMergeRequests(designID uuid.UUID, status DesignMergeRequestStatus) ([]DesignMergeRequest, error) {
+	mrs := []DesignMergeRequest{}
+	query := dp.DB.Where("origin_id = ? OR fork_id = ?", designID, designID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at desc").Find(&mrs).Error; err != nil {
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// DeleteDesign removes the lineage of the deleted design and closes the merge requests still open from
+// or to it. The forks of the design keep their lineage, their origin no longer exists.
+func (dp *DesignForkPersister) DeleteDesign(designID uuid.UUID) error {
+	if err := dp.DB.Where("fork_id = ?", designID).Delete(&DesignFork{}).Error; err != nil {
+		return err
+	}
+	return dp.DB.Model(&DesignMergeRequest{}).
+		Where("(origin_id = ? OR fork_id = ?) AND status = ?", designID, designID, DesignMergeRequestOpen).
+		Update("status", DesignMergeRequestClosed).Error
+}
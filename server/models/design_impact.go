@@ -0,0 +1,218 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/encoding"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// Kinds of schema changes that can break the configuration of an existing component.
+const (
+	SchemaFieldRemoved     = "removed"
+	SchemaFieldTypeChanged = "type_changed"
+	SchemaFieldNowRequired = "now_required"
+)
+
+// SchemaFieldChange is a breaking change of a single field of a component schema.
+// Field is the dot separated path of the field within the component configuration.
+type SchemaFieldChange struct {
+	Field  string `json:"field"`
+	Change string `json:"change"`
+}
+
+// DesignComponentUsage is a component used by a stored design.
+type DesignComponentUsage struct {
+	ComponentID  uuid.UUID `json:"component_id"`
+	DisplayName  string    `json:"display_name"`
+	Kind         string    `json:"kind"`
+	Version      string    `json:"version"`
+	Model        string    `json:"model"`
+	ModelVersion string    `json:"model_version"`
+	// BreakingFields is only set when analysing the impact of a registry change.
+	BreakingFields []SchemaFieldChange `json:"breaking_fields,omitempty"`
+}
+
+// DesignImpact lists the components of a stored design that are affected
+// by a registry change, or that match an impact-analysis query.
+type DesignImpact struct {
+	DesignID   uuid.UUID              `json:"design_id"`
+	DesignName string                 `json:"design_name"`
+	UserID     *string                `json:"user_id"`
+	Components []DesignComponentUsage `json:"components"`
+}
+
+// GetDesignsUsingComponent returns the designs of the user, and the published designs, that use components
+// of the given kind. The version is the version of the component, eg. apps/v1, not the version of its model.
+// Empty model and version match any model and component version.
+func (mpp *MesheryPatternPersister) GetDesignsUsingComponent(userID uuid.UUID, kind, model, version string) ([]DesignImpact, error) {
+	return mpp.findDesignImpacts(&userID, func(comp *component.ComponentDefinition) ([]SchemaFieldChange, bool) {
+		if comp.Component.Kind != kind {
+			return nil, false
+		}
+		if model != "" && comp.Model.Name != model {
+			return nil, false
+		}
+		if version != "" && comp.Component.Version != version {
+			return nil, false
+		}
+		return nil, true
+	})
+}
+
+// GetRegistryChangeImpact returns every stored design, whatever its owner and visibility, whose components
+// are affected by the given (newly registered) component definitions, so that all of their owners can be notified.
+// A design component is affected when a definition of the same model and kind
+// has breaking changes with respect to the schema the design was created with.
+func (mpp *MesheryPatternPersister) GetRegistryChangeImpact(updated []component.ComponentDefinition) ([]DesignImpact, error) {
+	if len(updated) == 0 {
+		return nil, nil
+	}
+
+	schemas := make(map[string]string, len(updated))
+	for _, def := range updated {
+		schemas[def.Model.Name+"/"+def.Component.Kind] = def.Component.Schema
+	}
+
+	return mpp.findDesignImpacts(nil, func(comp *component.ComponentDefinition) ([]SchemaFieldChange, bool) {
+		newSchema, ok := schemas[comp.Model.Name+"/"+comp.Component.Kind]
+		if !ok || comp.Component.Schema == "" || newSchema == comp.Component.Schema {
+			return nil, false
+		}
+		changes, err := BreakingSchemaChanges(comp.Component.Schema, newSchema)
+		if err != nil || len(changes) == 0 {
+			return nil, false
+		}
+		return changes, true
+	})
+}
+
+// findDesignImpacts matches the components of the designs the user can read: their own designs and the
+// published ones. All the stored designs are matched when userID is nil.
+func (mpp *MesheryPatternPersister) findDesignImpacts(userID *uuid.UUID, match func(*component.ComponentDefinition) ([]SchemaFieldChange, bool)) ([]DesignImpact, error) {
+	designs := []MesheryPattern{}
+	query := mpp.DB.Table("meshery_patterns")
+	if userID != nil {
+		query = query.Where("user_id = ? OR visibility = ?", userID.String(), Published)
+	}
+	if err := query.Find(&designs).Error; err != nil {
+		return nil, ErrAnalyzeDesignImpact(err)
+	}
+
+	impacts := []DesignImpact{}
+	for _, design := range designs {
+		var patternFile pattern.PatternFile
+		// Designs which cannot be decoded are skipped rather than failing the analysis.
+		if err := encoding.Unmarshal([]byte(design.PatternFile), &patternFile); err != nil {
+			continue
+		}
+
+		impact := DesignImpact{
+			DesignName: design.Name,
+			UserID:     design.UserID,
+		}
+		if design.ID != nil {
+			impact.DesignID = *design.ID
+		}
+		for _, comp := range patternFile.Components {
+			if comp == nil {
+				continue
+			}
+			changes, ok := match(comp)
+			if !ok {
+				continue
+			}
+			impact.Components = append(impact.Components, DesignComponentUsage{
+				ComponentID:    comp.Id,
+				DisplayName:    comp.DisplayName,
+				Kind:           comp.Component.Kind,
+				Version:        comp.Component.Version,
+				Model:          comp.Model.Name,
+				ModelVersion:   comp.Model.Model.Version,
+				BreakingFields: changes,
+			})
+		}
+		if len(impact.Components) > 0 {
+			impacts = append(impacts, impact)
+		}
+	}
+	return impacts, nil
+}
+
+// BreakingSchemaChanges compares two JSON schemas of a component and returns the
+// changes which can invalidate configuration written against the old schema:
+// removed properties, properties whose type changed and newly required properties.
+func BreakingSchemaChanges(oldSchema, newSchema string) ([]SchemaFieldChange, error) {
+	var oldS, newS map[string]interface{}
+	if err := json.Unmarshal([]byte(oldSchema), &oldS); err != nil {
+		return nil, ErrUnmarshal(err, "component schema")
+	}
+	if err := json.Unmarshal([]byte(newSchema), &newS); err != nil {
+		return nil, ErrUnmarshal(err, "component schema")
+	}
+
+	changes := []SchemaFieldChange{}
+	diffSchemaProperties("", oldS, newS, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func diffSchemaProperties(prefix string, oldS, newS map[string]interface{}, changes *[]SchemaFieldChange) {
+	oldProps, _ := oldS["properties"].(map[string]interface{})
+	newProps, _ := newS["properties"].(map[string]interface{})
+
+	for name, oldProp := range oldProps {
+		field := joinSchemaField(prefix, name)
+		newProp, ok := newProps[name]
+		if !ok {
+			*changes = append(*changes, SchemaFieldChange{Field: field, Change: SchemaFieldRemoved})
+			continue
+		}
+		oldPropSchema, _ := oldProp.(map[string]interface{})
+		newPropSchema, _ := newProp.(map[string]interface{})
+		if oldPropSchema == nil || newPropSchema == nil {
+			continue
+		}
+		if oldType, newType := oldPropSchema["type"], newPropSchema["type"]; oldType != nil && newType != nil && !jsonEqual(oldType, newType) {
+			*changes = append(*changes, SchemaFieldChange{Field: field, Change: SchemaFieldTypeChanged})
+			continue
+		}
+		diffSchemaProperties(field, oldPropSchema, newPropSchema, changes)
+	}
+
+	oldRequired := map[string]bool{}
+	if required, ok := oldS["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				oldRequired[name] = true
+			}
+		}
+	}
+	if required, ok := newS["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok || oldRequired[name] {
+				continue
+			}
+			*changes = append(*changes, SchemaFieldChange{Field: joinSchemaField(prefix, name), Change: SchemaFieldNowRequired})
+		}
+	}
+}
+
+func joinSchemaField(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ab, errA := json.Marshal(a)
+	bb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ab) == string(bb)
+}
@@ -0,0 +1,278 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// newTestDB returns a SQLite database in a temporary directory, with the tables of the models migrated.
+func newTestDB(t *testing.T, tables ...interface{}) *database.Handler {
+	t.Helper()
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(database.Options{
+		Filename: fmt.Sprintf("file:%s?mode=rwc", filepath.Join(t.TempDir(), "test.db")),
+		Engine:   database.SQLITE,
+		Logger:   log,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	return &db
+}
+
+func TestBreakingSchemaChanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldSchema string
+		newSchema string
+		want      []SchemaFieldChange
+		wantErr   bool
+	}{
+		{
+			name:      "unchanged",
+			oldSchema: `{"properties":{"replicas":{"type":"integer"}}}`,
+			newSchema: `{"properties":{"replicas":{"type":"integer"}}}`,
+			want:      []SchemaFieldChange{},
+		},
+		{
+			name:      "added optional property",
+			oldSchema: `{"properties":{"replicas":{"type":"integer"}}}`,
+			newSchema: `{"properties":{"replicas":{"type":"integer"},"paused":{"type":"boolean"}}}`,
+			want:      []SchemaFieldChange{},
+		},
+		{
+			name:      "removed property",
+			oldSchema: `{"properties":{"replicas":{"type":"integer"},"paused":{"type":"boolean"}}}`,
+			newSchema: `{"properties":{"replicas":{"type":"integer"}}}`,
+			want:      []SchemaFieldChange{{Field: "paused", Change: SchemaFieldRemoved}},
+		},
+		{
+			name:      "type changed",
+			oldSchema: `{"properties":{"replicas":{"type":"integer"}}}`,
+			newSchema: `{"properties":{"replicas":{"type":"string"}}}`,
+			want:      []SchemaFieldChange{{Field: "replicas", Change: SchemaFieldTypeChanged}},
+		},
+		{
+			name:      "newly required property",
+			oldSchema: `{"properties":{"replicas":{"type":"integer"}},"required":["replicas"]}`,
+			newSchema: `{"properties":{"replicas":{"type":"integer"},"selector":{"type":"object"}},"required":["replicas","selector"]}`,
+			want:      []SchemaFieldChange{{Field: "selector", Change: SchemaFieldNowRequired}},
+		},
+		{
+			name:      "nested changes sorted by field",
+			oldSchema: `{"properties":{"spec":{"type":"object","properties":{"template":{"type":"object"},"replicas":{"type":"integer"}}}}}`,
+			newSchema: `{"properties":{"spec":{"type":"object","properties":{"replicas":{"type":["integer","null"]}},"required":["selector"]}}}`,
+			want: []SchemaFieldChange{
+				{Field: "spec.replicas", Change: SchemaFieldTypeChanged},
+				{Field: "spec.selector", Change: SchemaFieldNowRequired},
+				{Field: "spec.template", Change: SchemaFieldRemoved},
+			},
+		},
+		{
+			name:      "invalid old schema",
+			oldSchema: `{`,
+			newSchema: `{}`,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid new schema",
+			oldSchema: `{}`,
+			newSchema: `[`,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BreakingSchemaChanges(tt.oldSchema, tt.newSchema)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BreakingSchemaChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDesignsUsingComponent_ScopedToUser(t *testing.T) {
+	db := newTestDB(t, &MesheryPattern{})
+	mpp := &MesheryPatternPersister{DB: db}
+
+	caller := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	designs := map[string]struct {
+		owner      uuid.UUID
+		visibility string
+	}{
+		"own private":     {owner: caller, visibility: Private},
+		"own published":   {owner: caller, visibility: Published},
+		"other private":   {owner: other, visibility: Private},
+		"other public":    {owner: other, visibility: Public},
+		"other published": {owner: other, visibility: Published},
+	}
+	for name, d := range designs {
+		patternFile, err := newDesignImpactTestPatternFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := uuid.Must(uuid.NewV4())
+		owner := d.owner.String()
+		if err := db.Create(&MesheryPattern{ID: &id, Name: name, PatternFile: patternFile, UserID: &owner, Visibility: d.visibility}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	impacts, err := mpp.GetDesignsUsingComponent(caller, "Deployment", "kubernetes", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, impact := range impacts {
+		got[impact.DesignName] = true
+	}
+	want := map[string]bool{"own private": true, "own published": true, "other published": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDesignsUsingComponent() returned designs %v, want %v", got, want)
+	}
+}
+
+func TestGetDesignsUsingComponent_Version(t *testing.T) {
+	db := newTestDB(t, &MesheryPattern{})
+	mpp := &MesheryPatternPersister{DB: db}
+
+	owner := uuid.Must(uuid.NewV4())
+	patternFile := pattern.PatternFile{
+		Name: "web",
+		Components: []*component.ComponentDefinition{{
+			Id:          uuid.Must(uuid.NewV4()),
+			DisplayName: "web",
+			Component:   component.Component{Kind: "Deployment", Version: "apps/v1"},
+			Model:       model.ModelDefinition{Name: "kubernetes", Model: model.Model{Version: "v1.30.0"}},
+		}},
+	}
+	byt, err := json.Marshal(patternFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := uuid.Must(uuid.NewV4())
+	ownerID := owner.String()
+	if err := db.Create(&MesheryPattern{ID: &id, Name: "web", PatternFile: string(byt), UserID: &ownerID, Visibility: Private}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{version: "", want: 1},
+		{version: "apps/v1", want: 1},
+		{version: "apps/v1beta1", want: 0},
+		{version: "v1.30.0", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			impacts, err := mpp.GetDesignsUsingComponent(owner, "Deployment", "kubernetes", tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(impacts) != tt.want {
+				t.Fatalf("GetDesignsUsingComponent() returned %d designs, want %d", len(impacts), tt.want)
+			}
+			if tt.want > 0 && (impacts[0].Components[0].Version != "apps/v1" || impacts[0].Components[0].ModelVersion != "v1.30.0") {
+				t.Errorf("expected the versions of the component and its model, got %+v", impacts[0].Components[0])
+			}
+		})
+	}
+}
+
+func TestGetRegistryChangeImpact_AllOwners(t *testing.T) {
+	db := newTestDB(t, &MesheryPattern{})
+	mpp := &MesheryPatternPersister{DB: db}
+
+	oldSchema := `{"properties": {"replicas": {"type": "integer"}}}`
+	owners := map[string]uuid.UUID{
+		"first private":  uuid.Must(uuid.NewV4()),
+		"second private": uuid.Must(uuid.NewV4()),
+		"published":      uuid.Must(uuid.NewV4()),
+	}
+	for name, owner := range owners {
+		patternFile := pattern.PatternFile{
+			Name: name,
+			Components: []*component.ComponentDefinition{{
+				Id:          uuid.NewV5(uuid.Nil, name),
+				DisplayName: name,
+				Component:   component.Component{Kind: "Deployment", Version: "apps/v1", Schema: oldSchema},
+				Model:       model.ModelDefinition{Name: "kubernetes"},
+			}},
+		}
+		byt, err := json.Marshal(patternFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		visibility := Private
+		if name == "published" {
+			visibility = Published
+		}
+		id := uuid.Must(uuid.NewV4())
+		ownerID := owner.String()
+		if err := db.Create(&MesheryPattern{ID: &id, Name: name, PatternFile: string(byt), UserID: &ownerID, Visibility: visibility}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated := []component.ComponentDefinition{{
+		Component: component.Component{Kind: "Deployment", Schema: `{"properties": {"replicas": {"type": "string"}}}`},
+		Model:     model.ModelDefinition{Name: "kubernetes"},
+	}}
+	impacts, err := mpp.GetRegistryChangeImpact(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, impact := range impacts {
+		got[impact.DesignName] = *impact.UserID
+	}
+	want := map[string]string{}
+	for name, owner := range owners {
+		want[name] = owner.String()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRegistryChangeImpact() returned designs %v, want %v", got, want)
+	}
+}
+
+func newDesignImpactTestPatternFile(name string) (string, error) {
+	patternFile := pattern.PatternFile{
+		Name: name,
+		Components: []*component.ComponentDefinition{{
+			Id:          uuid.NewV5(uuid.Nil, name),
+			DisplayName: name,
+			Component:   component.Component{Kind: "Deployment", Version: "apps/v1"},
+			Model:       model.ModelDefinition{Name: "kubernetes"},
+		}},
+	}
+	byt, err := json.Marshal(patternFile)
+	return string(byt), err
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// OwnershipConflictResource is a conflict on a resource deployed by another design.
+	OwnershipConflictResource = "resource"
+	// OwnershipConflictNamespace is a conflict on a resource in a namespace declared by another design.
+	OwnershipConflictNamespace = "namespace"
+)
+
+// OwnedResource identifies a resource of a cluster, namespace is empty for cluster scoped resources.
+type OwnedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (r OwnedResource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// ResourceOwnership records the design owning a resource of a cluster. A design owns the resources it
+// deployed last, a Namespace among them along with every resource deployed to it.
+type ResourceOwnership struct {
+	ID         uuid.UUID `json:"id" gorm:"primarykey"`
+	ClusterID  string    `json:"cluster_id" gorm:"uniqueIndex:idx_resource_ownership_resource"`
+	Kind       string    `json:"kind" gorm:"uniqueIndex:idx_resource_ownership_resource"`
+	Namespace  string    `json:"namespace" gorm:"uniqueIndex:idx_resource_ownership_resource"`
+	Name       string    `json:"name" gorm:"uniqueIndex:idx_resource_ownership_resource"`
+	DesignID   uuid.UUID `json:"design_id" gorm:"index"`
+	DesignName string    `json:"design_name"`
+	UserID     uuid.UUID `json:"user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Resource returns the resource owned.
+func (ro *ResourceOwnership) Resource() OwnedResource {
+	return OwnedResource{Kind: ro.Kind, Namespace: ro.Namespace, Name: ro.Name}
+}
+
+// OwnershipOverride records a deployment which modified, or removed, resources owned by another design.
+type OwnershipOverride struct {
+	ID                 uuid.UUID `json:"id" gorm:"primarykey"`
+	DesignID           uuid.UUID `json:"design_id" gorm:"index"`
+	DesignName         string    `json:"design_name"`
+	ClusterID          string    `json:"cluster_id"`
+	Kind               string    `json:"kind"`
+	Namespace          string    `json:"namespace"`
+	Name               string    `json:"name"`
+	PreviousDesignID   uuid.UUID `json:"previous_design_id" gorm:"index"`
+	PreviousDesignName string    `json:"previous_design_name"`
+	Justification      string    `json:"justification"`
+	UserID             uuid.UUID `json:"user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OwnershipOverrideRequest is the part of deployment requests overriding the ownership of the
+// resources of other designs. Overrides require a justification, which is recorded.
+type OwnershipOverrideRequest struct {
+	OverrideOwnership     bool   `json:"override_ownership,omitempty"`
+	OverrideJustification string `json:"override_justification,omitempty"`
+}
+
+// Validate ensures the override of the ownership is justified.
+func (o OwnershipOverrideRequest) Validate() error {
+	if o.OverrideOwnership && strings.TrimSpace(o.OverrideJustification) == "" {
+		return fmt.Errorf("overriding the ownership of the resources of other designs requires a justification")
+	}
+	return nil
+}
+
+// OwnershipConflict is a resource of a design which is owned by another design, directly or through
+// its namespace.
+type OwnershipConflict struct {
+	ClusterID string            `json:"cluster_id"`
+	Resource  OwnedResource     `json:"resource"`
+	Reason    string            `json:"reason"`
+	Owner     ResourceOwnership `json:"owner"`
+}
+
+// OwnershipConflictError is the error of a deployment which would modify, or remove, resources owned
+// by other designs without an override.
+type OwnershipConflictError struct {
+	Design    string              `json:"design"`
+	Conflicts []OwnershipConflict `json:"conflicts"`
+}
+
+func (e *OwnershipConflictError) Error() string {
+	resources := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		resources = append(resources, fmt.Sprintf("%s is owned by design %s", conflict.Resource, conflict.Owner.DesignName))
+	}
+	return fmt.Sprintf("design %s conflicts with other designs: %s; override the ownership with a justification to deploy it anyway", e.Design, strings.Join(resources, ", "))
+}
+
+// DesignOwnedResources returns the resources of the components of the design, by kind, namespace and name.
+func DesignOwnedResources(patternFile *pattern.PatternFile) []OwnedResource {
+	resources := []OwnedResource{}
+	seen := map[OwnedResource]bool{}
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Component.Kind == "" {
+			continue
+		}
+		resource := OwnedResource{Kind: comp.Component.Kind}
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		if resource.Name, _ = metadata["name"].(string); resource.Name == "" {
+			resource.Name = comp.DisplayName
+		}
+		if resource.Kind != "Namespace" {
+			resource.Namespace, _ = metadata["namespace"].(string)
+			if resource.Namespace == "" && comp.Metadata.IsNamespaced {
+				resource.Namespace = "default"
+			}
+		}
+		if resource.Name != "" && !seen[resource] {
+			seen[resource] = true
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// DesignOwnershipPersister is the persister for the ownership of resources by designs and its overrides
+type DesignOwnershipPersister struct {
+	DB *database.Handler
+}
+
+// Conflicts returns the resources, of the given clusters, owned by other designs than the given one,
+// either directly or through their namespace, in the order of the clusters and the resources.
+func (dop *DesignOwnershipPersister) Conflicts(designID uuid.UUID, clusterIDs []string, resources []OwnedResource) ([]OwnershipConflict, error) {
+	conflicts := []OwnershipConflict{}
+	if len(clusterIDs) == 0 || len(resources) == 0 {
+		return conflicts, nil
+	}
+	names := []string{}
+	namespaces := []string{}
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+		if resource.Namespace != "" {
+			namespaces = append(namespaces, resource.Namespace)
+		}
+	}
+
+	owned := []ResourceOwnership{}
+	err := dop.DB.Where("cluster_id IN ? AND design_id <> ?", clusterIDs, designID).
+		Where(dop.DB.Where("name IN ?", names).Or("kind = ? AND name IN ?", "Namespace", namespaces)).
+		Find(&owned).Error
+	if err != nil {
+		return nil, err
+	}
+	byResource := map[string]ResourceOwnership{}
+	for _, ownership := range owned {
+		byResource[ownership.ClusterID+" "+ownership.Resource().String()] = ownership
+	}
+
+	for _, clusterID := range clusterIDs {
+		for _, resource := range resources {
+			if owner, ok := byResource[clusterID+" "+resource.String()]; ok {
+				conflicts = append(conflicts, OwnershipConflict{ClusterID: clusterID, Resource: resource, Reason: OwnershipConflictResource, Owner: owner})
+				continue
+			}
+			namespace := OwnedResource{Kind: "Namespace", Name: resource.Namespace}
+			if owner, ok := byResource[clusterID+" "+namespace.String()]; ok && resource.Namespace != "" {
+				conflicts = append(conflicts, OwnershipConflict{ClusterID: clusterID, Resource: resource, Reason: OwnershipConflictNamespace, Owner: owner})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// Claim records the design as the owner of its resources in the given clusters, transferring the
+// ownership of the resources owned by other designs.
+func (dop *DesignOwnershipPersister) Claim(designID uuid.UUID, designName string, userID uuid.UUID, clusterIDs []string, resources []OwnedResource) error {
+	ownerships := []ResourceOwnership{}
+	for _, clusterID := range clusterIDs {
+		for _, resource := range resources {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return ErrGenerateUUID(err)
+			}
+			ownerships = append(ownerships, ResourceOwnership{
+				ID:         id,
+				ClusterID:  clusterID,
+				Kind:       resource.Kind,
+				Namespace:  resource.Namespace,
+				Name:       resource.Name,
+				DesignID:   designID,
+				DesignName: designName,
+				UserID:     userID,
+			})
+		}
+	}
+	if len(ownerships) == 0 {
+		return nil
+	}
+	return dop.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cluster_id"}, {Name: "kind"}, {Name: "namespace"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"design_id", "design_name", "user_id", "updated_at"}),
+	}).Create(&ownerships).Error
+}
+
+// Release forgets the ownership of the resources of the design in the given clusters, once undeployed.
+func (dop *DesignOwnershipPersister) Release(designID uuid.UUID, clusterIDs []string) error {
+	if len(clusterIDs) == 0 {
+		return nil
+	}
+	return dop.DB.Where("design_id = ? AND cluster_id IN ?", designID, clusterIDs).Delete(&ResourceOwnership{}).Error
+}
+
+// RecordOverrides records the overrides of the ownership of resources, along with their justification.
+func (dop *DesignOwnershipPersister) RecordOverrides(overrides []OwnershipOverride) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	for i := range overrides {
+		if overrides[i].ID == uuid.Nil {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return ErrGenerateUUID(err)
+			}
+			overrides[i].ID = id
+		}
+	}
+	return dop.DB.Create(&overrides).Error
+}
+
+// List returns the ownership of resources, of the given design and cluster when not empty, ordered
+// by cluster and resource.
+func (dop *DesignOwnershipPersister) List(designID uuid.UUID, clusterID string) ([]ResourceOwnership, error) {
+	ownerships := []ResourceOwnership{}
+	query := dop.DB.Order("cluster_id, kind, namespace, name")
+	if designID != uuid.Nil {
+		query = query.Where("design_id = ?", designID)
+	}
+	if clusterID != "" {
+		query = query.Where("cluster_id = ?", clusterID)
+	}
+	err := query.Find(&ownerships).Error
+	return ownerships, err
+}
+
+// ListOverrides returns the overrides, by or of the given design when not nil, the latest first.
+func (dop *DesignOwnershipPersister) ListOverrides(designID uuid.UUID) ([]OwnershipOverride, error) {
+	overrides := []OwnershipOverride{}
+	query := dop.DB.Order("created_at desc")
+	if designID != uuid.Nil {
+		query = query.Where("design_id = ? OR previous_design_id = ?", designID, designID)
+	}
+	err := query.Find(&overrides).Error
+	return overrides, err
+}
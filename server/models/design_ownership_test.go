@@ -0,0 +1,174 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func TestDesignOwnedResources(t *testing.T) {
+	comp := func(kind, displayName, name, namespace string, namespaced bool) *component.ComponentDefinition {
+		metadata := map[string]interface{}{}
+		if name != "" {
+			metadata["name"] = name
+		}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return &component.ComponentDefinition{
+			DisplayName:   displayName,
+			Component:     component.Component{Kind: kind},
+			Metadata:      component.ComponentDefinition_Metadata{IsNamespaced: namespaced},
+			Configuration: map[string]interface{}{"metadata": metadata},
+		}
+	}
+	design := &pattern.PatternFile{Components: []*component.ComponentDefinition{
+		comp("Namespace", "shop", "", "", false),
+		comp("Deployment", "web", "web-app", "shop", true),
+		comp("ConfigMap", "config", "", "", true),
+		comp("ClusterRole", "reader", "reader", "", false),
+		// the same resource twice
+		comp("Deployment", "web copy", "web-app", "shop", true),
+		comp("", "comment", "", "", false),
+		nil,
+	}}
+
+	want := []OwnedResource{
+		{Kind: "Namespace", Name: "shop"},
+		{Kind: "Deployment", Namespace: "shop", Name: "web-app"},
+		{Kind: "ConfigMap", Namespace: "default", Name: "config"},
+		{Kind: "ClusterRole", Name: "reader"},
+	}
+	if got := DesignOwnedResources(design); !reflect.DeepEqual(got, want) {
+		t.Errorf("DesignOwnedResources() = %v, want %v", got, want)
+	}
+}
+
+func TestDesignOwnershipPersister(t *testing.T) {
+	dop := &DesignOwnershipPersister{DB: newTestDB(t, &ResourceOwnership{}, &OwnershipOverride{})}
+	other := uuid.Must(uuid.NewV4())
+	design := uuid.Must(uuid.NewV4())
+	user := uuid.Must(uuid.NewV4())
+
+	shop := OwnedResource{Kind: "Namespace", Name: "shop"}
+	api := OwnedResource{Kind: "Deployment", Namespace: "default", Name: "api"}
+	web := OwnedResource{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	config := OwnedResource{Kind: "ConfigMap", Namespace: "default", Name: "config"}
+	if err := dop.Claim(other, "other", user, []string{"c1"}, []OwnedResource{shop, api}); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err := dop.Conflicts(design, []string{"c1", "c2"}, []OwnedResource{web, api, config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, conflict := range conflicts {
+		if conflict.Owner.DesignID != other || conflict.Owner.DesignName != "other" {
+			t.Errorf("expected the other design to own %s, got %+v", conflict.Resource, conflict.Owner)
+		}
+		got = append(got, conflict.ClusterID+" "+conflict.Resource.String()+" "+conflict.Reason)
+	}
+	want := []string{
+		"c1 Deployment shop/web " + OwnershipConflictNamespace,
+		"c1 Deployment default/api " + OwnershipConflictResource,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %v, want %v", got, want)
+	}
+	if conflicts, err := dop.Conflicts(other, []string{"c1"}, []OwnedResource{shop, api}); err != nil || len(conflicts) != 0 {
+		t.Errorf("expected a design not to conflict with itself, got %v: %v", conflicts, err)
+	}
+
+	// deploying the design transfers the ownership of api
+	if err := dop.Claim(design, "mine", user, []string{"c1"}, []OwnedResource{api, config}); err != nil {
+		t.Fatal(err)
+	}
+	owned, err := dop.List(uuid.Nil, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners := map[string]string{}
+	for _, ownership := range owned {
+		owners[ownership.Resource().String()] = ownership.DesignName
+	}
+	if !reflect.DeepEqual(owners, map[string]string{"Namespace shop": "other", "Deployment default/api": "mine", "ConfigMap default/config": "mine"}) {
+		t.Errorf("owners = %v", owners)
+	}
+
+	// undeploying the design releases its resources
+	if err := dop.Release(design, []string{"c1"}); err != nil {
+		t.Fatal(err)
+	}
+	if owned, err := dop.List(design, ""); err != nil || len(owned) != 0 {
+		t.Errorf("expected the resources of the design to be released, got %v: %v", owned, err)
+	}
+	if owned, err := dop.List(other, ""); err != nil || len(owned) != 1 {
+		t.Errorf("expected the resources of the other design to be kept, got %v: %v", owned, err)
+	}
+}
+
+func TestDesignOwnershipPersister_Overrides(t *testing.T) {
+	dop := &DesignOwnershipPersister{DB: newTestDB(t, &ResourceOwnership{}, &OwnershipOverride{})}
+	other := uuid.Must(uuid.NewV4())
+	design := uuid.Must(uuid.NewV4())
+
+	if err := dop.RecordOverrides([]OwnershipOverride{{
+		DesignID:         design,
+		DesignName:       "mine",
+		ClusterID:        "c1",
+		Kind:             "Deployment",
+		Namespace:        "default",
+		Name:             "api",
+		PreviousDesignID: other,
+		Justification:    "api moves to the shop design",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []uuid.UUID{design, other, uuid.Nil} {
+		overrides, err := dop.ListOverrides(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(overrides) != 1 || overrides[0].ID == uuid.Nil || overrides[0].Justification != "api moves to the shop design" {
+			t.Errorf("expected the override to be listed for %s, got %+v", id, overrides)
+		}
+	}
+	if overrides, err := dop.ListOverrides(uuid.Must(uuid.NewV4())); err != nil || len(overrides) != 0 {
+		t.Errorf("expected no overrides of an unrelated design, got %+v: %v", overrides, err)
+	}
+}
+
+func TestOwnershipOverrideRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request OwnershipOverrideRequest
+		wantErr bool
+	}{
+		{name: "no override"},
+		{name: "justified", request: OwnershipOverrideRequest{OverrideOwnership: true, OverrideJustification: "migration"}},
+		{name: "unjustified", request: OwnershipOverrideRequest{OverrideOwnership: true, OverrideJustification: "  "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOwnershipConflictError(t *testing.T) {
+	err := &OwnershipConflictError{Design: "mine", Conflicts: []OwnershipConflict{
+		{Resource: OwnedResource{Kind: "Deployment", Namespace: "default", Name: "api"}, Owner: ResourceOwnership{DesignName: "other"}},
+		{Resource: OwnedResource{Kind: "ClusterRole", Name: "reader"}, Owner: ResourceOwnership{DesignName: "rbac"}},
+	}}
+	if msg := err.Error(); !strings.Contains(msg, "Deployment default/api is owned by design other, ClusterRole reader is owned by design rbac") {
+		t.Errorf("unexpected error %q", msg)
+	}
+}
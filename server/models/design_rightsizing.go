@@ -0,0 +1,482 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	promModel "github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ResourceUsageSourcePrometheus    = "prometheus"
+	ResourceUsageSourceMetricsServer = "metrics-server"
+
+	// DefaultRightSizingWindow is the usage history recommendations are computed from.
+	DefaultRightSizingWindow = 7 * 24 * time.Hour
+
+	defaultRightSizingPercentile = 90
+	defaultRightSizingMargin     = 0.15
+	// the recommendations never go below these, in cores and bytes
+	minRecommendedCPU    = 0.01
+	minRecommendedMemory = 16 << 20
+)
+
+// RightSizingPolicy tunes the recommendations: requests cover the given percentile of the usage
+// and limits its peak, both with a safety margin on top.
+type RightSizingPolicy struct {
+	// RequestPercentile is the percentile of the usage covered by the requests, 90 by default.
+	RequestPercentile float64 `json:"request_percentile,omitempty"`
+	// Margin is the fraction added to the usage, 0.15 by default.
+	Margin float64 `json:"margin,omitempty"`
+}
+
+func (p RightSizingPolicy) withDefaults() RightSizingPolicy {
+	if p.RequestPercentile <= 0 || p.RequestPercentile > 100 {
+		p.RequestPercentile = defaultRightSizingPercentile
+	}
+	if p.Margin <= 0 {
+		p.Margin = defaultRightSizingMargin
+	}
+	return p
+}
+
+// DesignRightSizingRequest is a design, either a saved design or a design file, to compute
+// resource recommendations for from the usage of its deployed workloads.
+type DesignRightSizingRequest struct {
+	DesignID    *uuid.UUID `json:"design_id,omitempty"`
+	PatternFile string     `json:"pattern_file,omitempty"`
+	// ConnectionID is the Prometheus connection the usage history is queried from, the usage
+	// is read from the metrics-server of the selected clusters when it is not set.
+	ConnectionID *uuid.UUID `json:"connection_id,omitempty"`
+	// Window is the usage history considered, eg. 72h, 7 days by default.
+	Window string            `json:"window,omitempty"`
+	Policy RightSizingPolicy `json:"policy"`
+}
+
+// ApplyRightSizingRequest is the recommendations to apply to a saved design.
+type ApplyRightSizingRequest struct {
+	Components []ComponentRightSizing `json:"components"`
+}
+
+// ContainerUsage is the usage of a container sampled over the pods of a workload, CPU in cores
+// and memory in bytes.
+type ContainerUsage struct {
+	CPU    []float64
+	Memory []float64
+}
+
+// WorkloadUsage is the usage of the containers of a workload by container name.
+type WorkloadUsage map[string]*ContainerUsage
+
+func (u WorkloadUsage) container(name string) *ContainerUsage {
+	usage, ok := u[name]
+	if !ok {
+		usage = &ContainerUsage{}
+		u[name] = usage
+	}
+	return usage
+}
+
+// ResourceUsageCollector collects the usage of the deployed workloads of a design.
+type ResourceUsageCollector interface {
+	Source() string
+	WorkloadUsage(ctx context.Context, namespace, kind, name string) (WorkloadUsage, error)
+}
+
+// PrometheusUsageCollector collects the usage history of workloads from the cAdvisor metrics
+// scraped by Prometheus. The peak usage of every step of the window is sampled.
+type PrometheusUsageCollector struct {
+	Client *PrometheusClient
+	URL    string
+	Window time.Duration
+}
+
+func (c *PrometheusUsageCollector) Source() string {
+	return ResourceUsageSourcePrometheus
+}
+
+func (c *PrometheusUsageCollector) WorkloadUsage(ctx context.Context, namespace, kind, name string) (WorkloadUsage, error) {
+	end := time.Now()
+	start := end.Add(-c.Window)
+	step := c.Client.ComputeStep(ctx, start, end)
+	selector := fmt.Sprintf(`namespace=%q,pod=~%q,container!="",container!="POD"`, namespace, workloadPodNamePattern(kind, name))
+	queries := []struct {
+		query  string
+		sample func(usage *ContainerUsage, value float64)
+	}{
+		{
+			fmt.Sprintf(`max by (pod, container) (max_over_time(rate(container_cpu_usage_seconds_total{%s}[5m])[%s:1m]))`, selector, promModel.Duration(step)),
+			func(usage *ContainerUsage, value float64) { usage.CPU = append(usage.CPU, value) },
+		},
+		{
+			fmt.Sprintf(`max by (pod, container) (max_over_time(container_memory_working_set_bytes{%s}[%s]))`, selector, promModel.Duration(step)),
+			func(usage *ContainerUsage, value float64) { usage.Memory = append(usage.Memory, value) },
+		},
+	}
+
+	usage := WorkloadUsage{}
+	for _, q := range queries {
+		value, err := c.Client.QueryRangeUsingClient(ctx, c.URL, q.query, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		matrix, ok := value.(promModel.Matrix)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result of type %s for query %s", value.Type(), q.query)
+		}
+		for _, stream := range matrix {
+			container := usage.container(string(stream.Metric["container"]))
+			for _, pair := range stream.Values {
+				q.sample(container, float64(pair.Value))
+			}
+		}
+	}
+	return usage, nil
+}
+
+// MetricsServerUsageCollector collects the current usage of workloads from the metrics-server
+// of clusters, a single sample per pod. Prefer Prometheus for recommendations based on history.
+type MetricsServerUsageCollector struct {
+	Clients []kubernetes.Interface
+}
+
+func (c *MetricsServerUsageCollector) Source() string {
+	return ResourceUsageSourceMetricsServer
+}
+
+// podMetricsList is the part of a metrics.k8s.io/v1beta1 PodMetricsList used for recommendations.
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+func (c *MetricsServerUsageCollector) WorkloadUsage(ctx context.Context, namespace, kind, name string) (WorkloadUsage, error) {
+	pods := regexp.MustCompile("^" + workloadPodNamePattern(kind, name) + "$")
+	usage := WorkloadUsage{}
+	for _, client := range c.Clients {
+		byt, err := client.CoreV1().RESTClient().Get().AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").DoRaw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metrics := podMetricsList{}
+		if err := json.Unmarshal(byt, &metrics); err != nil {
+			return nil, ErrUnmarshal(err, "pod metrics")
+		}
+		for _, pod := range metrics.Items {
+			if !pods.MatchString(pod.Metadata.Name) {
+				continue
+			}
+			for _, c := range pod.Containers {
+				container := usage.container(c.Name)
+				container.CPU = append(container.CPU, c.Usage.Cpu().AsApproximateFloat64())
+				container.Memory = append(container.Memory, c.Usage.Memory().AsApproximateFloat64())
+			}
+		}
+	}
+	return usage, nil
+}
+
+// workloadPodNamePattern matches the names of the pods of a workload, which controllers derive
+// from the name of the workload.
+func workloadPodNamePattern(kind, name string) string {
+	name = regexp.QuoteMeta(name)
+	switch kind {
+	case "Pod":
+		return name
+	case "Deployment":
+		return name + "-[a-z0-9]+-[a-z0-9]+"
+	case "StatefulSet":
+		return name + "-[0-9]+"
+	default:
+		return name + "-[a-z0-9]+"
+	}
+}
+
+// ContainerResources are the requests and the limits of a container, eg. {"cpu": "250m", "memory": "256Mi"}.
+type ContainerResources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// ContainerRightSizing is the recommended resources of a container of a workload.
+type ContainerRightSizing struct {
+	Container   string             `json:"container"`
+	Samples     int                `json:"samples"`
+	Current     ContainerResources `json:"current"`
+	Recommended ContainerResources `json:"recommended"`
+}
+
+// ComponentRightSizing is the recommended resources of the containers of a workload of a design,
+// and the requests of all of its replicas before and after applying them.
+type ComponentRightSizing struct {
+	ComponentID uuid.UUID              `json:"component_id"`
+	Component   string                 `json:"component"`
+	Kind        string                 `json:"kind"`
+	Namespace   string                 `json:"namespace"`
+	Containers  []ContainerRightSizing `json:"containers"`
+	Before      ResourceAmounts        `json:"before"`
+	After       ResourceAmounts        `json:"after"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// DesignRightSizing is the resource recommendations for the workloads of a design.
+type DesignRightSizing struct {
+	Design     string                 `json:"design"`
+	Source     string                 `json:"source"`
+	Policy     RightSizingPolicy      `json:"policy"`
+	Components []ComponentRightSizing `json:"components"`
+	Before     ResourceAmounts        `json:"before"`
+	After      ResourceAmounts        `json:"after"`
+}
+
+// RecommendDesignResources computes the requests and the limits of the containers of the workloads
+// of the design from their usage. Workloads which are not deployed, or whose usage could not be
+// collected, are reported with an error and no recommendation. The design is left untouched.
+func RecommendDesignResources(ctx context.Context, patternFile *pattern.PatternFile, collector ResourceUsageCollector, policy RightSizingPolicy) (*DesignRightSizing, error) {
+	policy = policy.withDefaults()
+	rs := &DesignRightSizing{Design: patternFile.Name, Source: collector.Source(), Policy: policy, Components: []ComponentRightSizing{}}
+
+	for _, comp := range patternFile.Components {
+		if comp == nil || !isSimulatedWorkload(comp.Component.Kind) {
+			continue
+		}
+		kind := comp.Component.Kind
+		spec, _ := comp.Configuration["spec"].(map[string]interface{})
+		before, err := json.Marshal(spec)
+		if err != nil {
+			return nil, err
+		}
+		rec := ComponentRightSizing{ComponentID: comp.Id, Component: comp.DisplayName, Kind: kind, Namespace: "default", Containers: []ContainerRightSizing{}}
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		if ns, _ := metadata["namespace"].(string); ns != "" {
+			rec.Namespace = ns
+		}
+		name, _ := metadata["name"].(string)
+		if name == "" {
+			name = comp.DisplayName
+		}
+		_, rec.Before = workloadAmounts(kind, string(before))
+		rec.After = rec.Before
+
+		usage, err := collector.WorkloadUsage(ctx, rec.Namespace, kind, name)
+		if err != nil {
+			rec.Error = fmt.Sprintf("the usage of the workload could not be collected: %v", err)
+		}
+		for _, container := range workloadContainers(kind, string(before)) {
+			samples := usage[container.Name]
+			if samples == nil || len(samples.CPU)+len(samples.Memory) == 0 {
+				continue
+			}
+			rec.Containers = append(rec.Containers, ContainerRightSizing{
+				Container:   container.Name,
+				Samples:     max(len(samples.CPU), len(samples.Memory)),
+				Current:     container.Resources,
+				Recommended: recommendContainerResources(samples, policy),
+			})
+		}
+		if len(rec.Containers) == 0 && rec.Error == "" {
+			rec.Error = "no usage of the workload was found, it may not be deployed"
+		}
+
+		if len(rec.Containers) > 0 {
+			after, err := applyContainerResources(kind, before, rec.Containers)
+			if err != nil {
+				return nil, fmt.Errorf("component %q: %w", comp.DisplayName, err)
+			}
+			_, rec.After = workloadAmounts(kind, string(after))
+		}
+		rs.Before = rs.Before.add(rec.Before)
+		rs.After = rs.After.add(rec.After)
+		rs.Components = append(rs.Components, rec)
+	}
+	return rs, nil
+}
+
+// ApplyDesignRightSizing sets the recommended resources on the containers of the workloads of the
+// design and increments the patch version of the design, the recommendations are updated with the
+// requests of the workloads before and after.
+func ApplyDesignRightSizing(patternFile *pattern.PatternFile, recommendations []ComponentRightSizing) error {
+	for i := range recommendations {
+		rec := &recommendations[i]
+		found := false
+		for _, c := range patternFile.Components {
+			if c == nil || c.Id != rec.ComponentID {
+				continue
+			}
+			found = true
+			if !isSimulatedWorkload(c.Component.Kind) {
+				return fmt.Errorf("component %q is a %s, only workloads can be right-sized", c.DisplayName, c.Component.Kind)
+			}
+			spec, _ := c.Configuration["spec"].(map[string]interface{})
+			before, err := json.Marshal(spec)
+			if err != nil {
+				return err
+			}
+			after, err := applyContainerResources(c.Component.Kind, before, rec.Containers)
+			if err != nil {
+				return fmt.Errorf("component %q: %w", c.DisplayName, err)
+			}
+			updated := map[string]interface{}{}
+			if err := json.Unmarshal(after, &updated); err != nil {
+				return err
+			}
+			c.Configuration["spec"] = updated
+
+			rec.Component, rec.Kind = c.DisplayName, c.Component.Kind
+			_, rec.Before = workloadAmounts(c.Component.Kind, string(before))
+			_, rec.After = workloadAmounts(c.Component.Kind, string(after))
+		}
+		if !found {
+			return fmt.Errorf("component %s is not a component of the design", rec.ComponentID)
+		}
+	}
+	patternFile.Version = NextPatchVersion(patternFile.Version)
+	return nil
+}
+
+// workloadContainer is a container of the pod template of a workload.
+type workloadContainer struct {
+	Name      string             `json:"name"`
+	Resources ContainerResources `json:"resources"`
+}
+
+func workloadContainers(kind, spec string) []workloadContainer {
+	var workload struct {
+		Containers []workloadContainer `json:"containers"`
+		Template   struct {
+			Spec struct {
+				Containers []workloadContainer `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	}
+	if err := json.Unmarshal([]byte(spec), &workload); err != nil {
+		return nil
+	}
+	if kind == "Pod" {
+		return workload.Containers
+	}
+	return workload.Template.Spec.Containers
+}
+
+// applyContainerResources returns the spec of the workload with the recommended resources set on
+// its containers.
+func applyContainerResources(kind string, spec []byte, containers []ContainerRightSizing) ([]byte, error) {
+	changed := map[string]interface{}{}
+	if err := json.Unmarshal(spec, &changed); err != nil || changed == nil {
+		changed = map[string]interface{}{}
+	}
+	podSpec := changed
+	if kind != "Pod" {
+		template, _ := changed["template"].(map[string]interface{})
+		podSpec, _ = template["spec"].(map[string]interface{})
+	}
+	specContainers, _ := podSpec["containers"].([]interface{})
+
+	for _, rec := range containers {
+		found := false
+		for _, c := range specContainers {
+			container, ok := c.(map[string]interface{})
+			if !ok || container["name"] != rec.Container {
+				continue
+			}
+			found = true
+			resources, _ := container["resources"].(map[string]interface{})
+			if resources == nil {
+				resources = map[string]interface{}{}
+			}
+			for field, quantities := range map[string]map[string]string{"requests": rec.Recommended.Requests, "limits": rec.Recommended.Limits} {
+				if len(quantities) == 0 {
+					continue
+				}
+				values, _ := resources[field].(map[string]interface{})
+				if values == nil {
+					values = map[string]interface{}{}
+				}
+				for name, quantity := range quantities {
+					if _, err := resource.ParseQuantity(quantity); err != nil {
+						return nil, fmt.Errorf("container %q: invalid %s %s quantity %q: %w", rec.Container, name, field, quantity, err)
+					}
+					values[name] = quantity
+				}
+				resources[field] = values
+			}
+			container["resources"] = resources
+		}
+		if !found {
+			return nil, fmt.Errorf("the workload has no container %q", rec.Container)
+		}
+	}
+	return json.Marshal(changed)
+}
+
+// recommendContainerResources sets the requests to the percentile of the usage and the limits to
+// its peak, with the margin of the policy.
+func recommendContainerResources(usage *ContainerUsage, policy RightSizingPolicy) ContainerResources {
+	resources := ContainerResources{Requests: map[string]string{}, Limits: map[string]string{}}
+	scale := 1 + policy.Margin
+	if len(usage.CPU) > 0 {
+		request := math.Max(percentile(usage.CPU, policy.RequestPercentile)*scale, minRecommendedCPU)
+		limit := math.Max(percentile(usage.CPU, 100)*scale, request)
+		resources.Requests["cpu"] = cpuQuantity(request)
+		resources.Limits["cpu"] = cpuQuantity(limit)
+	}
+	if len(usage.Memory) > 0 {
+		request := math.Max(percentile(usage.Memory, policy.RequestPercentile)*scale, minRecommendedMemory)
+		limit := math.Max(percentile(usage.Memory, 100)*scale, request)
+		resources.Requests["memory"] = memoryQuantity(request)
+		resources.Limits["memory"] = memoryQuantity(limit)
+	}
+	return resources
+}
+
+// percentile returns the nearest-rank percentile of the samples.
+func percentile(samples []float64, p float64) float64 {
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// cpuQuantity rounds the cores up to the millicore, eg. 250m.
+func cpuQuantity(cores float64) string {
+	return resource.NewMilliQuantity(int64(math.Ceil(cores*1000)), resource.DecimalSI).String()
+}
+
+// memoryQuantity rounds the bytes up to the mebibyte, eg. 256Mi.
+func memoryQuantity(bytes float64) string {
+	return resource.NewQuantity(int64(math.Ceil(bytes/(1<<20)))<<20, resource.BinarySI).String()
+}
+
+var patchVersionPattern = regexp.MustCompile(`^(v?\d+\.\d+\.)(\d+)$`)
+
+// NextPatchVersion increments the patch of a semantic version, versions which are not
+// major.minor.patch start over at 0.0.1.
+func NextPatchVersion(version string) string {
+	match := patchVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return "0.0.1"
+	}
+	patch, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "0.0.1"
+	}
+	return match[1] + strconv.Itoa(patch+1)
+}
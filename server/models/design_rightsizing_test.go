@@ -0,0 +1,227 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// staticUsageCollector returns the usage of the workloads by namespace/name, or the error.
+type staticUsageCollector struct {
+	usage map[string]WorkloadUsage
+	err   error
+}
+
+func (c *staticUsageCollector) Source() string {
+	return "static"
+}
+
+func (c *staticUsageCollector) WorkloadUsage(_ context.Context, namespace, _, name string) (WorkloadUsage, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.usage[namespace+"/"+name], nil
+}
+
+var (
+	rightSizingWebID   = uuid.NewV5(uuid.Nil, "web")
+	rightSizingBatchID = uuid.NewV5(uuid.Nil, "batch")
+)
+
+// newRightSizingTestDesign returns a design with two replicas of web-app in the shop namespace, whose
+// main container requests 500m and 256Mi along with a sidecar, a batch pod and a config map.
+func newRightSizingTestDesign() *pattern.PatternFile {
+	main := map[string]interface{}{
+		"name":      "main",
+		"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m", "memory": "256Mi"}},
+	}
+	return &pattern.PatternFile{Name: "shop", Version: "1.2.3", Components: []*component.ComponentDefinition{
+		{Id: rightSizingWebID, DisplayName: "web", Component: component.Component{Kind: "Deployment"}, Configuration: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web-app", "namespace": "shop"},
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+					main, map[string]interface{}{"name": "sidecar"},
+				}}},
+			},
+		}},
+		{Id: rightSizingBatchID, DisplayName: "batch", Component: component.Component{Kind: "Pod"}, Configuration: map[string]interface{}{
+			"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "main"}}},
+		}},
+		{Id: uuid.NewV5(uuid.Nil, "config"), DisplayName: "config", Component: component.Component{Kind: "ConfigMap"}},
+	}}
+}
+
+func TestRecommendDesignResources(t *testing.T) {
+	policy := RightSizingPolicy{RequestPercentile: 50, Margin: 0.25}
+	collector := &staticUsageCollector{usage: map[string]WorkloadUsage{
+		"shop/web-app": {"main": {CPU: []float64{0.4, 0.2}, Memory: []float64{128 << 20, 64 << 20}}},
+	}}
+
+	rs, err := RecommendDesignResources(context.Background(), newRightSizingTestDesign(), collector, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Components) != 2 {
+		t.Fatalf("expected only the workloads to be right-sized, got %+v", rs.Components)
+	}
+
+	web := rs.Components[0]
+	want := []ContainerRightSizing{{
+		Container: "main",
+		Samples:   2,
+		Current:   ContainerResources{Requests: map[string]string{"cpu": "500m", "memory": "256Mi"}},
+		Recommended: ContainerResources{
+			Requests: map[string]string{"cpu": "250m", "memory": "80Mi"},
+			Limits:   map[string]string{"cpu": "500m", "memory": "160Mi"},
+		},
+	}}
+	if web.Namespace != "shop" || web.Error != "" || !reflect.DeepEqual(web.Containers, want) {
+		t.Errorf("expected the main container of web to be right-sized, got %+v", web)
+	}
+	if !sameAmounts(web.Before, ResourceAmounts{CPU: 1, MemoryGiB: 0.5, Pods: 2}) || !sameAmounts(web.After, ResourceAmounts{CPU: 0.5, MemoryGiB: 0.15625, Pods: 2}) {
+		t.Errorf("before %+v after %+v", web.Before, web.After)
+	}
+
+	batch := rs.Components[1]
+	if len(batch.Containers) != 0 || !strings.Contains(batch.Error, "may not be deployed") || batch.After != batch.Before {
+		t.Errorf("expected no recommendation for the batch pod which is not deployed, got %+v", batch)
+	}
+	if !sameAmounts(rs.Before, web.Before.add(batch.Before)) || !sameAmounts(rs.After, web.After.add(batch.After)) {
+		t.Errorf("expected the totals of the workloads, got before %+v after %+v", rs.Before, rs.After)
+	}
+}
+
+func TestRecommendDesignResources_CollectorError(t *testing.T) {
+	rs, err := RecommendDesignResources(context.Background(), newRightSizingTestDesign(), &staticUsageCollector{err: errors.New("metrics unavailable")}, RightSizingPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Policy.RequestPercentile != defaultRightSizingPercentile || rs.Policy.Margin != defaultRightSizingMargin {
+		t.Errorf("expected the default policy, got %+v", rs.Policy)
+	}
+	for _, rec := range rs.Components {
+		if !strings.Contains(rec.Error, "metrics unavailable") || len(rec.Containers) != 0 {
+			t.Errorf("expected the error of the collector to be reported for %s, got %+v", rec.Component, rec)
+		}
+	}
+}
+
+func TestRecommendContainerResources(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage ContainerUsage
+		want  ContainerResources
+	}{
+		{
+			name:  "minimum",
+			usage: ContainerUsage{CPU: []float64{0.001}, Memory: []float64{1 << 20}},
+			want: ContainerResources{
+				Requests: map[string]string{"cpu": "10m", "memory": "16Mi"},
+				Limits:   map[string]string{"cpu": "10m", "memory": "16Mi"},
+			},
+		},
+		{
+			name:  "percentile and peak",
+			usage: ContainerUsage{CPU: []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 2}},
+			want: ContainerResources{
+				Requests: map[string]string{"cpu": "200m"},
+				Limits:   map[string]string{"cpu": "4"},
+			},
+		},
+		{
+			name:  "memory only",
+			usage: ContainerUsage{Memory: []float64{100 << 20}},
+			want: ContainerResources{
+				Requests: map[string]string{"memory": "200Mi"},
+				Limits:   map[string]string{"memory": "200Mi"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recommendContainerResources(&tt.usage, RightSizingPolicy{RequestPercentile: 90, Margin: 1}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recommendContainerResources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDesignRightSizing(t *testing.T) {
+	recommended := ContainerResources{Requests: map[string]string{"cpu": "250m"}, Limits: map[string]string{"memory": "512Mi"}}
+
+	tests := []struct {
+		name    string
+		rec     ComponentRightSizing
+		wantErr string
+	}{
+		{name: "applied", rec: ComponentRightSizing{ComponentID: rightSizingWebID, Containers: []ContainerRightSizing{{Container: "main", Recommended: recommended}}}},
+		{name: "unknown component", rec: ComponentRightSizing{ComponentID: uuid.NewV5(uuid.Nil, "api")}, wantErr: "not a component of the design"},
+		{name: "not a workload", rec: ComponentRightSizing{ComponentID: uuid.NewV5(uuid.Nil, "config")}, wantErr: "only workloads"},
+		{name: "unknown container", rec: ComponentRightSizing{ComponentID: rightSizingWebID, Containers: []ContainerRightSizing{{Container: "proxy", Recommended: recommended}}}, wantErr: `no container "proxy"`},
+		{
+			name:    "invalid quantity",
+			rec:     ComponentRightSizing{ComponentID: rightSizingWebID, Containers: []ContainerRightSizing{{Container: "main", Recommended: ContainerResources{Requests: map[string]string{"cpu": "a lot"}}}}},
+			wantErr: "invalid cpu requests quantity",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			design := newRightSizingTestDesign()
+			recs := []ComponentRightSizing{tt.rec}
+			err := ApplyDesignRightSizing(design, recs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyDesignRightSizing() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			containers := workloadContainers("Deployment", mustMarshalSpec(t, design.Components[0]))
+			want := ContainerResources{Requests: map[string]string{"cpu": "250m", "memory": "256Mi"}, Limits: map[string]string{"memory": "512Mi"}}
+			if len(containers) != 2 || !reflect.DeepEqual(containers[0].Resources, want) || containers[1].Resources.Requests != nil {
+				t.Errorf("expected only the resources of the main container to be set, got %+v", containers)
+			}
+			if design.Version != "1.2.4" {
+				t.Errorf("version = %q, want 1.2.4", design.Version)
+			}
+			if rec := recs[0]; rec.Component != "web" || !sameAmounts(rec.Before, ResourceAmounts{CPU: 1, MemoryGiB: 0.5, Pods: 2}) || !sameAmounts(rec.After, ResourceAmounts{CPU: 0.5, MemoryGiB: 0.5, Pods: 2}) {
+				t.Errorf("expected the recommendation to be updated with the requests of the workload, got %+v", rec)
+			}
+		})
+	}
+}
+
+func mustMarshalSpec(t *testing.T, comp *component.ComponentDefinition) string {
+	t.Helper()
+	byt, err := json.Marshal(comp.Configuration["spec"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(byt)
+}
+
+func TestNextPatchVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":   "1.2.4",
+		"v0.1.9":  "v0.1.10",
+		"":        "0.0.1",
+		"1.2":     "0.0.1",
+		"1.2.3-a": "0.0.1",
+	}
+	for version, want := range tests {
+		if got := NextPatchVersion(version); got != want {
+			t.Errorf("NextPatchVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
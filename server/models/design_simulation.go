@@ -0,0 +1,415 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// hoursPerMonth is the average number of hours in a month, used to project monthly costs.
+const hoursPerMonth = 730
+
+// DesignScalingChange is a hypothetical change to a workload of a design.
+type DesignScalingChange struct {
+	// Component is the display name of the workload.
+	Component string `json:"component"`
+	Replicas  *int64 `json:"replicas,omitempty"`
+	// Requests are the cpu and memory requested by every container of the workload, eg. {"cpu": "500m", "memory": "512Mi"}.
+	Requests map[string]string `json:"requests,omitempty"`
+}
+
+// DesignSimulationRequest is a design along with the changes to simulate.
+// The design is either a saved design or a design file.
+type DesignSimulationRequest struct {
+	DesignID    *uuid.UUID            `json:"design_id,omitempty"`
+	PatternFile string                `json:"pattern_file,omitempty"`
+	Changes     []DesignScalingChange `json:"changes"`
+	// Deployed tells whether the design is already deployed to the clusters, in which case
+	// only the difference made by the changes is placed on the clusters.
+	Deployed bool      `json:"deployed"`
+	Rates    CostRates `json:"rates"`
+}
+
+// ResourceAmounts are CPU cores, GiB of memory and pods.
+type ResourceAmounts struct {
+	CPU       float64 `json:"cpu"`
+	MemoryGiB float64 `json:"memory_gib"`
+	Pods      float64 `json:"pods"`
+}
+
+func (a ResourceAmounts) add(b ResourceAmounts) ResourceAmounts {
+	return ResourceAmounts{CPU: a.CPU + b.CPU, MemoryGiB: a.MemoryGiB + b.MemoryGiB, Pods: a.Pods + b.Pods}
+}
+
+func (a ResourceAmounts) sub(b ResourceAmounts) ResourceAmounts {
+	return ResourceAmounts{CPU: a.CPU - b.CPU, MemoryGiB: a.MemoryGiB - b.MemoryGiB, Pods: a.Pods - b.Pods}
+}
+
+// SimulatedComponent is a workload of the design before and after the changes.
+type SimulatedComponent struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	// PodBefore and PodAfter are requested by every pod of the workload.
+	PodBefore ResourceAmounts `json:"pod_before"`
+	PodAfter  ResourceAmounts `json:"pod_after"`
+	// Before and After are requested by all the replicas of the workload.
+	Before ResourceAmounts `json:"before"`
+	After  ResourceAmounts `json:"after"`
+}
+
+// ClusterFit is whether the design fits a cluster after the changes.
+type ClusterFit struct {
+	ContextID string `json:"context_id"`
+	Name      string `json:"name"`
+	// Allocatable and Requested are summed over the schedulable nodes of the cluster,
+	// Projected is Requested along with the pods placed by the simulation.
+	Allocatable ResourceAmounts `json:"allocatable"`
+	Requested   ResourceAmounts `json:"requested"`
+	Projected   ResourceAmounts `json:"projected"`
+	Fits        bool            `json:"fits"`
+	// UnschedulablePods are the pods which would not fit on any node.
+	UnschedulablePods int    `json:"unschedulable_pods"`
+	Error             string `json:"error,omitempty"`
+}
+
+// QuotaFit is the consumption of a resource quota of a namespace of the design after the changes.
+// CPU is in cores and memory in GiB.
+type QuotaFit struct {
+	ContextID string  `json:"context_id"`
+	Namespace string  `json:"namespace"`
+	Quota     string  `json:"quota"`
+	Resource  string  `json:"resource"`
+	Hard      float64 `json:"hard"`
+	Used      float64 `json:"used"`
+	Projected float64 `json:"projected"`
+	Exceeded  bool    `json:"exceeded"`
+}
+
+// CostProjection is the monthly cost of the requests of the design before and after the changes.
+type CostProjection struct {
+	MonthlyBefore float64 `json:"monthly_before"`
+	MonthlyAfter  float64 `json:"monthly_after"`
+	MonthlyDelta  float64 `json:"monthly_delta"`
+}
+
+// DesignSimulation reports the projected effect of hypothetical changes to a design, without deploying it.
+type DesignSimulation struct {
+	Design     string               `json:"design"`
+	Components []SimulatedComponent `json:"components"`
+	Before     ResourceAmounts      `json:"before"`
+	After      ResourceAmounts      `json:"after"`
+	Clusters   []ClusterFit         `json:"clusters"`
+	Quotas     []QuotaFit           `json:"quotas"`
+	Rates      CostRates            `json:"rates"`
+	Cost       CostProjection       `json:"cost"`
+}
+
+// SimulateDesignChanges returns the requests of the workloads of the design before and after the changes.
+// The design is left untouched.
+func SimulateDesignChanges(patternFile *pattern.PatternFile, changes []DesignScalingChange) (*DesignSimulation, error) {
+	byName := make(map[string]DesignScalingChange, len(changes))
+	for _, change := range changes {
+		if _, ok := byName[change.Component]; ok {
+			return nil, fmt.Errorf("component %q is changed more than once", change.Component)
+		}
+		if change.Replicas != nil && *change.Replicas < 0 {
+			return nil, fmt.Errorf("component %q cannot have negative replicas", change.Component)
+		}
+		for name, quantity := range change.Requests {
+			if name != "cpu" && name != "memory" {
+				return nil, fmt.Errorf("component %q requests %q, only cpu and memory can be changed", change.Component, name)
+			}
+			if _, err := resource.ParseQuantity(quantity); err != nil {
+				return nil, fmt.Errorf("component %q requests an invalid %s quantity %q: %w", change.Component, name, quantity, err)
+			}
+		}
+		byName[change.Component] = change
+	}
+
+	sim := &DesignSimulation{Design: patternFile.Name, Components: []SimulatedComponent{}, Clusters: []ClusterFit{}, Quotas: []QuotaFit{}}
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		kind := comp.Component.Kind
+		change, changed := byName[comp.DisplayName]
+		delete(byName, comp.DisplayName)
+		if !isSimulatedWorkload(kind) {
+			if changed {
+				return nil, fmt.Errorf("component %q is a %s, only workloads can be changed", comp.DisplayName, kind)
+			}
+			continue
+		}
+
+		spec, _ := comp.Configuration["spec"].(map[string]interface{})
+		before, err := json.Marshal(spec)
+		if err != nil {
+			return nil, err
+		}
+		after := before
+		if changed {
+			if after, err = applyScalingChange(kind, before, change); err != nil {
+				return nil, fmt.Errorf("component %q: %w", comp.DisplayName, err)
+			}
+		}
+
+		namespace := "default"
+		if metadata, ok := comp.Configuration["metadata"].(map[string]interface{}); ok {
+			if ns, ok := metadata["namespace"].(string); ok && ns != "" {
+				namespace = ns
+			}
+		}
+		simulated := SimulatedComponent{Name: comp.DisplayName, Kind: kind, Namespace: namespace}
+		simulated.PodBefore, simulated.Before = workloadAmounts(kind, string(before))
+		simulated.PodAfter, simulated.After = workloadAmounts(kind, string(after))
+		sim.Components = append(sim.Components, simulated)
+		sim.Before = sim.Before.add(simulated.Before)
+		sim.After = sim.After.add(simulated.After)
+	}
+
+	if len(byName) > 0 {
+		unknown := make([]string, 0, len(byName))
+		for name := range byName {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%q are not components of the design", unknown)
+	}
+	return sim, nil
+}
+
+// EstimateCost projects the monthly cost of the requests of the design at the given rates.
+func (sim *DesignSimulation) EstimateCost(rates CostRates) {
+	cost := func(a ResourceAmounts) float64 {
+		return (a.CPU*rates.CPUCoreHour + a.MemoryGiB*rates.MemoryGiBHour) * hoursPerMonth
+	}
+	sim.Rates = rates
+	sim.Cost = CostProjection{
+		MonthlyBefore: cost(sim.Before),
+		MonthlyAfter:  cost(sim.After),
+	}
+	sim.Cost.MonthlyDelta = sim.Cost.MonthlyAfter - sim.Cost.MonthlyBefore
+}
+
+// FitCluster projects the design on the cluster after the changes. The pods of the design are
+// placed on the free capacity of the schedulable nodes, first fit by decreasing CPU, and their
+// requests are added to the resource quotas of their namespaces. When the design is deployed,
+// only the additional replicas are placed and only the difference in requests is added.
+func (sim *DesignSimulation) FitCluster(ctx context.Context, contextID, name string, client kubernetes.Interface, deployed bool) error {
+	fit := ClusterFit{ContextID: contextID, Name: name}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	if err != nil {
+		return err
+	}
+
+	requestedByNode := map[string]ResourceAmounts{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != "" {
+			requestedByNode[pod.Spec.NodeName] = requestedByNode[pod.Spec.NodeName].add(podAmounts(pod))
+		}
+	}
+
+	free := []ResourceAmounts{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		allocatable := ResourceAmounts{
+			CPU:       node.Status.Allocatable.Cpu().AsApproximateFloat64(),
+			MemoryGiB: node.Status.Allocatable.Memory().AsApproximateFloat64() / (1 << 30),
+			Pods:      node.Status.Allocatable.Pods().AsApproximateFloat64(),
+		}
+		requested := requestedByNode[node.Name]
+		fit.Allocatable = fit.Allocatable.add(allocatable)
+		fit.Requested = fit.Requested.add(requested)
+		free = append(free, allocatable.sub(requested))
+	}
+
+	placed := ResourceAmounts{}
+	for _, pod := range sim.podsToPlace(deployed) {
+		scheduled := false
+		for i := range free {
+			if pod.CPU <= free[i].CPU && pod.MemoryGiB <= free[i].MemoryGiB && free[i].Pods >= 1 {
+				free[i] = free[i].sub(pod)
+				placed = placed.add(pod)
+				scheduled = true
+				break
+			}
+		}
+		if !scheduled {
+			fit.UnschedulablePods++
+		}
+	}
+	fit.Projected = fit.Requested.add(placed)
+	fit.Fits = fit.UnschedulablePods == 0
+	sim.Clusters = append(sim.Clusters, fit)
+
+	return sim.fitQuotas(ctx, contextID, client, deployed)
+}
+
+// podsToPlace returns the pods the simulation places on a cluster, largest first.
+func (sim *DesignSimulation) podsToPlace(deployed bool) []ResourceAmounts {
+	pods := []ResourceAmounts{}
+	for _, comp := range sim.Components {
+		count := comp.After.Pods
+		if deployed {
+			count -= comp.Before.Pods
+		}
+		for i := 0; i < int(count); i++ {
+			pods = append(pods, comp.PodAfter)
+		}
+	}
+	sort.SliceStable(pods, func(i, j int) bool { return pods[i].CPU > pods[j].CPU })
+	return pods
+}
+
+func (sim *DesignSimulation) fitQuotas(ctx context.Context, contextID string, client kubernetes.Interface, deployed bool) error {
+	deltas := map[string]ResourceAmounts{}
+	for _, comp := range sim.Components {
+		delta := comp.After
+		if deployed {
+			delta = delta.sub(comp.Before)
+		}
+		deltas[comp.Namespace] = deltas[comp.Namespace].add(delta)
+	}
+
+	namespaces := make([]string, 0, len(deltas))
+	for namespace := range deltas {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		delta := deltas[namespace]
+		for _, quota := range quotas.Items {
+			for _, res := range []corev1.ResourceName{corev1.ResourceRequestsCPU, corev1.ResourceCPU, corev1.ResourceRequestsMemory, corev1.ResourceMemory, corev1.ResourcePods} {
+				hard, ok := quota.Status.Hard[res]
+				if !ok {
+					if hard, ok = quota.Spec.Hard[res]; !ok {
+						continue
+					}
+				}
+				used := quota.Status.Used[res]
+				qf := QuotaFit{
+					ContextID: contextID,
+					Namespace: namespace,
+					Quota:     quota.Name,
+					Resource:  string(res),
+					Hard:      hard.AsApproximateFloat64(),
+					Used:      used.AsApproximateFloat64(),
+				}
+				switch res {
+				case corev1.ResourceRequestsCPU, corev1.ResourceCPU:
+					qf.Projected = qf.Used + delta.CPU
+				case corev1.ResourceRequestsMemory, corev1.ResourceMemory:
+					qf.Hard /= 1 << 30
+					qf.Used /= 1 << 30
+					qf.Projected = qf.Used + delta.MemoryGiB
+				case corev1.ResourcePods:
+					qf.Projected = qf.Used + delta.Pods
+				}
+				qf.Exceeded = qf.Projected > qf.Hard
+				sim.Quotas = append(sim.Quotas, qf)
+			}
+		}
+	}
+	return nil
+}
+
+func isSimulatedWorkload(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "DaemonSet", "Pod":
+		return true
+	}
+	return false
+}
+
+// workloadAmounts returns the requests of a pod of the workload and of all of its replicas.
+func workloadAmounts(kind, spec string) (pod, total ResourceAmounts) {
+	cpu, memory, replicas := workloadPodRequests(kind, spec)
+	pod = ResourceAmounts{CPU: cpu, MemoryGiB: memory, Pods: 1}
+	total = ResourceAmounts{CPU: cpu * replicas, MemoryGiB: memory * replicas, Pods: replicas}
+	return pod, total
+}
+
+// applyScalingChange returns the spec of the workload with the change applied.
+func applyScalingChange(kind string, spec []byte, change DesignScalingChange) ([]byte, error) {
+	changed := map[string]interface{}{}
+	if err := json.Unmarshal(spec, &changed); err != nil || changed == nil {
+		changed = map[string]interface{}{}
+	}
+
+	if change.Replicas != nil {
+		if kind == "Pod" || kind == "DaemonSet" {
+			return nil, fmt.Errorf("the replicas of a %s cannot be changed", kind)
+		}
+		changed["replicas"] = *change.Replicas
+	}
+
+	if len(change.Requests) > 0 {
+		podSpec := changed
+		if kind != "Pod" {
+			template, _ := changed["template"].(map[string]interface{})
+			podSpec, _ = template["spec"].(map[string]interface{})
+		}
+		containers, _ := podSpec["containers"].([]interface{})
+		if len(containers) == 0 {
+			return nil, fmt.Errorf("the workload has no containers to change the requests of")
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			resources, _ := container["resources"].(map[string]interface{})
+			if resources == nil {
+				resources = map[string]interface{}{}
+			}
+			requests, _ := resources["requests"].(map[string]interface{})
+			if requests == nil {
+				requests = map[string]interface{}{}
+			}
+			for name, quantity := range change.Requests {
+				requests[name] = quantity
+			}
+			resources["requests"] = requests
+			container["resources"] = resources
+		}
+	}
+	return json.Marshal(changed)
+}
+
+// podAmounts returns the requests of the pod: the requests of its containers, or of its
+// largest init container when larger, since init containers run before the containers.
+func podAmounts(pod *corev1.Pod) ResourceAmounts {
+	var cpu, memory, initCPU, initMemory float64
+	for _, c := range pod.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().AsApproximateFloat64()
+		memory += c.Resources.Requests.Memory().AsApproximateFloat64()
+	}
+	for _, c := range pod.Spec.InitContainers {
+		initCPU = max(initCPU, c.Resources.Requests.Cpu().AsApproximateFloat64())
+		initMemory = max(initMemory, c.Resources.Requests.Memory().AsApproximateFloat64())
+	}
+	return ResourceAmounts{CPU: max(cpu, initCPU), MemoryGiB: max(memory, initMemory) / (1 << 30), Pods: 1}
+}
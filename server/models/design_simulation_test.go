@@ -0,0 +1,189 @@
+package models
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newSimulationTestDesign returns a design with two replicas of web requesting 500m and 1Gi in the shop namespace,
+// an agent daemon set requesting 100m and 128Mi, and a config map.
+func newSimulationTestDesign() *pattern.PatternFile {
+	containers := func(cpu, memory string) []interface{} {
+		return []interface{}{map[string]interface{}{
+			"name":      "main",
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu, "memory": memory}},
+		}}
+	}
+	return &pattern.PatternFile{Name: "shop", Components: []*component.ComponentDefinition{
+		{DisplayName: "web", Component: component.Component{Kind: "Deployment"}, Configuration: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": "shop"},
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers("500m", "1Gi")}},
+			},
+		}},
+		{DisplayName: "agent", Component: component.Component{Kind: "DaemonSet"}, Configuration: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers("100m", "128Mi")}},
+			},
+		}},
+		{DisplayName: "config", Component: component.Component{Kind: "ConfigMap"}},
+	}}
+}
+
+func sameAmounts(a, b ResourceAmounts) bool {
+	const epsilon = 1e-6
+	return math.Abs(a.CPU-b.CPU) < epsilon && math.Abs(a.MemoryGiB-b.MemoryGiB) < epsilon && math.Abs(a.Pods-b.Pods) < epsilon
+}
+
+func TestSimulateDesignChanges(t *testing.T) {
+	replicas := func(n int64) *int64 { return &n }
+
+	tests := []struct {
+		name       string
+		changes    []DesignScalingChange
+		wantErr    string
+		wantBefore ResourceAmounts
+		wantAfter  ResourceAmounts
+	}{
+		{
+			name:       "no changes",
+			wantBefore: ResourceAmounts{CPU: 1.1, MemoryGiB: 2.125, Pods: 3},
+			wantAfter:  ResourceAmounts{CPU: 1.1, MemoryGiB: 2.125, Pods: 3},
+		},
+		{
+			name:       "scaled and resized",
+			changes:    []DesignScalingChange{{Component: "web", Replicas: replicas(3), Requests: map[string]string{"cpu": "1"}}},
+			wantBefore: ResourceAmounts{CPU: 1.1, MemoryGiB: 2.125, Pods: 3},
+			wantAfter:  ResourceAmounts{CPU: 3.1, MemoryGiB: 3.125, Pods: 4},
+		},
+		{
+			name:       "scaled to zero",
+			changes:    []DesignScalingChange{{Component: "web", Replicas: replicas(0)}},
+			wantBefore: ResourceAmounts{CPU: 1.1, MemoryGiB: 2.125, Pods: 3},
+			wantAfter:  ResourceAmounts{CPU: 0.1, MemoryGiB: 0.125, Pods: 1},
+		},
+		{name: "changed twice", changes: []DesignScalingChange{{Component: "web"}, {Component: "web"}}, wantErr: "more than once"},
+		{name: "negative replicas", changes: []DesignScalingChange{{Component: "web", Replicas: replicas(-1)}}, wantErr: "negative replicas"},
+		{name: "other requests", changes: []DesignScalingChange{{Component: "web", Requests: map[string]string{"gpu": "1"}}}, wantErr: "only cpu and memory"},
+		{name: "invalid quantity", changes: []DesignScalingChange{{Component: "web", Requests: map[string]string{"cpu": "a lot"}}}, wantErr: "invalid cpu quantity"},
+		{name: "not a workload", changes: []DesignScalingChange{{Component: "config", Replicas: replicas(2)}}, wantErr: "only workloads"},
+		{name: "unknown component", changes: []DesignScalingChange{{Component: "api"}}, wantErr: "not components of the design"},
+		{name: "daemon set replicas", changes: []DesignScalingChange{{Component: "agent", Replicas: replicas(2)}}, wantErr: "replicas of a DaemonSet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			design := newSimulationTestDesign()
+			sim, err := SimulateDesignChanges(design, tt.changes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SimulateDesignChanges() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sim.Components) != 2 {
+				t.Fatalf("expected only the workloads to be simulated, got %+v", sim.Components)
+			}
+			if !sameAmounts(sim.Before, tt.wantBefore) || !sameAmounts(sim.After, tt.wantAfter) {
+				t.Errorf("before %+v after %+v, want %+v and %+v", sim.Before, sim.After, tt.wantBefore, tt.wantAfter)
+			}
+			if replicas := design.Components[0].Configuration["spec"].(map[string]interface{})["replicas"]; replicas != 2 {
+				t.Errorf("expected the design to be left untouched, got %v replicas", replicas)
+			}
+		})
+	}
+}
+
+func TestDesignSimulationEstimateCost(t *testing.T) {
+	sim := &DesignSimulation{Before: ResourceAmounts{CPU: 1, MemoryGiB: 2}, After: ResourceAmounts{CPU: 2, MemoryGiB: 2}}
+	sim.EstimateCost(CostRates{CPUCoreHour: 0.5, MemoryGiBHour: 0.25})
+
+	want := CostProjection{MonthlyBefore: 730, MonthlyAfter: 1095, MonthlyDelta: 365}
+	if sim.Cost != want {
+		t.Errorf("cost = %+v, want %+v", sim.Cost, want)
+	}
+}
+
+func TestDesignSimulationFitCluster(t *testing.T) {
+	node := func(name string, cpu, memory string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			}},
+		}
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-a",
+			// the init container requests more than the containers
+			InitContainers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}}},
+			Containers:     []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}}}},
+		},
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "shop"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("3")}},
+		Status:     corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")}},
+	}
+
+	tests := []struct {
+		name              string
+		deployed          bool
+		wantUnschedulable int
+		wantQuota         float64
+	}{
+		// the three pods of web take the 3 cores left, the agent does not fit
+		{name: "new", wantUnschedulable: 1, wantQuota: 4},
+		// only the additional replica of web is placed, and its additional requests are counted
+		{name: "deployed", deployed: true, wantQuota: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(node("node-a", "4", "8Gi", false), node("node-b", "64", "256Gi", true), running, quota)
+			replicas := int64(3)
+			sim, err := SimulateDesignChanges(newSimulationTestDesign(), []DesignScalingChange{{Component: "web", Replicas: &replicas, Requests: map[string]string{"cpu": "1"}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := sim.FitCluster(context.Background(), "ctx", "kind", client, tt.deployed); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sim.Clusters) != 1 {
+				t.Fatalf("expected the cluster to be fitted, got %+v", sim.Clusters)
+			}
+			fit := sim.Clusters[0]
+			if !sameAmounts(fit.Allocatable, ResourceAmounts{CPU: 4, MemoryGiB: 8, Pods: 110}) || !sameAmounts(fit.Requested, ResourceAmounts{CPU: 1, Pods: 1}) {
+				t.Errorf("expected only the schedulable node to be counted, got %+v requested %+v", fit.Allocatable, fit.Requested)
+			}
+			if fit.UnschedulablePods != tt.wantUnschedulable || fit.Fits != (tt.wantUnschedulable == 0) {
+				t.Errorf("%d unschedulable pods, fits %t, want %d", fit.UnschedulablePods, fit.Fits, tt.wantUnschedulable)
+			}
+			if len(sim.Quotas) != 1 {
+				t.Fatalf("expected the quota of the namespace of web, got %+v", sim.Quotas)
+			}
+			q := sim.Quotas[0]
+			if q.Resource != string(corev1.ResourceRequestsCPU) || q.Hard != 3 || q.Used != 1 || math.Abs(q.Projected-tt.wantQuota) > 1e-6 || q.Exceeded != (tt.wantQuota > 3) {
+				t.Errorf("quota = %+v, want %g projected", q, tt.wantQuota)
+			}
+		})
+	}
+}
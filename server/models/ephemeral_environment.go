@@ -0,0 +1,169 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultEphemeralWarningHours is how long before being undeployed users are warned by default.
+const DefaultEphemeralWarningHours = 1
+
+// EphemeralEnvironmentPolicy marks an environment as ephemeral. Designs deployed to the
+// connections of an ephemeral environment are undeployed after IdleHours without activity;
+// their owners are warned WarningHours before.
+type EphemeralEnvironmentPolicy struct {
+	EnvironmentID uuid.UUID `json:"environment_id" gorm:"primarykey"`
+	IdleHours     int       `json:"idle_hours"`
+	WarningHours  int       `json:"warning_hours"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// EphemeralDeployment is a design deployed to an ephemeral environment.
+type EphemeralDeployment struct {
+	ID             uuid.UUID  `json:"id" gorm:"primarykey"`
+	EnvironmentID  uuid.UUID  `json:"environment_id" gorm:"uniqueIndex:idx_ephemeral_deployment_env_design"`
+	DesignID       uuid.UUID  `json:"design_id" gorm:"uniqueIndex:idx_ephemeral_deployment_env_design"`
+	DesignName     string     `json:"design_name"`
+	UserID         uuid.UUID  `json:"user_id"`
+	PatternFile    string     `json:"-"`
+	DeployedAt     time.Time  `json:"deployed_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	WarnedAt       *time.Time `json:"warned_at,omitempty"`
+
+	// Provider, Token and Contexts are the session the design was deployed with,
+	// used to undeploy it on behalf of its owner.
+	Provider string       `json:"-"`
+	Token    string       `json:"-"`
+	Contexts []K8sContext `json:"-" gorm:"type:bytes;serializer:json"`
+}
+
+// UndeployAt returns when the deployment is undeployed unless there is further activity.
+func (ed *EphemeralDeployment) UndeployAt(policy *EphemeralEnvironmentPolicy) time.Time {
+	return ed.LastActivityAt.Add(time.Duration(policy.IdleHours) * time.Hour)
+}
+
+// EphemeralEnvironmentPersister is the persister for ephemeral environment policies and deployments
+type EphemeralEnvironmentPersister struct {
+	DB *database.Handler
+}
+
+// SavePolicy marks the environment as ephemeral, or updates its policy.
+func (eep *EphemeralEnvironmentPersister) SavePolicy(policy *EphemeralEnvironmentPolicy) error {
+	return eep.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "environment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"idle_hours", "warning_hours", "updated_at"}),
+	}).Create(policy).Error
+}
+
+// GetPolicy returns the policy of the environment, nil if the environment is not ephemeral.
+func (eep *EphemeralEnvironmentPersister) GetPolicy(environmentID uuid.UUID) (*EphemeralEnvironmentPolicy, error) {
+	var policy EphemeralEnvironmentPolicy
+	err := eep.DB.Where("environment_id = ?", environmentID).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// DeletePolicy unmarks the environment as ephemeral and stops tracking its deployments.
+func (eep *EphemeralEnvironmentPersister) DeletePolicy(environmentID uuid.UUID) error {
+	return eep.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("environment_id = ?", environmentID).Delete(&EphemeralDeployment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("environment_id = ?", environmentID).Delete(&EphemeralEnvironmentPolicy{}).Error
+	})
+}
+
+// GetEphemeralEnvironmentsOfConnections returns the ids of the ephemeral environments
+// the given connections belong to.
+func (eep *EphemeralEnvironmentPersister) GetEphemeralEnvironmentsOfConnections(connectionIDs []string) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	if len(connectionIDs) == 0 {
+		return ids, nil
+	}
+	err := eep.DB.Table("environment_connection_mappings").
+		Joins("JOIN ephemeral_environment_policies ON ephemeral_environment_policies.environment_id = environment_connection_mappings.environment_id").
+		Where("environment_connection_mappings.connection_id IN ? AND environment_connection_mappings.deleted_at IS NULL", connectionIDs).
+		Distinct().
+		Pluck("environment_connection_mappings.environment_id", &ids).Error
+	return ids, err
+}
+
+// RecordDeployment starts tracking the activity of the design deployed to the environment.
+// Deploying a design again resets its activity.
+func (eep *EphemeralEnvironmentPersister) RecordDeployment(deployment *EphemeralDeployment) error {
+	if deployment.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		deployment.ID = id
+	}
+	return eep.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "environment_id"}, {Name: "design_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"design_name", "user_id", "pattern_file", "deployed_at", "last_activity_at", "warned_at", "provider", "token", "contexts"}),
+	}).Create(deployment).Error
+}
+
+// RemoveDeployment stops tracking the design in every environment.
+func (eep *EphemeralEnvironmentPersister) RemoveDeployment(designID uuid.UUID) error {
+	return eep.DB.Where("design_id = ?", designID).Delete(&EphemeralDeployment{}).Error
+}
+
+// GetDeployments returns the tracked deployments, of the given environment if not nil.
+func (eep *EphemeralEnvironmentPersister) GetDeployments(environmentID uuid.UUID) ([]EphemeralDeployment, error) {
+	deployments := []EphemeralDeployment{}
+	query := eep.DB.Order("last_activity_at")
+	if environmentID != uuid.Nil {
+		query = query.Where("environment_id = ?", environmentID)
+	}
+	err := query.Find(&deployments).Error
+	return deployments, err
+}
+
+// GetDeployment returns the deployment of the design to the environment, nil if the design is not tracked.
+func (eep *EphemeralEnvironmentPersister) GetDeployment(environmentID, designID uuid.UUID) (*EphemeralDeployment, error) {
+	var deployment EphemeralDeployment
+	err := eep.DB.Where("environment_id = ? AND design_id = ?", environmentID, designID).First(&deployment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// RecordActivity records activity of the design in the environment at the given time,
+// postponing its undeployment.
+func (eep *EphemeralEnvironmentPersister) RecordActivity(environmentID, designID uuid.UUID, at time.Time) error {
+	return eep.DB.Model(&EphemeralDeployment{}).
+		Where("environment_id = ? AND design_id = ? AND last_activity_at < ?", environmentID, designID, at).
+		Updates(map[string]interface{}{"last_activity_at": at, "warned_at": nil}).Error
+}
+
+// MarkWarned records that the owner of the deployment was warned of its undeployment.
+func (eep *EphemeralEnvironmentPersister) MarkWarned(id uuid.UUID, at time.Time) error {
+	return eep.DB.Model(&EphemeralDeployment{}).Where("id = ?", id).Update("warned_at", at).Error
+}
+
+// GetLastDesignEventTime returns the time of the latest event acted upon the design, if any.
+func (eep *EphemeralEnvironmentPersister) GetLastDesignEventTime(designID uuid.UUID) (*time.Time, error) {
+	var last []time.Time
+	err := eep.DB.Table("events").Where("acted_upon = ?", designID).
+		Order("created_at desc").Limit(1).Pluck("created_at", &last).Error
+	if err != nil || len(last) == 0 {
+		return nil, err
+	}
+	return &last[0], nil
+}
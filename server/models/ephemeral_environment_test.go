@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestEphemeralDeploymentSession(t *testing.T) {
+	eep := &EphemeralEnvironmentPersister{DB: newTestDB(t, &EphemeralDeployment{})}
+	deployment := &EphemeralDeployment{
+		EnvironmentID:  uuid.Must(uuid.NewV4()),
+		DesignID:       uuid.Must(uuid.NewV4()),
+		UserID:         uuid.Must(uuid.NewV4()),
+		DeployedAt:     time.Now(),
+		LastActivityAt: time.Now(),
+		Provider:       "None",
+		Token:          "secret-token",
+		Contexts:       []K8sContext{{ID: "ctx", Name: "kind", ConnectionID: "conn"}},
+	}
+	if err := eep.RecordDeployment(deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := eep.GetDeployment(deployment.EnvironmentID, deployment.DesignID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Provider != deployment.Provider || got.Token != deployment.Token || !reflect.DeepEqual(got.Contexts, deployment.Contexts) {
+		t.Errorf("session = %q %q %+v, want %q %q %+v", got.Provider, got.Token, got.Contexts, deployment.Provider, deployment.Token, deployment.Contexts)
+	}
+
+	byt, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(byt), deployment.Token) {
+		t.Errorf("the token of the deployment is serialized: %s", byt)
+	}
+
+	missing, err := eep.GetDeployment(deployment.EnvironmentID, uuid.Must(uuid.NewV4()))
+	if err != nil || missing != nil {
+		t.Errorf("GetDeployment of an untracked design = %+v, %v", missing, err)
+	}
+}
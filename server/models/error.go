@@ -137,6 +137,17 @@ const (
 	ErrImportFailureCode                  = "meshery-server-1359"
 	ErrMarshallingDesignIntoYAMLCode      = "meshery-server-1135"
 	ErrStatusCodeCode                     = "meshery-server-1368"
+	ErrPullRegistrySnapshotCode           = "meshery-server-1369"
+	ErrPinRegistrySnapshotCode            = "meshery-server-1370"
+	ErrInvalidRegistrySnapshotVersionCode = "meshery-server-1427"
+	ErrAnalyzeDesignImpactCode            = "meshery-server-1371"
+	ErrEvaluateDesignPoliciesCode         = "meshery-server-1386"
+	ErrInvalidWorkflowCode                = "meshery-server-1399"
+	ErrReportingDBCode                    = "meshery-server-1417"
+	ErrInvalidAlertRuleCode               = "meshery-server-1418"
+	ErrInvalidDashboardCode               = "meshery-server-1423"
+	ErrInvalidRegistryUpdateCode          = "meshery-server-1426"
+	ErrRegistryUpdateSourceNotAllowedCode = "meshery-server-1430"
 )
 
 var (
@@ -609,3 +620,47 @@ func ErrImportFailure(hostname string, failedMsg string) error {
 func ErrMarshallingDesignIntoYAML(err error) error {
 	return errors.New(ErrMarshallingDesignIntoYAMLCode, errors.Alert, []string{"Failed to marshal design into YAML"}, []string{err.Error()}, []string{"unable to marshal design into YAML", "design may be corrupted"}, []string{"check if the design is valid and not corrupted"})
 }
+
+func ErrPullRegistrySnapshot(err error, version string) error {
+	return errors.New(ErrPullRegistrySnapshotCode, errors.Alert, []string{fmt.Sprintf("Failed to pull registry snapshot %s", version)}, []string{err.Error()}, []string{"Registry snapshot version does not exist", "OCI registry is not reachable", "Snapshot artifact is corrupted"}, []string{"Verify the snapshot version and the REGISTRY_SNAPSHOT_REGISTRY and REGISTRY_SNAPSHOT_REPOSITORY settings", "Ensure Meshery Server can reach the OCI registry"})
+}
+
+func ErrPinRegistrySnapshot(err error) error {
+	return errors.New(ErrPinRegistrySnapshotCode, errors.Alert, []string{"Failed to record the pinned registry snapshot"}, []string{err.Error()}, []string{"Pinned snapshot record is corrupted", "Insufficient permissions on the Meshery user data folder"}, []string{"Remove registry-snapshot.json from the Meshery user data folder and pin the snapshot again", "Ensure Meshery Server can write to its user data folder"})
+}
+
+func ErrInvalidRegistrySnapshotVersion(version string) error {
+	return errors.New(ErrInvalidRegistrySnapshotVersionCode, errors.Alert, []string{fmt.Sprintf("Invalid registry snapshot version %q", version)}, []string{"The version of a registry snapshot is the tag of its OCI artifact, made of up to 128 letters, digits, underscores, periods and dashes, not starting with a period or a dash"}, []string{"The version is empty or contains path separators or other characters not allowed in an OCI tag"}, []string{"Set the version to the tag of a published registry snapshot, eg. v0.8.0"})
+}
+
+func ErrAnalyzeDesignImpact(err error) error {
+	return errors.New(ErrAnalyzeDesignImpactCode, errors.Alert, []string{"Failed to analyze the impact of registry components on stored designs"}, []string{err.Error()}, []string{"Designs could not be read from the database"}, []string{"Ensure the Meshery database is reachable and try again"})
+}
+
+func ErrEvaluateDesignPolicies(err error) error {
+	return errors.New(ErrEvaluateDesignPoliciesCode, errors.Alert, []string{"Failed to evaluate design policies"}, []string{err.Error()}, []string{"The policies could not be loaded from the policy directory", "The policy query is invalid"}, []string{"Ensure the policies are valid rego", "Ensure the policy query refers to a rule defined by the policies"})
+}
+
+func ErrInvalidWorkflow(err error) error {
+	return errors.New(ErrInvalidWorkflowCode, errors.Alert, []string{"Invalid workflow"}, []string{err.Error()}, []string{"The workflow is not valid YAML or has unknown fields", "A step has an unknown type or lacks the fields its type requires"}, []string{"Ensure the workflow has a name and steps, each with a name and a type", "Ensure every step sets the fields of its type, eg. design for deploy steps"})
+}
+
+func ErrReportingDB(err error) error {
+	return errors.New(ErrReportingDBCode, errors.Alert, []string{"The reporting database is not available, reports are read from the primary database"}, []string{err.Error()}, []string{"REPORTING_DB_MODE is not primary, replica or snapshot", "The replica set in REPORTING_DB_FILE does not exist or cannot be read", "The snapshot of the database could not be written"}, []string{"Set REPORTING_DB_MODE to primary, replica or snapshot", "Set REPORTING_DB_FILE to the SQLite file of the replica", "Ensure the folder of REPORTING_DB_FILE is writable and has room for a copy of the database"})
+}
+
+func ErrInvalidAlertRule(err error) error {
+	return errors.New(ErrInvalidAlertRuleCode, errors.Alert, []string{"Invalid alert rule"}, []string{err.Error()}, []string{"The alert rule is not valid YAML or has unknown fields", "The metric, the condition or a channel of the alert rule is not valid"}, []string{"Ensure the alert rule has a name, a metric among event_rate, deployment_failure_streak, drift_count and adapter_down, a condition such as \"> 10\" and channels", "Ensure the webhook channels have a url"})
+}
+
+func ErrInvalidDashboard(err error) error {
+	return errors.New(ErrInvalidDashboardCode, errors.Alert, []string{"Invalid dashboard"}, []string{err.Error()}, []string{"A widget of the dashboard has an unknown type or misses the options of its type", "The layout of the dashboard does not place every widget once within the grid, or places widgets overlapping"}, []string{"Ensure the widgets have unique ids, a type among resource_count, design_health, perf_trend and prometheus_panel, and the options of their type", "Place every widget once within the 12 columns of the grid without overlapping another one"})
+}
+
+func ErrInvalidRegistryUpdate(err error) error {
+	return errors.New(ErrInvalidRegistryUpdateCode, errors.Alert, []string{"Invalid registry update"}, []string{err.Error()}, []string{"The registry update sets none or several of the spreadsheet, the CSV and the OCI reference to update from", "The OCI reference is not of the form registry/repository:tag"}, []string{"Set exactly one of spreadsheet_id, csv and oci_ref", "Set the OCI reference as registry/repository:tag, eg. ghcr.io/meshery/registry:v0.8.0"})
+}
+
+func ErrRegistryUpdateSourceNotAllowed(err error) error {
+	return errors.New(ErrRegistryUpdateSourceNotAllowedCode, errors.Alert, []string{"Registry update source not allowed"}, []string{err.Error()}, []string{"The OCI reference is not of the registry and repository of the registry snapshots configured on Meshery Server", "The spreadsheet is not the one configured on Meshery Server", "Components CSV uploads are not enabled on Meshery Server"}, []string{"Update from a tag of the REGISTRY_SNAPSHOT_REGISTRY and REGISTRY_SNAPSHOT_REPOSITORY settings", "Set the REGISTRY_UPDATE_SPREADSHEET_ID setting to the spreadsheet to update from", "Set the REGISTRY_UPDATE_CSV_ENABLED setting to true to allow components CSV uploads"})
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshkit/database"
+	"github.com/meshery/schemas/models/v1beta1"
+)
+
+// ManagementAPIBasePath is the base path of the management API.
+//
+// The management API is a stable subset of the API for infrastructure as code tools. Within a version, fields are only ever added. Resources are identified by ids
+// assigned on creation which never change, reads return the complete state of a resource in a
+// deterministic order, updates replace the state of a resource and requests for a resource which
+// does not exist fail with 404 so that it can be removed from the state of the tool. Any resource
+// can be imported by reading it by id.
+const ManagementAPIBasePath = "/api/management/v1"
+
+// ManagedDeploymentStatus is the outcome of the last deployment of a design to an environment.
+type ManagedDeploymentStatus string
+
+const (
+	ManagedDeploymentDeployed ManagedDeploymentStatus = "deployed"
+	ManagedDeploymentFailed   ManagedDeploymentStatus = "failed"
+)
+
+// ManagedDesign is a design as represented by the management API.
+type ManagedDesign struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	PatternFile string    `json:"pattern_file"`
+	Visibility  string    `json:"visibility"`
+	// ContentHash changes whenever the pattern file changes, to detect drift without comparing designs.
+	ContentHash string     `json:"content_hash"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// ManagedDesignRequest is the payload used to create or replace a design.
+type ManagedDesignRequest struct {
+	Name        string `json:"name"`
+	PatternFile string `json:"pattern_file"`
+}
+
+// ManagedEnvironment is an environment as represented by the management API,
+// along with the ids of the connections assigned to it.
+type ManagedEnvironment struct {
+	ID             uuid.UUID   `json:"id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	ConnectionIDs  []uuid.UUID `json:"connection_ids"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// ManagedEnvironmentRequest is the payload used to create or replace an environment.
+// The connections of the environment are reconciled with ConnectionIDs.
+type ManagedEnvironmentRequest struct {
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	ConnectionIDs  []uuid.UUID `json:"connection_ids"`
+}
+
+// ManagedConnection is a connection as represented by the management API.
+// Connections are discovered or registered by Meshery and are read only.
+type ManagedConnection struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"`
+	Type    string    `json:"type"`
+	SubType string    `json:"sub_type"`
+	Status  string    `json:"status"`
+}
+
+// ManagedDeployment is a design deployed to the connections of an environment through the management API.
+type ManagedDeployment struct {
+	ID            uuid.UUID `json:"id" gorm:"primarykey"`
+	DesignID      uuid.UUID `json:"design_id"`
+	EnvironmentID uuid.UUID `json:"environment_id"`
+	// ContentHash is the content hash of the design when it was last deployed.
+	ContentHash string                  `json:"content_hash"`
+	Status      ManagedDeploymentStatus `json:"status"`
+	Message     string                  `json:"message"`
+	// Diagnosis is the diagnosis of the last deployment when it failed.
+	Diagnosis *DeploymentDiagnosis `json:"diagnosis,omitempty" gorm:"type:bytes;serializer:json"`
+	UserID    uuid.UUID            `json:"user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ManagedDeploymentRequest is the payload used to create or replace a deployment.
+type ManagedDeploymentRequest struct {
+	DesignID      uuid.UUID `json:"design_id"`
+	EnvironmentID uuid.UUID `json:"environment_id"`
+	OwnershipOverrideRequest
+}
+
+// ManagedDeploymentPersister is the persister for deployments of the management API
+type ManagedDeploymentPersister struct {
+	DB *database.Handler
+}
+
+// Save creates the deployment, or updates it if it already exists.
+func (mdp *ManagedDeploymentPersister) Save(deployment *ManagedDeployment) error {
+	if deployment.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		deployment.ID = id
+	}
+	return mdp.DB.Save(deployment).Error
+}
+
+// Get returns the deployment of the user with the given id, nil if it does not exist or belongs to another user.
+func (mdp *ManagedDeploymentPersister) Get(userID, id uuid.UUID) (*ManagedDeployment, error) {
+	deployments := []ManagedDeployment{}
+	if err := mdp.DB.Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	return &deployments[0], nil
+}
+
+// List returns the deployments of the user, ordered by id.
+func (mdp *ManagedDeploymentPersister) List(userID uuid.UUID) ([]ManagedDeployment, error) {
+	deployments := []ManagedDeployment{}
+	err := mdp.DB.Where("user_id = ?", userID).Order("id").Find(&deployments).Error
+	return deployments, err
+}
+
+// Delete removes the deployment with the given id.
+func (mdp *ManagedDeploymentPersister) Delete(id uuid.UUID) error {
+	return mdp.DB.Where("id = ?", id).Delete(&ManagedDeployment{}).Error
+}
+
+// NewManagedDesign returns the design as represented by the management API.
+func NewManagedDesign(design *MesheryPattern) ManagedDesign {
+	managed := ManagedDesign{
+		Name:        design.Name,
+		PatternFile: design.PatternFile,
+		Visibility:  design.Visibility,
+		ContentHash: DesignContentHash(design.PatternFile),
+		CreatedAt:   design.CreatedAt,
+		UpdatedAt:   design.UpdatedAt,
+	}
+	if design.ID != nil {
+		managed.ID = *design.ID
+	}
+	return managed
+}
+
+// NewManagedEnvironment returns the environment and the connections assigned to it as represented by the management API.
+func NewManagedEnvironment(env *v1beta1.Environment, conns []*connections.Connection) ManagedEnvironment {
+	ids := make([]uuid.UUID, 0, len(conns))
+	for _, c := range conns {
+		if c != nil {
+			ids = append(ids, c.ID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ManagedEnvironment{
+		ID:             env.ID,
+		Name:           env.Name,
+		Description:    env.Description,
+		OrganizationID: env.OrganizationId,
+		ConnectionIDs:  ids,
+		CreatedAt:      env.CreatedAt,
+		UpdatedAt:      env.UpdatedAt,
+	}
+}
+
+// NewManagedConnection returns the connection as represented by the management API.
+func NewManagedConnection(conn *connections.Connection) ManagedConnection {
+	return ManagedConnection{
+		ID:      conn.ID,
+		Name:    conn.Name,
+		Kind:    conn.Kind,
+		Type:    conn.Type,
+		SubType: conn.SubType,
+		Status:  string(conn.Status),
+	}
+}
+
+// DesignContentHash returns the SHA-256 of the pattern file.
+func DesignContentHash(patternFile string) string {
+	sum := sha256.Sum256([]byte(patternFile))
+	return hex.EncodeToString(sum[:])
+}
@@ -140,4 +140,5 @@ func GetPatternName(stringifiedFile string) (string, error) {
 type MesheryPatternFileDeployPayload struct {
 	PatternFile string    `json:"pattern_file"`
 	PatternID   uuid.UUID `json:"pattern_id"`
+	OwnershipOverrideRequest
 }
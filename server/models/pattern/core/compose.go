@@ -0,0 +1,436 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	registry "github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// composeFile is the subset of the Compose specification converted to a design.
+// Fields accepting several syntaxes are decoded as interface{} and normalized.
+type composeFile struct {
+	Services map[string]composeService `json:"services"`
+	Volumes  map[string]interface{}    `json:"volumes"`
+}
+
+type composeService struct {
+	Image       string        `json:"image"`
+	Command     interface{}   `json:"command"`
+	Entrypoint  interface{}   `json:"entrypoint"`
+	Ports       []interface{} `json:"ports"`
+	Environment interface{}   `json:"environment"`
+	DependsOn   interface{}   `json:"depends_on"`
+	Volumes     []interface{} `json:"volumes"`
+	Deploy      struct {
+		Replicas *int `json:"replicas"`
+	} `json:"deploy"`
+}
+
+type composePort struct {
+	Target    int
+	Published int
+	Protocol  string
+}
+
+type composeVolume struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// NewPatternFileFromDockerCompose creates a design from a docker-compose.yaml.
+//
+// Every service is converted to a Deployment running its image with its command, environment and
+// volumes, and to a Service exposing its ports. Named volumes are converted to PersistentVolumeClaims
+// and bind mounts to hostPath volumes. The depends_on of a service is kept as the dependsOn of its
+// Deployment so the services are deployed in order.
+// Components are resolved from the registry, or derived from the GVK of the resources when reg is nil.
+func NewPatternFileFromDockerCompose(data []byte, fileName string, reg *registry.RegistryManager) (pattern.PatternFile, error) {
+	return convertToDesign(Conversion{From: ConversionFormatDockerCompose, To: ConversionFormatDesign}, func() (pattern.PatternFile, error) {
+		return newPatternFileFromDockerCompose(data, fileName, reg)
+	})
+}
+
+func newPatternFileFromDockerCompose(data []byte, fileName string, reg *registry.RegistryManager) (pattern.PatternFile, error) {
+	if fileName == "" {
+		fileName = "Autogenerated"
+	}
+	patternFile := pattern.PatternFile{
+		SchemaVersion: v1beta1.DesignSchemaVersion,
+		Name:          fileName,
+		Components:    []*component.ComponentDefinition{},
+	}
+
+	compose := composeFile{}
+	if err := ghodssyaml.Unmarshal(data, &compose); err != nil {
+		return patternFile, ErrParseDockerCompose(err)
+	}
+	if len(compose.Services) == 0 {
+		return patternFile, ErrParseDockerCompose(fmt.Errorf("docker compose file does not define any service"))
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	volumeNames := make([]string, 0, len(compose.Volumes))
+	for name := range compose.Volumes {
+		volumeNames = append(volumeNames, name)
+	}
+	sort.Strings(volumeNames)
+	// components are the components created for every volume and service, to resolve dependencies
+	components := map[string][]*component.ComponentDefinition{}
+	for _, name := range volumeNames {
+		comp, err := composeComponent(persistentVolumeClaimManifest(name), reg)
+		if err != nil {
+			return patternFile, ErrParseDockerCompose(err)
+		}
+		components["volume:"+name] = []*component.ComponentDefinition{comp}
+		patternFile.Components = append(patternFile.Components, comp)
+	}
+
+	deployments := map[string]*component.ComponentDefinition{}
+	for _, name := range names {
+		service := compose.Services[name]
+		if service.Image == "" {
+			return patternFile, ErrParseDockerCompose(fmt.Errorf("service %q does not define an image, building images is not supported", name))
+		}
+
+		ports, err := composePorts(service.Ports)
+		if err != nil {
+			return patternFile, ErrParseDockerCompose(fmt.Errorf("service %q: %w", name, err))
+		}
+		volumes := composeVolumes(service.Volumes)
+
+		deployment, err := composeComponent(deploymentManifest(name, service, ports, volumes, compose.Volumes), reg)
+		if err != nil {
+			return patternFile, ErrParseDockerCompose(err)
+		}
+		deployments[name] = deployment
+		patternFile.Components = append(patternFile.Components, deployment)
+		components[name] = []*component.ComponentDefinition{deployment}
+
+		if len(ports) > 0 {
+			svc, err := composeComponent(serviceManifest(name, ports), reg)
+			if err != nil {
+				return patternFile, ErrParseDockerCompose(err)
+			}
+			patternFile.Components = append(patternFile.Components, svc)
+			components[name] = append(components[name], svc)
+		}
+
+		// Deployments are deployed after the claims of their volumes
+		dependsOn := []string{}
+		for _, v := range volumes {
+			for _, comp := range components["volume:"+v.Source] {
+				dependsOn = append(dependsOn, comp.Id.String())
+			}
+		}
+		deployment.Metadata.Set("dependsOn", dependsOn)
+	}
+
+	for _, name := range names {
+		dependencies := composeStringList(compose.Services[name].DependsOn)
+		deployment := deployments[name]
+		dependsOn, _ := deployment.Metadata.AdditionalProperties["dependsOn"].([]string)
+		for _, dep := range dependencies {
+			comps, ok := components[dep]
+			if !ok {
+				return patternFile, ErrParseDockerCompose(fmt.Errorf("service %q depends on %q, which is not defined", name, dep))
+			}
+			for _, comp := range comps {
+				dependsOn = append(dependsOn, comp.Id.String())
+			}
+		}
+		deployment.Metadata.Set("dependsOn", dependsOn)
+	}
+
+	return patternFile, nil
+}
+
+// composeComponent creates the component of the manifest, derived from its GVK when it is not registered.
+func composeComponent(manifest map[string]interface{}, reg *registry.RegistryManager) (*component.ComponentDefinition, error) {
+	comp, err := createPatternDeclarationFromK8s(manifest, reg)
+	if err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+func deploymentManifest(name string, service composeService, ports []composePort, volumes []composeVolume, namedVolumes map[string]interface{}) map[string]interface{} {
+	labels := map[string]interface{}{"app": name}
+
+	container := map[string]interface{}{
+		"name":  helmChartName(name),
+		"image": service.Image,
+	}
+	if entrypoint := composeCommand(service.Entrypoint); len(entrypoint) > 0 {
+		container["command"] = entrypoint
+	}
+	if command := composeCommand(service.Command); len(command) > 0 {
+		container["args"] = command
+	}
+
+	containerPorts := []interface{}{}
+	for _, p := range ports {
+		containerPorts = append(containerPorts, map[string]interface{}{
+			"containerPort": p.Target,
+			"protocol":      strings.ToUpper(p.Protocol),
+		})
+	}
+	if len(containerPorts) > 0 {
+		container["ports"] = containerPorts
+	}
+
+	env := []interface{}{}
+	for _, kv := range composeEnvironment(service.Environment) {
+		env = append(env, map[string]interface{}{"name": kv[0], "value": kv[1]})
+	}
+	if len(env) > 0 {
+		container["env"] = env
+	}
+
+	podVolumes := []interface{}{}
+	mounts := []interface{}{}
+	for i, v := range volumes {
+		volumeName := fmt.Sprintf("volume-%d", i)
+		var source map[string]interface{}
+		switch {
+		case v.Source == "":
+			source = map[string]interface{}{"emptyDir": map[string]interface{}{}}
+		case isNamedVolume(v.Source, namedVolumes):
+			volumeName = helmChartName(v.Source)
+			source = map[string]interface{}{"persistentVolumeClaim": map[string]interface{}{"claimName": helmChartName(v.Source)}}
+		default:
+			source = map[string]interface{}{"hostPath": map[string]interface{}{"path": v.Source}}
+		}
+		source["name"] = volumeName
+		podVolumes = append(podVolumes, source)
+		mounts = append(mounts, map[string]interface{}{
+			"name":      volumeName,
+			"mountPath": v.Target,
+			"readOnly":  v.ReadOnly,
+		})
+	}
+	if len(mounts) > 0 {
+		container["volumeMounts"] = mounts
+	}
+
+	podSpec := map[string]interface{}{
+		"containers": []interface{}{container},
+	}
+	if len(podVolumes) > 0 {
+		podSpec["volumes"] = podVolumes
+	}
+
+	replicas := 1
+	if service.Deploy.Replicas != nil {
+		replicas = *service.Deploy.Replicas
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   helmChartName(name),
+			"labels": labels,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	}
+}
+
+func serviceManifest(name string, ports []composePort) map[string]interface{} {
+	servicePorts := []interface{}{}
+	for _, p := range ports {
+		port := p.Published
+		if port == 0 {
+			port = p.Target
+		}
+		servicePorts = append(servicePorts, map[string]interface{}{
+			"name":       fmt.Sprintf("%s-%d", strings.ToLower(p.Protocol), port),
+			"port":       port,
+			"targetPort": p.Target,
+			"protocol":   strings.ToUpper(p.Protocol),
+		})
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":   helmChartName(name),
+			"labels": map[string]interface{}{"app": name},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"app": name},
+			"ports":    servicePorts,
+		},
+	}
+}
+
+func persistentVolumeClaimManifest(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata": map[string]interface{}{
+			"name": helmChartName(name),
+		},
+		"spec": map[string]interface{}{
+			"accessModes": []interface{}{"ReadWriteOnce"},
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"storage": "1Gi"},
+			},
+		},
+	}
+}
+
+// composePorts parses the short ("[host:]published:target[/protocol]") and long syntax of ports.
+// Ranges are not supported.
+func composePorts(ports []interface{}) ([]composePort, error) {
+	parsed := []composePort{}
+	for _, p := range ports {
+		port := composePort{Protocol: "tcp"}
+		switch v := p.(type) {
+		case float64:
+			port.Target = int(v)
+		case string:
+			spec := v
+			if s, protocol, found := strings.Cut(spec, "/"); found {
+				spec, port.Protocol = s, protocol
+			}
+			parts := strings.Split(spec, ":")
+			target, err := strconv.Atoi(parts[len(parts)-1])
+			if err != nil {
+				return nil, fmt.Errorf("unsupported port %q", v)
+			}
+			port.Target = target
+			if len(parts) > 1 {
+				published, err := strconv.Atoi(parts[len(parts)-2])
+				if err != nil {
+					return nil, fmt.Errorf("unsupported port %q", v)
+				}
+				port.Published = published
+			}
+		case map[string]interface{}:
+			port.Target = composeInt(v["target"])
+			port.Published = composeInt(v["published"])
+			if protocol, ok := v["protocol"].(string); ok && protocol != "" {
+				port.Protocol = protocol
+			}
+		}
+		if port.Target == 0 {
+			return nil, fmt.Errorf("unsupported port %v", p)
+		}
+		parsed = append(parsed, port)
+	}
+	return parsed, nil
+}
+
+// composeVolumes parses the short ("[source:]target[:mode]") and long syntax of volumes.
+func composeVolumes(volumes []interface{}) []composeVolume {
+	parsed := []composeVolume{}
+	for _, v := range volumes {
+		switch vol := v.(type) {
+		case string:
+			parts := strings.Split(vol, ":")
+			volume := composeVolume{Target: parts[0]}
+			if len(parts) > 1 {
+				volume.Source, volume.Target = parts[0], parts[1]
+			}
+			if len(parts) > 2 {
+				volume.ReadOnly = strings.Contains(parts[2], "ro")
+			}
+			parsed = append(parsed, volume)
+		case map[string]interface{}:
+			source, _ := vol["source"].(string)
+			target, _ := vol["target"].(string)
+			readOnly, _ := vol["read_only"].(bool)
+			parsed = append(parsed, composeVolume{Source: source, Target: target, ReadOnly: readOnly})
+		}
+	}
+	return parsed
+}
+
+// composeEnvironment parses the list ("KEY=value") and map syntax of the environment, sorted by key.
+func composeEnvironment(environment interface{}) [][2]string {
+	env := [][2]string{}
+	switch e := environment.(type) {
+	case []interface{}:
+		for _, kv := range e {
+			key, value, _ := strings.Cut(fmt.Sprint(kv), "=")
+			env = append(env, [2]string{key, value})
+		}
+	case map[string]interface{}:
+		for key, value := range e {
+			if value == nil {
+				value = ""
+			}
+			env = append(env, [2]string{key, fmt.Sprint(value)})
+		}
+	}
+	sort.SliceStable(env, func(i, j int) bool { return env[i][0] < env[j][0] })
+	return env
+}
+
+// composeCommand returns the command given either as a list or as a string split on spaces.
+func composeCommand(command interface{}) []interface{} {
+	switch c := command.(type) {
+	case string:
+		args := []interface{}{}
+		for _, arg := range strings.Fields(c) {
+			args = append(args, arg)
+		}
+		return args
+	case []interface{}:
+		return c
+	}
+	return nil
+}
+
+// composeStringList returns the names in the list or map syntax of depends_on.
+func composeStringList(value interface{}) []string {
+	list := []string{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, s := range v {
+			list = append(list, fmt.Sprint(s))
+		}
+	case map[string]interface{}:
+		for s := range v {
+			list = append(list, s)
+		}
+		sort.Strings(list)
+	}
+	return list
+}
+
+func composeInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
+
+func isNamedVolume(source string, namedVolumes map[string]interface{}) bool {
+	_, ok := namedVolumes[source]
+	return ok
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+)
+
+const composeTestFile = `
+services:
+  web:
+    image: nginx:1.25
+    command: nginx -g "daemon off;"
+    ports:
+      - "8080:80"
+      - target: 443
+        published: 8443
+    environment:
+      - MODE=production
+      - DEBUG
+    depends_on:
+      - db
+    deploy:
+      replicas: 2
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: secret
+    volumes:
+      - data:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d:ro
+      - /tmp
+volumes:
+  data: {}
+`
+
+func TestNewPatternFileFromDockerCompose(t *testing.T) {
+	patternFile, err := NewPatternFileFromDockerCompose([]byte(composeTestFile), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patternFile.Name != "Autogenerated" {
+		t.Errorf("name = %q, want Autogenerated", patternFile.Name)
+	}
+
+	byKey := map[string]*component.ComponentDefinition{}
+	keys := []string{}
+	for _, comp := range patternFile.Components {
+		// configurations are stored prettified in designs, compare them as deployed
+		comp.Configuration = Format.DePrettify(comp.Configuration, false)
+		key := comp.Component.Kind + "/" + comp.DisplayName
+		byKey[key] = comp
+		keys = append(keys, key)
+	}
+	if want := []string{"PersistentVolumeClaim/data", "Deployment/db", "Deployment/web", "Service/web"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("components = %v, want %v", keys, want)
+	}
+	for _, comp := range patternFile.Components {
+		if comp.Model.Name != "kubernetes" {
+			t.Errorf("model of %s = %q, want kubernetes", comp.DisplayName, comp.Model.Name)
+		}
+	}
+
+	// the database is deployed after the claim of its named volume, the web server after the database
+	deps := func(key string) []string {
+		d := append([]string{}, componentDependencies(byKey[key])...)
+		sort.Strings(d)
+		return d
+	}
+	if got, want := deps("Deployment/db"), []string{byKey["PersistentVolumeClaim/data"].Id.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("db depends on %v, want %v", got, want)
+	}
+	if got, want := deps("Deployment/web"), []string{byKey["Deployment/db"].Id.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("web depends on %v, want %v", got, want)
+	}
+
+	container := func(key string) map[string]interface{} {
+		spec := byKey[key].Configuration["spec"].(map[string]interface{})
+		podSpec := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+		return podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"web image", container("Deployment/web")["image"], "nginx:1.25"},
+		{"web args", container("Deployment/web")["args"], []interface{}{"nginx", "-g", `"daemon`, `off;"`}},
+		{"web replicas", byKey["Deployment/web"].Configuration["spec"].(map[string]interface{})["replicas"], 2},
+		{"web env", container("Deployment/web")["env"], []interface{}{
+			map[string]interface{}{"name": "DEBUG", "value": ""},
+			map[string]interface{}{"name": "MODE", "value": "production"},
+		}},
+		{"web container ports", container("Deployment/web")["ports"], []interface{}{
+			map[string]interface{}{"containerPort": 80, "protocol": "TCP"},
+			map[string]interface{}{"containerPort": 443, "protocol": "TCP"},
+		}},
+		{"web service ports", byKey["Service/web"].Configuration["spec"].(map[string]interface{})["ports"], []interface{}{
+			map[string]interface{}{"name": "tcp-8080", "port": 8080, "targetPort": 80, "protocol": "TCP"},
+			map[string]interface{}{"name": "tcp-8443", "port": 8443, "targetPort": 443, "protocol": "TCP"},
+		}},
+		{"db replicas", byKey["Deployment/db"].Configuration["spec"].(map[string]interface{})["replicas"], 1},
+		{"db volume mounts", container("Deployment/db")["volumeMounts"], []interface{}{
+			map[string]interface{}{"name": "data", "mountPath": "/var/lib/postgresql/data", "readOnly": false},
+			map[string]interface{}{"name": "volume-1", "mountPath": "/docker-entrypoint-initdb.d", "readOnly": true},
+			map[string]interface{}{"name": "volume-2", "mountPath": "/tmp", "readOnly": false},
+		}},
+		{"db volumes", byKey["Deployment/db"].Configuration["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["volumes"], []interface{}{
+			map[string]interface{}{"name": "data", "persistentVolumeClaim": map[string]interface{}{"claimName": "data"}},
+			map[string]interface{}{"name": "volume-1", "hostPath": map[string]interface{}{"path": "./init"}},
+			map[string]interface{}{"name": "volume-2", "emptyDir": map[string]interface{}{}},
+		}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestNewPatternFileFromDockerCompose_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+	}{
+		{name: "invalid yaml", compose: "services: ["},
+		{name: "no service", compose: "volumes:\n  data: {}\n"},
+		{name: "service without image", compose: "services:\n  app:\n    build: .\n"},
+		{name: "unknown dependency", compose: "services:\n  app:\n    image: app\n    depends_on: [db]\n"},
+		{name: "port range", compose: "services:\n  app:\n    image: app\n    ports: [\"8000-8010:8000-8010\"]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPatternFileFromDockerCompose([]byte(tt.compose), "app", nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestComposePorts(t *testing.T) {
+	tests := []struct {
+		ports []interface{}
+		want  []composePort
+	}{
+		{ports: []interface{}{float64(80)}, want: []composePort{{Target: 80, Protocol: "tcp"}}},
+		{ports: []interface{}{"53:53/udp"}, want: []composePort{{Target: 53, Published: 53, Protocol: "udp"}}},
+		{ports: []interface{}{"127.0.0.1:8080:80"}, want: []composePort{{Target: 80, Published: 8080, Protocol: "tcp"}}},
+		{ports: []interface{}{map[string]interface{}{"target": float64(80), "published": "8080", "protocol": "udp"}}, want: []composePort{{Target: 80, Published: 8080, Protocol: "udp"}}},
+	}
+	for _, tt := range tests {
+		got, err := composePorts(tt.ports)
+		if err != nil {
+			t.Errorf("composePorts(%v): %v", tt.ports, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("composePorts(%v) = %+v, want %+v", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestComposeEnvironment(t *testing.T) {
+	tests := []struct {
+		environment interface{}
+		want        [][2]string
+	}{
+		{environment: nil, want: [][2]string{}},
+		{environment: []interface{}{"B=2", "A=1=1"}, want: [][2]string{{"A", "1=1"}, {"B", "2"}}},
+		{environment: map[string]interface{}{"B": float64(2), "A": nil}, want: [][2]string{{"A", ""}, {"B", "2"}}},
+	}
+	for _, tt := range tests {
+		if got := composeEnvironment(tt.environment); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("composeEnvironment(%v) = %v, want %v", tt.environment, got, tt.want)
+		}
+	}
+}
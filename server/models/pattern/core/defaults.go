@@ -0,0 +1,110 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// ApplyComponentDefaults resolves every component of the design to its definition and fills in the
+// configuration of the component from the schema of the definition, so that partially specified
+// components render complete resources.
+//
+// Properties missing from the configuration are set to their schema default, itself completed with
+// the defaults of its nested properties. Required objects missing from the configuration are created
+// so that the defaults of their properties apply.
+// Values declared by the design are never overwritten and required properties without a default
+// are left unset, for validation to report. Annotation components are left unchanged.
+func ApplyComponentDefaults(patternFile *pattern.PatternFile, resolver ComponentResolver) error {
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Metadata.IsAnnotation {
+			continue
+		}
+		def, err := resolver.Resolve(comp)
+		if err != nil {
+			return ErrApplyComponentDefaults(err, comp.DisplayName)
+		}
+		if err := applyComponentDefaults(comp, def); err != nil {
+			return ErrApplyComponentDefaults(err, comp.DisplayName)
+		}
+	}
+	return nil
+}
+
+func applyComponentDefaults(comp *component.ComponentDefinition, def *component.ComponentDefinition) error {
+	// Annotation components carry no schema to take defaults from.
+	if def.Metadata.IsAnnotation || def.Component.Schema == "" {
+		return nil
+	}
+	schema := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(def.Component.Schema), &schema); err != nil {
+		return fmt.Errorf("invalid schema in the component definition: %w", err)
+	}
+
+	if comp.Configuration == nil {
+		comp.Configuration = map[string]interface{}{}
+	}
+	fillObjectDefaults(comp.Configuration, schema)
+	return nil
+}
+
+// fillObjectDefaults fills in the properties of the object which are missing from value from
+// the given object schema, recursing into the nested objects and the items of the nested arrays.
+func fillObjectDefaults(value map[string]interface{}, schema map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	for name, property := range properties {
+		propertySchema, ok := property.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		current, exists := value[name]
+		if !exists {
+			if defaultValue, ok := propertySchema["default"]; ok {
+				current = deepCopyValue(defaultValue)
+			} else if required[name] && isObjectSchema(propertySchema) {
+				current = map[string]interface{}{}
+			} else {
+				continue
+			}
+			value[name] = current
+		}
+		fillValueDefaults(current, propertySchema)
+	}
+}
+
+// fillValueDefaults fills in the defaults of the given value if it is an object or an array of objects.
+func fillValueDefaults(value interface{}, schema map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isObjectSchema(schema) {
+			fillObjectDefaults(v, schema)
+		}
+	case []interface{}:
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range v {
+			fillValueDefaults(item, items)
+		}
+	}
+}
+
+func isObjectSchema(schema map[string]interface{}) bool {
+	if schemaType, ok := schema["type"].(string); ok {
+		return schemaType == "object"
+	}
+	_, ok := schema["properties"]
+	return ok
+}
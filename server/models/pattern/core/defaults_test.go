@@ -0,0 +1,168 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+const testDeploymentSchema = `{
+	"type": "object",
+	"required": ["spec"],
+	"properties": {
+		"spec": {
+			"type": "object",
+			"required": ["strategy"],
+			"properties": {
+				"replicas": {"type": "integer", "default": 1},
+				"revisionHistoryLimit": {"type": "integer", "default": 10},
+				"strategy": {
+					"type": "object",
+					"properties": {"type": {"type": "string", "default": "RollingUpdate"}}
+				},
+				"selector": {
+					"type": "object",
+					"properties": {"matchLabels": {"type": "object"}}
+				},
+				"template": {
+					"type": "object",
+					"properties": {
+						"spec": {
+							"type": "object",
+							"properties": {
+								"containers": {
+									"type": "array",
+									"items": {
+										"type": "object",
+										"required": ["name"],
+										"properties": {
+											"name": {"type": "string"},
+											"imagePullPolicy": {"type": "string", "default": "IfNotPresent"}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestApplyComponentDefaults(t *testing.T) {
+	deploymentDef := newTestDefinition("apps/v1", true)
+	deploymentDef.Component.Schema = testDeploymentSchema
+	resolver := staticResolver{
+		"Deployment": deploymentDef,
+		"Comment":    {Metadata: component.ComponentDefinition_Metadata{IsAnnotation: true}},
+	}
+
+	web := newTestWorkload("web", "nginx", nil)
+	web.Configuration["spec"].(map[string]interface{})["revisionHistoryLimit"] = 3
+	empty := newTestComponent("empty", "Deployment")
+	empty.Configuration = nil
+	note := newTestComponent("note", "Comment")
+	patternFile := &pattern.PatternFile{Components: []*component.ComponentDefinition{web, empty, note}}
+
+	if err := ApplyComponentDefaults(patternFile, resolver); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"replicas":             float64(1),
+		"revisionHistoryLimit": 3,
+		"strategy":             map[string]interface{}{"type": "RollingUpdate"},
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "web", "image": "nginx", "imagePullPolicy": "IfNotPresent"},
+				},
+			},
+		},
+	}
+	if got := web.Configuration["spec"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the defaults to fill in the missing settings only, got %v", got)
+	}
+
+	wantEmpty := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas":             float64(1),
+			"revisionHistoryLimit": float64(10),
+			"strategy":             map[string]interface{}{"type": "RollingUpdate"},
+		},
+	}
+	if !reflect.DeepEqual(empty.Configuration, wantEmpty) {
+		t.Errorf("expected the required objects to be created with their defaults, got %v", empty.Configuration)
+	}
+
+	if _, ok := note.Configuration["spec"]; ok {
+		t.Errorf("expected annotation components to be left unchanged, got %v", note.Configuration)
+	}
+}
+
+func TestApplyComponentDefaultsNestedDefault(t *testing.T) {
+	def := newTestDefinition("apps/v1", true)
+	def.Component.Schema = `{
+		"type": "object",
+		"properties": {
+			"spec": {
+				"type": "object",
+				"default": {"paused": false},
+				"properties": {
+					"replicas": {"type": "integer", "default": 1},
+					"strategy": {
+						"type": "object",
+						"default": {"type": "RollingUpdate"},
+						"properties": {
+							"rollingUpdate": {
+								"type": "object",
+								"default": {},
+								"properties": {"maxSurge": {"type": "string", "default": "25%"}}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`
+	comp := newTestComponent("web", "Deployment")
+	patternFile := &pattern.PatternFile{Components: []*component.ComponentDefinition{comp}}
+
+	if err := ApplyComponentDefaults(patternFile, staticResolver{"Deployment": def}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"paused":   false,
+		"replicas": float64(1),
+		"strategy": map[string]interface{}{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]interface{}{"maxSurge": "25%"},
+		},
+	}
+	if got := comp.Configuration["spec"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the defaults to be completed with their nested defaults, got %v", got)
+	}
+}
+
+func TestApplyComponentDefaultsErrors(t *testing.T) {
+	invalid := newTestDefinition("v1", true)
+	invalid.Component.Schema = "{"
+	resolver := staticResolver{"ConfigMap": invalid}
+
+	tests := map[string]*component.ComponentDefinition{
+		"unregistered":   newTestComponent("web", "Deployment"),
+		"invalid schema": newTestComponent("config", "ConfigMap"),
+	}
+	for name, comp := range tests {
+		t.Run(name, func(t *testing.T) {
+			patternFile := &pattern.PatternFile{Components: []*component.ComponentDefinition{comp}}
+			if err := ApplyComponentDefaults(patternFile, resolver); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1alpha3/relationship"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// FieldChange is a change of a single field of a component.
+// Path is a JSON pointer to the field, e.g. /configuration/metadata/namespace.
+// Old is omitted for added fields and New for removed fields.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ComponentChange is a component added, removed or modified between two versions of a design.
+type ComponentChange struct {
+	ID      uuid.UUID     `json:"id"`
+	Name    string        `json:"name"`
+	Kind    string        `json:"kind"`
+	Model   string        `json:"model"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// RelationshipChange is a relationship added or removed between two versions of a design.
+type RelationshipChange struct {
+	ID      uuid.UUID `json:"id"`
+	Kind    string    `json:"kind"`
+	Type    string    `json:"type"`
+	SubType string    `json:"subType"`
+}
+
+// PatternDiff is the change set between two versions of a design.
+type PatternDiff struct {
+	Added    []ComponentChange `json:"added"`
+	Removed  []ComponentChange `json:"removed"`
+	Modified []ComponentChange `json:"modified"`
+
+	AddedRelationships   []RelationshipChange `json:"addedRelationships"`
+	RemovedRelationships []RelationshipChange `json:"removedRelationships"`
+}
+
+// IsEmpty reports whether both versions of the design are equivalent.
+func (d *PatternDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 &&
+		len(d.AddedRelationships) == 0 && len(d.RemovedRelationships) == 0
+}
+
+// DiffPatternFiles compares two versions of a design. Components and relationships
+// are matched by id; modified components list the changes of their name, kind,
+// version, model, dependencies and of every key of their configuration.
+func DiffPatternFiles(before, after *pattern.PatternFile) *PatternDiff {
+	diff := &PatternDiff{
+		Added:                []ComponentChange{},
+		Removed:              []ComponentChange{},
+		Modified:             []ComponentChange{},
+		AddedRelationships:   []RelationshipChange{},
+		RemovedRelationships: []RelationshipChange{},
+	}
+
+	oldComps := componentsByID(before)
+	newComps := componentsByID(after)
+	for id, comp := range newComps {
+		prev, ok := oldComps[id]
+		if !ok {
+			diff.Added = append(diff.Added, componentChange(comp))
+			continue
+		}
+		if changes := diffComponents(prev, comp); len(changes) > 0 {
+			change := componentChange(comp)
+			change.Changes = changes
+			diff.Modified = append(diff.Modified, change)
+		}
+	}
+	for id, comp := range oldComps {
+		if _, ok := newComps[id]; !ok {
+			diff.Removed = append(diff.Removed, componentChange(comp))
+		}
+	}
+
+	oldRels := relationshipsByID(before)
+	newRels := relationshipsByID(after)
+	for id, rel := range newRels {
+		if _, ok := oldRels[id]; !ok {
+			diff.AddedRelationships = append(diff.AddedRelationships, relationshipChange(rel))
+		}
+	}
+	for id, rel := range oldRels {
+		if _, ok := newRels[id]; !ok {
+			diff.RemovedRelationships = append(diff.RemovedRelationships, relationshipChange(rel))
+		}
+	}
+
+	for _, changes := range [][]ComponentChange{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].Name != changes[j].Name {
+				return changes[i].Name < changes[j].Name
+			}
+			return changes[i].ID.String() < changes[j].ID.String()
+		})
+	}
+	for _, changes := range [][]RelationshipChange{diff.AddedRelationships, diff.RemovedRelationships} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].ID.String() < changes[j].ID.String()
+		})
+	}
+	return diff
+}
+
+func componentsByID(patternFile *pattern.PatternFile) map[uuid.UUID]*component.ComponentDefinition {
+	comps := map[uuid.UUID]*component.ComponentDefinition{}
+	if patternFile == nil {
+		return comps
+	}
+	for _, comp := range patternFile.Components {
+		if comp != nil {
+			comps[comp.Id] = comp
+		}
+	}
+	return comps
+}
+
+func relationshipsByID(patternFile *pattern.PatternFile) map[uuid.UUID]*relationship.RelationshipDefinition {
+	rels := map[uuid.UUID]*relationship.RelationshipDefinition{}
+	if patternFile == nil {
+		return rels
+	}
+	for _, rel := range patternFile.Relationships {
+		if rel != nil {
+			rels[rel.Id] = rel
+		}
+	}
+	return rels
+}
+
+func componentChange(comp *component.ComponentDefinition) ComponentChange {
+	return ComponentChange{
+		ID:    comp.Id,
+		Name:  comp.DisplayName,
+		Kind:  comp.Component.Kind,
+		Model: comp.Model.Name,
+	}
+}
+
+func relationshipChange(rel *relationship.RelationshipDefinition) RelationshipChange {
+	return RelationshipChange{
+		ID:      rel.Id,
+		Kind:    string(rel.Kind),
+		Type:    rel.RelationshipType,
+		SubType: rel.SubType,
+	}
+}
+
+func diffComponents(before, after *component.ComponentDefinition) []FieldChange {
+	changes := []FieldChange{}
+	fields := []struct {
+		path     string
+		from, to string
+	}{
+		{"/displayName", before.DisplayName, after.DisplayName},
+		{"/component/kind", before.Component.Kind, after.Component.Kind},
+		{"/component/version", before.Component.Version, after.Component.Version},
+		{"/model/name", before.Model.Name, after.Model.Name},
+		{"/model/version", before.Model.Model.Version, after.Model.Model.Version},
+	}
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, FieldChange{Path: f.path, Old: f.from, New: f.to})
+		}
+	}
+	// dependencies are compared regardless of their order
+	if from, to := sortedDependencies(before), sortedDependencies(after); !reflect.DeepEqual(from, to) {
+		change := FieldChange{Path: "/metadata/dependsOn"}
+		if len(from) > 0 {
+			change.Old = from
+		}
+		if len(to) > 0 {
+			change.New = to
+		}
+		changes = append(changes, change)
+	}
+	return diffValues("/configuration", before.Configuration, after.Configuration, changes)
+}
+
+func sortedDependencies(comp *component.ComponentDefinition) []string {
+	deps := append([]string{}, componentDependencies(comp)...)
+	sort.Strings(deps)
+	return deps
+}
+
+// diffValues appends the changes between before and after to changes. Maps are compared
+// key by key, any other value is compared as a whole.
+func diffValues(path string, before, after interface{}, changes []FieldChange) []FieldChange {
+	oldMap, oldIsMap := before.(map[string]interface{})
+	newMap, newIsMap := after.(map[string]interface{})
+	if !oldIsMap || !newIsMap {
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, FieldChange{Path: path, Old: before, New: after})
+		}
+		return changes
+	}
+
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys = append(keys, k)
+	}
+	for k := range newMap {
+		if _, ok := oldMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		changes = diffValues(path+"/"+escapeJSONPointer(k), oldMap[k], newMap[k], changes)
+	}
+	return changes
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func TestDiffPatternFiles(t *testing.T) {
+	namespace := newTestComponent("ns", "Namespace")
+	config := newTestComponent("config", "ConfigMap")
+	nsID, configID := namespace.Id.String(), config.Id.String()
+
+	tests := []struct {
+		name   string
+		before *component.ComponentDefinition
+		after  *component.ComponentDefinition
+		want   []FieldChange
+	}{
+		{
+			name:   "unchanged",
+			before: newTestComponent("web", "Deployment", nsID, configID),
+			after:  newTestComponent("web", "Deployment", nsID, configID),
+		},
+		{
+			name:   "dependencies reordered",
+			before: newTestComponent("web", "Deployment", nsID, configID),
+			after:  newTestComponent("web", "Deployment", configID, nsID),
+		},
+		{
+			name:   "dependency added",
+			before: newTestComponent("web", "Deployment", nsID),
+			after:  newTestComponent("web", "Deployment", nsID, configID),
+			want:   []FieldChange{{Path: "/metadata/dependsOn", Old: []string{nsID}, New: sortedStrings(nsID, configID)}},
+		},
+		{
+			name:   "dependencies removed",
+			before: newTestComponent("web", "Deployment", nsID),
+			after:  newTestComponent("web", "Deployment"),
+			want:   []FieldChange{{Path: "/metadata/dependsOn", Old: []string{nsID}}},
+		},
+		{
+			name:   "first dependency",
+			before: newTestComponent("web", "Deployment"),
+			after:  newTestComponent("web", "Deployment", configID),
+			want:   []FieldChange{{Path: "/metadata/dependsOn", New: []string{configID}}},
+		},
+		{
+			name:   "configuration and version",
+			before: newTestComponent("web", "Deployment"),
+			after: func() *component.ComponentDefinition {
+				comp := newTestComponent("web", "Deployment")
+				comp.Component.Version = "v2"
+				comp.Configuration["metadata"].(map[string]interface{})["namespace"] = "prod"
+				return comp
+			}(),
+			want: []FieldChange{
+				{Path: "/component/version", Old: "v1", New: "v2"},
+				{Path: "/configuration/metadata/namespace", New: "prod"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := &pattern.PatternFile{Components: []*component.ComponentDefinition{namespace, config, tt.before}}
+			after := &pattern.PatternFile{Components: []*component.ComponentDefinition{namespace, config, tt.after}}
+			diff := DiffPatternFiles(before, after)
+			if len(tt.want) == 0 {
+				if !diff.IsEmpty() {
+					t.Errorf("expected no changes, got %+v", diff)
+				}
+				return
+			}
+			if len(diff.Modified) != 1 {
+				t.Fatalf("expected 1 modified component, got %+v", diff.Modified)
+			}
+			if got := diff.Modified[0].Changes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPatternFiles_AddedAndRemoved(t *testing.T) {
+	kept := newTestComponent("kept", "ConfigMap")
+	removed := newTestComponent("removed", "Secret")
+	added := newTestComponent("added", "Service")
+	diff := DiffPatternFiles(
+		&pattern.PatternFile{Components: []*component.ComponentDefinition{kept, removed}},
+		&pattern.PatternFile{Components: []*component.ComponentDefinition{kept, added}},
+	)
+	if want := []ComponentChange{{ID: added.Id, Name: "added", Kind: "Service"}}; !reflect.DeepEqual(diff.Added, want) {
+		t.Errorf("added = %+v, want %+v", diff.Added, want)
+	}
+	if want := []ComponentChange{{ID: removed.Id, Name: "removed", Kind: "Secret"}}; !reflect.DeepEqual(diff.Removed, want) {
+		t.Errorf("removed = %+v, want %+v", diff.Removed, want)
+	}
+	if len(diff.Modified) != 0 {
+		t.Errorf("modified = %+v, want none", diff.Modified)
+	}
+}
+
+func sortedStrings(values ...string) []string {
+	sort.Strings(values)
+	return values
+}
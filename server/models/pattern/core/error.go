@@ -1,6 +1,8 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -8,10 +10,29 @@ import (
 // https://docs.meshery.io/project/contributing/contributing-error
 // https://github.com/meshery/meshkit/blob/master/errors/errors.go
 const (
-	ErrGetK8sComponentsCode     = "meshery-server-1314"
-	ErrParseK8sManifestCode     = "meshery-server-1315"
-	ErrCreatePatternServiceCode = "meshery-server-1316"
-	ErrPatternFromCytoscapeCode = "meshery-server-1317"
+	ErrGetK8sComponentsCode             = "meshery-server-1314"
+	ErrParseK8sManifestCode             = "meshery-server-1315"
+	ErrCreatePatternServiceCode         = "meshery-server-1316"
+	ErrPatternFromCytoscapeCode         = "meshery-server-1317"
+	ErrUnsupportedPatternFileFormatCode = "meshery-server-1372"
+	ErrResolvePatternVarsCode           = "meshery-server-1378"
+	ErrMergePatternFilesCode            = "meshery-server-1381"
+	ErrExportHelmChartCode              = "meshery-server-1385"
+	ErrExportKustomizeCode              = "meshery-server-1390"
+	ErrParseDockerComposeCode           = "meshery-server-1391"
+	ErrRenderManifestsCode              = "meshery-server-1394"
+	ErrPatternOverlayCode               = "meshery-server-1396"
+	ErrPatternImportCode                = "meshery-server-1401"
+	ErrSchedulingTraitsCode             = "meshery-server-1403"
+	ErrPatternHookCode                  = "meshery-server-1405"
+	ErrConvertV1alpha2Code              = "meshery-server-1406"
+	ErrPodSecurityCode                  = "meshery-server-1407"
+	ErrLintConfigCode                   = "meshery-server-1409"
+	ErrResolvePatternSecretsCode        = "meshery-server-1413"
+	ErrSanitizePatternCode              = "meshery-server-1414"
+	ErrSanitizePolicyCode               = "meshery-server-1415"
+	ErrNameCollisionCode                = "meshery-server-1420"
+	ErrApplyComponentDefaultsCode       = "meshery-server-1429"
 )
 
 func ErrGetK8sComponents(err error) error {
@@ -29,3 +50,79 @@ func ErrCreatePatternService(err error) error {
 func ErrPatternFromCytoscape(err error) error {
 	return errors.New(ErrPatternFromCytoscapeCode, errors.Alert, []string{"Could not create design file from given cytoscape"}, []string{err.Error()}, []string{"Invalid cytoscape body", "Service name is empty for one or more services", "_data does not have correct data"}, []string{"Make sure cytoscape is valid", "Check if valid service name was passed in the request", "Make sure _data field has \"settings\" field"})
 }
+
+func ErrUnsupportedPatternFileFormat(format string) error {
+	return errors.New(ErrUnsupportedPatternFileFormatCode, errors.Alert, []string{fmt.Sprintf("Unsupported design format %q", format)}, []string{"Designs can only be read from YAML or JSON"}, []string{"An unsupported format was requested while reading the design"}, []string{"Use \"yaml\" or \"json\" as the design format, or let the format be detected automatically"})
+}
+
+func ErrResolvePatternVars(err error) error {
+	return errors.New(ErrResolvePatternVarsCode, errors.Alert, []string{"Failed to resolve the variables of the design"}, []string{err.Error()}, []string{"The vars section of the design is not a map of variable names to default values", "A value was provided for a variable which is not declared in the vars section"}, []string{"Declare every variable in the vars section of the design, e.g. vars: {namespace: default}", "Only override variables declared by the design"})
+}
+
+func ErrMergePatternFiles(err error) error {
+	return errors.New(ErrMergePatternFilesCode, errors.Alert, []string{"Failed to merge designs"}, []string{err.Error()}, []string{"Both designs declare a component with the same kind and name but different configurations", "The merge strategy is not supported"}, []string{"Use one of the ours, theirs or deep-merge strategies to resolve conflicting components", "Use one of the error, ours, theirs or deep-merge strategies"})
+}
+
+func ErrExportHelmChart(err error, designName string) error {
+	return errors.New(ErrExportHelmChartCode, errors.Alert, []string{fmt.Sprintf("Failed to export design %s as a Helm chart", designName)}, []string{err.Error()}, []string{"The configuration of a component cannot be rendered as a manifest", "The chart could not be written to the output directory"}, []string{"Ensure the design is valid", "Ensure the output directory is writable"})
+}
+
+func ErrExportKustomize(err error, designName string) error {
+	return errors.New(ErrExportKustomizeCode, errors.Alert, []string{fmt.Sprintf("Failed to export design %s as a Kustomize base", designName)}, []string{err.Error()}, []string{"An overlay overrides the settings of a component which is not in the design", "The kustomization could not be written to the output directory"}, []string{"Ensure the overlays refer to components by their display name", "Ensure the output directory is writable"})
+}
+
+func ErrParseDockerCompose(err error) error {
+	return errors.New(ErrParseDockerComposeCode, errors.Alert, []string{"Failed to convert the Docker Compose file to a design"}, []string{err.Error()}, []string{"The Docker Compose file is not valid YAML", "A service is built from sources instead of an image", "A service depends on a service which is not defined", "A port uses an unsupported syntax such as a range"}, []string{"Ensure the Docker Compose file is valid", "Reference a published image for every service", "Define every service referenced in depends_on", "Declare every port individually"})
+}
+
+func ErrRenderManifests(err error, designName string) error {
+	return errors.New(ErrRenderManifestsCode, errors.Alert, []string{fmt.Sprintf("Failed to render design %s into Kubernetes manifests", designName)}, []string{err.Error()}, []string{"A component is not registered", "The dependencies of the components form a cycle"}, []string{"Ensure the models of every component of the design are registered", "Remove the cyclic dependency between the components"})
+}
+
+func ErrPatternOverlay(err error, designName string) error {
+	return errors.New(ErrPatternOverlayCode, errors.Alert, []string{fmt.Sprintf("Failed to apply the overlay of design %s", designName)}, []string{err.Error()}, []string{"The overlay document is not valid YAML", "The overlay is not declared in the overlay document", "An overlay patches a component which is not in the design"}, []string{"Ensure every overlay is a YAML document with a unique name", "Ensure the overlays refer to components by their display name"})
+}
+
+func ErrPatternImport(err error, source string) error {
+	return errors.New(ErrPatternImportCode, errors.Alert, []string{fmt.Sprintf("Failed to import design %s", source)}, []string{err.Error()}, []string{"The imported design could not be read or fetched", "Designs import each other in a cycle", "A local design is imported by a design which was not read from a local file"}, []string{"Ensure the source of the import is a valid path relative to the importing design, or a reachable URL", "Remove the import closing the cycle", "Import the design by its URL"})
+}
+
+func ErrSchedulingTraits(err error, componentName string) error {
+	return errors.New(ErrSchedulingTraitsCode, errors.Alert, []string{fmt.Sprintf("Invalid scheduling traits for component %s", componentName)}, []string{err.Error()}, []string{"The nodeSelector trait is not a map of node labels to values", "The tolerations trait is not a list of Kubernetes tolerations", "The pod template of the component is malformed"}, []string{"Declare the nodeSelector trait like the nodeSelector of a pod, e.g. nodeSelector: {kubernetes.io/arch: arm64}", "Declare the tolerations trait like the tolerations of a pod"})
+}
+
+func ErrPatternHook(err error, stage string) error {
+	return errors.New(ErrPatternHookCode, errors.Alert, []string{fmt.Sprintf("A %s hook rejected the design", stage)}, []string{err.Error()}, []string{"The design does not comply with a policy enforced by a hook registered by the embedder of Meshery Server"}, []string{"Refer to the cause to comply with the policy enforced by the hook"})
+}
+
+func ErrConvertV1alpha2(err error, designName string) error {
+	return errors.New(ErrConvertV1alpha2Code, errors.Alert, []string{fmt.Sprintf("Failed to convert design %s between the v1alpha2 format and the current design schema", designName)}, []string{err.Error()}, []string{"The design is not a valid v1alpha2 design", "The meshmap trait of a service is malformed", "The labels or annotations of a component are not maps of strings"}, []string{"Ensure the design declares its services under the services section", "Remove the malformed meshmap trait, its canvas data is optional", "Ensure the labels and annotations of every component are maps of strings"})
+}
+
+func ErrPodSecurity(err error, name string) error {
+	return errors.New(ErrPodSecurityCode, errors.Alert, []string{fmt.Sprintf("Failed to check %s against the Pod Security Standards", name)}, []string{err.Error()}, []string{"The pod template of the component is malformed"}, []string{"Ensure the pod template of the component is a valid Kubernetes pod template"})
+}
+
+func ErrLintConfig(err error) error {
+	return errors.New(ErrLintConfigCode, errors.Alert, []string{"Invalid design lint configuration"}, []string{err.Error()}, []string{"The configuration enables, disables or sets the severity of a rule which is not registered", "The severity of a rule is not error, warning or info"}, []string{"Refer to the rules by the names listed with the lint findings", "Set the severities to error, warning or info"})
+}
+
+func ErrResolvePatternSecrets(err error, designName string) error {
+	return errors.New(ErrResolvePatternSecretsCode, errors.Alert, []string{fmt.Sprintf("Failed to resolve the secret references of design %s", designName)}, []string{err.Error()}, []string{"A secretRef lacks its source or its name", "No resolver is registered for the source of a secretRef", "The referred Secret, key or environment variable does not exist"}, []string{"Refer to secrets as secretRef: {source, name, key}, with the kubernetes or env source or the source of a registered resolver", "Create the Secret in the namespace of the component, or set the MESHERY_SECRET_ prefixed environment variable of Meshery Server"})
+}
+
+func ErrSanitizePattern(err error, designName string) error {
+	return errors.New(ErrSanitizePatternCode, errors.Alert, []string{fmt.Sprintf("Failed to sanitize design %s", designName)}, []string{err.Error()}, []string{"The configuration of a component, or the settings of an overlay, cannot be read as JSON"}, []string{"Ensure the configurations of the components and the settings of the overlays are maps of JSON values"})
+}
+
+func ErrSanitizePolicy(err error) error {
+	return errors.New(ErrSanitizePolicyCode, errors.Alert, []string{"Invalid design sanitize policy"}, []string{err.Error()}, []string{"The action of the policy is not mask or strip", "A key or value pattern of the policy is not a valid regular expression"}, []string{"Set the action to mask or strip", "Write the key and value patterns with the RE2 syntax of Go regular expressions"})
+}
+
+func ErrNameCollision(err error, design string) error {
+	return errors.New(ErrNameCollisionCode, errors.Alert, []string{fmt.Sprintf("Components of the design %s share a name", design)}, []string{err.Error()}, []string{"Several components of the design have the same name, or the same id", "DESIGN_NAME_COLLISION_STRATEGY is not error, suffix or namespace"}, []string{"Name the components of the design uniquely", "Set DESIGN_NAME_COLLISION_STRATEGY to suffix, to suffix the names shared with their rank, or to namespace, to qualify them with the namespace of the components"})
+}
+
+func ErrApplyComponentDefaults(err error, componentName string) error {
+	return errors.New(ErrApplyComponentDefaultsCode, errors.Alert, []string{fmt.Sprintf("Failed to apply the schema defaults to the configuration of component %s", componentName)}, []string{err.Error()}, []string{"The component is not registered", "The schema of the registered component definition is not valid JSON"}, []string{"Ensure the model of the component is registered", "Regenerate the model of the component"})
+}
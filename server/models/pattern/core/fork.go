@@ -0,0 +1,217 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1alpha3/relationship"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// MergeConflict is a field changed both by the design, since it was forked, and by the changes
+// proposed by the fork. Path is a JSON pointer to the field, e.g.
+// /components/<id>/configuration/spec/replicas; Current or Proposed is omitted when the
+// component or relationship was removed.
+type MergeConflict struct {
+	Path     string      `json:"path"`
+	Base     interface{} `json:"base,omitempty"`
+	Current  interface{} `json:"current,omitempty"`
+	Proposed interface{} `json:"proposed,omitempty"`
+}
+
+// ApplyDesignChanges applies the changes proposed by a fork of a design to the current version of
+// the design, base being the version of the design the fork was made from. None of the designs
+// is modified.
+// Components and relationships are matched by id and merged field by field: a field changed only
+// by the fork is taken from the fork, a field changed only by the design is kept. Fields changed
+// by both are conflicts, resolved by the strategy: error rejects the changes, ours keeps the
+// current value, theirs and deep-merge take the proposed value.
+// The conflicts are returned whatever the strategy, so that the changes can be previewed.
+func ApplyDesignChanges(base, current, proposed *pattern.PatternFile, strategy MergeStrategy) (*pattern.PatternFile, []MergeConflict, error) {
+	switch strategy {
+	case MergeStrategyError, MergeStrategyOurs, MergeStrategyTheirs, MergeStrategyDeepMerge:
+	default:
+		return nil, nil, ErrMergePatternFiles(fmt.Errorf("unsupported merge strategy %q", strategy))
+	}
+	if base == nil {
+		base = &pattern.PatternFile{}
+	}
+
+	merged, err := clonePatternFile(current)
+	if err != nil {
+		return nil, nil, ErrMergePatternFiles(err)
+	}
+	m := &threeWayMerge{strategy: strategy}
+
+	components := m.mergeEntities("/components", componentEntities(base), componentEntities(current), componentEntities(proposed))
+	merged.Components = make([]*component.ComponentDefinition, 0, len(components))
+	for _, value := range components {
+		comp := &component.ComponentDefinition{}
+		if err := fromInterface(value, comp); err != nil {
+			return nil, nil, ErrMergePatternFiles(err)
+		}
+		merged.Components = append(merged.Components, comp)
+	}
+
+	relationships := m.mergeEntities("/relationships", relationshipEntities(base), relationshipEntities(current), relationshipEntities(proposed))
+	merged.Relationships = make([]*relationship.RelationshipDefinition, 0, len(relationships))
+	for _, value := range relationships {
+		rel := &relationship.RelationshipDefinition{}
+		if err := fromInterface(value, rel); err != nil {
+			return nil, nil, ErrMergePatternFiles(err)
+		}
+		merged.Relationships = append(merged.Relationships, rel)
+	}
+
+	if strategy == MergeStrategyError && len(m.conflicts) > 0 {
+		paths := make([]string, 0, len(m.conflicts))
+		for _, conflict := range m.conflicts {
+			paths = append(paths, conflict.Path)
+		}
+		return nil, m.conflicts, ErrMergePatternFiles(fmt.Errorf("the proposed changes conflict with the changes of the design at %s", strings.Join(paths, ", ")))
+	}
+	return merged, m.conflicts, nil
+}
+
+// entities are the components or the relationships of a design, as decoded from JSON, by id, in order.
+type entities struct {
+	ids    []string
+	values map[string]interface{}
+}
+
+func componentEntities(patternFile *pattern.PatternFile) entities {
+	e := entities{values: map[string]interface{}{}}
+	if patternFile == nil {
+		return e
+	}
+	for _, comp := range patternFile.Components {
+		if comp != nil {
+			e.add(comp.Id.String(), comp)
+		}
+	}
+	return e
+}
+
+func relationshipEntities(patternFile *pattern.PatternFile) entities {
+	e := entities{values: map[string]interface{}{}}
+	if patternFile == nil {
+		return e
+	}
+	for _, rel := range patternFile.Relationships {
+		if rel != nil {
+			e.add(rel.Id.String(), rel)
+		}
+	}
+	return e
+}
+
+func (e *entities) add(id string, value interface{}) {
+	if _, ok := e.values[id]; ok {
+		return
+	}
+	decoded, err := toInterface(value)
+	if err != nil {
+		return
+	}
+	e.ids = append(e.ids, id)
+	e.values[id] = decoded
+}
+
+type threeWayMerge struct {
+	strategy  MergeStrategy
+	conflicts []MergeConflict
+}
+
+// mergeEntities merges the components or the relationships of the designs, in the order of the
+// current design followed by the ones added by the fork.
+func (m *threeWayMerge) mergeEntities(path string, base, current, proposed entities) []interface{} {
+	merged := []interface{}{}
+	for _, id := range current.ids {
+		ours := current.values[id]
+		theirs, proposedHas := proposed.values[id]
+		old, baseHas := base.values[id]
+		switch {
+		case proposedHas:
+			merged = append(merged, m.mergeValues(path+"/"+escapeJSONPointer(id), old, ours, theirs))
+		case !baseHas:
+			// added by the design
+			merged = append(merged, ours)
+		case reflect.DeepEqual(ours, old):
+			// removed by the fork
+		default:
+			// modified by the design, removed by the fork
+			if value := m.conflict(path+"/"+escapeJSONPointer(id), old, ours, nil); value != nil {
+				merged = append(merged, value)
+			}
+		}
+	}
+	for _, id := range proposed.ids {
+		if _, ok := current.values[id]; ok {
+			continue
+		}
+		theirs := proposed.values[id]
+		old, baseHas := base.values[id]
+		switch {
+		case !baseHas:
+			// added by the fork
+			merged = append(merged, theirs)
+		case reflect.DeepEqual(theirs, old):
+			// removed by the design
+		default:
+			// removed by the design, modified by the fork
+			if value := m.conflict(path+"/"+escapeJSONPointer(id), old, nil, theirs); value != nil {
+				merged = append(merged, value)
+			}
+		}
+	}
+	return merged
+}
+
+// mergeValues merges the values changed by the design, ours, and by the fork, theirs, since base.
+// Maps are merged key by key; any other value changed by both is a conflict.
+func (m *threeWayMerge) mergeValues(path string, base, ours, theirs interface{}) interface{} {
+	switch {
+	case reflect.DeepEqual(theirs, base), reflect.DeepEqual(ours, theirs):
+		return ours
+	case reflect.DeepEqual(ours, base):
+		return theirs
+	}
+
+	oursMap, oursIsMap := ours.(map[string]interface{})
+	theirsMap, theirsIsMap := theirs.(map[string]interface{})
+	if !oursIsMap || !theirsIsMap {
+		return m.conflict(path, base, ours, theirs)
+	}
+	baseMap, _ := base.(map[string]interface{})
+
+	keys := make([]string, 0, len(oursMap)+len(theirsMap))
+	for k := range oursMap {
+		keys = append(keys, k)
+	}
+	for k := range theirsMap {
+		if _, ok := oursMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	merged := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		if value := m.mergeValues(path+"/"+escapeJSONPointer(k), baseMap[k], oursMap[k], theirsMap[k]); value != nil {
+			merged[k] = value
+		}
+	}
+	return merged
+}
+
+// conflict records the conflict and returns the value resolved by the strategy.
+func (m *threeWayMerge) conflict(path string, base, ours, theirs interface{}) interface{} {
+	m.conflicts = append(m.conflicts, MergeConflict{Path: path, Base: base, Current: ours, Proposed: theirs})
+	if m.strategy == MergeStrategyTheirs || m.strategy == MergeStrategyDeepMerge {
+		return theirs
+	}
+	return ours
+}
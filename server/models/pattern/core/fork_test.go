@@ -0,0 +1,123 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func clonedTestPatternFile(t *testing.T, pf *pattern.PatternFile) *pattern.PatternFile {
+	t.Helper()
+	clone, err := clonePatternFile(pf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clone
+}
+
+func componentMetadata(comp *component.ComponentDefinition) map[string]interface{} {
+	return comp.Configuration["metadata"].(map[string]interface{})
+}
+
+func TestApplyDesignChanges(t *testing.T) {
+	base := newTestPatternFile()
+
+	// the design labels the config map and removes the deployment
+	current := clonedTestPatternFile(t, base)
+	componentMetadata(current.Components[1])["labels"] = map[string]interface{}{"team": "shop"}
+	current.Components = current.Components[:2]
+
+	// the fork annotates the config map, renames the namespace and adds a service
+	proposed := clonedTestPatternFile(t, base)
+	componentMetadata(proposed.Components[1])["annotations"] = map[string]interface{}{"owner": "jane"}
+	componentMetadata(proposed.Components[0])["name"] = "shop"
+	proposed.Components = append(proposed.Components, newTestComponent("service", "Service"))
+
+	merged, conflicts, err := ApplyDesignChanges(base, current, proposed, MergeStrategyError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+	names := []string{}
+	for _, comp := range merged.Components {
+		names = append(names, comp.DisplayName)
+	}
+	if want := []string{"namespace", "config", "service"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected the components %v, got %v", want, names)
+	}
+	if name := componentMetadata(merged.Components[0])["name"]; name != "shop" {
+		t.Errorf("expected the namespace to be renamed, got %v", name)
+	}
+	config := componentMetadata(merged.Components[1])
+	if config["labels"] == nil || config["annotations"] == nil {
+		t.Errorf("expected the changes of both the design and the fork, got %v", config)
+	}
+	if len(current.Components) != 2 || componentMetadata(current.Components[1])["annotations"] != nil {
+		t.Error("expected the current design to be left unmodified")
+	}
+}
+
+func TestApplyDesignChanges_Conflicts(t *testing.T) {
+	base := newTestPatternFile()
+
+	current := clonedTestPatternFile(t, base)
+	componentMetadata(current.Components[0])["name"] = "billing"
+	// the fork removes the config map which the design modified
+	componentMetadata(current.Components[1])["namespace"] = "billing"
+
+	proposed := clonedTestPatternFile(t, base)
+	componentMetadata(proposed.Components[0])["name"] = "shop"
+	proposed.Components = []*component.ComponentDefinition{proposed.Components[0], proposed.Components[2]}
+
+	wantPaths := []string{
+		"/components/" + base.Components[0].Id.String() + "/configuration/metadata/name",
+		"/components/" + base.Components[1].Id.String(),
+	}
+
+	tests := []struct {
+		strategy      MergeStrategy
+		wantErr       bool
+		wantNamespace string
+		wantConfig    bool
+	}{
+		{strategy: MergeStrategyError, wantErr: true},
+		{strategy: MergeStrategyOurs, wantNamespace: "billing", wantConfig: true},
+		{strategy: MergeStrategyTheirs, wantNamespace: "shop", wantConfig: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			merged, conflicts, err := ApplyDesignChanges(base, current, proposed, tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			paths := []string{}
+			for _, conflict := range conflicts {
+				paths = append(paths, conflict.Path)
+			}
+			if !reflect.DeepEqual(paths, wantPaths) {
+				t.Errorf("expected the conflicts %v, got %v", wantPaths, paths)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name := componentMetadata(merged.Components[0])["name"]; name != tt.wantNamespace {
+				t.Errorf("expected the namespace %q, got %v", tt.wantNamespace, name)
+			}
+			hasConfig := false
+			for _, comp := range merged.Components {
+				hasConfig = hasConfig || comp.DisplayName == "config"
+			}
+			if hasConfig != tt.wantConfig {
+				t.Errorf("expected the config map to be kept %v, got %v", tt.wantConfig, hasConfig)
+			}
+		})
+	}
+
+	if _, _, err := ApplyDesignChanges(base, current, proposed, "rebase"); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
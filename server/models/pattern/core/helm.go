@@ -0,0 +1,284 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultHelmChartVersion = "0.1.0"
+
+var (
+	helmChartNameInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	helmValueKeyInvalidChars  = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	semverPattern             = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// helmTemplateEscaper escapes the delimiters of template actions, so they are rendered literally by Helm.
+	helmTemplateEscaper = strings.NewReplacer("{{", `{{ "{{" }}`, "}}", `{{ "}}" }}`)
+)
+
+// ToHelmChart exports the design as a Helm chart in outDir and returns the path of the packaged chart.
+//
+// Every component is rendered into a manifest under templates/, as RenderManifests renders it,
+// template delimiters of its values being escaped. The namespace, replicas and
+// container images of the components are exposed in values.yaml, under the name of the component
+// in lowerCamelCase, with the values of the design as defaults. The chart is written to
+// outDir/{name} and packaged as outDir/{name}-{version}.tgz.
+func ToHelmChart(patternFile *pattern.PatternFile, outDir string) (string, error) {
+	return convertFromDesign(Conversion{From: ConversionFormatDesign, To: ConversionFormatHelmChart}, patternFile, func() (string, error) {
+		return toHelmChart(patternFile, outDir)
+	})
+}
+
+func toHelmChart(patternFile *pattern.PatternFile, outDir string) (string, error) {
+	name := helmChartName(patternFile.Name)
+	version := strings.TrimPrefix(patternFile.Version, "v")
+	if !semverPattern.MatchString(version) {
+		version = defaultHelmChartVersion
+	}
+
+	names, err := ComponentNames(patternFile)
+	if err != nil {
+		return "", err
+	}
+
+	resources, err := renderResources(patternFile, declaredResolver{})
+	if err != nil {
+		return "", ErrExportHelmChart(err, patternFile.Name)
+	}
+
+	files := map[string][]byte{}
+	values := map[string]interface{}{}
+	usedKeys := map[string]int{}
+
+	for _, r := range resources {
+		i, comp := r.index, r.comp
+		// distinct names may still map to the same key, eg. "my-api" and "my api"
+		key := helmValueKey(names[i], comp.Component.Kind)
+		if n := usedKeys[key]; n > 0 {
+			usedKeys[key]++
+			key = fmt.Sprintf("%s%d", key, n+1)
+		} else {
+			usedKeys[key] = 1
+		}
+
+		compValues, placeholders := templatizeHelmValues(r.resource, key)
+		if len(compValues) > 0 {
+			values[key] = compValues
+		}
+
+		manifest, err := yaml.Marshal(r.resource)
+		if err != nil {
+			return "", ErrExportHelmChart(err, patternFile.Name)
+		}
+		// values of the design are not template expressions, escape them before adding the placeholders
+		rendered := helmTemplateEscaper.Replace(string(manifest))
+		for placeholder, expr := range placeholders {
+			rendered = strings.ReplaceAll(rendered, placeholder, expr)
+		}
+
+		fileName := fmt.Sprintf("%02d-%s-%s.yaml", i, strings.ToLower(comp.Component.Kind), helmChartName(names[i]))
+		files[filepath.Join("templates", fileName)] = []byte(rendered)
+	}
+
+	chart, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        name,
+		"description": fmt.Sprintf("Helm chart generated from the Meshery design %s", patternFile.Name),
+		"type":        "application",
+		"version":     version,
+		"appVersion":  version,
+	})
+	if err != nil {
+		return "", ErrExportHelmChart(err, patternFile.Name)
+	}
+	files["Chart.yaml"] = chart
+
+	valuesFile, err := yaml.Marshal(values)
+	if err != nil {
+		return "", ErrExportHelmChart(err, patternFile.Name)
+	}
+	files["values.yaml"] = valuesFile
+
+	if err := writeFiles(filepath.Join(outDir, name), files); err != nil {
+		return "", ErrExportHelmChart(err, patternFile.Name)
+	}
+
+	pkg := filepath.Join(outDir, fmt.Sprintf("%s-%s.tgz", name, version))
+	if err := writeTarGz(pkg, name, files); err != nil {
+		return "", ErrExportHelmChart(err, patternFile.Name)
+	}
+	return pkg, nil
+}
+
+// templatizeHelmValues replaces the namespace, replicas and container images of the
+// resource with placeholders and returns the default values along with the template
+// expression of every placeholder.
+func templatizeHelmValues(resource map[string]interface{}, key string) (map[string]interface{}, map[string]string) {
+	values := map[string]interface{}{}
+	placeholders := map[string]string{}
+	expose := func(parent map[string]interface{}, field string, path ...string) {
+		value, ok := parent[field]
+		if !ok || value == nil {
+			return
+		}
+		placeholder := fmt.Sprintf("__meshery_helm_value_%s_%d__", key, len(placeholders))
+		placeholders[placeholder] = fmt.Sprintf("{{ .Values.%s.%s }}", key, strings.Join(path, "."))
+		if _, isString := value.(string); isString {
+			placeholders[placeholder] = fmt.Sprintf("{{ .Values.%s.%s | quote }}", key, strings.Join(path, "."))
+		}
+		parent[field] = placeholder
+
+		m := values
+		for _, p := range path[:len(path)-1] {
+			next, ok := m[p].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				m[p] = next
+			}
+			m = next
+		}
+		m[path[len(path)-1]] = value
+	}
+
+	if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+		expose(metadata, "namespace", "namespace")
+	}
+
+	spec, _ := resource["spec"].(map[string]interface{})
+	if spec == nil {
+		return values, placeholders
+	}
+	expose(spec, "replicas", "replicas")
+
+	podSpec := spec
+	if template, ok := spec["template"].(map[string]interface{}); ok {
+		podSpec, _ = template["spec"].(map[string]interface{})
+	}
+	if podSpec == nil {
+		return values, placeholders
+	}
+	containers, _ := podSpec["containers"].([]interface{})
+	for i, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerName, _ := container["name"].(string)
+		containerKey := helmValueKey(containerName, fmt.Sprintf("container%d", i))
+		expose(container, "image", "images", containerKey)
+	}
+	return values, placeholders
+}
+
+// writeFiles writes the files, keyed by their path relative to dir.
+func writeFiles(dir string, files map[string][]byte) error {
+	for path, content := range files {
+		dst := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTarGz writes the files to a gzipped tarball, under the directory dir.
+func writeTarGz(dst, dir string, files map[string][]byte) (err error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, path := range paths {
+		content := files[path]
+		hdr := &tar.Header{
+			Name: filepath.ToSlash(filepath.Join(dir, path)),
+			Mode: 0644,
+			Size: int64(len(content)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func deepCopyValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[k] = deepCopyValue(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, val := range x {
+			l[i] = deepCopyValue(val)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// helmChartName returns a name valid for a chart or a file: lowercase alphanumerics and dashes.
+func helmChartName(name string) string {
+	name = strings.Trim(helmChartNameInvalidChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "design"
+	}
+	return name
+}
+
+// helmValueKey returns the name in lowerCamelCase so it can be used in template expressions.
+func helmValueKey(name, fallback string) string {
+	words := helmValueKeyInvalidChars.Split(name, -1)
+	key := ""
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(w[:1]) + w[1:]
+		} else {
+			key += strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		if fallback == "" || fallback == name {
+			return "component" + key
+		}
+		return helmValueKey(fallback, "")
+	}
+	return key
+}
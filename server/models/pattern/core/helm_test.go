@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"text/template"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func newTestHelmDesign() *pattern.PatternFile {
+	namespace := newTestComponent("ns", "Namespace")
+	config := withConfig(newTestComponent("config", "ConfigMap", namespace.Id.String()), "data", map[string]interface{}{
+		"template": "Hello {{ .Name }}}",
+	})
+	withNamespace(config, "prod")
+	deployment := withConfig(withNamespace(newTestComponent("web", "Deployment", config.Id.String()), "prod"), "spec", map[string]interface{}{
+		"replicas": 2,
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+				},
+			},
+		},
+	})
+	deployment.Component.Version = "apps/v1"
+	note := newTestComponent("note", "Comment")
+	note.Metadata.IsAnnotation = true
+	return &pattern.PatternFile{
+		Name:       "My App",
+		Version:    "v1.2.3",
+		Components: []*component.ComponentDefinition{deployment, note, config, namespace},
+	}
+}
+
+// renderHelmChart renders the templates of the chart with its default values, as helm template would.
+func renderHelmChart(t *testing.T, dir string) map[string]map[string]interface{} {
+	t.Helper()
+	valuesFile, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]interface{}{}
+	if err := ghodssyaml.Unmarshal(valuesFile, &values); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := filepath.Glob(filepath.Join(dir, "templates", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := map[string]map[string]interface{}{}
+	for _, path := range templates {
+		tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+			"quote": func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		}).ParseFiles(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, map[string]interface{}{"Values": values}); err != nil {
+			t.Fatal(err)
+		}
+		resource := map[string]interface{}{}
+		if err := ghodssyaml.Unmarshal(buf.Bytes(), &resource); err != nil {
+			t.Fatalf("%s: %v\n%s", path, err, buf)
+		}
+		rendered[filepath.Base(path)] = resource
+	}
+	return rendered
+}
+
+func TestToHelmChart(t *testing.T) {
+	outDir := t.TempDir()
+	pkg, err := ToHelmChart(newTestHelmDesign(), outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(outDir, "my-app-1.2.3.tgz"); pkg != want {
+		t.Errorf("package = %s, want %s", pkg, want)
+	}
+
+	rendered := renderHelmChart(t, filepath.Join(outDir, "my-app"))
+	files := make([]string, 0, len(rendered))
+	for name := range rendered {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	if want := []string{"00-deployment-web.yaml", "02-configmap-config.yaml", "03-namespace-ns.yaml"}; !reflect.DeepEqual(files, want) {
+		t.Fatalf("templates = %v, want %v", files, want)
+	}
+
+	tests := []struct {
+		file string
+		path []string
+		want interface{}
+	}{
+		{file: "02-configmap-config.yaml", path: []string{"data", "template"}, want: "Hello {{ .Name }}}"},
+		{file: "02-configmap-config.yaml", path: []string{"metadata", "namespace"}, want: "prod"},
+		{file: "00-deployment-web.yaml", path: []string{"spec", "replicas"}, want: float64(2)},
+		{file: "00-deployment-web.yaml", path: []string{"metadata", "namespace"}, want: "prod"},
+		{file: "00-deployment-web.yaml", path: []string{"spec", "template", "spec", "containers"}, want: []interface{}{
+			map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+		}},
+	}
+	for _, tt := range tests {
+		var got interface{} = rendered[tt.file]
+		for _, p := range tt.path {
+			got = got.(map[string]interface{})[p]
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %v = %#v, want %#v", tt.file, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHelmValueKey(t *testing.T) {
+	tests := []struct {
+		name, fallback, want string
+	}{
+		{name: "my-api", want: "myApi"},
+		{name: "My API server", want: "myAPIServer"},
+		{name: "1st", fallback: "Deployment", want: "deployment"},
+		{name: "---", fallback: "", want: "component"},
+	}
+	for _, tt := range tests {
+		if got := helmValueKey(tt.name, tt.fallback); got != tt.want {
+			t.Errorf("helmValueKey(%q, %q) = %q, want %q", tt.name, tt.fallback, got, tt.want)
+		}
+	}
+}
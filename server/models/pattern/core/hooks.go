@@ -0,0 +1,169 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// ParseHook is called around the parsing of designs by NewPatternFile and its variants, eg. to
+// trace the parsing or to enforce policies on designs. Designs imported by a design are part of
+// its parsing and do not trigger the hooks themselves.
+type ParseHook interface {
+	// PreParse is called with the raw design, an error aborts the parsing.
+	PreParse(byt []byte) error
+	// PostParse is called with the parsed design and the error of the parsing, if any. The design
+	// may be altered, an error fails the parsing.
+	PostParse(patternFile *pattern.PatternFile, err error) error
+}
+
+// ConversionFormat is a format designs are converted from or into.
+type ConversionFormat string
+
+const (
+	ConversionFormatDesign        ConversionFormat = "design"
+	ConversionFormatK8sManifest   ConversionFormat = "k8s-manifest"
+	ConversionFormatDockerCompose ConversionFormat = "docker-compose"
+	ConversionFormatCytoscape     ConversionFormat = "cytoscape"
+	ConversionFormatHelmChart     ConversionFormat = "helm-chart"
+	ConversionFormatKustomize     ConversionFormat = "kustomize"
+	// ConversionFormatV1alpha2 is the legacy design format declaring services instead of components.
+	ConversionFormatV1alpha2 ConversionFormat = "v1alpha2"
+)
+
+// Conversion is the conversion of a design from a format into another, one of them being a design.
+type Conversion struct {
+	From ConversionFormat
+	To   ConversionFormat
+}
+
+// ConvertHook is called around the conversions of designs from and to other formats.
+type ConvertHook interface {
+	// PreConvert is called with the design being converted, nil when converting into a design,
+	// an error aborts the conversion.
+	PreConvert(conversion Conversion, patternFile *pattern.PatternFile) error
+	// PostConvert is called with the design converted from or into, and the error of the
+	// conversion, if any. An error fails the conversion.
+	PostConvert(conversion Conversion, patternFile *pattern.PatternFile, err error) error
+}
+
+// processing holds the logger, the hooks, the secret resolvers and the name collision strategy set by the embedder of the package.
+var processing struct {
+	mx              sync.RWMutex
+	log             logger.Handler
+	parseHooks      []ParseHook
+	convertHooks    []ConvertHook
+	secretResolvers map[string]SecretResolver
+	nameCollisions  NameCollisionStrategy
+}
+
+// SetLogger sets the logger the package logs to, nothing is logged until it is set.
+func SetLogger(log logger.Handler) {
+	processing.mx.Lock()
+	defer processing.mx.Unlock()
+	processing.log = log
+}
+
+// RegisterParseHook adds a hook called around the parsing of every design, after the hooks
+// registered before it.
+func RegisterParseHook(hook ParseHook) {
+	processing.mx.Lock()
+	defer processing.mx.Unlock()
+	processing.parseHooks = append(processing.parseHooks, hook)
+}
+
+// RegisterConvertHook adds a hook called around every conversion, after the hooks registered
+// before it.
+func RegisterConvertHook(hook ConvertHook) {
+	processing.mx.Lock()
+	defer processing.mx.Unlock()
+	processing.convertHooks = append(processing.convertHooks, hook)
+}
+
+func processingLogger() logger.Handler {
+	processing.mx.RLock()
+	defer processing.mx.RUnlock()
+	return processing.log
+}
+
+func debugf(format string, args ...interface{}) {
+	if log := processingLogger(); log != nil {
+		log.Debugf(format, args...)
+	}
+}
+
+func warnf(format string, args ...interface{}) {
+	if log := processingLogger(); log != nil {
+		log.Warnf(format, args...)
+	}
+}
+
+func registeredParseHooks() []ParseHook {
+	processing.mx.RLock()
+	defer processing.mx.RUnlock()
+	return processing.parseHooks
+}
+
+func registeredConvertHooks() []ConvertHook {
+	processing.mx.RLock()
+	defer processing.mx.RUnlock()
+	return processing.convertHooks
+}
+
+// parseWithHooks runs the parsing of the design between the parse hooks.
+func parseWithHooks(byt []byte, parse func() (pattern.PatternFile, error)) (pattern.PatternFile, error) {
+	hooks := registeredParseHooks()
+	for _, hook := range hooks {
+		if err := hook.PreParse(byt); err != nil {
+			return pattern.PatternFile{}, ErrPatternHook(err, "pre-parse")
+		}
+	}
+
+	patternFile, err := parse()
+	for _, hook := range hooks {
+		if hookErr := hook.PostParse(&patternFile, err); hookErr != nil {
+			return patternFile, ErrPatternHook(hookErr, "post-parse")
+		}
+	}
+	return patternFile, err
+}
+
+// convertToDesign runs the conversion of a source into a design between the convert hooks.
+func convertToDesign(conversion Conversion, convert func() (pattern.PatternFile, error)) (pattern.PatternFile, error) {
+	hooks := registeredConvertHooks()
+	if err := preConvert(hooks, conversion, nil); err != nil {
+		return pattern.PatternFile{}, err
+	}
+	patternFile, err := convert()
+	return patternFile, postConvert(hooks, conversion, &patternFile, err)
+}
+
+// convertFromDesign runs the conversion of a design into another format between the convert hooks.
+func convertFromDesign[T any](conversion Conversion, patternFile *pattern.PatternFile, convert func() (T, error)) (T, error) {
+	hooks := registeredConvertHooks()
+	if err := preConvert(hooks, conversion, patternFile); err != nil {
+		var out T
+		return out, err
+	}
+	out, err := convert()
+	return out, postConvert(hooks, conversion, patternFile, err)
+}
+
+func preConvert(hooks []ConvertHook, conversion Conversion, patternFile *pattern.PatternFile) error {
+	for _, hook := range hooks {
+		if err := hook.PreConvert(conversion, patternFile); err != nil {
+			return ErrPatternHook(err, "pre-convert")
+		}
+	}
+	return nil
+}
+
+func postConvert(hooks []ConvertHook, conversion Conversion, patternFile *pattern.PatternFile, err error) error {
+	for _, hook := range hooks {
+		if hookErr := hook.PostConvert(conversion, patternFile, err); hookErr != nil {
+			return ErrPatternHook(hookErr, "post-convert")
+		}
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// rejectingHook rejects the designs with the given name and records the conversions it saw.
+type rejectingHook struct {
+	name        string
+	conversions []Conversion
+}
+
+func (h *rejectingHook) PreParse(byt []byte) error {
+	if strings.Contains(string(byt), "pre-rejected") {
+		return fmt.Errorf("design is rejected before parsing")
+	}
+	return nil
+}
+
+func (h *rejectingHook) PostParse(patternFile *pattern.PatternFile, err error) error {
+	if err == nil && patternFile.Name == h.name {
+		return fmt.Errorf("design %s is rejected", patternFile.Name)
+	}
+	return nil
+}
+
+func (h *rejectingHook) PreConvert(conversion Conversion, _ *pattern.PatternFile) error {
+	h.conversions = append(h.conversions, conversion)
+	return nil
+}
+
+func (h *rejectingHook) PostConvert(_ Conversion, patternFile *pattern.PatternFile, err error) error {
+	if err == nil && patternFile.Name == h.name {
+		return fmt.Errorf("design %s is rejected", patternFile.Name)
+	}
+	return nil
+}
+
+func TestProcessingHooks(t *testing.T) {
+	hook := &rejectingHook{name: "rejected-by-hook"}
+	RegisterParseHook(hook)
+	RegisterConvertHook(hook)
+
+	if _, err := NewPatternFile([]byte("name: accepted\ncomponents: []\n")); err != nil {
+		t.Errorf("expected the design to be accepted, got %v", err)
+	}
+	if _, err := NewPatternFile([]byte("name: pre-rejected\ncomponents: []\n")); err == nil {
+		t.Error("expected the design to be rejected before parsing")
+	}
+	if _, err := NewPatternFile([]byte("name: rejected-by-hook\ncomponents: []\n")); err == nil {
+		t.Error("expected the design to be rejected after parsing")
+	}
+
+	pf := newTestPatternFile()
+	pf.Name = "rejected-by-hook"
+	if _, err := ToCytoscapeJS(pf, nil); err == nil {
+		t.Error("expected the conversion to be rejected")
+	}
+	want := Conversion{From: ConversionFormatDesign, To: ConversionFormatCytoscape}
+	if len(hook.conversions) == 0 || hook.conversions[len(hook.conversions)-1] != want {
+		t.Errorf("expected the hook to see the conversion %v, got %v", want, hook.conversions)
+	}
+}
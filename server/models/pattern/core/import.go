@@ -0,0 +1,251 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	patternImportTimeout = 30 * time.Second
+	// maxPatternImportSize bounds the size of remote designs.
+	maxPatternImportSize = 10 << 20
+)
+
+// PatternImport references a design whose components are merged into the importing design
+// when it is parsed by NewPatternFileWithImports, so that common building blocks, eg. a monitoring
+// stack or an ingress, are maintained once and reused across designs. Imports are declared in the
+// `imports:` section of the design, eg.
+//
+//	imports:
+//	  - source: ./fragments/monitoring.yaml
+//	    namespace: monitoring
+//	  - source: https://example.com/designs/ingress.yaml
+//	    prefix: edge-
+//
+// Imported designs may import designs themselves. Components declared by the importing
+// design take precedence over imported components with the same kind and name.
+type PatternImport struct {
+	// Source is the URL of the design, or its path relative to the importing design.
+	Source string `json:"source" yaml:"source"`
+	// Prefix is prepended to the names of the imported components, so that the same design
+	// can be imported more than once.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// Namespace is set as the namespace of the imported components.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// patternImports is the `imports:` section of a design.
+type patternImports struct {
+	Imports []PatternImport `json:"imports" yaml:"imports"`
+}
+
+// PatternImporter reads the designs imported by a design.
+type PatternImporter struct {
+	// Location is the path or the URL the importing design was read from, relative sources
+	// are resolved against it. Local designs can only be imported by local designs, so that
+	// designs submitted to Meshery Server cannot read its filesystem.
+	Location string
+	// Client fetches remote designs, a client with a timeout is used when nil.
+	Client *http.Client
+
+	// chain is the locations of the designs being imported, to detect cycles.
+	chain []string
+}
+
+// NewPatternFileWithImports is NewPatternFileWithVars which also merges the designs imported by
+// the design, read from the location of the importer so that its imports can be resolved relative
+// to it. Imports fetch the designs they reference, so they are opt-in: the other constructors of
+// designs ignore the `imports:` section.
+func NewPatternFileWithImports(byt []byte, overrides map[string]string, importer *PatternImporter) (pattern.PatternFile, error) {
+	if importer == nil {
+		importer = &PatternImporter{}
+	}
+	return newPatternFileWithImporter(byt, PatternFileFormatAuto, overrides, importer)
+}
+
+// GetPatternImports returns the imports declared in the `imports:` section of the design.
+func GetPatternImports(byt []byte, format PatternFileFormat) ([]PatternImport, error) {
+	var pis patternImports
+	var err error
+	if format == PatternFileFormatAuto {
+		format = DetectPatternFileFormat(byt)
+	}
+	if format == PatternFileFormatJSON {
+		err = json.Unmarshal(byt, &pis)
+	} else {
+		err = yaml.Unmarshal(byt, &pis)
+	}
+	if err != nil {
+		return nil, ErrPatternImport(err, "")
+	}
+	return pis.Imports, nil
+}
+
+// resolveImports merges the components and the relationships of the designs imported by the
+// design into it, in the order of the imports.
+func (pi *PatternImporter) resolveImports(patternFile *pattern.PatternFile, byt []byte, format PatternFileFormat) error {
+	imports, err := GetPatternImports(byt, format)
+	if err != nil || len(imports) == 0 {
+		return err
+	}
+
+	chain := pi.chain
+	if len(chain) == 0 && pi.Location != "" {
+		root, err := pi.canonicalLocation()
+		if err != nil {
+			return ErrPatternImport(err, pi.Location)
+		}
+		chain = []string{root}
+	}
+
+	for _, imp := range imports {
+		if imp.Source == "" {
+			return ErrPatternImport(fmt.Errorf("an import of design %q has no source", patternFile.Name), "")
+		}
+		location, remote, err := pi.resolve(imp.Source)
+		if err != nil {
+			return ErrPatternImport(err, imp.Source)
+		}
+		for _, seen := range chain {
+			if seen == location {
+				return ErrPatternImport(fmt.Errorf("import cycle: %s -> %s", strings.Join(chain, " -> "), location), imp.Source)
+			}
+		}
+
+		data, err := pi.load(location, remote)
+		if err != nil {
+			return ErrPatternImport(err, imp.Source)
+		}
+		child := &PatternImporter{
+			Location: location,
+			Client:   pi.Client,
+			chain:    append(append([]string{}, chain...), location),
+		}
+		// errors of nested imports already name the import at fault
+		fragment, err := parsePatternFile(data, PatternFileFormatAuto, nil, child)
+		if err != nil {
+			return err
+		}
+
+		prepareImportedPatternFile(&fragment, imp, location)
+		merged, _, err := MergePatternFiles(patternFile, &fragment, MergeStrategyOurs)
+		if err != nil {
+			return ErrPatternImport(err, imp.Source)
+		}
+		*patternFile = *merged
+	}
+	return nil
+}
+
+// resolve returns the location of the source and whether it is remote.
+func (pi *PatternImporter) resolve(source string) (string, bool, error) {
+	if u, err := url.Parse(source); err == nil && isRemoteLocation(u) {
+		return u.String(), true, nil
+	}
+	if base, err := url.Parse(pi.Location); err == nil && isRemoteLocation(base) {
+		ref, err := url.Parse(source)
+		if err != nil {
+			return "", false, err
+		}
+		return base.ResolveReference(ref).String(), true, nil
+	}
+	if pi.Location == "" {
+		return "", false, fmt.Errorf("local designs can only be imported by designs read from a local file, import %q by its URL instead", source)
+	}
+
+	path := source
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(pi.Location), path)
+	}
+	path, err := filepath.Abs(path)
+	return path, false, err
+}
+
+func (pi *PatternImporter) canonicalLocation() (string, error) {
+	if u, err := url.Parse(pi.Location); err == nil && isRemoteLocation(u) {
+		return u.String(), nil
+	}
+	return filepath.Abs(pi.Location)
+}
+
+func (pi *PatternImporter) load(location string, remote bool) ([]byte, error) {
+	if !remote {
+		return os.ReadFile(location)
+	}
+
+	client := pi.Client
+	if client == nil {
+		client = &http.Client{Timeout: patternImportTimeout}
+	}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s returned %s", location, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPatternImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPatternImportSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", location, maxPatternImportSize)
+	}
+	return data, nil
+}
+
+func isRemoteLocation(u *url.URL) bool {
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+// prepareImportedPatternFile prefixes and namespaces the imported components. They are given ids
+// derived from the location and the prefix of the import, so that importing the same design
+// twice does not yield duplicate ids while parsing a design again yields the same ids.
+func prepareImportedPatternFile(fragment *pattern.PatternFile, imp PatternImport, location string) {
+	seed := location + "#" + imp.Prefix
+	ids := map[uuid.UUID]uuid.UUID{}
+	for _, comp := range fragment.Components {
+		if comp == nil {
+			continue
+		}
+		id := uuid.NewV5(comp.Id, seed)
+		ids[comp.Id] = id
+		comp.Id = id
+		comp.DisplayName = imp.Prefix + comp.DisplayName
+
+		if imp.Namespace != "" && comp.Component.Kind != "Namespace" {
+			metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+			metadata["namespace"] = imp.Namespace
+			comp.Configuration["metadata"] = metadata
+		}
+	}
+
+	for _, comp := range fragment.Components {
+		if comp != nil {
+			remapDependencies(comp, ids)
+		}
+	}
+
+	for _, rel := range fragment.Relationships {
+		if rel == nil {
+			continue
+		}
+		rel.Id = uuid.NewV5(rel.Id, seed)
+		remapSelectorIds(rel, ids)
+	}
+}
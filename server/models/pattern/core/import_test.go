@@ -0,0 +1,217 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+)
+
+// importTestDesign returns a design declaring the components, by kind and name, and the imports.
+// Every component but the first depends on the first one.
+func importTestDesign(name string, imports []PatternImport, comps ...[2]string) string {
+	design := fmt.Sprintf("name: %s\n", name)
+	if len(imports) > 0 {
+		design += "imports:\n"
+		for _, imp := range imports {
+			design += fmt.Sprintf("  - source: %s\n    prefix: %q\n    namespace: %q\n", imp.Source, imp.Prefix, imp.Namespace)
+		}
+	}
+	design += "components:\n"
+	for i, comp := range comps {
+		design += fmt.Sprintf("  - id: %s\n    displayName: %s\n    component:\n      kind: %s\n      version: v1\n    configuration:\n      metadata:\n        name: %s\n",
+			newTestComponent(name+"/"+comp[1], comp[0]).Id, comp[1], comp[0], comp[1])
+		if i > 0 {
+			design += fmt.Sprintf("    metadata:\n      dependsOn:\n        - %s\n", newTestComponent(name+"/"+comps[0][1], comps[0][0]).Id)
+		}
+	}
+	return design
+}
+
+func writeImportTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNewPatternFileWithImports(t *testing.T) {
+	dir := writeImportTestFiles(t, map[string]string{
+		"monitoring.yaml": importTestDesign("monitoring", nil, [2]string{"Namespace", "monitoring"}, [2]string{"Deployment", "prometheus"}, [2]string{"Service", "prometheus"}),
+		"app.yaml": importTestDesign("app", []PatternImport{
+			{Source: "monitoring.yaml", Namespace: "observability"},
+			{Source: "./monitoring.yaml", Prefix: "edge-"},
+		}, [2]string{"Deployment", "web"}, [2]string{"Service", "prometheus"}),
+	})
+	location := filepath.Join(dir, "app.yaml")
+	byt, err := os.ReadFile(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patternFile, err := NewPatternFileWithImports(byt, nil, &PatternImporter{Location: location})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byKey := map[string]*component.ComponentDefinition{}
+	ids := map[string]bool{}
+	for _, comp := range patternFile.Components {
+		byKey[mergeKey(comp)] = comp
+		if ids[comp.Id.String()] {
+			t.Errorf("id %s is shared by several components", comp.Id)
+		}
+		ids[comp.Id.String()] = true
+	}
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{
+		// the Service declared by the importing design takes precedence over the imported one
+		"Deployment/default/web", "Service/default/prometheus",
+		// imported in the namespace of the import, Namespace components excepted
+		"Deployment/observability/prometheus", "Namespace/default/monitoring", "Service/observability/prometheus",
+		// imported a second time with a prefix
+		"Deployment/default/edge-prometheus", "Namespace/default/edge-monitoring", "Service/default/edge-prometheus",
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("components = %v, want %v", keys, want)
+	}
+
+	// the dependencies of imported components reference the components of the same import
+	for dependent, dependency := range map[string]string{
+		"Deployment/observability/prometheus": "Namespace/default/monitoring",
+		"Service/observability/prometheus":    "Namespace/default/monitoring",
+		"Deployment/default/edge-prometheus":  "Namespace/default/edge-monitoring",
+	} {
+		if got, want := componentDependencies(byKey[dependent]), []string{byKey[dependency].Id.String()}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s depends on %v, want %v", dependent, got, want)
+		}
+	}
+
+	// parsing the design again yields the same ids
+	again, err := NewPatternFileWithImports(byt, nil, &PatternImporter{Location: location})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if componentsJSON(t, again.Components) != componentsJSON(t, patternFile.Components) {
+		t.Error("parsing the design again yields different components")
+	}
+}
+
+func TestNewPatternFileWithImports_Remote(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ingress.yaml":
+			fmt.Fprint(w, importTestDesign("ingress", nil, [2]string{"Deployment", "ingress"}))
+		case "/a.yaml":
+			fmt.Fprint(w, importTestDesign("a", []PatternImport{{Source: "b.yaml"}}, [2]string{"ConfigMap", "a"}))
+		case "/b.yaml":
+			fmt.Fprint(w, importTestDesign("b", []PatternImport{{Source: server.URL + "/a.yaml"}}, [2]string{"ConfigMap", "b"}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		imports  []PatternImport
+		location string
+		want     int
+		// wantErr is part of the error, if any
+		wantErr string
+	}{
+		{name: "remote import", imports: []PatternImport{{Source: server.URL + "/ingress.yaml"}}, want: 2},
+		{name: "import relative to a remote design", imports: []PatternImport{{Source: "ingress.yaml"}}, location: server.URL + "/designs/../app.yaml", want: 2},
+		{name: "import cycle", imports: []PatternImport{{Source: server.URL + "/a.yaml"}}, wantErr: "import cycle"},
+		{name: "missing design", imports: []PatternImport{{Source: server.URL + "/missing.yaml"}}, wantErr: "404 Not Found"},
+		{name: "local import of a design without location", imports: []PatternImport{{Source: "ingress.yaml"}}, wantErr: "can only be imported by designs read from a local file"},
+		{name: "import without source", imports: []PatternImport{{Source: `""`}}, wantErr: "has no source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			design := importTestDesign("app", tt.imports, [2]string{"Deployment", "web"})
+			patternFile, err := NewPatternFileWithImports([]byte(design), nil, &PatternImporter{Location: tt.location})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(patternFile.Components) != tt.want {
+				t.Errorf("design has %d components, want %d", len(patternFile.Components), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPatternFileWithImports_LocalCycle(t *testing.T) {
+	dir := writeImportTestFiles(t, map[string]string{
+		"a.yaml": importTestDesign("a", []PatternImport{{Source: "b.yaml"}}, [2]string{"ConfigMap", "a"}),
+		"b.yaml": importTestDesign("b", []PatternImport{{Source: "a.yaml"}}, [2]string{"ConfigMap", "b"}),
+	})
+	location := filepath.Join(dir, "a.yaml")
+	byt, err := os.ReadFile(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPatternFileWithImports(byt, nil, &PatternImporter{Location: location}); err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("error = %v, want an import cycle", err)
+	}
+}
+
+func TestNewPatternFile_IgnoresImports(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, importTestDesign("ingress", nil, [2]string{"Deployment", "ingress"}))
+	}))
+	defer server.Close()
+
+	design := []byte(importTestDesign("app", []PatternImport{{Source: server.URL + "/ingress.yaml"}, {Source: "/etc/passwd"}}, [2]string{"Deployment", "web"}))
+	parsers := map[string]func() error{
+		"NewPatternFile": func() error {
+			pf, err := NewPatternFile(design)
+			if err == nil && len(pf.Components) != 1 {
+				err = fmt.Errorf("design has %d components, want 1", len(pf.Components))
+			}
+			return err
+		},
+		"NewPatternFileWithFormat": func() error {
+			_, err := NewPatternFileWithFormat(design, PatternFileFormatYAML)
+			return err
+		},
+		"NewPatternFileWithVars": func() error {
+			_, err := NewPatternFileWithVars(design, map[string]string{})
+			return err
+		},
+	}
+	for name, parse := range parsers {
+		if err := parse(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("imports were fetched %d times", n)
+	}
+}
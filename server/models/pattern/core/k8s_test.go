@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/meshery/schemas/models/v1beta1/category"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/connection"
+	"github.com/meshery/schemas/models/v1beta1/model"
+)
+
+const k8sTestManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: widget
+`
+
+func newTestRegistryManager(t *testing.T, comps ...component.ComponentDefinition) *registry.RegistryManager {
+	t.Helper()
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(database.Options{
+		Filename: fmt.Sprintf("file:%s?mode=rwc", filepath.Join(t.TempDir(), "registry.db")),
+		Engine:   database.SQLITE,
+		Logger:   log,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err := registry.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comp := range comps {
+		comp := comp
+		if _, _, err := reg.RegisterEntity(connection.Connection{Kind: "kubernetes", Type: "registry"}, &comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reg
+}
+
+func TestNewPatternFileFromK8sManifest(t *testing.T) {
+	deployment := component.ComponentDefinition{
+		DisplayName: "Deployment",
+		Component: component.Component{
+			Kind:    "Deployment",
+			Version: "apps/v1",
+			Schema:  `{"type":"object"}`,
+		},
+		Metadata: component.ComponentDefinition_Metadata{
+			AdditionalProperties: map[string]interface{}{"isNamespaced": true},
+		},
+		Model: model.ModelDefinition{
+			Name:     "kubernetes",
+			Version:  "v1.0.0",
+			Category: category.CategoryDefinition{Name: "Orchestration"},
+			Model:    model.Model{Version: "v1.30.0"},
+			Status:   model.ModelDefinitionStatusEnabled,
+		},
+	}
+
+	tests := []struct {
+		name string
+		reg  func(t *testing.T) *registry.RegistryManager
+		// want are the models of the components, by name; registered components carry the version of their model
+		want map[string]string
+	}{
+		{
+			name: "without registry",
+			reg:  func(*testing.T) *registry.RegistryManager { return nil },
+			want: map[string]string{"web": "kubernetes", "config": "kubernetes", "widget": "example.com"},
+		},
+		{
+			name: "unregistered resources",
+			reg:  func(t *testing.T) *registry.RegistryManager { return newTestRegistryManager(t) },
+			want: map[string]string{"web": "kubernetes", "config": "kubernetes", "widget": "example.com"},
+		},
+		{
+			name: "registered and unregistered resources",
+			reg:  func(t *testing.T) *registry.RegistryManager { return newTestRegistryManager(t, deployment) },
+			want: map[string]string{"web": "kubernetes v1.30.0", "config": "kubernetes", "widget": "example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf, err := NewPatternFileFromK8sManifest(k8sTestManifest, "", false, tt.reg(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			for _, comp := range pf.Components {
+				got[comp.DisplayName] = strings.TrimSpace(comp.Model.Name + " " + comp.Model.Model.Version)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("models = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+// KustomizeOverlays are the settings overridden in every environment, keyed by the name of the
+// environment and then by the name of the component, its display name unless it is shared with other
+// components, see SetNameCollisionStrategy. The settings of a component are
+// merged with its manifest as a strategic merge patch, eg.
+//
+//	overlays := KustomizeOverlays{
+//		"production": {
+//			"frontend": {"spec": map[string]interface{}{"replicas": 3}},
+//		},
+//	}
+type KustomizeOverlays map[string]map[string]map[string]interface{}
+
+// ToKustomize exports the design as a Kustomize base, with an overlay for every environment of
+// overlays, in outDir/{name} and returns its path.
+//
+// Every component is rendered into a manifest of base/, as RenderManifests renders it, listed in
+// base/kustomization.yaml.
+// Every overlay is written to overlays/{environment}, referencing the base and patching the
+// components with the settings of the environment.
+func ToKustomize(patternFile *pattern.PatternFile, outDir string, overlays KustomizeOverlays) (string, error) {
+	return convertFromDesign(Conversion{From: ConversionFormatDesign, To: ConversionFormatKustomize}, patternFile, func() (string, error) {
+		return toKustomize(patternFile, outDir, overlays)
+	})
+}
+
+func toKustomize(patternFile *pattern.PatternFile, outDir string, overlays KustomizeOverlays) (string, error) {
+	name := helmChartName(patternFile.Name)
+
+	names, err := ComponentNames(patternFile)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := renderResources(patternFile, declaredResolver{})
+	if err != nil {
+		return "", ErrExportKustomize(err, patternFile.Name)
+	}
+
+	files := map[string][]byte{}
+	resources := []string{}
+	// manifests are the rendered components, by name, for the overlays to patch
+	manifests := map[string]map[string]interface{}{}
+
+	for _, r := range rendered {
+		i, comp := r.index, r.comp
+		manifest, err := yaml.Marshal(r.resource)
+		if err != nil {
+			return "", ErrExportKustomize(err, patternFile.Name)
+		}
+
+		fileName := fmt.Sprintf("%02d-%s-%s.yaml", i, strings.ToLower(comp.Component.Kind), helmChartName(names[i]))
+		files[filepath.Join("base", fileName)] = manifest
+		resources = append(resources, fileName)
+		manifests[names[i]] = r.resource
+	}
+
+	base, err := yaml.Marshal(kustomization(resources, nil))
+	if err != nil {
+		return "", ErrExportKustomize(err, patternFile.Name)
+	}
+	files[filepath.Join("base", "kustomization.yaml")] = base
+
+	for env, overrides := range overlays {
+		envDir := filepath.Join("overlays", helmChartName(env))
+		patches := []string{}
+
+		componentNames := make([]string, 0, len(overrides))
+		for componentName := range overrides {
+			componentNames = append(componentNames, componentName)
+		}
+		sort.Strings(componentNames)
+
+		for _, componentName := range componentNames {
+			resource, ok := manifests[componentName]
+			if !ok {
+				return "", ErrExportKustomize(fmt.Errorf("overlay %q overrides the settings of %q, which is not a component of the design", env, componentName), patternFile.Name)
+			}
+			patch, err := yaml.Marshal(kustomizePatch(resource, overrides[componentName]))
+			if err != nil {
+				return "", ErrExportKustomize(err, patternFile.Name)
+			}
+
+			fileName := fmt.Sprintf("patch-%s-%s.yaml", strings.ToLower(fmt.Sprint(resource["kind"])), helmChartName(componentName))
+			files[filepath.Join(envDir, fileName)] = patch
+			patches = append(patches, fileName)
+		}
+
+		overlay, err := yaml.Marshal(kustomization([]string{"../../base"}, patches))
+		if err != nil {
+			return "", ErrExportKustomize(err, patternFile.Name)
+		}
+		files[filepath.Join(envDir, "kustomization.yaml")] = overlay
+	}
+
+	dir := filepath.Join(outDir, name)
+	if err := writeFiles(dir, files); err != nil {
+		return "", ErrExportKustomize(err, patternFile.Name)
+	}
+	return dir, nil
+}
+
+func kustomization(resources, patches []string) yaml.MapSlice {
+	k := yaml.MapSlice{
+		{Key: "apiVersion", Value: "kustomize.config.k8s.io/v1beta1"},
+		{Key: "kind", Value: "Kustomization"},
+		{Key: "resources", Value: resources},
+	}
+	if len(patches) > 0 {
+		paths := make([]map[string]string, 0, len(patches))
+		for _, p := range patches {
+			paths = append(paths, map[string]string{"path": p})
+		}
+		k = append(k, yaml.MapItem{Key: "patches", Value: paths})
+	}
+	return k
+}
+
+// kustomizePatch returns the overrides as a strategic merge patch of the resource,
+// identified by its apiVersion, kind, name and namespace.
+func kustomizePatch(resource map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	patch, _ := deepCopyValue(overrides).(map[string]interface{})
+	if patch == nil {
+		patch = map[string]interface{}{}
+	}
+
+	metadata := map[string]interface{}{}
+	if m, ok := patch["metadata"].(map[string]interface{}); ok {
+		metadata = m
+	}
+	if m, ok := resource["metadata"].(map[string]interface{}); ok {
+		metadata["name"] = m["name"]
+		if ns, ok := m["namespace"]; ok {
+			metadata["namespace"] = ns
+		}
+	}
+
+	patch["apiVersion"] = resource["apiVersion"]
+	patch["kind"] = resource["kind"]
+	patch["metadata"] = metadata
+	return patch
+}
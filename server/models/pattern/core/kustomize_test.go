@@ -0,0 +1,102 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestToKustomize(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlays KustomizeOverlays
+		// wantPatches are the patches of every overlay, by file name
+		wantPatches map[string]map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name: "without overlays",
+		},
+		{
+			name: "overlay",
+			overlays: KustomizeOverlays{
+				"Production": {"web": {"spec": map[string]interface{}{"replicas": 3}}},
+			},
+			wantPatches: map[string]map[string]interface{}{
+				"overlays/production/patch-deployment-web.yaml": {
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata":   map[interface{}]interface{}{"name": "web", "namespace": "prod"},
+					"spec":       map[interface{}]interface{}{"replicas": 3},
+				},
+			},
+		},
+		{
+			name: "overlay of an annotation",
+			overlays: KustomizeOverlays{
+				"production": {"note": {"text": "hello"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patternFile := newTestHelmDesign()
+			dir, err := ToKustomize(patternFile, t.TempDir(), tt.overlays)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var base struct {
+				Resources []string `yaml:"resources"`
+			}
+			readKustomizeFile(t, filepath.Join(dir, "base", "kustomization.yaml"), &base)
+			// the base renders the same resources as RenderManifests, in the same order
+			var got bytes.Buffer
+			for _, resource := range base.Resources {
+				manifest, err := os.ReadFile(filepath.Join(dir, "base", resource))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got.WriteString("---\n")
+				got.Write(manifest)
+			}
+			want, err := RenderManifests(patternFile, declaredResolver{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("base =\n%s\nwant\n%s", got.String(), want)
+			}
+
+			for path, wantPatch := range tt.wantPatches {
+				patch := map[string]interface{}{}
+				readKustomizeFile(t, filepath.Join(dir, path), &patch)
+				if !reflect.DeepEqual(patch, wantPatch) {
+					t.Errorf("%s = %#v, want %#v", path, patch, wantPatch)
+				}
+			}
+		})
+	}
+}
+
+func readKustomizeFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(byt, v); err != nil {
+		t.Fatal(err)
+	}
+}
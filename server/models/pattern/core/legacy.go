@@ -0,0 +1,450 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models/pattern/utils"
+	"github.com/layer5io/meshkit/encoding"
+	"github.com/meshery/schemas/models/v1alpha2"
+	"github.com/meshery/schemas/models/v1alpha3/relationship"
+	"github.com/meshery/schemas/models/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// legacyCanvasTrait is the trait of the services of v1alpha2 designs holding their canvas data.
+	legacyCanvasTrait = "meshmap"
+	// legacyTraitsProperty keeps the other traits of the services of v1alpha2 designs in the
+	// metadata of their components, so that converting the design back restores them.
+	legacyTraitsProperty    = "traits"
+	defaultComponentVersion = "v1.0.0"
+)
+
+var (
+	// ErrInvalidTrait is the error of a service whose meshmap trait is malformed.
+	ErrInvalidTrait = errors.New("invalid trait")
+	// ErrInvalidMetadata is the error of a service or a component whose metadata, labels or annotations are malformed.
+	ErrInvalidMetadata = errors.New("invalid metadata")
+)
+
+// ServiceError is the error of a service of a v1alpha2 design, or of the component it is converted
+// from, which could not be converted. It wraps ErrInvalidTrait or ErrInvalidMetadata along with the
+// cause, for errors.Is and errors.As.
+type ServiceError struct {
+	// Service is the key of the service, or the display name of the component.
+	Service string
+	Field   string
+	Err     error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("service %s: %s: %v", e.Service, e.Field, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+func serviceError(service, field string, kind, err error) *ServiceError {
+	return &ServiceError{Service: service, Field: field, Err: fmt.Errorf("%w: %w", kind, err)}
+}
+
+// ConversionError aggregates the errors of every service of a design which could not be converted
+// between the v1alpha2 format and the current design schema, so that they are reported at once.
+type ConversionError struct {
+	Design string
+	Errs   []error
+}
+
+func (e *ConversionError) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("failed to convert design %s: %s", e.Design, strings.Join(messages, "; "))
+}
+
+func (e *ConversionError) Unwrap() []error {
+	return e.Errs
+}
+
+// legacyCanvasProperties are the canvas data kept in the metadata of components.
+var legacyCanvasProperties = []string{"whiteboardData", "fieldRefData"}
+
+// legacyPosition is the position of services on the canvas.
+type legacyPosition struct {
+	X float64 `json:"posX"`
+	Y float64 `json:"posY"`
+}
+
+// IsV1alpha2PatternFile returns whether the raw design is in the v1alpha2 format, which declares
+// services with settings and traits instead of components.
+func IsV1alpha2PatternFile(byt []byte) bool {
+	var probe struct {
+		SchemaVersion string                 `yaml:"schemaVersion"`
+		Services      map[string]interface{} `yaml:"services"`
+	}
+	// YAML is a superset of JSON
+	if err := yaml.Unmarshal(byt, &probe); err != nil {
+		return false
+	}
+	return probe.SchemaVersion == "" && probe.Services != nil
+}
+
+// NewPatternFileFromV1alpha2 takes in a raw design in the v1alpha2 format, in YAML or JSON, and
+// upgrades it to the current design schema.
+func NewPatternFileFromV1alpha2(byt []byte) (pattern.PatternFile, error) {
+	legacy := v1alpha2.PatternFile{}
+	if err := encoding.Unmarshal(byt, &legacy); err != nil {
+		return pattern.PatternFile{}, ErrConvertV1alpha2(err, "")
+	}
+	return ConvertV1alpha2ToDesign(&legacy)
+}
+
+// ConvertV1alpha2ToDesign upgrades a design in the v1alpha2 format to the current design schema.
+//
+// Every service becomes a component with the settings of the service as configuration. The labels,
+// annotations and namespace of the service are moved to the metadata of the configuration, and
+// the dependencies of the service, by key, name or id, become dependencies on the ids of the
+// components. The canvas data of the meshmap trait is restored, the other traits are kept in the
+// metadata of the component so that ConvertDesignToV1alpha2 restores them. Services without an
+// id are given ids derived from the id of the design and their key, so that upgrading the same
+// design again yields the same ids. When services cannot be converted, a *ConversionError holding
+// the *ServiceError of every one of them is returned.
+func ConvertV1alpha2ToDesign(legacy *v1alpha2.PatternFile) (pattern.PatternFile, error) {
+	return convertToDesign(Conversion{From: ConversionFormatV1alpha2, To: ConversionFormatDesign}, func() (pattern.PatternFile, error) {
+		return upgradeV1alpha2(legacy)
+	})
+}
+
+func upgradeV1alpha2(legacy *v1alpha2.PatternFile) (pattern.PatternFile, error) {
+	patternFile := pattern.PatternFile{
+		Id:            uuid.FromStringOrNil(legacy.PatternID),
+		Name:          legacy.Name,
+		SchemaVersion: v1beta1.DesignSchemaVersion,
+		Version:       legacy.Version,
+		Components:    []*component.ComponentDefinition{},
+		Relationships: []*relationship.RelationshipDefinition{},
+	}
+	if patternFile.Id == uuid.Nil {
+		patternFile.Id, _ = uuid.NewV4()
+	}
+
+	keys := make([]string, 0, len(legacy.Services))
+	for key, svc := range legacy.Services {
+		if svc != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// ids maps the keys, names and ids services are referred to by to the ids of their components
+	ids := map[string]string{}
+	for _, key := range keys {
+		id := legacyServiceID(key, legacy.Services[key], patternFile.Id).String()
+		ids[key] = id
+		ids[id] = id
+	}
+	for _, key := range keys {
+		if name := legacy.Services[key].Name; name != "" {
+			if _, ok := ids[name]; !ok {
+				ids[name] = ids[key]
+			}
+		}
+	}
+
+	errs := []error{}
+	for _, key := range keys {
+		svc := legacy.Services[key]
+		comp, err := upgradeV1alpha2Service(key, svc, uuid.FromStringOrNil(ids[key]))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		dependsOn := []interface{}{}
+		for _, dep := range svc.DependsOn {
+			if id, ok := ids[dep]; ok {
+				dep = id
+			}
+			dependsOn = append(dependsOn, dep)
+		}
+		if len(dependsOn) > 0 {
+			comp.Metadata.AdditionalProperties["dependsOn"] = dependsOn
+		}
+		patternFile.Components = append(patternFile.Components, comp)
+	}
+	if len(errs) > 0 {
+		return patternFile, &ConversionError{Design: legacy.Name, Errs: errs}
+	}
+
+	normalizePatternFile(&patternFile)
+	return patternFile, nil
+}
+
+// legacyServiceID returns the id of the service, the id of its canvas node when it has none.
+func legacyServiceID(key string, svc *v1alpha2.Service, designID uuid.UUID) uuid.UUID {
+	if svc.Id != nil && *svc.Id != uuid.Nil {
+		return *svc.Id
+	}
+	canvas, _ := utils.RecursiveCastMapStringInterfaceToMapStringInterface(svc.Traits)[legacyCanvasTrait].(map[string]interface{})
+	if id, ok := canvas["id"].(string); ok {
+		if nodeID := uuid.FromStringOrNil(id); nodeID != uuid.Nil {
+			return nodeID
+		}
+	}
+	return uuid.NewV5(designID, key)
+}
+
+func upgradeV1alpha2Service(key string, svc *v1alpha2.Service, id uuid.UUID) (*component.ComponentDefinition, error) {
+	name := svc.Name
+	if name == "" {
+		name = key
+	}
+	version := svc.Version
+	if version == "" {
+		version = defaultComponentVersion
+	}
+	comp := &component.ComponentDefinition{
+		Id:            id,
+		SchemaVersion: v1beta1.ComponentSchemaVersion,
+		Version:       version,
+		DisplayName:   name,
+		Component: component.Component{
+			Kind:    svc.Type,
+			Version: svc.ApiVersion,
+		},
+		Model: model.ModelDefinition{
+			SchemaVersion: v1beta1.ModelSchemaVersion,
+			Name:          svc.Model,
+		},
+	}
+
+	configuration, _ := deepCopyValue(utils.RecursiveCastMapStringInterfaceToMapStringInterface(svc.Settings)).(map[string]interface{})
+	if configuration == nil {
+		configuration = map[string]interface{}{}
+	}
+	metadata, _ := configuration["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	// the settings take precedence over the fields of the service
+	for field, values := range map[string]map[string]string{"labels": svc.Labels, "annotations": svc.Annotations} {
+		if len(values) == 0 {
+			continue
+		}
+		merged, _ := metadata[field].(map[string]interface{})
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+		for k, v := range values {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+		metadata[field] = merged
+	}
+	if _, ok := metadata["namespace"]; !ok && svc.Namespace != "" {
+		metadata["namespace"] = svc.Namespace
+	}
+	if len(metadata) > 0 {
+		configuration["metadata"] = metadata
+	}
+	comp.Configuration = configuration
+
+	traits := utils.RecursiveCastMapStringInterfaceToMapStringInterface(svc.Traits)
+	canvas, _ := traits[legacyCanvasTrait].(map[string]interface{})
+	if value, ok := canvas["meshmodel-metadata"].(map[string]interface{}); ok {
+		componentMetadata := map[string]interface{}{}
+		for k, v := range value {
+			componentMetadata[k] = v
+		}
+		// legacy designs hold booleans as strings
+		for _, field := range []string{"isNamespaced", "isAnnotation", "published"} {
+			if s, ok := componentMetadata[field].(string); ok {
+				componentMetadata[field] = strings.EqualFold(s, "true")
+			}
+		}
+		if err := fromInterface(componentMetadata, &comp.Metadata); err != nil {
+			return nil, serviceError(key, "traits.meshmap.meshmodel-metadata", ErrInvalidTrait, err)
+		}
+	}
+	comp.Metadata.IsAnnotation = comp.Metadata.IsAnnotation || svc.IsAnnotation
+	if comp.Metadata.AdditionalProperties == nil {
+		comp.Metadata.AdditionalProperties = map[string]interface{}{}
+	}
+
+	if value, ok := canvas["position"]; ok && value != nil {
+		position := legacyPosition{}
+		if err := fromInterface(value, &position); err != nil {
+			return nil, serviceError(key, "traits.meshmap.position", ErrInvalidTrait, err)
+		}
+		comp.Styles = &component.Styles{
+			Position: &struct {
+				X float64 `json:"x" yaml:"x"`
+				Y float64 `json:"y" yaml:"y"`
+			}{X: position.X, Y: position.Y},
+		}
+	}
+	for _, property := range legacyCanvasProperties {
+		if value, ok := canvas[property]; ok && value != nil {
+			comp.Metadata.AdditionalProperties[property] = value
+		}
+	}
+
+	otherTraits := map[string]interface{}{}
+	for trait, value := range traits {
+		if trait != legacyCanvasTrait {
+			otherTraits[trait] = value
+		}
+	}
+	if len(otherTraits) > 0 {
+		comp.Metadata.AdditionalProperties[legacyTraitsProperty] = otherTraits
+	}
+	return comp, nil
+}
+
+// ConvertDesignToV1alpha2 converts a design to the v1alpha2 format for the clients which only
+// understand it, the reverse of ConvertV1alpha2ToDesign.
+//
+// Services are keyed by the display names of the components, suffixed when several components
+// share a name, and depend on the keys of the services. The labels, annotations and namespace of
+// the configuration are moved to the fields of the service and the canvas data of the component
+// to its meshmap trait. When components cannot be converted, a *ConversionError holding the
+// *ServiceError of every one of them is returned.
+func ConvertDesignToV1alpha2(patternFile *pattern.PatternFile) (v1alpha2.PatternFile, error) {
+	return convertFromDesign(Conversion{From: ConversionFormatDesign, To: ConversionFormatV1alpha2}, patternFile, func() (v1alpha2.PatternFile, error) {
+		return downgradeToV1alpha2(patternFile)
+	})
+}
+
+func downgradeToV1alpha2(patternFile *pattern.PatternFile) (v1alpha2.PatternFile, error) {
+	legacy := v1alpha2.PatternFile{
+		Name:     patternFile.Name,
+		Version:  patternFile.Version,
+		Services: map[string]*v1alpha2.Service{},
+	}
+	if patternFile.Id != uuid.Nil {
+		legacy.PatternID = patternFile.Id.String()
+	}
+
+	// keys maps the ids of the components to the keys of their services
+	keys := map[string]string{}
+	used := map[string]int{}
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		key := comp.DisplayName
+		if key == "" {
+			key = comp.Id.String()
+		}
+		used[key]++
+		if n := used[key]; n > 1 {
+			key = fmt.Sprintf("%s-%d", key, n)
+		}
+		keys[comp.Id.String()] = key
+	}
+
+	errs := []error{}
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		svc, err := downgradeComponent(comp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, dep := range componentDependencies(comp) {
+			if key, ok := keys[dep]; ok {
+				dep = key
+			}
+			svc.DependsOn = append(svc.DependsOn, dep)
+		}
+		legacy.Services[keys[comp.Id.String()]] = svc
+	}
+	if len(errs) > 0 {
+		return legacy, &ConversionError{Design: patternFile.Name, Errs: errs}
+	}
+	return legacy, nil
+}
+
+func downgradeComponent(comp *component.ComponentDefinition) (*v1alpha2.Service, error) {
+	id := comp.Id
+	svc := &v1alpha2.Service{
+		Id:           &id,
+		Name:         comp.DisplayName,
+		Type:         comp.Component.Kind,
+		ApiVersion:   comp.Component.Version,
+		Model:        comp.Model.Name,
+		Version:      comp.Version,
+		IsAnnotation: comp.Metadata.IsAnnotation,
+	}
+
+	settings, _ := deepCopyValue(comp.Configuration).(map[string]interface{})
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	if metadata, ok := settings["metadata"].(map[string]interface{}); ok {
+		if value, ok := metadata["labels"]; ok && value != nil {
+			if err := fromInterface(value, &svc.Labels); err != nil {
+				return nil, serviceError(comp.DisplayName, "configuration.metadata.labels", ErrInvalidMetadata, err)
+			}
+		}
+		if value, ok := metadata["annotations"]; ok && value != nil {
+			if err := fromInterface(value, &svc.Annotations); err != nil {
+				return nil, serviceError(comp.DisplayName, "configuration.metadata.annotations", ErrInvalidMetadata, err)
+			}
+		}
+		svc.Namespace, _ = metadata["namespace"].(string)
+		delete(metadata, "labels")
+		delete(metadata, "annotations")
+		delete(metadata, "namespace")
+		if len(metadata) == 0 {
+			delete(settings, "metadata")
+		}
+	}
+	svc.Settings = settings
+
+	componentMetadata, err := toInterface(comp.Metadata)
+	if err != nil {
+		return nil, serviceError(comp.DisplayName, "metadata", ErrInvalidMetadata, err)
+	}
+	meshmodelMetadata, _ := componentMetadata.(map[string]interface{})
+	canvas := map[string]interface{}{
+		"id": comp.Id.String(),
+	}
+	for _, property := range append([]string{"dependsOn", legacyTraitsProperty}, legacyCanvasProperties...) {
+		delete(meshmodelMetadata, property)
+	}
+	canvas["meshmodel-metadata"] = meshmodelMetadata
+	if comp.Styles != nil && comp.Styles.Position != nil {
+		canvas["position"] = map[string]interface{}{
+			"posX": comp.Styles.Position.X,
+			"posY": comp.Styles.Position.Y,
+		}
+	}
+	for _, property := range legacyCanvasProperties {
+		if value, ok := comp.Metadata.AdditionalProperties[property]; ok && value != nil {
+			canvas[property] = value
+		}
+	}
+
+	traits := map[string]interface{}{}
+	if otherTraits, ok := comp.Metadata.AdditionalProperties[legacyTraitsProperty].(map[string]interface{}); ok {
+		for trait, value := range otherTraits {
+			traits[trait] = value
+		}
+	}
+	traits[legacyCanvasTrait] = canvas
+	svc.Traits = traits
+	return svc, nil
+}
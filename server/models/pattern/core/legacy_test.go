@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	meshkiterrors "github.com/layer5io/meshkit/errors"
+)
+
+const legacyDesign = `
+name: legacy
+patternID: 3c8a8a5e-4a5c-4c8c-9c4c-2d7f4b1a6f10
+services:
+  namespace:
+    type: Namespace
+    apiVersion: v1
+    model: kubernetes
+    settings: {}
+  web:
+    name: web
+    type: Deployment
+    apiVersion: apps/v1
+    model: kubernetes
+    namespace: default
+    labels:
+      app: web
+    dependsOn:
+      - namespace
+    settings:
+      spec:
+        replicas: 2
+    traits:
+      mTLS:
+        policy: strict
+      meshmap:
+        position:
+          posX: 10
+          posY: 20
+        meshmodel-metadata:
+          isNamespaced: "true"
+`
+
+func TestNewPatternFileFromV1alpha2(t *testing.T) {
+	if !IsV1alpha2PatternFile([]byte(legacyDesign)) {
+		t.Fatal("expected the design to be detected as v1alpha2")
+	}
+
+	pf, err := NewPatternFileFromV1alpha2([]byte(legacyDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsV1alpha2PatternFile([]byte(`{"schemaVersion": "designs.meshery.io/v1beta1", "components": []}`)) {
+		t.Error("expected a current design not to be detected as v1alpha2")
+	}
+	if len(pf.Components) != 2 {
+		t.Fatalf("expected 2 components, got %d", len(pf.Components))
+	}
+
+	namespace, web := pf.Components[0], pf.Components[1]
+	if web.DisplayName != "web" || web.Component.Kind != "Deployment" || web.Component.Version != "apps/v1" {
+		t.Errorf("unexpected component %s %s %s", web.DisplayName, web.Component.Kind, web.Component.Version)
+	}
+	metadata, _ := web.Configuration["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if metadata["namespace"] != "default" || labels["app"] != "web" {
+		t.Errorf("expected the namespace and the labels in the configuration, got %v", metadata)
+	}
+	if deps := componentDependencies(web); len(deps) != 1 || deps[0] != namespace.Id.String() {
+		t.Errorf("expected web to depend on the namespace component, got %v", deps)
+	}
+	if !web.Metadata.IsNamespaced {
+		t.Error("expected web to be namespaced")
+	}
+	if web.Styles == nil || web.Styles.Position == nil || web.Styles.Position.X != 10 || web.Styles.Position.Y != 20 {
+		t.Errorf("expected web to keep its position, got %+v", web.Styles)
+	}
+
+	// converting back and forth keeps the components
+	legacy, err := ConvertDesignToV1alpha2(&pf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc := legacy.Services["web"]; svc == nil || svc.Namespace != "default" || svc.Labels["app"] != "web" || len(svc.DependsOn) != 1 || svc.DependsOn[0] != "namespace" {
+		t.Errorf("unexpected service %+v", legacy.Services["web"])
+	}
+	if _, ok := legacy.Services["web"].Traits["mTLS"]; !ok {
+		t.Error("expected the traits of the service to be restored")
+	}
+
+	upgraded, err := ConvertV1alpha2ToDesign(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := componentsJSON(t, pf.Components), componentsJSON(t, upgraded.Components); want != have {
+		t.Errorf("components changed in the round trip:\nwant %s\nhave %s", want, have)
+	}
+}
+
+func TestNewPatternFileFromV1alpha2Errors(t *testing.T) {
+	_, err := NewPatternFileFromV1alpha2([]byte(`
+name: broken
+services:
+  api:
+    type: Deployment
+    traits:
+      meshmap:
+        position: left
+  web:
+    type: Deployment
+    traits:
+      meshmap:
+        position: [1, 2]
+`))
+	var conversionErr *ConversionError
+	if !errors.As(err, &conversionErr) {
+		t.Fatalf("expected a conversion error, got %v", err)
+	}
+	if len(conversionErr.Errs) != 2 {
+		t.Errorf("expected the errors of both services, got %v", conversionErr.Errs)
+	}
+	if !errors.Is(err, ErrInvalidTrait) || errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("expected only invalid traits, got %v", err)
+	}
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Service != "api" || serviceErr.Field != "traits.meshmap.position" {
+		t.Errorf("expected the error of the api service first, got %+v", serviceErr)
+	}
+}
+
+func TestNewPatternFileFromV1alpha2Malformed(t *testing.T) {
+	_, err := NewPatternFileFromV1alpha2([]byte("services: ["))
+	if code := meshkiterrors.GetCode(err); code != ErrConvertV1alpha2Code {
+		t.Errorf("expected a meshkit error with code %s, got %v", ErrConvertV1alpha2Code, err)
+	}
+}
@@ -0,0 +1,389 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LintSeverity is the severity of a lint finding.
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityInfo    LintSeverity = "info"
+)
+
+var lintSeverityRanks = map[LintSeverity]int{
+	LintSeverityError:   0,
+	LintSeverityWarning: 1,
+	LintSeverityInfo:    2,
+}
+
+// LintFinding is a problem found in a design by a lint rule. Field is a JSON pointer to the
+// field of the component causing it, e.g. /configuration/metadata/namespace, empty when the
+// finding concerns the component as a whole. Rules leave Rule and Severity to the linter.
+type LintFinding struct {
+	Rule        string       `json:"rule"`
+	Severity    LintSeverity `json:"severity"`
+	ComponentID uuid.UUID    `json:"component_id"`
+	Component   string       `json:"component"`
+	Field       string       `json:"field,omitempty"`
+	Message     string       `json:"message"`
+}
+
+// LintInput is the design linted and the means the rules have to inspect it.
+type LintInput struct {
+	PatternFile *pattern.PatternFile
+	// Resolver resolves the components to their definitions, the rules which need the
+	// definitions are skipped when it is nil.
+	Resolver ComponentResolver
+
+	definitions map[uuid.UUID]lintDefinition
+}
+
+type lintDefinition struct {
+	def *component.ComponentDefinition
+	err error
+}
+
+// Definition resolves the component to its definition, once per lint run.
+func (in *LintInput) Definition(comp *component.ComponentDefinition) (*component.ComponentDefinition, error) {
+	if in.Resolver == nil {
+		return nil, fmt.Errorf("no resolver to resolve component %s", comp.DisplayName)
+	}
+	if in.definitions == nil {
+		in.definitions = map[uuid.UUID]lintDefinition{}
+	}
+	resolved, ok := in.definitions[comp.Id]
+	if !ok {
+		resolved.def, resolved.err = in.Resolver.Resolve(comp)
+		in.definitions[comp.Id] = resolved
+	}
+	return resolved.def, resolved.err
+}
+
+// LintRule checks designs for a class of problems.
+type LintRule interface {
+	// Name identifies the rule in the findings and in the LintConfig, eg. dangling-dependency.
+	Name() string
+	Description() string
+	// Severity is the severity of the findings of the rule unless the LintConfig overrides it.
+	Severity() LintSeverity
+	Lint(in *LintInput) []LintFinding
+}
+
+// LintConfig selects the rules run by LintPatternFile and their severities.
+type LintConfig struct {
+	// Enabled restricts the rules run to the listed rules when it is not empty.
+	Enabled []string `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Disabled rules are not run.
+	Disabled []string `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Severities overrides the severities of the rules.
+	Severities map[string]LintSeverity `json:"severities,omitempty" yaml:"severities,omitempty"`
+}
+
+var lintRules = struct {
+	mx    sync.RWMutex
+	rules []LintRule
+}{rules: []LintRule{
+	missingNamespaceRule,
+	unknownComponentTypeRule,
+	danglingDependencyRule,
+	duplicatePortRule,
+	missingResourceLimitsRule,
+}}
+
+// RegisterLintRule adds a rule to the rules run by LintPatternFile. A rule replaces the rule
+// registered before it with the same name.
+func RegisterLintRule(rule LintRule) {
+	lintRules.mx.Lock()
+	defer lintRules.mx.Unlock()
+	for i, registered := range lintRules.rules {
+		if registered.Name() == rule.Name() {
+			lintRules.rules[i] = rule
+			return
+		}
+	}
+	lintRules.rules = append(lintRules.rules, rule)
+}
+
+// LintRules returns the registered rules.
+func LintRules() []LintRule {
+	lintRules.mx.RLock()
+	defer lintRules.mx.RUnlock()
+	return slices.Clone(lintRules.rules)
+}
+
+// LintPatternFile runs the rules selected by the config on the design and returns their
+// findings, the most severe first. The components are resolved through the resolver, which
+// may be nil to lint a design without the registry.
+func LintPatternFile(patternFile *pattern.PatternFile, resolver ComponentResolver, config LintConfig) ([]LintFinding, error) {
+	rules := LintRules()
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name())
+	}
+	for _, name := range append(append([]string{}, config.Enabled...), config.Disabled...) {
+		if !slices.Contains(names, name) {
+			return nil, ErrLintConfig(fmt.Errorf("unknown rule %s", name))
+		}
+	}
+	for name, severity := range config.Severities {
+		if !slices.Contains(names, name) {
+			return nil, ErrLintConfig(fmt.Errorf("unknown rule %s", name))
+		}
+		if _, ok := lintSeverityRanks[severity]; !ok {
+			return nil, ErrLintConfig(fmt.Errorf("invalid severity %s of rule %s", severity, name))
+		}
+	}
+
+	in := &LintInput{PatternFile: patternFile, Resolver: resolver}
+	findings := []LintFinding{}
+	for _, rule := range rules {
+		if (len(config.Enabled) > 0 && !slices.Contains(config.Enabled, rule.Name())) || slices.Contains(config.Disabled, rule.Name()) {
+			continue
+		}
+		severity := rule.Severity()
+		if override, ok := config.Severities[rule.Name()]; ok {
+			severity = override
+		}
+		for _, finding := range rule.Lint(in) {
+			finding.Rule = rule.Name()
+			finding.Severity = severity
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if a, b := lintSeverityRanks[findings[i].Severity], lintSeverityRanks[findings[j].Severity]; a != b {
+			return a < b
+		}
+		return findings[i].Component < findings[j].Component
+	})
+	return findings, nil
+}
+
+// lintRule is a rule implemented by a function.
+type lintRule struct {
+	name        string
+	description string
+	severity    LintSeverity
+	lint        func(in *LintInput) []LintFinding
+}
+
+func (r *lintRule) Name() string                     { return r.name }
+func (r *lintRule) Description() string              { return r.description }
+func (r *lintRule) Severity() LintSeverity           { return r.severity }
+func (r *lintRule) Lint(in *LintInput) []LintFinding { return r.lint(in) }
+
+func lintFinding(comp *component.ComponentDefinition, field, message string) LintFinding {
+	return LintFinding{ComponentID: comp.Id, Component: comp.DisplayName, Field: field, Message: message}
+}
+
+// lintedComponents returns the components of the design which are deployed.
+func lintedComponents(patternFile *pattern.PatternFile) []*component.ComponentDefinition {
+	comps := []*component.ComponentDefinition{}
+	for _, comp := range patternFile.Components {
+		if comp != nil && !comp.Metadata.IsAnnotation {
+			comps = append(comps, comp)
+		}
+	}
+	return comps
+}
+
+var missingNamespaceRule = &lintRule{
+	name:        "missing-namespace",
+	description: "Namespaced components declare their namespace, which is either declared by the design or default",
+	severity:    LintSeverityWarning,
+	lint: func(in *LintInput) []LintFinding {
+		findings := []LintFinding{}
+		declared := map[string]bool{"default": true}
+		for _, comp := range lintedComponents(in.PatternFile) {
+			if comp.Component.Kind != "Namespace" {
+				continue
+			}
+			metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+			name, _ := metadata["name"].(string)
+			if name == "" {
+				name = comp.DisplayName
+			}
+			declared[name] = true
+		}
+
+		for _, comp := range lintedComponents(in.PatternFile) {
+			namespaced := comp.Metadata.IsNamespaced
+			if def, err := in.Definition(comp); err == nil {
+				namespaced = def.Metadata.IsNamespaced
+			}
+			if !namespaced {
+				continue
+			}
+			metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+			namespace, _ := metadata["namespace"].(string)
+			switch {
+			case namespace == "":
+				findings = append(findings, lintFinding(comp, "/configuration/metadata/namespace",
+					fmt.Sprintf("%s %s declares no namespace, it is deployed to the namespace chosen on deployment", comp.Component.Kind, comp.DisplayName)))
+			case !declared[namespace] && !strings.HasPrefix(namespace, "kube-"):
+				findings = append(findings, lintFinding(comp, "/configuration/metadata/namespace",
+					fmt.Sprintf("namespace %s is not declared by the design, it must exist in the cluster", namespace)))
+			}
+		}
+		return findings
+	},
+}
+
+var unknownComponentTypeRule = &lintRule{
+	name:        "unknown-component-type",
+	description: "Components are of a kind registered by their model",
+	severity:    LintSeverityError,
+	lint: func(in *LintInput) []LintFinding {
+		findings := []LintFinding{}
+		if in.Resolver == nil {
+			return findings
+		}
+		for _, comp := range lintedComponents(in.PatternFile) {
+			if comp.Component.Kind == "" {
+				findings = append(findings, lintFinding(comp, "/component/kind", fmt.Sprintf("component %s declares no kind", comp.DisplayName)))
+				continue
+			}
+			if _, err := in.Definition(comp); err != nil {
+				findings = append(findings, lintFinding(comp, "/component/kind", err.Error()))
+			}
+		}
+		return findings
+	},
+}
+
+var danglingDependencyRule = &lintRule{
+	name:        "dangling-dependency",
+	description: "Components depend on components of the design",
+	severity:    LintSeverityError,
+	lint: func(in *LintInput) []LintFinding {
+		findings := []LintFinding{}
+		ids := map[string]bool{}
+		for _, comp := range in.PatternFile.Components {
+			if comp != nil {
+				ids[comp.Id.String()] = true
+			}
+		}
+		for _, comp := range lintedComponents(in.PatternFile) {
+			for i, dep := range componentDependencies(comp) {
+				if !ids[dep] {
+					findings = append(findings, lintFinding(comp, fmt.Sprintf("/metadata/dependsOn/%d", i),
+						fmt.Sprintf("%s depends on %s, which is not a component of the design", comp.DisplayName, dep)))
+				}
+			}
+		}
+		return findings
+	},
+}
+
+var duplicatePortRule = &lintRule{
+	name:        "duplicate-port",
+	description: "The containers of a pod and the ports of a service do not declare a port twice, and services do not share a node port",
+	severity:    LintSeverityError,
+	lint: func(in *LintInput) []LintFinding {
+		findings := []LintFinding{}
+		nodePorts := map[string]string{}
+		for _, comp := range lintedComponents(in.PatternFile) {
+			if comp.Component.Kind == "Service" {
+				var spec corev1.ServiceSpec
+				if err := fromInterface(comp.Configuration["spec"], &spec); err != nil {
+					continue
+				}
+				ports := map[string]bool{}
+				for i, port := range spec.Ports {
+					key := portKey(port.Port, port.Protocol)
+					if ports[key] {
+						findings = append(findings, lintFinding(comp, fmt.Sprintf("/configuration/spec/ports/%d/port", i),
+							fmt.Sprintf("service %s declares port %s twice", comp.DisplayName, key)))
+					}
+					ports[key] = true
+
+					if port.NodePort == 0 {
+						continue
+					}
+					key = portKey(port.NodePort, port.Protocol)
+					if other, ok := nodePorts[key]; ok {
+						findings = append(findings, lintFinding(comp, fmt.Sprintf("/configuration/spec/ports/%d/nodePort", i),
+							fmt.Sprintf("node port %s is used by service %s too", key, other)))
+					}
+					nodePorts[key] = comp.DisplayName
+				}
+				continue
+			}
+
+			spec := podSpec(comp.Component.Kind, comp.Configuration, false)
+			var pod corev1.PodSpec
+			if spec == nil || fromInterface(spec, &pod) != nil {
+				continue
+			}
+			specPath := "/configuration/" + strings.Join(podSpecPath(comp.Component.Kind), "/")
+			ports := map[string]string{}
+			for i, container := range pod.Containers {
+				for j, port := range container.Ports {
+					key := portKey(port.ContainerPort, port.Protocol)
+					if other, ok := ports[key]; ok {
+						findings = append(findings, lintFinding(comp, fmt.Sprintf("%s/containers/%d/ports/%d/containerPort", specPath, i, j),
+							fmt.Sprintf("port %s of container %s is declared by container %s too", key, container.Name, other)))
+					}
+					ports[key] = container.Name
+				}
+			}
+		}
+		return findings
+	},
+}
+
+// portKey formats a port with its protocol, TCP by default, eg. 8080/TCP.
+func portKey(port int32, protocol corev1.Protocol) string {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	return fmt.Sprintf("%d/%s", port, protocol)
+}
+
+var missingResourceLimitsRule = &lintRule{
+	name:        "missing-resource-limits",
+	description: "The containers of workloads limit their CPU and memory",
+	severity:    LintSeverityWarning,
+	lint: func(in *LintInput) []LintFinding {
+		findings := []LintFinding{}
+		for _, comp := range lintedComponents(in.PatternFile) {
+			spec := podSpec(comp.Component.Kind, comp.Configuration, false)
+			var pod corev1.PodSpec
+			if spec == nil || fromInterface(spec, &pod) != nil {
+				continue
+			}
+			specPath := "/configuration/" + strings.Join(podSpecPath(comp.Component.Kind), "/")
+			for _, list := range []struct {
+				name       string
+				containers []corev1.Container
+			}{{"initContainers", pod.InitContainers}, {"containers", pod.Containers}} {
+				for i, container := range list.containers {
+					missing := []string{}
+					for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+						if _, ok := container.Resources.Limits[resource]; !ok {
+							missing = append(missing, string(resource))
+						}
+					}
+					if len(missing) > 0 {
+						findings = append(findings, lintFinding(comp, fmt.Sprintf("%s/%s/%d/resources/limits", specPath, list.name, i),
+							fmt.Sprintf("container %s sets no %s limit", container.Name, strings.Join(missing, " nor "))))
+					}
+				}
+			}
+		}
+		return findings
+	},
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+)
+
+const lintDesign = `
+name: lint
+components:
+  - id: 1e4b0a2c-5d6e-4f70-8a9b-0c1d2e3f4a5b
+    displayName: web
+    component:
+      kind: Deployment
+      version: apps/v1
+    model:
+      name: kubernetes
+    metadata:
+      isNamespaced: true
+      dependsOn:
+        - 2f5c1b3d-6e7f-4a81-9bac-1d2e3f4a5b6c
+    configuration:
+      metadata:
+        namespace: shop
+      spec:
+        template:
+          spec:
+            containers:
+              - name: web
+                image: nginx
+                ports:
+                  - containerPort: 8080
+                resources:
+                  limits:
+                    cpu: 500m
+              - name: metrics
+                image: exporter
+                ports:
+                  - containerPort: 8080
+                resources:
+                  limits:
+                    cpu: 100m
+                    memory: 64Mi
+  - id: 3a6d2c4e-7f80-4b92-8cbd-2e3f4a5b6c7d
+    displayName: web-svc
+    component:
+      kind: Service
+      version: v1
+    model:
+      name: kubernetes
+    metadata:
+      isNamespaced: true
+    configuration:
+      spec:
+        ports:
+          - port: 80
+          - port: 80
+            protocol: TCP
+`
+
+// kindResolver resolves the components of the registered kinds.
+type kindResolver map[string]bool
+
+func (r kindResolver) Resolve(comp *component.ComponentDefinition) (*component.ComponentDefinition, error) {
+	if !r[comp.Component.Kind] {
+		return nil, fmt.Errorf("component %s is not registered", comp.Component.Kind)
+	}
+	return comp, nil
+}
+
+func TestLintPatternFile(t *testing.T) {
+	pf, err := NewPatternFile([]byte(lintDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := LintPatternFile(&pf, kindResolver{"Deployment": true}, LintConfig{
+		Severities: map[string]LintSeverity{"missing-resource-limits": LintSeverityInfo},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]LintFinding{}
+	for _, f := range findings {
+		found[f.Rule+" "+f.Component+" "+f.Field] = f
+	}
+	for _, key := range []string{
+		"missing-namespace web /configuration/metadata/namespace",
+		"missing-namespace web-svc /configuration/metadata/namespace",
+		"unknown-component-type web-svc /component/kind",
+		"dangling-dependency web /metadata/dependsOn/0",
+		"duplicate-port web /configuration/spec/template/spec/containers/1/ports/0/containerPort",
+		"duplicate-port web-svc /configuration/spec/ports/1/port",
+		"missing-resource-limits web /configuration/spec/template/spec/containers/0/resources/limits",
+	} {
+		if _, ok := found[key]; !ok {
+			t.Errorf("expected the finding %s, got %v", key, findings)
+		}
+	}
+	if len(findings) != 7 {
+		t.Errorf("expected 7 findings, got %v", findings)
+	}
+	if f := found["missing-resource-limits web /configuration/spec/template/spec/containers/0/resources/limits"]; f.Severity != LintSeverityInfo {
+		t.Errorf("expected the severity to be overridden, got %s", f.Severity)
+	}
+	if findings[0].Severity != LintSeverityError || findings[len(findings)-1].Severity != LintSeverityInfo {
+		t.Errorf("expected the most severe findings first, got %v", findings)
+	}
+
+	findings, err = LintPatternFile(&pf, nil, LintConfig{Enabled: []string{"duplicate-port", "unknown-component-type"}, Disabled: []string{"duplicate-port"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected no findings without a resolver, got %v", findings)
+	}
+
+	if _, err := LintPatternFile(&pf, nil, LintConfig{Disabled: []string{"no-such-rule"}}); err == nil {
+		t.Error("expected an unknown rule to be rejected")
+	}
+}
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1alpha3/relationship"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// MergeStrategy decides how conflicting components are resolved when merging designs.
+// Two components conflict when they have the same kind, namespace and name but different configurations.
+type MergeStrategy string
+
+const (
+	// MergeStrategyError fails the merge on the first conflict.
+	MergeStrategyError MergeStrategy = "error"
+	// MergeStrategyOurs keeps the component of the base design.
+	MergeStrategyOurs MergeStrategy = "ours"
+	// MergeStrategyTheirs replaces the component of the base design with the other one.
+	MergeStrategyTheirs MergeStrategy = "theirs"
+	// MergeStrategyDeepMerge merges the configurations of both components,
+	// values of the other design taking precedence.
+	MergeStrategyDeepMerge MergeStrategy = "deep-merge"
+)
+
+// MergeReport lists the components affected by a merge, by kind, namespace and name.
+type MergeReport struct {
+	// Added are the components only declared by the other design.
+	Added []string `json:"added"`
+	// Reassigned are the added components given a new id, as their id is the id of another component of the
+	// merged design.
+	Reassigned []string `json:"reassigned,omitempty"`
+	// Merged are the conflicting components whose configurations were deep merged.
+	Merged []string `json:"merged"`
+	// Overridden are the conflicting components of the base design replaced by the other design.
+	Overridden []string `json:"overridden"`
+	// Kept are the conflicting components of the base design kept as is.
+	Kept []string `json:"kept"`
+}
+
+// MergePatternFiles layers the other design on top of the base design and returns
+// the combined design. Neither design is modified.
+// Components are matched by kind, namespace and name; relationships of the other design are
+// added unless the base design declares a relationship with the same id. The dependencies
+// of the components and the selectors of the relationships of the other design are updated
+// to reference the components of the merged design.
+func MergePatternFiles(base, other *pattern.PatternFile, strategy MergeStrategy) (*pattern.PatternFile, *MergeReport, error) {
+	switch strategy {
+	case MergeStrategyError, MergeStrategyOurs, MergeStrategyTheirs, MergeStrategyDeepMerge:
+	default:
+		return nil, nil, ErrMergePatternFiles(fmt.Errorf("unsupported merge strategy %q", strategy))
+	}
+
+	merged, err := clonePatternFile(base)
+	if err != nil {
+		return nil, nil, ErrMergePatternFiles(err)
+	}
+	theirs, err := clonePatternFile(other)
+	if err != nil {
+		return nil, nil, ErrMergePatternFiles(err)
+	}
+
+	report := &MergeReport{}
+	// ids maps the ids of components of the other design to the ids they have in the merged design.
+	ids := map[uuid.UUID]uuid.UUID{}
+	index := map[string]int{}
+	// taken are the ids of the components of the merged design.
+	taken := map[uuid.UUID]bool{}
+	for i, comp := range merged.Components {
+		if comp != nil {
+			index[mergeKey(comp)] = i
+			taken[comp.Id] = true
+		}
+	}
+
+	// matched are the components of the other design matching a component of the base design.
+	matched := map[*component.ComponentDefinition]int{}
+	for _, comp := range theirs.Components {
+		if comp == nil {
+			continue
+		}
+		if i, ok := index[mergeKey(comp)]; ok {
+			matched[comp] = i
+			ids[comp.Id] = merged.Components[i].Id
+		}
+	}
+
+	// theirComponents are the components of the other design in the merged design, whose dependencies are remapped.
+	theirComponents := []*component.ComponentDefinition{}
+	for _, comp := range theirs.Components {
+		if comp == nil {
+			continue
+		}
+		key := mergeKey(comp)
+		i, ok := matched[comp]
+		if !ok {
+			if taken[comp.Id] {
+				id := uuid.NewV5(comp.Id, key)
+				for taken[id] {
+					id = uuid.NewV5(id, key)
+				}
+				ids[comp.Id] = id
+				comp.Id = id
+				report.Reassigned = append(report.Reassigned, key)
+			}
+			taken[comp.Id] = true
+			merged.Components = append(merged.Components, comp)
+			index[key] = len(merged.Components) - 1
+			theirComponents = append(theirComponents, comp)
+			report.Added = append(report.Added, key)
+			continue
+		}
+
+		ours := merged.Components[i]
+		if reflect.DeepEqual(ours.Configuration, comp.Configuration) {
+			continue
+		}
+
+		switch strategy {
+		case MergeStrategyError:
+			return nil, nil, ErrMergePatternFiles(fmt.Errorf("component %s is declared with different configurations in both designs", key))
+		case MergeStrategyOurs:
+			report.Kept = append(report.Kept, key)
+		case MergeStrategyTheirs:
+			comp.Id = ours.Id
+			merged.Components[i] = comp
+			theirComponents = append(theirComponents, comp)
+			report.Overridden = append(report.Overridden, key)
+		case MergeStrategyDeepMerge:
+			ours.Configuration = deepMergeMaps(ours.Configuration, comp.Configuration)
+			report.Merged = append(report.Merged, key)
+		}
+	}
+
+	for _, comp := range theirComponents {
+		remapDependencies(comp, ids)
+	}
+
+	relationships := map[uuid.UUID]bool{}
+	for _, rel := range merged.Relationships {
+		if rel != nil {
+			relationships[rel.Id] = true
+		}
+	}
+	for _, rel := range theirs.Relationships {
+		if rel == nil || relationships[rel.Id] {
+			continue
+		}
+		remapSelectorIds(rel, ids)
+		merged.Relationships = append(merged.Relationships, rel)
+	}
+
+	return merged, report, nil
+}
+
+// mergeKey is the identity of a component across designs.
+func mergeKey(comp *component.ComponentDefinition) string {
+	return comp.Component.Kind + "/" + componentNamespace(comp) + "/" + comp.DisplayName
+}
+
+// remapDependencies updates the dependencies of the component to the ids the components have in the merged design.
+func remapDependencies(comp *component.ComponentDefinition, ids map[uuid.UUID]uuid.UUID) {
+	deps := componentDependencies(comp)
+	if len(deps) == 0 {
+		return
+	}
+	remapped := make([]interface{}, 0, len(deps))
+	for _, dep := range deps {
+		if id, ok := ids[uuid.FromStringOrNil(dep)]; ok {
+			dep = id.String()
+		}
+		remapped = append(remapped, dep)
+	}
+	comp.Metadata.AdditionalProperties["dependsOn"] = remapped
+}
+
+func clonePatternFile(patternFile *pattern.PatternFile) (*pattern.PatternFile, error) {
+	byt, err := json.Marshal(patternFile)
+	if err != nil {
+		return nil, err
+	}
+	var clone pattern.PatternFile
+	if err := json.Unmarshal(byt, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// deepMergeMaps merges src into dst. Nested maps are merged recursively,
+// any other value of src replaces the value of dst.
+func deepMergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if dstMap, isMap := dst[k].(map[string]interface{}); ok && isMap {
+			dst[k] = deepMergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+func remapSelectorIds(rel *relationship.RelationshipDefinition, ids map[uuid.UUID]uuid.UUID) {
+	if rel.Selectors == nil {
+		return
+	}
+	remap := func(items []relationship.SelectorItem) {
+		for i := range items {
+			if items[i].Id == nil {
+				continue
+			}
+			if id, ok := ids[*items[i].Id]; ok {
+				items[i].Id = &id
+			}
+		}
+	}
+	for i := range *rel.Selectors {
+		selector := &(*rel.Selectors)[i]
+		remap(selector.Allow.From)
+		remap(selector.Allow.To)
+		if selector.Deny != nil {
+			remap(selector.Deny.From)
+			remap(selector.Deny.To)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func withNamespace(comp *component.ComponentDefinition, namespace string) *component.ComponentDefinition {
+	comp.Configuration["metadata"].(map[string]interface{})["namespace"] = namespace
+	return comp
+}
+
+func withConfig(comp *component.ComponentDefinition, key string, value interface{}) *component.ComponentDefinition {
+	comp.Configuration[key] = value
+	return comp
+}
+
+func withID(comp *component.ComponentDefinition, id uuid.UUID) *component.ComponentDefinition {
+	comp.Id = id
+	return comp
+}
+
+func TestMergePatternFiles(t *testing.T) {
+	baseNamespace := newTestComponent("ns", "Namespace")
+	baseDeployment := withConfig(newTestComponent("web", "Deployment"), "spec", map[string]interface{}{"replicas": 1})
+
+	tests := []struct {
+		name     string
+		other    []*component.ComponentDefinition
+		strategy MergeStrategy
+		// want are the configurations of the components of the merged design, by key
+		want       map[string]interface{}
+		wantReport MergeReport
+		wantErr    bool
+	}{
+		{
+			name:     "identical components",
+			other:    []*component.ComponentDefinition{newTestComponent("ns", "Namespace")},
+			strategy: MergeStrategyError,
+			want: map[string]interface{}{
+				"Namespace/default/ns":   nil,
+				"Deployment/default/web": map[string]interface{}{"replicas": float64(1)},
+			},
+		},
+		{
+			name:     "conflict with error strategy",
+			other:    []*component.ComponentDefinition{withConfig(newTestComponent("web", "Deployment"), "spec", map[string]interface{}{"replicas": 3})},
+			strategy: MergeStrategyError,
+			wantErr:  true,
+		},
+		{
+			name:     "conflict with ours strategy",
+			other:    []*component.ComponentDefinition{withConfig(newTestComponent("web", "Deployment"), "spec", map[string]interface{}{"replicas": 3})},
+			strategy: MergeStrategyOurs,
+			want: map[string]interface{}{
+				"Namespace/default/ns":   nil,
+				"Deployment/default/web": map[string]interface{}{"replicas": float64(1)},
+			},
+			wantReport: MergeReport{Kept: []string{"Deployment/default/web"}},
+		},
+		{
+			name:     "conflict with theirs strategy",
+			other:    []*component.ComponentDefinition{withConfig(newTestComponent("web", "Deployment"), "spec", map[string]interface{}{"replicas": 3})},
+			strategy: MergeStrategyTheirs,
+			want: map[string]interface{}{
+				"Namespace/default/ns":   nil,
+				"Deployment/default/web": map[string]interface{}{"replicas": float64(3)},
+			},
+			wantReport: MergeReport{Overridden: []string{"Deployment/default/web"}},
+		},
+		{
+			name:     "conflict with deep-merge strategy",
+			other:    []*component.ComponentDefinition{withConfig(newTestComponent("web", "Deployment"), "spec", map[string]interface{}{"paused": true})},
+			strategy: MergeStrategyDeepMerge,
+			want: map[string]interface{}{
+				"Namespace/default/ns":   nil,
+				"Deployment/default/web": map[string]interface{}{"replicas": float64(1), "paused": true},
+			},
+			wantReport: MergeReport{Merged: []string{"Deployment/default/web"}},
+		},
+		{
+			name:     "same kind and name in another namespace",
+			other:    []*component.ComponentDefinition{withID(withConfig(withNamespace(newTestComponent("web", "Deployment"), "prod"), "spec", map[string]interface{}{"replicas": 3}), uuid.NewV5(uuid.Nil, "prod-web"))},
+			strategy: MergeStrategyError,
+			want: map[string]interface{}{
+				"Namespace/default/ns":   nil,
+				"Deployment/default/web": map[string]interface{}{"replicas": float64(1)},
+				"Deployment/prod/web":    map[string]interface{}{"replicas": float64(3)},
+			},
+			wantReport: MergeReport{Added: []string{"Deployment/prod/web"}},
+		},
+		{
+			name:     "added component with the id of another component",
+			other:    []*component.ComponentDefinition{withID(newTestComponent("cache", "StatefulSet"), baseDeployment.Id)},
+			strategy: MergeStrategyError,
+			want: map[string]interface{}{
+				"Namespace/default/ns":      nil,
+				"Deployment/default/web":    map[string]interface{}{"replicas": float64(1)},
+				"StatefulSet/default/cache": nil,
+			},
+			wantReport: MergeReport{Added: []string{"StatefulSet/default/cache"}, Reassigned: []string{"StatefulSet/default/cache"}},
+		},
+		{
+			name:     "unsupported strategy",
+			strategy: MergeStrategy("union"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &pattern.PatternFile{Name: "base", Components: []*component.ComponentDefinition{baseNamespace, baseDeployment}}
+			other := &pattern.PatternFile{Name: "other", Components: tt.other}
+			before := componentsJSON(t, base.Components)
+
+			merged, report, err := MergePatternFiles(base, other, tt.strategy)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if componentsJSON(t, base.Components) != before {
+				t.Error("the base design was modified")
+			}
+
+			got := map[string]interface{}{}
+			ids := map[uuid.UUID]bool{}
+			for _, comp := range merged.Components {
+				got[mergeKey(comp)] = comp.Configuration["spec"]
+				if ids[comp.Id] {
+					t.Errorf("id %s is shared by several components", comp.Id)
+				}
+				ids[comp.Id] = true
+			}
+			if !reflect.DeepEqual(got, jsonRoundTrip(t, tt.want)) {
+				t.Errorf("merged components = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(*report, tt.wantReport) {
+				t.Errorf("report = %+v, want %+v", *report, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestMergePatternFiles_RemapsDependencies(t *testing.T) {
+	baseNamespace := newTestComponent("ns", "Namespace")
+	base := &pattern.PatternFile{Name: "base", Components: []*component.ComponentDefinition{baseNamespace}}
+
+	otherNamespace := withID(newTestComponent("ns", "Namespace"), uuid.NewV5(uuid.Nil, "other-ns"))
+	// the config map of the other design takes the id of the namespace of the base design
+	configMap := withID(newTestComponent("config", "ConfigMap", otherNamespace.Id.String()), baseNamespace.Id)
+	deployment := newTestComponent("web", "Deployment", otherNamespace.Id.String(), configMap.Id.String())
+	other := &pattern.PatternFile{Name: "other", Components: []*component.ComponentDefinition{otherNamespace, configMap, deployment}}
+
+	merged, _, err := MergePatternFiles(base, other, MergeStrategyOurs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := map[string]*component.ComponentDefinition{}
+	for _, comp := range merged.Components {
+		byKey[mergeKey(comp)] = comp
+	}
+	mergedConfigMap := byKey["ConfigMap/default/config"]
+	if mergedConfigMap.Id == baseNamespace.Id {
+		t.Fatal("the config map kept the id of the namespace")
+	}
+
+	if got, want := componentDependencies(mergedConfigMap), []string{baseNamespace.Id.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("config map depends on %v, want %v", got, want)
+	}
+	if got, want := componentDependencies(byKey["Deployment/default/web"]), []string{baseNamespace.Id.String(), mergedConfigMap.Id.String()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deployment depends on %v, want %v", got, want)
+	}
+}
+
+func jsonRoundTrip(t *testing.T, v map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	pf := &pattern.PatternFile{Components: []*component.ComponentDefinition{{Configuration: v}}}
+	clone, err := clonePatternFile(pf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clone.Components[0].Configuration
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// NameCollisionStrategy is how conversions identify the components of a design which share a name,
+// or an id, in the formats requiring unique names: the nodes of cytoscape graphs and the components
+// of the application configurations, ie. Helm charts, Kustomize bases and overlays.
+type NameCollisionStrategy string
+
+const (
+	// NameCollisionError fails the conversion, listing the names shared.
+	NameCollisionError NameCollisionStrategy = "error"
+	// NameCollisionSuffix suffixes the name of every component sharing the name of a component before
+	// it with its rank, eg. the second "api" is "api-2". The default.
+	NameCollisionSuffix NameCollisionStrategy = "suffix"
+	// NameCollisionNamespace qualifies the names shared with the namespace of the components, eg.
+	// "shop/api", and fails the conversion when components of the same namespace share a name.
+	NameCollisionNamespace NameCollisionStrategy = "namespace"
+)
+
+// ParseNameCollisionStrategy returns the strategy named, the default strategy when name is empty.
+func ParseNameCollisionStrategy(name string) (NameCollisionStrategy, error) {
+	switch strategy := NameCollisionStrategy(strings.ToLower(name)); strategy {
+	case "":
+		return NameCollisionSuffix, nil
+	case NameCollisionError, NameCollisionSuffix, NameCollisionNamespace:
+		return strategy, nil
+	}
+	return "", ErrNameCollision(fmt.Errorf("unknown strategy %q, the strategy is %s, %s or %s", name, NameCollisionError, NameCollisionSuffix, NameCollisionNamespace), "")
+}
+
+// SetNameCollisionStrategy sets the strategy of every conversion, NameCollisionSuffix until it is set.
+func SetNameCollisionStrategy(strategy NameCollisionStrategy) {
+	processing.mx.Lock()
+	defer processing.mx.Unlock()
+	processing.nameCollisions = strategy
+}
+
+func nameCollisionStrategy() NameCollisionStrategy {
+	processing.mx.RLock()
+	defer processing.mx.RUnlock()
+	if processing.nameCollisions == "" {
+		return NameCollisionSuffix
+	}
+	return processing.nameCollisions
+}
+
+// ComponentNames returns the unique name of every component of the design, by index, nil components
+// included, from their display names and as resolved by the strategy of the conversions.
+// This function always returns meshkit error
+func ComponentNames(patternFile *pattern.PatternFile) ([]string, error) {
+	names, err := uniqueComponentNames(patternFile.Components, func(comp *component.ComponentDefinition) string {
+		return comp.DisplayName
+	}, nameCollisionStrategy())
+	if err != nil {
+		return nil, ErrNameCollision(err, patternFile.Name)
+	}
+	return names, nil
+}
+
+// componentNodeIDs returns the id of the node of every component of the design in cytoscape graphs,
+// by index, the id of the component as resolved by the strategy of the conversions.
+func componentNodeIDs(patternFile *pattern.PatternFile) ([]string, error) {
+	ids, err := uniqueComponentNames(patternFile.Components, func(comp *component.ComponentDefinition) string {
+		return comp.Id.String()
+	}, nameCollisionStrategy())
+	if err != nil {
+		return nil, ErrNameCollision(err, patternFile.Name)
+	}
+	return ids, nil
+}
+
+// uniqueComponentNames resolves the names of the components, given by name, with the strategy. The
+// names of nil components are empty.
+func uniqueComponentNames(components []*component.ComponentDefinition, name func(*component.ComponentDefinition) string, strategy NameCollisionStrategy) ([]string, error) {
+	names := make([]string, len(components))
+	counts := map[string]int{}
+	for i, comp := range components {
+		if comp == nil {
+			continue
+		}
+		names[i] = name(comp)
+		counts[names[i]]++
+	}
+
+	shared := []string{}
+	for n, count := range counts {
+		if count > 1 {
+			shared = append(shared, n)
+		}
+	}
+	if len(shared) == 0 {
+		return names, nil
+	}
+	sort.Strings(shared)
+
+	switch strategy {
+	case NameCollisionError:
+		return nil, fmt.Errorf("the components %s are not unique, name them uniquely or select the %s or %s strategy", quoteAll(shared), NameCollisionSuffix, NameCollisionNamespace)
+
+	case NameCollisionNamespace:
+		for i, comp := range components {
+			if comp == nil || counts[names[i]] < 2 {
+				continue
+			}
+			names[i] = componentNamespace(comp) + "/" + names[i]
+		}
+		// the qualified names may also be shared, by components of the same namespace or named alike
+		qualified := map[string]int{}
+		for i, comp := range components {
+			if comp != nil {
+				qualified[names[i]]++
+			}
+		}
+		ambiguous := []string{}
+		for n, count := range qualified {
+			if count > 1 {
+				ambiguous = append(ambiguous, n)
+			}
+		}
+		if len(ambiguous) > 0 {
+			sort.Strings(ambiguous)
+			return nil, fmt.Errorf("the components %s are not unique within their namespace", quoteAll(ambiguous))
+		}
+		warnf("components sharing a name are qualified with their namespace: %s", strings.Join(shared, ", "))
+		return names, nil
+
+	case NameCollisionSuffix:
+		taken := map[string]bool{}
+		for n := range counts {
+			taken[n] = true
+		}
+		seen := map[string]int{}
+		for i, comp := range components {
+			if comp == nil {
+				continue
+			}
+			seen[names[i]]++
+			if seen[names[i]] == 1 {
+				continue
+			}
+			// the suffixed name must not be the name of another component either
+			rank := seen[names[i]]
+			suffixed := fmt.Sprintf("%s-%d", names[i], rank)
+			for taken[suffixed] {
+				rank++
+				suffixed = fmt.Sprintf("%s-%d", names[i], rank)
+			}
+			seen[names[i]] = rank
+			taken[suffixed] = true
+			names[i] = suffixed
+		}
+		warnf("components sharing a name are suffixed with their rank: %s", strings.Join(shared, ", "))
+		return names, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q", strategy)
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func newCollidingPatternFile() *pattern.PatternFile {
+	shop := newTestComponent("api", "Deployment")
+	shop.Configuration["metadata"].(map[string]interface{})["namespace"] = "shop"
+	billing := newTestComponent("api", "Service")
+	billing.Configuration["metadata"].(map[string]interface{})["namespace"] = "billing"
+	// the suffixed name of the second api must not collide with this one
+	api2 := newTestComponent("api-2", "ConfigMap")
+	worker := newTestComponent("worker", "Deployment", shop.Id.String())
+
+	return &pattern.PatternFile{
+		Name:       "colliding",
+		Components: []*component.ComponentDefinition{shop, billing, api2, nil, worker},
+	}
+}
+
+func TestComponentNames(t *testing.T) {
+	t.Cleanup(func() {
+		SetNameCollisionStrategy("")
+	})
+
+	tests := []struct {
+		strategy NameCollisionStrategy
+		want     []string
+		wantErr  bool
+	}{
+		{strategy: NameCollisionSuffix, want: []string{"api", "api-3", "api-2", "", "worker"}},
+		{strategy: NameCollisionNamespace, want: []string{"shop/api", "billing/api", "api-2", "", "worker"}},
+		{strategy: NameCollisionError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			SetNameCollisionStrategy(tt.strategy)
+			names, err := ComponentNames(newCollidingPatternFile())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("expected the names %v, got %v", tt.want, names)
+			}
+		})
+	}
+
+	SetNameCollisionStrategy(NameCollisionNamespace)
+	pf := newCollidingPatternFile()
+	pf.Components[1].Configuration["metadata"].(map[string]interface{})["namespace"] = "shop"
+	if _, err := ComponentNames(pf); err == nil {
+		t.Error("expected components sharing a name within a namespace to be rejected")
+	}
+
+	if _, err := ParseNameCollisionStrategy("rename"); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestToCytoscapeJS_SharedIDs(t *testing.T) {
+	t.Cleanup(func() {
+		SetNameCollisionStrategy("")
+	})
+
+	pf := newTestPatternFile()
+	// a copy of the config map, eg. pasted, shares its id
+	copied := *pf.Components[1]
+	pf.Components = append(pf.Components, &copied)
+
+	cy, err := ToCytoscapeJS(pf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, elem := range cy.Elements {
+		if ids[elem.Data.ID] {
+			t.Errorf("expected the ids of the elements to be unique, %q is not", elem.Data.ID)
+		}
+		ids[elem.Data.ID] = true
+	}
+	if !ids[copied.Id.String()+"-2"] {
+		t.Errorf("expected the copy to be suffixed, got %v", ids)
+	}
+
+	SetNameCollisionStrategy(NameCollisionError)
+	if _, err := ToCytoscapeJS(pf, nil); err == nil {
+		t.Error("expected components sharing an id to be rejected")
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/layer5io/meshery/server/models/pattern/utils"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+// PatternOverlay patches a base design for an environment, so that a single design serves
+// every environment. Overlays are declared in a companion document of the design, one YAML
+// document per environment, eg.
+//
+//	name: production
+//	namespaces:
+//	  default: prod
+//	replicas:
+//	  frontend: 3
+//	settings:
+//	  frontend:
+//	    spec:
+//	      template:
+//	        spec:
+//	          containers:
+//	            - name: frontend
+//	              image: frontend:1.4.2
+type PatternOverlay struct {
+	Name string `json:"name" yaml:"name"`
+	// Namespaces remaps the namespaces of the base design to the namespaces of the environment.
+	// Namespace components named after a remapped namespace are renamed as well.
+	Namespaces map[string]string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// Replicas are the replica counts of the components, by name, see ComponentNames.
+	Replicas map[string]int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	// Settings are merged into the configuration of the components, by name, see ComponentNames.
+	Settings map[string]map[string]interface{} `json:"settings,omitempty" yaml:"settings,omitempty"`
+}
+
+// Pattern is a design along with the overlays adapting it to every environment.
+type Pattern struct {
+	File     pattern.PatternFile
+	Overlays []PatternOverlay
+}
+
+// NewPatternWithOverlays returns the design along with the overlays declared in the companion
+// document, in YAML or JSON. An empty document declares no overlays.
+func NewPatternWithOverlays(patternFile pattern.PatternFile, overlays []byte) (*Pattern, error) {
+	p := &Pattern{File: patternFile}
+	names := map[string]bool{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(overlays))
+	for {
+		var overlay PatternOverlay
+		err := dec.Decode(&overlay)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrPatternOverlay(err, patternFile.Name)
+		}
+		if overlay.Name == "" {
+			return nil, ErrPatternOverlay(fmt.Errorf("overlay %d has no name", len(p.Overlays)+1), patternFile.Name)
+		}
+		if names[overlay.Name] {
+			return nil, ErrPatternOverlay(fmt.Errorf("overlay %q is declared more than once", overlay.Name), patternFile.Name)
+		}
+		names[overlay.Name] = true
+
+		for componentName, settings := range overlay.Settings {
+			overlay.Settings[componentName] = utils.RecursiveCastMapStringInterfaceToMapStringInterface(settings)
+		}
+		p.Overlays = append(p.Overlays, overlay)
+	}
+	return p, nil
+}
+
+// OverlayNames returns the names of the overlays, in the order they are declared.
+func (p *Pattern) OverlayNames() []string {
+	names := make([]string, 0, len(p.Overlays))
+	for _, overlay := range p.Overlays {
+		names = append(names, overlay.Name)
+	}
+	return names
+}
+
+// WithOverlay resolves the design for the environment of the named overlay. The namespaces are
+// remapped first, then the replica counts and the settings are applied, so settings take
+// precedence. The design itself is left untouched.
+func (p *Pattern) WithOverlay(name string) (*pattern.PatternFile, error) {
+	var overlay *PatternOverlay
+	for i := range p.Overlays {
+		if p.Overlays[i].Name == name {
+			overlay = &p.Overlays[i]
+			break
+		}
+	}
+	if overlay == nil {
+		return nil, ErrPatternOverlay(fmt.Errorf("overlay %q is not declared, available overlays are %v", name, p.OverlayNames()), p.File.Name)
+	}
+
+	resolved, err := clonePatternFile(&p.File)
+	if err != nil {
+		return nil, ErrPatternOverlay(err, p.File.Name)
+	}
+
+	// the components are named before their namespaces are remapped
+	componentNames, err := ComponentNames(resolved)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]map[string]interface{}{}
+	for i, comp := range resolved.Components {
+		if comp == nil {
+			continue
+		}
+		if comp.Configuration == nil {
+			comp.Configuration = map[string]interface{}{}
+		}
+		byName[componentNames[i]] = comp.Configuration
+
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		if metadata == nil {
+			continue
+		}
+		if ns, ok := metadata["namespace"].(string); ok && overlay.Namespaces[ns] != "" {
+			metadata["namespace"] = overlay.Namespaces[ns]
+		}
+		if ns, ok := metadata["name"].(string); ok && comp.Component.Kind == "Namespace" && overlay.Namespaces[ns] != "" {
+			metadata["name"] = overlay.Namespaces[ns]
+		}
+	}
+
+	for _, componentName := range sortedKeys(overlay.Replicas) {
+		config, ok := byName[componentName]
+		if !ok {
+			return nil, ErrPatternOverlay(fmt.Errorf("overlay %q sets the replicas of %q, which is not a component of the design", name, componentName), p.File.Name)
+		}
+		spec, _ := config["spec"].(map[string]interface{})
+		if spec == nil {
+			spec = map[string]interface{}{}
+		}
+		spec["replicas"] = overlay.Replicas[componentName]
+		config["spec"] = spec
+	}
+
+	for _, componentName := range sortedKeys(overlay.Settings) {
+		config, ok := byName[componentName]
+		if !ok {
+			return nil, ErrPatternOverlay(fmt.Errorf("overlay %q overrides the settings of %q, which is not a component of the design", name, componentName), p.File.Name)
+		}
+		settings, _ := deepCopyValue(overlay.Settings[componentName]).(map[string]interface{})
+		deepMergeMaps(config, settings)
+	}
+
+	return resolved, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+const overlayTestDocument = `
+name: staging
+replicas:
+  web: 2
+---
+name: production
+namespaces:
+  default: prod
+replicas:
+  web: 3
+settings:
+  web:
+    spec:
+      replicas: 5
+      paused: true
+    metadata:
+      labels:
+        tier: frontend
+`
+
+func newTestOverlayDesign() pattern.PatternFile {
+	namespace := newTestComponent("default", "Namespace")
+	web := withConfig(withNamespace(newTestComponent("web", "Deployment"), "default"), "spec", map[string]interface{}{"replicas": 1})
+	other := withNamespace(newTestComponent("other", "ConfigMap"), "kube-system")
+	return pattern.PatternFile{Name: "test", Components: []*component.ComponentDefinition{namespace, web, other}}
+}
+
+func TestPatternWithOverlay(t *testing.T) {
+	p, err := NewPatternWithOverlays(newTestOverlayDesign(), []byte(overlayTestDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"staging", "production"}; !reflect.DeepEqual(p.OverlayNames(), want) {
+		t.Errorf("overlays = %v, want %v", p.OverlayNames(), want)
+	}
+	before := componentsJSON(t, p.File.Components)
+
+	tests := []struct {
+		overlay string
+		// want are the configurations of the components, by name
+		want    map[string]map[string]interface{}
+		wantErr bool
+	}{
+		{
+			overlay: "staging",
+			want: map[string]map[string]interface{}{
+				"default": {"metadata": map[string]interface{}{"name": "default"}},
+				"web": {
+					"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+					"spec":     map[string]interface{}{"replicas": 2},
+				},
+				"other": {"metadata": map[string]interface{}{"name": "other", "namespace": "kube-system"}},
+			},
+		},
+		{
+			// settings take precedence over replicas
+			overlay: "production",
+			want: map[string]map[string]interface{}{
+				"default": {"metadata": map[string]interface{}{"name": "prod"}},
+				"web": {
+					"metadata": map[string]interface{}{"name": "web", "namespace": "prod", "labels": map[string]interface{}{"tier": "frontend"}},
+					"spec":     map[string]interface{}{"replicas": 5, "paused": true},
+				},
+				"other": {"metadata": map[string]interface{}{"name": "other", "namespace": "kube-system"}},
+			},
+		},
+		{
+			overlay: "development",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overlay, func(t *testing.T) {
+			resolved, err := p.WithOverlay(tt.overlay)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if componentsJSON(t, p.File.Components) != before {
+				t.Error("the base design was modified")
+			}
+
+			got := map[string]map[string]interface{}{}
+			for _, comp := range resolved.Components {
+				got[comp.DisplayName] = comp.Configuration
+			}
+			for name, want := range tt.want {
+				if !reflect.DeepEqual(jsonRoundTrip(t, got[name]), jsonRoundTrip(t, want)) {
+					t.Errorf("configuration of %s = %v, want %v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPatternWithOverlays_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlays string
+		overlay  string
+	}{
+		{name: "invalid document", overlays: "name: [", overlay: "production"},
+		{name: "unnamed overlay", overlays: "replicas:\n  web: 2\n"},
+		{name: "duplicate overlay", overlays: "name: production\n---\nname: production\n"},
+		{name: "replicas of an unknown component", overlays: "name: production\nreplicas:\n  api: 2\n", overlay: "production"},
+		{name: "settings of an unknown component", overlays: "name: production\nsettings:\n  api:\n    spec: {}\n", overlay: "production"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPatternWithOverlays(newTestOverlayDesign(), []byte(tt.overlays))
+			if err == nil && tt.overlay != "" {
+				_, err = p.WithOverlay(tt.overlay)
+			}
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewPatternWithOverlays_Empty(t *testing.T) {
+	p, err := NewPatternWithOverlays(newTestOverlayDesign(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Overlays) != 0 {
+		t.Errorf("overlays = %v, want none", p.OverlayNames())
+	}
+}
@@ -5,17 +5,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
+	ghodssyaml "github.com/ghodss/yaml"
 	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshery/server/models/pattern/utils"
 	"github.com/layer5io/meshkit/encoding"
 	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
 	registry "github.com/layer5io/meshkit/models/meshmodel/registry"
 	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
 	mutils "github.com/layer5io/meshkit/utils"
 	"github.com/layer5io/meshkit/utils/manifests"
 	"github.com/meshery/schemas/models/v1beta1"
 	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
 	"github.com/meshery/schemas/models/v1beta1/pattern"
 	cytoscapejs "gonum.org/v1/gonum/graph/formats/cytoscapejs"
 	"gopkg.in/yaml.v2"
@@ -33,7 +39,7 @@ func (p prettifier) Prettify(m map[string]interface{}, isSchema bool) map[string
 	res := ConvertMapInterfaceMapString(m, true, isSchema)
 	out, ok := res.(map[string]interface{})
 	if !ok {
-		fmt.Println("failed to cast")
+		warnf("failed to cast the prettified map, got %T", res)
 	}
 	return out
 }
@@ -41,7 +47,7 @@ func (p prettifier) DePrettify(m map[string]interface{}, isSchema bool) map[stri
 	res := ConvertMapInterfaceMapString(m, false, isSchema)
 	out, ok := res.(map[string]interface{})
 	if !ok {
-		fmt.Println("failed to cast")
+		warnf("failed to cast the prettified map, got %T", res)
 	}
 
 	return out
@@ -143,12 +149,109 @@ type DryRunFailureCause struct {
 	FieldPath string //Dot separated field path inside service. (For eg: <name>.settings.spec.containers (for pod) or <name>.annotations ) where <name> is the name of service/component
 }
 
-// NewPatternFile takes in raw yaml and encodes it into a construct
-func NewPatternFile(yml []byte) (patternFile pattern.PatternFile, err error) {
-	err = encoding.Unmarshal(yml, &patternFile)
+// PatternFileFormat is the serialization format of a design.
+type PatternFileFormat string
+
+const (
+	// PatternFileFormatAuto detects the format of the design from its content.
+	PatternFileFormatAuto PatternFileFormat = ""
+	PatternFileFormatJSON PatternFileFormat = "json"
+	PatternFileFormatYAML PatternFileFormat = "yaml"
+)
+
+// DetectPatternFileFormat returns the format of the given design.
+// JSON designs are objects, so anything starting with "{" is treated as JSON
+// and everything else as YAML (which is a superset of JSON).
+func DetectPatternFileFormat(byt []byte) PatternFileFormat {
+	if trimmed := bytes.TrimSpace(byt); len(trimmed) > 0 && trimmed[0] == '{' {
+		return PatternFileFormatJSON
+	}
+	return PatternFileFormatYAML
+}
+
+// NewPatternFile takes in a raw design, in YAML or JSON, and encodes it into a construct.
+// The format is detected automatically.
+func NewPatternFile(byt []byte) (patternFile pattern.PatternFile, err error) {
+	return NewPatternFileWithFormat(byt, PatternFileFormatAuto)
+}
+
+// NewPatternFileWithFormat takes in a raw design in the given format and encodes it into a construct.
+// Both formats go through the same normalization, so the same design yields identical constructs.
+func NewPatternFileWithFormat(byt []byte, format PatternFileFormat) (patternFile pattern.PatternFile, err error) {
+	return newPatternFile(byt, format, nil)
+}
+
+// NewPatternFileWithVars is NewPatternFile with overrides for the variables
+// declared in the `vars:` section of the design (e.g. passed as flags).
+func NewPatternFileWithVars(byt []byte, overrides map[string]string) (patternFile pattern.PatternFile, err error) {
+	return newPatternFile(byt, PatternFileFormatAuto, overrides)
+}
+
+// newPatternFile decodes the design and resolves the ${var} references in the
+// configuration of its components. The `imports:` section of the design is ignored,
+// imports are only followed by NewPatternFileWithImports.
+func newPatternFile(byt []byte, format PatternFileFormat, overrides map[string]string) (patternFile pattern.PatternFile, err error) {
+	return newPatternFileWithImporter(byt, format, overrides, nil)
+}
+
+// newPatternFileWithImporter parses the design between the registered parse hooks.
+func newPatternFileWithImporter(byt []byte, format PatternFileFormat, overrides map[string]string, importer *PatternImporter) (pattern.PatternFile, error) {
+	return parseWithHooks(byt, func() (pattern.PatternFile, error) {
+		return parsePatternFile(byt, format, overrides, importer)
+	})
+}
+
+// parsePatternFile decodes the design, merges the designs it imports when an importer is given
+// and resolves the ${var} references in the configuration of its components, imported components included.
+func parsePatternFile(byt []byte, format PatternFileFormat, overrides map[string]string, importer *PatternImporter) (patternFile pattern.PatternFile, err error) {
+	if format == PatternFileFormatAuto {
+		format = DetectPatternFileFormat(byt)
+	}
+
+	switch format {
+	case PatternFileFormatJSON:
+		if err = json.Unmarshal(byt, &patternFile); err != nil {
+			return patternFile, encoding.ErrUnmarshal(err)
+		}
+	case PatternFileFormatYAML:
+		// Decoded through JSON, as the metadata of components keeps its additional properties,
+		// eg. dependsOn, only when decoded from JSON.
+		jsonByt, err := ghodssyaml.YAMLToJSON(byt)
+		if err != nil {
+			return patternFile, encoding.ErrDecodeYaml(err)
+		}
+		if err = json.Unmarshal(jsonByt, &patternFile); err != nil {
+			return patternFile, encoding.ErrUnmarshal(err)
+		}
+	default:
+		return patternFile, ErrUnsupportedPatternFileFormat(string(format))
+	}
+
+	normalizePatternFile(&patternFile)
+
+	if importer != nil {
+		if err = importer.resolveImports(&patternFile, byt, format); err != nil {
+			return patternFile, err
+		}
+	}
+
+	vars, err := GetPatternVars(byt, format, overrides)
 	if err != nil {
 		return patternFile, err
 	}
+	if len(vars) > 0 {
+		for _, component := range patternFile.Components {
+			configuration, ok := interpolatePatternVars(component.Configuration, vars).(map[string]interface{})
+			if !ok {
+				return patternFile, ErrResolvePatternVars(fmt.Errorf("the configuration of component %s is not a map", component.DisplayName))
+			}
+			component.Configuration = configuration
+		}
+	}
+	return
+}
+
+func normalizePatternFile(patternFile *pattern.PatternFile) {
 	for _, component := range patternFile.Components {
 		// If an explicit name is not given to the service then use
 		// the service identifier as its name
@@ -162,8 +265,6 @@ func NewPatternFile(yml []byte) (patternFile pattern.PatternFile, err error) {
 			component.Configuration = map[string]interface{}{}
 		}
 	}
-
-	return
 }
 
 // AssignAdditionalLabels adds labels to identify resources deployed by meshery.
@@ -185,13 +286,22 @@ func AssignAdditionalLabels(comp *component.ComponentDefinition) error {
 		}
 	}
 
-	existingLabels["resource.pattern.meshery.io/id"] = comp.Id.String() //set the patternID to track back the object
+	existingLabels[models.DesignComponentLabel] = comp.Id.String() //set the patternID to track back the object
 	comp.Configuration["labels"] = existingLabels
 	return nil
 }
 
-// ToCytoscapeJS converts pattern file into cytoscape object
+// ToCytoscapeJS converts pattern file into cytoscape object. Every component is a node holding the
+// component in the "_data" field of its scratch, and every dependency between components is an edge
+// from the dependency to the dependent component. The nodes are identified by the ids of the
+// components, the components sharing an id are identified as selected by SetNameCollisionStrategy.
 func ToCytoscapeJS(patternFile *pattern.PatternFile, log logger.Handler) (cytoscapejs.GraphElem, error) {
+	return convertFromDesign(Conversion{From: ConversionFormatDesign, To: ConversionFormatCytoscape}, patternFile, func() (cytoscapejs.GraphElem, error) {
+		return toCytoscapeJS(patternFile)
+	})
+}
+
+func toCytoscapeJS(patternFile *pattern.PatternFile) (cytoscapejs.GraphElem, error) {
 	var cy cytoscapejs.GraphElem
 
 	// Not specifying any cytoscapejs layout
@@ -200,20 +310,27 @@ func ToCytoscapeJS(patternFile *pattern.PatternFile, log logger.Handler) (cytosc
 	// Not specifying styles, may get applied on the
 	// client side
 
-	// Set up the nodes
-	for _, cmp := range patternFile.Components {
+	// Set up the nodes, the components sharing an id are identified by the name collision strategy
+	ids, err := componentNodeIDs(patternFile)
+	if err != nil {
+		return cy, err
+	}
+	// nodes maps the ids of the components to the first of their nodes, the one dependencies point to
+	nodes := map[string]string{}
+	for i, cmp := range patternFile.Components {
+		if cmp == nil {
+			continue
+		}
 		elemData := cytoscapejs.ElemData{
-			ID: getCytoscapeElementID(cmp.Id.String(), cmp, log),
+			ID: ids[i],
 		}
-
-		elemPosition, err := getCytoscapeJSPosition(cmp, log)
-		if err != nil {
-			return cy, err
+		if _, ok := nodes[cmp.Id.String()]; !ok {
+			nodes[cmp.Id.String()] = elemData.ID
 		}
 
 		elem := cytoscapejs.Element{
 			Data:       elemData,
-			Position:   &elemPosition,
+			Position:   getCytoscapeJSPosition(cmp),
 			Selectable: true,
 			Grabbable:  true,
 			Scratch: map[string]component.ComponentDefinition{
@@ -224,6 +341,28 @@ func ToCytoscapeJS(patternFile *pattern.PatternFile, log logger.Handler) (cytosc
 		cy.Elements = append(cy.Elements, elem)
 	}
 
+	// Set up the edges
+	for i, cmp := range patternFile.Components {
+		if cmp == nil {
+			continue
+		}
+		id := ids[i]
+		for _, dep := range componentDependencies(cmp) {
+			source, ok := nodes[dep]
+			if !ok {
+				continue
+			}
+			cy.Elements = append(cy.Elements, cytoscapejs.Element{
+				Data: cytoscapejs.ElemData{
+					ID:     source + "-" + id,
+					Source: source,
+					Target: id,
+				},
+				Selectable: true,
+			})
+		}
+	}
+
 	return cy, nil
 }
 
@@ -236,74 +375,105 @@ func NewPatternFileFromCytoscapeJSJSON(name string, byt []byte) (pattern.Pattern
 	if err := json.Unmarshal(byt, &cy); err != nil {
 		return pattern.PatternFile{}, ErrPatternFromCytoscape(err)
 	}
+	return FromCytoscapeJS(name, cy)
+}
 
+// FromCytoscapeJS reconstructs the design edited as a cytoscape graph, the reverse of ToCytoscapeJS.
+// Components are read from the "_data" field of the scratch of the nodes, at the position of the nodes,
+// and the dependencies between components from the edges, which go from the dependency to the
+// dependent component. Dependencies on components which are not nodes of the graph are kept.
+// This function always returns meshkit error
+func FromCytoscapeJS(name string, cy cytoscapejs.GraphElem) (pattern.PatternFile, error) {
+	return convertToDesign(Conversion{From: ConversionFormatCytoscape, To: ConversionFormatDesign}, func() (pattern.PatternFile, error) {
+		return fromCytoscapeJS(name, cy)
+	})
+}
+
+func fromCytoscapeJS(name string, cy cytoscapejs.GraphElem) (pattern.PatternFile, error) {
 	if name == "" {
 		name = "MesheryGeneratedPattern"
 	}
 
 	id, _ := uuid.NewV4()
-	// Convert cytoscape struct to patternfile
 	pf := pattern.PatternFile{
-		Id:         id,
-		Name:       name,
-		Components: []*component.ComponentDefinition{},
-	}
-
-	// dependsOnMap := make(map[string][]string, 0) //used to figure out dependencies from componentmetadata.additionalProperties["dependsOn"]
-	// eleToSvc := make(map[string]string)          //used to map cyto element ID uniquely to the name of the service created.
-	countDuplicates := make(map[string]int)
-	//store the names of services and their count
-	err := processCytoElementsWithPattern(cy.Elements, func(comp component.ComponentDefinition, ele cytoscapejs.Element) error {
-		name := comp.DisplayName
-		countDuplicates[name]++
+		Id:            id,
+		Name:          name,
+		SchemaVersion: v1beta1.DesignSchemaVersion,
+		Components:    []*component.ComponentDefinition{},
+	}
+
+	nodes := []cytoscapejs.Element{}
+	edges := []cytoscapejs.Element{}
+	for _, elem := range cy.Elements {
+		if elem.Data.Source == "" && elem.Data.Target == "" {
+			nodes = append(nodes, elem)
+			continue
+		}
+		if elem.Data.Source == "" || elem.Data.Target == "" {
+			return pf, ErrPatternFromCytoscape(fmt.Errorf("edge %q must have both a source and a target", elem.Data.ID))
+		}
+		edges = append(edges, elem)
+	}
+
+	// components maps the ids of the nodes to their components
+	components := map[string]*component.ComponentDefinition{}
+	err := processCytoElementsWithPattern(nodes, func(comp component.ComponentDefinition, ele cytoscapejs.Element) error {
+		if comp.Id == uuid.Nil {
+			comp.Id = uuid.FromStringOrNil(ele.Data.ID)
+		}
+		if comp.Id == uuid.Nil {
+			return fmt.Errorf("component %q has no id", comp.DisplayName)
+		}
+		if _, ok := components[ele.Data.ID]; ok {
+			return fmt.Errorf("node %q is declared more than once", ele.Data.ID)
+		}
+		components[ele.Data.ID] = &comp
+		pf.Components = append(pf.Components, &comp)
 		return nil
 	})
-
 	if err != nil {
 		return pf, ErrPatternFromCytoscape(err)
 	}
 
-	//Populate the dependsOn field with appropriate unique service names
-	// err = processCytoElementsWithPattern(cy.Elements, func(declaration component.ComponentDefinition, ele cytoscapejs.Element) error {
-	//Extract dependsOn, if present
-
-	// dependencies, err := mutils.Cast[[]string](declaration.Metadata.AdditionalProperties["dependsOn"])
-
-	// if err == nil {
-	// 	declId := declaration.Id.String()
-	// 	dependsOnMap[declId] = append(dependsOnMap[declId], dependencies...)
-	// }
-
-	// As client and server both depends on Id for determining uniqueness.
-	// It isn't a problem if declaration have the same name.
-
-	// Only make the name unique when duplicates are encountered. This allows clients to preserve and propagate the unique name they want to give to their workload
-	// uniqueName := declaration.DisplayName
-	// if countDuplicates[uniqueName] > 1 {
-	// 	//set appropriate unique service name
-	// 	uniqueName = strings.ToLower(uniqueName)
-	// 	uniqueName += "-" + utils.GetRandomAlphabetsOfDigit(5)
-	// }
-	// eleToSvc[ele.Data.ID] = uniqueName //will be used while adding depends-on
-	// pf.Services[uniqueName] = &svc
-	// 	return nil
-	// })
-
-	// if err != nil {
-	// 	return pf, ErrPatternFromCytoscape(err)
-	// }
-
-	// // add depends-on field
-	// for child, parents := range dependsOnMap {
-	// 	childSvc := eleToSvc[child]
-	// 	if childSvc != "" {
-	// 		for _, parent := range parents {
-	// 			if eleToSvc[parent] != "" {
-	// 				pf.Services[childSvc].DependsOn = append(pf.Services[childSvc].DependsOn, eleToSvc[parent])
-	// 			}
-	// 		}
-	// 	}
-	// }
+	dependencies := map[string][]interface{}{}
+	for _, edge := range edges {
+		dep, ok := components[edge.Data.Source]
+		if !ok {
+			return pf, ErrPatternFromCytoscape(fmt.Errorf("edge %q starts from %q, which is not a node of the graph", edge.Data.ID, edge.Data.Source))
+		}
+		if _, ok := components[edge.Data.Target]; !ok {
+			return pf, ErrPatternFromCytoscape(fmt.Errorf("edge %q ends at %q, which is not a node of the graph", edge.Data.ID, edge.Data.Target))
+		}
+		depID := dep.Id.String()
+		if !slices.Contains(dependencies[edge.Data.Target], interface{}(depID)) {
+			dependencies[edge.Data.Target] = append(dependencies[edge.Data.Target], depID)
+		}
+	}
+
+	componentIDs := map[string]bool{}
+	for _, comp := range pf.Components {
+		componentIDs[comp.Id.String()] = true
+	}
+	for nodeID, comp := range components {
+		// the edges are the dependencies between the components of the graph
+		dependsOn := []interface{}{}
+		for _, dep := range componentDependencies(comp) {
+			if !componentIDs[dep] {
+				dependsOn = append(dependsOn, dep)
+			}
+		}
+		dependsOn = append(dependsOn, dependencies[nodeID]...)
+
+		if comp.Metadata.AdditionalProperties == nil {
+			comp.Metadata.AdditionalProperties = map[string]interface{}{}
+		}
+		delete(comp.Metadata.AdditionalProperties, "dependsOn")
+		if len(dependsOn) > 0 {
+			comp.Metadata.AdditionalProperties["dependsOn"] = dependsOn
+		}
+	}
+
+	normalizePatternFile(&pf)
 	return pf, nil
 }
 
@@ -311,38 +481,44 @@ func NewPatternFileFromCytoscapeJSJSON(name string, byt []byte) (pattern.Pattern
 func processCytoElementsWithPattern(eles []cytoscapejs.Element, callback func(svc component.ComponentDefinition, ele cytoscapejs.Element) error) error {
 	for _, elem := range eles {
 		// Try to create component.ComponentDefinition object from the elem.scratch's _data field
-		// if this fails then immediately fail the process and return an error
-		castedScratch, ok := elem.Scratch.(map[string]interface{})
-		if !ok {
+		// if this fails then immediately fail the process and return an error.
+		// The scratch is normalized through JSON, as graphs built in memory hold typed components.
+		scratchByt, err := json.Marshal(elem.Scratch)
+		if err != nil {
+			return fmt.Errorf("failed to serialize the scratch of node %q", elem.Data.ID)
+		}
+		castedScratch := map[string]json.RawMessage{}
+		if err := json.Unmarshal(scratchByt, &castedScratch); err != nil || len(castedScratch) == 0 {
 			return fmt.Errorf("empty scratch field is not allowed, must contain \"_data\" field holding metadata")
 		}
 
-		data, ok := castedScratch["_data"]
+		declarationByt, ok := castedScratch["_data"]
 		if !ok {
 			return fmt.Errorf("\"_data\" cannot be empty")
 		}
 
-		// Convert data to JSON for easy serialization
-		declarationByt, err := json.Marshal(&data)
-		if err != nil {
-			return fmt.Errorf("failed to serialize component declaration from the metadata in the scratch")
-		}
-
 		// Unmarshal the JSON into a component declaration
 		declaration := component.ComponentDefinition{
 			Configuration: map[string]interface{}{},
 		}
-
-		// Add position
-		declaration.Styles.Position.X = elem.Position.X
-		declaration.Styles.Position.Y = elem.Position.Y
-
 		if err := json.Unmarshal(declarationByt, &declaration); err != nil {
 			return fmt.Errorf("failed to create component declaration from the metadata in the scratch")
 		}
 		if declaration.DisplayName == "" {
 			return fmt.Errorf("cannot save design with empty name")
 		}
+
+		// The position of the node takes precedence, as nodes are moved on the graph
+		if elem.Position != nil {
+			if declaration.Styles == nil {
+				declaration.Styles = &component.Styles{}
+			}
+			declaration.Styles.Position = &struct {
+				X float64 `json:"x" yaml:"x"`
+				Y float64 `json:"y" yaml:"y"`
+			}{X: elem.Position.X, Y: elem.Position.Y}
+		}
+
 		err = callback(declaration, elem)
 		if err != nil {
 			return err
@@ -351,8 +527,19 @@ func processCytoElementsWithPattern(eles []cytoscapejs.Element, callback func(sv
 	return nil
 }
 
+// NewPatternFileFromK8sManifest creates a design with a component for every resource of the
+// multi-document manifest; the items of Lists are imported individually.
+// Components are resolved from the registry, or derived from the GVK of the resources when reg is nil
+// or does not know them.
+//
 // Note: If modified, make sure this function always returns a meshkit error
 func NewPatternFileFromK8sManifest(data string, fileName string, ignoreErrors bool, reg *registry.RegistryManager) (pattern.PatternFile, error) {
+	return convertToDesign(Conversion{From: ConversionFormatK8sManifest, To: ConversionFormatDesign}, func() (pattern.PatternFile, error) {
+		return newPatternFileFromK8sManifest(data, fileName, ignoreErrors, reg)
+	})
+}
+
+func newPatternFileFromK8sManifest(data string, fileName string, ignoreErrors bool, reg *registry.RegistryManager) (pattern.PatternFile, error) {
 	if fileName == "" {
 		fileName = "Autogenerated"
 	}
@@ -376,7 +563,7 @@ func NewPatternFileFromK8sManifest(data string, fileName string, ignoreErrors bo
 				}
 				return pattern, nil
 			} else {
-				return pattern, ErrParseK8sManifest(fmt.Errorf("kubernetes manifest is empty"))
+				return pattern, ErrParseK8sManifest(err)
 			}
 		}
 		if len(manifest) == 0 {
@@ -391,21 +578,28 @@ func NewPatternFileFromK8sManifest(data string, fileName string, ignoreErrors bo
 			return pattern, ErrParseK8sManifest(fmt.Errorf("failed to parse manifest into an internal representation"))
 		}
 
-		declaration, err := createPatternDeclarationFromK8s(manifest, reg)
-		if err != nil {
-			if ignoreErrors {
-				continue
-			}
-			return pattern, ErrCreatePatternService(fmt.Errorf("failed to create design service from kubernetes component: %s", err))
+		// Resources exported with `kubectl get -o yaml` are wrapped in a List
+		resources := []map[string]interface{}{manifest}
+		if kind, _ := manifest["kind"].(string); kind == "List" {
+			resources = listItems(manifest)
 		}
 
-		pattern.Components = append(pattern.Components, &declaration)
+		for _, resource := range resources {
+			declaration, err := createPatternDeclarationFromK8s(resource, reg)
+			if err != nil {
+				if ignoreErrors {
+					continue
+				}
+				return pattern, ErrCreatePatternService(fmt.Errorf("failed to create design service from kubernetes component: %s", err))
+			}
+
+			pattern.Components = append(pattern.Components, &declaration)
+		}
 	}
 
 }
 
 func createPatternDeclarationFromK8s(manifest map[string]interface{}, regManager *registry.RegistryManager) (component.ComponentDefinition, error) {
-	fmt.Printf("%+#v\n", manifest)
 
 	apiVersion, err := mutils.Cast[string](manifest["apiVersion"])
 	if err != nil {
@@ -419,6 +613,7 @@ func createPatternDeclarationFromK8s(manifest map[string]interface{}, regManager
 
 	metadata, _ := manifest["metadata"].(map[string]interface{})
 	name, _ := metadata["name"].(string)
+	debugf("creating component from %s %s %s", apiVersion, kind, name)
 
 	rest := map[string]interface{}{}
 	// rest will store a map of everything other than the above mentioned fields
@@ -433,14 +628,22 @@ func createPatternDeclarationFromK8s(manifest map[string]interface{}, regManager
 		rest[k] = v
 	}
 
-	// Get MeshModel entity with the selectors
-	componentList, _, _, _ := regManager.GetEntities(&regv1beta1.ComponentFilter{
-		Name:       kind,
-		APIVersion: apiVersion,
-	})
+	rest = Format.Prettify(rest, false)
 
+	var componentList []entity.Entity
+	if regManager != nil {
+		// Get MeshModel entity with the selectors
+		componentList, _, _, _ = regManager.GetEntities(&regv1beta1.ComponentFilter{
+			Name:       kind,
+			APIVersion: apiVersion,
+		})
+	}
+
+	// Without a registry, or when the resource is not registered, the component is derived from the GVK of the resource
 	if len(componentList) == 0 {
-		return component.ComponentDefinition{}, ErrCreatePatternService(fmt.Errorf("no resources found for APIVersion: %s Kind: %s", apiVersion, kind))
+		declaration := componentFromGVK(apiVersion, kind, name, rest)
+		declaration.Configuration["metadata"] = metadata
+		return declaration, nil
 	}
 
 	// just needs the first entry to grab meshmodel-metadata and other model requirements
@@ -449,7 +652,6 @@ func createPatternDeclarationFromK8s(manifest map[string]interface{}, regManager
 		return component.ComponentDefinition{}, ErrCreatePatternService(fmt.Errorf("cannot cast to the component-definition for APIVersion: %s Kind: %s", apiVersion, kind))
 	}
 
-	rest = Format.Prettify(rest, false)
 	uuidV4, _ := uuid.NewV4()
 	declaration := component.ComponentDefinition{
 		Id:            uuidV4,
@@ -472,6 +674,42 @@ func createPatternDeclarationFromK8s(manifest map[string]interface{}, regManager
 	return declaration, nil
 }
 
+// listItems returns the resources of a Kubernetes List.
+func listItems(list map[string]interface{}) []map[string]interface{} {
+	items, _ := list["items"].([]interface{})
+	resources := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if resource, ok := item.(map[string]interface{}); ok && len(resource) > 0 {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// componentFromGVK creates the declaration of a resource whose component definition is unknown.
+// Resources of the built-in API groups belong to the kubernetes model, others to the model named after their API group.
+func componentFromGVK(apiVersion, kind, name string, configuration map[string]interface{}) component.ComponentDefinition {
+	modelName := "kubernetes"
+	if group, _, found := strings.Cut(apiVersion, "/"); found && strings.Contains(group, ".") && !strings.HasSuffix(group, ".k8s.io") {
+		modelName = group
+	}
+
+	id, _ := uuid.NewV4()
+	return component.ComponentDefinition{
+		Id:            id,
+		SchemaVersion: v1beta1.ComponentSchemaVersion,
+		DisplayName:   name,
+		Component: component.Component{
+			Version: apiVersion,
+			Kind:    kind,
+		},
+		Model: model.ModelDefinition{
+			Name: modelName,
+		},
+		Configuration: configuration,
+	}
+}
+
 func assignNamespaceForNamespacedScopedComp(declaration *component.ComponentDefinition, metadata map[string]interface{}, compDef *component.ComponentDefinition) *component.ComponentDefinition {
 	if isNamespacedComponent(compDef) {
 		namespace, _ := mutils.Cast[string](metadata["namespace"])
@@ -491,22 +729,13 @@ func isNamespacedComponent(comp *component.ComponentDefinition) bool {
 	return isNamespaced
 }
 
-// getCytoscapeElementID returns the element id for a given service
-func getCytoscapeElementID(name string, component *component.ComponentDefinition, log logger.Handler) string {
-	return component.Id.String()
-}
-
-func getCytoscapeJSPosition(component *component.ComponentDefinition, log logger.Handler) (cytoscapejs.Position, error) {
-
-	x, y := component.Styles.Position.X, component.Styles.Position.Y
-
-	X := float64(x)
-	Y := float64(y)
-
-	pos := cytoscapejs.Position{
-		X: X,
-		Y: Y,
+// getCytoscapeJSPosition returns the position of the component, or nil when it has none.
+func getCytoscapeJSPosition(component *component.ComponentDefinition) *cytoscapejs.Position {
+	if component.Styles == nil || component.Styles.Position == nil {
+		return nil
+	}
+	return &cytoscapejs.Position{
+		X: component.Styles.Position.X,
+		Y: component.Styles.Position.Y,
 	}
-
-	return pos, nil
 }
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/encoding"
+	meshkiterrors "github.com/layer5io/meshkit/errors"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	cytoscapejs "gonum.org/v1/gonum/graph/formats/cytoscapejs"
+)
+
+func newTestComponent(name, kind string, dependsOn ...string) *component.ComponentDefinition {
+	comp := &component.ComponentDefinition{
+		Id:          uuid.NewV5(uuid.Nil, name),
+		DisplayName: name,
+		Component: component.Component{
+			Kind:    kind,
+			Version: "v1",
+		},
+		Configuration: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	if len(dependsOn) > 0 {
+		deps := []interface{}{}
+		for _, dep := range dependsOn {
+			deps = append(deps, dep)
+		}
+		comp.Metadata.AdditionalProperties = map[string]interface{}{
+			"dependsOn": deps,
+		}
+	}
+	return comp
+}
+
+func newTestPatternFile() *pattern.PatternFile {
+	namespace := newTestComponent("namespace", "Namespace")
+	namespace.Styles = &component.Styles{
+		Position: &struct {
+			X float64 `json:"x" yaml:"x"`
+			Y float64 `json:"y" yaml:"y"`
+		}{X: 10, Y: 20},
+	}
+	configMap := newTestComponent("config", "ConfigMap", namespace.Id.String())
+	deployment := newTestComponent("deployment", "Deployment", namespace.Id.String(), configMap.Id.String())
+
+	return &pattern.PatternFile{
+		Name:       "test",
+		Components: []*component.ComponentDefinition{namespace, configMap, deployment},
+	}
+}
+
+func componentsJSON(t *testing.T, comps []*component.ComponentDefinition) string {
+	t.Helper()
+	byt, err := json.Marshal(comps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(byt)
+}
+
+func TestNewPatternFile_JSONAndYAML(t *testing.T) {
+	jsonByt, err := json.Marshal(newTestPatternFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlByt, err := ghodssyaml.JSONToYAML(jsonByt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if DetectPatternFileFormat(jsonByt) != PatternFileFormatJSON {
+		t.Fatal("expected the design to be detected as JSON")
+	}
+	if DetectPatternFileFormat(yamlByt) != PatternFileFormatYAML {
+		t.Fatal("expected the design to be detected as YAML")
+	}
+
+	fromJSON, err := NewPatternFile(jsonByt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := NewPatternFile(yamlByt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := componentsJSON(t, fromYAML.Components), componentsJSON(t, fromJSON.Components); got != want {
+		t.Errorf("YAML and JSON designs differ:\nyaml: %s\njson: %s", got, want)
+	}
+	deployment := fromYAML.Components[2]
+	if deps := componentDependencies(deployment); len(deps) != 2 {
+		t.Errorf("expected the deployment of the YAML design to depend on 2 components, got %v", deps)
+	}
+}
+
+func TestNewPatternFile_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		byt  string
+		want error
+	}{
+		{name: "invalid YAML", byt: "name: [web", want: encoding.ErrDecodeYaml(errors.New(""))},
+		{name: "invalid design", byt: "name: web\ncomponents: 1\n", want: encoding.ErrUnmarshal(errors.New(""))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPatternFile([]byte(tt.byt))
+			if got, want := meshkiterrors.GetSDescription(err), meshkiterrors.GetSDescription(tt.want); got != want {
+				t.Errorf("error = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFromCytoscapeJS_RoundTrip(t *testing.T) {
+	pf := newTestPatternFile()
+	cy, err := ToCytoscapeJS(pf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edges := 0
+	for _, elem := range cy.Elements {
+		if elem.Data.Source != "" {
+			edges++
+		}
+	}
+	if edges != 3 {
+		t.Fatalf("expected 3 edges, got %d", edges)
+	}
+
+	// the graph is exchanged with the UI as JSON
+	byt, err := json.Marshal(cy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewPatternFileFromCytoscapeJSJSON(pf.Name, byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != pf.Name {
+		t.Errorf("expected name %q, got %q", pf.Name, got.Name)
+	}
+	if want, have := componentsJSON(t, pf.Components), componentsJSON(t, got.Components); want != have {
+		t.Errorf("components changed in the round trip:\nwant %s\nhave %s", want, have)
+	}
+
+	// the graph built in memory converts as well
+	got, err = FromCytoscapeJS(pf.Name, cy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := componentsJSON(t, pf.Components), componentsJSON(t, got.Components); want != have {
+		t.Errorf("components changed in the round trip:\nwant %s\nhave %s", want, have)
+	}
+}
+
+func TestFromCytoscapeJS_GraphEdits(t *testing.T) {
+	pf := newTestPatternFile()
+	namespace, configMap, deployment := pf.Components[0], pf.Components[1], pf.Components[2]
+
+	cy, err := ToCytoscapeJS(pf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// move the deployment and drop its dependency on the config map
+	elements := []cytoscapejs.Element{}
+	for _, elem := range cy.Elements {
+		if elem.Data.ID == deployment.Id.String() {
+			elem.Position = &cytoscapejs.Position{X: 5, Y: 6}
+		}
+		if elem.Data.Source == configMap.Id.String() && elem.Data.Target == deployment.Id.String() {
+			continue
+		}
+		elements = append(elements, elem)
+	}
+	cy.Elements = elements
+
+	got, err := FromCytoscapeJS(pf.Name, cy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comp := got.Components[2]
+	if deps := componentDependencies(comp); len(deps) != 1 || deps[0] != namespace.Id.String() {
+		t.Errorf("expected the deployment to depend on the namespace only, got %v", deps)
+	}
+	if comp.Styles == nil || comp.Styles.Position == nil || comp.Styles.Position.X != 5 || comp.Styles.Position.Y != 6 {
+		t.Errorf("expected the deployment to be at the position of its node, got %+v", comp.Styles)
+	}
+}
+
+func TestFromCytoscapeJS_Errors(t *testing.T) {
+	pf := newTestPatternFile()
+	cy, err := ToCytoscapeJS(pf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		elem cytoscapejs.Element
+	}{
+		{
+			name: "Edge to a node which is not in the graph",
+			elem: cytoscapejs.Element{
+				Data: cytoscapejs.ElemData{ID: "edge", Source: pf.Components[0].Id.String(), Target: "unknown"},
+			},
+		},
+		{
+			name: "Edge without a source",
+			elem: cytoscapejs.Element{
+				Data: cytoscapejs.ElemData{ID: "edge", Target: pf.Components[0].Id.String()},
+			},
+		},
+		{
+			name: "Node without a component",
+			elem: cytoscapejs.Element{
+				Data: cytoscapejs.ElemData{ID: "node"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := cytoscapejs.GraphElem{
+				Elements: append(append([]cytoscapejs.Element{}, cy.Elements...), tt.elem),
+			}
+			if _, err := FromCytoscapeJS(pf.Name, graph); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodSecurityLevel is a level of the Pod Security Standards, which the Pod Security admission
+// controller enforces on the pods of the namespaces labelled with it.
+type PodSecurityLevel string
+
+const (
+	PodSecurityPrivileged PodSecurityLevel = "privileged"
+	PodSecurityBaseline   PodSecurityLevel = "baseline"
+	PodSecurityRestricted PodSecurityLevel = "restricted"
+
+	// PodSecurityEnforceLabel labels a namespace with the level enforced on its pods.
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+)
+
+var podSecurityRanks = map[PodSecurityLevel]int{
+	PodSecurityPrivileged: 0,
+	PodSecurityBaseline:   1,
+	PodSecurityRestricted: 2,
+}
+
+// ParsePodSecurityLevel parses the value of the enforce label of a namespace. Like the admission
+// controller, an invalid value enforces the restricted level.
+func ParsePodSecurityLevel(value string) PodSecurityLevel {
+	level := PodSecurityLevel(value)
+	if _, ok := podSecurityRanks[level]; ok {
+		return level
+	}
+	return PodSecurityRestricted
+}
+
+// PodSecurityNamespaces are the levels enforced on the namespaces of a cluster. Default is
+// enforced on the namespaces which are not labelled, privileged unless the admission controller
+// of the cluster is configured otherwise.
+type PodSecurityNamespaces struct {
+	Levels  map[string]PodSecurityLevel `json:"levels"`
+	Default PodSecurityLevel            `json:"default"`
+}
+
+// NewPodSecurityNamespaces reads the levels enforced on the namespaces from their labels.
+func NewPodSecurityNamespaces(namespaces []corev1.Namespace, defaultLevel PodSecurityLevel) PodSecurityNamespaces {
+	levels := map[string]PodSecurityLevel{}
+	for _, ns := range namespaces {
+		if value, ok := ns.Labels[PodSecurityEnforceLabel]; ok {
+			levels[ns.Name] = ParsePodSecurityLevel(value)
+		}
+	}
+	return PodSecurityNamespaces{Levels: levels, Default: defaultLevel}
+}
+
+// Level returns the level enforced on the namespace.
+func (n PodSecurityNamespaces) Level(namespace string) PodSecurityLevel {
+	if level, ok := n.Levels[namespace]; ok {
+		return level
+	}
+	if n.Default == "" {
+		return PodSecurityPrivileged
+	}
+	return n.Default
+}
+
+// withDesign returns the levels once the namespaces of the design are deployed, as their labels
+// replace the labels of the namespaces of the cluster.
+func (n PodSecurityNamespaces) withDesign(patternFile *pattern.PatternFile) PodSecurityNamespaces {
+	levels := make(map[string]PodSecurityLevel, len(n.Levels))
+	for ns, level := range n.Levels {
+		levels[ns] = level
+	}
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Component.Kind != "Namespace" {
+			continue
+		}
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		if name == "" {
+			name = comp.DisplayName
+		}
+		labels, _ := metadata["labels"].(map[string]interface{})
+		if value, ok := labels[PodSecurityEnforceLabel].(string); ok {
+			levels[name] = ParsePodSecurityLevel(value)
+		}
+	}
+	return PodSecurityNamespaces{Levels: levels, Default: n.Default}
+}
+
+// PodSecurityViolation reports a field of a workload of a design which violates the level
+// enforced on its namespace. Field is a JSON pointer to the field in the component, e.g.
+// /configuration/spec/template/spec/containers/0/securityContext/privileged, which is missing
+// when the level requires it to be set. Remediable violations are fixed by RemediatePodSecurity.
+type PodSecurityViolation struct {
+	ComponentID uuid.UUID        `json:"component_id"`
+	Component   string           `json:"component"`
+	Namespace   string           `json:"namespace"`
+	Level       PodSecurityLevel `json:"level"`
+	Check       string           `json:"check"`
+	Field       string           `json:"field"`
+	Value       interface{}      `json:"value,omitempty"`
+	Message     string           `json:"message"`
+	Remediable  bool             `json:"remediable"`
+}
+
+// The values the Pod Security Standards allow, see
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+var (
+	baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+		"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	restrictedCapabilities = []string{"NET_BIND_SERVICE"}
+	safeSysctls            = []string{"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports", "net.ipv4.tcp_keepalive_time",
+		"net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl", "net.ipv4.tcp_keepalive_probes"}
+	seLinuxTypes      = []string{"", "container_t", "container_init_t", "container_kvm_t"}
+	restrictedVolumes = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+)
+
+// appArmorAnnotationPrefix prefixes the annotations setting the AppArmor profiles of the containers.
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// containerLists are the fields of a pod spec listing containers.
+var containerLists = []string{"initContainers", "containers", "ephemeralContainers"}
+
+// CheckPodSecurity evaluates the workloads the design renders against the Pod Security Standards
+// level enforced on their namespaces, the namespaces declared by the design taking the levels of
+// their labels. Workloads without a namespace are deployed to the default namespace.
+func CheckPodSecurity(patternFile *pattern.PatternFile, namespaces PodSecurityNamespaces) ([]PodSecurityViolation, error) {
+	namespaces = namespaces.withDesign(patternFile)
+	violations := []PodSecurityViolation{}
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Metadata.IsAnnotation {
+			continue
+		}
+		namespace := componentNamespace(comp)
+		found, err := checkWorkloadPodSecurity(comp, namespace, namespaces.Level(namespace))
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
+
+// RemediatePodSecurity returns a copy of the design with the remediable violations fixed, the
+// changes made to the design and the violations left, which need a decision of the author of the
+// design. Capabilities the level does not allow are removed, and for the restricted level all
+// capabilities are dropped, privilege escalation is disallowed, containers run as non-root
+// and with the seccomp profile of the container runtime, unless they set another one.
+func RemediatePodSecurity(patternFile *pattern.PatternFile, namespaces PodSecurityNamespaces) (*pattern.PatternFile, *PatternDiff, []PodSecurityViolation, error) {
+	// both versions are compared as JSON, the values of a parsed design may be of other types
+	original, err := clonePatternFile(patternFile)
+	if err != nil {
+		return nil, nil, nil, ErrPodSecurity(err, patternFile.Name)
+	}
+	remediated, err := clonePatternFile(patternFile)
+	if err != nil {
+		return nil, nil, nil, ErrPodSecurity(err, patternFile.Name)
+	}
+	levels := namespaces.withDesign(patternFile)
+	for _, comp := range remediated.Components {
+		if comp == nil || comp.Metadata.IsAnnotation {
+			continue
+		}
+		remediateWorkloadPodSecurity(comp, levels.Level(componentNamespace(comp)))
+	}
+
+	violations, err := CheckPodSecurity(remediated, namespaces)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return remediated, DiffPatternFiles(original, remediated), violations, nil
+}
+
+// componentNamespace returns the namespace the component is deployed to.
+func componentNamespace(comp *component.ComponentDefinition) string {
+	metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+	if namespace, _ := metadata["namespace"].(string); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+func checkWorkloadPodSecurity(comp *component.ComponentDefinition, namespace string, enforced PodSecurityLevel) ([]PodSecurityViolation, error) {
+	violations := []PodSecurityViolation{}
+	spec := podSpec(comp.Component.Kind, comp.Configuration, false)
+	if spec == nil || enforced == PodSecurityPrivileged {
+		return violations, nil
+	}
+	var pod corev1.PodSpec
+	if err := fromInterface(spec, &pod); err != nil {
+		return nil, ErrPodSecurity(err, comp.DisplayName)
+	}
+
+	path := podSpecPath(comp.Component.Kind)
+	specPath := "/configuration/" + strings.Join(path, "/")
+	violate := func(level PodSecurityLevel, check, field string, value interface{}, remediable bool, message string) {
+		if podSecurityRanks[enforced] < podSecurityRanks[level] {
+			return
+		}
+		violations = append(violations, PodSecurityViolation{
+			ComponentID: comp.Id,
+			Component:   comp.DisplayName,
+			Namespace:   namespace,
+			Level:       enforced,
+			Check:       check,
+			Field:       field,
+			Value:       value,
+			Message:     message,
+			Remediable:  remediable,
+		})
+	}
+
+	hostNamespaces := []struct {
+		field   string
+		enabled bool
+	}{{"hostNetwork", pod.HostNetwork}, {"hostPID", pod.HostPID}, {"hostIPC", pod.HostIPC}}
+	for _, ns := range hostNamespaces {
+		if ns.enabled {
+			violate(PodSecurityBaseline, "hostNamespaces", specPath+"/"+ns.field, true, false, "sharing the namespaces of the host is forbidden")
+		}
+	}
+
+	// the template metadata of a pod is a sibling of its spec
+	metadataPath := append(append([]string{}, path[:len(path)-1]...), "metadata")
+	metadata := comp.Configuration
+	for _, key := range metadataPath {
+		metadata, _ = metadata[key].(map[string]interface{})
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	for _, key := range sortedKeys(annotations) {
+		value, _ := annotations[key].(string)
+		if strings.HasPrefix(key, appArmorAnnotationPrefix) && value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+			violate(PodSecurityBaseline, "appArmorProfile", "/configuration/"+strings.Join(metadataPath, "/")+"/annotations/"+escapeJSONPointer(key),
+				value, false, "the AppArmor profile must be runtime/default or a profile loaded on the nodes")
+		}
+	}
+
+	volumes, _ := spec["volumes"].([]interface{})
+	for i, value := range volumes {
+		volume, _ := value.(map[string]interface{})
+		for _, source := range sortedKeys(volume) {
+			if source == "name" {
+				continue
+			}
+			field := fmt.Sprintf("%s/volumes/%d/%s", specPath, i, source)
+			if source == "hostPath" {
+				violate(PodSecurityBaseline, "hostPathVolumes", field, volume[source], false, "hostPath volumes are forbidden")
+			} else if !slices.Contains(restrictedVolumes, source) {
+				violate(PodSecurityRestricted, "restrictedVolumes", field, volume[source], false,
+					fmt.Sprintf("%s volumes are forbidden, only %s volumes are allowed", source, strings.Join(restrictedVolumes, ", ")))
+			}
+		}
+	}
+
+	podContext := pod.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+	podContextPath := specPath + "/securityContext"
+	checkSecurityOptions(violate, podContextPath, podContext.WindowsOptions, podContext.SELinuxOptions, podContext.SeccompProfile, podContext.RunAsUser)
+	for i, sysctl := range podContext.Sysctls {
+		if !slices.Contains(safeSysctls, sysctl.Name) {
+			violate(PodSecurityBaseline, "sysctls", fmt.Sprintf("%s/sysctls/%d/name", podContextPath, i), sysctl.Name, false,
+				fmt.Sprintf("the sysctl %s is not safe", sysctl.Name))
+		}
+	}
+	if podContext.RunAsNonRoot != nil && !*podContext.RunAsNonRoot {
+		violate(PodSecurityRestricted, "runAsNonRoot", podContextPath+"/runAsNonRoot", false, true, "the pod must run as a non-root user")
+	}
+
+	containers := map[string][]corev1.Container{
+		"initContainers": pod.InitContainers,
+		"containers":     pod.Containers,
+	}
+	for _, container := range pod.EphemeralContainers {
+		containers["ephemeralContainers"] = append(containers["ephemeralContainers"], corev1.Container(container.EphemeralContainerCommon))
+	}
+
+	rootContainers := []string{}
+	unconfinedContainers := []string{}
+	for _, list := range containerLists {
+		for i, container := range containers[list] {
+			containerPath := fmt.Sprintf("%s/%s/%d", specPath, list, i)
+			for j, port := range container.Ports {
+				if port.HostPort != 0 {
+					violate(PodSecurityBaseline, "hostPorts", fmt.Sprintf("%s/ports/%d/hostPort", containerPath, j), port.HostPort, false,
+						fmt.Sprintf("container %s must not expose host ports", container.Name))
+				}
+			}
+
+			sc := container.SecurityContext
+			if sc == nil {
+				sc = &corev1.SecurityContext{}
+			}
+			contextPath := containerPath + "/securityContext"
+			checkSecurityOptions(violate, contextPath, sc.WindowsOptions, sc.SELinuxOptions, sc.SeccompProfile, sc.RunAsUser)
+			privileged := sc.Privileged != nil && *sc.Privileged
+			if privileged {
+				violate(PodSecurityBaseline, "privileged", contextPath+"/privileged", true, false,
+					fmt.Sprintf("container %s must not be privileged", container.Name))
+			}
+			if sc.ProcMount != nil && *sc.ProcMount != "" && *sc.ProcMount != corev1.DefaultProcMount {
+				violate(PodSecurityBaseline, "procMount", contextPath+"/procMount", string(*sc.ProcMount), false,
+					fmt.Sprintf("container %s must use the default proc mount", container.Name))
+			}
+			// privileged containers can not disallow privilege escalation
+			if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+				violate(PodSecurityRestricted, "allowPrivilegeEscalation", contextPath+"/allowPrivilegeEscalation", sc.AllowPrivilegeEscalation, !privileged,
+					fmt.Sprintf("container %s must set allowPrivilegeEscalation to false", container.Name))
+			}
+
+			capabilities := sc.Capabilities
+			if capabilities == nil {
+				capabilities = &corev1.Capabilities{}
+			}
+			for j, capability := range capabilities.Add {
+				field := fmt.Sprintf("%s/capabilities/add/%d", contextPath, j)
+				if !slices.Contains(baselineCapabilities, string(capability)) {
+					violate(PodSecurityBaseline, "capabilities", field, string(capability), true,
+						fmt.Sprintf("container %s must not add the capability %s", container.Name, capability))
+				} else if !slices.Contains(restrictedCapabilities, string(capability)) {
+					violate(PodSecurityRestricted, "capabilities", field, string(capability), true,
+						fmt.Sprintf("container %s must not add the capability %s, only NET_BIND_SERVICE may be added", container.Name, capability))
+				}
+			}
+			if !slices.Contains(capabilities.Drop, "ALL") {
+				violate(PodSecurityRestricted, "capabilities", contextPath+"/capabilities/drop", capabilities.Drop, true,
+					fmt.Sprintf("container %s must drop all capabilities", container.Name))
+			}
+
+			if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+				violate(PodSecurityRestricted, "runAsNonRoot", contextPath+"/runAsNonRoot", false, true,
+					fmt.Sprintf("container %s must run as a non-root user", container.Name))
+			} else if sc.RunAsNonRoot == nil && (podContext.RunAsNonRoot == nil || !*podContext.RunAsNonRoot) {
+				rootContainers = append(rootContainers, container.Name)
+			}
+			if sc.SeccompProfile == nil && podContext.SeccompProfile == nil {
+				unconfinedContainers = append(unconfinedContainers, container.Name)
+			}
+		}
+	}
+
+	// settings the containers inherit are reported once, on the pod
+	if len(rootContainers) > 0 && podContext.RunAsNonRoot == nil {
+		violate(PodSecurityRestricted, "runAsNonRoot", podContextPath+"/runAsNonRoot", nil, true,
+			fmt.Sprintf("the pod or containers %s must set runAsNonRoot to true", strings.Join(rootContainers, ", ")))
+	}
+	if len(unconfinedContainers) > 0 {
+		violate(PodSecurityRestricted, "seccompProfile", podContextPath+"/seccompProfile/type", nil, true,
+			fmt.Sprintf("the pod or containers %s must set the seccomp profile to RuntimeDefault or Localhost", strings.Join(unconfinedContainers, ", ")))
+	}
+	return violations, nil
+}
+
+// checkSecurityOptions checks the options a pod and its containers both set in their security context.
+func checkSecurityOptions(violate func(PodSecurityLevel, string, string, interface{}, bool, string), path string,
+	windows *corev1.WindowsSecurityContextOptions, seLinux *corev1.SELinuxOptions, seccomp *corev1.SeccompProfile, runAsUser *int64) {
+	if windows != nil && windows.HostProcess != nil && *windows.HostProcess {
+		violate(PodSecurityBaseline, "hostProcess", path+"/windowsOptions/hostProcess", true, false, "Windows host processes are forbidden")
+	}
+	if seLinux != nil {
+		if !slices.Contains(seLinuxTypes, seLinux.Type) {
+			violate(PodSecurityBaseline, "seLinuxOptions", path+"/seLinuxOptions/type", seLinux.Type, false,
+				fmt.Sprintf("the SELinux type must be one of %s", strings.Join(seLinuxTypes[1:], ", ")))
+		}
+		if seLinux.User != "" {
+			violate(PodSecurityBaseline, "seLinuxOptions", path+"/seLinuxOptions/user", seLinux.User, false, "the SELinux user must not be set")
+		}
+		if seLinux.Role != "" {
+			violate(PodSecurityBaseline, "seLinuxOptions", path+"/seLinuxOptions/role", seLinux.Role, false, "the SELinux role must not be set")
+		}
+	}
+	if seccomp != nil && seccomp.Type == corev1.SeccompProfileTypeUnconfined {
+		violate(PodSecurityBaseline, "seccompProfile", path+"/seccompProfile/type", string(seccomp.Type), false, "the seccomp profile must not be Unconfined")
+	}
+	if runAsUser != nil && *runAsUser == 0 {
+		violate(PodSecurityRestricted, "runAsUser", path+"/runAsUser", 0, false, "the user must not be root")
+	}
+}
+
+// remediateWorkloadPodSecurity fixes the remediable violations of the workload in its configuration.
+func remediateWorkloadPodSecurity(comp *component.ComponentDefinition, enforced PodSecurityLevel) {
+	spec := podSpec(comp.Component.Kind, comp.Configuration, false)
+	if spec == nil || enforced == PodSecurityPrivileged {
+		return
+	}
+	restricted := enforced == PodSecurityRestricted
+	allowed := baselineCapabilities
+	if restricted {
+		allowed = restrictedCapabilities
+	}
+
+	podContext, _ := spec["securityContext"].(map[string]interface{})
+	podNonRoot, _ := podContext["runAsNonRoot"].(bool)
+	_, podSeccomp := podContext["seccompProfile"]
+	rootContainers, unconfinedContainers := false, false
+
+	for _, list := range containerLists {
+		containers, _ := spec[list].([]interface{})
+		for _, value := range containers {
+			container, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			sc, _ := container["securityContext"].(map[string]interface{})
+			if capabilities, ok := sc["capabilities"].(map[string]interface{}); ok {
+				if added, ok := capabilities["add"].([]interface{}); ok {
+					kept := []interface{}{}
+					for _, capability := range added {
+						if name, _ := capability.(string); slices.Contains(allowed, name) {
+							kept = append(kept, capability)
+						}
+					}
+					if len(kept) > 0 {
+						capabilities["add"] = kept
+					} else {
+						delete(capabilities, "add")
+					}
+				}
+			}
+			if !restricted {
+				continue
+			}
+
+			if sc == nil {
+				sc = map[string]interface{}{}
+				container["securityContext"] = sc
+			}
+			if privileged, _ := sc["privileged"].(bool); !privileged {
+				sc["allowPrivilegeEscalation"] = false
+			}
+			capabilities, _ := sc["capabilities"].(map[string]interface{})
+			if capabilities == nil {
+				capabilities = map[string]interface{}{}
+				sc["capabilities"] = capabilities
+			}
+			dropped, _ := capabilities["drop"].([]interface{})
+			if !slices.Contains(dropped, interface{}("ALL")) {
+				capabilities["drop"] = append(dropped, "ALL")
+			}
+			if nonRoot, ok := sc["runAsNonRoot"].(bool); ok && !nonRoot {
+				sc["runAsNonRoot"] = true
+			} else if !ok && !podNonRoot {
+				rootContainers = true
+			}
+			if _, ok := sc["seccompProfile"]; !ok && !podSeccomp {
+				unconfinedContainers = true
+			}
+		}
+	}
+
+	podRoot := podContext["runAsNonRoot"] == false
+	if !restricted || (!rootContainers && !unconfinedContainers && !podRoot) {
+		return
+	}
+	if podContext == nil {
+		podContext = map[string]interface{}{}
+		spec["securityContext"] = podContext
+	}
+	if rootContainers || podRoot {
+		podContext["runAsNonRoot"] = true
+	}
+	if unconfinedContainers {
+		podContext["seccompProfile"] = map[string]interface{}{"type": string(corev1.SeccompProfileTypeRuntimeDefault)}
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+const podSecurityDesign = `
+name: pod-security
+components:
+  - id: 5a0f7d3e-1b2c-4d5e-8f90-a1b2c3d4e5f6
+    displayName: restricted
+    component:
+      kind: Namespace
+      version: v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        labels:
+          pod-security.kubernetes.io/enforce: restricted
+  - id: 6b1f8e4f-2c3d-4e5f-9a01-b2c3d4e5f6a7
+    displayName: web
+    component:
+      kind: Deployment
+      version: apps/v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        namespace: restricted
+      spec:
+        template:
+          spec:
+            containers:
+              - name: web
+                image: nginx
+                securityContext:
+                  capabilities:
+                    add: [NET_ADMIN, NET_BIND_SERVICE]
+              - name: agent
+                image: agent
+                securityContext:
+                  privileged: true
+  - id: 7c2a9f5a-3d4e-4f6a-8b12-c3d4e5f6a7b8
+    displayName: sidecar
+    component:
+      kind: Pod
+      version: v1
+    model:
+      name: kubernetes
+    configuration:
+      spec:
+        hostNetwork: true
+        containers:
+          - name: sidecar
+            image: envoy
+`
+
+func TestCheckPodSecurity(t *testing.T) {
+	pf, err := NewPatternFile([]byte(podSecurityDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaces := PodSecurityNamespaces{Levels: map[string]PodSecurityLevel{"restricted": PodSecurityPrivileged}, Default: PodSecurityBaseline}
+
+	violations, err := CheckPodSecurity(&pf, namespaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]PodSecurityViolation{}
+	for _, v := range violations {
+		fields[v.Component+" "+v.Check+" "+v.Field] = v
+	}
+	for _, key := range []string{
+		// the design labels the namespace restricted
+		"web capabilities /configuration/spec/template/spec/containers/0/securityContext/capabilities/add/0",
+		"web capabilities /configuration/spec/template/spec/containers/0/securityContext/capabilities/drop",
+		"web privileged /configuration/spec/template/spec/containers/1/securityContext/privileged",
+		"web allowPrivilegeEscalation /configuration/spec/template/spec/containers/0/securityContext/allowPrivilegeEscalation",
+		"web runAsNonRoot /configuration/spec/template/spec/securityContext/runAsNonRoot",
+		"web seccompProfile /configuration/spec/template/spec/securityContext/seccompProfile/type",
+		// the default namespace is baseline
+		"sidecar hostNamespaces /configuration/spec/hostNetwork",
+	} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected the violation %s, got %v", key, violations)
+		}
+	}
+	if v := fields["web capabilities /configuration/spec/template/spec/containers/0/securityContext/capabilities/add/0"]; v.Value != "NET_ADMIN" || v.Level != PodSecurityRestricted || !v.Remediable {
+		t.Errorf("unexpected violation %+v", v)
+	}
+	for _, v := range violations {
+		if v.Component == "sidecar" && v.Check != "hostNamespaces" {
+			t.Errorf("expected the sidecar to be checked against the baseline level only, got %+v", v)
+		}
+	}
+}
+
+func TestRemediatePodSecurity(t *testing.T) {
+	pf, err := NewPatternFile([]byte(podSecurityDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remediated, diff, violations, err := RemediatePodSecurity(&pf, PodSecurityNamespaces{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// privileged containers and host namespaces are left to the author of the design
+	for _, v := range violations {
+		if v.Remediable {
+			t.Errorf("expected the remediable violations to be fixed, got %+v", v)
+		}
+	}
+	if len(violations) == 0 {
+		t.Error("expected the privileged container to be left")
+	}
+	for _, v := range violations {
+		if !strings.Contains(v.Field, "/containers/1/") {
+			t.Errorf("expected the violations of the privileged container only, got %+v", v)
+		}
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].Name != "web" {
+		t.Fatalf("expected web only to be modified, got %+v", diff.Modified)
+	}
+
+	spec := podSpec("Deployment", remediated.Components[1].Configuration, false)
+	web := spec["containers"].([]interface{})[0].(map[string]interface{})["securityContext"].(map[string]interface{})
+	capabilities := web["capabilities"].(map[string]interface{})
+	if add := capabilities["add"].([]interface{}); len(add) != 1 || add[0] != "NET_BIND_SERVICE" {
+		t.Errorf("expected NET_ADMIN to be removed, got %v", add)
+	}
+	if web["allowPrivilegeEscalation"] != false {
+		t.Errorf("expected privilege escalation to be disallowed, got %v", web)
+	}
+	if podContext := spec["securityContext"].(map[string]interface{}); podContext["runAsNonRoot"] != true {
+		t.Errorf("expected the pod to run as non-root, got %v", podContext)
+	}
+	if pf.Components[1].Configuration["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["securityContext"] != nil {
+		t.Error("expected the design to be left unchanged")
+	}
+}
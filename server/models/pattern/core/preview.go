@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	cytoscapejs "gonum.org/v1/gonum/graph/formats/cytoscapejs"
+)
+
+// PreviewSize is the resolution of a design preview image.
+type PreviewSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (s PreviewSize) String() string {
+	return strconv.Itoa(s.Width) + "x" + strconv.Itoa(s.Height)
+}
+
+// CatalogPreviewSizes are the resolutions at which catalog card previews are generated.
+var CatalogPreviewSizes = []PreviewSize{
+	{Width: 1200, Height: 630},
+	{Width: 600, Height: 315},
+	{Width: 300, Height: 158},
+}
+
+var (
+	previewBackground   = color.RGBA{R: 0x1e, G: 0x21, B: 0x25, A: 0xff}
+	previewEdgeColor    = color.RGBA{R: 0x9a, G: 0xa4, B: 0xae, A: 0xff}
+	previewDefaultColor = color.RGBA{R: 0x00, G: 0xb3, B: 0x9f, A: 0xff}
+)
+
+type previewNode struct {
+	x, y float64
+	fill color.RGBA
+}
+
+// RenderPreview draws the CytoscapeJS graph of the design, the one the canvas and its snapshots are
+// rendered from, into a PNG image of the given size: components as nodes and their dependencies as edges.
+// Components are placed at the position they have on the canvas; when a
+// design has no positions, components are laid out on a grid.
+// The rendering is done in process, without a browser.
+func RenderPreview(patternFile *pattern.PatternFile, size PreviewSize) ([]byte, error) {
+	cy, err := toCytoscapeJS(patternFile)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: previewBackground}, image.Point{}, draw.Src)
+
+	nodes := previewLayout(cy)
+	if len(nodes) > 0 {
+		radius := previewNodeRadius(size, len(nodes))
+		project := previewProjection(nodes, size, radius)
+
+		for _, elem := range cy.Elements {
+			if elem.Data.Source == "" {
+				continue
+			}
+			src, ok := nodes[elem.Data.Source]
+			dst, ok2 := nodes[elem.Data.Target]
+			if !ok || !ok2 {
+				continue
+			}
+			x0, y0 := project(src)
+			x1, y1 := project(dst)
+			drawLine(img, x0, y0, x1, y1, previewEdgeColor)
+		}
+
+		for _, node := range nodes {
+			x, y := project(node)
+			drawDisc(img, x, y, radius, node.fill)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// previewLayout returns the nodes of the graph by id, at their position on the canvas.
+func previewLayout(cy cytoscapejs.GraphElem) map[string]*previewNode {
+	nodes := map[string]*previewNode{}
+	order := []string{}
+	positioned := true
+	for _, elem := range cy.Elements {
+		if elem.Data.Source != "" {
+			continue
+		}
+		node := &previewNode{fill: previewDefaultColor}
+		scratch, _ := elem.Scratch.(map[string]component.ComponentDefinition)
+		if comp, ok := scratch["_data"]; ok && comp.Styles != nil {
+			if c, ok := parseHexColor(comp.Styles.PrimaryColor); ok {
+				node.fill = c
+			}
+		}
+		if elem.Position != nil {
+			node.x, node.y = elem.Position.X, elem.Position.Y
+		} else {
+			positioned = false
+		}
+		nodes[elem.Data.ID] = node
+		order = append(order, elem.Data.ID)
+	}
+
+	if !positioned {
+		cols := int(math.Ceil(math.Sqrt(float64(len(order)))))
+		for i, id := range order {
+			nodes[id].x = float64(i % cols)
+			nodes[id].y = float64(i / cols)
+		}
+	}
+	return nodes
+}
+
+func previewNodeRadius(size PreviewSize, n int) int {
+	short := math.Min(float64(size.Width), float64(size.Height))
+	r := short / (3 * math.Sqrt(float64(n)+1))
+	return int(math.Max(2, math.Min(r, short/12)))
+}
+
+// previewProjection fits the bounding box of the nodes into the image, keeping the aspect ratio.
+func previewProjection(nodes map[string]*previewNode, size PreviewSize, radius int) func(*previewNode) (int, int) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, n := range nodes {
+		minX, maxX = math.Min(minX, n.x), math.Max(maxX, n.x)
+		minY, maxY = math.Min(minY, n.y), math.Max(maxY, n.y)
+	}
+
+	margin := float64(2 * radius)
+	w, h := float64(size.Width)-2*margin, float64(size.Height)-2*margin
+	scale := 1.0
+	if dx, dy := maxX-minX, maxY-minY; dx > 0 || dy > 0 {
+		scale = math.Min(w/math.Max(dx, 1e-9), h/math.Max(dy, 1e-9))
+	}
+	offX := margin + (w-(maxX-minX)*scale)/2
+	offY := margin + (h-(maxY-minY)*scale)/2
+
+	return func(n *previewNode) (int, int) {
+		return int(offX + (n.x-minX)*scale), int(offY + (n.y-minY)*scale)
+	}
+}
+
+func drawDisc(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				img.SetRGBA(cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+// drawLine draws a line using Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func parseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+func TestRenderPreview(t *testing.T) {
+	positioned := newTestPatternFile()
+	for i, comp := range positioned.Components {
+		comp.Styles = &component.Styles{
+			Position: &struct {
+				X float64 `json:"x" yaml:"x"`
+				Y float64 `json:"y" yaml:"y"`
+			}{X: float64(100 * i), Y: float64(50 * i)},
+		}
+	}
+	positioned.Components[0].Styles.PrimaryColor = "#ff0000"
+
+	tests := []struct {
+		name        string
+		patternFile *pattern.PatternFile
+	}{
+		{name: "positioned", patternFile: positioned},
+		{name: "laid out on a grid", patternFile: newTestPatternFile()},
+		{name: "empty", patternFile: &pattern.PatternFile{Name: "empty"}},
+	}
+	for _, tt := range tests {
+		for _, size := range CatalogPreviewSizes {
+			t.Run(tt.name+"/"+size.String(), func(t *testing.T) {
+				byt, err := RenderPreview(tt.patternFile, size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				img, err := png.Decode(bytes.NewReader(byt))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if b := img.Bounds(); b.Dx() != size.Width || b.Dy() != size.Height {
+					t.Errorf("image is %dx%d, want %s", b.Dx(), b.Dy(), size)
+				}
+			})
+		}
+	}
+
+	byt, err := RenderPreview(positioned, CatalogPreviewSizes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(byt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := map[color.RGBA]bool{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			colors[color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(bl >> 8), A: uint8(a >> 8)}] = true
+		}
+	}
+	for name, c := range map[string]color.RGBA{
+		"component color": {R: 0xff, A: 0xff},
+		"default color":   previewDefaultColor,
+		"edge":            previewEdgeColor,
+	} {
+		if !colors[c] {
+			t.Errorf("preview does not contain the %s", name)
+		}
+	}
+}
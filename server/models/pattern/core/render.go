@@ -0,0 +1,191 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/converter"
+	registry "github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+// ComponentResolver resolves a component declared in a design to its ComponentDefinition.
+type ComponentResolver interface {
+	Resolve(comp *component.ComponentDefinition) (*component.ComponentDefinition, error)
+}
+
+// RegistryResolver resolves components to the definitions registered in the registry.
+type RegistryResolver struct {
+	Registry *registry.RegistryManager
+}
+
+// Resolve returns the registered definition of the model version of the component,
+// falling back to any registered version of the component.
+func (r *RegistryResolver) Resolve(comp *component.ComponentDefinition) (*component.ComponentDefinition, error) {
+	return resolveComponentDefinition(comp, r.Registry)
+}
+
+// RenderManifests renders the design into the Kubernetes manifests which would be applied
+// when deploying it, as a multi-document YAML, without contacting a cluster.
+//
+// Every component is resolved through its definition, which fills in the apiVersion when the
+// design omits it and tells whether the resource is namespaced. Resources are labelled with the
+// id of their component, like deployed resources are, and emitted in dependency order. The
+// scheduling traits of workloads are set on their pod templates. Annotation components are
+// not rendered.
+func RenderManifests(patternFile *pattern.PatternFile, resolver ComponentResolver) ([]byte, error) {
+	return convertFromDesign(Conversion{From: ConversionFormatDesign, To: ConversionFormatK8sManifest}, patternFile, func() ([]byte, error) {
+		return renderManifests(patternFile, resolver)
+	})
+}
+
+func renderManifests(patternFile *pattern.PatternFile, resolver ComponentResolver) ([]byte, error) {
+	resources, err := renderResources(patternFile, resolver)
+	if err != nil {
+		return nil, ErrRenderManifests(err, patternFile.Name)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, r := range resources {
+		manifest, err := yaml.Marshal(r.resource)
+		if err != nil {
+			return nil, ErrRenderManifests(err, patternFile.Name)
+		}
+		buf.WriteString("---\n")
+		buf.Write(manifest)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderedResource is the Kubernetes resource rendered from the component at index of the design.
+type renderedResource struct {
+	index    int
+	comp     *component.ComponentDefinition
+	resource map[string]interface{}
+}
+
+// renderResources renders the components of the design into the resources applied when deploying it,
+// in dependency order, skipping annotation components. It is shared by every export of a design into
+// Kubernetes resources so that they all render the same resources.
+func renderResources(patternFile *pattern.PatternFile, resolver ComponentResolver) ([]renderedResource, error) {
+	comps, err := orderByDependencies(patternFile.Components)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[*component.ComponentDefinition]int, len(patternFile.Components))
+	for i, comp := range patternFile.Components {
+		indexes[comp] = i
+	}
+
+	resources := make([]renderedResource, 0, len(comps))
+	for _, comp := range comps {
+		if comp.Metadata.IsAnnotation {
+			continue
+		}
+		def, err := resolver.Resolve(comp)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", comp.DisplayName, err)
+		}
+		if def.Metadata.IsAnnotation {
+			continue
+		}
+
+		resolved := *comp
+		resolved.Configuration, _ = deepCopyValue(comp.Configuration).(map[string]interface{})
+		if resolved.Component.Version == "" {
+			resolved.Component.Version = def.Component.Version
+		}
+		if err := ApplySchedulingTraits(&resolved); err != nil {
+			return nil, err
+		}
+
+		resource := converter.CreateK8sResourceStructure(&resolved)
+		if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+			labels, _ := metadata["labels"].(map[string]interface{})
+			if labels == nil {
+				labels = map[string]interface{}{}
+			}
+			labels[models.DesignComponentLabel] = comp.Id.String()
+			metadata["labels"] = labels
+			if isNamespaced, ok := def.Metadata.AdditionalProperties["isNamespaced"].(bool); ok && !isNamespaced {
+				delete(metadata, "namespace")
+			}
+		}
+		resources = append(resources, renderedResource{index: indexes[comp], comp: comp, resource: resource})
+	}
+	return resources, nil
+}
+
+// declaredResolver resolves components to their own declaration, for exports which do not
+// have access to the registry.
+type declaredResolver struct{}
+
+func (declaredResolver) Resolve(comp *component.ComponentDefinition) (*component.ComponentDefinition, error) {
+	return comp, nil
+}
+
+// orderByDependencies orders the components so that every component comes after the
+// components it depends on, keeping the order of the design otherwise.
+func orderByDependencies(comps []*component.ComponentDefinition) ([]*component.ComponentDefinition, error) {
+	byID := map[string]*component.ComponentDefinition{}
+	for _, comp := range comps {
+		if comp != nil {
+			byID[comp.Id.String()] = comp
+		}
+	}
+
+	ordered := make([]*component.ComponentDefinition, 0, len(byID))
+	// state is 1 while the dependencies of a component are visited, 2 once it is ordered
+	state := map[string]int{}
+	var visit func(comp *component.ComponentDefinition) error
+	visit = func(comp *component.ComponentDefinition) error {
+		id := comp.Id.String()
+		switch state[id] {
+		case 1:
+			return fmt.Errorf("component %s depends on itself through its dependencies", comp.DisplayName)
+		case 2:
+			return nil
+		}
+		state[id] = 1
+		for _, dep := range componentDependencies(comp) {
+			if depComp, ok := byID[dep]; ok {
+				if err := visit(depComp); err != nil {
+					return err
+				}
+			}
+		}
+		state[id] = 2
+		ordered = append(ordered, comp)
+		return nil
+	}
+
+	for _, comp := range comps {
+		if comp == nil {
+			continue
+		}
+		if err := visit(comp); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// componentDependencies returns the ids of the components the component depends on.
+func componentDependencies(comp *component.ComponentDefinition) []string {
+	switch deps := comp.Metadata.AdditionalProperties["dependsOn"].(type) {
+	case []string:
+		return deps
+	case []interface{}:
+		ids := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			if id, ok := dep.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
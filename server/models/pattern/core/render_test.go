@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	"gopkg.in/yaml.v2"
+)
+
+// staticResolver resolves components to the definitions of their kind.
+type staticResolver map[string]*component.ComponentDefinition
+
+func (r staticResolver) Resolve(comp *component.ComponentDefinition) (*component.ComponentDefinition, error) {
+	def, ok := r[comp.Component.Kind]
+	if !ok {
+		return nil, fmt.Errorf("component %s is not registered", comp.Component.Kind)
+	}
+	return def, nil
+}
+
+func newTestDefinition(version string, isNamespaced bool) *component.ComponentDefinition {
+	return &component.ComponentDefinition{
+		Component: component.Component{Version: version},
+		Metadata: component.ComponentDefinition_Metadata{
+			AdditionalProperties: map[string]interface{}{"isNamespaced": isNamespaced},
+		},
+	}
+}
+
+func decodeManifests(t *testing.T, manifests []byte) []map[interface{}]interface{} {
+	t.Helper()
+	resources := []map[interface{}]interface{}{}
+	decoder := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		resource := map[interface{}]interface{}{}
+		err := decoder.Decode(&resource)
+		if err == io.EOF {
+			return resources
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, resource)
+	}
+}
+
+func TestRenderManifests(t *testing.T) {
+	resolver := staticResolver{
+		"Namespace":  newTestDefinition("v1", false),
+		"ConfigMap":  newTestDefinition("v1", true),
+		"Deployment": newTestDefinition("apps/v1", true),
+		"Comment":    {Metadata: component.ComponentDefinition_Metadata{IsAnnotation: true}},
+	}
+
+	namespace := withNamespace(newTestComponent("ns", "Namespace"), "prod")
+	config := withNamespace(newTestComponent("config", "ConfigMap", namespace.Id.String()), "prod")
+	deployment := newTestWorkload("web", "nginx", map[string]interface{}{
+		"dependsOn":       []interface{}{config.Id.String()},
+		NodeSelectorTrait: map[string]interface{}{"kubernetes.io/arch": "arm64"},
+	})
+	deployment.Component.Version = ""
+	note := newTestComponent("note", "Comment")
+	annotation := newTestComponent("group", "Group")
+	annotation.Metadata.IsAnnotation = true
+
+	patternFile := &pattern.PatternFile{
+		Name:       "test",
+		Components: []*component.ComponentDefinition{deployment, note, annotation, config, namespace},
+	}
+	before := componentsJSON(t, patternFile.Components)
+
+	manifests, err := RenderManifests(patternFile, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if componentsJSON(t, patternFile.Components) != before {
+		t.Error("the design was modified")
+	}
+
+	resources := decodeManifests(t, manifests)
+	kinds := []interface{}{}
+	for _, resource := range resources {
+		kinds = append(kinds, resource["kind"])
+	}
+	if want := []interface{}{"Namespace", "ConfigMap", "Deployment"}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+
+	metadata := func(i int) map[interface{}]interface{} {
+		return resources[i]["metadata"].(map[interface{}]interface{})
+	}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"namespace of a cluster-scoped resource", metadata(0)["namespace"], nil},
+		{"namespace of a namespaced resource", metadata(1)["namespace"], "prod"},
+		{"label of the component", metadata(1)["labels"], map[interface{}]interface{}{models.DesignComponentLabel: config.Id.String()}},
+		{"apiVersion of the definition", resources[2]["apiVersion"], "apps/v1"},
+		{"apiVersion of the design", resources[1]["apiVersion"], "v1"},
+		{"node selector of the scheduling trait", resources[2]["spec"].(map[interface{}]interface{})["template"].(map[interface{}]interface{})["spec"].(map[interface{}]interface{})["nodeSelector"],
+			map[interface{}]interface{}{"kubernetes.io/arch": "arm64"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestRenderManifests_Errors(t *testing.T) {
+	first := newTestComponent("first", "ConfigMap")
+	second := newTestComponent("second", "ConfigMap", first.Id.String())
+	first.Metadata.AdditionalProperties = map[string]interface{}{"dependsOn": []interface{}{second.Id.String()}}
+
+	tests := []struct {
+		name  string
+		comps []*component.ComponentDefinition
+	}{
+		{name: "unregistered component", comps: []*component.ComponentDefinition{newTestComponent("svc", "Service")}},
+		{name: "dependency cycle", comps: []*component.ComponentDefinition{first, second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderManifests(&pattern.PatternFile{Name: "test", Components: tt.comps}, staticResolver{"ConfigMap": newTestDefinition("v1", true)})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// SanitizeAction is what happens to a sensitive value of a design when it is sanitized.
+type SanitizeAction string
+
+const (
+	// SanitizeActionMask replaces sensitive values by the mask of the policy.
+	SanitizeActionMask SanitizeAction = "mask"
+	// SanitizeActionStrip removes sensitive values, along with their key.
+	SanitizeActionStrip SanitizeAction = "strip"
+)
+
+const (
+	// SanitizeDefaultMask replaces the sensitive values masked, unless the policy sets its own mask.
+	SanitizeDefaultMask = "********"
+	// SanitizeHostnameMask replaces the private hostnames and registries, whatever the action.
+	SanitizeHostnameMask = "redacted.invalid"
+)
+
+// DefaultSanitizeKeys match the keys of the settings holding credentials, eg. password, dbPassword,
+// clientSecret, accessToken or API_KEY. Only the string and number values of these keys are sensitive,
+// so that references like secretName or flags like automountServiceAccountToken are kept.
+var DefaultSanitizeKeys = []string{
+	`(?i)(password|passwd|passphrase|secret|token|api[-_]?key|access[-_]?key|private[-_]?key|credentials?)$`,
+	`(?i)^(auth|authorization|\.dockerconfigjson|connection[-_]?string)$`,
+}
+
+// DefaultSanitizeValues match the values which are credentials whatever their key: private keys, AWS
+// access keys, GitHub and Slack tokens, and JWTs.
+var DefaultSanitizeValues = []string{
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+	`\bAKIA[0-9A-Z]{16}\b`,
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,
+	`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`,
+}
+
+// SanitizePolicy selects the settings of a design which are sensitive and what happens to them when the
+// design is sanitized. The zero value masks the settings matched by the default keys and values.
+type SanitizePolicy struct {
+	// Action is mask by default.
+	Action SanitizeAction `json:"action,omitempty" yaml:"action,omitempty"`
+	// Mask replaces the masked values, SanitizeDefaultMask by default.
+	Mask string `json:"mask,omitempty" yaml:"mask,omitempty"`
+	// Keys are regular expressions matching the keys of sensitive values, DefaultSanitizeKeys by default.
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Values are regular expressions matching sensitive values, DefaultSanitizeValues by default.
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+	// Registries are the private registries whose host is masked in the images of the components,
+	// a registry matches its subdomains, eg. corp.example.com matches registry.corp.example.com.
+	Registries []string `json:"registries,omitempty" yaml:"registries,omitempty"`
+	// Hostnames are the private hostnames masked in every setting, matching their subdomains.
+	Hostnames []string `json:"hostnames,omitempty" yaml:"hostnames,omitempty"`
+}
+
+// Redaction is a value redacted from a design. Field is a JSON pointer to the value in the component,
+// eg. /configuration/spec/template/spec/containers/0/env/1/value, or in the overlay for the settings
+// of overlays, eg. /overlays/staging/settings/api/spec.
+type Redaction struct {
+	ComponentID uuid.UUID      `json:"component_id,omitempty"`
+	Component   string         `json:"component"`
+	Field       string         `json:"field"`
+	Reason      string         `json:"reason"`
+	Action      SanitizeAction `json:"action"`
+}
+
+// SanitizeReport is the report of the values redacted from a design, in the order of the design.
+type SanitizeReport struct {
+	Design     string      `json:"design"`
+	Redactions []Redaction `json:"redactions"`
+}
+
+// Redacted returns whether values were redacted.
+func (r *SanitizeReport) Redacted() bool {
+	return len(r.Redactions) > 0
+}
+
+// SanitizePatternFile returns a copy of the design with the sensitive settings of its components masked
+// or stripped according to the policy, leaving the design untouched, along with the report of what was
+// redacted. Secret references are kept, the data of Secrets is always sensitive.
+//
+// Designs are sanitized before they are shared, exported or published to the catalog.
+func SanitizePatternFile(patternFile *pattern.PatternFile, policy SanitizePolicy) (*pattern.PatternFile, *SanitizeReport, error) {
+	s, err := newSanitizer(policy, patternFile.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	sanitized, err := clonePatternFile(patternFile)
+	if err != nil {
+		return nil, nil, ErrSanitizePattern(err, patternFile.Name)
+	}
+	for _, comp := range sanitized.Components {
+		if comp == nil {
+			continue
+		}
+		// the configurations of parsed designs may hold typed values
+		configuration, err := toInterface(comp.Configuration)
+		if err != nil {
+			return nil, nil, ErrSanitizePattern(fmt.Errorf("component %s: %w", comp.DisplayName, err), patternFile.Name)
+		}
+		s.componentID, s.component = comp.Id, comp.DisplayName
+		secret := comp.Component.Kind == "Secret"
+		if config, ok := configuration.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(config) {
+				sensitive := secret && (key == "data" || key == "stringData")
+				if value, keep := s.sanitize("/configuration/"+escapeJSONPointer(key), key, config[key], sensitive); keep {
+					config[key] = value
+				} else {
+					delete(config, key)
+				}
+			}
+			comp.Configuration = config
+		}
+	}
+	debugf("sanitized design %s, %d values redacted", patternFile.Name, len(s.report.Redactions))
+	return sanitized, s.report, nil
+}
+
+// Sanitize returns a copy of the design, along with its overlays, with its sensitive settings masked or
+// stripped according to the policy, and the report of what was redacted.
+func (p *Pattern) Sanitize(policy SanitizePolicy) (*Pattern, *SanitizeReport, error) {
+	sanitizedFile, report, err := SanitizePatternFile(&p.File, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := newSanitizer(policy, p.File.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.report = report
+
+	sanitized := &Pattern{File: *sanitizedFile, Overlays: make([]PatternOverlay, 0, len(p.Overlays))}
+	for _, overlay := range p.Overlays {
+		settings := make(map[string]map[string]interface{}, len(overlay.Settings))
+		for _, componentName := range sortedKeys(overlay.Settings) {
+			copied, err := toInterface(overlay.Settings[componentName])
+			if err != nil {
+				return nil, nil, ErrSanitizePattern(fmt.Errorf("overlay %s: %w", overlay.Name, err), p.File.Name)
+			}
+			config, _ := copied.(map[string]interface{})
+			s.componentID, s.component = uuid.Nil, componentName
+			path := "/overlays/" + escapeJSONPointer(overlay.Name) + "/settings/" + escapeJSONPointer(componentName)
+			for _, key := range sortedKeys(config) {
+				if value, keep := s.sanitize(path+"/"+escapeJSONPointer(key), key, config[key], false); keep {
+					config[key] = value
+				} else {
+					delete(config, key)
+				}
+			}
+			settings[componentName] = config
+		}
+		overlay.Settings = settings
+		sanitized.Overlays = append(sanitized.Overlays, overlay)
+	}
+	return sanitized, s.report, nil
+}
+
+type sanitizer struct {
+	action     SanitizeAction
+	mask       string
+	keys       []*regexp.Regexp
+	values     []*regexp.Regexp
+	registries []string
+	hostnames  []string
+
+	report      *SanitizeReport
+	componentID uuid.UUID
+	component   string
+}
+
+var (
+	urlCredentialsPattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://)([^:/@\s]+):([^@\s]+)@`)
+	hostnamePattern       = regexp.MustCompile(`[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)+`)
+)
+
+func newSanitizer(policy SanitizePolicy, designName string) (*sanitizer, error) {
+	s := &sanitizer{
+		action:     policy.Action,
+		mask:       policy.Mask,
+		registries: normalizeHostnames(policy.Registries),
+		hostnames:  normalizeHostnames(policy.Hostnames),
+		report:     &SanitizeReport{Design: designName, Redactions: []Redaction{}},
+	}
+	switch s.action {
+	case "":
+		s.action = SanitizeActionMask
+	case SanitizeActionMask, SanitizeActionStrip:
+	default:
+		return nil, ErrSanitizePolicy(fmt.Errorf("unknown action %q", policy.Action))
+	}
+	if s.mask == "" {
+		s.mask = SanitizeDefaultMask
+	}
+
+	keys, values := policy.Keys, policy.Values
+	if len(keys) == 0 {
+		keys = DefaultSanitizeKeys
+	}
+	if len(values) == 0 {
+		values = DefaultSanitizeValues
+	}
+	var err error
+	if s.keys, err = compileSanitizePatterns(keys); err != nil {
+		return nil, err
+	}
+	if s.values, err = compileSanitizePatterns(values); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func compileSanitizePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, ErrSanitizePolicy(fmt.Errorf("invalid pattern %q: %w", p, err))
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func normalizeHostnames(hostnames []string) []string {
+	normalized := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		if hostname = strings.Trim(strings.ToLower(strings.TrimSpace(hostname)), "."); hostname != "" {
+			normalized = append(normalized, hostname)
+		}
+	}
+	return normalized
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesHostname(hostnames []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, hostname := range hostnames {
+		if host == hostname || strings.HasSuffix(host, "."+hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sanitizer) redact(path, reason string, action SanitizeAction) {
+	s.report.Redactions = append(s.report.Redactions, Redaction{
+		ComponentID: s.componentID,
+		Component:   s.component,
+		Field:       path,
+		Reason:      reason,
+		Action:      action,
+	})
+}
+
+// sanitize returns the sanitized value of the key, and whether to keep it. The values under sensitive keys
+// are sensitive, secret references are kept as they hold no secret.
+func (s *sanitizer) sanitize(path, key string, value interface{}, sensitive bool) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v[SecretRefKey]; ok && len(v) == 1 {
+			return v, true
+		}
+		// env-style entries, eg. {name: DB_PASSWORD, value: hunter2}
+		name, _ := v["name"].(string)
+		sensitiveEntry := name != "" && matchesAny(s.keys, name)
+		for _, k := range sortedKeys(v) {
+			childSensitive := sensitive || (sensitiveEntry && k == "value")
+			if sanitized, keep := s.sanitize(path+"/"+escapeJSONPointer(k), k, v[k], childSensitive); keep {
+				v[k] = sanitized
+			} else {
+				delete(v, k)
+			}
+		}
+		return v, true
+	case []interface{}:
+		sanitized := make([]interface{}, 0, len(v))
+		for i, val := range v {
+			// the items of lists are sensitive as their key
+			if item, keep := s.sanitize(path+"/"+strconv.Itoa(i), key, val, sensitive); keep {
+				sanitized = append(sanitized, item)
+			}
+		}
+		return sanitized, true
+	case string:
+		// the values masked already are not redacted again, so that sanitized designs are left as they are
+		if v == "" || v == s.mask {
+			return v, true
+		}
+		if sensitive || matchesAny(s.keys, key) {
+			s.redact(path, fmt.Sprintf("sensitive setting %s", key), s.action)
+			return s.mask, s.action != SanitizeActionStrip
+		}
+		if matchesAny(s.values, v) {
+			s.redact(path, "credential value", s.action)
+			return s.mask, s.action != SanitizeActionStrip
+		}
+		return s.sanitizeString(path, key, v), true
+	case float64, int, int64:
+		if sensitive || matchesAny(s.keys, key) {
+			s.redact(path, fmt.Sprintf("sensitive setting %s", key), s.action)
+			return s.mask, s.action != SanitizeActionStrip
+		}
+	}
+	return value, true
+}
+
+// sanitizeString masks or strips the credentials of the URLs, and masks the private hostnames of the string.
+// Stripping the credentials of a URL removes its user along with the password.
+func (s *sanitizer) sanitizeString(path, key, value string) string {
+	masked := false
+	value = urlCredentialsPattern.ReplaceAllStringFunc(value, func(url string) string {
+		match := urlCredentialsPattern.FindStringSubmatch(url)
+		if match[3] == s.mask {
+			return url
+		}
+		masked = true
+		if s.action == SanitizeActionStrip {
+			return match[1]
+		}
+		return match[1] + match[2] + ":" + s.mask + "@"
+	})
+	if masked {
+		s.redact(path, "credentials in URL", s.action)
+	}
+
+	if key == "image" && len(s.registries) > 0 {
+		if host, rest, ok := strings.Cut(value, "/"); ok && strings.ContainsAny(host, ".:") {
+			if registry, _, _ := strings.Cut(host, ":"); matchesHostname(s.registries, registry) {
+				value = SanitizeHostnameMask + "/" + rest
+				s.redact(path, "private registry", SanitizeActionMask)
+			}
+		}
+	}
+
+	if len(s.hostnames) > 0 {
+		masked = false
+		value = hostnamePattern.ReplaceAllStringFunc(value, func(host string) string {
+			if matchesHostname(s.hostnames, host) {
+				masked = true
+				return SanitizeHostnameMask
+			}
+			return host
+		})
+		if masked {
+			s.redact(path, "private hostname", SanitizeActionMask)
+		}
+	}
+	return value
+}
@@ -0,0 +1,445 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Scheduling traits are declared in the metadata of the components of a design and are set on
+// the pod template of the workloads when they are rendered or deployed, eg.
+//
+//	metadata:
+//	  nodeSelector:
+//	    kubernetes.io/arch: arm64
+//	  tolerations:
+//	    - key: dedicated
+//	      operator: Equal
+//	      value: edge
+//	      effect: NoSchedule
+const (
+	NodeSelectorTrait = "nodeSelector"
+	TolerationsTrait  = "tolerations"
+)
+
+// ApplySchedulingTraits sets the node selector and the tolerations declared by the scheduling
+// traits of the component on the pod template of its configuration. The node selector of the
+// trait takes precedence over the node selector of the configuration, tolerations are added.
+// Components which are not workloads are left unchanged.
+func ApplySchedulingTraits(comp *component.ComponentDefinition) error {
+	nodeSelector, tolerations, err := schedulingTraits(comp)
+	if err != nil {
+		return ErrSchedulingTraits(err, comp.DisplayName)
+	}
+	if len(nodeSelector) == 0 && len(tolerations) == 0 {
+		return nil
+	}
+	spec := podSpec(comp.Component.Kind, comp.Configuration, true)
+	if spec == nil {
+		return nil
+	}
+
+	if len(nodeSelector) > 0 {
+		selector, _ := spec["nodeSelector"].(map[string]interface{})
+		if selector == nil {
+			selector = map[string]interface{}{}
+		}
+		for key, value := range nodeSelector {
+			selector[key] = value
+		}
+		spec["nodeSelector"] = selector
+	}
+
+	if len(tolerations) > 0 {
+		existing, err := podTolerations(spec)
+		if err != nil {
+			return ErrSchedulingTraits(err, comp.DisplayName)
+		}
+		merged, _ := spec["tolerations"].([]interface{})
+		for _, toleration := range tolerations {
+			if containsToleration(existing, toleration) {
+				continue
+			}
+			value, err := toInterface(toleration)
+			if err != nil {
+				return ErrSchedulingTraits(err, comp.DisplayName)
+			}
+			merged = append(merged, value)
+		}
+		spec["tolerations"] = merged
+	}
+	return nil
+}
+
+// schedulingTraits returns the node selector and the tolerations declared by the component.
+func schedulingTraits(comp *component.ComponentDefinition) (map[string]string, []corev1.Toleration, error) {
+	nodeSelector := map[string]string{}
+	tolerations := []corev1.Toleration{}
+	if value, ok := comp.Metadata.AdditionalProperties[NodeSelectorTrait]; ok && value != nil {
+		if err := fromInterface(value, &nodeSelector); err != nil {
+			return nil, nil, fmt.Errorf("%s must be a map of node labels: %w", NodeSelectorTrait, err)
+		}
+	}
+	if value, ok := comp.Metadata.AdditionalProperties[TolerationsTrait]; ok && value != nil {
+		if err := fromInterface(value, &tolerations); err != nil {
+			return nil, nil, fmt.Errorf("%s must be a list of tolerations: %w", TolerationsTrait, err)
+		}
+	}
+	return nodeSelector, tolerations, nil
+}
+
+// podSpec returns the pod spec in the configuration of a workload, nil if the kind is not a
+// workload. Missing parents of the pod spec are created when create is set.
+func podSpec(kind string, configuration map[string]interface{}, create bool) map[string]interface{} {
+	path := podSpecPath(kind)
+	if path == nil || configuration == nil {
+		return nil
+	}
+
+	current := configuration
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil
+			}
+			next = map[string]interface{}{}
+			current[key] = next
+		}
+		current = next
+	}
+	return current
+}
+
+// podSpecPath returns the path of the pod spec in the configuration of a workload, nil if the
+// kind is not a workload.
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return []string{"spec", "template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	return nil
+}
+
+func podTolerations(spec map[string]interface{}) ([]corev1.Toleration, error) {
+	tolerations := []corev1.Toleration{}
+	if value, ok := spec["tolerations"]; ok && value != nil {
+		if err := fromInterface(value, &tolerations); err != nil {
+			return nil, err
+		}
+	}
+	return tolerations, nil
+}
+
+func containsToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if t.MatchToleration(&toleration) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromInterface converts a value decoded from a design into the type of out.
+func fromInterface(value interface{}, out interface{}) error {
+	byt, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(byt, out)
+}
+
+// toInterface converts a value into the maps and the slices of a design.
+func toInterface(value interface{}) (interface{}, error) {
+	var out interface{}
+	err := fromInterface(value, &out)
+	return out, err
+}
+
+// ClusterNode is the part of a node of a cluster which decides which pods can be scheduled on it.
+type ClusterNode struct {
+	Name          string            `json:"name"`
+	OS            string            `json:"os"`
+	Architecture  string            `json:"architecture"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Taints        []corev1.Taint    `json:"taints,omitempty"`
+	Unschedulable bool              `json:"unschedulable,omitempty"`
+}
+
+// NewClusterNodes returns the scheduling relevant part of the nodes of a cluster.
+func NewClusterNodes(nodes []corev1.Node) []ClusterNode {
+	clusterNodes := make([]ClusterNode, 0, len(nodes))
+	for _, node := range nodes {
+		clusterNodes = append(clusterNodes, ClusterNode{
+			Name:          node.Name,
+			OS:            node.Status.NodeInfo.OperatingSystem,
+			Architecture:  node.Status.NodeInfo.Architecture,
+			Labels:        node.Labels,
+			Taints:        node.Spec.Taints,
+			Unschedulable: node.Spec.Unschedulable,
+		})
+	}
+	return clusterNodes
+}
+
+// Platform returns the platform of the node, eg. linux/arm64.
+func (n ClusterNode) Platform() string {
+	return n.OS + "/" + n.Architecture
+}
+
+// schedules returns whether a pod with the node selector and the tolerations can be scheduled
+// on the node, and the reason when it can not.
+func (n ClusterNode) schedules(nodeSelector map[string]string, tolerations []corev1.Toleration) (bool, string) {
+	if n.Unschedulable {
+		return false, "cordoned"
+	}
+	keys := make([]string, 0, len(nodeSelector))
+	for key := range nodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if n.Labels[key] != nodeSelector[key] {
+			return false, fmt.Sprintf("%s is not %s", key, nodeSelector[key])
+		}
+	}
+	for i := range n.Taints {
+		taint := &n.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false, fmt.Sprintf("taint %s is not tolerated", taint.ToString())
+		}
+	}
+	return true, ""
+}
+
+// ImagePlatformResolver returns the platforms, eg. linux/amd64, an image is available for.
+type ImagePlatformResolver interface {
+	Platforms(ctx context.Context, image string) ([]string, error)
+}
+
+// RegistryPlatformResolver inspects the manifests of images in their registries.
+type RegistryPlatformResolver struct {
+	// Options configure the access to the registries, eg. their credentials.
+	Options []remote.Option
+}
+
+// Platforms returns the platforms of the manifests of the image index, or the platform of the
+// configuration of the image when it is not an index.
+func (r *RegistryPlatformResolver) Platforms(ctx context.Context, image string) ([]string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	options := append([]remote.Option{remote.WithContext(ctx)}, r.Options...)
+	desc, err := remote.Get(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		platforms := []string{}
+		for _, m := range manifest.Manifests {
+			// attestations are listed with an unknown platform
+			if m.Platform == nil || m.Platform.OS == "unknown" {
+				continue
+			}
+			platforms = append(platforms, platformString(m.Platform))
+		}
+		return platforms, nil
+	}
+
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return []string{platformString(&v1.Platform{OS: config.OS, Architecture: config.Architecture})}, nil
+}
+
+// CachedPlatformResolver remembers the platforms of the images, or the failure to resolve them,
+// so that the images shared by components and clusters are inspected once.
+type CachedPlatformResolver struct {
+	Resolver ImagePlatformResolver
+
+	mu      sync.Mutex
+	results map[string]platformsResult
+}
+
+type platformsResult struct {
+	platforms []string
+	err       error
+}
+
+// Platforms returns the platforms of the image returned by the resolver.
+func (r *CachedPlatformResolver) Platforms(ctx context.Context, image string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if result, ok := r.results[image]; ok {
+		return result.platforms, result.err
+	}
+	platforms, err := r.Resolver.Platforms(ctx, image)
+	if r.results == nil {
+		r.results = map[string]platformsResult{}
+	}
+	r.results[image] = platformsResult{platforms: platforms, err: err}
+	return platforms, err
+}
+
+// platformString formats the platform like nodes report theirs, the variant is left out.
+func platformString(p *v1.Platform) string {
+	return p.OS + "/" + p.Architecture
+}
+
+// SchedulingWarning reports a component of a design which would not be scheduled on a cluster.
+type SchedulingWarning struct {
+	ComponentID uuid.UUID `json:"component_id"`
+	Component   string    `json:"component"`
+	Image       string    `json:"image,omitempty"`
+	Message     string    `json:"message"`
+}
+
+// CheckScheduling checks that the workloads of the design can be scheduled on the nodes of a
+// cluster: that some node is selected by their scheduling traits and node selectors and has its
+// taints tolerated, and that their images are available for the platform of such a node. Images
+// which can not be inspected are reported as warnings too, as the check is then incomplete.
+// Wrap the resolver in a CachedPlatformResolver to inspect the images shared by components once.
+func CheckScheduling(ctx context.Context, patternFile *pattern.PatternFile, nodes []ClusterNode, resolver ImagePlatformResolver) ([]SchedulingWarning, error) {
+	warnings := []SchedulingWarning{}
+
+	for _, comp := range patternFile.Components {
+		if comp == nil || comp.Metadata.IsAnnotation {
+			continue
+		}
+		resolved := *comp
+		resolved.Configuration, _ = deepCopyValue(comp.Configuration).(map[string]interface{})
+		if err := ApplySchedulingTraits(&resolved); err != nil {
+			return nil, err
+		}
+		spec := podSpec(resolved.Component.Kind, resolved.Configuration, false)
+		if spec == nil {
+			continue
+		}
+
+		var pod corev1.PodSpec
+		if err := fromInterface(spec, &pod); err != nil {
+			return nil, ErrSchedulingTraits(err, comp.DisplayName)
+		}
+		warn := func(image, message string) {
+			warnings = append(warnings, SchedulingWarning{ComponentID: comp.Id, Component: comp.DisplayName, Image: image, Message: message})
+		}
+
+		eligible := []ClusterNode{}
+		reasons := map[string]int{}
+		for _, node := range nodes {
+			ok, reason := node.schedules(pod.NodeSelector, pod.Tolerations)
+			if ok {
+				eligible = append(eligible, node)
+			} else {
+				reasons[reason]++
+			}
+		}
+		if len(eligible) == 0 {
+			if len(nodes) == 0 {
+				warn("", "the cluster has no nodes")
+			} else {
+				warn("", fmt.Sprintf("no node can run the pods of the component: %s", summarizeReasons(reasons)))
+			}
+			continue
+		}
+
+		// nodes which can run every image of the pod
+		runnable := eligible
+		containers := append(append([]corev1.Container{}, pod.InitContainers...), pod.Containers...)
+		for _, container := range containers {
+			if container.Image == "" {
+				continue
+			}
+			platforms, err := resolver.Platforms(ctx, container.Image)
+			if err != nil {
+				warn(container.Image, fmt.Sprintf("the platforms of the image could not be inspected: %v", err))
+				continue
+			}
+
+			compatible := []ClusterNode{}
+			for _, node := range runnable {
+				if slices.Contains(platforms, node.Platform()) {
+					compatible = append(compatible, node)
+				}
+			}
+			if len(compatible) == 0 {
+				warn(container.Image, fmt.Sprintf("the image is available for %s, which no node that can run the pods of the component provides (%s)",
+					strings.Join(platforms, ", "), strings.Join(nodePlatforms(runnable), ", ")))
+				continue
+			}
+			runnable = compatible
+		}
+	}
+	return warnings, nil
+}
+
+// summarizeReasons formats the reasons nodes were not eligible with the number of nodes, eg.
+// "2 nodes: kubernetes.io/arch is not arm64; 1 node: cordoned".
+func summarizeReasons(reasons map[string]int) string {
+	keys := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		keys = append(keys, reason)
+	}
+	sort.Strings(keys)
+	summary := make([]string, 0, len(keys))
+	for _, reason := range keys {
+		nodes := "nodes"
+		if reasons[reason] == 1 {
+			nodes = "node"
+		}
+		summary = append(summary, fmt.Sprintf("%d %s: %s", reasons[reason], nodes, reason))
+	}
+	return strings.Join(summary, "; ")
+}
+
+func nodePlatforms(nodes []ClusterNode) []string {
+	platforms := []string{}
+	for _, node := range nodes {
+		if !slices.Contains(platforms, node.Platform()) {
+			platforms = append(platforms, node.Platform())
+		}
+	}
+	sort.Strings(platforms)
+	return platforms
+}
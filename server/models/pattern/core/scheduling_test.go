@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type staticPlatformResolver map[string][]string
+
+func (r staticPlatformResolver) Platforms(_ context.Context, image string) ([]string, error) {
+	return r[image], nil
+}
+
+func newTestWorkload(name, image string, traits map[string]interface{}) *component.ComponentDefinition {
+	comp := newTestComponent(name, "Deployment")
+	comp.Configuration["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": name, "image": image},
+				},
+			},
+		},
+	}
+	comp.Metadata.AdditionalProperties = traits
+	return comp
+}
+
+func TestCheckScheduling(t *testing.T) {
+	nodes := []ClusterNode{
+		{Name: "amd", OS: "linux", Architecture: "amd64", Labels: map[string]string{"kubernetes.io/arch": "amd64"}},
+		{
+			Name: "arm", OS: "linux", Architecture: "arm64", Labels: map[string]string{"kubernetes.io/arch": "arm64"},
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "edge", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	resolver := staticPlatformResolver{
+		"multi":      {"linux/amd64", "linux/arm64"},
+		"amd64-only": {"linux/amd64"},
+	}
+	armTraits := func() map[string]interface{} {
+		return map[string]interface{}{
+			NodeSelectorTrait: map[string]interface{}{"kubernetes.io/arch": "arm64"},
+		}
+	}
+	tolerateEdge := []interface{}{
+		map[string]interface{}{"key": "dedicated", "operator": "Equal", "value": "edge", "effect": "NoSchedule"},
+	}
+
+	tests := []struct {
+		name    string
+		comp    *component.ComponentDefinition
+		warning string
+	}{
+		{
+			name: "Multi-arch image without traits",
+			comp: newTestWorkload("web", "multi", nil),
+		},
+		{
+			name:    "Selected node taint is not tolerated",
+			comp:    newTestWorkload("web", "multi", armTraits()),
+			warning: "taint dedicated=edge:NoSchedule is not tolerated",
+		},
+		{
+			name: "Selected node taint is tolerated",
+			comp: func() *component.ComponentDefinition {
+				traits := armTraits()
+				traits[TolerationsTrait] = tolerateEdge
+				return newTestWorkload("web", "multi", traits)
+			}(),
+		},
+		{
+			name: "Image is not available for the selected node",
+			comp: func() *component.ComponentDefinition {
+				traits := armTraits()
+				traits[TolerationsTrait] = tolerateEdge
+				return newTestWorkload("web", "amd64-only", traits)
+			}(),
+			warning: "the image is available for linux/amd64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf := &pattern.PatternFile{Name: "test", Components: []*component.ComponentDefinition{tt.comp}}
+			warnings, err := CheckScheduling(context.Background(), pf, nodes, resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.warning == "" {
+				if len(warnings) != 0 {
+					t.Errorf("expected no warnings, got %+v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0].Message, tt.warning) {
+				t.Errorf("expected a warning containing %q, got %+v", tt.warning, warnings)
+			}
+		})
+	}
+}
+
+func TestApplySchedulingTraits(t *testing.T) {
+	comp := newTestWorkload("web", "multi", map[string]interface{}{
+		NodeSelectorTrait: map[string]interface{}{"kubernetes.io/arch": "arm64"},
+		TolerationsTrait: []interface{}{
+			map[string]interface{}{"key": "dedicated", "operator": "Exists"},
+		},
+	})
+	// applying the traits again, eg. when deploying a rendered design, must not duplicate them
+	for i := 0; i < 2; i++ {
+		if err := ApplySchedulingTraits(comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := podSpec(comp.Component.Kind, comp.Configuration, false)
+	selector, _ := spec["nodeSelector"].(map[string]interface{})
+	if selector["kubernetes.io/arch"] != "arm64" {
+		t.Errorf("expected the node selector to be set, got %v", spec["nodeSelector"])
+	}
+	tolerations, _ := spec["tolerations"].([]interface{})
+	if len(tolerations) != 1 {
+		t.Errorf("expected one toleration, got %v", spec["tolerations"])
+	}
+}
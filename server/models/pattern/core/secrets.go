@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretRefKey is the key of the values of the configuration of components which refer to a secret
+// instead of holding it, so that designs never store secrets in plaintext, eg.
+//
+//	env:
+//	  - name: DB_PASSWORD
+//	    value:
+//	      secretRef:
+//	        source: kubernetes
+//	        name: db-credentials
+//	        key: password
+const SecretRefKey = "secretRef"
+
+const (
+	// SecretSourceKubernetes refers to the key of a Secret of the cluster the design is deployed to.
+	SecretSourceKubernetes = "kubernetes"
+	// SecretSourceEnv refers to an environment variable of Meshery Server, prefixed with PatternSecretEnvPrefix.
+	SecretSourceEnv = "env"
+)
+
+// PatternSecretEnvPrefix is the prefix of the environment variables env secret references refer to, so
+// that designs cannot read any other environment variable of Meshery Server. For example, the reference
+// {source: env, name: DB_PASSWORD} resolves to MESHERY_SECRET_DB_PASSWORD.
+const PatternSecretEnvPrefix = "MESHERY_SECRET_"
+
+// SecretRef refers to a secret from its source, the other fields are interpreted by the resolver of
+// the source.
+type SecretRef struct {
+	Source string `json:"source" yaml:"source"`
+	Name   string `json:"name" yaml:"name"`
+	// Namespace of the Secret of kubernetes references, the namespace of the component by default.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Key       string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+func (ref SecretRef) String() string {
+	s := ref.Source + ":"
+	if ref.Namespace != "" {
+		s += ref.Namespace + "/"
+	}
+	s += ref.Name
+	if ref.Key != "" {
+		s += "#" + ref.Key
+	}
+	return s
+}
+
+// SecretResolver resolves references to the secrets of a source to their values.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, ref SecretRef) (string, error)
+}
+
+// SecretResolverFunc is a function resolving secrets.
+type SecretResolverFunc func(ctx context.Context, ref SecretRef) (string, error)
+
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, ref SecretRef) (string, error) {
+	return f(ctx, ref)
+}
+
+// RegisterSecretResolver sets the resolver of the references to the secrets of the source, eg. an external
+// secret manager, replacing the resolver previously registered for the source.
+func RegisterSecretResolver(source string, resolver SecretResolver) {
+	processing.mx.Lock()
+	defer processing.mx.Unlock()
+	if processing.secretResolvers == nil {
+		processing.secretResolvers = map[string]SecretResolver{}
+	}
+	processing.secretResolvers[source] = resolver
+}
+
+func registeredSecretResolver(source string) SecretResolver {
+	processing.mx.RLock()
+	defer processing.mx.RUnlock()
+	if resolver, ok := processing.secretResolvers[source]; ok {
+		return resolver
+	}
+	if source == SecretSourceEnv {
+		return EnvSecretResolver{}
+	}
+	return nil
+}
+
+// EnvSecretResolver resolves env references to the environment variables of Meshery Server prefixed with
+// PatternSecretEnvPrefix.
+type EnvSecretResolver struct{}
+
+func (EnvSecretResolver) ResolveSecret(_ context.Context, ref SecretRef) (string, error) {
+	value, ok := os.LookupEnv(PatternSecretEnvPrefix + ref.Name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s%s is not set", PatternSecretEnvPrefix, ref.Name)
+	}
+	return value, nil
+}
+
+// KubernetesSecretResolver resolves kubernetes references to the keys of Secrets, looked up in the
+// clusters in order.
+type KubernetesSecretResolver struct {
+	Clients []kubernetes.Interface
+}
+
+func (r *KubernetesSecretResolver) ResolveSecret(ctx context.Context, ref SecretRef) (string, error) {
+	if ref.Key == "" {
+		return "", fmt.Errorf("the key of the Secret is required")
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	for _, client := range r.Clients {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", fmt.Errorf("secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+		}
+		return string(value), nil
+	}
+	return "", fmt.Errorf("secret %s/%s does not exist", namespace, ref.Name)
+}
+
+// HasSecretRefs returns whether the configuration of a component of the design refers to a secret.
+func HasSecretRefs(patternFile *pattern.PatternFile) bool {
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		// the configurations of parsed designs may hold typed values
+		if configuration, err := toInterface(comp.Configuration); err == nil && containsSecretRef(configuration) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePatternSecrets returns a copy of the design with the secret references of the configurations
+// of its components replaced by the values of the secrets, leaving the design untouched. The resolvers,
+// by source, take precedence over the registered resolvers. Kubernetes references without a namespace
+// refer to the namespace of their component. Every reference which cannot be resolved is reported.
+//
+// Designs are resolved when they are deployed, exporting a design keeps its references.
+func ResolvePatternSecrets(ctx context.Context, patternFile *pattern.PatternFile, resolvers map[string]SecretResolver) (*pattern.PatternFile, error) {
+	resolved, err := clonePatternFile(patternFile)
+	if err != nil {
+		return nil, ErrResolvePatternSecrets(err, patternFile.Name)
+	}
+
+	errs := []error{}
+	for _, comp := range resolved.Components {
+		if comp == nil {
+			continue
+		}
+		metadata, _ := comp.Configuration["metadata"].(map[string]interface{})
+		namespace, _ := metadata["namespace"].(string)
+		configuration := walkSecretRefs(comp.Configuration, func(value interface{}) interface{} {
+			ref := SecretRef{}
+			if err := fromInterface(value, &ref); err != nil || ref.Source == "" || ref.Name == "" {
+				errs = append(errs, fmt.Errorf("component %s: invalid secret reference %v, a source and a name are required", comp.DisplayName, value))
+				return ""
+			}
+			if ref.Source == SecretSourceKubernetes && ref.Namespace == "" {
+				ref.Namespace = namespace
+			}
+			resolver, ok := resolvers[ref.Source]
+			if !ok {
+				resolver = registeredSecretResolver(ref.Source)
+			}
+			if resolver == nil {
+				errs = append(errs, fmt.Errorf("component %s: secret %s: no resolver for the source %q", comp.DisplayName, ref, ref.Source))
+				return ""
+			}
+			secret, err := resolver.ResolveSecret(ctx, ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("component %s: secret %s: %w", comp.DisplayName, ref, err))
+				return ""
+			}
+			return secret
+		})
+		comp.Configuration, _ = configuration.(map[string]interface{})
+	}
+	if len(errs) > 0 {
+		return nil, ErrResolvePatternSecrets(errors.Join(errs...), patternFile.Name)
+	}
+	debugf("resolved the secret references of design %s", patternFile.Name)
+	return resolved, nil
+}
+
+func containsSecretRef(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v[SecretRefKey]; ok && len(v) == 1 {
+			return true
+		}
+		for _, val := range v {
+			if containsSecretRef(val) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if containsSecretRef(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkSecretRefs replaces the secret references of the value by the result of resolve, in place.
+func walkSecretRefs(value interface{}, resolve func(ref interface{}) interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v[SecretRefKey]; ok && len(v) == 1 {
+			return resolve(ref)
+		}
+		for k, val := range v {
+			v[k] = walkSecretRefs(val, resolve)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = walkSecretRefs(val, resolve)
+		}
+	}
+	return value
+}
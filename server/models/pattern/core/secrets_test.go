@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const secretsDesign = `
+name: secrets
+components:
+  - id: 4b7e3d5f-8091-4ca3-9dce-3f4a5b6c7d8e
+    displayName: api
+    component:
+      kind: Deployment
+      version: apps/v1
+    model:
+      name: kubernetes
+    configuration:
+      metadata:
+        namespace: shop
+      spec:
+        template:
+          spec:
+            containers:
+              - name: api
+                image: api
+                env:
+                  - name: DB_PASSWORD
+                    value:
+                      secretRef:
+                        source: kubernetes
+                        name: db-credentials
+                        key: password
+                  - name: API_TOKEN
+                    value:
+                      secretRef:
+                        source: env
+                        name: API_TOKEN
+                  - name: LICENSE
+                    value:
+                      secretRef:
+                        source: vault
+                        name: license
+`
+
+func TestResolvePatternSecrets(t *testing.T) {
+	pf, err := NewPatternFile([]byte(secretsDesign))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasSecretRefs(&pf) {
+		t.Fatal("expected the design to refer to secrets")
+	}
+
+	t.Setenv(PatternSecretEnvPrefix+"API_TOKEN", "token")
+	clients := &KubernetesSecretResolver{}
+	clients.Clients = append(clients.Clients, fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "shop"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}))
+	resolvers := map[string]SecretResolver{SecretSourceKubernetes: clients}
+
+	if _, err := ResolvePatternSecrets(context.Background(), &pf, resolvers); err == nil || !strings.Contains(err.Error(), `no resolver for the source "vault"`) {
+		t.Fatalf("expected the vault reference to fail without a resolver, got %v", err)
+	}
+
+	RegisterSecretResolver("vault", SecretResolverFunc(func(_ context.Context, ref SecretRef) (string, error) {
+		return "vault:" + ref.Name, nil
+	}))
+	defer func() {
+		processing.mx.Lock()
+		delete(processing.secretResolvers, "vault")
+		processing.mx.Unlock()
+	}()
+
+	resolved, err := ResolvePatternSecrets(context.Background(), &pf, resolvers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := podSpec("Deployment", resolved.Components[0].Configuration, false)
+	containers, _ := spec["containers"].([]interface{})
+	container, _ := containers[0].(map[string]interface{})
+	env, _ := container["env"].([]interface{})
+	values := map[string]interface{}{}
+	for _, e := range env {
+		v, _ := e.(map[string]interface{})
+		values[v["name"].(string)] = v["value"]
+	}
+	for name, want := range map[string]string{"DB_PASSWORD": "hunter2", "API_TOKEN": "token", "LICENSE": "vault:license"} {
+		if values[name] != want {
+			t.Errorf("expected %s to be resolved to %q, got %v", name, want, values[name])
+		}
+	}
+	if HasSecretRefs(resolved) {
+		t.Error("expected every reference to be resolved")
+	}
+	if !HasSecretRefs(&pf) {
+		t.Error("expected the design to keep its references")
+	}
+}
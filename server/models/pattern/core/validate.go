@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models/pattern/jsonschema"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	registry "github.com/layer5io/meshkit/models/meshmodel/registry"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+// ConfigurationViolation is a single violation of a component's configuration
+// against the schema of its registered ComponentDefinition.
+// Path is a JSON pointer into the configuration of the component, empty when
+// the violation concerns the component as a whole.
+type ConfigurationViolation struct {
+	ComponentID   uuid.UUID `json:"component_id"`
+	ComponentName string    `json:"component_name"`
+	Path          string    `json:"path"`
+	Message       string    `json:"message"`
+}
+
+// PatternValidationError aggregates all the violations found while validating a design.
+type PatternValidationError struct {
+	Violations []ConfigurationViolation `json:"violations"`
+}
+
+func (e *PatternValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		if v.Path == "" {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", v.ComponentName, v.Message))
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("%s%s: %s", v.ComponentName, v.Path, v.Message))
+	}
+	return fmt.Sprintf("design has %d invalid component configuration(s): %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// ValidatePatternFile resolves every component of the design to its registered
+// ComponentDefinition and validates the component configuration against the
+// definition's schema.
+// Validation does not stop at the first failure: all violations are returned
+// together as a *PatternValidationError. A nil error means the design is valid.
+func ValidatePatternFile(patternFile *pattern.PatternFile, regManager *registry.RegistryManager) error {
+	validationErr := &PatternValidationError{}
+
+	for _, comp := range patternFile.Components {
+		if comp == nil {
+			continue
+		}
+		def, err := resolveComponentDefinition(comp, regManager)
+		if err != nil {
+			validationErr.Violations = append(validationErr.Violations, ConfigurationViolation{
+				ComponentID:   comp.Id,
+				ComponentName: comp.DisplayName,
+				Message:       err.Error(),
+			})
+			continue
+		}
+		validationErr.Violations = append(validationErr.Violations, validateConfiguration(comp, def)...)
+	}
+
+	if len(validationErr.Violations) == 0 {
+		return nil
+	}
+	return validationErr
+}
+
+func resolveComponentDefinition(comp *component.ComponentDefinition, regManager *registry.RegistryManager) (*component.ComponentDefinition, error) {
+	if comp.Model.Name == "" {
+		return nil, fmt.Errorf("model is not specified for component of kind %s", comp.Component.Kind)
+	}
+
+	entities, _, _, err := regManager.GetEntities(&regv1beta1.ComponentFilter{
+		Name:       comp.Component.Kind,
+		ModelName:  comp.Model.Name,
+		APIVersion: comp.Component.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	def := findComponentDefinition(entities, comp.Model.Model.Version)
+	if def == nil {
+		return nil, fmt.Errorf("component %s (model: %s, apiVersion: %s) is not registered", comp.Component.Kind, comp.Model.Name, comp.Component.Version)
+	}
+	return def, nil
+}
+
+// findComponentDefinition returns the definition of the given model version,
+// falling back to any registered version of the component.
+func findComponentDefinition(entities []entity.Entity, modelVersion string) *component.ComponentDefinition {
+	var found *component.ComponentDefinition
+	for _, en := range entities {
+		def, ok := en.(*component.ComponentDefinition)
+		if !ok || def == nil {
+			continue
+		}
+		if def.Model.Model.Version == modelVersion {
+			return def
+		}
+		if found == nil {
+			found = def
+		}
+	}
+	return found
+}
+
+func validateConfiguration(comp *component.ComponentDefinition, def *component.ComponentDefinition) []ConfigurationViolation {
+	// Annotation components carry no schema to validate against.
+	if def.Component.Schema == "" {
+		return nil
+	}
+
+	violation := func(path, msg string) ConfigurationViolation {
+		return ConfigurationViolation{
+			ComponentID:   comp.Id,
+			ComponentName: comp.DisplayName,
+			Path:          path,
+			Message:       msg,
+		}
+	}
+
+	rs := jsonschema.GlobalJSONSchema()
+	if err := json.Unmarshal([]byte(def.Component.Schema), rs); err != nil {
+		return []ConfigurationViolation{violation("", fmt.Sprintf("invalid schema in the component definition: %s", err))}
+	}
+
+	configuration := comp.Configuration
+	if configuration == nil {
+		configuration = map[string]interface{}{}
+	}
+	if Format {
+		configuration = Format.DePrettify(configuration, false)
+	}
+	byt, err := json.Marshal(configuration)
+	if err != nil {
+		return []ConfigurationViolation{violation("", fmt.Sprintf("invalid configuration: %s", err))}
+	}
+
+	keyErrs, err := rs.ValidateBytes(context.TODO(), byt)
+	if err != nil {
+		return []ConfigurationViolation{violation("", fmt.Sprintf("error occurred during schema validation: %s", err))}
+	}
+
+	violations := make([]ConfigurationViolation, 0, len(keyErrs))
+	for _, keyErr := range keyErrs {
+		violations = append(violations, violation(keyErr.PropertyPath, keyErr.Message))
+	}
+	return violations
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/meshery/schemas/models/v1beta1/category"
+	"github.com/meshery/schemas/models/v1beta1/component"
+	"github.com/meshery/schemas/models/v1beta1/model"
+	"github.com/meshery/schemas/models/v1beta1/pattern"
+)
+
+const testValidateDeploymentSchema = `{
+	"type": "object",
+	"properties": {
+		"spec": {
+			"type": "object",
+			"required": ["selector"],
+			"properties": {
+				"replicas": {"type": "integer"},
+				"paused": {"type": "boolean"},
+				"selector": {"type": "object"}
+			}
+		}
+	}
+}`
+
+func newTestRegisteredDefinition(kind, version, schema string) component.ComponentDefinition {
+	return component.ComponentDefinition{
+		DisplayName: kind,
+		Component:   component.Component{Kind: kind, Version: version, Schema: schema},
+		Model: model.ModelDefinition{
+			Name:     "kubernetes",
+			Version:  "v1.0.0",
+			Category: category.CategoryDefinition{Name: "Orchestration"},
+			Model:    model.Model{Version: "v1.30.0"},
+			Status:   model.ModelDefinitionStatusEnabled,
+		},
+	}
+}
+
+// newTestRegisteredAnnotation returns the definition of an annotation component, which is registered without a schema.
+func newTestRegisteredAnnotation(kind, version string) component.ComponentDefinition {
+	def := newTestRegisteredDefinition(kind, version, "")
+	def.Metadata.IsAnnotation = true
+	return def
+}
+
+func newTestValidatedComponent(name, kind, version string, configuration map[string]interface{}) *component.ComponentDefinition {
+	comp := newTestComponent(name, kind)
+	comp.Component.Version = version
+	comp.Model = model.ModelDefinition{Name: "kubernetes", Model: model.Model{Version: "v1.30.0"}}
+	for key, value := range configuration {
+		comp.Configuration[key] = value
+	}
+	return comp
+}
+
+func TestValidatePatternFile(t *testing.T) {
+	reg := newTestRegistryManager(t,
+		newTestRegisteredDefinition("Deployment", "apps/v1", testValidateDeploymentSchema),
+		newTestRegisteredAnnotation("Comment", "v1"),
+	)
+
+	valid := newTestValidatedComponent("web", "Deployment", "apps/v1", map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": 2, "selector": map[string]interface{}{}},
+	})
+	invalid := newTestValidatedComponent("api", "Deployment", "apps/v1", map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": "two", "paused": "yes", "selector": map[string]interface{}{}},
+	})
+	missing := newTestValidatedComponent("worker", "Deployment", "apps/v1", map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": 1},
+	})
+	noSchema := newTestValidatedComponent("note", "Comment", "v1", map[string]interface{}{
+		"text": 1,
+	})
+	unregistered := newTestValidatedComponent("widget", "Widget", "example.com/v1", nil)
+	noModel := newTestComponent("orphan", "Deployment")
+
+	type violation struct {
+		component string
+		path      string
+	}
+	tests := []struct {
+		name  string
+		comps []*component.ComponentDefinition
+		// want are the violations of the design, nil when it is valid
+		want []violation
+	}{
+		{
+			name:  "valid configurations",
+			comps: []*component.ComponentDefinition{valid},
+		},
+		{
+			name:  "components without a registered schema",
+			comps: []*component.ComponentDefinition{valid, noSchema},
+		},
+		{
+			name:  "violations of several components",
+			comps: []*component.ComponentDefinition{valid, invalid, missing},
+			want: []violation{
+				{component: "api", path: "/spec/paused"},
+				{component: "api", path: "/spec/replicas"},
+				{component: "worker", path: "/spec"},
+			},
+		},
+		{
+			name:  "unregistered components",
+			comps: []*component.ComponentDefinition{valid, unregistered, noModel},
+			want: []violation{
+				{component: "orphan", path: ""},
+				{component: "widget", path: ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePatternFile(&pattern.PatternFile{Name: "test", Components: tt.comps}, reg)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("expected the design to be valid, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *PatternValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a design validation error, got %v", err)
+			}
+			got := []violation{}
+			for _, v := range validationErr.Violations {
+				if v.Message == "" {
+					t.Errorf("violation of %s at %q has no message", v.ComponentName, v.Path)
+				}
+				got = append(got, violation{component: v.ComponentName, path: v.Path})
+			}
+			sort.Slice(got, func(i, j int) bool {
+				if got[i].component != got[j].component {
+					return got[i].component < got[j].component
+				}
+				return got[i].path < got[j].path
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidatePatternFile() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
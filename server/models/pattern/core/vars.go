@@ -0,0 +1,145 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	ghodssyaml "github.com/ghodss/yaml"
+)
+
+// PatternVarEnvPrefix is the prefix of the environment variables overriding design variables.
+// For example, MESHERY_VAR_namespace overrides the "namespace" variable.
+const PatternVarEnvPrefix = "MESHERY_VAR_"
+
+// patternVarRef matches ${name} references. A reference escaped as $${name}
+// is kept as the literal ${name}.
+var patternVarRef = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// patternVars is the `vars:` section of a design. It maps the name of each
+// variable to its default value.
+type patternVars struct {
+	Vars map[string]interface{} `json:"vars" yaml:"vars"`
+}
+
+// GetPatternVars returns the variables declared in the `vars:` section of the design,
+// with their values resolved from the given overrides, the environment and the
+// declared defaults, in that order of precedence. Overrides and environment variables
+// are converted to the type of the default of the variable, so that a number or a
+// boolean variable stays one when overridden.
+func GetPatternVars(byt []byte, format PatternFileFormat, overrides map[string]string) (map[string]interface{}, error) {
+	var pv patternVars
+	if format == PatternFileFormatAuto {
+		format = DetectPatternFileFormat(byt)
+	}
+	jsonByt := byt
+	if format != PatternFileFormatJSON {
+		var err error
+		if jsonByt, err = ghodssyaml.YAMLToJSON(byt); err != nil {
+			return nil, ErrResolvePatternVars(err)
+		}
+	}
+	if err := json.Unmarshal(jsonByt, &pv); err != nil {
+		return nil, ErrResolvePatternVars(err)
+	}
+
+	vars := make(map[string]interface{}, len(pv.Vars))
+	for name, def := range pv.Vars {
+		value, ok := overrides[name]
+		if !ok {
+			value, ok = os.LookupEnv(PatternVarEnvPrefix + name)
+		}
+		if !ok {
+			vars[name] = def
+			if def == nil {
+				vars[name] = ""
+			}
+			continue
+		}
+		typed, err := typedPatternVar(name, value, def)
+		if err != nil {
+			return nil, ErrResolvePatternVars(err)
+		}
+		vars[name] = typed
+	}
+
+	for name := range overrides {
+		if _, ok := vars[name]; !ok {
+			return nil, ErrResolvePatternVars(fmt.Errorf("variable %q is not declared in the vars section of the design", name))
+		}
+	}
+	return vars, nil
+}
+
+// typedPatternVar converts the value given to the variable to the type of its default.
+// Variables without a default, or with a string default, are strings.
+func typedPatternVar(name, value string, def interface{}) (interface{}, error) {
+	switch def.(type) {
+	case nil, string:
+		return value, nil
+	}
+	var typed interface{}
+	jsonByt, err := ghodssyaml.YAMLToJSON([]byte(value))
+	if err == nil {
+		err = json.Unmarshal(jsonByt, &typed)
+	}
+	if err != nil || reflect.TypeOf(typed) != reflect.TypeOf(def) {
+		return nil, fmt.Errorf("variable %q is of the type of its default %v, which %q is not", name, def, value)
+	}
+	return typed, nil
+}
+
+// interpolatePatternVars replaces the ${name} references to declared variables
+// in every string of the value. A string which is exactly a reference is replaced
+// by the value of the variable, keeping its type, eg. `replicas: ${replicas}` stays
+// a number. References to undeclared variables are left untouched.
+func interpolatePatternVars(v interface{}, vars map[string]interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, val := range x {
+			x[k] = interpolatePatternVars(val, vars)
+		}
+		return x
+	case []interface{}:
+		for i, val := range x {
+			x[i] = interpolatePatternVars(val, vars)
+		}
+		return x
+	case string:
+		if match := patternVarRef.FindStringSubmatch(x); match != nil && match[0] == x && !strings.HasPrefix(x, "$$") {
+			if val, ok := vars[match[1]]; ok {
+				return val
+			}
+			return x
+		}
+		return patternVarRef.ReplaceAllStringFunc(x, func(ref string) string {
+			if strings.HasPrefix(ref, "$$") {
+				return ref[1:]
+			}
+			name := patternVarRef.FindStringSubmatch(ref)[1]
+			if val, ok := vars[name]; ok {
+				return patternVarString(val)
+			}
+			return ref
+		})
+	default:
+		return v
+	}
+}
+
+// patternVarString returns the value of a variable referenced within a string,
+// maps and lists as JSON.
+func patternVarString(val interface{}) string {
+	switch x := val.(type) {
+	case string:
+		return x
+	case map[string]interface{}, []interface{}:
+		if byt, err := json.Marshal(x); err == nil {
+			return string(byt)
+		}
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+const varsTestDesign = `
+name: vars
+vars:
+  namespace: default
+  replicas: 2
+  debug: false
+  labels:
+    team: platform
+  image:
+components:
+  - id: 00000000-0000-0000-0000-000000000001
+    displayName: web
+    component:
+      kind: Deployment
+      version: apps/v1
+    configuration:
+      metadata:
+        name: web
+        namespace: ${namespace}
+        labels: ${labels}
+        annotations:
+          description: web in ${namespace} with ${replicas} replicas
+          escaped: $${namespace}
+          undeclared: ${undeclared}
+      spec:
+        replicas: ${replicas}
+        paused: ${debug}
+        template:
+          spec:
+            containers:
+              - name: web
+                image: ${image}
+`
+
+func TestNewPatternFileWithVars(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		env       map[string]string
+		want      map[string]interface{}
+		wantErr   bool
+	}{
+		{
+			name: "defaults keep their type",
+			want: map[string]interface{}{
+				"namespace":   "default",
+				"replicas":    float64(2),
+				"paused":      false,
+				"labels":      map[string]interface{}{"team": "platform"},
+				"image":       "",
+				"description": "web in default with 2 replicas",
+			},
+		},
+		{
+			name:      "overrides are converted to the type of the default",
+			overrides: map[string]string{"replicas": "3", "debug": "true", "namespace": "prod"},
+			want: map[string]interface{}{
+				"namespace":   "prod",
+				"replicas":    float64(3),
+				"paused":      true,
+				"description": "web in prod with 3 replicas",
+			},
+		},
+		{
+			name:      "empty override",
+			overrides: map[string]string{"namespace": ""},
+			env:       map[string]string{PatternVarEnvPrefix + "namespace": "from-env"},
+			want: map[string]interface{}{
+				"namespace":   "",
+				"description": "web in  with 2 replicas",
+			},
+		},
+		{
+			name: "environment",
+			env:  map[string]string{PatternVarEnvPrefix + "namespace": "from-env", PatternVarEnvPrefix + "image": "nginx:1.25"},
+			want: map[string]interface{}{
+				"namespace": "from-env",
+				"image":     "nginx:1.25",
+			},
+		},
+		{
+			name:      "override of the wrong type",
+			overrides: map[string]string{"replicas": "three"},
+			wantErr:   true,
+		},
+		{
+			name:      "override of an undeclared variable",
+			overrides: map[string]string{"undeclared": "value"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			pf, err := NewPatternFileWithVars([]byte(varsTestDesign), tt.overrides)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := pf.Components[0].Configuration
+			metadata := config["metadata"].(map[string]interface{})
+			annotations := metadata["annotations"].(map[string]interface{})
+			spec := config["spec"].(map[string]interface{})
+			container := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+			got := map[string]interface{}{
+				"namespace":   metadata["namespace"],
+				"replicas":    spec["replicas"],
+				"paused":      spec["paused"],
+				"labels":      metadata["labels"],
+				"image":       container["image"],
+				"description": annotations["description"],
+			}
+			for k, want := range tt.want {
+				if !reflect.DeepEqual(got[k], want) {
+					t.Errorf("%s = %#v, want %#v", k, got[k], want)
+				}
+			}
+			if annotations["escaped"] != "${namespace}" {
+				t.Errorf("escaped reference = %v, want ${namespace}", annotations["escaped"])
+			}
+			if annotations["undeclared"] != "${undeclared}" {
+				t.Errorf("undeclared reference = %v, want ${undeclared}", annotations["undeclared"])
+			}
+		})
+	}
+}
@@ -58,6 +58,12 @@ func Provision(prov ServiceInfoProvider, act ServiceActionProvider, log logger.H
 				return false
 			}
 
+			err = core.ApplySchedulingTraits(&component)
+			if err != nil {
+				errs = append(errs, err)
+				return false
+			}
+
 			// Generate hosts list
 			ccp.Hosts = generateHosts(
 				data.DeclartionToDefinitionMapping[component.Id],
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/oci"
+	meshkitUtils "github.com/layer5io/meshkit/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	// RegistrySnapshotVersionENV pins the server to a published registry snapshot on startup.
+	RegistrySnapshotVersionENV = "REGISTRY_SNAPSHOT_VERSION"
+	// RegistrySnapshotRegistryENV is the OCI registry hosting the registry snapshots.
+	RegistrySnapshotRegistryENV = "REGISTRY_SNAPSHOT_REGISTRY"
+	// RegistrySnapshotRepositoryENV is the repository (within the OCI registry) holding the registry snapshots.
+	RegistrySnapshotRepositoryENV = "REGISTRY_SNAPSHOT_REPOSITORY"
+
+	registrySnapshotPinFile = "registry-snapshot.json"
+	registrySnapshotsDir    = "registry-snapshots"
+)
+
+// RegistrySnapshot is a versioned, immutable copy of the full model registry
+// (the contents of the meshmodel directory) published as an OCI artifact.
+//
+// When a snapshot is pinned, models are seeded from the snapshot instead of
+// the models shipped with the server, so every deployment pinned to the same
+// version gets the same registry content.
+type RegistrySnapshot struct {
+	Version    string    `json:"version"`
+	Registry   string    `json:"registry"`
+	Repository string    `json:"repository"`
+	PinnedAt   time.Time `json:"pinned_at"`
+	// Path is the local directory holding the extracted models of the snapshot.
+	Path string `json:"path"`
+}
+
+// RegistrySnapshotUpgradeRequest is the payload used to pin the server to a registry snapshot.
+// Snapshots are only ever pulled from the registry and repository configured on the server.
+type RegistrySnapshotUpgradeRequest struct {
+	Version string `json:"version"`
+}
+
+var registrySnapshotMx sync.Mutex
+
+// registrySnapshotVersionPattern is the grammar of OCI tags, which the versions of snapshots are.
+var registrySnapshotVersionPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+
+// ValidateRegistrySnapshotVersion checks that the version is a valid OCI tag. As the version names
+// the directory the snapshot is pulled into, this also keeps it within the user data folder.
+func ValidateRegistrySnapshotVersion(version string) error {
+	if !registrySnapshotVersionPattern.MatchString(version) {
+		return ErrInvalidRegistrySnapshotVersion(version)
+	}
+	return nil
+}
+
+func registrySnapshotPinPath() string {
+	return filepath.Join(viper.GetString("USER_DATA_FOLDER"), registrySnapshotPinFile)
+}
+
+// GetPinnedRegistrySnapshot returns the registry snapshot the server is pinned to.
+// A pin recorded through an explicit upgrade takes precedence over the
+// REGISTRY_SNAPSHOT_VERSION environment variable.
+// A nil snapshot is returned when the server is not pinned.
+func GetPinnedRegistrySnapshot() (*RegistrySnapshot, error) {
+	registrySnapshotMx.Lock()
+	defer registrySnapshotMx.Unlock()
+
+	pinPath := registrySnapshotPinPath()
+	data, err := os.ReadFile(pinPath)
+	if err == nil {
+		snapshot := &RegistrySnapshot{}
+		if err := json.Unmarshal(data, snapshot); err != nil {
+			return nil, ErrPinRegistrySnapshot(err)
+		}
+		return snapshot, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, meshkitUtils.ErrReadFile(err, pinPath)
+	}
+
+	version := viper.GetString(RegistrySnapshotVersionENV)
+	if version == "" {
+		return nil, nil
+	}
+	return NewRegistrySnapshot(version, "", ""), nil
+}
+
+// NewRegistrySnapshot returns a snapshot reference for the given version,
+// falling back to the configured registry and repository when they are empty.
+func NewRegistrySnapshot(version, registry, repository string) *RegistrySnapshot {
+	if registry == "" {
+		registry = viper.GetString(RegistrySnapshotRegistryENV)
+	}
+	if repository == "" {
+		repository = viper.GetString(RegistrySnapshotRepositoryENV)
+	}
+	return &RegistrySnapshot{
+		Version:    version,
+		Registry:   registry,
+		Repository: repository,
+	}
+}
+
+// Pull downloads the snapshot into the user data folder, unless it was
+// already downloaded, and sets Path to the extracted models directory.
+func (rs *RegistrySnapshot) Pull() error {
+	if err := ValidateRegistrySnapshotVersion(rs.Version); err != nil {
+		return err
+	}
+	snapshotDir := rs.cacheDir()
+
+	if path, err := findSnapshotModelsDir(snapshotDir); err == nil {
+		rs.Path = path
+		return nil
+	}
+
+	err := os.MkdirAll(snapshotDir, 0755)
+	if err != nil {
+		return ErrPullRegistrySnapshot(meshkitUtils.ErrCreateDir(err, snapshotDir), rs.Version)
+	}
+
+	err = oci.PullFromOCIRegistry(snapshotDir, rs.Registry, rs.Repository, rs.Version, "", "")
+	if err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return ErrPullRegistrySnapshot(err, rs.Version)
+	}
+
+	path, err := findSnapshotModelsDir(snapshotDir)
+	if err != nil {
+		return ErrPullRegistrySnapshot(err, rs.Version)
+	}
+	rs.Path = path
+	return nil
+}
+
+// cacheDir returns the directory the snapshot is pulled into. It is keyed by the registry and the
+// repository of the snapshot along with its version, as the same tag can be published to several
+// repositories with different contents.
+func (rs *RegistrySnapshot) cacheDir() string {
+	source := sha256.Sum256([]byte(rs.Registry + "/" + rs.Repository))
+	return filepath.Join(viper.GetString("USER_DATA_FOLDER"), registrySnapshotsDir, hex.EncodeToString(source[:8]), rs.Version)
+}
+
+// Pin records the snapshot as the one the server should seed models from,
+// including across restarts.
+func (rs *RegistrySnapshot) Pin() error {
+	registrySnapshotMx.Lock()
+	defer registrySnapshotMx.Unlock()
+
+	rs.PinnedAt = time.Now()
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return ErrPinRegistrySnapshot(err)
+	}
+
+	err = os.WriteFile(registrySnapshotPinPath(), data, 0644)
+	if err != nil {
+		return ErrPinRegistrySnapshot(err)
+	}
+	return nil
+}
+
+// findSnapshotModelsDir returns the models directory inside a pulled snapshot.
+// Snapshots are published as a single directory layer (e.g. "meshmodel").
+func findSnapshotModelsDir(snapshotDir string) (string, error) {
+	entries, err := os.ReadDir(snapshotDir)
+	if err != nil {
+		return "", meshkitUtils.ErrReadDir(err, snapshotDir)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return filepath.Join(snapshotDir, entry.Name()), nil
+		}
+	}
+	return "", meshkitUtils.ErrReadDir(os.ErrNotExist, snapshotDir)
+}
+
+// ResolveModelsPath returns the directory models should be seeded from.
+// It is the pinned registry snapshot when one is configured and can be
+// retrieved, otherwise the models shipped with the server.
+func ResolveModelsPath(log logger.Handler) string {
+	snapshot, err := GetPinnedRegistrySnapshot()
+	if err != nil {
+		log.Error(err)
+		return ModelsPath
+	}
+	if snapshot == nil {
+		return ModelsPath
+	}
+
+	err = snapshot.Pull()
+	if err != nil {
+		log.Warn(err)
+		return ModelsPath
+	}
+
+	log.Info("Seeding models from registry snapshot ", snapshot.Version)
+	return snapshot.Path
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidateRegistrySnapshotVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		valid   bool
+	}{
+		{version: "v0.8.0", valid: true},
+		{version: "latest", valid: true},
+		{version: "_build-1.2", valid: true},
+		{version: strings.Repeat("a", 128), valid: true},
+		{version: strings.Repeat("a", 129)},
+		{version: ""},
+		{version: ".."},
+		{version: "../../etc"},
+		{version: "v1/../../x"},
+		{version: "-v1"},
+		{version: ".v1"},
+		{version: "v1 0"},
+		{version: `v1\x`},
+	}
+	for _, tt := range tests {
+		err := ValidateRegistrySnapshotVersion(tt.version)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateRegistrySnapshotVersion(%q) = %v, want nil", tt.version, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("ValidateRegistrySnapshotVersion(%q) = nil, want an error", tt.version)
+		}
+	}
+}
+
+func TestRegistrySnapshotPull_RejectsTraversal(t *testing.T) {
+	dataDir := t.TempDir()
+	viper.Set("USER_DATA_FOLDER", filepath.Join(dataDir, "meshery"))
+	defer viper.Set("USER_DATA_FOLDER", "")
+
+	// a directory next to the user data folder, which a version of ../.. would reach
+	outside := filepath.Join(dataDir, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []string{"../../outside", "../..", ""} {
+		snapshot := NewRegistrySnapshot(version, "ghcr.io", "meshery/registry")
+		if err := snapshot.Pull(); err == nil {
+			t.Fatalf("Pull() of version %q succeeded, want an error", version)
+		}
+		if snapshot.Path != "" {
+			t.Errorf("Pull() of version %q set Path to %q", version, snapshot.Path)
+		}
+	}
+
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("directory outside the user data folder was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "meshery", registrySnapshotsDir)); !os.IsNotExist(err) {
+		t.Errorf("Pull() of an invalid version created the snapshots directory")
+	}
+}
+
+func TestRegistrySnapshotPull_CachedPerRepository(t *testing.T) {
+	dataDir := t.TempDir()
+	viper.Set("USER_DATA_FOLDER", dataDir)
+	defer viper.Set("USER_DATA_FOLDER", "")
+
+	cached := NewRegistrySnapshot("v1.0.0", "ghcr.io", "meshery/registry")
+	modelsDir := filepath.Join(cached.cacheDir(), "meshmodel")
+	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cached.Pull(); err != nil {
+		t.Fatal(err)
+	}
+	if cached.Path != modelsDir {
+		t.Errorf("Pull() set Path to %q, want the cached models %q", cached.Path, modelsDir)
+	}
+
+	for _, snapshot := range []*RegistrySnapshot{
+		NewRegistrySnapshot("v1.0.0", "ghcr.io", "fork/registry"),
+		NewRegistrySnapshot("v1.0.0", "docker.io", "meshery/registry"),
+	} {
+		if snapshot.cacheDir() == cached.cacheDir() {
+			t.Errorf("snapshot of %s/%s shares the cache directory of ghcr.io/meshery/registry", snapshot.Registry, snapshot.Repository)
+		}
+	}
+}
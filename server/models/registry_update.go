@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	// RegistryUpdateSpreadsheetIDENV is the id of the only spreadsheet the registry can be updated from.
+	// Spreadsheet updates are refused when it is not set.
+	RegistryUpdateSpreadsheetIDENV = "REGISTRY_UPDATE_SPREADSHEET_ID"
+	// RegistryUpdateCSVEnabledENV enables registry updates from uploaded components CSVs.
+	RegistryUpdateCSVEnabledENV = "REGISTRY_UPDATE_CSV_ENABLED"
+)
+
+const (
+	RegistryUpdateSourceSpreadsheet = "spreadsheet"
+	RegistryUpdateSourceCSV         = "csv"
+	RegistryUpdateSourceOCI         = "oci"
+)
+
+type RegistryUpdateStatus string
+
+const (
+	RegistryUpdateRunning   RegistryUpdateStatus = "running"
+	RegistryUpdateCompleted RegistryUpdateStatus = "completed"
+	RegistryUpdateFailed    RegistryUpdateStatus = "failed"
+)
+
+// RegistryUpdateRequest is the source a registry update is run from, one of: the Components sheet of a
+// published spreadsheet, an uploaded components CSV (as a data URL), or an OCI artifact of models such as
+// a registry snapshot.
+type RegistryUpdateRequest struct {
+	SpreadsheetID string `json:"spreadsheet_id,omitempty"`
+	// SheetGID is the id of the Components sheet of the spreadsheet.
+	SheetGID int64  `json:"sheet_gid,omitempty"`
+	CSV      string `json:"csv,omitempty"`
+	// OCIRef is the reference of the artifact, e.g. ghcr.io/meshery/registry:v0.8.0.
+	OCIRef string `json:"oci_ref,omitempty"`
+	// Model restricts a spreadsheet or CSV update to the components of a model.
+	Model string `json:"model,omitempty"`
+}
+
+// Source returns the source of the update, after checking that the request sets exactly one.
+func (r *RegistryUpdateRequest) Source() (string, error) {
+	sources := []string{}
+	if r.SpreadsheetID != "" {
+		sources = append(sources, RegistryUpdateSourceSpreadsheet)
+	}
+	if r.CSV != "" {
+		sources = append(sources, RegistryUpdateSourceCSV)
+	}
+	if r.OCIRef != "" {
+		sources = append(sources, RegistryUpdateSourceOCI)
+	}
+	if len(sources) != 1 {
+		return "", ErrInvalidRegistryUpdate(fmt.Errorf("exactly one of spreadsheet_id, csv and oci_ref is required, got %d", len(sources)))
+	}
+	if sources[0] == RegistryUpdateSourceOCI {
+		if _, _, _, err := ParseOCIRef(r.OCIRef); err != nil {
+			return "", err
+		}
+	}
+	return sources[0], nil
+}
+
+// CheckAllowed checks that the source of the update is one the server is configured to update the registry from:
+// a tag of the repository of the registry snapshots, the configured spreadsheet, or an uploaded CSV when enabled.
+func (r *RegistryUpdateRequest) CheckAllowed(source string) error {
+	switch source {
+	case RegistryUpdateSourceOCI:
+		registry, repository, _, err := ParseOCIRef(r.OCIRef)
+		if err != nil {
+			return err
+		}
+		allowedRegistry := viper.GetString(RegistrySnapshotRegistryENV)
+		allowedRepository := viper.GetString(RegistrySnapshotRepositoryENV)
+		if registry != allowedRegistry || repository != allowedRepository {
+			return ErrRegistryUpdateSourceNotAllowed(fmt.Errorf("OCI reference %q is not a tag of %s/%s", r.OCIRef, allowedRegistry, allowedRepository))
+		}
+	case RegistryUpdateSourceSpreadsheet:
+		if allowed := viper.GetString(RegistryUpdateSpreadsheetIDENV); allowed == "" || r.SpreadsheetID != allowed {
+			return ErrRegistryUpdateSourceNotAllowed(fmt.Errorf("spreadsheet %s is not the configured spreadsheet", r.SpreadsheetID))
+		}
+	case RegistryUpdateSourceCSV:
+		if !viper.GetBool(RegistryUpdateCSVEnabledENV) {
+			return ErrRegistryUpdateSourceNotAllowed(fmt.Errorf("components CSV uploads are not enabled"))
+		}
+	}
+	return nil
+}
+
+// ParseOCIRef splits the reference of an OCI artifact into its registry, repository and tag,
+// e.g. ghcr.io/meshery/registry:v0.8.0 is the tag v0.8.0 of the repository meshery/registry of ghcr.io.
+func ParseOCIRef(ref string) (registry, repository, tag string, err error) {
+	slash := strings.Index(ref, "/")
+	colon := strings.LastIndex(ref, ":")
+	if slash <= 0 || colon < slash || colon == len(ref)-1 {
+		return "", "", "", ErrInvalidRegistryUpdate(fmt.Errorf("OCI reference %q is not of the form registry/repository:tag", ref))
+	}
+	return ref[:slash], ref[slash+1 : colon], ref[colon+1:], nil
+}
+
+// RegistryUpdateProgress is a step of a registry update.
+type RegistryUpdateProgress struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Model   string    `json:"model,omitempty"`
+	Error   bool      `json:"error,omitempty"`
+}
+
+// RegistryUpdate is a registry update run by the server against its registry, along with its progress.
+// Updates are kept in memory: they are meant to be monitored while they run, not audited later, which
+// events are for. Only the user who started an update can follow it.
+type RegistryUpdate struct {
+	ID                uuid.UUID                `json:"id"`
+	Source            string                   `json:"source"`
+	UserID            uuid.UUID                `json:"user_id"`
+	Status            RegistryUpdateStatus     `json:"status"`
+	Progress          []RegistryUpdateProgress `json:"progress"`
+	ModelsUpdated     int                      `json:"models_updated"`
+	ComponentsUpdated int                      `json:"components_updated"`
+	Error             string                   `json:"error,omitempty"`
+	StartedAt         time.Time                `json:"started_at"`
+	CompletedAt       *time.Time               `json:"completed_at,omitempty"`
+
+	mx          sync.Mutex
+	subscribers map[chan RegistryUpdateProgress]struct{}
+}
+
+// NewRegistryUpdate returns a running update of the source, started by the user.
+func NewRegistryUpdate(source string, userID uuid.UUID) (*RegistryUpdate, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryUpdate{
+		ID:          id,
+		Source:      source,
+		UserID:      userID,
+		Status:      RegistryUpdateRunning,
+		Progress:    []RegistryUpdateProgress{},
+		StartedAt:   time.Now(),
+		subscribers: map[chan RegistryUpdateProgress]struct{}{},
+	}, nil
+}
+
+// Report records a step of the update and sends it to the subscribers.
+func (u *RegistryUpdate) Report(model, message string, isError bool) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	progress := RegistryUpdateProgress{Time: time.Now(), Message: message, Model: model, Error: isError}
+	u.Progress = append(u.Progress, progress)
+	for ch := range u.subscribers {
+		select {
+		case ch <- progress:
+		default:
+			// a subscriber not keeping up misses steps rather than blocking the update
+		}
+	}
+}
+
+// Count adds the models and components updated.
+func (u *RegistryUpdate) Count(models, components int) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	u.ModelsUpdated += models
+	u.ComponentsUpdated += components
+}
+
+// Finish completes the update, failed with err if it is not nil, and closes the channels of the subscribers.
+func (u *RegistryUpdate) Finish(err error) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	now := time.Now()
+	u.CompletedAt = &now
+	u.Status = RegistryUpdateCompleted
+	if err != nil {
+		u.Status = RegistryUpdateFailed
+		u.Error = err.Error()
+	}
+	for ch := range u.subscribers {
+		close(ch)
+	}
+	u.subscribers = map[chan RegistryUpdateProgress]struct{}{}
+}
+
+// Subscribe returns the steps of the update so far, along with a channel of the next ones, closed when the
+// update finishes, and the function to unsubscribe. The channel is nil once the update finished.
+func (u *RegistryUpdate) Subscribe() ([]RegistryUpdateProgress, <-chan RegistryUpdateProgress, func()) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	progress := append([]RegistryUpdateProgress{}, u.Progress...)
+	if u.Status != RegistryUpdateRunning {
+		return progress, nil, func() {}
+	}
+	ch := make(chan RegistryUpdateProgress, 100)
+	u.subscribers[ch] = struct{}{}
+	return progress, ch, func() {
+		u.mx.Lock()
+		defer u.mx.Unlock()
+		if _, ok := u.subscribers[ch]; ok {
+			delete(u.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Snapshot returns a copy of the update, safe to marshal while the update runs.
+func (u *RegistryUpdate) Snapshot() *RegistryUpdate {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	return &RegistryUpdate{
+		ID:                u.ID,
+		Source:            u.Source,
+		UserID:            u.UserID,
+		Status:            u.Status,
+		Progress:          append([]RegistryUpdateProgress{}, u.Progress...),
+		ModelsUpdated:     u.ModelsUpdated,
+		ComponentsUpdated: u.ComponentsUpdated,
+		Error:             u.Error,
+		StartedAt:         u.StartedAt,
+		CompletedAt:       u.CompletedAt,
+	}
+}
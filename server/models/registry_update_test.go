@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/spf13/viper"
+)
+
+func TestRegistryUpdateRequestSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     RegistryUpdateRequest
+		want    string
+		wantErr bool
+	}{
+		{name: "spreadsheet", req: RegistryUpdateRequest{SpreadsheetID: "sheet", SheetGID: 1}, want: RegistryUpdateSourceSpreadsheet},
+		{name: "csv", req: RegistryUpdateRequest{CSV: "data:text/csv;base64,"}, want: RegistryUpdateSourceCSV},
+		{name: "oci", req: RegistryUpdateRequest{OCIRef: "ghcr.io/meshery/registry:v0.8.0"}, want: RegistryUpdateSourceOCI},
+		{name: "none", req: RegistryUpdateRequest{}, wantErr: true},
+		{name: "several", req: RegistryUpdateRequest{SpreadsheetID: "sheet", CSV: "data:text/csv;base64,"}, wantErr: true},
+		{name: "invalid OCI reference", req: RegistryUpdateRequest{OCIRef: "registry"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Source()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Source() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOCIRef(t *testing.T) {
+	tests := []struct {
+		ref                       string
+		registry, repository, tag string
+		wantErr                   bool
+	}{
+		{ref: "ghcr.io/meshery/registry:v0.8.0", registry: "ghcr.io", repository: "meshery/registry", tag: "v0.8.0"},
+		{ref: "localhost:5000/registry:latest", registry: "localhost:5000", repository: "registry", tag: "latest"},
+		{ref: "ghcr.io/meshery/registry", wantErr: true},
+		{ref: "ghcr.io/meshery/registry:", wantErr: true},
+		{ref: "registry:v0.8.0", wantErr: true},
+		{ref: "/meshery/registry:v0.8.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			registry, repository, tag, err := ParseOCIRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOCIRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if registry != tt.registry || repository != tt.repository || tag != tt.tag {
+				t.Errorf("ParseOCIRef() = %q, %q, %q, want %q, %q, %q", registry, repository, tag, tt.registry, tt.repository, tt.tag)
+			}
+		})
+	}
+}
+
+func TestRegistryUpdateRequestCheckAllowed(t *testing.T) {
+	viper.Set(RegistrySnapshotRegistryENV, "ghcr.io")
+	viper.Set(RegistrySnapshotRepositoryENV, "meshery/registry")
+	t.Cleanup(func() {
+		viper.Set(RegistrySnapshotRegistryENV, nil)
+		viper.Set(RegistrySnapshotRepositoryENV, nil)
+		viper.Set(RegistryUpdateSpreadsheetIDENV, nil)
+		viper.Set(RegistryUpdateCSVEnabledENV, nil)
+	})
+
+	tests := []struct {
+		name        string
+		req         RegistryUpdateRequest
+		spreadsheet string
+		csvEnabled  bool
+		wantErr     bool
+	}{
+		{name: "snapshot tag", req: RegistryUpdateRequest{OCIRef: "ghcr.io/meshery/registry:v0.8.0"}},
+		{name: "other registry", req: RegistryUpdateRequest{OCIRef: "docker.io/meshery/registry:v0.8.0"}, wantErr: true},
+		{name: "other repository", req: RegistryUpdateRequest{OCIRef: "ghcr.io/fork/registry:v0.8.0"}, wantErr: true},
+		{name: "configured spreadsheet", req: RegistryUpdateRequest{SpreadsheetID: "sheet"}, spreadsheet: "sheet"},
+		{name: "other spreadsheet", req: RegistryUpdateRequest{SpreadsheetID: "other"}, spreadsheet: "sheet", wantErr: true},
+		{name: "no configured spreadsheet", req: RegistryUpdateRequest{SpreadsheetID: "sheet"}, wantErr: true},
+		{name: "csv enabled", req: RegistryUpdateRequest{CSV: "data:text/csv;base64,"}, csvEnabled: true},
+		{name: "csv disabled", req: RegistryUpdateRequest{CSV: "data:text/csv;base64,"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(RegistryUpdateSpreadsheetIDENV, tt.spreadsheet)
+			viper.Set(RegistryUpdateCSVEnabledENV, tt.csvEnabled)
+			source, err := tt.req.Source()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.req.CheckAllowed(source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckAllowed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryUpdate(t *testing.T) {
+	userID := uuid.Must(uuid.NewV4())
+	update, err := NewRegistryUpdate(RegistryUpdateSourceOCI, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update.Report("", "Pulling", false)
+
+	done, next, unsubscribe := update.Subscribe()
+	defer unsubscribe()
+	if len(done) != 1 || next == nil {
+		t.Fatalf("expected the step done and a channel of the next ones, got %v, %v", done, next)
+	}
+
+	update.Report("istio", "Registered 2 components", false)
+	update.Count(1, 2)
+	if progress := <-next; progress.Model != "istio" {
+		t.Errorf("expected the next step to be sent to the subscriber, got %+v", progress)
+	}
+	update.Finish(errors.New("failed"))
+	if _, ok := <-next; ok {
+		t.Error("expected the channel to be closed once the update finished")
+	}
+
+	got := update.Snapshot()
+	if got.Status != RegistryUpdateFailed || got.Error != "failed" || got.CompletedAt == nil {
+		t.Errorf("expected the update to be failed, got %+v", got)
+	}
+	if got.UserID != userID || got.ModelsUpdated != 1 || got.ComponentsUpdated != 2 || len(got.Progress) != 2 {
+		t.Errorf("expected the snapshot to copy the update, got %+v", got)
+	}
+	if _, next, _ := update.Subscribe(); next != nil {
+		t.Error("expected no channel once the update finished")
+	}
+}
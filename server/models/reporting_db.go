@@ -0,0 +1,260 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ReportingDBModeENV selects where the reporting queries read from: primary, replica or snapshot.
+	ReportingDBModeENV = "REPORTING_DB_MODE"
+	// ReportingDBFileENV is the SQLite file of the replica, or of the snapshots in the snapshot mode.
+	ReportingDBFileENV = "REPORTING_DB_FILE"
+	// ReportingDBSnapshotIntervalENV is the interval between the snapshots, 15m by default.
+	ReportingDBSnapshotIntervalENV = "REPORTING_DB_SNAPSHOT_INTERVAL"
+)
+
+const (
+	// ReportingDBModePrimary reads the reports from the database of Meshery Server, the default.
+	ReportingDBModePrimary = "primary"
+	// ReportingDBModeReplica reads the reports from a read-only replica of the database, kept up to date
+	// by an external replication, eg. Litestream.
+	ReportingDBModeReplica = "replica"
+	// ReportingDBModeSnapshot reads the reports from a snapshot of the database taken periodically by
+	// Meshery Server.
+	ReportingDBModeSnapshot = "snapshot"
+)
+
+const defaultReportingDBSnapshotInterval = 15 * time.Minute
+
+// ReportingDBStatus describes the database the reporting queries read from.
+type ReportingDBStatus struct {
+	Mode string `json:"mode"`
+	// Source is the database read from, the primary database when the replica or the snapshot is not available.
+	Source       string     `json:"source"`
+	File         string     `json:"file,omitempty"`
+	SnapshotAt   *time.Time `json:"snapshot_at,omitempty"`
+	NextSnapshot *time.Time `json:"next_snapshot,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// ReportingDB is the database of the heavy reporting queries, eg. usage analytics, cost reports and audit
+// logs, so that they do not contend with the writes of the primary database on busy deployments. Reports
+// read from a replica or a snapshot may lag behind the primary database.
+type ReportingDB struct {
+	primary  *database.Handler
+	log      logger.Handler
+	mode     string
+	file     string
+	interval time.Duration
+
+	mx         sync.RWMutex
+	db         *database.Handler
+	stale      *database.Handler
+	snapshotAt time.Time
+	generation int
+	err        error
+}
+
+// NewReportingDB configures the reporting database from the environment. The primary database is used
+// whenever the replica or the snapshot cannot be opened, the error is returned along with it.
+func NewReportingDB(primary *database.Handler, log logger.Handler) (*ReportingDB, error) {
+	rdb := &ReportingDB{
+		primary:  primary,
+		log:      log,
+		mode:     viper.GetString(ReportingDBModeENV),
+		file:     viper.GetString(ReportingDBFileENV),
+		interval: viper.GetDuration(ReportingDBSnapshotIntervalENV),
+	}
+	if rdb.interval <= 0 {
+		rdb.interval = defaultReportingDBSnapshotInterval
+	}
+
+	switch rdb.mode {
+	case "", ReportingDBModePrimary:
+		rdb.mode = ReportingDBModePrimary
+		return rdb, nil
+	case ReportingDBModeReplica:
+		if rdb.file == "" {
+			rdb.err = fmt.Errorf("%s is required to read the reports from a replica", ReportingDBFileENV)
+			return rdb, ErrReportingDB(rdb.err)
+		}
+		db, err := openReportingDB(rdb.file, log)
+		if err != nil {
+			rdb.err = err
+			return rdb, ErrReportingDB(err)
+		}
+		rdb.db = db
+		return rdb, nil
+	case ReportingDBModeSnapshot:
+		if rdb.file == "" {
+			rdb.file = filepath.Join(viper.GetString("USER_DATA_FOLDER"), "mesheryreporting.sql")
+		}
+		return rdb, nil
+	}
+	rdb.err = fmt.Errorf("unknown mode %q, the mode is %s, %s or %s", rdb.mode, ReportingDBModePrimary, ReportingDBModeReplica, ReportingDBModeSnapshot)
+	rdb.mode = ReportingDBModePrimary
+	return rdb, ErrReportingDB(rdb.err)
+}
+
+// openReportingDB opens the SQLite file read-only.
+func openReportingDB(file string, log logger.Handler) (*database.Handler, error) {
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+	db, err := database.New(database.Options{
+		Filename: fmt.Sprintf("file:%s?mode=ro&_busy_timeout=10000", file),
+		Engine:   database.SQLITE,
+		Logger:   log,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+// Handler returns the database to run the reporting queries on, the primary database unless a replica or
+// a snapshot is available.
+func (rdb *ReportingDB) Handler() *database.Handler {
+	if rdb == nil {
+		return GetDBInstance()
+	}
+	rdb.mx.RLock()
+	defer rdb.mx.RUnlock()
+	if rdb.db != nil {
+		return rdb.db
+	}
+	return rdb.primary
+}
+
+// Run takes the snapshots of the primary database in the snapshot mode, until the reporting database is
+// closed. It returns right away in the other modes.
+func (rdb *ReportingDB) Run() {
+	if rdb == nil || rdb.mode != ReportingDBModeSnapshot {
+		return
+	}
+	if err := rdb.Snapshot(); err != nil {
+		rdb.log.Warn(err)
+	}
+	ticker := time.NewTicker(rdb.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if rdb.closed() {
+			return
+		}
+		if err := rdb.Snapshot(); err != nil {
+			rdb.log.Warn(err)
+		}
+	}
+}
+
+// Snapshot copies the primary database into the snapshot read by the reporting queries. The snapshots are
+// written alternately to two files, so that the queries in progress keep reading the previous snapshot
+// until the next one.
+func (rdb *ReportingDB) Snapshot() error {
+	rdb.mx.Lock()
+	if rdb.generation < 0 {
+		rdb.mx.Unlock()
+		return nil
+	}
+	generation := rdb.generation + 1
+	stale := rdb.stale
+	rdb.stale = nil
+	rdb.mx.Unlock()
+	file := fmt.Sprintf("%s.%d", rdb.file, generation%2)
+
+	if stale != nil {
+		// the previous snapshot is overwritten, waits for the queries in progress on it
+		_ = stale.DBClose()
+	}
+
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return rdb.fail(err)
+	}
+	// VACUUM INTO writes a consistent copy of the database without blocking its writers.
+	if err := rdb.primary.Exec("VACUUM INTO ?", file).Error; err != nil {
+		return rdb.fail(err)
+	}
+	db, err := openReportingDB(file, rdb.log)
+	if err != nil {
+		return rdb.fail(err)
+	}
+
+	rdb.mx.Lock()
+	if rdb.generation < 0 {
+		rdb.mx.Unlock()
+		return db.DBClose()
+	}
+	rdb.stale = rdb.db
+	rdb.db = db
+	rdb.generation = generation
+	rdb.snapshotAt = time.Now()
+	rdb.err = nil
+	rdb.mx.Unlock()
+	return nil
+}
+
+func (rdb *ReportingDB) fail(err error) error {
+	rdb.mx.Lock()
+	defer rdb.mx.Unlock()
+	rdb.err = err
+	return ErrReportingDB(err)
+}
+
+func (rdb *ReportingDB) closed() bool {
+	rdb.mx.RLock()
+	defer rdb.mx.RUnlock()
+	return rdb.generation < 0
+}
+
+// Status returns the database the reporting queries read from.
+func (rdb *ReportingDB) Status() ReportingDBStatus {
+	if rdb == nil {
+		return ReportingDBStatus{Mode: ReportingDBModePrimary, Source: ReportingDBModePrimary}
+	}
+	rdb.mx.RLock()
+	defer rdb.mx.RUnlock()
+	status := ReportingDBStatus{Mode: rdb.mode, Source: ReportingDBModePrimary}
+	if rdb.mode != ReportingDBModePrimary {
+		status.File = rdb.file
+	}
+	if rdb.db != nil {
+		status.Source = rdb.mode
+	}
+	if rdb.mode == ReportingDBModeSnapshot && !rdb.snapshotAt.IsZero() {
+		snapshotAt := rdb.snapshotAt
+		next := snapshotAt.Add(rdb.interval)
+		status.SnapshotAt, status.NextSnapshot = &snapshotAt, &next
+	}
+	if rdb.err != nil {
+		status.Error = rdb.err.Error()
+	}
+	return status
+}
+
+// Close closes the replica or the snapshots, and stops taking snapshots. The primary database is left open.
+func (rdb *ReportingDB) Close() error {
+	if rdb == nil {
+		return nil
+	}
+	rdb.mx.Lock()
+	defer rdb.mx.Unlock()
+	rdb.generation = -1
+	if rdb.stale != nil {
+		_ = rdb.stale.DBClose()
+		rdb.stale = nil
+	}
+	if rdb.db == nil {
+		return nil
+	}
+	db := rdb.db
+	rdb.db = nil
+	return db.DBClose()
+}
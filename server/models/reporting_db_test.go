@@ -0,0 +1,178 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/spf13/viper"
+)
+
+type reportingTestRow struct {
+	ID int
+}
+
+func newTestReportingDB(t *testing.T, primary *database.Handler, mode, file string) (*ReportingDB, error) {
+	t.Helper()
+	log, err := logger.New("test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.Set(ReportingDBModeENV, mode)
+	viper.Set(ReportingDBFileENV, file)
+	t.Cleanup(func() {
+		viper.Set(ReportingDBModeENV, "")
+		viper.Set(ReportingDBFileENV, "")
+	})
+	rdb, err := NewReportingDB(primary, log)
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, err
+}
+
+func countReportingTestRows(t *testing.T, db *database.Handler) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&reportingTestRow{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestReportingDBSnapshot(t *testing.T) {
+	primary := newTestDB(t, &reportingTestRow{})
+	file := filepath.Join(t.TempDir(), "reporting.sql")
+	rdb, err := newTestReportingDB(t, primary, ReportingDBModeSnapshot, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reports are read from the primary database until the first snapshot
+	if rdb.Handler() != primary {
+		t.Error("reports are not read from the primary database before the first snapshot")
+	}
+	if status := rdb.Status(); status.Source != ReportingDBModePrimary || status.SnapshotAt != nil {
+		t.Errorf("status = %+v, want the primary database as source", status)
+	}
+
+	if err := primary.Create(&reportingTestRow{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	first := rdb.Handler()
+	if first == primary {
+		t.Fatal("reports are read from the primary database after a snapshot")
+	}
+	if got := countReportingTestRows(t, first); got != 1 {
+		t.Errorf("snapshot has %d rows, want 1", got)
+	}
+	if err := first.Create(&reportingTestRow{ID: 2}).Error; err == nil {
+		t.Error("the snapshot is writable")
+	}
+	status := rdb.Status()
+	if status.Source != ReportingDBModeSnapshot || status.File != file || status.SnapshotAt == nil || status.NextSnapshot == nil || status.Error != "" {
+		t.Errorf("status = %+v, want a snapshot", status)
+	}
+
+	// the snapshot lags behind the primary database until the next one
+	if err := primary.Create(&reportingTestRow{ID: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := countReportingTestRows(t, first); got != 1 {
+		t.Errorf("snapshot has %d rows, want 1", got)
+	}
+	if err := rdb.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if got := countReportingTestRows(t, rdb.Handler()); got != 2 {
+		t.Errorf("next snapshot has %d rows, want 2", got)
+	}
+	// the previous snapshot stays readable until it is overwritten by the one after
+	if got := countReportingTestRows(t, first); got != 1 {
+		t.Errorf("previous snapshot has %d rows, want 1", got)
+	}
+	for _, name := range []string{file + ".0", file + ".1"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("snapshot %s: %v", name, err)
+		}
+	}
+	if err := rdb.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if got := countReportingTestRows(t, rdb.Handler()); got != 2 {
+		t.Errorf("snapshot written over the first one has %d rows, want 2", got)
+	}
+
+	// no snapshot is taken once closed
+	if err := rdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if rdb.Handler() != primary {
+		t.Error("reports are not read from the primary database once closed")
+	}
+}
+
+func TestReportingDBSnapshot_Error(t *testing.T) {
+	primary := newTestDB(t, &reportingTestRow{})
+	file := filepath.Join(t.TempDir(), "missing", "reporting.sql")
+	rdb, err := newTestReportingDB(t, primary, ReportingDBModeSnapshot, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.Snapshot(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if rdb.Handler() != primary {
+		t.Error("reports are not read from the primary database when the snapshot fails")
+	}
+	if status := rdb.Status(); status.Source != ReportingDBModePrimary || status.Error == "" {
+		t.Errorf("status = %+v, want the error of the snapshot", status)
+	}
+}
+
+func TestNewReportingDB(t *testing.T) {
+	primary := newTestDB(t, &reportingTestRow{})
+	replica := filepath.Join(t.TempDir(), "replica.sql")
+	if err := primary.Create(&reportingTestRow{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := primary.Exec("VACUUM INTO ?", replica).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		mode       string
+		file       string
+		wantMode   string
+		wantSource string
+		wantErr    bool
+	}{
+		{name: "default", wantMode: ReportingDBModePrimary, wantSource: ReportingDBModePrimary},
+		{name: "replica", mode: ReportingDBModeReplica, file: replica, wantMode: ReportingDBModeReplica, wantSource: ReportingDBModeReplica},
+		{name: "replica without file", mode: ReportingDBModeReplica, wantMode: ReportingDBModeReplica, wantSource: ReportingDBModePrimary, wantErr: true},
+		{name: "missing replica", mode: ReportingDBModeReplica, file: replica + ".missing", wantMode: ReportingDBModeReplica, wantSource: ReportingDBModePrimary, wantErr: true},
+		{name: "unknown mode", mode: "export", wantMode: ReportingDBModePrimary, wantSource: ReportingDBModePrimary, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb, err := newTestReportingDB(t, primary, tt.mode, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			status := rdb.Status()
+			if status.Mode != tt.wantMode || status.Source != tt.wantSource || (status.Error != "") != tt.wantErr {
+				t.Errorf("status = %+v, want mode %s and source %s", status, tt.wantMode, tt.wantSource)
+			}
+			if got := countReportingTestRows(t, rdb.Handler()); got != 1 {
+				t.Errorf("reporting database has %d rows, want 1", got)
+			}
+		})
+	}
+}
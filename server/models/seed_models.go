@@ -59,7 +59,10 @@ func GetModelDirectoryPaths(modelPath string) ([]string, error) {
 		}
 		// Temporarily remove this once the connection and credentials of k8s is written to repective version is implemented in the generator, and the namespace bug (where the component namespace is incorrectly marked as true) is resolved.
 		if modelName == "kubernetes" {
-			sortedVersionDirs[0] = "../meshmodel/kubernetes/v1.32.0-alpha.3"
+			k8sVersionDir := filepath.Join(modelPath, "kubernetes", "v1.32.0-alpha.3")
+			if _, err := os.Stat(k8sVersionDir); err == nil {
+				sortedVersionDirs[0] = k8sVersionDir
+			}
 		}
 		modelDefDirPath, err := getLatestModelDefDir(sortedVersionDirs[0])
 		if err != nil {
@@ -116,7 +119,7 @@ func getLatestModelDefDir(latestVersionDirPath string) (string, error) {
 func SeedComponents(log logger.Handler, hc *HandlerConfig, regm *meshmodel.RegistryManager) {
 	regErrorStore := NewRegistrationFailureLogHandler()
 	regHelper := registration.NewRegistrationHelper(utils.UI, regm, regErrorStore)
-	modelDirPaths, err := GetModelDirectoryPaths(ModelsPath)
+	modelDirPaths, err := GetModelDirectoryPaths(ResolveModelsPath(log))
 	if err != nil {
 		log.Error(ErrSeedingComponents(err))
 	}
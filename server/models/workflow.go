@@ -0,0 +1,333 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"gopkg.in/yaml.v2"
+)
+
+// WorkflowStepType is the operation performed by a step of a workflow.
+type WorkflowStepType string
+
+const (
+	// WorkflowStepDeploy deploys the design.
+	WorkflowStepDeploy WorkflowStepType = "deploy"
+	// WorkflowStepUndeploy undeploys the design.
+	WorkflowStepUndeploy WorkflowStepType = "undeploy"
+	// WorkflowStepWait waits for the duration.
+	WorkflowStepWait WorkflowStepType = "wait"
+	// WorkflowStepSLO waits until every series of the Prometheus query is within min and max, up to the timeout.
+	WorkflowStepSLO WorkflowStepType = "slo"
+	// WorkflowStepPerfTest runs the performance profile and fails if the test fails.
+	WorkflowStepPerfTest WorkflowStepType = "perf_test"
+	// WorkflowStepApproval waits until a user approves or rejects the run.
+	WorkflowStepApproval WorkflowStepType = "approval"
+	// WorkflowStepNotify posts the message to the URL, as expected by Slack incoming webhooks.
+	WorkflowStepNotify WorkflowStepType = "notify"
+)
+
+// WorkflowStep is a step of a workflow. The fields used depend on the type of the step.
+type WorkflowStep struct {
+	Name string           `json:"name" yaml:"name"`
+	Type WorkflowStepType `json:"type" yaml:"type"`
+	// Design is the id of the design of deploy and undeploy steps.
+	Design string `json:"design,omitempty" yaml:"design,omitempty"`
+	// Profile is the id of the performance profile of perf_test steps.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// Query is the PromQL query of slo steps, bounded by Min and Max.
+	Query string   `json:"query,omitempty" yaml:"query,omitempty"`
+	Min   *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	// Duration is how long wait steps wait, eg. 5m.
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	// Timeout bounds slo and approval steps, eg. 30m. Approval steps wait indefinitely without it.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// URL and Message are the webhook and the text of notify steps.
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Retries is how many times the step is retried after failing, RetryDelay apart. Approval steps are never retried.
+	Retries    int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryDelay string `json:"retry_delay,omitempty" yaml:"retry_delay,omitempty"`
+}
+
+// WorkflowDefinition is a workflow as written by users: ordered steps run one after the other.
+//
+//	name: promote-frontend
+//	steps:
+//	  - name: deploy to staging
+//	    type: deploy
+//	    design: 9c0b8f0e-3b0d-4f0a-9a43-9a1b3c0b6e21
+//	    retries: 2
+//	  - name: wait for SLO
+//	    type: slo
+//	    query: sum(rate(http_requests_total{code=~"5.."}[5m])) / sum(rate(http_requests_total[5m]))
+//	    max: 0.01
+//	    timeout: 15m
+//	  - name: approve promotion
+//	    type: approval
+//	  - name: notify
+//	    type: notify
+//	    url: https://hooks.slack.com/services/T000/B000/XXXX
+//	    message: frontend promoted to production
+type WorkflowDefinition struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Steps       []WorkflowStep `json:"steps" yaml:"steps"`
+}
+
+// ParseWorkflowDefinition decodes and validates the workflow, in YAML or JSON.
+func ParseWorkflowDefinition(byt []byte) (*WorkflowDefinition, error) {
+	def := &WorkflowDefinition{}
+	if err := yaml.UnmarshalStrict(byt, def); err != nil {
+		return nil, ErrInvalidWorkflow(err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, ErrInvalidWorkflow(err)
+	}
+	return def, nil
+}
+
+// Validate checks that the workflow is named, has steps and that every step has the fields its type requires.
+func (def *WorkflowDefinition) Validate() error {
+	if def.Name == "" {
+		return fmt.Errorf("the workflow has no name")
+	}
+	if len(def.Steps) == 0 {
+		return fmt.Errorf("the workflow has no steps")
+	}
+	for i, step := range def.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d has no name", i+1)
+		}
+		if step.Retries < 0 {
+			return fmt.Errorf("step %q has negative retries", step.Name)
+		}
+		for field, d := range map[string]string{"duration": step.Duration, "timeout": step.Timeout, "retry_delay": step.RetryDelay} {
+			if d == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(d)
+			if err != nil {
+				return fmt.Errorf("step %q has an invalid %s: %w", step.Name, field, err)
+			}
+			if parsed <= 0 {
+				return fmt.Errorf("step %q has a %s of %s, it must be positive", step.Name, field, d)
+			}
+		}
+
+		switch step.Type {
+		case WorkflowStepDeploy, WorkflowStepUndeploy:
+			if _, err := uuid.FromString(step.Design); err != nil {
+				return fmt.Errorf("step %q has an invalid design id %q", step.Name, step.Design)
+			}
+		case WorkflowStepWait:
+			if step.Duration == "" {
+				return fmt.Errorf("step %q has no duration", step.Name)
+			}
+		case WorkflowStepSLO:
+			if step.Query == "" || (step.Min == nil && step.Max == nil) {
+				return fmt.Errorf("step %q needs a query and a min or max", step.Name)
+			}
+			if step.Timeout == "" {
+				return fmt.Errorf("step %q has no timeout", step.Name)
+			}
+		case WorkflowStepPerfTest:
+			if _, err := uuid.FromString(step.Profile); err != nil {
+				return fmt.Errorf("step %q has an invalid performance profile id %q", step.Name, step.Profile)
+			}
+		case WorkflowStepApproval:
+		case WorkflowStepNotify:
+			if step.URL == "" || step.Message == "" {
+				return fmt.Errorf("step %q needs a url and a message", step.Name)
+			}
+		default:
+			return fmt.Errorf("step %q has an unknown type %q", step.Name, step.Type)
+		}
+	}
+	return nil
+}
+
+// Workflow is a workflow stored server-side, along with its definition as written by its owner.
+type Workflow struct {
+	ID          uuid.UUID `json:"id" gorm:"primarykey"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Definition  string    `json:"definition"`
+	UserID      uuid.UUID `json:"user_id" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkflowRunStatus is the status of a run of a workflow, or of one of its steps.
+type WorkflowRunStatus string
+
+const (
+	WorkflowPending         WorkflowRunStatus = "pending"
+	WorkflowRunning         WorkflowRunStatus = "running"
+	WorkflowWaitingApproval WorkflowRunStatus = "waiting_approval"
+	WorkflowSucceeded       WorkflowRunStatus = "succeeded"
+	WorkflowFailed          WorkflowRunStatus = "failed"
+	WorkflowCancelled       WorkflowRunStatus = "cancelled"
+)
+
+// Done returns whether the run or step is over.
+func (s WorkflowRunStatus) Done() bool {
+	return s == WorkflowSucceeded || s == WorkflowFailed || s == WorkflowCancelled
+}
+
+// WorkflowStepStatus is the status of a step of a run.
+type WorkflowStepStatus struct {
+	Name     string            `json:"name"`
+	Type     WorkflowStepType  `json:"type"`
+	Status   WorkflowRunStatus `json:"status"`
+	Attempts int               `json:"attempts"`
+	Message  string            `json:"message,omitempty"`
+	// ApprovedBy is the user who approved or rejected an approval step.
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// WorkflowRun is a run of a workflow. The definition is copied when the run starts,
+// so that editing the workflow does not affect runs in progress.
+type WorkflowRun struct {
+	ID          uuid.UUID            `json:"id" gorm:"primarykey"`
+	WorkflowID  uuid.UUID            `json:"workflow_id" gorm:"index"`
+	UserID      uuid.UUID            `json:"user_id"`
+	Definition  string               `json:"-"`
+	Status      WorkflowRunStatus    `json:"status"`
+	CurrentStep int                  `json:"current_step"`
+	Steps       []WorkflowStepStatus `json:"steps" gorm:"type:bytes;serializer:json"`
+	FinishedAt  *time.Time           `json:"finished_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewWorkflowRun returns a pending run of the workflow.
+func NewWorkflowRun(workflow *Workflow, def *WorkflowDefinition, userID uuid.UUID) (*WorkflowRun, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, ErrGenerateUUID(err)
+	}
+	steps := make([]WorkflowStepStatus, 0, len(def.Steps))
+	for _, step := range def.Steps {
+		steps = append(steps, WorkflowStepStatus{Name: step.Name, Type: step.Type, Status: WorkflowPending})
+	}
+	return &WorkflowRun{
+		ID:         id,
+		WorkflowID: workflow.ID,
+		UserID:     userID,
+		Definition: workflow.Definition,
+		Status:     WorkflowPending,
+		Steps:      steps,
+	}, nil
+}
+
+// WorkflowPersister is the persister for workflows and their runs
+type WorkflowPersister struct {
+	DB *database.Handler
+}
+
+// SaveWorkflow creates the workflow, or updates it if it already exists.
+func (wp *WorkflowPersister) SaveWorkflow(workflow *Workflow) error {
+	if workflow.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ErrGenerateUUID(err)
+		}
+		workflow.ID = id
+	}
+	return wp.DB.Save(workflow).Error
+}
+
+// GetWorkflow returns the workflow with the given id, nil if it does not exist.
+func (wp *WorkflowPersister) GetWorkflow(id uuid.UUID) (*Workflow, error) {
+	workflows := []Workflow{}
+	if err := wp.DB.Where("id = ?", id).Limit(1).Find(&workflows).Error; err != nil {
+		return nil, err
+	}
+	if len(workflows) == 0 {
+		return nil, nil
+	}
+	return &workflows[0], nil
+}
+
+// GetWorkflows returns the workflows of the user, by name.
+func (wp *WorkflowPersister) GetWorkflows(userID uuid.UUID) ([]Workflow, error) {
+	workflows := []Workflow{}
+	err := wp.DB.Where("user_id = ?", userID).Order("name").Find(&workflows).Error
+	return workflows, err
+}
+
+// DeleteWorkflow removes the workflow and its runs.
+func (wp *WorkflowPersister) DeleteWorkflow(id uuid.UUID) error {
+	if err := wp.DB.Where("workflow_id = ?", id).Delete(&WorkflowRun{}).Error; err != nil {
+		return err
+	}
+	return wp.DB.Where("id = ?", id).Delete(&Workflow{}).Error
+}
+
+// SaveRun creates the run, or updates it if it already exists.
+func (wp *WorkflowPersister) SaveRun(run *WorkflowRun) error {
+	return wp.DB.Save(run).Error
+}
+
+// GetRun returns the run with the given id, nil if it does not exist.
+func (wp *WorkflowPersister) GetRun(id uuid.UUID) (*WorkflowRun, error) {
+	runs := []WorkflowRun{}
+	if err := wp.DB.Where("id = ?", id).Limit(1).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// GetRuns returns the runs of the workflow, most recent first.
+func (wp *WorkflowPersister) GetRuns(workflowID uuid.UUID) ([]WorkflowRun, error) {
+	runs := []WorkflowRun{}
+	err := wp.DB.Where("workflow_id = ?", workflowID).Order("created_at desc").Find(&runs).Error
+	return runs, err
+}
+
+// GetUnfinishedRuns returns the runs which are not over.
+func (wp *WorkflowPersister) GetUnfinishedRuns() ([]WorkflowRun, error) {
+	runs := []WorkflowRun{}
+	err := wp.DB.Where("status IN ?", []WorkflowRunStatus{WorkflowPending, WorkflowRunning, WorkflowWaitingApproval}).Find(&runs).Error
+	return runs, err
+}
+
+// NotifyWorkflowWebhook posts the message to the webhook as {"text": message},
+// the payload of Slack incoming webhooks.
+func NotifyWorkflowWebhook(ctx context.Context, url, message string) error {
+	body, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		return ErrMarshal(err, "workflow notification")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrStatusCode(resp.StatusCode)
+	}
+	return nil
+}
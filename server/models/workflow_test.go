@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWorkflowDefinition(t *testing.T) {
+	const design = "9c0b8f0e-3b0d-4f0a-9a43-9a1b3c0b6e21"
+	tests := []struct {
+		name    string
+		def     string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			def: `
+name: promote
+steps:
+  - {name: deploy, type: deploy, design: ` + design + `, retries: 2, retry_delay: 10s}
+  - {name: slo, type: slo, query: up, min: 1, timeout: 15m}
+  - {name: approve, type: approval, timeout: 1h}
+  - {name: notify, type: notify, url: "https://hooks.example.com", message: promoted}
+`,
+		},
+		{name: "unknown field", def: "name: promote\nsteps: [{name: wait, type: wait, duration: 1m, delay: 1m}]", wantErr: "delay"},
+		{name: "no name", def: "steps: [{name: wait, type: wait, duration: 1m}]", wantErr: "no name"},
+		{name: "no steps", def: "name: promote", wantErr: "no steps"},
+		{name: "unknown type", def: "name: promote\nsteps: [{name: build, type: build}]", wantErr: "unknown type"},
+		{name: "negative retries", def: "name: promote\nsteps: [{name: wait, type: wait, duration: 1m, retries: -1}]", wantErr: "negative retries"},
+		{name: "invalid retry delay", def: "name: promote\nsteps: [{name: wait, type: wait, duration: 1m, retry_delay: soon}]", wantErr: "invalid retry_delay"},
+		{name: "negative retry delay", def: "name: promote\nsteps: [{name: wait, type: wait, duration: 1m, retry_delay: -5s}]", wantErr: "retry_delay of -5s"},
+		{name: "invalid timeout", def: "name: promote\nsteps: [{name: approve, type: approval, timeout: 1 hour}]", wantErr: "invalid timeout"},
+		{name: "zero timeout", def: "name: promote\nsteps: [{name: approve, type: approval, timeout: 0s}]", wantErr: "timeout of 0s"},
+		{name: "wait without duration", def: "name: promote\nsteps: [{name: wait, type: wait}]", wantErr: "no duration"},
+		{name: "slo without timeout", def: "name: promote\nsteps: [{name: slo, type: slo, query: up, max: 1}]", wantErr: "no timeout"},
+		{name: "invalid design", def: "name: promote\nsteps: [{name: deploy, type: deploy, design: web}]", wantErr: "invalid design id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := ParseWorkflowDefinition([]byte(tt.def))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(def.Steps) != 4 || def.Steps[0].Retries != 2 {
+					t.Errorf("unexpected definition %+v", def)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseWorkflowDefinition() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
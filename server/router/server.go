@@ -10,6 +10,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/viper"
 )
 
 // Router represents Meshery router
@@ -29,10 +30,14 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 		Methods("GET")
 	gMux.Handle("/api/extension/version", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ExtensionsVersionHandler), models.ProviderAuth))).
 		Methods("GET")
+	gMux.Handle("/api/extension/{name}/token", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ExtensionTokenHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/system/database", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetSystemDatabase), models.ProviderAuth))).
 		Methods("GET")
 	gMux.Handle("/api/system/database/reset", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ResetSystemDatabase), models.ProviderAuth))).
 		Methods("DELETE")
+	gMux.Handle("/api/system/database/reporting", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetReportingDatabaseHandler), models.ProviderAuth))).
+		Methods("GET")
 
 	gMux.HandleFunc("/api/provider", h.ProviderHandler)
 	gMux.HandleFunc("/error", h.HandleErrorHandler)
@@ -163,6 +168,56 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 		Methods("POST")
 	gMux.Handle("/api/pattern/deploy", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.PatternFileHandler)), models.ProviderAuth))).
 		Methods("POST", "DELETE")
+	gMux.Handle("/api/pattern/validate", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ValidatePatternHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/simulate", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.SimulatePatternHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/scheduling", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.CheckPatternSchedulingHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/pod-security", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.CheckPatternPodSecurityHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/rightsizing", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.RightSizePatternHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/{id}/rightsizing", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ApplyPatternRightSizingHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/{id}/comments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignCommentsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/{id}/comments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.AddDesignCommentHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/{id}/comments/{commentID}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateDesignCommentHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/pattern/{id}/comments/{commentID}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteDesignCommentHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/pattern/{id}/comments/{commentID}/reactions", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.AddDesignCommentReactionHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/{id}/comments/{commentID}/reactions/{reaction}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteDesignCommentReactionHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/pattern/{id}/activity", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignActivityHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/{id}/fork", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ForkDesignHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/{id}/lineage", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignLineageHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/{id}/merge-requests", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignMergeRequestsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/{id}/merge-requests", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ProposeDesignChangesHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/merge-requests/{mrID}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignMergeRequestHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/merge-requests/{mrID}/accept", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.AcceptDesignMergeRequestHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/merge-requests/{mrID}/reject", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.RejectDesignMergeRequestHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/diagnosis", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.DiagnosePatternDeploymentHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/ownership", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetPatternOwnershipHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/ownership/overrides", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetPatternOwnershipOverridesHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/pattern/sanitize", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SanitizePatternHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/pattern/lint", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.LintPatternHandler), models.ProviderAuth))).
+		Methods("POST")
 	gMux.Handle("/api/pattern", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.PatternFileRequestHandler), models.ProviderAuth))).
 		Methods("POST", "GET")
 	gMux.Handle("/api/pattern/{sourcetype}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DesignFileRequestHandlerWithSourceType), models.ProviderAuth))).
@@ -175,6 +230,8 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 		Methods("DELETE")
 	gMux.Handle("/api/pattern/catalog/publish", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.PublishCatalogPatternHandler), models.ProviderAuth))).
 		Methods("POST")
+	gMux.Handle("/api/pattern/catalog/preview/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetCatalogPatternPreviewHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/pattern/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetMesheryPatternHandler), models.ProviderAuth))).
 		Methods("GET")
 	gMux.Handle("/api/pattern/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteMesheryPatternHandler), models.ProviderAuth))).
@@ -205,6 +262,16 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 
 	gMux.Handle("/api/meshmodels/register", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.RegisterMeshmodels), models.ProviderAuth))).
 		Methods("POST")
+	gMux.Handle("/api/meshmodels/snapshot", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetRegistrySnapshotHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/meshmodels/snapshot", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpgradeRegistrySnapshotHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/meshmodels/updates", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.StartRegistryUpdateHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/meshmodels/updates/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetRegistryUpdateHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/meshmodels/updates/{id}/stream", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.StreamRegistryUpdateHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/meshmodels/categories/{category}", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetMeshmodelCategoriesByName), models.NoAuth))).Methods("GET")
 	gMux.Handle("/api/meshmodels/categories/{category}/models", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetMeshmodelModelsByCategories), models.NoAuth))).Methods("GET")
 	gMux.Handle("/api/meshmodels/categories/{category}/models/{model}", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetMeshmodelModelsByCategoriesByModel), models.NoAuth))).Methods("GET")
@@ -213,7 +280,11 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 	gMux.Handle("/api/meshmodels/categories/{category}/components", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetMeshmodelComponentByCategory), models.NoAuth))).Methods("GET")
 	gMux.Handle("/api/meshmodels/categories/{category}/components/{name}", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetMeshmodelComponentsByNameByCategory), models.NoAuth))).Methods("GET")
 
+	gMux.Handle("/api/meshmodels/components/usage", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetComponentUsageHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/meshmodels/components/{name}", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetAllMeshmodelComponentsByName), models.NoAuth))).Methods("GET")
+	gMux.Handle("/api/meshmodels/components/{name}/designs", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDesignsUsingComponentHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/meshmodels/generate", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.MeshModelGenerationHandler), models.NoAuth))).Methods("POST")
 	gMux.Handle("/api/meshmodels/relationships", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.GetAllMeshmodelRelationships), models.NoAuth))).Methods("GET")
 
@@ -298,6 +369,52 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 	gMux.Handle("/api/integrations/credentials", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveUserCredential), models.ProviderAuth))).
 		Methods("POST")
 
+	gMux.Handle("/api/integrations/backstage/entities", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetBackstageEntitiesHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/integrations/backstage/entities/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetBackstageDesignEntityHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/integrations/backstage/webhooks", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetBackstageWebhooksHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/integrations/backstage/webhooks", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveBackstageWebhookHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/integrations/backstage/webhooks/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteBackstageWebhookHandler), models.ProviderAuth))).
+		Methods("DELETE")
+
+	gMux.Handle("/api/management/v1/designs", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedDesignsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/designs", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.CreateManagedDesignHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/management/v1/designs/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedDesignHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/designs/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateManagedDesignHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/management/v1/designs/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteManagedDesignHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/management/v1/environments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedEnvironmentsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/environments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.CreateManagedEnvironmentHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/management/v1/environments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedEnvironmentHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/environments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateManagedEnvironmentHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/management/v1/environments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteManagedEnvironmentHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/management/v1/connections", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedConnectionsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/connections/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedConnectionHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/deployments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedDeploymentsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/deployments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.CreateManagedDeploymentHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/management/v1/deployments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetManagedDeploymentHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/management/v1/deployments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.UpdateManagedDeploymentHandler)), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/management/v1/deployments/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.DeleteManagedDeploymentHandler)), models.ProviderAuth))).
+		Methods("DELETE")
+
 	gMux.PathPrefix("/api/extensions").
 		Handler(h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ExtensionsHandler), models.ProviderAuth))).
 		Methods("GET", "POST", "OPTIONS", "PUT", "DELETE")
@@ -318,6 +435,81 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 		Methods("DELETE")
 	gMux.Handle("/api/environments/{environmentID}/connections", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetConnectionsOfEnvironmentHandler), models.ProviderAuth))).
 		Methods("GET")
+	gMux.Handle("/api/environments/{environmentID}/ephemeral", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetEphemeralEnvironmentPolicyHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/environments/{environmentID}/ephemeral", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveEphemeralEnvironmentPolicyHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/environments/{environmentID}/ephemeral", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteEphemeralEnvironmentPolicyHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/environments/{environmentID}/ephemeral/deployments", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetEphemeralDeploymentsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/environments/{environmentID}/ephemeral/deployments/{designID}/extend", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ExtendEphemeralDeploymentHandler), models.ProviderAuth))).
+		Methods("POST")
+
+	gMux.Handle("/api/workflows", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetWorkflowsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/workflows", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveWorkflowHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/workflows/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetWorkflowHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/workflows/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateWorkflowHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/workflows/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteWorkflowHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/workflows/{id}/runs", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetWorkflowRunsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/workflows/{id}/runs", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesMiddleware(h.RunWorkflowHandler)), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/workflows/{id}/runs/{runID}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetWorkflowRunHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/workflows/{id}/runs/{runID}/approve", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.ApproveWorkflowRunHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/workflows/{id}/runs/{runID}/reject", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.RejectWorkflowRunHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/workflows/{id}/runs/{runID}/cancel", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.CancelWorkflowRunHandler), models.ProviderAuth))).
+		Methods("POST")
+
+	gMux.Handle("/api/alerts/rules", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetAlertRulesHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/alerts/rules", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveAlertRuleHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/alerts/rules/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetAlertRuleHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/alerts/rules/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateAlertRuleHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/alerts/rules/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteAlertRuleHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/dashboards", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDashboardsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/dashboards", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveDashboardHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/dashboards/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetDashboardHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/dashboards/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.UpdateDashboardHandler), models.ProviderAuth))).
+		Methods("PUT")
+	gMux.Handle("/api/dashboards/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteDashboardHandler), models.ProviderAuth))).
+		Methods("DELETE")
+
+	gMux.Handle("/api/cost-centers/tags", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetCostCenterTagsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/cost-centers/tags", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.SaveCostCenterTagHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/cost-centers/tags/{type}/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteCostCenterTagHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/cost-centers/report", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetCostReportHandler), models.ProviderAuth))).
+		Methods("GET")
+
+	// The admission webhook is called by the Kubernetes API server, which has no Meshery session: the route is not
+	// authenticated. It has no side effects and only answers whether a resource violates the design policies, but
+	// anyone who can reach Meshery Server can submit resources to it and learn the policies from the responses.
+	// Meshery Server does not terminate TLS, so expose the route to the API server only through a TLS terminating
+	// proxy verifying the client certificate of the API server, or restrict access to it with network policies.
+	if viper.GetBool(models.AdmissionWebhookEnabledENV) {
+		gMux.Handle(models.AdmissionWebhookPath, http.HandlerFunc(h.ValidateAdmissionReviewHandler)).
+			Methods("POST")
+		gMux.Handle("/api/system/admission/configuration", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetAdmissionWebhookConfigurationHandler), models.ProviderAuth))).
+			Methods("GET")
+	}
 
 	gMux.Handle("/api/workspaces", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetWorkspacesHandler), models.ProviderAuth))).
 		Methods("GET")